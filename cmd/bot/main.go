@@ -18,6 +18,7 @@ import (
 	"github.com/fardannozami/whatsapp-gateway/internal/infra/repository"
 	"github.com/fardannozami/whatsapp-gateway/internal/infra/strava"
 	"github.com/fardannozami/whatsapp-gateway/internal/infra/wa"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/webhook"
 	"github.com/fardannozami/whatsapp-gateway/internal/queue"
 	"github.com/fardannozami/whatsapp-gateway/internal/scheduler"
 
@@ -52,6 +53,7 @@ func main() {
 	morningCheckpointUC := usecase.NewMorningWorkoutCheckpointUsecase(repo)
 	comebackUC := usecase.NewComebackChallengeUsecase(repo)
 	cancelUC := usecase.NewCancelReportUsecase(repo)
+	cancelUC.SetUserLock(reportUC.UserLock)
 	updateNameUC := usecase.NewUpdateNameUsecase(repo)
 	broadcastUpdateUC := usecase.NewBroadcastUpdateUsecase()
 	resetSessionUC := usecase.NewResetSessionUsecase(repo)
@@ -60,15 +62,43 @@ func main() {
 	goalUC := usecase.NewGoalUsecase(repo)
 	weeklyRanksAnnouncementUC := usecase.NewWeeklyHunterRanksAnnouncementUsecase(repo)
 	dailyQuestUC := usecase.NewDailyQuestUsecase(repo)
+	raidUC := usecase.NewRaidBossUsecase(repo)
+	reportUC.SetRaidRecorder(raidUC.RecordDamage)
+	raidUC.SetRewardGranter(reportUC.GrantRaidReward)
+	cancelUC.SetRaidReverter(raidUC.RevertDamage)
+	liftingUC := usecase.NewLiftingUsecase(repo)
+	reportUC.SetLiftingRecorder(liftingUC.Record)
+	recordUC := usecase.NewPersonalRecordUsecase(repo)
+	cancelUC.SetRecordRetractor(recordUC.Retract)
+	reportUC.SetRecordTracker(recordUC.Record)
 
 	// Strava Integration
 	stravaClient := strava.NewClient(cfg)
 	linkStravaUC := usecase.NewLinkStravaUsecase(repo, stravaClient, cfg)
 	processStravaUC := usecase.NewProcessStravaWebhookUsecase(repo, stravaClient, reportUC, cfg.GroupID)
+	processStravaUC.SetCancelUsecase(cancelUC)
+
+	// Outbound webhooks — bot events are queued as they happen and sent by the
+	// webhook-deliveries job. The same events drop the cached public
+	// leaderboard pages and are pushed to the dashboard's live streams.
+	webhookUC := usecase.NewWebhookUsecase(repo, webhook.NewClient())
+	publicLeaderboardUC := usecase.NewPublicLeaderboardUsecase(repo)
+	eventStreamUC := usecase.NewEventStreamUsecase()
+	publishEvent := usecase.PublishToAll(webhookUC.Publish, publicLeaderboardUC.Invalidate, eventStreamUC.Publish)
+	reportUC.SetEventPublisher(publishEvent)
+	cancelUC.SetEventPublisher(publishEvent)
+	resetSessionUC.SetEventPublisher(publishEvent)
+	processStravaUC.SetEventPublisher(publishEvent)
+	raidUC.SetEventPublisher(publishEvent)
 
 	handleMessageUC := usecase.NewHandleMessageUsecase(
 		reportUC, leaderboardUC, myStatsUC, achievementsUC, comebackUC, cancelUC, updateNameUC, linkStravaUC, broadcastUpdateUC, motivationUC, helpUC,
 	)
+	handleMessageUC.SetRaidBossUsecase(raidUC)
+	handleMessageUC.SetStravaAccountUsecase(usecase.NewStravaAccountUsecase(repo, processStravaUC))
+	handleMessageUC.SetWorkoutFileUsecase(usecase.NewImportWorkoutFileUsecase(repo, reportUC))
+	handleMessageUC.SetLoginUsecase(usecase.NewLoginUsecase(repo, cfg))
+	handleMessageUC.SetSessionUsecase(usecase.NewSessionUsecase(repo, time.Duration(cfg.RefreshTokenDays)*24*time.Hour))
 
 	// 5. WhatsApp Service
 	waService := wa.NewService(cfg.SQLitePath, logger)
@@ -101,6 +131,47 @@ func main() {
 		_ = sender.SendHighPriority(ctx, targetJID, msg)
 	})
 
+	// Raid defeat notifier — posts the MVP list to the group when the weekly boss falls.
+	raidUC.SetDefeatNotifier(func(ctx context.Context, message string) {
+		if sender == nil || cfg.GroupID == "" {
+			return
+		}
+		targetJID, err := types.ParseJID(cfg.GroupID)
+		if err != nil {
+			return
+		}
+		msg := &waE2E.Message{Conversation: &message}
+		_ = sender.SendHighPriority(ctx, targetJID, msg)
+	})
+
+	// Lifting PR notifier — shouts out new Hevy e1RM records in the group.
+	liftingUC.SetPRNotifier(func(ctx context.Context, message string) {
+		if sender == nil || cfg.GroupID == "" {
+			return
+		}
+		targetJID, err := types.ParseJID(cfg.GroupID)
+		if err != nil {
+			return
+		}
+		msg := &waE2E.Message{Conversation: &message}
+		_ = sender.SendNormalPriority(ctx, targetJID, msg)
+	})
+
+	// Personal record notifier — optional, members can find records via /pr either way.
+	if cfg.AnnounceRecords {
+		recordUC.SetNotifier(func(ctx context.Context, message string) {
+			if sender == nil || cfg.GroupID == "" {
+				return
+			}
+			targetJID, err := types.ParseJID(cfg.GroupID)
+			if err != nil {
+				return
+			}
+			msg := &waE2E.Message{Conversation: &message}
+			_ = sender.SendNormalPriority(ctx, targetJID, msg)
+		})
+	}
+
 	// 6. Register Message Handler
 	waService.SetMessageHandler(func(ctx context.Context, client *whatsmeow.Client, evt *events.Message) {
 		fmt.Printf("[DEBUG] Incoming message from Chat ID: %s\n", evt.Info.Chat.String())
@@ -214,7 +285,18 @@ func main() {
 			return
 		}
 
-		response, err := handleMessageUC.Execute(ctx, userID, pushName, msg)
+		var response usecase.MessageResponse
+		var err error
+		if doc := evt.Message.DocumentMessage; doc != nil {
+			response, err = handleMessageUC.ExecuteDocument(ctx, userID, pushName, msg, doc.GetFileName(), func(ctx context.Context) ([]byte, error) {
+				if doc.GetFileLength() > usecase.MaxWorkoutFileBytes {
+					return nil, usecase.ErrWorkoutFileTooLarge
+				}
+				return client.Download(ctx, doc)
+			})
+		} else {
+			response, err = handleMessageUC.Execute(ctx, userID, pushName, msg)
+		}
 		if err != nil {
 			log.Printf("Error handling message: %v", err)
 			return
@@ -295,6 +377,8 @@ func main() {
 	// 9. Start message sender (serializes all SendMessage calls)
 	sender = queue.NewMessageSender(waService.GetClient(), appCtx)
 	sender.Start()
+	processStravaUC.SetSender(sender)
+	resetSessionUC.SetSender(sender)
 
 	// 10. Schedule seasonal reset every 4 months at 00:00 WIB.
 	resetCtx, resetCancel := context.WithCancel(appCtx)
@@ -404,23 +488,22 @@ func main() {
 				return fmt.Errorf("not connected or no group configured")
 			}
 			log.Println("[SCHEDULER] Running daily leaderboard...")
-			response, err := leaderboardUC.Execute(ctx)
+			card, err := leaderboardUC.ExecuteCard(ctx, time.Now())
 			if err != nil {
 				log.Printf("[SCHEDULER] Leaderboard failed: %v", err)
 				return err
 			}
 
-			response += usecase.BuildWellnessReminder()
-			response += "\n\n🌐 Lihat klasemen & stats: https://lapor-bot.web.id/"
+			// The card replaces the text wall; the wellness reminder and the
+			// site link go under it, or after the full text if the image
+			// can't be sent.
+			card.Append(usecase.BuildWellnessReminder() + "\n\n🌐 Lihat klasemen & stats: https://lapor-bot.web.id/")
 
 			targetJID, err := types.ParseJID(cfg.GroupID)
 			if err != nil {
 				return fmt.Errorf("invalid GroupID: %w", err)
 			}
-			msg := &waE2E.Message{
-				Conversation: &response,
-			}
-			return sender.SendHighPriority(ctx, targetJID, msg)
+			return sender.SendImageHighPriority(ctx, targetJID, card.Image, card.Caption, card.Text)
 		},
 	})
 
@@ -433,7 +516,7 @@ func main() {
 				return fmt.Errorf("not connected or no group configured")
 			}
 			log.Println("[SCHEDULER] Running weekly ranks announcement...")
-			response, err := weeklyRanksAnnouncementUC.Execute(ctx, time.Now().In(jakartaLoc))
+			card, err := weeklyRanksAnnouncementUC.ExecuteCard(ctx, time.Now().In(jakartaLoc))
 			if err != nil {
 				log.Printf("[SCHEDULER] Weekly ranks announcement failed: %v", err)
 				return err
@@ -443,10 +526,7 @@ func main() {
 			if err != nil {
 				return fmt.Errorf("invalid GroupID: %w", err)
 			}
-			msg := &waE2E.Message{
-				Conversation: &response,
-			}
-			return sender.SendHighPriority(ctx, targetJID, msg)
+			return sender.SendImageHighPriority(ctx, targetJID, card.Image, card.Caption, card.Text)
 		},
 	})
 
@@ -463,12 +543,120 @@ func main() {
 		},
 	})
 
+	// Runs just after the Monday report cutoff so the new ISO week has begun.
+	sched.AddJob(&scheduler.Job{
+		Name:    "raid-weekly",
+		Freq:    scheduler.WeeklySchedule{Weekday: time.Monday, Hour: 0, Minute: 35, Loc: jakartaLoc},
+		Recover: false,
+		Fn: func(ctx context.Context) error {
+			if cfg.GroupID == "" || !waService.IsLoggedIn() || !waService.GetClient().IsConnected() {
+				return fmt.Errorf("not connected or no group configured")
+			}
+			log.Println("[SCHEDULER] Rotating weekly raid boss...")
+			now := time.Now().In(jakartaLoc)
+			// Bosses whose rewards failed at the finishing blow are settled
+			// first; a failure here must not hold up the rotation.
+			settled, err := raidUC.SettleUnrewarded(ctx, now)
+			if err != nil {
+				log.Printf("[SCHEDULER] Raid reward settlement failed: %v", err)
+			}
+			summary, err := raidUC.Expire(ctx, now)
+			if err != nil {
+				log.Printf("[SCHEDULER] Raid expiry failed: %v", err)
+				return err
+			}
+			announcement, err := raidUC.Spawn(ctx, now)
+			if err != nil {
+				log.Printf("[SCHEDULER] Raid spawn failed: %v", err)
+				return err
+			}
+
+			targetJID, err := types.ParseJID(cfg.GroupID)
+			if err != nil {
+				return fmt.Errorf("invalid GroupID: %w", err)
+			}
+			for _, text := range []string{settled, summary, announcement} {
+				if text == "" {
+					continue
+				}
+				msg := &waE2E.Message{
+					Conversation: &text,
+				}
+				if err := sender.SendHighPriority(ctx, targetJID, msg); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+
+	stravaInboxUC := usecase.NewStravaWebhookInboxUsecase(repo, processStravaUC)
+	sched.AddJob(&scheduler.Job{
+		Name:    "strava-webhook-inbox",
+		Freq:    scheduler.IntervalSchedule{Every: 10 * time.Second},
+		Recover: false,
+		Fn: func(ctx context.Context) error {
+			if _, err := stravaInboxUC.ProcessDue(ctx, time.Now()); err != nil {
+				log.Printf("[SCHEDULER] Strava webhook inbox failed: %v", err)
+				return err
+			}
+			return nil
+		},
+	})
+
+	sched.AddJob(&scheduler.Job{
+		Name:    "webhook-deliveries",
+		Freq:    scheduler.IntervalSchedule{Every: 15 * time.Second},
+		Recover: false,
+		Fn: func(ctx context.Context) error {
+			if _, err := webhookUC.DeliverDue(ctx, time.Now()); err != nil {
+				log.Printf("[SCHEDULER] Webhook deliveries failed: %v", err)
+				return err
+			}
+			return nil
+		},
+	})
+
+	stravaSyncUC := usecase.NewSyncStravaUsecase(repo, processStravaUC)
+	sched.AddJob(&scheduler.Job{
+		Name:    "strava-poll",
+		Freq:    scheduler.IntervalSchedule{Every: 15 * time.Minute},
+		Recover: false,
+		Fn: func(ctx context.Context) error {
+			if _, err := stravaSyncUC.PollQuiet(ctx, time.Now()); err != nil {
+				log.Printf("[SCHEDULER] Strava poll failed: %v", err)
+				return err
+			}
+			return nil
+		},
+	})
+
 	sched.Start()
 
 	log.Printf("Starting HTTP server on port %s", cfg.Port)
 
 	// 12. HTTP server (Healthcheck + Strava + Leaderboard API)
 	httpServer := botHTTP.NewServer(repo, linkStravaUC, processStravaUC, waService.GetClient(), cfg)
+	httpServer.SetReportUsecase(reportUC)
+	httpServer.SetPublicLeaderboardUsecase(publicLeaderboardUC)
+	httpServer.SetEventStreamUsecase(eventStreamUC)
+	userReportUC := usecase.NewUserReportUsecase(repo, reportUC, dailyQuestUC, cancelUC)
+	if cfg.AnnounceWebReports {
+		// Keeps reports made outside WhatsApp visible in the group chat.
+		userReportUC.SetNotifier(func(ctx context.Context, message string) {
+			if sender == nil || cfg.GroupID == "" {
+				return
+			}
+			targetJID, err := types.ParseJID(cfg.GroupID)
+			if err != nil {
+				return
+			}
+			msg := &waE2E.Message{Conversation: &message}
+			_ = sender.SendNormalPriority(ctx, targetJID, msg)
+		})
+	}
+	httpServer.SetUserReportUsecase(userReportUC)
+	httpServer.SetMessageSender(sender)
 	mux := http.NewServeMux()
 	httpServer.RegisterHandlers(mux)
 
@@ -476,6 +664,9 @@ func main() {
 		Addr:    ":" + cfg.Port,
 		Handler: mux,
 	}
+	// Open event streams never finish on their own; end them so shutdown
+	// doesn't wait out its timeout.
+	server.RegisterOnShutdown(eventStreamUC.Close)
 
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
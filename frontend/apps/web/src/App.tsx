@@ -34,7 +34,7 @@ function getInitialTheme(): Theme {
 function App() {
   const { summary, hunters, loading, refreshing, error, refresh } =
     useReports();
-  const { user: authUser, token, logout } = useAuth();
+  const { user: authUser, token, logout, loginWithLink } = useAuth();
   const [selectedHunter, setSelectedHunter] = useState<EnrichedReport | null>(
     null,
   );
@@ -66,6 +66,24 @@ function App() {
     }
   }, [authUser, token]);
 
+  // /dashboard DMs a link to /login?token=…; exchange it once and drop it
+  // from the address bar so a reload or shared URL doesn't replay it.
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.search);
+    const linkToken = params.get("token");
+    if (window.location.pathname !== "/login" || !linkToken) return;
+    window.history.replaceState(null, "", "/");
+    loginWithLink(linkToken).then((user) => {
+      if (!user) {
+        setPage("login");
+        return;
+      }
+      setPersonalUser(user);
+      const isPhoneLike = !user.name || /^[\d+]/.test(user.name);
+      setPage(isPhoneLike ? "profile-setup" : "personal");
+    });
+  }, []);
+
   const seasonTitle = summary
     ? `SWEG Healthy Club - Season ${summary.current_season}`
     : "SWEG Healthy Club";
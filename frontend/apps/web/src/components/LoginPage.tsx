@@ -1,5 +1,5 @@
 import { useState, type FormEvent } from 'react';
-import { LogIn, ArrowLeft, Phone, User, AlertCircle, Loader2 } from 'lucide-react';
+import { LogIn, ArrowLeft, Phone, User, AlertCircle, Loader2, KeyRound, Send } from 'lucide-react';
 import { useAuth } from '@lapor-bot/shared';
 import type { EnrichedReport } from '@lapor-bot/shared';
 
@@ -11,12 +11,15 @@ interface LoginPageProps {
 export function LoginPage({ onLoginSuccess, onBack }: LoginPageProps) {
   const [countryCode, setCountryCode] = useState('62');
   const [phone, setPhone] = useState('');
+  const [code, setCode] = useState('');
+  // Set once the bot has DM'd a code to this number.
+  const [codeSentTo, setCodeSentTo] = useState<string | null>(null);
   const [validationError, setValidationError] = useState<string | null>(null);
-  const { login, loading, error: authError } = useAuth();
+  const { requestCode, login, loading, error: authError } = useAuth();
 
   const error = validationError || authError;
 
-  const handleSubmit = async (e: FormEvent) => {
+  const handleRequestCode = async (e: FormEvent) => {
     e.preventDefault();
     setValidationError(null);
 
@@ -35,12 +38,35 @@ export function LoginPage({ onLoginSuccess, onBack }: LoginPageProps) {
       return;
     }
 
-    const user = await login(`${countryCode}${digits}`);
+    const fullPhone = `${countryCode}${digits}`;
+    if (await requestCode(fullPhone)) {
+      setCodeSentTo(fullPhone);
+      setCode('');
+    }
+  };
+
+  const handleVerify = async (e: FormEvent) => {
+    e.preventDefault();
+    setValidationError(null);
+    if (!codeSentTo) return;
+
+    if (!/^\d{6}$/.test(code)) {
+      setValidationError('Kode login terdiri dari 6 digit.');
+      return;
+    }
+
+    const user = await login(codeSentTo, code);
     if (user) {
       onLoginSuccess(user);
     }
   };
 
+  const handleChangeNumber = () => {
+    setCodeSentTo(null);
+    setCode('');
+    setValidationError(null);
+  };
+
   return (
     <div className="min-h-[70vh] flex items-center justify-center px-4">
       <div className="w-full max-w-md glass rounded-3xl p-8 border border-system-green/20">
@@ -63,65 +89,122 @@ export function LoginPage({ onLoginSuccess, onBack }: LoginPageProps) {
           </div>
         </div>
 
-        <form onSubmit={handleSubmit} className="space-y-4">
-          <div>
-            <label className="block text-xs text-gray-400 font-mono uppercase tracking-wider mb-1.5">
-              Nomor Telepon
-            </label>
-            <div className="flex gap-2">
-              <div className="relative shrink-0">
-                <span className="absolute left-3 top-1/2 -translate-y-1/2 text-gray-400 text-sm pointer-events-none">
-                  +
-                </span>
+        {codeSentTo ? (
+          <form onSubmit={handleVerify} className="space-y-4">
+            <div>
+              <label className="block text-xs text-gray-400 font-mono uppercase tracking-wider mb-1.5">
+                Kode Login
+              </label>
+              <p className="text-xs text-gray-500 font-mono mb-2">
+                Bot sudah kirim kode 6 digit ke WhatsApp +{codeSentTo}.
+              </p>
+              <div className="relative">
+                <KeyRound className="absolute left-3 top-1/2 -translate-y-1/2 text-gray-500" size={14} />
                 <input
                   type="text"
-                  value={countryCode}
-                  onChange={(e) => setCountryCode(e.target.value.replace(/\D/g, ''))}
-                  placeholder="62"
-                  maxLength={3}
-                  className="w-16 pl-6 pr-2 py-2.5 rounded-xl bg-gray-950 border border-gray-800 text-white text-sm font-mono focus:outline-none focus:border-system-blue transition-colors"
-                  disabled={loading}
-                />
-              </div>
-              <div className="relative flex-1">
-                <Phone className="absolute left-3 top-1/2 -translate-y-1/2 text-gray-500" size={14} />
-                <input
-                  type="text"
-                  value={phone}
-                  onChange={(e) => setPhone(e.target.value.replace(/\D/g, ''))}
-                  placeholder="8xxxxxx"
-                  maxLength={14}
-                  className="w-full pl-9 pr-3 py-2.5 rounded-xl bg-gray-950 border border-gray-800 text-white text-sm font-mono focus:outline-none focus:border-system-blue transition-colors"
+                  inputMode="numeric"
+                  autoComplete="one-time-code"
+                  value={code}
+                  onChange={(e) => setCode(e.target.value.replace(/\D/g, ''))}
+                  placeholder="000000"
+                  maxLength={6}
+                  className="w-full pl-9 pr-3 py-2.5 rounded-xl bg-gray-950 border border-gray-800 text-white text-sm font-mono tracking-[0.4em] focus:outline-none focus:border-system-blue transition-colors"
                   disabled={loading}
                   autoFocus
                 />
               </div>
             </div>
-          </div>
 
-          {error && (
-            <div className="p-3 rounded-xl bg-system-red/10 border border-system-red/35 flex items-start gap-2">
-              <AlertCircle className="text-system-red mt-0.5 shrink-0" size={14} />
-              <p className="text-xs text-red-300 font-mono">{error}</p>
+            {error && (
+              <div className="p-3 rounded-xl bg-system-red/10 border border-system-red/35 flex items-start gap-2">
+                <AlertCircle className="text-system-red mt-0.5 shrink-0" size={14} />
+                <p className="text-xs text-red-300 font-mono">{error}</p>
+              </div>
+            )}
+
+            <button
+              type="submit"
+              disabled={loading}
+              className="w-full flex items-center justify-center gap-2 px-5 py-3 rounded-xl bg-system-blue/10 hover:bg-system-blue/20 border border-system-blue/30 text-system-blue font-bold font-orbitron text-sm tracking-wider uppercase transition-colors disabled:opacity-50"
+            >
+              {loading ? (
+                <Loader2 className="animate-spin" size={16} />
+              ) : (
+                <LogIn size={16} />
+              )}
+              {loading ? 'Memeriksa...' : 'Masuk'}
+            </button>
+
+            <button
+              type="button"
+              onClick={handleChangeNumber}
+              disabled={loading}
+              className="w-full text-xs text-gray-500 hover:text-gray-300 font-mono uppercase tracking-wider transition-colors disabled:opacity-50"
+            >
+              Ganti nomor / kirim ulang kode
+            </button>
+          </form>
+        ) : (
+          <form onSubmit={handleRequestCode} className="space-y-4">
+            <div>
+              <label className="block text-xs text-gray-400 font-mono uppercase tracking-wider mb-1.5">
+                Nomor Telepon
+              </label>
+              <div className="flex gap-2">
+                <div className="relative shrink-0">
+                  <span className="absolute left-3 top-1/2 -translate-y-1/2 text-gray-400 text-sm pointer-events-none">
+                    +
+                  </span>
+                  <input
+                    type="text"
+                    value={countryCode}
+                    onChange={(e) => setCountryCode(e.target.value.replace(/\D/g, ''))}
+                    placeholder="62"
+                    maxLength={3}
+                    className="w-16 pl-6 pr-2 py-2.5 rounded-xl bg-gray-950 border border-gray-800 text-white text-sm font-mono focus:outline-none focus:border-system-blue transition-colors"
+                    disabled={loading}
+                  />
+                </div>
+                <div className="relative flex-1">
+                  <Phone className="absolute left-3 top-1/2 -translate-y-1/2 text-gray-500" size={14} />
+                  <input
+                    type="text"
+                    value={phone}
+                    onChange={(e) => setPhone(e.target.value.replace(/\D/g, ''))}
+                    placeholder="8xxxxxx"
+                    maxLength={14}
+                    className="w-full pl-9 pr-3 py-2.5 rounded-xl bg-gray-950 border border-gray-800 text-white text-sm font-mono focus:outline-none focus:border-system-blue transition-colors"
+                    disabled={loading}
+                    autoFocus
+                  />
+                </div>
+              </div>
             </div>
-          )}
 
-          <button
-            type="submit"
-            disabled={loading}
-            className="w-full flex items-center justify-center gap-2 px-5 py-3 rounded-xl bg-system-blue/10 hover:bg-system-blue/20 border border-system-blue/30 text-system-blue font-bold font-orbitron text-sm tracking-wider uppercase transition-colors disabled:opacity-50"
-          >
-            {loading ? (
-              <Loader2 className="animate-spin" size={16} />
-            ) : (
-              <LogIn size={16} />
+            {error && (
+              <div className="p-3 rounded-xl bg-system-red/10 border border-system-red/35 flex items-start gap-2">
+                <AlertCircle className="text-system-red mt-0.5 shrink-0" size={14} />
+                <p className="text-xs text-red-300 font-mono">{error}</p>
+              </div>
             )}
-            {loading ? 'Mencari...' : 'Masuk'}
-          </button>
-        </form>
+
+            <button
+              type="submit"
+              disabled={loading}
+              className="w-full flex items-center justify-center gap-2 px-5 py-3 rounded-xl bg-system-blue/10 hover:bg-system-blue/20 border border-system-blue/30 text-system-blue font-bold font-orbitron text-sm tracking-wider uppercase transition-colors disabled:opacity-50"
+            >
+              {loading ? (
+                <Loader2 className="animate-spin" size={16} />
+              ) : (
+                <Send size={16} />
+              )}
+              {loading ? 'Mengirim...' : 'Kirim Kode'}
+            </button>
+          </form>
+        )}
 
         <p className="text-[10px] text-gray-600 font-mono mt-5 text-center tracking-wide uppercase">
-          Pastikan nomor HP kamu terdaftar di database laporan grup
+          Kode login dikirim lewat WhatsApp ke nomor yang terdaftar di laporan grup
         </p>
       </div>
     </div>
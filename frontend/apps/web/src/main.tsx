@@ -9,12 +9,45 @@ import App from './App.tsx'
 const queryClient = new QueryClient();
 
 const TOKEN_KEY = 'lapor-bot-token';
+const REFRESH_KEY = 'lapor-bot-refresh-token';
 const getToken = () => {
   try { return localStorage.getItem(TOKEN_KEY); } catch { return null; }
 };
 
 const authRepo = new HttpAuthRepository('', getToken);
-const reportRepo = new HttpReportRepository('', getToken);
+
+// Refresh tokens are single use, so requests that hit an expired access
+// token at the same time share one refresh instead of racing each other.
+// Tabs share the token through localStorage, so they take turns through a
+// Web Lock, and a tab that finds the token already swapped by another one
+// just uses the result.
+const withRefreshLock = <T,>(fn: () => Promise<T>): Promise<T> =>
+  navigator.locks ? navigator.locks.request('lapor-bot-refresh', fn) : fn();
+
+let pendingRefresh: Promise<string | null> | null = null;
+const refreshToken = () => {
+  pendingRefresh ??= (async () => {
+    try {
+      const seen = localStorage.getItem(REFRESH_KEY);
+      return await withRefreshLock(async () => {
+        const current = localStorage.getItem(REFRESH_KEY);
+        if (!current) return null;
+        if (current !== seen) return localStorage.getItem(TOKEN_KEY);
+        const renewed = await authRepo.refresh(current);
+        localStorage.setItem(TOKEN_KEY, renewed.token);
+        localStorage.setItem(REFRESH_KEY, renewed.refresh_token);
+        return renewed.token;
+      });
+    } catch {
+      return null;
+    } finally {
+      pendingRefresh = null;
+    }
+  })();
+  return pendingRefresh;
+};
+
+const reportRepo = new HttpReportRepository('', getToken, undefined, refreshToken);
 
 const repositories = {
   reports: reportRepo,
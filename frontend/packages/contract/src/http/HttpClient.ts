@@ -1,19 +1,46 @@
 export type GetTokenFn = () => string | null;
 export type OnUnauthorizedFn = () => void;
+/** Renews the access token and resolves to it, or to null if the session is over. */
+export type RefreshTokenFn = () => Promise<string | null>;
 
 export class HttpClient {
   protected baseURL: string;
   private getToken: GetTokenFn;
   private onUnauthorized?: OnUnauthorizedFn;
+  private refreshToken?: RefreshTokenFn;
 
   constructor(
     baseURL: string = "",
     getToken: GetTokenFn = () => null,
     onUnauthorized?: OnUnauthorizedFn,
+    refreshToken?: RefreshTokenFn,
   ) {
     this.baseURL = baseURL;
     this.getToken = getToken;
     this.onUnauthorized = onUnauthorized;
+    this.refreshToken = refreshToken;
+  }
+
+  /**
+   * Sends an authenticated request. Access tokens are short-lived, so a 401
+   * is retried once after renewing the token.
+   */
+  private async send(
+    path: string,
+    init: Omit<RequestInit, "headers"> & { headers?: Record<string, string> },
+  ): Promise<Response> {
+    const request = () =>
+      fetch(`${this.baseURL}${path}`, {
+        ...init,
+        headers: { ...init.headers, ...this.authHeaders() },
+      });
+
+    const response = await request();
+    if (response.status !== 401 || !this.refreshToken || !this.getToken()) {
+      return response;
+    }
+    const renewed = await this.refreshToken();
+    return renewed ? request() : response;
   }
 
   private authHeaders(): Record<string, string> {
@@ -40,8 +67,7 @@ export class HttpClient {
   }
 
   protected async get<T>(path: string): Promise<T> {
-    const response = await fetch(`${this.baseURL}${path}`, {
-      headers: { ...this.authHeaders() },
+    const response = await this.send(path, {
       // Bypass the browser HTTP cache so a refetch right after a PATCH
       // (e.g. goal update) returns fresh data instead of a stale copy.
       cache: "no-store",
@@ -50,20 +76,25 @@ export class HttpClient {
   }
 
   protected async post<T>(path: string, body: unknown): Promise<T> {
-    const response = await fetch(`${this.baseURL}${path}`, {
+    const response = await this.send(path, {
       method: "POST",
-      headers: { "Content-Type": "application/json", ...this.authHeaders() },
+      headers: { "Content-Type": "application/json" },
       body: JSON.stringify(body),
     });
     return this.handleResponse<T>(response);
   }
 
   protected async patch<T>(path: string, body: unknown): Promise<T> {
-    const response = await fetch(`${this.baseURL}${path}`, {
+    const response = await this.send(path, {
       method: "PATCH",
-      headers: { "Content-Type": "application/json", ...this.authHeaders() },
+      headers: { "Content-Type": "application/json" },
       body: JSON.stringify(body),
     });
     return this.handleResponse<T>(response);
   }
+
+  protected async delete<T>(path: string): Promise<T> {
+    const response = await this.send(path, { method: "DELETE" });
+    return this.handleResponse<T>(response);
+  }
 }
@@ -1,4 +1,4 @@
-import type { IAuthRepository, LoginResult, EnrichedReport } from '@lapor-bot/shared';
+import type { IAuthRepository, LoginCodeResult, LoginResult, SessionTokens, EnrichedReport } from '@lapor-bot/shared';
 import { HttpClient } from '../http/HttpClient';
 import type { GetTokenFn, OnUnauthorizedFn } from '../http/HttpClient';
 
@@ -7,10 +7,14 @@ export class HttpAuthRepository extends HttpClient implements IAuthRepository {
     super(baseURL, getToken, onUnauthorized);
   }
 
-  async login(phone: string): Promise<LoginResult> {
-    const res = await this.post<{ token: string; expires_at: string; user: { phone: string; name: string } }>(
-      '/api/auth/login',
-      { phone },
+  requestCode(phone: string): Promise<LoginCodeResult> {
+    return this.post<LoginCodeResult>('/api/auth/login', { phone });
+  }
+
+  async login(phone: string, code: string): Promise<LoginResult> {
+    const res = await this.post<SessionTokens & { user: { phone: string; name: string } }>(
+      '/api/auth/verify',
+      { phone, code },
     );
 
     // Fetch full profile using the new token
@@ -19,6 +23,20 @@ export class HttpAuthRepository extends HttpClient implements IAuthRepository {
     return { ...res, profile };
   }
 
+  async loginWithLink(token: string): Promise<LoginResult> {
+    const res = await this.post<SessionTokens>('/api/auth/link', { token });
+    const profile = await this.getWithToken<EnrichedReport>('/api/user', res.token);
+    return { ...res, profile };
+  }
+
+  refresh(refreshToken: string): Promise<SessionTokens> {
+    return this.post<SessionTokens>('/api/auth/refresh', { refresh_token: refreshToken });
+  }
+
+  async logout(refreshToken: string): Promise<void> {
+    await this.post<{ status: string }>('/api/auth/logout', { refresh_token: refreshToken });
+  }
+
   private async getWithToken<T>(path: string, token: string): Promise<T> {
     const response = await fetch(`${this.baseURL}${path}`, {
       headers: { Authorization: `Bearer ${token}` },
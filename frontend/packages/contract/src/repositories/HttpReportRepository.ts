@@ -1,24 +1,50 @@
 import type {
 	IReportRepository,
+	CancelReportResult,
 	EnrichedReport,
 	GlobalSummary,
 	JobInfo,
+	LeaderboardPage,
+	LeaderboardQuery,
+	ProfileSettings,
+	ProfileSettingsUpdate,
+	PublicProfile,
+	ReportHistoryPage,
+	ReportHistoryQuery,
+	ReportResult,
+	SideQuestEntry,
 } from "@lapor-bot/shared";
 import { HttpClient } from "../http/HttpClient";
-import type { GetTokenFn, OnUnauthorizedFn } from "../http/HttpClient";
+import type { GetTokenFn, OnUnauthorizedFn, RefreshTokenFn } from "../http/HttpClient";
 
 export class HttpReportRepository
 	extends HttpClient
 	implements IReportRepository
 {
-	constructor(baseURL: string = "", getToken: GetTokenFn = () => null, onUnauthorized?: OnUnauthorizedFn) {
-		super(baseURL, getToken, onUnauthorized);
+	constructor(
+		baseURL: string = "",
+		getToken: GetTokenFn = () => null,
+		onUnauthorized?: OnUnauthorizedFn,
+		refreshToken?: RefreshTokenFn,
+	) {
+		super(baseURL, getToken, onUnauthorized, refreshToken);
 	}
 
 	async getLeaderboard(): Promise<EnrichedReport[]> {
 		return this.get<EnrichedReport[]>("/api/leaderboard");
 	}
 
+	async getLeaderboardPage(query: LeaderboardQuery = {}): Promise<LeaderboardPage> {
+		const params = new URLSearchParams();
+		params.set("sort", query.sort ?? "season_rank");
+		if (query.job) params.set("job", query.job);
+		if (query.season) params.set("season", String(query.season));
+		if (query.search) params.set("search", query.search);
+		if (query.cursor) params.set("cursor", query.cursor);
+		if (query.limit) params.set("limit", String(query.limit));
+		return this.get<LeaderboardPage>(`/api/leaderboard?${params.toString()}`);
+	}
+
 	async getSummary(): Promise<GlobalSummary> {
 		return this.get<GlobalSummary>("/api/summary");
 	}
@@ -73,4 +99,40 @@ export class HttpReportRepository
 	async fetchUser(): Promise<EnrichedReport> {
 		return this.get<EnrichedReport>("/api/user");
 	}
+
+	async submitReport(activity: string): Promise<ReportResult> {
+		return this.post<ReportResult>("/api/user/reports", { activity });
+	}
+
+	async submitSideQuests(quests: SideQuestEntry[]): Promise<ReportResult> {
+		return this.post<ReportResult>("/api/user/sidequests", { quests });
+	}
+
+	async cancelReport(eventId: string): Promise<CancelReportResult> {
+		return this.delete<CancelReportResult>(
+			`/api/user/reports/${encodeURIComponent(eventId)}`,
+		);
+	}
+
+	async listReports(query: ReportHistoryQuery = {}): Promise<ReportHistoryPage> {
+		const params = new URLSearchParams();
+		if (query.before) params.set("before", query.before);
+		if (query.limit) params.set("limit", String(query.limit));
+		if (query.kind) params.set("kind", query.kind);
+		if (query.season) params.set("season", String(query.season));
+		const qs = params.toString();
+		return this.get<ReportHistoryPage>(`/api/user/reports${qs ? `?${qs}` : ""}`);
+	}
+
+	async getPublicProfile(key: string): Promise<PublicProfile> {
+		return this.get<PublicProfile>(`/api/profiles/${encodeURIComponent(key)}`);
+	}
+
+	async getProfileSettings(): Promise<ProfileSettings> {
+		return this.get<ProfileSettings>("/api/user/profile");
+	}
+
+	async updateProfileSettings(update: ProfileSettingsUpdate): Promise<ProfileSettings> {
+		return this.patch<ProfileSettings>("/api/user/profile", update);
+	}
 }
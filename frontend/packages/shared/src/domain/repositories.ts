@@ -1,14 +1,42 @@
-import type { EnrichedReport, GlobalSummary, JobInfo } from "../types";
+import type {
+	CancelReportResult,
+	EnrichedReport,
+	GlobalSummary,
+	JobInfo,
+	LeaderboardPage,
+	LeaderboardQuery,
+	ProfileSettings,
+	ProfileSettingsUpdate,
+	PublicProfile,
+	ReportHistoryPage,
+	ReportHistoryQuery,
+	ReportResult,
+	SideQuestEntry,
+} from "../types";
 
-export interface LoginResult {
+export interface LoginCodeResult {
+	status: string;
+	expires_at: string;
+	resend_at: string;
+}
+
+export interface SessionTokens {
 	token: string;
 	expires_at: string;
+	/** Single use: every refresh returns a new one. */
+	refresh_token: string;
+	refresh_expires_at: string;
+}
+
+export interface LoginResult extends SessionTokens {
 	user: { phone: string; name: string };
 	profile: EnrichedReport;
 }
 
 export interface IReportRepository {
 	getLeaderboard(): Promise<EnrichedReport[]>;
+	/** One ranked, filtered page of the leaderboard. */
+	getLeaderboardPage(query?: LeaderboardQuery): Promise<LeaderboardPage>;
 	getSummary(): Promise<GlobalSummary>;
 	updateName(name: string): Promise<{ success: boolean; message: string }>;
 	selectJob(jobId: string): Promise<{ success: boolean; message: string }>;
@@ -20,8 +48,29 @@ export interface IReportRepository {
 	listJobs(): Promise<JobInfo[]>;
 	fetchUser(): Promise<EnrichedReport>;
 	resetGoal?(): Promise<{ success: boolean; message: string }>;
+	/** Files today's report, like /lapor in the group. */
+	submitReport(activity: string): Promise<ReportResult>;
+	/** Reports finished side quests for today, like /lapor sidequest. */
+	submitSideQuests(quests: SideQuestEntry[]): Promise<ReportResult>;
+	/** Cancels the report with the given event ID. */
+	cancelReport(eventId: string): Promise<CancelReportResult>;
+	/** Pages through the user's reports, newest first. */
+	listReports(query?: ReportHistoryQuery): Promise<ReportHistoryPage>;
+	/** An opted-in hunter's public profile, by masked ID or handle. */
+	getPublicProfile(key: string): Promise<PublicProfile>;
+	getProfileSettings(): Promise<ProfileSettings>;
+	updateProfileSettings(update: ProfileSettingsUpdate): Promise<ProfileSettings>;
 }
 
 export interface IAuthRepository {
-	login(phone: string): Promise<LoginResult>;
+	/** Asks the bot to DM a one-time login code to the number. */
+	requestCode(phone: string): Promise<LoginCodeResult>;
+	/** Exchanges the code from the bot's DM for a session. */
+	login(phone: string, code: string): Promise<LoginResult>;
+	/** Exchanges the token from a /dashboard link for a session. */
+	loginWithLink(token: string): Promise<LoginResult>;
+	/** Swaps a refresh token for a new access token and refresh token. */
+	refresh(refreshToken: string): Promise<SessionTokens>;
+	/** Ends the session the refresh token belongs to. */
+	logout(refreshToken: string): Promise<void>;
 }
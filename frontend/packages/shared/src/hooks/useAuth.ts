@@ -1,17 +1,35 @@
 import { useState } from 'react';
 import { useAuthContext } from '../providers/AuthProvider';
 import type { EnrichedReport } from '../types';
+import type { LoginCodeResult } from '../domain/repositories';
 
 export const useAuth = () => {
-  const { login: ctxLogin, logout, user, token, isLoading: ctxLoading } = useAuthContext();
+  const { requestCode: ctxRequestCode, login: ctxLogin, loginWithLink: ctxLoginWithLink, logout, user, token, isLoading: ctxLoading } = useAuthContext();
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
 
-  const login = async (phone: string): Promise<EnrichedReport | null> => {
+  const requestCode = async (phone: string): Promise<LoginCodeResult | null> => {
     setLoading(true);
     setError(null);
     try {
-      const profile = await ctxLogin(phone);
+      return await ctxRequestCode(phone);
+    } catch (err: unknown) {
+      if (err instanceof Error) {
+        setError(err.message);
+      } else {
+        setError('Failed to send login code');
+      }
+      return null;
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const login = async (phone: string, code: string): Promise<EnrichedReport | null> => {
+    setLoading(true);
+    setError(null);
+    try {
+      const profile = await ctxLogin(phone, code);
       return profile;
     } catch (err: unknown) {
       if (err instanceof Error) {
@@ -25,5 +43,22 @@ export const useAuth = () => {
     }
   };
 
-  return { login, logout, user, token, loading: loading || ctxLoading, error };
+  const loginWithLink = async (linkToken: string): Promise<EnrichedReport | null> => {
+    setLoading(true);
+    setError(null);
+    try {
+      return await ctxLoginWithLink(linkToken);
+    } catch (err: unknown) {
+      if (err instanceof Error) {
+        setError(err.message);
+      } else {
+        setError('Login link is invalid or has expired');
+      }
+      return null;
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return { requestCode, login, loginWithLink, logout, user, token, loading: loading || ctxLoading, error };
 };
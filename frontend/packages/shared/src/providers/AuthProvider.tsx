@@ -1,10 +1,11 @@
 import React, { createContext, useContext, useState, useCallback, useEffect } from 'react';
 import type { ReactNode } from 'react';
 import type { EnrichedReport } from '../types';
-import type { IAuthRepository, LoginResult } from '../domain/repositories';
+import type { IAuthRepository, LoginCodeResult, LoginResult } from '../domain/repositories';
 
 const TOKEN_KEY = 'lapor-bot-token';
 const PROFILE_KEY = 'lapor-bot-profile';
+const REFRESH_KEY = 'lapor-bot-refresh-token';
 
 function loadToken(): string | null {
 	try {
@@ -23,9 +24,18 @@ function loadProfile(): EnrichedReport | null {
 	}
 }
 
-function saveSession(token: string, profile: EnrichedReport): void {
+function loadRefreshToken(): string | null {
+	try {
+		return localStorage.getItem(REFRESH_KEY);
+	} catch {
+		return null;
+	}
+}
+
+function saveSession(token: string, refreshToken: string, profile: EnrichedReport): void {
 	try {
 		localStorage.setItem(TOKEN_KEY, token);
+		localStorage.setItem(REFRESH_KEY, refreshToken);
 		localStorage.setItem(PROFILE_KEY, JSON.stringify(profile));
 	} catch {
 		// storage full or unavailable — ignore
@@ -35,6 +45,7 @@ function saveSession(token: string, profile: EnrichedReport): void {
 function clearSession(): void {
 	try {
 		localStorage.removeItem(TOKEN_KEY);
+		localStorage.removeItem(REFRESH_KEY);
 		localStorage.removeItem(PROFILE_KEY);
 	} catch {
 		// ignore
@@ -45,7 +56,9 @@ export interface AuthState {
 	token: string | null;
 	user: EnrichedReport | null;
 	isLoading: boolean;
-	login: (phone: string) => Promise<EnrichedReport>;
+	requestCode: (phone: string) => Promise<LoginCodeResult>;
+	login: (phone: string, code: string) => Promise<EnrichedReport>;
+	loginWithLink: (linkToken: string) => Promise<EnrichedReport>;
 	logout: () => void;
 	getToken: () => string | null;
 }
@@ -65,25 +78,49 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ authRepo, children,
 
 	const getToken = useCallback(() => token, [token]);
 
-	const login = useCallback(async (phone: string): Promise<EnrichedReport> => {
+	const requestCode = useCallback(async (phone: string): Promise<LoginCodeResult> => {
+		setIsLoading(true);
+		try {
+			return await authRepo.requestCode(phone);
+		} finally {
+			setIsLoading(false);
+		}
+	}, [authRepo]);
+
+	const startSession = useCallback(async (pending: Promise<LoginResult>): Promise<EnrichedReport> => {
 		setIsLoading(true);
 		try {
-			const result: LoginResult = await authRepo.login(phone);
+			const result = await pending;
 			setToken(result.token);
 			setUser(result.profile);
-			saveSession(result.token, result.profile);
+			saveSession(result.token, result.refresh_token, result.profile);
 			return result.profile;
 		} finally {
 			setIsLoading(false);
 		}
-	}, [authRepo]);
+	}, []);
+
+	const login = useCallback(
+		(phone: string, code: string) => startSession(authRepo.login(phone, code)),
+		[authRepo, startSession],
+	);
+
+	const loginWithLink = useCallback(
+		(linkToken: string) => startSession(authRepo.loginWithLink(linkToken)),
+		[authRepo, startSession],
+	);
 
 	const logout = useCallback(() => {
+		const refreshToken = loadRefreshToken();
+		if (refreshToken) {
+			// Best effort: the local session is cleared either way.
+			authRepo.logout(refreshToken).catch(() => {});
+		}
 		setToken(null);
 		setUser(null);
 		clearSession();
 		onUnauthorized?.();
-	}, [onUnauthorized]);
+	}, [authRepo, onUnauthorized]);
 
 	// Sync token across tabs
 	useEffect(() => {
@@ -100,7 +137,7 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ authRepo, children,
 	}, []);
 
 	return (
-		<AuthContext.Provider value={{ token, user, isLoading, login, logout, getToken }}>
+		<AuthContext.Provider value={{ token, user, isLoading, requestCode, login, loginWithLink, logout, getToken }}>
 			{children}
 		</AuthContext.Provider>
 	);
@@ -65,6 +65,8 @@ export interface EnrichedReport {
   active_days_in_window?: number;
   active_goal?: PersonalGoal;
   today_side_quests?: QuestTask[];
+  /** Position on a ranked leaderboard page. */
+  rank?: number;
 }
 
 export interface DailyActivity {
@@ -144,3 +146,182 @@ export type LeaderboardSortKey =
   | "attribute_sta"
   | "attribute_agi"
   | "attribute_vit";
+
+// Mirror of usecase.UserReportResult: what a report filed from the web or
+// app changed. When accepted is false, reply carries the bot's reason.
+export interface ReportBadge {
+  id: string;
+  name: string;
+  emoji: string;
+  points: number;
+  comeback: boolean;
+}
+
+// Mirror of usecase.ReportPoints: how a report's points add up.
+export interface ReportPointsBreakdown {
+  base: number;
+  weekly_streak_bonus: number;
+  daily_streak_bonus: number;
+  season_first_bonus: number;
+  repeat_halved: boolean;
+  report: number;
+  badges: number;
+  total: number;
+}
+
+export interface ReportAttributeGain {
+  attribute: "STR" | "STA" | "AGI" | "VIT";
+  points: number;
+}
+
+export interface ReportResult {
+  accepted: boolean;
+  rejection?: string;
+  reply?: string;
+  event_id?: string;
+  kind: "regular_report" | "sidequest";
+  activity_date?: string;
+  points: number;
+  breakdown: ReportPointsBreakdown;
+  attributes: ReportAttributeGain[];
+  total_points: number;
+  seasonal_points: number;
+  level: number;
+  previous_level: number;
+  tier?: string;
+  previous_tier?: string;
+  rank?: string;
+  previous_rank?: string;
+  streak: number;
+  freeze_used: boolean;
+  goal_completed: boolean;
+  badges: ReportBadge[];
+}
+
+export interface SideQuestEntry {
+  task: string;
+  amount: number;
+}
+
+// Mirror of usecase.ReportHistoryEntry: one report as it was filed.
+export interface ReportHistoryEntry {
+  event_id: string;
+  kind: "regular_report" | "sidequest";
+  season: number;
+  activity_date: string;
+  occurred_at: string;
+  text: string;
+  /** "whatsapp", "strava", "web", "file" or "integration:<id>". */
+  source: string;
+  points: number;
+  side_quests: number;
+  attributes: ReportAttributeGain[];
+  cancelled: boolean;
+  cancelled_at?: string;
+}
+
+export interface ReportHistoryPage {
+  entries: ReportHistoryEntry[];
+  /** Pass as `before` for the next page; absent on the last page. */
+  next_cursor?: string;
+}
+
+export interface ReportHistoryQuery {
+  before?: string;
+  limit?: number;
+  kind?: "regular_report" | "sidequest";
+  season?: number;
+}
+
+// Mirror of http.LeaderboardPage: one ranked page of /api/leaderboard.
+export interface LeaderboardPage {
+  entries: EnrichedReport[];
+  total: number;
+  /** Pass as `cursor` for the next page; absent on the last page. */
+  next_cursor?: string;
+  sort: LeaderboardSortKey;
+  season: number;
+}
+
+export interface LeaderboardQuery {
+  sort?: LeaderboardSortKey;
+  /** A job class ID, or "all". */
+  job?: string;
+  /** An earlier season; omit for the running one. */
+  season?: number;
+  search?: string;
+  cursor?: string;
+  limit?: number;
+}
+
+// Event names on GET /api/stream and /api/user/stream (server-sent events).
+export type StreamEventType =
+  | "report.accepted"
+  | "report.cancelled"
+  | "achievement.unlocked"
+  | "level.up"
+  | "rank.up"
+  | "goal.completed"
+  | "raid.defeated"
+  | "raid.rewarded"
+  | "season.reset"
+  | "streak.broken"
+  /** The events since Last-Event-ID were lost; reload the leaderboard. */
+  | "resync";
+
+// Mirror of http.streamMessage: the data of one stream event.
+export interface StreamMessage {
+  type: StreamEventType;
+  occurred_at: string;
+  /** Masked like EnrichedReport.user_id. */
+  user_id?: string;
+  name?: string;
+  /** The subscriber's own event, with the full event data. */
+  personal?: boolean;
+  data?: Record<string, unknown>;
+}
+
+// Mirror of http.PublicProfileResponse: GET /api/profiles/{key}, where key
+// is a masked user_id or a handle.
+export interface PublicProfile {
+  public_id: string;
+  handle?: string;
+  /** Shareable page with OpenGraph tags for link previews. */
+  url: string;
+  /** The hunter card PNG. */
+  card_url: string;
+  /** Masked; daily_activity covers the last 12 weeks. */
+  hunter: EnrichedReport;
+  top_badges: ProfileBadge[];
+}
+
+export interface ProfileBadge {
+  id: string;
+  name: string;
+  emoji: string;
+}
+
+// Mirror of http.ProfileSettings: GET/PATCH /api/user/profile.
+export interface ProfileSettings {
+  public_id: string;
+  handle?: string;
+  enabled: boolean;
+  updated_at: string;
+  url: string;
+  card_url: string;
+}
+
+export interface ProfileSettingsUpdate {
+  enabled?: boolean;
+  /** 3-20 lowercase letters, digits or underscores; "" releases it. */
+  handle?: string;
+}
+
+export interface CancelReportResult {
+  event_id: string;
+  kind: "regular_report" | "sidequest";
+  activity_date: string;
+  remaining: number;
+  total_points: number;
+  seasonal_points: number;
+}
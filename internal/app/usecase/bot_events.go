@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// BotEventPublisher receives bot events as they happen, e.g. to queue
+// outbound webhooks. It runs inside the report flow, so it must not block
+// for long.
+type BotEventPublisher func(ctx context.Context, event domain.BotEvent)
+
+// PublishToAll returns a publisher that hands every event to each of
+// publishers in turn.
+func PublishToAll(publishers ...BotEventPublisher) BotEventPublisher {
+	return func(ctx context.Context, event domain.BotEvent) {
+		for _, publish := range publishers {
+			publish(ctx, event)
+		}
+	}
+}
+
+// publishReportEvents emits the events for an accepted report: the report
+// itself, then whatever it unlocked.
+func (uc *ReportActivityUsecase) publishReportEvents(ctx context.Context, o ReportOutcome) {
+	if uc.eventPublisher == nil {
+		return
+	}
+	report := o.Report
+	source := o.Source
+	if source == "" {
+		source = "whatsapp"
+	}
+	event := func(eventType string, data map[string]any) domain.BotEvent {
+		return domain.BotEvent{Type: eventType, OccurredAt: o.OccurredAt, UserID: o.UserID, Name: o.Name, Data: data}
+	}
+
+	if o.BrokenStreak > 0 {
+		uc.eventPublisher(ctx, event(domain.BotEventStreakBroken, map[string]any{
+			"previous_streak": o.BrokenStreak,
+			"inactive_days":   report.InactiveDays,
+		}))
+	}
+	uc.eventPublisher(ctx, event(domain.BotEventReportAccepted, map[string]any{
+		"event_key":       o.EventKey,
+		"kind":            o.Kind,
+		"source":          source,
+		"late":            o.Late,
+		"activity_date":   o.ActivityDate.Format(time.DateOnly),
+		"points":          o.Points.Total,
+		"breakdown":       o.Points,
+		"attributes":      o.AttributeGains,
+		"total_points":    report.TotalPoints,
+		"seasonal_points": report.SeasonalPoints,
+		"level":           report.Level,
+		"streak":          report.Streak,
+	}))
+	if o.GoalCompleted {
+		uc.eventPublisher(ctx, event(domain.BotEventGoalCompleted, map[string]any{
+			"event_key":       o.EventKey,
+			"goals_completed": o.GoalsCompleted,
+		}))
+	}
+	for _, ach := range o.Badges {
+		uc.eventPublisher(ctx, event(domain.BotEventAchievementUnlocked, map[string]any{
+			"event_key":      o.EventKey,
+			"achievement_id": ach.ID,
+			"name":           ach.Name,
+			"points":         ach.Points,
+			"comeback":       false,
+		}))
+	}
+	for _, ach := range o.ComebackBadges {
+		uc.eventPublisher(ctx, event(domain.BotEventAchievementUnlocked, map[string]any{
+			"event_key":      o.EventKey,
+			"achievement_id": ach.ID,
+			"name":           ach.Name,
+			"points":         ach.Points,
+			"comeback":       true,
+		}))
+	}
+	if o.LeveledUp() {
+		uc.eventPublisher(ctx, event(domain.BotEventLevelUp, map[string]any{
+			"event_key": o.EventKey,
+			"from":      o.OldLevel,
+			"to":        report.Level,
+		}))
+	}
+	if tier := o.NewTier(); tier.Tier > o.OldTier.Tier {
+		uc.eventPublisher(ctx, event(domain.BotEventRankUp, map[string]any{
+			"event_key": o.EventKey,
+			"scope":     "lifetime",
+			"from":      o.OldTier.Name,
+			"to":        tier.Name,
+		}))
+	}
+	if rank := o.NewRank(); rank.Tier > o.OldRank.Tier {
+		uc.eventPublisher(ctx, event(domain.BotEventRankUp, map[string]any{
+			"event_key": o.EventKey,
+			"scope":     "season",
+			"from":      o.OldRank.Name,
+			"to":        rank.Name,
+		}))
+	}
+}
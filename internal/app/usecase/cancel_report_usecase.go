@@ -3,104 +3,313 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"log"
 	"math"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
 )
 
+// CancelledEventHook undoes what a report event caused outside the report
+// itself once the event is cancelled, such as the damage it dealt a raid
+// boss. Failures are the hook's to log: the report is already cancelled.
+type CancelledEventHook func(ctx context.Context, userID, eventID string)
+
 type CancelReportUsecase struct {
-	repo domain.ReportRepository
+	repo            domain.ReportRepository
+	eventPublisher  BotEventPublisher
+	raidReverter    CancelledEventHook
+	recordRetractor CancelledEventHook
+	userLock        func(userID string) *sync.Mutex
+	locks           sync.Map
 }
 
 func NewCancelReportUsecase(repo domain.ReportRepository) *CancelReportUsecase {
 	return &CancelReportUsecase{repo: repo}
 }
 
+// SetEventPublisher sets the hook that is told about cancelled reports.
+func (uc *CancelReportUsecase) SetEventPublisher(fn BotEventPublisher) {
+	uc.eventPublisher = fn
+}
+
+func (uc *CancelReportUsecase) publishCancelled(ctx context.Context, report *domain.Report, kind string, activityDate time.Time, cancelled, remaining int) {
+	if uc.eventPublisher == nil {
+		return
+	}
+	uc.eventPublisher(ctx, domain.BotEvent{
+		Type:       domain.BotEventReportCancelled,
+		OccurredAt: time.Now(),
+		UserID:     report.UserID,
+		Name:       report.Name,
+		Data: map[string]any{
+			"kind":            kind,
+			"activity_date":   activityDate.Format(time.DateOnly),
+			"cancelled":       cancelled,
+			"remaining":       remaining,
+			"total_points":    report.TotalPoints,
+			"seasonal_points": report.SeasonalPoints,
+		},
+	})
+}
+
+// SetRaidReverter sets the hook that takes back the raid damage of
+// cancelled reports, so cancelling and reporting again can't stack hits.
+func (uc *CancelReportUsecase) SetRaidReverter(fn CancelledEventHook) {
+	uc.raidReverter = fn
+}
+
+// SetRecordRetractor sets the hook that drops the personal records set by
+// cancelled reports.
+func (uc *CancelReportUsecase) SetRecordRetractor(fn CancelledEventHook) {
+	uc.recordRetractor = fn
+}
+
+// SetUserLock shares the per-user lock reports are filed under, so a
+// cancellation can't interleave with a report or bonus of the same user.
+// Without it, cancellations only exclude each other.
+func (uc *CancelReportUsecase) SetUserLock(fn func(userID string) *sync.Mutex) {
+	uc.userLock = fn
+}
+
+// lockUser takes userID's lock unless ctx already runs under it, and
+// returns the context to run under it and the unlock.
+func (uc *CancelReportUsecase) lockUser(ctx context.Context, userID string) (context.Context, func()) {
+	if holdsUserLock(ctx, userID) {
+		return ctx, func() {}
+	}
+	var lock *sync.Mutex
+	if uc.userLock != nil {
+		lock = uc.userLock(userID)
+	} else {
+		l, _ := uc.locks.LoadOrStore(userID, &sync.Mutex{})
+		lock = l.(*sync.Mutex)
+	}
+	lock.Lock()
+	return withUserLockHeld(ctx, userID), lock.Unlock
+}
+
+// claimEvent marks event cancelled before anything it added is undone, so
+// of two cancellations racing for it only one takes its points back. It
+// reports false when the event was already cancelled.
+func (uc *CancelReportUsecase) claimEvent(ctx context.Context, event *domain.ReportActivityEvent) (bool, error) {
+	if event == nil {
+		return true, nil
+	}
+	// A ledger event was found, so the repository keeps the ledger.
+	events := uc.repo.(domain.ReportEventRepository)
+	return events.CancelReportEvent(ctx, event.UserID, event.EventID, time.Now())
+}
+
+// retireEvents marks the ledger events behind a cancellation: the day's
+// newest until count are marked (all of them when count is zero), with
+// claimedID, the event claimed up front, counting as the first. The counts
+// are already undone at this point, so a failure here is only logged.
+func (uc *CancelReportUsecase) retireEvents(ctx context.Context, userID, kind string, day time.Time, count int, claimedID string) {
+	events, ok := uc.repo.(domain.ReportEventRepository)
+	if !ok {
+		return
+	}
+	var retired []string
+	defer func() { uc.undoEventEffects(ctx, userID, retired) }()
+	if claimedID != "" {
+		retired = append(retired, claimedID)
+		if count == 1 {
+			return
+		}
+	}
+	latest, err := events.CancelLatestReportEvents(ctx, userID, kind, day, count, time.Now())
+	if err != nil {
+		log.Printf("Failed to mark %s events of %s cancelled for %s: %v", kind, day.Format(time.DateOnly), userID, err)
+		return
+	}
+	retired = append(retired, latest...)
+}
+
+// undoEventEffects runs the cancellation hooks for each retired event.
+func (uc *CancelReportUsecase) undoEventEffects(ctx context.Context, userID string, eventIDs []string) {
+	for _, eventID := range eventIDs {
+		if uc.raidReverter != nil {
+			uc.raidReverter(ctx, userID, eventID)
+		}
+		if uc.recordRetractor != nil {
+			uc.recordRetractor(ctx, userID, eventID)
+		}
+	}
+}
+
 func (uc *CancelReportUsecase) Execute(ctx context.Context, userID, name string) (string, error) {
-	return uc.cancelToday(ctx, userID, name, domain.ActivityKindRegularReport, false)
+	return uc.render(uc.cancelOn(ctx, userID, name, domain.ActivityKindRegularReport, domain.GetToday(time.Now()), false, ""))
 }
 
 func (uc *CancelReportUsecase) ExecuteAll(ctx context.Context, userID, name string) (string, error) {
-	return uc.cancelToday(ctx, userID, name, domain.ActivityKindRegularReport, true)
+	return uc.render(uc.cancelOn(ctx, userID, name, domain.ActivityKindRegularReport, domain.GetToday(time.Now()), true, ""))
 }
 
 func (uc *CancelReportUsecase) ExecuteSideQuest(ctx context.Context, userID, name string) (string, error) {
-	return uc.cancelToday(ctx, userID, name, domain.ActivityKindSideQuest, false)
+	return uc.render(uc.cancelOn(ctx, userID, name, domain.ActivityKindSideQuest, domain.GetToday(time.Now()), false, ""))
 }
 
 func (uc *CancelReportUsecase) ExecuteAllSideQuest(ctx context.Context, userID, name string) (string, error) {
-	return uc.cancelToday(ctx, userID, name, domain.ActivityKindSideQuest, true)
+	return uc.render(uc.cancelOn(ctx, userID, name, domain.ActivityKindSideQuest, domain.GetToday(time.Now()), true, ""))
 }
 
-func (uc *CancelReportUsecase) cancelToday(ctx context.Context, userID, name string, kind string, all bool) (string, error) {
-	report, err := uc.repo.GetReport(ctx, userID)
+// CancelActivity cancels the regular report of eventID on activityDate, or
+// the day's latest when eventID is empty. Integrations use it when the
+// activity behind a report is deleted or no longer counts.
+func (uc *CancelReportUsecase) CancelActivity(ctx context.Context, userID, name string, activityDate time.Time, eventID string) (string, error) {
+	return uc.render(uc.cancelOn(ctx, userID, name, domain.ActivityKindRegularReport, activityDate, false, eventID))
+}
+
+// Cancel cancels today's latest report of kind, or all of them, and returns
+// what was undone instead of the bot's reply.
+func (uc *CancelReportUsecase) Cancel(ctx context.Context, userID, name, kind string, all bool) (CancelOutcome, error) {
+	return uc.cancelOn(ctx, userID, name, kind, domain.GetToday(time.Now()), all, "")
+}
+
+func (uc *CancelReportUsecase) render(outcome CancelOutcome, err error) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	return RenderCancelOutcome(outcome), nil
+}
 
-	if report == nil {
-		return fmt.Sprintf("Halo %s, kamu belum pernah laporan. Belum ada yang bisa dibatalkan.", name), nil
+// Reasons a cancellation finds nothing to undo.
+const (
+	CancelRejectedNeverReported = "never_reported"
+	CancelRejectedNothingToday  = "nothing_today"
+	CancelRejectedNoActivity    = "no_activity"
+	// CancelRejectedAlreadyCancelled means the requested report event was
+	// cancelled already, possibly by a cancellation running alongside.
+	CancelRejectedAlreadyCancelled = "already_cancelled"
+)
+
+// CancelOutcome is what a cancellation undid. Cancelled is zero and
+// Rejection says why when there was nothing to cancel.
+type CancelOutcome struct {
+	Rejection    string
+	Name         string
+	Kind         string
+	ActivityDate time.Time
+	// All is set when every report of Kind on the day was asked to go, as
+	// with /cancel-all.
+	All       bool
+	Cancelled int
+	// Remaining is how many reports of Kind the day still has.
+	Remaining int
+	// Report is the user's report after the cancellation.
+	Report *domain.Report
+}
+
+// cancelOn cancels the latest report of kind on today, or all of them.
+// eventID, when set, is the ledger event the caller asked to cancel: it is
+// marked instead of the day's newest one, and the counts and points it
+// added are taken back rather than a repeat report's. It runs under the
+// user's lock.
+func (uc *CancelReportUsecase) cancelOn(ctx context.Context, userID, name string, kind string, today time.Time, all bool, eventID string) (CancelOutcome, error) {
+	ctx, unlock := uc.lockUser(ctx, userID)
+	defer unlock()
+	return uc.cancelDay(ctx, userID, name, kind, today, all, eventID, false)
+}
+
+// cancelDay does cancelOn's work. claimed is set once eventID has been
+// marked cancelled.
+func (uc *CancelReportUsecase) cancelDay(ctx context.Context, userID, name string, kind string, today time.Time, all bool, eventID string, claimed bool) (CancelOutcome, error) {
+	report, err := uc.repo.GetReport(ctx, userID)
+	if err != nil {
+		return CancelOutcome{}, err
+	}
+	event, err := uc.reportEvent(ctx, userID, eventID)
+	if err != nil {
+		return CancelOutcome{}, err
 	}
 
-	now := time.Now()
-	today := domain.GetToday(now)
+	outcome := CancelOutcome{Name: name, Kind: kind, ActivityDate: today, All: all}
+	if report == nil {
+		outcome.Rejection = CancelRejectedNeverReported
+		return outcome, nil
+	}
+	outcome.Name = report.Name
 
 	dailyCount, err := uc.repo.GetDailyActivityCountByKind(ctx, userID, today, kind)
 	if err != nil {
-		return "", err
+		return CancelOutcome{}, err
 	}
 	if dailyCount == 0 {
-		return fmt.Sprintf("Halo %s, tidak menemukan %s untuk hari ini.", report.Name, cancelItemLabel(kind)), nil
+		outcome.Rejection = CancelRejectedNothingToday
+		return outcome, nil
 	}
 
 	if kind == domain.ActivityKindSideQuest {
-		return uc.cancelSideQuestToday(ctx, report, today, dailyCount, all)
+		return uc.cancelSideQuestToday(ctx, report, today, dailyCount, all, event)
 	}
 
 	dates, err := uc.repo.GetUserActivityDatesByKind(ctx, userID, kind)
 	if err != nil {
-		return "", err
+		return CancelOutcome{}, err
 	}
 	if len(dates) == 0 {
-		return fmt.Sprintf("Halo %s, tidak ada aktivitas yang tercatat.", report.Name), nil
+		outcome.Rejection = CancelRejectedNoActivity
+		return outcome, nil
 	}
 
 	if !containsDate(dates, today) {
-		return fmt.Sprintf("Halo %s, tidak menemukan laporan untuk hari ini.", report.Name), nil
+		outcome.Rejection = CancelRejectedNothingToday
+		return outcome, nil
+	}
+
+	claimedID := ""
+	if event != nil {
+		if !claimed {
+			ok, err := uc.claimEvent(ctx, event)
+			if err != nil {
+				return CancelOutcome{}, err
+			}
+			if !ok {
+				outcome.Rejection = CancelRejectedAlreadyCancelled
+				return outcome, nil
+			}
+		}
+		claimedID = event.EventID
 	}
 
 	if !all && dailyCount > 1 {
 		remainingReports, err := uc.repo.DeleteLatestActivityLogByKind(ctx, userID, today, kind)
 		if err != nil {
-			return "", err
+			return CancelOutcome{}, err
 		}
 		if remainingReports == 0 {
-			return uc.cancelToday(ctx, userID, name, kind, true)
+			return uc.cancelDay(ctx, userID, name, kind, today, true, eventID, true)
 		}
 
-		removeRepeatReportPoints(report)
+		if event != nil {
+			removeEventPoints(report, event, time.Now())
+		} else {
+			removeRepeatReportPoints(report)
+		}
 		if err := uc.repo.UpsertReport(ctx, report); err != nil {
-			return "", err
+			return CancelOutcome{}, err
 		}
+		uc.retireEvents(ctx, userID, kind, today, 1, claimedID)
+		uc.publishCancelled(ctx, report, kind, today, 1, remainingReports)
 
-		msg := fmt.Sprintf("✅ Laporan terakhir hari ini telah dibatalkan, %s.\n\n", report.Name)
-		msg += fmt.Sprintf("📌 Sisa laporan hari ini: %d/%d\n", remainingReports, MaxDailyReports)
-		msg += fmt.Sprintf("📅 Total hari aktif tetap: %d\n", report.ActivityCount)
-		msg += fmt.Sprintf("⭐ Total poin sekarang: %d\n", report.TotalPoints)
-		msg += "\nKalau ingin menghapus semua laporan hari ini, ketik /cancel-all."
-		return msg, nil
+		outcome.Cancelled = 1
+		outcome.Remaining = remainingReports
+		outcome.Report = report
+		return outcome, nil
 	}
 
 	remainingDates := removeDate(dates, today)
 	if len(remainingDates) == len(dates) {
-		return fmt.Sprintf("Halo %s, tidak menemukan laporan untuk hari ini.", report.Name), nil
+		outcome.Rejection = CancelRejectedNothingToday
+		return outcome, nil
 	}
 
 	if err := uc.repo.DeleteActivityLogByKind(ctx, userID, today, kind); err != nil {
-		return "", err
+		return CancelOutcome{}, err
 	}
 
 	var newReport *domain.Report
@@ -124,60 +333,138 @@ func (uc *CancelReportUsecase) cancelToday(ctx context.Context, userID, name str
 	preserveNonReportFields(newReport, report)
 
 	if err := uc.repo.UpsertReport(ctx, newReport); err != nil {
-		return "", err
-	}
-
-	msg := fmt.Sprintf("✅ Semua laporan hari ini telah dibatalkan, %s.\n\n", report.Name)
-	if !all {
-		msg = fmt.Sprintf("✅ Laporan hari ini telah dibatalkan, %s.\n\n", report.Name)
+		return CancelOutcome{}, err
 	}
-	msg += fmt.Sprintf("📅 Total hari aktif: %d\n", newReport.ActivityCount)
-	msg += fmt.Sprintf("🔥 Streak saat ini: %d minggu\n", newReport.Streak)
-	msg += fmt.Sprintf("⭐ Total poin: %d\n", newReport.TotalPoints)
+	uc.retireEvents(ctx, userID, kind, today, 0, claimedID)
+	uc.publishCancelled(ctx, newReport, kind, today, dailyCount, 0)
 
-	if newReport.InactiveDays > 0 {
-		msg += fmt.Sprintf("🔄 Comeback streak: %d minggu\n", newReport.ComebackStreak)
-	}
-
-	msg += "\n_Kamu bisa lapor lagi hari ini dengan /lapor._"
-	return msg, nil
+	outcome.Cancelled = dailyCount
+	outcome.Report = newReport
+	return outcome, nil
 }
 
-func (uc *CancelReportUsecase) cancelSideQuestToday(ctx context.Context, report *domain.Report, today time.Time, dailyCount int, all bool) (string, error) {
+func (uc *CancelReportUsecase) cancelSideQuestToday(ctx context.Context, report *domain.Report, today time.Time, dailyCount int, all bool, event *domain.ReportActivityEvent) (CancelOutcome, error) {
+	outcome := CancelOutcome{Name: report.Name, Kind: domain.ActivityKindSideQuest, ActivityDate: today, All: all, Report: report}
+	eventID := ""
 	deletedCount := dailyCount
-	if !all && dailyCount > 1 {
-		remainingSideQuests, err := uc.repo.DeleteLatestActivityLogByKind(ctx, report.UserID, today, domain.ActivityKindSideQuest)
+	if event != nil {
+		ok, err := uc.claimEvent(ctx, event)
 		if err != nil {
-			return "", err
+			return CancelOutcome{}, err
 		}
+		if !ok {
+			outcome.Rejection = CancelRejectedAlreadyCancelled
+			outcome.Report = nil
+			return outcome, nil
+		}
+		eventID = event.EventID
+		removeEventPoints(report, event, time.Now())
+		// One event can cover several quests.
+		if event.SideQuestCountDelta < dailyCount {
+			deletedCount = max(event.SideQuestCountDelta, 1)
+		}
+	} else if !all && dailyCount > 1 {
 		deletedCount = 1
+	}
+	if deletedCount < dailyCount {
+		var remainingSideQuests int
+		for range deletedCount {
+			remaining, err := uc.repo.DeleteLatestActivityLogByKind(ctx, report.UserID, today, domain.ActivityKindSideQuest)
+			if err != nil {
+				return CancelOutcome{}, err
+			}
+			remainingSideQuests = remaining
+		}
 		decrementSideQuestCount(report, deletedCount)
 		if err := uc.repo.UpsertReport(ctx, report); err != nil {
-			return "", err
+			return CancelOutcome{}, err
 		}
+		uc.retireEvents(ctx, report.UserID, domain.ActivityKindSideQuest, today, 1, eventID)
+		uc.publishCancelled(ctx, report, domain.ActivityKindSideQuest, today, deletedCount, remainingSideQuests)
 
-		msg := fmt.Sprintf("✅ Side quest terakhir hari ini telah dibatalkan, %s.\n\n", report.Name)
-		msg += fmt.Sprintf("📌 Sisa side quest hari ini: %d/%d\n", remainingSideQuests, MaxDailySideQuests)
-		msg += fmt.Sprintf("🧩 Total side quest: %d\n", report.TotalSideQuests)
-		msg += "\nKalau ingin menghapus semua side quest hari ini, ketik /cancel-all sidequest."
-		return msg, nil
+		outcome.Cancelled = deletedCount
+		outcome.Remaining = remainingSideQuests
+		return outcome, nil
 	}
 
 	if err := uc.repo.DeleteActivityLogByKind(ctx, report.UserID, today, domain.ActivityKindSideQuest); err != nil {
-		return "", err
+		return CancelOutcome{}, err
 	}
 	decrementSideQuestCount(report, deletedCount)
 	if err := uc.repo.UpsertReport(ctx, report); err != nil {
-		return "", err
+		return CancelOutcome{}, err
+	}
+	uc.retireEvents(ctx, report.UserID, domain.ActivityKindSideQuest, today, 0, eventID)
+	uc.publishCancelled(ctx, report, domain.ActivityKindSideQuest, today, deletedCount, 0)
+
+	outcome.Cancelled = deletedCount
+	return outcome, nil
+}
+
+// reportEvent looks up the ledger event a cancellation is for; it is nil
+// when eventID is empty or the repository keeps no ledger.
+func (uc *CancelReportUsecase) reportEvent(ctx context.Context, userID, eventID string) (*domain.ReportActivityEvent, error) {
+	events, ok := uc.repo.(domain.ReportEventRepository)
+	if eventID == "" || !ok {
+		return nil, nil
+	}
+	return events.GetReportEvent(ctx, userID, eventID)
+}
+
+// RenderCancelOutcome writes the bot's WhatsApp reply for a cancellation.
+func RenderCancelOutcome(o CancelOutcome) string {
+	switch o.Rejection {
+	case CancelRejectedNeverReported:
+		return fmt.Sprintf("Halo %s, kamu belum pernah laporan. Belum ada yang bisa dibatalkan.", o.Name)
+	case CancelRejectedNothingToday:
+		return fmt.Sprintf("Halo %s, tidak menemukan %s untuk hari ini.", o.Name, cancelItemLabel(o.Kind))
+	case CancelRejectedNoActivity:
+		return fmt.Sprintf("Halo %s, tidak ada aktivitas yang tercatat.", o.Name)
+	case CancelRejectedAlreadyCancelled:
+		return fmt.Sprintf("Halo %s, %s itu sudah dibatalkan.", o.Name, cancelItemLabel(o.Kind))
+	}
+
+	report := o.Report
+	if o.Kind == domain.ActivityKindSideQuest {
+		if o.Remaining > 0 {
+			msg := fmt.Sprintf("✅ Side quest terakhir hari ini telah dibatalkan, %s.\n\n", o.Name)
+			msg += fmt.Sprintf("📌 Sisa side quest hari ini: %d/%d\n", o.Remaining, MaxDailySideQuests)
+			msg += fmt.Sprintf("🧩 Total side quest: %d\n", report.TotalSideQuests)
+			msg += "\nKalau ingin menghapus semua side quest hari ini, ketik /cancel-all sidequest."
+			return msg
+		}
+		msg := fmt.Sprintf("✅ Semua side quest hari ini telah dibatalkan, %s.\n\n", o.Name)
+		if !o.All {
+			msg = fmt.Sprintf("✅ Side quest hari ini telah dibatalkan, %s.\n\n", o.Name)
+		}
+		msg += fmt.Sprintf("🧩 Total side quest: %d\n", report.TotalSideQuests)
+		msg += "\n_Kamu bisa lapor side quest lagi hari ini dengan /lapor sidequest._"
+		return msg
+	}
+
+	if o.Remaining > 0 {
+		msg := fmt.Sprintf("✅ Laporan terakhir hari ini telah dibatalkan, %s.\n\n", o.Name)
+		msg += fmt.Sprintf("📌 Sisa laporan hari ini: %d/%d\n", o.Remaining, MaxDailyReports)
+		msg += fmt.Sprintf("📅 Total hari aktif tetap: %d\n", report.ActivityCount)
+		msg += fmt.Sprintf("⭐ Total poin sekarang: %d\n", report.TotalPoints)
+		msg += "\nKalau ingin menghapus semua laporan hari ini, ketik /cancel-all."
+		return msg
+	}
+
+	msg := fmt.Sprintf("✅ Semua laporan hari ini telah dibatalkan, %s.\n\n", o.Name)
+	if !o.All {
+		msg = fmt.Sprintf("✅ Laporan hari ini telah dibatalkan, %s.\n\n", o.Name)
 	}
+	msg += fmt.Sprintf("📅 Total hari aktif: %d\n", report.ActivityCount)
+	msg += fmt.Sprintf("🔥 Streak saat ini: %d minggu\n", report.Streak)
+	msg += fmt.Sprintf("⭐ Total poin: %d\n", report.TotalPoints)
 
-	msg := fmt.Sprintf("✅ Semua side quest hari ini telah dibatalkan, %s.\n\n", report.Name)
-	if !all {
-		msg = fmt.Sprintf("✅ Side quest hari ini telah dibatalkan, %s.\n\n", report.Name)
+	if report.InactiveDays > 0 {
+		msg += fmt.Sprintf("🔄 Comeback streak: %d minggu\n", report.ComebackStreak)
 	}
-	msg += fmt.Sprintf("🧩 Total side quest: %d\n", report.TotalSideQuests)
-	msg += "\n_Kamu bisa lapor side quest lagi hari ini dengan /lapor sidequest._"
-	return msg, nil
+
+	msg += "\n_Kamu bisa lapor lagi hari ini dengan /lapor._"
+	return msg
 }
 
 func decrementSideQuestCount(report *domain.Report, count int) {
@@ -210,6 +497,22 @@ func preserveNonReportFields(next, current *domain.Report) {
 	next.Vit = current.Vit
 }
 
+// removeEventPoints takes back the points one report event earned. They
+// only count towards the seasonal points while its season is running.
+func removeEventPoints(report *domain.Report, event *domain.ReportActivityEvent, now time.Time) {
+	report.TotalPoints -= event.PointsDelta
+	if report.TotalPoints < 0 {
+		report.TotalPoints = 0
+	}
+	if season, _ := GetCurrentSessionInfo(now); event.SeasonNumber == season {
+		report.SeasonalPoints -= event.PointsDelta
+		if report.SeasonalPoints < 0 {
+			report.SeasonalPoints = 0
+		}
+	}
+	report.Level = domain.NumericLevelFromTotalPoints(report.TotalPoints)
+}
+
 func removeRepeatReportPoints(report *domain.Report) {
 	const repeatReportPoints = 5
 	report.TotalPoints -= repeatReportPoints
@@ -487,3 +487,44 @@ func TestCancelAllSideQuest_DeletesOnlySideQuestKind(t *testing.T) {
 		t.Fatalf("expected TotalSideQuests=0, got %d", repo.upsertedReport.TotalSideQuests)
 	}
 }
+
+func TestCancel_ReturnsOutcome(t *testing.T) {
+	today := domain.GetToday(time.Now())
+	repo := &cancelReportRepoStub{
+		report: &domain.Report{
+			UserID:         "user1",
+			Name:           "Budi",
+			LastReportDate: time.Now(),
+			ActivityCount:  1,
+			TotalPoints:    25,
+			SeasonalPoints: 25,
+		},
+		dates: []time.Time{today},
+		dailyCountByKind: map[string]int{
+			domain.ActivityKindRegularReport: 2,
+		},
+	}
+	uc := NewCancelReportUsecase(repo)
+
+	outcome, err := uc.Cancel(context.Background(), "user1", "Budi", domain.ActivityKindRegularReport, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outcome.Rejection != "" || outcome.Cancelled != 1 || outcome.Remaining != 1 || outcome.All {
+		t.Fatalf("expected one of two reports cancelled, got %+v", outcome)
+	}
+	if outcome.Report == nil || outcome.Report.TotalPoints != 20 || !outcome.ActivityDate.Equal(today) {
+		t.Fatalf("outcome should carry the updated report, got %+v", outcome)
+	}
+
+	outcome, err = uc.Cancel(context.Background(), "user1", "Budi", domain.ActivityKindSideQuest, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outcome.Rejection != CancelRejectedNothingToday || outcome.Cancelled != 0 {
+		t.Fatalf("expected nothing_today, got %+v", outcome)
+	}
+	if msg := RenderCancelOutcome(outcome); msg != "Halo Budi, tidak menemukan side quest untuk hari ini." {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+}
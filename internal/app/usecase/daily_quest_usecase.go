@@ -157,35 +157,82 @@ func (u *DailyQuestUsecase) ViewQuest(ctx context.Context, userID, name string,
 	return sb.String(), nil
 }
 
+// Reasons a side quest report is turned down.
+const (
+	SideQuestRejectedNotRegistered = "not_registered"
+	SideQuestRejectedNoJob         = "no_job"
+	SideQuestRejectedDailyLimit    = "daily_limit"
+	SideQuestRejectedAlreadyDone   = "already_done"
+	SideQuestRejectedIncomplete    = "incomplete"
+	SideQuestRejectedUnreadable    = "unreadable"
+	SideQuestRejectedNoSlots       = "not_enough_slots"
+)
+
+// SideQuestShortfall is a reported side quest that didn't count.
+type SideQuestShortfall struct {
+	// Input is what the user wrote when it matched none of today's quests;
+	// Task is then zero.
+	Input string
+	Task  domain.QuestTask
+	// Reported is the amount that was reported, e.g. "3.999 langkah".
+	Reported string
+}
+
+// SideQuestOutcome is what a side quest report did. Accepted means the
+// completed quests were saved and filed as a report; Report then says what
+// that report earned, and may itself be rejected by the daily limit.
+type SideQuestOutcome struct {
+	Accepted  bool
+	Rejection string
+	Name      string
+	// DoneTask is the quest that was already finished, for already_done.
+	DoneTask   string
+	SlotsLeft  int
+	Shortfalls []SideQuestShortfall
+	Completed  []string
+	Tasks      []domain.QuestTask
+	Report     ReportOutcome
+}
+
 // UpdateProgress updates the progressive reps or minutes for matched tasks.
 func (u *DailyQuestUsecase) UpdateProgress(ctx context.Context, userID, name string, inputLines []string, reportUC *ReportActivityUsecase, now time.Time) (string, error) {
-	report, err := u.repo.GetReport(ctx, userID)
+	outcome, err := u.updateProgress(ctx, userID, name, inputLines, reportUC, now, reportActivityOptions{})
 	if err != nil {
 		return "", err
 	}
+	return RenderSideQuestOutcome(outcome), nil
+}
+
+// updateProgress is UpdateProgress with the report options the completed
+// side quests are filed with.
+func (u *DailyQuestUsecase) updateProgress(ctx context.Context, userID, name string, inputLines []string, reportUC *ReportActivityUsecase, now time.Time, opts reportActivityOptions) (SideQuestOutcome, error) {
+	report, err := u.repo.GetReport(ctx, userID)
+	if err != nil {
+		return SideQuestOutcome{}, err
+	}
 
 	if report == nil {
-		return fmt.Sprintf("Halo %s, kamu belum terdaftar di database. Silakan lakukan laporan pertama dengan `/lapor` terlebih dahulu! 💪", name), nil
+		return SideQuestOutcome{Rejection: SideQuestRejectedNotRegistered, Name: name}, nil
 	}
 	if strings.TrimSpace(report.JobClass) == "" {
-		return "🔒 Side quest tersedia untuk profil yang sudah punya job. Untuk sementara, cek progres dan info profil di web: https://lapor-bot.web.id/", nil
+		return SideQuestOutcome{Rejection: SideQuestRejectedNoJob, Name: report.Name}, nil
 	}
 	today := domain.GetToday(now)
 	dailyCount, err := getDailySideQuestCount(ctx, u.repo, userID, today)
 	if err != nil {
-		return "", err
+		return SideQuestOutcome{}, err
 	}
 	if dailyCount >= MaxDailySideQuests {
-		return fmt.Sprintf("Batas side quest harian sudah penuh (%dx). Kamu tetap masih punya slot laporan utama terpisah sampai %dx hari ini. 🙏", MaxDailySideQuests, MaxDailyRegularReports), nil
+		return SideQuestOutcome{Rejection: SideQuestRejectedDailyLimit, Name: report.Name}, nil
 	}
 
 	tasks, err := u.GetOrGenerateQuestList(ctx, userID, report.JobClass, report.Level, now)
 	if err != nil {
-		return "", err
+		return SideQuestOutcome{}, err
 	}
 
 	var completedTasks []string
-	var rejected []string
+	var shortfalls []SideQuestShortfall
 	totalSideQuestPoints := 0
 
 	for _, line := range inputLines {
@@ -201,7 +248,7 @@ func (u *DailyQuestUsecase) UpdateProgress(ctx context.Context, userID, name str
 				added := 0
 				if task.ID == "easycardio" {
 					if !isEasyCardioComplete(namePart, val) {
-						rejected = append(rejected, fmt.Sprintf("%s butuh minimal jalan kaki 4000 langkah atau sepeda 5 km, laporanmu baru %s", task.Name, formatEasyCardioReport(namePart, val)))
+						shortfalls = append(shortfalls, SideQuestShortfall{Task: task, Reported: formatEasyCardioReport(namePart, val)})
 						continue
 					}
 					added = task.Target
@@ -216,10 +263,10 @@ func (u *DailyQuestUsecase) UpdateProgress(ctx context.Context, userID, name str
 				}
 
 				if task.Progress >= task.Target {
-					return fmt.Sprintf("%s sudah selesai hari ini. Pilih side quest lain di `/lapor sidequest` kalau masih mau lanjut. ✅", task.Name), nil
+					return SideQuestOutcome{Rejection: SideQuestRejectedAlreadyDone, Name: report.Name, DoneTask: task.Name}, nil
 				}
 				if added < task.Target {
-					rejected = append(rejected, fmt.Sprintf("%s butuh minimal %s, laporanmu baru %s", task.Name, formatQuestTarget(task), formatQuestValue(task, added)))
+					shortfalls = append(shortfalls, SideQuestShortfall{Task: task, Reported: formatQuestValue(task, added)})
 					continue
 				}
 
@@ -229,55 +276,86 @@ func (u *DailyQuestUsecase) UpdateProgress(ctx context.Context, userID, name str
 			}
 		}
 		if !matched {
-			rejected = append(rejected, fmt.Sprintf("%q tidak cocok dengan side quest hari ini", namePart))
+			shortfalls = append(shortfalls, SideQuestShortfall{Input: namePart})
 		}
 	}
 
 	if len(completedTasks) == 0 {
-		if len(rejected) > 0 {
-			return "💪 *Semangat! Tinggal sedikit lagi...* 🔥\n\n" + strings.Join(rejected, "\n") + "\n\nAyo lanjutkan sampai target lalu lapor ulang ya! Kamu pasti bisa! ✨\n\n📜 Cek detail target: `/lapor sidequest`\n📝 Lapor ulang: `/lapor sidequest <kegiatan> <jumlah>`", nil
+		if len(shortfalls) > 0 {
+			return SideQuestOutcome{Rejection: SideQuestRejectedIncomplete, Name: report.Name, Shortfalls: shortfalls}, nil
 		}
-		return "Gagal membaca laporan side quest. Contoh: `/lapor sidequest jalan 4000` atau `/lapor sidequest sepeda 5 km`", nil
+		return SideQuestOutcome{Rejection: SideQuestRejectedUnreadable, Name: report.Name}, nil
 	}
 	if dailyCount+len(completedTasks) > MaxDailySideQuests {
 		remaining := MaxDailySideQuests - dailyCount
 		if remaining < 0 {
 			remaining = 0
 		}
-		return fmt.Sprintf("Kamu hanya punya sisa %d slot side quest hari ini. Side quest dan laporan utama punya limit terpisah: masing-masing %d kali per hari. 🙏", remaining, MaxDailySideQuests), nil
+		return SideQuestOutcome{Rejection: SideQuestRejectedNoSlots, Name: report.Name, SlotsLeft: remaining}, nil
 	}
 
 	// Save updated tasks list
 	bytes, err := json.Marshal(tasks)
 	if err != nil {
-		return "", err
+		return SideQuestOutcome{}, err
 	}
 	todayStr := today.Format("2006-01-02")
 	if err := u.repo.SaveDailyQuest(ctx, userID, todayStr, string(bytes)); err != nil {
-		return "", err
+		return SideQuestOutcome{}, err
 	}
 
 	activityText := "Side quest: " + strings.Join(completedTasks, ", ")
-	reportResult, err := reportUC.ExecuteSideQuest(ctx, userID, name, activityText, len(completedTasks), totalSideQuestPoints, now)
+	reportResult, err := reportUC.executeSideQuest(ctx, userID, name, activityText, len(completedTasks), totalSideQuestPoints, now, opts)
 	if err != nil {
-		return "", err
+		return SideQuestOutcome{}, err
+	}
+	return SideQuestOutcome{
+		Accepted:   true,
+		Name:       report.Name,
+		SlotsLeft:  MaxDailySideQuests - dailyCount - len(completedTasks),
+		Shortfalls: shortfalls,
+		Completed:  completedTasks,
+		Tasks:      tasks,
+		Report:     reportResult,
+	}, nil
+}
+
+// RenderSideQuestOutcome writes the bot's WhatsApp reply for a side quest
+// report.
+func RenderSideQuestOutcome(o SideQuestOutcome) string {
+	switch o.Rejection {
+	case SideQuestRejectedNotRegistered:
+		return fmt.Sprintf("Halo %s, kamu belum terdaftar di database. Silakan lakukan laporan pertama dengan `/lapor` terlebih dahulu! 💪", o.Name)
+	case SideQuestRejectedNoJob:
+		return "🔒 Side quest tersedia untuk profil yang sudah punya job. Untuk sementara, cek progres dan info profil di web: https://lapor-bot.web.id/"
+	case SideQuestRejectedDailyLimit:
+		return fmt.Sprintf("Batas side quest harian sudah penuh (%dx). Kamu tetap masih punya slot laporan utama terpisah sampai %dx hari ini. 🙏", MaxDailySideQuests, MaxDailyRegularReports)
+	case SideQuestRejectedAlreadyDone:
+		return fmt.Sprintf("%s sudah selesai hari ini. Pilih side quest lain di `/lapor sidequest` kalau masih mau lanjut. ✅", o.DoneTask)
+	case SideQuestRejectedIncomplete:
+		lines := make([]string, 0, len(o.Shortfalls))
+		for _, shortfall := range o.Shortfalls {
+			lines = append(lines, formatSideQuestShortfall(shortfall))
+		}
+		return "💪 *Semangat! Tinggal sedikit lagi...* 🔥\n\n" + strings.Join(lines, "\n") + "\n\nAyo lanjutkan sampai target lalu lapor ulang ya! Kamu pasti bisa! ✨\n\n📜 Cek detail target: `/lapor sidequest`\n📝 Lapor ulang: `/lapor sidequest <kegiatan> <jumlah>`"
+	case SideQuestRejectedUnreadable:
+		return "Gagal membaca laporan side quest. Contoh: `/lapor sidequest jalan 4000` atau `/lapor sidequest sepeda 5 km`"
+	case SideQuestRejectedNoSlots:
+		return fmt.Sprintf("Kamu hanya punya sisa %d slot side quest hari ini. Side quest dan laporan utama punya limit terpisah: masing-masing %d kali per hari. 🙏", o.SlotsLeft, MaxDailySideQuests)
 	}
 
-	// Format response message
 	var sb strings.Builder
-	if len(completedTasks) > 0 {
-		sb.WriteString("🎉 *SIDE QUEST BERHASIL DISELESAIKAN!* 🏆\n\n")
-		sb.WriteString("Selamat, kamu menyelesaikan:\n")
-		for _, task := range tasks {
-			if task.Progress >= task.Target {
-				sb.WriteString(fmt.Sprintf("- *%s* (%s)\n", task.Name, formatQuestTarget(task)))
-			}
+	sb.WriteString("🎉 *SIDE QUEST BERHASIL DISELESAIKAN!* 🏆\n\n")
+	sb.WriteString("Selamat, kamu menyelesaikan:\n")
+	for _, task := range o.Tasks {
+		if task.Progress >= task.Target {
+			sb.WriteString(fmt.Sprintf("- *%s* (%s)\n", task.Name, formatQuestTarget(task)))
 		}
-		sb.WriteString("\n💰 Reward: XP bonus per side quest valid.\n\n")
 	}
+	sb.WriteString("\n💰 Reward: XP bonus per side quest valid.\n\n")
 
 	sb.WriteString("📜 *Daftar Quest Saat Ini:*\n")
-	for i, t := range tasks {
+	for i, t := range o.Tasks {
 		status := "⏳"
 		if t.Progress >= t.Target {
 			status = "✅"
@@ -285,11 +363,22 @@ func (u *DailyQuestUsecase) UpdateProgress(ctx context.Context, userID, name str
 		sb.WriteString(fmt.Sprintf("%s %d. %s\n", status, i+1, domain.FormatQuestProgressTask(t)))
 	}
 
-	sb.WriteString(fmt.Sprintf("\nProgress: %s\n", formatQuestProgressBar(tasks)))
+	sb.WriteString(fmt.Sprintf("\nProgress: %s\n", formatQuestProgressBar(o.Tasks)))
 	sb.WriteString("\n━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-	sb.WriteString(reportResult)
+	sb.WriteString(RenderReportOutcome(o.Report))
 
-	return sb.String(), nil
+	return sb.String()
+}
+
+func formatSideQuestShortfall(s SideQuestShortfall) string {
+	switch {
+	case s.Task.ID == "":
+		return fmt.Sprintf("%q tidak cocok dengan side quest hari ini", s.Input)
+	case s.Task.ID == "easycardio":
+		return fmt.Sprintf("%s butuh minimal jalan kaki 4000 langkah atau sepeda 5 km, laporanmu baru %s", s.Task.Name, s.Reported)
+	default:
+		return fmt.Sprintf("%s butuh minimal %s, laporanmu baru %s", s.Task.Name, formatQuestTarget(s.Task), s.Reported)
+	}
 }
 
 // sideQuestPoints returns the base points for a completed side quest task
@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const (
+	// eventStreamBufferSize is how many recent events a reconnecting
+	// dashboard can resume from.
+	eventStreamBufferSize = 512
+	// eventStreamQueueSize is how far a subscriber may fall behind before
+	// it is dropped; it resumes from the buffer when it reconnects.
+	eventStreamQueueSize = 64
+)
+
+// publicStreamData lists, per event type, the data fields anyone watching
+// the dashboard may see. Types not listed are only sent to the user the
+// event is about.
+var publicStreamData = map[string][]string{
+	domain.BotEventReportAccepted:      {"kind", "points", "attributes", "seasonal_points"},
+	domain.BotEventReportCancelled:     {"kind", "cancelled", "seasonal_points"},
+	domain.BotEventAchievementUnlocked: {"achievement_id", "name", "comeback"},
+	domain.BotEventLevelUp:             {"from", "to"},
+	domain.BotEventRankUp:              {"scope", "from", "to"},
+	domain.BotEventGoalCompleted:       {"goals_completed"},
+	domain.BotEventRaidDefeated:        {"boss_name", "boss_icon", "contributors", "reward"},
+	domain.BotEventRaidRewarded:        {"boss_name", "reward", "seasonal_points"},
+	domain.BotEventSeasonReset:         {"season"},
+}
+
+// StreamEvent is a bot event numbered for the dashboard stream. Event is
+// what the user it is about sees; Public is what everyone else sees, with
+// the user left for the caller to mask, or nil when it is private.
+type StreamEvent struct {
+	ID     uint64
+	Event  domain.BotEvent
+	Public map[string]any
+}
+
+// For reports whether the subscriber signed in as userID receives e. An
+// empty userID is an anonymous subscriber.
+func (e StreamEvent) For(userID string) bool {
+	return e.Public != nil || (userID != "" && e.Event.UserID == userID)
+}
+
+// StreamSubscription receives live events until it is closed. Events is
+// closed when the subscriber falls too far behind.
+type StreamSubscription struct {
+	userID string
+	events chan StreamEvent
+}
+
+func (s *StreamSubscription) Events() <-chan StreamEvent {
+	return s.events
+}
+
+// EventStreamUsecase fans bot events out to dashboard streams and keeps
+// the latest ones so a reconnecting stream can resume where it left off.
+// Event IDs start from the boot time, so IDs from before a restart are
+// older than the buffer and ask for a resync.
+type EventStreamUsecase struct {
+	mu          sync.Mutex
+	nextID      uint64
+	buffer      []StreamEvent
+	subscribers map[*StreamSubscription]struct{}
+}
+
+func NewEventStreamUsecase() *EventStreamUsecase {
+	return &EventStreamUsecase{
+		nextID:      uint64(time.Now().UnixMilli()),
+		subscribers: make(map[*StreamSubscription]struct{}),
+	}
+}
+
+// Publish numbers the event, buffers it and sends it to every subscriber
+// that receives it. It has the BotEventPublisher signature and never
+// blocks on a slow subscriber.
+func (u *EventStreamUsecase) Publish(ctx context.Context, event domain.BotEvent) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.nextID++
+	streamEvent := StreamEvent{ID: u.nextID, Event: event, Public: publicStreamView(event)}
+	if len(u.buffer) == eventStreamBufferSize {
+		u.buffer = append(u.buffer[:0], u.buffer[1:]...)
+	}
+	u.buffer = append(u.buffer, streamEvent)
+
+	for sub := range u.subscribers {
+		if !streamEvent.For(sub.userID) {
+			continue
+		}
+		select {
+		case sub.events <- streamEvent:
+		default:
+			delete(u.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Subscribe starts a subscription for userID, empty for an anonymous one.
+// With a lastEventID it also returns the buffered events after it; resumed
+// is false when that ID is no longer buffered, so the subscriber missed
+// events and should reload.
+func (u *EventStreamUsecase) Subscribe(userID string, lastEventID uint64) (sub *StreamSubscription, backlog []StreamEvent, resumed bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	resumed = true
+	if lastEventID > 0 {
+		oldest := u.nextID + 1
+		if len(u.buffer) > 0 {
+			oldest = u.buffer[0].ID
+		}
+		resumed = lastEventID+1 >= oldest && lastEventID <= u.nextID
+		for _, event := range u.buffer {
+			if event.ID > lastEventID && event.For(userID) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	sub = &StreamSubscription{userID: userID, events: make(chan StreamEvent, eventStreamQueueSize)}
+	u.subscribers[sub] = struct{}{}
+	return sub, backlog, resumed
+}
+
+// Unsubscribe ends a subscription. It is safe to call after the
+// subscription was dropped.
+func (u *EventStreamUsecase) Unsubscribe(sub *StreamSubscription) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.subscribers[sub]; ok {
+		delete(u.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Close drops every subscription, e.g. so open streams end when the HTTP
+// server shuts down.
+func (u *EventStreamUsecase) Close() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for sub := range u.subscribers {
+		delete(u.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func publicStreamView(event domain.BotEvent) map[string]any {
+	fields, ok := publicStreamData[event.Type]
+	if !ok {
+		return nil
+	}
+	public := make(map[string]any, len(fields))
+	for _, field := range fields {
+		if value, ok := event.Data[field]; ok {
+			public[field] = value
+		}
+	}
+	return public
+}
@@ -0,0 +1,64 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestEventStream_RoutesAndResumes(t *testing.T) {
+	uc := usecase.NewEventStreamUsecase()
+	ctx := context.Background()
+	anonymous, _, _ := uc.Subscribe("", 0)
+	alice, _, _ := uc.Subscribe("628111", 0)
+
+	uc.Publish(ctx, domain.BotEvent{Type: domain.BotEventReportAccepted, UserID: "628111", Data: map[string]any{"points": 10, "event_key": "k1"}})
+	uc.Publish(ctx, domain.BotEvent{Type: domain.BotEventStreakBroken, UserID: "628111", Data: map[string]any{"previous_streak": 4}})
+	uc.Publish(ctx, domain.BotEvent{Type: domain.BotEventStreakBroken, UserID: "628222"})
+
+	report := <-anonymous.Events()
+	if report.Public["points"] != 10 || report.Public["event_key"] != nil {
+		t.Fatalf("public view should keep points and drop the event key, got %+v", report.Public)
+	}
+	if len(anonymous.Events()) != 0 {
+		t.Fatal("anonymous streams must not receive private events")
+	}
+	<-alice.Events()
+	if broken := <-alice.Events(); broken.Event.Type != domain.BotEventStreakBroken || broken.Event.UserID != "628111" {
+		t.Fatalf("alice should receive her own private event, got %+v", broken)
+	}
+	if len(alice.Events()) != 0 {
+		t.Fatal("alice must not receive another user's private event")
+	}
+
+	_, backlog, resumed := uc.Subscribe("628111", report.ID)
+	if !resumed || len(backlog) != 1 || backlog[0].Event.Type != domain.BotEventStreakBroken {
+		t.Fatalf("resuming after the report should replay alice's streak event, got %v %+v", resumed, backlog)
+	}
+	if _, _, resumed := uc.Subscribe("", 1); resumed {
+		t.Fatal("an ID older than the buffer should ask for a resync")
+	}
+
+	uc.Unsubscribe(alice)
+	uc.Unsubscribe(alice)
+	if _, open := <-alice.Events(); open {
+		t.Fatal("unsubscribing should close the events channel")
+	}
+}
+
+func TestEventStream_DropsSlowSubscriber(t *testing.T) {
+	uc := usecase.NewEventStreamUsecase()
+	slow, _, _ := uc.Subscribe("", 0)
+	for range 100 {
+		uc.Publish(context.Background(), domain.BotEvent{Type: domain.BotEventSeasonReset, Data: map[string]any{"season": 3}})
+	}
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+	if received == 0 || received >= 100 {
+		t.Fatalf("a subscriber that stops reading should be dropped after its queue fills, got %d events", received)
+	}
+}
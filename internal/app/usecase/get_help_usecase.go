@@ -22,6 +22,16 @@ var helpSections = []struct {
 		title:   "Membatalkan Laporan",
 		content: "*/cancel* atau *#cancel* — Batalkan laporan utama terakhir hari ini jika kamu salah input. Kalau hari ini ada 2-3 laporan utama, hanya laporan paling akhir yang dihapus.\n*/cancel-all* atau *#cancel-all* — Hapus semua laporan utama hari ini dan hitung ulang progresmu.\n*/cancel sidequest* atau *#cancel sidequest* — Batalkan side quest terakhir hari ini.\n*/cancel-all sidequest* atau *#cancel-all sidequest* — Hapus semua side quest hari ini.\nHanya bisa digunakan pada hari yang sama dengan laporan.",
 	},
+	{
+		emoji:   "🐉",
+		title:   "Raid Boss Mingguan",
+		content: "Setiap Senin muncul satu raid boss untuk seluruh grup. HP boss menyesuaikan jumlah hunter yang aktif minggu sebelumnya.\n\nSetiap laporan valid memberi damage sebesar poin yang kamu dapat. Kalau attribute laporanmu cocok dengan *weakness* boss, damage dikali 1.5.\n\n*/raid* atau *#raid* — Cek HP boss dan daftar MVP. Kalau boss tumbang sebelum Senin berikutnya, semua kontributor dapat +15 pts.",
+	},
+	{
+		emoji:   "🥇",
+		title:   "Rekor Pribadi",
+		content: "Bot mencatat rekor pribadimu dari setiap laporan (teks, Hevy, Strava, maupun side quest): 5K tercepat, lari dan gowes terjauh, plank terlama, jarak mingguan terbesar, dan bulan paling aktif.\n\nTulis jarak dan waktunya supaya terbaca, contoh: `/lapor lari 5km 28 menit` atau `/lapor plank 2 menit`. Kalau kamu memecahkan rekor sendiri, bot akan mengumumkannya.\n\n*/pr* atau *#pr* — Lihat semua rekor pribadimu beserta tanggalnya.",
+	},
 	{
 		emoji:   "🏆",
 		title:   "Leaderboard & Statistik",
@@ -52,10 +62,18 @@ func (uc *GetHelpUsecase) Execute() string {
 ✨ /lapor sidequest or #lapor sidequest — lihat side quest hari ini
 ✨ /lapor sidequest [kegiatan] [jumlah] or #lapor sidequest [kegiatan] [jumlah] — lapor side quest
 📌 /lapor-kemarin or #lapor-kemarin — laporan khusus hari kemarin (max 3x/hari)
+📎 Kirim file .gpx/.tcx/.fit dengan caption /lapor — lapor dari jam GPS tanpa Strava
 ❌ /cancel or #cancel — batalkan laporan terakhir hari ini
 🧹 /cancel-all or #cancel-all — batalkan semua laporan hari ini
 ❌ /cancel sidequest or #cancel sidequest — batalkan side quest terakhir hari ini
 🧹 /cancel-all sidequest or #cancel-all sidequest — batalkan semua side quest hari ini
+⚔️ /raid or #raid — cek raid boss mingguan grup
+🥇 /pr or #pr — lihat rekor pribadimu
+📜 /riwayat or #riwayat — 10 laporan terakhirmu (dikirim lewat DM)
+🚴 /strava or #strava — hubungkan akun Strava (link dikirim lewat DM)
+🔧 /strava status|sync|unlink — kelola akun Strava, atur lewat /strava tipe dan /strava kirim
+🌐 /dashboard — link login dashboard tanpa ketik nomor (dikirim lewat DM)
+🔒 /logout-semua — keluarkan semua sesi dashboard web kamu
 📚 /tutorial or #tutorial — panduan lengkap penggunaan bot
 ❓ /help or #help — list command ini
 
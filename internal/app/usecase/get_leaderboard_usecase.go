@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/render"
 )
 
 type GetLeaderboardUsecase struct {
@@ -20,16 +21,80 @@ func NewGetLeaderboardUsecase(repo domain.ReportRepository) *GetLeaderboardUseca
 }
 
 func (uc *GetLeaderboardUsecase) Execute(ctx context.Context) (string, error) {
-	reports, err := uc.repo.GetAllReports(ctx)
+	standings, err := uc.dailyStandings(ctx, time.Now())
 	if err != nil {
 		return "", err
 	}
+	return standings.text(), nil
+}
+
+// ExecuteCard draws the daily leaderboard as a board card for the group.
+// The card's Text is what Execute returns.
+func (uc *GetLeaderboardUsecase) ExecuteCard(ctx context.Context, now time.Time) (GroupCard, error) {
+	standings, err := uc.dailyStandings(ctx, now)
+	if err != nil {
+		return GroupCard{}, err
+	}
+
+	rows, more := boardRows(standings.ranked, func(rank int, r *domain.Report) render.BoardRow {
+		detail := fmt.Sprintf("%d hari season, %d hari lifetime, %d minggu streak", r.SeasonalActivityCount, r.TotalActiveDays(), r.Streak)
+		alive := weeklyStreakAlive(r, standings.now)
+		if !alive {
+			detail = fmt.Sprintf("%d hari season, %d hari lifetime, streak putus", r.SeasonalActivityCount, r.TotalActiveDays())
+		}
+		return render.BoardRow{
+			Rank:   rank,
+			Name:   centurionCyclePrefix(r) + r.Name,
+			Detail: detail,
+			Value:  formatCardPoints(r.SeasonalPoints),
+			Tier:   domain.GetSeasonRank(r.SeasonalPoints).Tier,
+			Dimmed: !alive,
+		}
+	})
+	card := render.BoardCard{
+		Title:    fmt.Sprintf("Season %d - Day %d", standings.season, standings.day),
+		Subtitle: "Klasemen season sementara, " + standings.date,
+		Stats: []render.BoardStat{
+			{Label: "keep the streak", Value: fmt.Sprint(standings.active)},
+			{Label: "lose the streak", Value: fmt.Sprint(standings.lost)},
+		},
+		Rows:   rows,
+		More:   more,
+		Empty:  "Belum ada hunter aktif season ini",
+		Footer: groupCardSite,
+	}
+
+	return GroupCard{
+		Image: renderGroupCard("leaderboard", func() ([]byte, error) {
+			return render.RenderBoard(card, render.SeasonTheme(standings.season))
+		}),
+		Caption: fmt.Sprintf("📊 *Klasemen Season %d – Day %d*\n%d keep the streak 🔥 · %d lose the streak 💔",
+			standings.season, standings.day, standings.active, standings.lost),
+		Text: standings.text(),
+	}, nil
+}
+
+// dailyStandings is the data behind the daily leaderboard post.
+type dailyStandings struct {
+	now          time.Time
+	season       int
+	day          int
+	date         string
+	active, lost int
+	// ranked holds hunters with season activity in season rank order.
+	ranked []*domain.Report
+}
+
+func (uc *GetLeaderboardUsecase) dailyStandings(ctx context.Context, now time.Time) (dailyStandings, error) {
+	reports, err := uc.repo.GetAllReports(ctx)
+	if err != nil {
+		return dailyStandings{}, err
+	}
 	reports = domain.DedupReportsByUserID(reports, domain.SortBySeasonRank)
 
-	now := time.Now()
 	displayDate := domain.GetToday(now)
 
-	_, sessionStart := GetCurrentSessionInfo(now)
+	seasonNumber, sessionStart := GetCurrentSessionInfo(now)
 	startDate := time.Date(sessionStart.Year(), sessionStart.Month(), sessionStart.Day(), 0, 0, 0, 0, time.UTC)
 	challengeDay := int(displayDate.Sub(startDate).Hours()/24) + 1
 
@@ -37,45 +102,41 @@ func (uc *GetLeaderboardUsecase) Execute(ctx context.Context) (string, error) {
 
 	activeCount, lostCount := countStreakStatus(reports, now)
 
+	return dailyStandings{
+		now:    now,
+		season: seasonNumber,
+		day:    challengeDay,
+		date:   displayDate.Format("02-01-2006"),
+		active: activeCount,
+		lost:   lostCount,
+		ranked: domain.FilterReports(reports, domain.HasSeasonActivity),
+	}, nil
+}
+
+func (d dailyStandings) text() string {
 	sb := strings.Builder{}
-	dateStr := displayDate.Format("02-01-2006")
-	seasonNumber, _ := GetCurrentSessionInfo(now)
-	sb.WriteString(fmt.Sprintf("Season %d Hidup Sehat SWE Growth – Day %d (%s)\n\n", seasonNumber, challengeDay, dateStr))
+	sb.WriteString(fmt.Sprintf("Season %d Hidup Sehat SWE Growth – Day %d (%s)\n\n", d.season, d.day, d.date))
 
-	sb.WriteString(fmt.Sprintf("Recap day %d:\n", challengeDay))
-	sb.WriteString(fmt.Sprintf("%d peoples keep the streak 🔥\n", activeCount))
-	sb.WriteString(fmt.Sprintf("%d lose the streak 💔\n", lostCount))
+	sb.WriteString(fmt.Sprintf("Recap day %d:\n", d.day))
+	sb.WriteString(fmt.Sprintf("%d peoples keep the streak 🔥\n", d.active))
+	sb.WriteString(fmt.Sprintf("%d lose the streak 💔\n", d.lost))
 	sb.WriteString("\nUpdate klasemen season sementara:\n")
 
-	currentWeekStart := domain.GetStartOfISOWeek(now)
-	rank := 1
-	for _, r := range reports {
-		if !domain.HasSeasonActivity(r) {
-			continue
-		}
-		lastWeekStart := domain.GetStartOfISOWeek(r.LastReportDate)
-		weeksSinceLastReport := int(math.Round(currentWeekStart.Sub(lastWeekStart).Hours() / (24 * 7)))
-
-		cyclePrefix := ""
-		if r.CenturionCycles > 0 {
-			cyclePrefix = fmt.Sprintf("[S1-C%d] ", r.CenturionCycles+1)
-		}
-
-		if weeksSinceLastReport <= 1 {
-			sb.WriteString(fmt.Sprintf("%d. %s%s — %d pts (%d hari season, %d hari lifetime, %d minggu streak 🔥)\n", rank, cyclePrefix, r.Name, r.SeasonalPoints, r.SeasonalActivityCount, r.TotalActiveDays(), r.Streak))
+	for i, r := range d.ranked {
+		if weeklyStreakAlive(r, d.now) {
+			sb.WriteString(fmt.Sprintf("%d. %s%s — %d pts (%d hari season, %d hari lifetime, %d minggu streak 🔥)\n", i+1, centurionCyclePrefix(r), r.Name, r.SeasonalPoints, r.SeasonalActivityCount, r.TotalActiveDays(), r.Streak))
 		} else {
-			sb.WriteString(fmt.Sprintf("%d. %s%s — %d pts (%d hari season, %d hari lifetime, 💔)\n", rank, cyclePrefix, r.Name, r.SeasonalPoints, r.SeasonalActivityCount, r.TotalActiveDays()))
+			sb.WriteString(fmt.Sprintf("%d. %s%s — %d pts (%d hari season, %d hari lifetime, 💔)\n", i+1, centurionCyclePrefix(r), r.Name, r.SeasonalPoints, r.SeasonalActivityCount, r.TotalActiveDays()))
 		}
-		rank++
 	}
 
-	if rank == 1 {
+	if len(d.ranked) == 0 {
 		sb.WriteString("Belum ada hunter aktif season ini.\n")
 	}
 
 	sb.WriteString("\nYang udah keringetan langsung update/posting aja nanti dimasukkin klasemen 💪\n\nSemangat🔥")
 
-	return sb.String(), nil
+	return sb.String()
 }
 
 func (uc *GetLeaderboardUsecase) ExecuteSeasonal(ctx context.Context) (string, error) {
@@ -514,11 +575,8 @@ func calculateDailyStreaks(activityDates []time.Time, today time.Time) (int, int
 }
 
 func countStreakStatus(reports []*domain.Report, now time.Time) (active, lost int) {
-	currentWeekStart := domain.GetStartOfISOWeek(now)
 	for _, r := range reports {
-		lastWeekStart := domain.GetStartOfISOWeek(r.LastReportDate)
-		weeksSinceLastReport := int(math.Round(currentWeekStart.Sub(lastWeekStart).Hours() / (24 * 7)))
-		if weeksSinceLastReport <= 1 {
+		if weeklyStreakAlive(r, now) {
 			active++
 		} else {
 			lost++
@@ -527,6 +585,22 @@ func countStreakStatus(reports []*domain.Report, now time.Time) (active, lost in
 	return
 }
 
+// weeklyStreakAlive reports whether r last reported this ISO week or the
+// one before, so its weekly streak can still continue.
+func weeklyStreakAlive(r *domain.Report, now time.Time) bool {
+	currentWeekStart := domain.GetStartOfISOWeek(now)
+	lastWeekStart := domain.GetStartOfISOWeek(r.LastReportDate)
+	weeksSinceLastReport := int(math.Round(currentWeekStart.Sub(lastWeekStart).Hours() / (24 * 7)))
+	return weeksSinceLastReport <= 1
+}
+
+func centurionCyclePrefix(r *domain.Report) string {
+	if r.CenturionCycles > 0 {
+		return fmt.Sprintf("[S1-C%d] ", r.CenturionCycles+1)
+	}
+	return ""
+}
+
 func countBadges(achievements string) int {
 	if achievements == "" {
 		return 0
@@ -243,11 +243,11 @@ func goalReportDate(t time.Time) time.Time {
 	return domain.GetToday(t.UTC())
 }
 
-func goalActivityText(workout *domain.HevyWorkout) string {
+func goalActivityText(workout *domain.Workout) string {
 	return goalActivityTextWithFallback(workout, "")
 }
 
-func goalActivityTextWithFallback(workout *domain.HevyWorkout, fallback string) string {
+func goalActivityTextWithFallback(workout *domain.Workout, fallback string) string {
 	if workout == nil || strings.TrimSpace(workout.Title) == "" {
 		if text := strings.TrimSpace(fallback); text != "" {
 			return text
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/render"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const (
+	// groupCardRows is how many hunters fit on a board card before the
+	// rest are summarized as "+N hunter lainnya".
+	groupCardRows = 15
+	groupCardSite = "lapor-bot.web.id"
+	groupCardLink = "\n\n🌐 Lihat klasemen & stats: https://lapor-bot.web.id/"
+)
+
+// GroupCard is a scheduled group post drawn as an image. Caption goes under
+// the image; Text is the full post, sent instead when the image is missing
+// or can't be uploaded.
+type GroupCard struct {
+	Image   []byte
+	Caption string
+	Text    string
+}
+
+// Append adds footer to both the caption and the text, so the post reads
+// the same whether the image goes out or not.
+func (c *GroupCard) Append(footer string) {
+	c.Caption += footer
+	c.Text += footer
+}
+
+// GroupCardSender posts group cards and messages through the WhatsApp send
+// queue; *queue.MessageSender implements it.
+type GroupCardSender interface {
+	SendHighPriority(ctx context.Context, target types.JID, msg *waE2E.Message) error
+	SendImageHighPriority(ctx context.Context, target types.JID, png []byte, caption, fallback string) error
+}
+
+// renderGroupCard runs draw and logs failures, leaving the post to fall
+// back to text rather than failing the job.
+func renderGroupCard(name string, draw func() ([]byte, error)) []byte {
+	image, err := draw()
+	if err != nil {
+		log.Printf("[CARD] Failed to render %s card: %v", name, err)
+		return nil
+	}
+	return image
+}
+
+// boardRows turns ranked reports into card rows, keeping the first
+// groupCardRows and returning how many were left out.
+func boardRows(reports []*domain.Report, row func(rank int, r *domain.Report) render.BoardRow) ([]render.BoardRow, int) {
+	shown := min(len(reports), groupCardRows)
+	rows := make([]render.BoardRow, 0, shown)
+	for i := 0; i < shown; i++ {
+		rows = append(rows, row(i+1, reports[i]))
+	}
+	return rows, len(reports) - shown
+}
+
+func formatCardPoints(points int) string {
+	return fmt.Sprintf("%d PTS", points)
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestLeaderboardCard_FallsBackToDailyText(t *testing.T) {
+	now := time.Date(2026, 6, 17, 20, 0, 0, 0, time.UTC)
+	reports := []*domain.Report{
+		{UserID: "user1", Name: "Alice", SeasonalPoints: 300, SeasonalActivityCount: 15, Streak: 3, LastReportDate: now},
+		{UserID: "user2", Name: "Bob", SeasonalPoints: 120, SeasonalActivityCount: 6, LastReportDate: now.AddDate(0, 0, -30)},
+	}
+	for i := 0; i < groupCardRows; i++ {
+		reports = append(reports, &domain.Report{UserID: "extra" + string(rune('a'+i)), Name: "Extra", SeasonalPoints: 10, SeasonalActivityCount: 1, LastReportDate: now})
+	}
+	uc := NewGetLeaderboardUsecase(&mockWeeklyRanksRepo{reports: reports})
+
+	card, err := uc.ExecuteCard(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := png.DecodeConfig(bytes.NewReader(card.Image)); err != nil {
+		t.Fatalf("expected a PNG card: %v", err)
+	}
+	for _, expected := range []string{
+		"Season 1 Hidup Sehat SWE Growth – Day 48 (17-06-2026)",
+		"1. Alice — 300 pts (15 hari season, 0 hari lifetime, 3 minggu streak 🔥)",
+		"2. Bob — 120 pts (6 hari season, 0 hari lifetime, 💔)",
+		"17. Extra",
+	} {
+		if !strings.Contains(card.Text, expected) {
+			t.Errorf("expected fallback to contain %q, got:\n%s", expected, card.Text)
+		}
+	}
+	if !strings.Contains(card.Caption, "16 keep the streak") || strings.Contains(card.Caption, "Alice") {
+		t.Errorf("caption should only carry the recap, got %q", card.Caption)
+	}
+
+	card.Append("\n\nfooter")
+	if !strings.HasSuffix(card.Caption, "footer") || !strings.HasSuffix(card.Text, "footer") {
+		t.Errorf("footer should close both the caption and the fallback, got %q / %q", card.Caption, card.Text)
+	}
+}
+
+func TestSeasonPodium(t *testing.T) {
+	uc := NewResetSessionUsecase(&mockWeeklyRanksRepo{reports: []*domain.Report{
+		{UserID: "user1", Name: "Alice", SeasonalPoints: 300, SeasonalActivityCount: 15},
+		{UserID: "user2", Name: "Bob", SeasonalPoints: 120, SeasonalActivityCount: 6},
+		{UserID: "user3", Name: "Charlie", SeasonalPoints: 50, SeasonalActivityCount: 2},
+		{UserID: "user4", Name: "Dana", SeasonalPoints: 20, SeasonalActivityCount: 1},
+	}})
+
+	podium, err := uc.seasonPodium(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := png.DecodeConfig(bytes.NewReader(podium.Image)); err != nil {
+		t.Fatalf("expected a PNG podium: %v", err)
+	}
+	for _, expected := range []string{"PODIUM SEASON 1", "🥇 Alice — 300 pts", "🥉 Charlie — 50 pts"} {
+		if !strings.Contains(podium.Text, expected) {
+			t.Errorf("expected fallback to contain %q, got:\n%s", expected, podium.Text)
+		}
+	}
+	if strings.Contains(podium.Text, "Dana") {
+		t.Errorf("only the top three belong on the podium, got:\n%s", podium.Text)
+	}
+
+	empty, err := NewResetSessionUsecase(&mockWeeklyRanksRepo{}).seasonPodium(context.Background(), 1)
+	if err != nil || empty != nil {
+		t.Fatalf("a season without activity has no podium, got %v, %v", empty, err)
+	}
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 	"unicode"
@@ -26,12 +27,19 @@ type HandleMessageUsecase struct {
 	cancelUC            *CancelReportUsecase
 	updateNameUC        *UpdateNameUsecase
 	linkStravaUC        *LinkStravaUsecase
+	stravaAccountUC     *StravaAccountUsecase
+	workoutFileUC       *ImportWorkoutFileUsecase
+	sessionUC           *SessionUsecase
+	loginUC             *LoginUsecase
 	broadcastUpdateUC   *BroadcastUpdateUsecase
 	motivationUC        *GetMotivationUsecase
 	helpUC              *GetHelpUsecase
 	jobUC               *JobUsecase
 	goalUC              *GoalUsecase
 	dailyQuestUC        *DailyQuestUsecase
+	raidUC              *RaidBossUsecase
+	recordUC            *PersonalRecordUsecase
+	historyUC           *ReportHistoryUsecase
 }
 
 func NewHandleMessageUsecase(
@@ -63,9 +71,79 @@ func NewHandleMessageUsecase(
 		jobUC:               NewJobUsecase(leaderboardUC.repo),
 		goalUC:              NewGoalUsecase(leaderboardUC.repo),
 		dailyQuestUC:        NewDailyQuestUsecase(leaderboardUC.repo),
+		recordUC:            NewPersonalRecordUsecase(leaderboardUC.repo),
+		historyUC:           NewReportHistoryUsecase(leaderboardUC.repo),
 	}
 }
 
+// SetStravaAccountUsecase enables the /strava status, sync, unlink and
+// preference subcommands.
+func (uc *HandleMessageUsecase) SetStravaAccountUsecase(stravaAccountUC *StravaAccountUsecase) {
+	uc.stravaAccountUC = stravaAccountUC
+}
+
+// SetWorkoutFileUsecase enables /lapor with a GPX, TCX or FIT attachment.
+func (uc *HandleMessageUsecase) SetWorkoutFileUsecase(workoutFileUC *ImportWorkoutFileUsecase) {
+	uc.workoutFileUC = workoutFileUC
+}
+
+// SetSessionUsecase enables /logout-semua, which signs the sender out of
+// every dashboard session.
+func (uc *HandleMessageUsecase) SetSessionUsecase(sessionUC *SessionUsecase) {
+	uc.sessionUC = sessionUC
+}
+
+// SetRaidBossUsecase enables /raid with the bot's shared raid usecase.
+func (uc *HandleMessageUsecase) SetRaidBossUsecase(raidUC *RaidBossUsecase) {
+	uc.raidUC = raidUC
+}
+
+// SetLoginUsecase enables /dashboard, which DMs a one-time login link.
+func (uc *HandleMessageUsecase) SetLoginUsecase(loginUC *LoginUsecase) {
+	uc.loginUC = loginUC
+}
+
+// ExecuteDocument handles a document sent with a caption. A workout file
+// captioned with /lapor is downloaded and imported; any other document is
+// handled by its caption alone, so download is only called when needed.
+func (uc *HandleMessageUsecase) ExecuteDocument(ctx context.Context, userID, name, caption, fileName string, download func(context.Context) ([]byte, error)) (MessageResponse, error) {
+	note, ok := workoutFileReport(caption)
+	if !ok || uc.workoutFileUC == nil || !IsWorkoutFileName(fileName) {
+		return uc.Execute(ctx, userID, name, caption)
+	}
+
+	data, err := download(ctx)
+	if err == nil {
+		var result WorkoutFileImportResult
+		result, err = uc.workoutFileUC.Import(ctx, userID, name, fileName, data, note, time.Now())
+		if err == nil {
+			return MessageResponse{Text: result.Reply}, nil
+		}
+	}
+	if reply, ok := WorkoutFileErrorReply(err); ok {
+		return MessageResponse{Text: reply}, nil
+	}
+	return MessageResponse{}, fmt.Errorf("import %s: %w", fileName, err)
+}
+
+// workoutFileReport returns the text after a plain /lapor caption. The
+// yesterday and side quest variants keep their own meaning and are not file
+// reports.
+func workoutFileReport(caption string) (string, bool) {
+	trimmed := strings.TrimSpace(caption)
+	if strings.HasPrefix(trimmed, "#") {
+		trimmed = "/" + trimmed[1:]
+	}
+	msg := strings.ToLower(trimmed)
+	if !hasCommand(msg, "/lapor") {
+		return "", false
+	}
+	if _, ok := extractSideQuestReport(trimmed); ok {
+		return "", false
+	}
+	return strings.TrimSpace(trimmed[len("/lapor"):]), true
+}
+
 func (uc *HandleMessageUsecase) Execute(ctx context.Context, userID, name, message string) (MessageResponse, error) {
 	trimmedMessage := strings.TrimSpace(message)
 	msg := strings.ToLower(trimmedMessage)
@@ -101,7 +179,7 @@ func (uc *HandleMessageUsecase) Execute(ctx context.Context, userID, name, messa
 	// }
 
 	if hasCommand(msg, "/lapor-kemarin") {
-		workout := domain.ParseHevy(trimmedMessage)
+		workout := domain.ParseWorkout(trimmedMessage)
 		text, err := uc.reportUC.ExecuteYesterdayWithMessage(ctx, userID, name, trimmedMessage, workout)
 		return MessageResponse{Text: text}, err
 	}
@@ -121,7 +199,7 @@ func (uc *HandleMessageUsecase) Execute(ctx context.Context, userID, name, messa
 	}
 
 	if hasCommand(msg, "/lapor") {
-		workout := domain.ParseHevy(trimmedMessage)
+		workout := domain.ParseWorkout(trimmedMessage)
 		text, err := uc.reportUC.ExecuteWithMessage(ctx, userID, name, trimmedMessage, workout)
 		return MessageResponse{Text: text}, err
 	}
@@ -131,6 +209,52 @@ func (uc *HandleMessageUsecase) Execute(ctx context.Context, userID, name, messa
 		return MessageResponse{Text: text}, err
 	}
 
+	if hasCommand(msg, "/raid") && uc.raidUC != nil {
+		text, err := uc.raidUC.Status(ctx, time.Now())
+		return MessageResponse{Text: text}, err
+	}
+
+	if hasCommand(msg, "/pr") {
+		text, err := uc.recordUC.Execute(ctx, userID, name)
+		return MessageResponse{Text: text}, err
+	}
+
+	if hasCommand(msg, "/riwayat") {
+		// Report texts can be personal, so the history only goes out by DM.
+		text, err := uc.historyUC.Execute(ctx, userID, name)
+		return MessageResponse{Text: text, IsPrivate: true}, err
+	}
+
+	if hasCommand(msg, "/dashboard") && uc.loginUC != nil {
+		// Whoever holds the link is signed in as the sender, so it only
+		// ever goes out by DM.
+		text, err := uc.loginUC.ExecuteDashboard(ctx, userID, time.Now())
+		return MessageResponse{Text: text, IsPrivate: true}, err
+	}
+
+	if hasCommand(msg, "/logout-semua") && uc.sessionUC != nil {
+		text, err := uc.sessionUC.ExecuteLogoutAll(ctx, userID, time.Now())
+		return MessageResponse{Text: text, IsPrivate: true}, err
+	}
+
+	if hasCommand(msg, "/strava") && uc.stravaAccountUC != nil {
+		if args := strings.TrimSpace(trimmedMessage[len("/strava"):]); args != "" {
+			text, err := uc.stravaAccountUC.ExecuteCommand(ctx, userID, args, time.Now())
+			return MessageResponse{Text: text, IsPrivate: true}, err
+		}
+	}
+
+	if hasCommand(msg, "/strava") && uc.linkStravaUC != nil {
+		// The link is single use and tied to this sender, so it only ever
+		// goes out by DM.
+		linkURL, err := uc.linkStravaUC.IssueLinkToken(ctx, userID, name, time.Now())
+		if err != nil {
+			return MessageResponse{}, err
+		}
+		text := fmt.Sprintf("🚴‍♂️ *Integrasi Strava* 🏃‍♂️\n\nKlik link di bawah ini untuk menghubungkan akun Strava kamu:\n\n%s\n\nLink ini hanya bisa dipakai sekali dan berlaku 15 menit. Jangan dibagikan ke orang lain ya!\n\nSetelah berhasil, aktivitas larimu akan otomatis dilaporkan! 🎉", linkURL)
+		return MessageResponse{Text: text, IsPrivate: true}, nil
+	}
+
 	// if hasCommand(msg, "/motivasi") {
 	// 	text := uc.motivationUC.Execute()
 	// 	return MessageResponse{Text: text}, nil
@@ -197,12 +321,6 @@ func (uc *HandleMessageUsecase) Execute(ctx context.Context, userID, name, messa
 	// 	return MessageResponse{Text: text}, err
 	// }
 
-	// if hasCommand(msg, "/strava") {
-	// 	authURL := uc.linkStravaUC.GetAuthURL(userID, name)
-	// 	text := fmt.Sprintf("🚴‍♂️ *Integrasi Strava* 🏃‍♂️\n\nKlik link di bawah ini untuk menghubungkan akun Strava kamu:\n\n%s\n\nSetelah berhasil, aktivitas larimu akan otomatis dilaporkan! 🎉", authURL)
-	// 	return MessageResponse{Text: text, IsPrivate: true}, nil
-	// }
-
 	// if strings.HasPrefix(msg, "!broadcast_update") {
 	// 	text := uc.broadcastUpdateUC.Execute()
 	// 	return MessageResponse{Text: text}, nil
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/workoutfile"
+)
+
+const (
+	// MaxWorkoutFileBytes bounds an upload; a multi-hour FIT recording is
+	// a few megabytes.
+	MaxWorkoutFileBytes = 10 << 20
+
+	workoutFileReportSource = "file"
+)
+
+var (
+	ErrWorkoutFileUnavailable = errors.New("workout file import is not supported by this repository")
+	ErrWorkoutFileUnsupported = errors.New("workout file must be .gpx, .tcx or .fit")
+	ErrWorkoutFileTooLarge    = errors.New("workout file is too large")
+	ErrWorkoutFileInvalid     = errors.New("workout file could not be read")
+	ErrWorkoutFileDuplicate   = errors.New("workout file was already reported")
+)
+
+// WorkoutFileImportResult is the outcome of one upload. Accepted is false
+// when the report rules turned it down, e.g. the activity is too old or the
+// day's limit was reached; Reply explains why.
+type WorkoutFileImportResult struct {
+	Accepted bool                     `json:"accepted"`
+	Reply    string                   `json:"reply"`
+	File     domain.WorkoutFileImport `json:"file"`
+}
+
+// ImportWorkoutFileUsecase reports an activity from a GPX, TCX or FIT file,
+// for members whose watch does not sync to Strava.
+type ImportWorkoutFileUsecase struct {
+	repo     domain.ReportRepository
+	reportUC *ReportActivityUsecase
+}
+
+func NewImportWorkoutFileUsecase(repo domain.ReportRepository, reportUC *ReportActivityUsecase) *ImportWorkoutFileUsecase {
+	return &ImportWorkoutFileUsecase{repo: repo, reportUC: reportUC}
+}
+
+// IsWorkoutFileName reports whether the file name has a supported extension,
+// so callers can skip downloading anything else.
+func IsWorkoutFileName(fileName string) bool {
+	_, ok := workoutfile.FormatFromName(fileName)
+	return ok
+}
+
+// Import parses the file and reports it on the day it was recorded, under
+// the same rules as /lapor: today, or yesterday as a late report. note is
+// the user's own text sent with the file.
+func (u *ImportWorkoutFileUsecase) Import(ctx context.Context, userID, name, fileName string, data []byte, note string, now time.Time) (WorkoutFileImportResult, error) {
+	var result WorkoutFileImportResult
+	files, ok := u.repo.(domain.WorkoutFileRepository)
+	if !ok {
+		return result, ErrWorkoutFileUnavailable
+	}
+	format, ok := workoutfile.FormatFromName(fileName)
+	if !ok {
+		return result, ErrWorkoutFileUnsupported
+	}
+	if len(data) > MaxWorkoutFileBytes {
+		return result, ErrWorkoutFileTooLarge
+	}
+	activity, err := workoutfile.Parse(format, data)
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrWorkoutFileInvalid, err)
+	}
+
+	workout := activity.Workout()
+	result.File = domain.WorkoutFileImport{
+		EvidenceHash:        domain.WorkoutFileEvidenceHash(userID, activity.StartTime),
+		UserID:              userID,
+		FileName:            fileName,
+		Format:              format,
+		ActivityType:        activity.ActivityType,
+		ActivityDate:        activity.LocalDate(),
+		StartTime:           activity.StartTime,
+		DistanceMeters:      workout.DistanceMeters,
+		DurationSeconds:     activity.DurationSeconds,
+		ElevationGainMeters: activity.ElevationGainMeters,
+		CreatedAt:           now,
+	}
+	claimed, err := files.ClaimWorkoutFile(ctx, result.File)
+	if err != nil {
+		return result, err
+	}
+	if !claimed {
+		return result, ErrWorkoutFileDuplicate
+	}
+
+	reply, eventKey, err := u.reportUC.ExecuteFromSource(ctx, userID, name, workoutFileReportSource, note, workout, result.File.ActivityDate)
+	if err != nil || eventKey == "" {
+		// Nothing was recorded, so the same file may be sent again.
+		if releaseErr := files.ReleaseWorkoutFile(ctx, result.File.EvidenceHash); releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+		result.Reply = reply
+		return result, err
+	}
+	if err := files.CompleteWorkoutFile(ctx, result.File.EvidenceHash, eventKey); err != nil {
+		return result, err
+	}
+
+	result.Accepted = true
+	result.File.EventKey = eventKey
+	result.Reply = reply
+	if gain := math.Round(activity.ElevationGainMeters); gain > 0 {
+		result.Reply += fmt.Sprintf("\n⛰️ Elevasi +%.0f m", gain)
+	}
+	return result, nil
+}
+
+// WorkoutFileErrorReply turns an import error into a message for the user,
+// or returns false when the error is not the user's to fix.
+func WorkoutFileErrorReply(err error) (string, bool) {
+	switch {
+	case errors.Is(err, ErrWorkoutFileUnsupported):
+		return "File aktivitas harus berformat .gpx, .tcx atau .fit. 🙏", true
+	case errors.Is(err, ErrWorkoutFileTooLarge):
+		return fmt.Sprintf("File aktivitas terlalu besar (maksimal %d MB).", MaxWorkoutFileBytes>>20), true
+	case errors.Is(err, ErrWorkoutFileInvalid):
+		return "File aktivitas tidak bisa dibaca. Pastikan file-nya hasil ekspor dari jam atau aplikasi olahraga dan berisi waktu rekaman. 🙏", true
+	case errors.Is(err, ErrWorkoutFileDuplicate):
+		return "File ini sudah pernah dilaporkan, jadi tidak dihitung lagi. 🙂", true
+	default:
+		return "", false
+	}
+}
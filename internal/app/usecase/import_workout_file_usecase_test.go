@@ -0,0 +1,173 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// workoutFileRepo adds the evidence table to the handle-message mock.
+type workoutFileRepo struct {
+	*mockReportRepo
+	files map[string]domain.WorkoutFileImport
+}
+
+func newWorkoutFileRepo() *workoutFileRepo {
+	return &workoutFileRepo{
+		mockReportRepo: &mockReportRepo{reports: make(map[string]*domain.Report)},
+		files:          map[string]domain.WorkoutFileImport{},
+	}
+}
+
+func (r *workoutFileRepo) ClaimWorkoutFile(ctx context.Context, file domain.WorkoutFileImport) (bool, error) {
+	if _, ok := r.files[file.EvidenceHash]; ok {
+		return false, nil
+	}
+	r.files[file.EvidenceHash] = file
+	return true, nil
+}
+
+func (r *workoutFileRepo) CompleteWorkoutFile(ctx context.Context, evidenceHash, eventKey string) error {
+	file := r.files[evidenceHash]
+	file.EventKey = eventKey
+	r.files[evidenceHash] = file
+	return nil
+}
+
+func (r *workoutFileRepo) ReleaseWorkoutFile(ctx context.Context, evidenceHash string) error {
+	delete(r.files, evidenceHash)
+	return nil
+}
+
+// gpxAt is a 2 km, 12 minute run starting at start.
+func gpxAt(start time.Time) []byte {
+	point := func(lat float64, ele int, offset time.Duration) string {
+		return fmt.Sprintf(`<trkpt lat="%.6f" lon="106.816666"><ele>%d</ele><time>%s</time></trkpt>`,
+			lat, ele, start.Add(offset).UTC().Format(time.RFC3339))
+	}
+	return []byte(`<gpx version="1.1"><trk><type>running</type><trkseg>` +
+		point(-6.200, 10, 0) + point(-6.209, 25, 6*time.Minute) + point(-6.218, 20, 12*time.Minute) +
+		`</trkseg></trk></gpx>`)
+}
+
+func TestImportWorkoutFile_ReportsOnceAndRejectsReupload(t *testing.T) {
+	repo := newWorkoutFileRepo()
+	uc := usecase.NewImportWorkoutFileUsecase(repo, usecase.NewReportActivityUsecase(repo))
+	ctx := context.Background()
+	now := time.Now()
+	data := gpxAt(now.Add(-time.Hour))
+
+	result, err := uc.Import(ctx, "user1", "Alice", "pagi.gpx", data, "easy run", now)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if !result.Accepted || result.File.EventKey == "" || len(result.File.EvidenceHash) != 64 {
+		t.Fatalf("expected an accepted import with evidence, got %+v", result)
+	}
+	if result.File.Format != "gpx" || result.File.DurationSeconds != 720 || result.File.ActivityType != domain.ActivityTypeRun {
+		t.Fatalf("unexpected file summary: %+v", result.File)
+	}
+	if !strings.Contains(result.Reply, "File aktivitas") || !strings.Contains(result.Reply, "Elevasi +15 m") {
+		t.Errorf("reply should show the source and elevation, got %q", result.Reply)
+	}
+	if got := repo.files[result.File.EvidenceHash].EventKey; got != result.File.EventKey {
+		t.Fatalf("evidence should store the event key, got %q", got)
+	}
+	count := repo.reports["user1"].ActivityCount
+
+	if _, err := uc.Import(ctx, "user1", "Alice", "renamed.gpx", data, "", now); !errors.Is(err, usecase.ErrWorkoutFileDuplicate) {
+		t.Fatalf("expected ErrWorkoutFileDuplicate, got %v", err)
+	}
+	if repo.reports["user1"].ActivityCount != count {
+		t.Fatal("a re-upload must not add a report")
+	}
+
+	// The same recording exported again differs in bytes but not in what
+	// it records.
+	reexported := strings.Replace(string(data), `<gpx version="1.1">`, `<gpx version="1.1" creator="Garmin Connect">`, 1)
+	if _, err := uc.Import(ctx, "user1", "Alice", "export.gpx", []byte(reexported), "", now); !errors.Is(err, usecase.ErrWorkoutFileDuplicate) {
+		t.Fatalf("expected ErrWorkoutFileDuplicate for a re-export, got %v", err)
+	}
+	if repo.reports["user1"].ActivityCount != count {
+		t.Fatal("a re-export must not add a report")
+	}
+}
+
+func TestImportWorkoutFile_TooOldReleasesEvidence(t *testing.T) {
+	repo := newWorkoutFileRepo()
+	uc := usecase.NewImportWorkoutFileUsecase(repo, usecase.NewReportActivityUsecase(repo))
+	now := time.Now()
+
+	result, err := uc.Import(context.Background(), "user1", "Alice", "old.gpx", gpxAt(now.AddDate(0, 0, -5)), "", now)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if result.Accepted || !strings.Contains(result.Reply, "terlalu lama") {
+		t.Fatalf("expected a too-old rejection, got %+v", result)
+	}
+	if len(repo.files) != 0 {
+		t.Fatal("a rejected file should not be kept as evidence")
+	}
+}
+
+func TestImportWorkoutFile_RejectsBadFiles(t *testing.T) {
+	repo := newWorkoutFileRepo()
+	uc := usecase.NewImportWorkoutFileUsecase(repo, usecase.NewReportActivityUsecase(repo))
+	ctx := context.Background()
+
+	if _, err := uc.Import(ctx, "user1", "Alice", "photo.jpg", []byte("x"), "", time.Now()); !errors.Is(err, usecase.ErrWorkoutFileUnsupported) {
+		t.Errorf("expected ErrWorkoutFileUnsupported, got %v", err)
+	}
+	if _, err := uc.Import(ctx, "user1", "Alice", "run.fit", []byte("garbage"), "", time.Now()); !errors.Is(err, usecase.ErrWorkoutFileInvalid) {
+		t.Errorf("expected ErrWorkoutFileInvalid, got %v", err)
+	}
+	if reply, ok := usecase.WorkoutFileErrorReply(usecase.ErrWorkoutFileDuplicate); !ok || reply == "" {
+		t.Error("duplicate uploads should get a user-facing reply")
+	}
+}
+
+func TestHandleMessage_ExecuteDocument(t *testing.T) {
+	repo := newWorkoutFileRepo()
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	handleUC := usecase.NewHandleMessageUsecase(reportUC, usecase.NewGetLeaderboardUsecase(repo), usecase.NewGetMyStatsUsecase(repo), usecase.NewGetAchievementsUsecase(repo), usecase.NewComebackChallengeUsecase(repo), usecase.NewCancelReportUsecase(repo), usecase.NewUpdateNameUsecase(repo), nil, usecase.NewBroadcastUpdateUsecase(), usecase.NewGetMotivationUsecase(), usecase.NewGetHelpUsecase())
+	handleUC.SetWorkoutFileUsecase(usecase.NewImportWorkoutFileUsecase(repo, reportUC))
+	ctx := context.Background()
+
+	data := gpxAt(time.Now().Add(-time.Hour))
+	downloads := 0
+	download := func(context.Context) ([]byte, error) {
+		downloads++
+		return data, nil
+	}
+
+	for _, caption := range []string{"lihat file ini", "/lapor-kemarin"} {
+		if _, err := handleUC.ExecuteDocument(ctx, "user1", "Alice", caption, "run.gpx", download); err != nil {
+			t.Fatalf("ExecuteDocument(%q): %v", caption, err)
+		}
+	}
+	if _, err := handleUC.ExecuteDocument(ctx, "user1", "Alice", "/lapor", "notes.pdf", download); err != nil {
+		t.Fatalf("ExecuteDocument(pdf): %v", err)
+	}
+	if downloads != 0 {
+		t.Fatalf("only /lapor with a workout file should be downloaded, got %d downloads", downloads)
+	}
+
+	resp, err := handleUC.ExecuteDocument(ctx, "user2", "Budi", "#lapor pagi", "run.gpx", download)
+	if err != nil {
+		t.Fatalf("ExecuteDocument: %v", err)
+	}
+	if downloads != 1 || repo.reports["user2"] == nil || !strings.Contains(resp.Text, "File aktivitas") {
+		t.Fatalf("expected the file to be reported, got downloads=%d reply=%q", downloads, resp.Text)
+	}
+
+	resp, err = handleUC.ExecuteDocument(ctx, "user2", "Budi", "/lapor", "run.gpx", download)
+	if err != nil || !strings.Contains(resp.Text, "sudah pernah dilaporkan") {
+		t.Fatalf("expected a duplicate reply, got %q, %v", resp.Text, err)
+	}
+}
@@ -0,0 +1,363 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+)
+
+const (
+	// integrationClockSkew is how far a request timestamp may be from now.
+	// It bounds how long a captured request could be replayed.
+	integrationClockSkew = 5 * time.Minute
+	// integrationSecretGrace keeps the previous secret valid after a
+	// rotation while the client is updated.
+	integrationSecretGrace = 24 * time.Hour
+	maxExternalIDLength    = 128
+)
+
+var (
+	ErrIntegrationUnavailable = errors.New("integrations are not supported by this repository")
+	ErrIntegrationNotFound    = errors.New("integration not found")
+	ErrIntegrationExists      = errors.New("integration already exists")
+	ErrIntegrationInvalidID   = errors.New("integration ID must be 2-40 lowercase letters, digits or dashes")
+	// ErrIntegrationUnauthorized covers unknown and disabled integrations as
+	// well as bad signatures, so callers can't probe which IDs exist.
+	ErrIntegrationUnauthorized    = errors.New("integration request is not authorized")
+	ErrIntegrationInvalidActivity = errors.New("invalid integration activity")
+	ErrIntegrationUnknownUser     = errors.New("user has never reported")
+	// ErrIntegrationInProgress means the same external ID is being processed
+	// by a concurrent request.
+	ErrIntegrationInProgress = errors.New("activity is already being processed")
+)
+
+var integrationIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,39}$`)
+
+// IntegrationActivityInput is the normalized activity an integration
+// submits. ExternalID is the integration's own ID for the activity; sending
+// it again returns the first result instead of reporting twice.
+type IntegrationActivityInput struct {
+	ExternalID      string    `json:"external_id"`
+	User            string    `json:"user"` // phone number, e.g. 628123456789
+	OccurredAt      time.Time `json:"occurred_at"`
+	ActivityType    string    `json:"activity_type"`
+	Title           string    `json:"title"`
+	DistanceMeters  int       `json:"distance_meters"`
+	DurationSeconds int       `json:"duration_seconds"`
+	Calories        int       `json:"calories"`
+	Note            string    `json:"note"`
+}
+
+// IntegrationSubmitResult is the stored outcome of a submission. Duplicate
+// is true when the external ID had been submitted before.
+type IntegrationSubmitResult struct {
+	domain.IntegrationActivity
+	Duplicate bool `json:"duplicate"`
+}
+
+// IntegrationUsecase manages third-party integrations and files the
+// activities they submit through the normal report path.
+type IntegrationUsecase struct {
+	repo     domain.ReportRepository
+	reportUC *ReportActivityUsecase
+}
+
+func NewIntegrationUsecase(repo domain.ReportRepository, reportUC *ReportActivityUsecase) *IntegrationUsecase {
+	return &IntegrationUsecase{repo: repo, reportUC: reportUC}
+}
+
+func (u *IntegrationUsecase) integrations() (domain.IntegrationRepository, error) {
+	integrations, ok := u.repo.(domain.IntegrationRepository)
+	if !ok {
+		return nil, ErrIntegrationUnavailable
+	}
+	return integrations, nil
+}
+
+func (u *IntegrationUsecase) List(ctx context.Context) ([]domain.Integration, error) {
+	integrations, err := u.integrations()
+	if err != nil {
+		return nil, err
+	}
+	return integrations.ListIntegrations(ctx)
+}
+
+// Create registers an integration and returns its signing secret. The
+// secret is only shown here and on rotation.
+func (u *IntegrationUsecase) Create(ctx context.Context, id, name string, now time.Time) (domain.Integration, string, error) {
+	integrations, err := u.integrations()
+	if err != nil {
+		return domain.Integration{}, "", err
+	}
+	id = strings.ToLower(strings.TrimSpace(id))
+	if !integrationIDPattern.MatchString(id) {
+		return domain.Integration{}, "", ErrIntegrationInvalidID
+	}
+	existing, err := integrations.GetIntegration(ctx, id)
+	if err != nil {
+		return domain.Integration{}, "", err
+	}
+	if existing != nil {
+		return domain.Integration{}, "", ErrIntegrationExists
+	}
+
+	secret, err := newIntegrationSecret()
+	if err != nil {
+		return domain.Integration{}, "", err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = id
+	}
+	integration := domain.Integration{
+		ID:        id,
+		Name:      name,
+		Secret:    secret,
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := integrations.CreateIntegration(ctx, integration); err != nil {
+		return domain.Integration{}, "", err
+	}
+	return integration, secret, nil
+}
+
+// Rotate issues a new secret. The old one keeps working for a grace period
+// so the integration can be updated without dropping requests.
+func (u *IntegrationUsecase) Rotate(ctx context.Context, id string, now time.Time) (domain.Integration, string, error) {
+	integrations, err := u.integrations()
+	if err != nil {
+		return domain.Integration{}, "", err
+	}
+	integration, err := integrations.GetIntegration(ctx, id)
+	if err != nil {
+		return domain.Integration{}, "", err
+	}
+	if integration == nil {
+		return domain.Integration{}, "", ErrIntegrationNotFound
+	}
+	secret, err := newIntegrationSecret()
+	if err != nil {
+		return domain.Integration{}, "", err
+	}
+	integration.PreviousSecret = integration.Secret
+	integration.PreviousSecretExpiresAt = now.Add(integrationSecretGrace)
+	integration.Secret = secret
+	integration.UpdatedAt = now
+	if err := integrations.UpdateIntegration(ctx, *integration); err != nil {
+		return domain.Integration{}, "", err
+	}
+	return *integration, secret, nil
+}
+
+// SetEnabled turns an integration on or off. A disabled integration's
+// requests are rejected; its past activities are kept.
+func (u *IntegrationUsecase) SetEnabled(ctx context.Context, id string, enabled bool, now time.Time) (domain.Integration, error) {
+	integrations, err := u.integrations()
+	if err != nil {
+		return domain.Integration{}, err
+	}
+	integration, err := integrations.GetIntegration(ctx, id)
+	if err != nil {
+		return domain.Integration{}, err
+	}
+	if integration == nil {
+		return domain.Integration{}, ErrIntegrationNotFound
+	}
+	integration.Enabled = enabled
+	integration.UpdatedAt = now
+	if err := integrations.UpdateIntegration(ctx, *integration); err != nil {
+		return domain.Integration{}, err
+	}
+	return *integration, nil
+}
+
+func newIntegrationSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SignIntegrationRequest returns the signature an integration sends with a
+// request: "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>" keyed
+// with its secret. timestamp is the Unix time in seconds.
+func SignIntegrationRequest(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Authenticate checks a request's signature and timestamp against the
+// integration's current secret, or the previous one during its grace period.
+func (u *IntegrationUsecase) Authenticate(ctx context.Context, id, timestamp, signature string, body []byte, now time.Time) (*domain.Integration, error) {
+	integrations, err := u.integrations()
+	if err != nil {
+		return nil, err
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrIntegrationUnauthorized
+	}
+	if skew := now.Sub(time.Unix(unix, 0)); skew > integrationClockSkew || skew < -integrationClockSkew {
+		return nil, ErrIntegrationUnauthorized
+	}
+	integration, err := integrations.GetIntegration(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if integration == nil || !integration.Enabled {
+		return nil, ErrIntegrationUnauthorized
+	}
+
+	valid := hmac.Equal([]byte(signature), []byte(SignIntegrationRequest(integration.Secret, timestamp, body)))
+	if !valid && integration.PreviousSecret != "" && now.Before(integration.PreviousSecretExpiresAt) {
+		valid = hmac.Equal([]byte(signature), []byte(SignIntegrationRequest(integration.PreviousSecret, timestamp, body)))
+	}
+	if !valid {
+		return nil, ErrIntegrationUnauthorized
+	}
+	if err := integrations.TouchIntegration(ctx, integration.ID, now); err != nil {
+		return nil, err
+	}
+	return integration, nil
+}
+
+// Submit files an authenticated integration's activity as a report on the
+// day it happened, with the same rules as /lapor. The first result for an
+// external ID is stored and returned again on resubmission.
+func (u *IntegrationUsecase) Submit(ctx context.Context, integration *domain.Integration, input IntegrationActivityInput, now time.Time) (IntegrationSubmitResult, error) {
+	integrations, err := u.integrations()
+	if err != nil {
+		return IntegrationSubmitResult{}, err
+	}
+	input.ExternalID = strings.TrimSpace(input.ExternalID)
+	if input.ExternalID == "" || len(input.ExternalID) > maxExternalIDLength {
+		return IntegrationSubmitResult{}, fmt.Errorf("%w: external_id is required and at most %d characters", ErrIntegrationInvalidActivity, maxExternalIDLength)
+	}
+	if input.OccurredAt.IsZero() {
+		return IntegrationSubmitResult{}, fmt.Errorf("%w: occurred_at is required", ErrIntegrationInvalidActivity)
+	}
+	if input.DistanceMeters < 0 || input.DurationSeconds < 0 || input.Calories < 0 {
+		return IntegrationSubmitResult{}, fmt.Errorf("%w: quantities must not be negative", ErrIntegrationInvalidActivity)
+	}
+	input.ActivityType = strings.ToLower(strings.TrimSpace(input.ActivityType))
+	if input.ActivityType != "" && !knownActivityType(input.ActivityType) {
+		return IntegrationSubmitResult{}, fmt.Errorf("%w: unknown activity_type %q", ErrIntegrationInvalidActivity, input.ActivityType)
+	}
+	userID, err := phone.Normalize(input.User)
+	if err != nil {
+		return IntegrationSubmitResult{}, fmt.Errorf("%w: user: %v", ErrIntegrationInvalidActivity, err)
+	}
+
+	if previous, err := integrations.GetIntegrationActivity(ctx, integration.ID, input.ExternalID); err != nil {
+		return IntegrationSubmitResult{}, err
+	} else if previous != nil && !staleIntegrationClaim(previous, now) {
+		return integrationDuplicate(previous)
+	}
+
+	report, err := u.repo.GetReport(ctx, userID)
+	if err != nil {
+		return IntegrationSubmitResult{}, err
+	}
+	if report == nil {
+		// Integrations can only report for existing members, never enrol
+		// new ones.
+		return IntegrationSubmitResult{}, ErrIntegrationUnknownUser
+	}
+
+	activityDate := domain.GetToday(input.OccurredAt.Local())
+	record := domain.IntegrationActivity{
+		IntegrationID: integration.ID,
+		ExternalID:    input.ExternalID,
+		UserID:        userID,
+		Status:        domain.IntegrationActivityPending,
+		ActivityDate:  activityDate,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	claimed, err := integrations.ClaimIntegrationActivity(ctx, record)
+	if err != nil {
+		return IntegrationSubmitResult{}, err
+	}
+	if !claimed {
+		previous, err := integrations.GetIntegrationActivity(ctx, integration.ID, input.ExternalID)
+		if err != nil {
+			return IntegrationSubmitResult{}, err
+		}
+		if previous == nil {
+			return IntegrationSubmitResult{}, ErrIntegrationInProgress
+		}
+		return integrationDuplicate(previous)
+	}
+
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		title = integration.Name
+	}
+	workout := &domain.Workout{
+		Source:          domain.IntegrationReportSource(integration.ID),
+		Title:           title,
+		ActivityType:    input.ActivityType,
+		DistanceMeters:  input.DistanceMeters,
+		DurationSeconds: input.DurationSeconds,
+		Calories:        input.Calories,
+	}
+	reply, eventKey, err := u.reportUC.ExecuteFromSource(ctx, userID, report.Name, domain.IntegrationReportSource(integration.ID), input.Note, workout, activityDate)
+	if err != nil {
+		// Let the client's retry process it again.
+		_ = integrations.ReleaseIntegrationActivity(ctx, integration.ID, input.ExternalID)
+		return IntegrationSubmitResult{}, err
+	}
+
+	record.Status = domain.IntegrationActivityReported
+	if eventKey == "" {
+		record.Status = domain.IntegrationActivityRejected
+	}
+	record.EventKey = eventKey
+	record.Reply = reply
+	record.UpdatedAt = now
+	if err := integrations.SaveIntegrationActivity(ctx, record); err != nil {
+		return IntegrationSubmitResult{}, err
+	}
+	return IntegrationSubmitResult{IntegrationActivity: record}, nil
+}
+
+// staleIntegrationClaim reports whether a pending claim was left behind by
+// a submission that died before saving its result.
+func staleIntegrationClaim(previous *domain.IntegrationActivity, now time.Time) bool {
+	return previous.Status == domain.IntegrationActivityPending && now.Sub(previous.UpdatedAt) > domain.IntegrationClaimTimeout
+}
+
+func integrationDuplicate(previous *domain.IntegrationActivity) (IntegrationSubmitResult, error) {
+	if previous.Status == domain.IntegrationActivityPending {
+		return IntegrationSubmitResult{}, ErrIntegrationInProgress
+	}
+	return IntegrationSubmitResult{IntegrationActivity: *previous, Duplicate: true}, nil
+}
+
+func knownActivityType(activityType string) bool {
+	switch activityType {
+	case domain.ActivityTypeRun, domain.ActivityTypeWalk, domain.ActivityTypeRide,
+		domain.ActivityTypeSwim, domain.ActivityTypeHike, domain.ActivityTypeStrength,
+		domain.ActivityTypeHIIT, domain.ActivityTypeSport, domain.ActivityTypeYoga:
+		return true
+	default:
+		return false
+	}
+}
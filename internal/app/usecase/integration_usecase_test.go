@@ -0,0 +1,238 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type integrationRepo struct {
+	*mockReportRepo
+	integrations map[string]domain.Integration
+	activities   map[string]domain.IntegrationActivity
+}
+
+func newIntegrationRepo() *integrationRepo {
+	return &integrationRepo{
+		mockReportRepo: &mockReportRepo{reports: make(map[string]*domain.Report)},
+		integrations:   map[string]domain.Integration{},
+		activities:     map[string]domain.IntegrationActivity{},
+	}
+}
+
+func (r *integrationRepo) CreateIntegration(ctx context.Context, integration domain.Integration) error {
+	r.integrations[integration.ID] = integration
+	return nil
+}
+
+func (r *integrationRepo) GetIntegration(ctx context.Context, id string) (*domain.Integration, error) {
+	integration, ok := r.integrations[id]
+	if !ok {
+		return nil, nil
+	}
+	return &integration, nil
+}
+
+func (r *integrationRepo) ListIntegrations(ctx context.Context) ([]domain.Integration, error) {
+	var list []domain.Integration
+	for _, integration := range r.integrations {
+		list = append(list, integration)
+	}
+	return list, nil
+}
+
+func (r *integrationRepo) UpdateIntegration(ctx context.Context, integration domain.Integration) error {
+	r.integrations[integration.ID] = integration
+	return nil
+}
+
+func (r *integrationRepo) TouchIntegration(ctx context.Context, id string, usedAt time.Time) error {
+	integration := r.integrations[id]
+	integration.LastUsedAt = usedAt
+	r.integrations[id] = integration
+	return nil
+}
+
+func (r *integrationRepo) ClaimIntegrationActivity(ctx context.Context, activity domain.IntegrationActivity) (bool, error) {
+	key := activity.IntegrationID + "/" + activity.ExternalID
+	if previous, ok := r.activities[key]; ok {
+		stale := previous.Status == domain.IntegrationActivityPending && activity.UpdatedAt.Sub(previous.UpdatedAt) > domain.IntegrationClaimTimeout
+		if !stale {
+			return false, nil
+		}
+	}
+	r.activities[key] = activity
+	return true, nil
+}
+
+func (r *integrationRepo) GetIntegrationActivity(ctx context.Context, integrationID, externalID string) (*domain.IntegrationActivity, error) {
+	activity, ok := r.activities[integrationID+"/"+externalID]
+	if !ok {
+		return nil, nil
+	}
+	return &activity, nil
+}
+
+func (r *integrationRepo) SaveIntegrationActivity(ctx context.Context, activity domain.IntegrationActivity) error {
+	r.activities[activity.IntegrationID+"/"+activity.ExternalID] = activity
+	return nil
+}
+
+func (r *integrationRepo) ReleaseIntegrationActivity(ctx context.Context, integrationID, externalID string) error {
+	delete(r.activities, integrationID+"/"+externalID)
+	return nil
+}
+
+func TestIntegration_AuthenticateAndRotate(t *testing.T) {
+	repo := newIntegrationRepo()
+	uc := usecase.NewIntegrationUsecase(repo, usecase.NewReportActivityUsecase(repo))
+	ctx := context.Background()
+	now := time.Now()
+
+	if _, _, err := uc.Create(ctx, "Gym Kiosk!", "", now); !errors.Is(err, usecase.ErrIntegrationInvalidID) {
+		t.Fatalf("expected ErrIntegrationInvalidID, got %v", err)
+	}
+	integration, secret, err := uc.Create(ctx, "gym-kiosk", "Gym Kiosk", now)
+	if err != nil || secret == "" || !integration.Enabled {
+		t.Fatalf("Create = %+v, %q, %v", integration, secret, err)
+	}
+	if _, _, err := uc.Create(ctx, "gym-kiosk", "", now); !errors.Is(err, usecase.ErrIntegrationExists) {
+		t.Fatalf("expected ErrIntegrationExists, got %v", err)
+	}
+
+	body := []byte(`{"external_id":"1"}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	signature := usecase.SignIntegrationRequest(secret, ts, body)
+	if _, err := uc.Authenticate(ctx, "gym-kiosk", ts, signature, body, now); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if _, err := uc.Authenticate(ctx, "gym-kiosk", ts, signature, []byte(`{"external_id":"2"}`), now); !errors.Is(err, usecase.ErrIntegrationUnauthorized) {
+		t.Fatalf("tampered body should be rejected, got %v", err)
+	}
+	if _, err := uc.Authenticate(ctx, "gym-kiosk", ts, signature, body, now.Add(10*time.Minute)); !errors.Is(err, usecase.ErrIntegrationUnauthorized) {
+		t.Fatalf("stale timestamp should be rejected, got %v", err)
+	}
+
+	_, newSecret, err := uc.Rotate(ctx, "gym-kiosk", now)
+	if err != nil || newSecret == secret {
+		t.Fatalf("Rotate = %q, %v", newSecret, err)
+	}
+	if _, err := uc.Authenticate(ctx, "gym-kiosk", ts, signature, body, now); err != nil {
+		t.Fatalf("old secret should work during the grace period: %v", err)
+	}
+	later := now.Add(25 * time.Hour)
+	laterTS := strconv.FormatInt(later.Unix(), 10)
+	if _, err := uc.Authenticate(ctx, "gym-kiosk", laterTS, usecase.SignIntegrationRequest(secret, laterTS, body), body, later); !errors.Is(err, usecase.ErrIntegrationUnauthorized) {
+		t.Fatalf("old secret should expire, got %v", err)
+	}
+	if _, err := uc.Authenticate(ctx, "gym-kiosk", laterTS, usecase.SignIntegrationRequest(newSecret, laterTS, body), body, later); err != nil {
+		t.Fatalf("new secret rejected: %v", err)
+	}
+
+	if _, err := uc.SetEnabled(ctx, "gym-kiosk", false, now); err != nil {
+		t.Fatalf("SetEnabled: %v", err)
+	}
+	if _, err := uc.Authenticate(ctx, "gym-kiosk", ts, usecase.SignIntegrationRequest(newSecret, ts, body), body, now); !errors.Is(err, usecase.ErrIntegrationUnauthorized) {
+		t.Fatalf("disabled integration should be rejected, got %v", err)
+	}
+}
+
+func TestIntegration_SubmitTakesOverStaleClaim(t *testing.T) {
+	repo := newIntegrationRepo()
+	repo.reports["628111222333"] = &domain.Report{UserID: "628111222333", Name: "Alice"}
+	uc := usecase.NewIntegrationUsecase(repo, usecase.NewReportActivityUsecase(repo))
+	ctx := context.Background()
+	now := time.Now()
+	integration, _, err := uc.Create(ctx, "gym-kiosk", "Gym Kiosk", now)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	input := usecase.IntegrationActivityInput{
+		ExternalID:      "checkin-1",
+		User:            "628111222333",
+		OccurredAt:      now,
+		ActivityType:    "strength",
+		DurationSeconds: 3600,
+	}
+
+	// A submission that died after claiming leaves its row pending.
+	repo.activities["gym-kiosk/checkin-1"] = domain.IntegrationActivity{
+		IntegrationID: "gym-kiosk",
+		ExternalID:    "checkin-1",
+		UserID:        "628111222333",
+		Status:        domain.IntegrationActivityPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if _, err := uc.Submit(ctx, &integration, input, now.Add(time.Minute)); !errors.Is(err, usecase.ErrIntegrationInProgress) {
+		t.Fatalf("a fresh claim must hold the external ID, got %v", err)
+	}
+
+	later := now.Add(domain.IntegrationClaimTimeout + time.Minute)
+	result, err := uc.Submit(ctx, &integration, input, later)
+	if err != nil {
+		t.Fatalf("Submit after the claim went stale: %v", err)
+	}
+	if result.Duplicate || result.Status != domain.IntegrationActivityReported || result.EventKey == "" {
+		t.Fatalf("expected the stale claim to be reported, got %+v", result)
+	}
+	if got := repo.reports["628111222333"].ActivityCount; got != 1 {
+		t.Fatalf("expected one reported activity, got %d", got)
+	}
+}
+
+func TestIntegration_SubmitIsIdempotentAndTagged(t *testing.T) {
+	repo := newIntegrationRepo()
+	repo.reports["628111222333"] = &domain.Report{UserID: "628111222333", Name: "Alice"}
+	uc := usecase.NewIntegrationUsecase(repo, usecase.NewReportActivityUsecase(repo))
+	ctx := context.Background()
+	now := time.Now()
+	integration, _, err := uc.Create(ctx, "gym-kiosk", "Gym Kiosk", now)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	input := usecase.IntegrationActivityInput{
+		ExternalID:      "checkin-1",
+		User:            "+62 811-1222-333",
+		OccurredAt:      now,
+		ActivityType:    "strength",
+		DurationSeconds: 3600,
+	}
+	first, err := uc.Submit(ctx, &integration, input, now)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if first.Duplicate || first.Status != domain.IntegrationActivityReported || first.EventKey == "" {
+		t.Fatalf("expected a new reported activity, got %+v", first)
+	}
+	count := repo.reports["628111222333"].ActivityCount
+
+	again, err := uc.Submit(ctx, &integration, input, now)
+	if err != nil {
+		t.Fatalf("second Submit: %v", err)
+	}
+	if !again.Duplicate || again.EventKey != first.EventKey || repo.reports["628111222333"].ActivityCount != count {
+		t.Fatalf("resubmission must return the first result without reporting again, got %+v", again)
+	}
+
+	if id, ok := domain.IntegrationIDFromSource(domain.IntegrationReportSource("gym-kiosk")); !ok || id != "gym-kiosk" {
+		t.Fatalf("source should round-trip, got %q", id)
+	}
+
+	input.ExternalID = "checkin-2"
+	input.User = "628999999999"
+	if _, err := uc.Submit(ctx, &integration, input, now); !errors.Is(err, usecase.ErrIntegrationUnknownUser) {
+		t.Fatalf("expected ErrIntegrationUnknownUser, got %v", err)
+	}
+	input.User = "628111222333"
+	input.ActivityType = "teleport"
+	if _, err := uc.Submit(ctx, &integration, input, now); !errors.Is(err, usecase.ErrIntegrationInvalidActivity) {
+		t.Fatalf("expected ErrIntegrationInvalidActivity, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// liftingHistoryLimit caps how many sessions one history request returns.
+const liftingHistoryLimit = 100
+
+// LiftingRecorder stores the sets of a parsed workout and returns the lines
+// for the reporter's reply (volume and any new PRs). An empty string means
+// there was nothing to record.
+type LiftingRecorder func(ctx context.Context, userID, name string, workout *domain.Workout, eventKey string, activityDate, now time.Time) string
+
+// LiftingPRNotifier receives the group shout-out for new lifting PRs.
+type LiftingPRNotifier func(ctx context.Context, message string)
+
+type LiftingUsecase struct {
+	repo       domain.ReportRepository
+	prNotifier LiftingPRNotifier
+}
+
+func NewLiftingUsecase(repo domain.ReportRepository) *LiftingUsecase {
+	return &LiftingUsecase{repo: repo}
+}
+
+// SetPRNotifier sets a callback that fires when a report sets new lifting PRs.
+func (u *LiftingUsecase) SetPRNotifier(fn LiftingPRNotifier) {
+	u.prNotifier = fn
+}
+
+func (u *LiftingUsecase) lifts() (domain.LiftingRepository, bool) {
+	lifts, ok := u.repo.(domain.LiftingRepository)
+	return lifts, ok
+}
+
+// Record implements LiftingRecorder. Storage errors are logged and swallowed
+// so a failing lift log never rejects the report itself.
+func (u *LiftingUsecase) Record(ctx context.Context, userID, name string, workout *domain.Workout, eventKey string, activityDate, now time.Time) string {
+	lifts, ok := u.lifts()
+	if !ok {
+		return ""
+	}
+	logs := domain.NewLiftingLogs(userID, eventKey, workout, activityDate, now)
+	if len(logs) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(logs))
+	for _, l := range logs {
+		keys = append(keys, l.ExerciseKey)
+	}
+	previous, err := lifts.GetBestEstimatedOneRepMax(ctx, userID, keys)
+	if err != nil {
+		log.Printf("lifting: failed to load best lifts for %s: %v", userID, err)
+		return ""
+	}
+	inserted, err := lifts.RecordLiftingLogs(ctx, logs)
+	if err != nil {
+		log.Printf("lifting: failed to record lifts for %s: %v", userID, err)
+		return ""
+	}
+	if !inserted {
+		return ""
+	}
+
+	prs := domain.DetectLiftingPRs(workout, previous)
+	if len(prs) > 0 && u.prNotifier != nil {
+		u.prNotifier(ctx, formatLiftingShoutOut(name, prs))
+	}
+	return formatLiftingReply(workout.TotalVolumeKg(), prs)
+}
+
+// History returns one exercise's sessions for progress charts.
+func (u *LiftingUsecase) History(ctx context.Context, userID, exercise string) ([]domain.LiftingLog, error) {
+	lifts, ok := u.lifts()
+	if !ok {
+		return nil, nil
+	}
+	return lifts.GetLiftingHistory(ctx, userID, domain.ExerciseKey(exercise), liftingHistoryLimit)
+}
+
+// Exercises lists every exercise the user has logged sets for.
+func (u *LiftingUsecase) Exercises(ctx context.Context, userID string) ([]domain.LiftingExerciseSummary, error) {
+	lifts, ok := u.lifts()
+	if !ok {
+		return nil, nil
+	}
+	return lifts.GetLiftingExercises(ctx, userID)
+}
+
+func formatLiftingReply(volumeKg float64, prs []domain.LiftingPR) string {
+	var lines []string
+	if volumeKg > 0 {
+		lines = append(lines, fmt.Sprintf("🏋️ Total volume: %s", domain.FormatKg(volumeKg)))
+	}
+	for _, pr := range prs {
+		lines = append(lines, fmt.Sprintf("🏆 *PR BARU!* %s: e1RM %s (sebelumnya %s) dari %s × %d",
+			pr.Exercise, domain.FormatKg(pr.NewKg), domain.FormatKg(pr.PreviousKg), domain.FormatKg(pr.Set.WeightKg), pr.Set.Reps))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatLiftingShoutOut(name string, prs []domain.LiftingPR) string {
+	sb := new(strings.Builder)
+	sb.WriteString("🎉 *PR ALERT!* 🎉\n\n")
+	sb.WriteString(fmt.Sprintf("%s baru saja pecah rekor angkatnya:\n", name))
+	for _, pr := range prs {
+		sb.WriteString(fmt.Sprintf("🏋️ %s — e1RM %s (+%s)\n", pr.Exercise, domain.FormatKg(pr.NewKg), domain.FormatKg(pr.NewKg-pr.PreviousKg)))
+	}
+	sb.WriteString("\nKasih selamat dulu dong! 👏💪")
+	return sb.String()
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockLiftingRepo struct {
+	domain.ReportRepository
+	logs []domain.LiftingLog
+}
+
+func (m *mockLiftingRepo) RecordLiftingLogs(ctx context.Context, logs []domain.LiftingLog) (bool, error) {
+	for _, prev := range m.logs {
+		if prev.EventKey == logs[0].EventKey {
+			return false, nil
+		}
+	}
+	m.logs = append(m.logs, logs...)
+	return true, nil
+}
+
+func (m *mockLiftingRepo) GetBestEstimatedOneRepMax(ctx context.Context, userID string, exerciseKeys []string) (map[string]float64, error) {
+	best := map[string]float64{}
+	for _, l := range m.logs {
+		if l.UserID == userID && l.EstimatedOneRepMaxKg > best[l.ExerciseKey] {
+			best[l.ExerciseKey] = l.EstimatedOneRepMaxKg
+		}
+	}
+	return best, nil
+}
+
+func (m *mockLiftingRepo) GetLiftingHistory(ctx context.Context, userID, exerciseKey string, limit int) ([]domain.LiftingLog, error) {
+	return nil, nil
+}
+
+func (m *mockLiftingRepo) GetLiftingExercises(ctx context.Context, userID string) ([]domain.LiftingExerciseSummary, error) {
+	return nil, nil
+}
+
+func squatWorkout(weight float64, reps int) *domain.Workout {
+	return &domain.Workout{Source: domain.WorkoutSourceHevy, ExerciseLogs: []domain.ExerciseLog{{
+		Name: "Squat (Barbell)",
+		Sets: []domain.ExerciseSet{{Kind: domain.SetKindWeighted, WeightKg: weight, Reps: reps}},
+	}}}
+}
+
+func TestLiftingUsecase_RecordAnnouncesPROnce(t *testing.T) {
+	repo := &mockLiftingRepo{}
+	uc := NewLiftingUsecase(repo)
+	var shoutOuts []string
+	uc.SetPRNotifier(func(ctx context.Context, message string) { shoutOuts = append(shoutOuts, message) })
+
+	ctx := context.Background()
+	now := time.Date(2026, time.September, 1, 11, 0, 0, 0, time.UTC)
+	today := domain.GetToday(now)
+
+	line := uc.Record(ctx, "u1", "Alice", squatWorkout(100, 5), "evt-1", today, now)
+	if line != "🏋️ Total volume: 500 kg" || len(shoutOuts) != 0 {
+		t.Fatalf("baseline session: line %q, shout-outs %v", line, shoutOuts)
+	}
+
+	line = uc.Record(ctx, "u1", "Alice", squatWorkout(110, 3), "evt-2", today, now)
+	if !strings.Contains(line, "🏆 *PR BARU!* Squat (Barbell): e1RM 121 kg (sebelumnya 116.7 kg) dari 110 kg × 3") {
+		t.Fatalf("expected PR line, got %q", line)
+	}
+	if len(shoutOuts) != 1 || !strings.Contains(shoutOuts[0], "Alice baru saja pecah rekor") {
+		t.Fatalf("expected one group shout-out, got %v", shoutOuts)
+	}
+
+	if line := uc.Record(ctx, "u1", "Alice", squatWorkout(110, 3), "evt-2", today, now); line != "" || len(shoutOuts) != 1 {
+		t.Fatalf("retried event must not re-announce: line %q, shout-outs %d", line, len(shoutOuts))
+	}
+}
@@ -2,20 +2,36 @@ package usecase
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/config"
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
 	"github.com/fardannozami/whatsapp-gateway/internal/infra/strava"
 )
 
+const (
+	// stravaLinkTTL is how long the one-time link DM'd by the bot stays valid.
+	stravaLinkTTL = 15 * time.Minute
+	// stravaOAuthStateTTL bounds the round trip through Strava's consent page.
+	stravaOAuthStateTTL = 10 * time.Minute
+)
+
 type LinkStravaUsecase struct {
 	repo         domain.ReportRepository
 	stravaClient *strava.Client
 	clientID     string
 	redirectURI  string
+	linkURL      string
+	secret       []byte
 }
 
 func NewLinkStravaUsecase(repo domain.ReportRepository, stravaClient *strava.Client, cfg config.Config) *LinkStravaUsecase {
@@ -24,35 +40,129 @@ func NewLinkStravaUsecase(repo domain.ReportRepository, stravaClient *strava.Cli
 		stravaClient: stravaClient,
 		clientID:     cfg.StravaClientID,
 		redirectURI:  fmt.Sprintf("%s/strava/callback", cfg.AppBaseURL),
+		linkURL:      fmt.Sprintf("%s/strava/link", cfg.AppBaseURL),
+		secret:       []byte(cfg.JWTSecret),
+	}
+}
+
+func (uc *LinkStravaUsecase) states() (domain.StravaOAuthStateRepository, error) {
+	states, ok := uc.repo.(domain.StravaOAuthStateRepository)
+	if !ok {
+		return nil, errors.New("strava linking is not supported by this repository")
+	}
+	return states, nil
+}
+
+// IssueLinkToken returns a one-time link for the bot to DM to a user who asked
+// to connect Strava from WhatsApp.
+func (uc *LinkStravaUsecase) IssueLinkToken(ctx context.Context, userID, userName string, now time.Time) (string, error) {
+	token, err := uc.newState(ctx, domain.StravaStateLink, userID, userName, now, stravaLinkTTL)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s?token=%s", uc.linkURL, url.QueryEscape(token)), nil
+}
+
+// RedeemLinkToken consumes a one-time link and returns the Strava
+// authorization URL for the user it was issued to.
+func (uc *LinkStravaUsecase) RedeemLinkToken(ctx context.Context, token string, now time.Time) (string, error) {
+	state, err := uc.consumeState(ctx, domain.StravaStateLink, token, now)
+	if err != nil {
+		return "", err
 	}
+	return uc.StartAuth(ctx, state.UserID, state.Name, now)
 }
 
-func (uc *LinkStravaUsecase) GetAuthURL(userID, userName string) string {
+// StartAuth returns the Strava authorization URL for an already authenticated
+// user. The state it carries is single use and expires.
+func (uc *LinkStravaUsecase) StartAuth(ctx context.Context, userID, userName string, now time.Time) (string, error) {
+	state, err := uc.newState(ctx, domain.StravaStateOAuth, userID, userName, now, stravaOAuthStateTTL)
+	if err != nil {
+		return "", err
+	}
+
 	baseURL := "https://www.strava.com/oauth/authorize"
 	params := url.Values{}
 	params.Set("client_id", uc.clientID)
 	params.Set("redirect_uri", uc.redirectURI)
 	params.Set("response_type", "code")
 	params.Set("scope", "activity:read_all")
-	params.Set("state", fmt.Sprintf("%s|%s", userID, userName))
+	params.Set("state", state)
 
-	return fmt.Sprintf("%s?%s", baseURL, params.Encode())
+	return fmt.Sprintf("%s?%s", baseURL, params.Encode()), nil
 }
 
-func (uc *LinkStravaUsecase) HandleCallback(ctx context.Context, code, state string) error {
-	account, err := uc.stravaClient.ExchangeToken(code)
+// HandleCallback links the Strava athlete to the user who started the flow.
+// The state is checked and consumed before the code is exchanged, so a reused
+// or forged state never reaches Strava.
+func (uc *LinkStravaUsecase) HandleCallback(ctx context.Context, code, state string, now time.Time) error {
+	owner, err := uc.consumeState(ctx, domain.StravaStateOAuth, state, now)
 	if err != nil {
 		return err
 	}
 
-	parts := strings.Split(state, "|")
-	userID := parts[0]
-	userName := ""
-	if len(parts) > 1 {
-		userName = parts[1]
+	account, err := uc.stravaClient.ExchangeToken(code)
+	if err != nil {
+		return err
 	}
 
-	account.UserID = userID
-	account.Name = userName
+	account.UserID = owner.UserID
+	account.Name = owner.Name
 	return uc.repo.UpsertStravaAccount(ctx, account)
 }
+
+func (uc *LinkStravaUsecase) newState(ctx context.Context, purpose, userID, userName string, now time.Time, ttl time.Duration) (string, error) {
+	if userID == "" {
+		return "", errors.New("user ID is required")
+	}
+	states, err := uc.states()
+	if err != nil {
+		return "", err
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	nonce := hex.EncodeToString(raw)
+
+	err = states.CreateStravaOAuthState(ctx, domain.StravaOAuthState{
+		Nonce:     nonce,
+		Purpose:   purpose,
+		UserID:    userID,
+		Name:      userName,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store state: %w", err)
+	}
+	return nonce + "." + uc.sign(purpose, nonce), nil
+}
+
+func (uc *LinkStravaUsecase) consumeState(ctx context.Context, purpose, token string, now time.Time) (*domain.StravaOAuthState, error) {
+	nonce, sig, ok := strings.Cut(token, ".")
+	if !ok || nonce == "" || !hmac.Equal([]byte(sig), []byte(uc.sign(purpose, nonce))) {
+		return nil, domain.ErrInvalidStravaState
+	}
+	states, err := uc.states()
+	if err != nil {
+		return nil, err
+	}
+	state, err := states.ConsumeStravaOAuthState(ctx, nonce, purpose, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume state: %w", err)
+	}
+	if state == nil {
+		return nil, domain.ErrInvalidStravaState
+	}
+	return state, nil
+}
+
+// sign binds the nonce to its purpose, so a link token can't be replayed as
+// an OAuth state or the other way round.
+func (uc *LinkStravaUsecase) sign(purpose, nonce string) string {
+	mac := hmac.New(sha256.New, uc.secret)
+	mac.Write([]byte(purpose + ":" + nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
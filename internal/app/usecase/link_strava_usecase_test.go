@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockStravaStateRepo struct {
+	domain.ReportRepository
+	states map[string]domain.StravaOAuthState
+	used   map[string]bool
+}
+
+func newMockStravaStateRepo() *mockStravaStateRepo {
+	return &mockStravaStateRepo{states: map[string]domain.StravaOAuthState{}, used: map[string]bool{}}
+}
+
+func (m *mockStravaStateRepo) CreateStravaOAuthState(ctx context.Context, state domain.StravaOAuthState) error {
+	m.states[state.Nonce] = state
+	return nil
+}
+
+func (m *mockStravaStateRepo) ConsumeStravaOAuthState(ctx context.Context, nonce, purpose string, now time.Time) (*domain.StravaOAuthState, error) {
+	state, ok := m.states[nonce]
+	if !ok || m.used[nonce] || state.Purpose != purpose || !now.Before(state.ExpiresAt) {
+		return nil, nil
+	}
+	m.used[nonce] = true
+	return &state, nil
+}
+
+func newTestLinkStravaUsecase(repo domain.ReportRepository) *LinkStravaUsecase {
+	return NewLinkStravaUsecase(repo, nil, config.Config{
+		StravaClientID: "123",
+		AppBaseURL:     "https://bot.example",
+		JWTSecret:      "secret",
+	})
+}
+
+func tokenFromURL(t *testing.T, raw, param string) string {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("invalid URL %q: %v", raw, err)
+	}
+	return u.Query().Get(param)
+}
+
+func TestLinkStrava_LinkTokenIsSingleUse(t *testing.T) {
+	repo := newMockStravaStateRepo()
+	uc := newTestLinkStravaUsecase(repo)
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	link, err := uc.IssueLinkToken(context.Background(), "628111", "Budi", now)
+	if err != nil {
+		t.Fatalf("IssueLinkToken: %v", err)
+	}
+	if !strings.HasPrefix(link, "https://bot.example/strava/link?token=") {
+		t.Fatalf("unexpected link %q", link)
+	}
+	token := tokenFromURL(t, link, "token")
+
+	authURL, err := uc.RedeemLinkToken(context.Background(), token, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("RedeemLinkToken: %v", err)
+	}
+	state := tokenFromURL(t, authURL, "state")
+	if state == "" || strings.Contains(state, "628111") {
+		t.Fatalf("state must be an opaque nonce, got %q", state)
+	}
+
+	if _, err := uc.RedeemLinkToken(context.Background(), token, now.Add(2*time.Minute)); !errors.Is(err, domain.ErrInvalidStravaState) {
+		t.Fatalf("expected reused link to be rejected, got %v", err)
+	}
+}
+
+func TestLinkStrava_RejectsExpiredLink(t *testing.T) {
+	repo := newMockStravaStateRepo()
+	uc := newTestLinkStravaUsecase(repo)
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	link, err := uc.IssueLinkToken(context.Background(), "628111", "Budi", now)
+	if err != nil {
+		t.Fatalf("IssueLinkToken: %v", err)
+	}
+	_, err = uc.RedeemLinkToken(context.Background(), tokenFromURL(t, link, "token"), now.Add(stravaLinkTTL))
+	if !errors.Is(err, domain.ErrInvalidStravaState) {
+		t.Fatalf("expected expired link to be rejected, got %v", err)
+	}
+}
+
+func TestLinkStrava_CallbackRejectsBadState(t *testing.T) {
+	repo := newMockStravaStateRepo()
+	uc := newTestLinkStravaUsecase(repo)
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	authURL, err := uc.StartAuth(ctx, "628111", "Budi", now)
+	if err != nil {
+		t.Fatalf("StartAuth: %v", err)
+	}
+	state := tokenFromURL(t, authURL, "state")
+	nonce, _, _ := strings.Cut(state, ".")
+
+	link, _ := uc.IssueLinkToken(ctx, "628111", "Budi", now)
+	otherUC := NewLinkStravaUsecase(repo, nil, config.Config{JWTSecret: "other"})
+	forged, _ := otherUC.StartAuth(ctx, "628999", "Mallory", now)
+
+	cases := map[string]string{
+		"legacy plain state":    "628999|Mallory",
+		"tampered signature":    nonce + ".AAAA",
+		"missing signature":     nonce,
+		"link token as state":   tokenFromURL(t, link, "token"),
+		"signed with other key": tokenFromURL(t, forged, "state"),
+	}
+	for name, bad := range cases {
+		if err := uc.HandleCallback(ctx, "code", bad, now); !errors.Is(err, domain.ErrInvalidStravaState) {
+			t.Errorf("%s: expected ErrInvalidStravaState, got %v", name, err)
+		}
+	}
+
+	// A state that was already consumed can't be replayed.
+	if _, err := repo.ConsumeStravaOAuthState(ctx, nonce, domain.StravaStateOAuth, now); err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+	if err := uc.HandleCallback(ctx, "code", state, now); !errors.Is(err, domain.ErrInvalidStravaState) {
+		t.Fatalf("expected reused state to be rejected, got %v", err)
+	}
+}
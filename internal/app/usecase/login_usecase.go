@@ -0,0 +1,375 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/queue"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const (
+	loginCodeLength = 6
+	// loginCodeTTL is how long a code DM'd by the bot can be exchanged.
+	loginCodeTTL = 5 * time.Minute
+	// loginMaxAttempts is how many wrong guesses lock a code.
+	loginMaxAttempts = 5
+	// loginResendInterval is the minimum wait between two codes.
+	loginResendInterval = time.Minute
+	// At most loginMaxSends codes go out per loginSendWindow, which bounds
+	// both the DMs a stranger can trigger and the guesses they get.
+	loginSendWindow = time.Hour
+	loginMaxSends   = 5
+	loginAuditLimit = 100
+	// loginLinkTTL is how long a /dashboard link stays valid. It only has
+	// to survive the tap from WhatsApp to the browser.
+	loginLinkTTL = 5 * time.Minute
+)
+
+var (
+	ErrLoginUnavailable = errors.New("login codes are not supported by this setup")
+	ErrLoginUnknownUser = errors.New("user has never reported")
+	ErrLoginThrottled   = errors.New("a login code was sent recently")
+	ErrLoginInvalidCode = errors.New("login code is invalid")
+	ErrLoginCodeExpired = errors.New("login code has expired")
+	// ErrLoginLocked means the code ran out of attempts; a new one has to
+	// be requested.
+	ErrLoginLocked = errors.New("too many wrong login codes")
+	// ErrLoginLinkInvalid covers forged, expired and already used links.
+	ErrLoginLinkInvalid = errors.New("login link is invalid or expired")
+)
+
+// LoginClient describes where a login request came from, for the audit log.
+type LoginClient struct {
+	IP        string
+	UserAgent string
+}
+
+// LoginCode tells the caller when the code expires and when another one can
+// be requested. Only ResendAt is set when the request was throttled.
+type LoginCode struct {
+	ExpiresAt time.Time
+	ResendAt  time.Time
+}
+
+// LoginUsecase proves dashboard users own their number: the bot DMs them a
+// one-time code, or a one-time link for /dashboard, which they exchange for
+// a session.
+type LoginUsecase struct {
+	repo    domain.ReportRepository
+	sender  *queue.MessageSender
+	secret  []byte
+	linkURL string
+}
+
+// NewLoginUsecase keys the stored code hashes and link signatures with the
+// JWT secret, so a copy of the database doesn't give the codes away.
+func NewLoginUsecase(repo domain.ReportRepository, cfg config.Config) *LoginUsecase {
+	return &LoginUsecase{
+		repo:    repo,
+		secret:  []byte(cfg.JWTSecret),
+		linkURL: fmt.Sprintf("%s/login", cfg.AppBaseURL),
+	}
+}
+
+// SetSender configures how codes are DM'd. Without one, no code can be sent.
+func (uc *LoginUsecase) SetSender(sender *queue.MessageSender) {
+	uc.sender = sender
+}
+
+func (uc *LoginUsecase) logins() (domain.LoginRepository, error) {
+	logins, ok := uc.repo.(domain.LoginRepository)
+	if !ok {
+		return nil, ErrLoginUnavailable
+	}
+	return logins, nil
+}
+
+// RequestCode DMs a fresh code to userID, replacing any pending one.
+func (uc *LoginUsecase) RequestCode(ctx context.Context, userID string, client LoginClient, now time.Time) (LoginCode, error) {
+	logins, err := uc.logins()
+	if err != nil {
+		return LoginCode{}, err
+	}
+	if uc.sender == nil {
+		return LoginCode{}, ErrLoginUnavailable
+	}
+	report, err := uc.repo.GetReport(ctx, userID)
+	if err != nil {
+		return LoginCode{}, err
+	}
+	if report == nil {
+		return LoginCode{}, ErrLoginUnknownUser
+	}
+
+	challenge, err := logins.GetLoginChallenge(ctx, userID)
+	if err != nil {
+		return LoginCode{}, err
+	}
+	if challenge == nil {
+		challenge = &domain.LoginChallenge{UserID: userID, WindowStartedAt: now}
+	}
+	if !now.Before(challenge.WindowStartedAt.Add(loginSendWindow)) {
+		challenge.SendCount = 0
+		challenge.WindowStartedAt = now
+	}
+	if resendAt := loginResendAt(*challenge); challenge.SendCount > 0 && now.Before(resendAt) {
+		uc.audit(ctx, logins, userID, domain.LoginEventCodeThrottled, client, now)
+		return LoginCode{ResendAt: resendAt}, ErrLoginThrottled
+	}
+
+	code, err := newLoginCode()
+	if err != nil {
+		return LoginCode{}, err
+	}
+	lastSentAt := challenge.LastSentAt
+	challenge.CodeHash = uc.hash(userID, code)
+	challenge.Attempts = 0
+	challenge.SendCount++
+	challenge.LastSentAt = now
+	challenge.ExpiresAt = now.Add(loginCodeTTL)
+	saved, err := logins.SaveLoginChallenge(ctx, *challenge, lastSentAt)
+	if err != nil {
+		return LoginCode{}, err
+	}
+	if !saved {
+		// A parallel request sent a code since we read the challenge.
+		uc.audit(ctx, logins, userID, domain.LoginEventCodeThrottled, client, now)
+		return LoginCode{ResendAt: now.Add(loginResendInterval)}, ErrLoginThrottled
+	}
+
+	text := fmt.Sprintf("🔐 Kode login dashboard kamu: *%s*\n\nBerlaku %d menit. Jangan bagikan kode ini ke siapa pun. Kalau kamu tidak sedang login, abaikan pesan ini.",
+		code, int(loginCodeTTL/time.Minute))
+	target := types.NewJID(userID, types.DefaultUserServer)
+	if err := uc.sender.SendHighPriority(ctx, target, &waE2E.Message{Conversation: &text}); err != nil {
+		return LoginCode{}, fmt.Errorf("failed to send login code: %w", err)
+	}
+	uc.audit(ctx, logins, userID, domain.LoginEventCodeSent, client, now)
+
+	return LoginCode{ExpiresAt: challenge.ExpiresAt, ResendAt: loginResendAt(*challenge)}, nil
+}
+
+// Verify exchanges a code for the user's report. A code works once, and
+// only until it expires or has been guessed wrong loginMaxAttempts times.
+func (uc *LoginUsecase) Verify(ctx context.Context, userID, code string, client LoginClient, now time.Time) (*domain.Report, error) {
+	logins, err := uc.logins()
+	if err != nil {
+		return nil, err
+	}
+	code = strings.TrimSpace(code)
+	if !isLoginCode(code) {
+		return nil, ErrLoginInvalidCode
+	}
+
+	challenge, err := logins.GetLoginChallenge(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil || challenge.CodeHash == "" {
+		uc.audit(ctx, logins, userID, domain.LoginEventFailed, client, now)
+		return nil, ErrLoginInvalidCode
+	}
+	if !now.Before(challenge.ExpiresAt) {
+		uc.audit(ctx, logins, userID, domain.LoginEventExpired, client, now)
+		return nil, ErrLoginCodeExpired
+	}
+	if challenge.Attempts >= loginMaxAttempts {
+		return nil, ErrLoginLocked
+	}
+
+	// The attempt is counted before the comparison, so parallel guesses
+	// can't get past the limit.
+	attempts, err := logins.IncrementLoginAttempts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if attempts == 0 {
+		return nil, ErrLoginInvalidCode
+	}
+	if attempts > loginMaxAttempts {
+		return nil, ErrLoginLocked
+	}
+
+	hash := uc.hash(userID, code)
+	if !hmac.Equal([]byte(hash), []byte(challenge.CodeHash)) {
+		if attempts == loginMaxAttempts {
+			uc.audit(ctx, logins, userID, domain.LoginEventLocked, client, now)
+			return nil, ErrLoginLocked
+		}
+		uc.audit(ctx, logins, userID, domain.LoginEventFailed, client, now)
+		return nil, ErrLoginInvalidCode
+	}
+	consumed, err := logins.ConsumeLoginChallenge(ctx, userID, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, ErrLoginInvalidCode
+	}
+	uc.audit(ctx, logins, userID, domain.LoginEventVerified, client, now)
+
+	report, err := uc.repo.GetReport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, ErrLoginUnknownUser
+	}
+	return report, nil
+}
+
+// IssueLink returns a one-time dashboard login link for the bot to DM to a
+// user who sent /dashboard. The dashboard posts the token back to
+// RedeemLink; opening the URL alone, as WhatsApp's link preview does,
+// doesn't use it up.
+func (uc *LoginUsecase) IssueLink(ctx context.Context, userID string, now time.Time) (string, error) {
+	if userID == "" {
+		return "", errors.New("user ID is required")
+	}
+	logins, err := uc.logins()
+	if err != nil {
+		return "", err
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate login link: %w", err)
+	}
+	nonce := hex.EncodeToString(raw)
+	err = logins.CreateLoginLink(ctx, domain.LoginLink{
+		Nonce:     nonce,
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(loginLinkTTL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store login link: %w", err)
+	}
+	uc.audit(ctx, logins, userID, domain.LoginEventLinkSent, LoginClient{}, now)
+
+	token := nonce + "." + uc.signLink(userID, nonce)
+	return fmt.Sprintf("%s?token=%s", uc.linkURL, url.QueryEscape(token)), nil
+}
+
+// RedeemLink consumes a /dashboard link token and returns the report of the
+// user it was issued to.
+func (uc *LoginUsecase) RedeemLink(ctx context.Context, token string, client LoginClient, now time.Time) (*domain.Report, error) {
+	logins, err := uc.logins()
+	if err != nil {
+		return nil, err
+	}
+	nonce, sig, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok || nonce == "" || sig == "" {
+		return nil, ErrLoginLinkInvalid
+	}
+
+	link, err := logins.ConsumeLoginLink(ctx, nonce, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume login link: %w", err)
+	}
+	// The signature binds the link to the user it was issued to, so a
+	// tampered row or token can't log in as someone else.
+	if link == nil || !hmac.Equal([]byte(sig), []byte(uc.signLink(link.UserID, nonce))) {
+		return nil, ErrLoginLinkInvalid
+	}
+	uc.audit(ctx, logins, link.UserID, domain.LoginEventLinkUsed, client, now)
+
+	report, err := uc.repo.GetReport(ctx, link.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, ErrLoginUnknownUser
+	}
+	return report, nil
+}
+
+// ExecuteDashboard answers /dashboard with a login link.
+func (uc *LoginUsecase) ExecuteDashboard(ctx context.Context, userID string, now time.Time) (string, error) {
+	link, err := uc.IssueLink(ctx, userID, now)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("🌐 *Dashboard Lapor Bot*\n\nKlik link ini untuk langsung masuk ke dashboard kamu:\n\n%s\n\nLink ini hanya bisa dipakai sekali dan berlaku %d menit. Jangan dibagikan ke orang lain ya!",
+		link, int(loginLinkTTL/time.Minute)), nil
+}
+
+// Audit lists the latest login events, optionally for a single user.
+func (uc *LoginUsecase) Audit(ctx context.Context, userID string) ([]domain.LoginAuditEntry, error) {
+	logins, err := uc.logins()
+	if err != nil {
+		return nil, err
+	}
+	return logins.ListLoginAudit(ctx, userID, loginAuditLimit)
+}
+
+// audit never fails the login; a lost entry is only logged.
+func (uc *LoginUsecase) audit(ctx context.Context, logins domain.LoginRepository, userID, event string, client LoginClient, now time.Time) {
+	err := logins.AddLoginAudit(ctx, domain.LoginAuditEntry{
+		UserID:    userID,
+		Event:     event,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		CreatedAt: now,
+	})
+	if err != nil {
+		log.Printf("Failed to record login event %s for %s: %v", event, userID, err)
+	}
+}
+
+func (uc *LoginUsecase) hash(userID, code string) string {
+	mac := hmac.New(sha256.New, uc.secret)
+	mac.Write([]byte(userID + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (uc *LoginUsecase) signLink(userID, nonce string) string {
+	mac := hmac.New(sha256.New, uc.secret)
+	mac.Write([]byte("login-link:" + userID + ":" + nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// loginResendAt is the earliest time the next code may be sent.
+func loginResendAt(challenge domain.LoginChallenge) time.Time {
+	resendAt := challenge.LastSentAt.Add(loginResendInterval)
+	if challenge.SendCount >= loginMaxSends {
+		if windowEnd := challenge.WindowStartedAt.Add(loginSendWindow); windowEnd.After(resendAt) {
+			resendAt = windowEnd
+		}
+	}
+	return resendAt
+}
+
+func newLoginCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate login code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", loginCodeLength, n.Int64()), nil
+}
+
+func isLoginCode(code string) bool {
+	if len(code) != loginCodeLength {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
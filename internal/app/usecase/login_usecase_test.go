@@ -0,0 +1,360 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/queue"
+)
+
+type loginRepoStub struct {
+	*cancelReportRepoStub
+	challenges map[string]domain.LoginChallenge
+	links      map[string]domain.LoginLink
+	audit      []domain.LoginAuditEntry
+	// beforeSave runs once before the next save, to let a test slip in a
+	// parallel request.
+	beforeSave func()
+}
+
+func newLoginRepoStub(report *domain.Report) *loginRepoStub {
+	return &loginRepoStub{
+		cancelReportRepoStub: &cancelReportRepoStub{report: report},
+		challenges:           map[string]domain.LoginChallenge{},
+		links:                map[string]domain.LoginLink{},
+	}
+}
+
+func (r *loginRepoStub) GetLoginChallenge(ctx context.Context, userID string) (*domain.LoginChallenge, error) {
+	c, ok := r.challenges[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+func (r *loginRepoStub) SaveLoginChallenge(ctx context.Context, c domain.LoginChallenge, lastSentAt time.Time) (bool, error) {
+	if hook := r.beforeSave; hook != nil {
+		r.beforeSave = nil
+		hook()
+	}
+	if stored, ok := r.challenges[c.UserID]; ok && !stored.LastSentAt.Equal(lastSentAt) {
+		return false, nil
+	}
+	r.challenges[c.UserID] = c
+	return true, nil
+}
+
+func (r *loginRepoStub) IncrementLoginAttempts(ctx context.Context, userID string) (int, error) {
+	c, ok := r.challenges[userID]
+	if !ok || c.CodeHash == "" {
+		return 0, nil
+	}
+	c.Attempts++
+	r.challenges[userID] = c
+	return c.Attempts, nil
+}
+
+func (r *loginRepoStub) ConsumeLoginChallenge(ctx context.Context, userID, codeHash string) (bool, error) {
+	c, ok := r.challenges[userID]
+	if !ok || c.CodeHash == "" || c.CodeHash != codeHash {
+		return false, nil
+	}
+	c.CodeHash = ""
+	r.challenges[userID] = c
+	return true, nil
+}
+
+func (r *loginRepoStub) CreateLoginLink(ctx context.Context, link domain.LoginLink) error {
+	r.links[link.Nonce] = link
+	return nil
+}
+
+func (r *loginRepoStub) ConsumeLoginLink(ctx context.Context, nonce string, now time.Time) (*domain.LoginLink, error) {
+	link, ok := r.links[nonce]
+	if !ok || !link.UsedAt.IsZero() || !now.Before(link.ExpiresAt) {
+		return nil, nil
+	}
+	link.UsedAt = now
+	r.links[nonce] = link
+	return &link, nil
+}
+
+func (r *loginRepoStub) AddLoginAudit(ctx context.Context, entry domain.LoginAuditEntry) error {
+	r.audit = append(r.audit, entry)
+	return nil
+}
+
+func (r *loginRepoStub) ListLoginAudit(ctx context.Context, userID string, limit int) ([]domain.LoginAuditEntry, error) {
+	return r.audit, nil
+}
+
+func (r *loginRepoStub) auditEvents() []string {
+	var events []string
+	for _, entry := range r.audit {
+		events = append(events, entry.Event)
+	}
+	return events
+}
+
+func newTestLoginUsecase(t *testing.T, repo *loginRepoStub) (*LoginUsecase, *fakeMessageClient) {
+	t.Helper()
+	client := &fakeMessageClient{}
+	sender := queue.NewTestSender(client, context.Background())
+	sender.Start()
+	t.Cleanup(func() { sender.Shutdown(100 * time.Millisecond) })
+
+	uc := NewLoginUsecase(repo, config.Config{JWTSecret: "jwt-secret", AppBaseURL: "https://lapor.example"})
+	uc.SetSender(sender)
+	return uc, client
+}
+
+// sentLoginCode pulls the code out of the last DM, where it is in bold.
+func sentLoginCode(t *testing.T, client *fakeMessageClient) string {
+	t.Helper()
+	if client.sentMsg == nil {
+		t.Fatal("no login code was sent")
+	}
+	_, rest, _ := strings.Cut(client.sentMsg.GetConversation(), "*")
+	code, _, _ := strings.Cut(rest, "*")
+	if !isLoginCode(code) {
+		t.Fatalf("no code in %q", client.sentMsg.GetConversation())
+	}
+	return code
+}
+
+func TestLogin_CodeIsExchangedOnce(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	repo := newLoginRepoStub(&domain.Report{UserID: "628111", Name: "Budi"})
+	uc, client := newTestLoginUsecase(t, repo)
+	from := LoginClient{IP: "10.0.0.1", UserAgent: "test"}
+
+	sent, err := uc.RequestCode(context.Background(), "628111", from, now)
+	if err != nil {
+		t.Fatalf("RequestCode: %v", err)
+	}
+	if !sent.ExpiresAt.Equal(now.Add(loginCodeTTL)) || !sent.ResendAt.Equal(now.Add(loginResendInterval)) {
+		t.Fatalf("unexpected code timing: %+v", sent)
+	}
+	if client.sentJID.User != "628111" {
+		t.Fatalf("code should be DM'd to the user, got %s", client.sentJID)
+	}
+	code := sentLoginCode(t, client)
+	if stored := repo.challenges["628111"].CodeHash; stored == "" || strings.Contains(stored, code) {
+		t.Fatalf("only a hash of the code should be stored, got %q", stored)
+	}
+
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	if _, err := uc.Verify(context.Background(), "628111", wrong, from, now); !errors.Is(err, ErrLoginInvalidCode) {
+		t.Fatalf("wrong code: got %v", err)
+	}
+	report, err := uc.Verify(context.Background(), "628111", code, from, now.Add(time.Minute))
+	if err != nil || report.Name != "Budi" {
+		t.Fatalf("Verify = %v, %v", report, err)
+	}
+	if _, err := uc.Verify(context.Background(), "628111", code, from, now.Add(time.Minute)); !errors.Is(err, ErrLoginInvalidCode) {
+		t.Fatalf("a code must only work once, got %v", err)
+	}
+
+	want := []string{domain.LoginEventCodeSent, domain.LoginEventFailed, domain.LoginEventVerified, domain.LoginEventFailed}
+	if got := repo.auditEvents(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("audit = %v, want %v", got, want)
+	}
+	if repo.audit[0].IP != "10.0.0.1" || repo.audit[0].UserAgent != "test" {
+		t.Fatalf("audit should record the client, got %+v", repo.audit[0])
+	}
+}
+
+func TestLogin_WrongGuessesLockTheCode(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	repo := newLoginRepoStub(&domain.Report{UserID: "628111"})
+	uc, client := newTestLoginUsecase(t, repo)
+
+	if _, err := uc.RequestCode(context.Background(), "628111", LoginClient{}, now); err != nil {
+		t.Fatalf("RequestCode: %v", err)
+	}
+	code := sentLoginCode(t, client)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	for i := 1; i < loginMaxAttempts; i++ {
+		if _, err := uc.Verify(context.Background(), "628111", wrong, LoginClient{}, now); !errors.Is(err, ErrLoginInvalidCode) {
+			t.Fatalf("attempt %d: got %v", i, err)
+		}
+	}
+	if _, err := uc.Verify(context.Background(), "628111", wrong, LoginClient{}, now); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("last attempt should lock the code, got %v", err)
+	}
+	if _, err := uc.Verify(context.Background(), "628111", code, LoginClient{}, now); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("a locked code must not work, got %v", err)
+	}
+	if events := repo.auditEvents(); events[len(events)-1] != domain.LoginEventLocked {
+		t.Fatalf("lock should be audited, got %v", events)
+	}
+}
+
+func TestLogin_CodeExpires(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	repo := newLoginRepoStub(&domain.Report{UserID: "628111"})
+	uc, client := newTestLoginUsecase(t, repo)
+
+	if _, err := uc.RequestCode(context.Background(), "628111", LoginClient{}, now); err != nil {
+		t.Fatalf("RequestCode: %v", err)
+	}
+	code := sentLoginCode(t, client)
+	if _, err := uc.Verify(context.Background(), "628111", code, LoginClient{}, now.Add(loginCodeTTL)); !errors.Is(err, ErrLoginCodeExpired) {
+		t.Fatalf("expired code: got %v", err)
+	}
+}
+
+func TestLogin_ResendIsThrottled(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	repo := newLoginRepoStub(&domain.Report{UserID: "628111"})
+	uc, _ := newTestLoginUsecase(t, repo)
+
+	if _, err := uc.RequestCode(context.Background(), "628111", LoginClient{}, now); err != nil {
+		t.Fatalf("RequestCode: %v", err)
+	}
+	sent, err := uc.RequestCode(context.Background(), "628111", LoginClient{}, now.Add(10*time.Second))
+	if !errors.Is(err, ErrLoginThrottled) || !sent.ResendAt.Equal(now.Add(loginResendInterval)) {
+		t.Fatalf("quick resend should be throttled until %v, got %+v, %v", now.Add(loginResendInterval), sent, err)
+	}
+
+	at := now
+	for i := 1; i < loginMaxSends; i++ {
+		at = at.Add(loginResendInterval)
+		if _, err := uc.RequestCode(context.Background(), "628111", LoginClient{}, at); err != nil {
+			t.Fatalf("send %d: %v", i+1, err)
+		}
+	}
+	sent, err = uc.RequestCode(context.Background(), "628111", LoginClient{}, at.Add(loginResendInterval))
+	if !errors.Is(err, ErrLoginThrottled) || !sent.ResendAt.Equal(now.Add(loginSendWindow)) {
+		t.Fatalf("sends should be capped until the window ends, got %+v, %v", sent, err)
+	}
+	if _, err := uc.RequestCode(context.Background(), "628111", LoginClient{}, now.Add(loginSendWindow)); err != nil {
+		t.Fatalf("a new window should allow sending again: %v", err)
+	}
+}
+
+func TestLogin_ParallelRequestsSendOneCode(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	repo := newLoginRepoStub(&domain.Report{UserID: "628111"})
+	uc, client := newTestLoginUsecase(t, repo)
+
+	// Another request sends a code between our read and our save.
+	repo.beforeSave = func() {
+		repo.challenges["628111"] = domain.LoginChallenge{
+			UserID:          "628111",
+			CodeHash:        "other",
+			Attempts:        3,
+			SendCount:       1,
+			WindowStartedAt: now,
+			LastSentAt:      now,
+			ExpiresAt:       now.Add(loginCodeTTL),
+		}
+	}
+	if _, err := uc.RequestCode(context.Background(), "628111", LoginClient{}, now); !errors.Is(err, ErrLoginThrottled) {
+		t.Fatalf("the losing request should be throttled, got %v", err)
+	}
+	if client.sentMsg != nil {
+		t.Fatal("the losing request must not send a code")
+	}
+	if got := repo.challenges["628111"]; got.CodeHash != "other" || got.Attempts != 3 {
+		t.Fatalf("the losing request must not reset the challenge, got %+v", got)
+	}
+}
+
+func TestLogin_UnknownUserGetsNoCode(t *testing.T) {
+	repo := newLoginRepoStub(nil)
+	uc, client := newTestLoginUsecase(t, repo)
+
+	if _, err := uc.RequestCode(context.Background(), "628999", LoginClient{}, time.Now()); !errors.Is(err, ErrLoginUnknownUser) {
+		t.Fatalf("got %v", err)
+	}
+	if client.sentMsg != nil {
+		t.Fatal("no DM should be sent to an unknown number")
+	}
+}
+
+func TestLogin_DashboardLinkIsSingleUse(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	repo := newLoginRepoStub(&domain.Report{UserID: "628111", Name: "Budi"})
+	uc, _ := newTestLoginUsecase(t, repo)
+	ctx := context.Background()
+
+	reply, err := uc.ExecuteDashboard(ctx, "628111", now)
+	if err != nil {
+		t.Fatalf("ExecuteDashboard: %v", err)
+	}
+	_, rawURL, ok := strings.Cut(reply, "https://lapor.example/login?token=")
+	if !ok {
+		t.Fatalf("reply should carry a login link, got %q", reply)
+	}
+	token, _, _ := strings.Cut(rawURL, "\n")
+	token, err = url.QueryUnescape(token)
+	if err != nil {
+		t.Fatalf("unescape token: %v", err)
+	}
+
+	report, err := uc.RedeemLink(ctx, token, LoginClient{IP: "10.0.0.1"}, now.Add(time.Minute))
+	if err != nil || report.Name != "Budi" {
+		t.Fatalf("RedeemLink = %v, %v", report, err)
+	}
+	if _, err := uc.RedeemLink(ctx, token, LoginClient{}, now.Add(time.Minute)); !errors.Is(err, ErrLoginLinkInvalid) {
+		t.Fatalf("a link must only work once, got %v", err)
+	}
+	want := []string{domain.LoginEventLinkSent, domain.LoginEventLinkUsed}
+	if got := repo.auditEvents(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("audit = %v, want %v", got, want)
+	}
+}
+
+func TestLogin_DashboardLinkIsBoundToItsUser(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	repo := newLoginRepoStub(&domain.Report{UserID: "628111"})
+	uc, _ := newTestLoginUsecase(t, repo)
+	ctx := context.Background()
+
+	if _, err := uc.IssueLink(ctx, "628111", now); err != nil {
+		t.Fatalf("IssueLink: %v", err)
+	}
+	var nonce string
+	for n := range repo.links {
+		nonce = n
+	}
+
+	// A valid signature for another user doesn't fit this link.
+	forged := nonce + "." + uc.signLink("628999", nonce)
+	if _, err := uc.RedeemLink(ctx, forged, LoginClient{}, now); !errors.Is(err, ErrLoginLinkInvalid) {
+		t.Fatalf("forged link: got %v", err)
+	}
+
+	if _, err := uc.IssueLink(ctx, "628111", now); err != nil {
+		t.Fatalf("IssueLink: %v", err)
+	}
+	for n := range repo.links {
+		if n != nonce {
+			nonce = n
+		}
+	}
+	valid := nonce + "." + uc.signLink("628111", nonce)
+	if _, err := uc.RedeemLink(ctx, valid, LoginClient{}, now.Add(loginLinkTTL)); !errors.Is(err, ErrLoginLinkInvalid) {
+		t.Fatalf("expired link: got %v", err)
+	}
+	for _, token := range []string{"", "nodot", nonce + "."} {
+		if _, err := uc.RedeemLink(ctx, token, LoginClient{}, now); !errors.Is(err, ErrLoginLinkInvalid) {
+			t.Fatalf("token %q: got %v", token, err)
+		}
+	}
+}
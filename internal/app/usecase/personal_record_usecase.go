@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// PersonalRecordEvent is what an accepted report (WhatsApp text, Hevy,
+// Strava or side quest) tells the personal-records tracker.
+type PersonalRecordEvent struct {
+	UserID       string
+	Name         string
+	EventKey     string
+	ActivityText string
+	Metrics      domain.ActivityMetrics
+	Workout      *domain.Workout
+	ActivityDate time.Time
+	OccurredAt   time.Time
+}
+
+// PersonalRecordRecorder updates the records of an accepted report and
+// returns the lines for the reporter's reply.
+type PersonalRecordRecorder func(ctx context.Context, event PersonalRecordEvent) string
+
+// PersonalRecordNotifier receives the group announcement for new records.
+type PersonalRecordNotifier func(ctx context.Context, message string)
+
+type PersonalRecordUsecase struct {
+	repo     domain.ReportRepository
+	notifier PersonalRecordNotifier
+}
+
+func NewPersonalRecordUsecase(repo domain.ReportRepository) *PersonalRecordUsecase {
+	return &PersonalRecordUsecase{repo: repo}
+}
+
+// SetNotifier enables group announcements of new records.
+func (u *PersonalRecordUsecase) SetNotifier(fn PersonalRecordNotifier) {
+	u.notifier = fn
+}
+
+func (u *PersonalRecordUsecase) records() (domain.PersonalRecordRepository, bool) {
+	records, ok := u.repo.(domain.PersonalRecordRepository)
+	return records, ok
+}
+
+// newPersonalRecord is a record that beat the user's previous best.
+type newPersonalRecord struct {
+	recordType *domain.PersonalRecordType
+	value      int
+	previous   int
+}
+
+// Record implements PersonalRecordRecorder. The first value of a record type
+// only sets the baseline; later values are announced when they beat it.
+// Errors are logged so records never block the report itself.
+func (u *PersonalRecordUsecase) Record(ctx context.Context, event PersonalRecordEvent) string {
+	records, ok := u.records()
+	if !ok {
+		return ""
+	}
+	history, err := records.GetPersonalRecordHistory(ctx, event.UserID, "")
+	if err != nil {
+		log.Printf("records: failed to load history for %s: %v", event.UserID, err)
+		return ""
+	}
+
+	candidates := domain.EffortRecordCandidates(event.EventKey, event.ActivityText, event.Metrics, event.Workout)
+	candidates = append(candidates, u.aggregateCandidates(ctx, event)...)
+
+	var improved []newPersonalRecord
+	for _, c := range candidates {
+		t := domain.FindPersonalRecordType(c.RecordType)
+		if t == nil {
+			continue
+		}
+		best, hasBest, same, hasSame := splitRecordHistory(history, t, c.Ref)
+		if hasSame && !t.Beats(c.Value, same.Value) {
+			continue
+		}
+		if hasBest && !t.Beats(c.Value, best.Value) {
+			continue
+		}
+
+		rec := domain.PersonalRecord{
+			UserID:       event.UserID,
+			RecordType:   c.RecordType,
+			Ref:          c.Ref,
+			EventKey:     event.EventKey,
+			Value:        c.Value,
+			ActivityDate: event.ActivityDate,
+			AchievedAt:   event.OccurredAt,
+		}
+		if err := records.SavePersonalRecord(ctx, rec); err != nil {
+			log.Printf("records: failed to save %s for %s: %v", c.RecordType, event.UserID, err)
+			continue
+		}
+
+		// A period that already held the record just extends it quietly.
+		alreadyRecord := hasSame && hasBest && t.Beats(same.Value, best.Value)
+		if hasBest && !alreadyRecord {
+			improved = append(improved, newPersonalRecord{recordType: t, value: c.Value, previous: best.Value})
+		}
+	}
+
+	if len(improved) == 0 {
+		return ""
+	}
+	if u.notifier != nil {
+		u.notifier(ctx, formatPersonalRecordAnnouncement(event.Name, improved))
+	}
+	lines := make([]string, 0, len(improved))
+	for _, pr := range improved {
+		lines = append(lines, fmt.Sprintf("🥇 *REKOR PRIBADI!* %s %s: %s (sebelumnya %s)",
+			pr.recordType.Icon, pr.recordType.Name, pr.recordType.FormatValue(pr.value), pr.recordType.FormatValue(pr.previous)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// aggregateCandidates totals the report's week and month. The event itself is
+// already stored when the recorder runs, so it is part of the totals.
+func (u *PersonalRecordUsecase) aggregateCandidates(ctx context.Context, event PersonalRecordEvent) []domain.PersonalRecordCandidate {
+	var out []domain.PersonalRecordCandidate
+	if event.Metrics.DistanceMeters > 0 {
+		if total := u.weeklyDistance(ctx, event.UserID, event.ActivityDate); total > 0 {
+			out = append(out, domain.PersonalRecordCandidate{RecordType: domain.RecordBiggestWeeklyDistance, Ref: domain.WeekRecordRef(event.ActivityDate), Value: total})
+		}
+	}
+	if activeDays := u.monthActiveDays(ctx, event.UserID, event.ActivityDate); activeDays > 0 {
+		out = append(out, domain.PersonalRecordCandidate{RecordType: domain.RecordMostActiveMonth, Ref: domain.MonthRecordRef(event.ActivityDate), Value: activeDays})
+	}
+	return out
+}
+
+// weeklyDistance totals the distance of the ISO week holding date.
+func (u *PersonalRecordUsecase) weeklyDistance(ctx context.Context, userID string, date time.Time) int {
+	metricsRepo, ok := u.repo.(domain.ActivityMetricsRepository)
+	if !ok {
+		return 0
+	}
+	weekStart := domain.GetStartOfISOWeek(date)
+	activities, err := metricsRepo.GetActivityRecords(ctx, userID, weekStart, weekStart.AddDate(0, 0, 7))
+	if err != nil {
+		log.Printf("records: failed to load weekly distance for %s: %v", userID, err)
+		return 0
+	}
+	total := 0
+	for _, a := range activities {
+		total += a.DistanceMeters
+	}
+	return total
+}
+
+// monthActiveDays counts the active days of the calendar month holding date.
+func (u *PersonalRecordUsecase) monthActiveDays(ctx context.Context, userID string, date time.Time) int {
+	dates, err := u.repo.GetUserActivityDates(ctx, userID)
+	if err != nil {
+		log.Printf("records: failed to load active days for %s: %v", userID, err)
+		return 0
+	}
+	month := domain.MonthRecordRef(date)
+	activeDays := 0
+	for _, d := range dates {
+		if domain.MonthRecordRef(d) == month {
+			activeDays++
+		}
+	}
+	return activeDays
+}
+
+// Retract implements CancelledEventHook: it drops the records the cancelled
+// report event wrote. A week or month total it last extended is counted
+// again from what is left, so the period keeps its other reports.
+func (u *PersonalRecordUsecase) Retract(ctx context.Context, userID, eventID string) {
+	records, ok := u.records()
+	if !ok {
+		return
+	}
+	removed, err := records.DeletePersonalRecordsByEvent(ctx, userID, eventID)
+	if err != nil {
+		log.Printf("records: failed to retract %s for %s: %v", eventID, userID, err)
+		return
+	}
+	for _, rec := range removed {
+		value := 0
+		switch rec.RecordType {
+		case domain.RecordBiggestWeeklyDistance:
+			value = u.weeklyDistance(ctx, userID, rec.ActivityDate)
+		case domain.RecordMostActiveMonth:
+			value = u.monthActiveDays(ctx, userID, rec.ActivityDate)
+		}
+		if value <= 0 {
+			continue
+		}
+		rec.Value, rec.EventKey = value, ""
+		if err := records.SavePersonalRecord(ctx, rec); err != nil {
+			log.Printf("records: failed to recount %s for %s: %v", rec.RecordType, userID, err)
+		}
+	}
+}
+
+// splitRecordHistory returns the best entry of a type excluding ref, and the
+// entry for ref itself.
+func splitRecordHistory(history []domain.PersonalRecord, t *domain.PersonalRecordType, ref string) (best domain.PersonalRecord, hasBest bool, same domain.PersonalRecord, hasSame bool) {
+	for _, rec := range history {
+		if rec.RecordType != t.ID {
+			continue
+		}
+		if rec.Ref == ref {
+			same, hasSame = rec, true
+			continue
+		}
+		if !hasBest || t.Beats(rec.Value, best.Value) {
+			best, hasBest = rec, true
+		}
+	}
+	return best, hasBest, same, hasSame
+}
+
+// Current returns the user's current records in display order, along with
+// the full history they were reduced from.
+func (u *PersonalRecordUsecase) Current(ctx context.Context, userID string) ([]domain.PersonalRecord, []domain.PersonalRecord, error) {
+	records, ok := u.records()
+	if !ok {
+		return nil, nil, nil
+	}
+	history, err := records.GetPersonalRecordHistory(ctx, userID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return domain.CurrentPersonalRecords(history), history, nil
+}
+
+// Execute renders the /pr reply.
+func (u *PersonalRecordUsecase) Execute(ctx context.Context, userID, name string) (string, error) {
+	current, _, err := u.Current(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load personal records: %w", err)
+	}
+	if len(current) == 0 {
+		return "📭 Belum ada rekor pribadi. Lapor dengan detail jarak/waktu (contoh: `/lapor lari 5km 28 menit`) biar rekormu tercatat!", nil
+	}
+
+	sb := new(strings.Builder)
+	if isUnknownName(name) {
+		sb.WriteString("🥇 *REKOR PRIBADI*\n\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("🥇 *REKOR PRIBADI %s*\n\n", strings.ToUpper(name)))
+	}
+	for _, rec := range current {
+		t := domain.FindPersonalRecordType(rec.RecordType)
+		sb.WriteString(fmt.Sprintf("%s %s: *%s* (%s)\n", t.Icon, t.Name, t.FormatValue(rec.Value), formatRecordDate(t, rec)))
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+func formatRecordDate(t *domain.PersonalRecordType, rec domain.PersonalRecord) string {
+	switch t.ID {
+	case domain.RecordBiggestWeeklyDistance:
+		return "minggu " + domain.GetStartOfISOWeek(rec.ActivityDate).Format("02 Jan 2006")
+	case domain.RecordMostActiveMonth:
+		return rec.ActivityDate.Format("Jan 2006")
+	default:
+		return rec.ActivityDate.Format("02 Jan 2006")
+	}
+}
+
+func formatPersonalRecordAnnouncement(name string, records []newPersonalRecord) string {
+	sb := new(strings.Builder)
+	sb.WriteString("🥇 *REKOR PRIBADI BARU!* 🥇\n\n")
+	sb.WriteString(fmt.Sprintf("%s baru saja memecahkan rekornya sendiri:\n", name))
+	for _, pr := range records {
+		sb.WriteString(fmt.Sprintf("%s %s: %s (sebelumnya %s)\n", pr.recordType.Icon, pr.recordType.Name, pr.recordType.FormatValue(pr.value), pr.recordType.FormatValue(pr.previous)))
+	}
+	sb.WriteString("\nGas terus! 🔥")
+	return sb.String()
+}
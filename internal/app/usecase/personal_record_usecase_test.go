@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockRecordRepo struct {
+	domain.ReportRepository
+	records    []domain.PersonalRecord
+	activities []domain.ActivityRecord
+	dates      []time.Time
+}
+
+func (m *mockRecordRepo) SavePersonalRecord(ctx context.Context, rec domain.PersonalRecord) error {
+	for i := range m.records {
+		if m.records[i].RecordType == rec.RecordType && m.records[i].Ref == rec.Ref {
+			m.records[i] = rec
+			return nil
+		}
+	}
+	m.records = append(m.records, rec)
+	return nil
+}
+
+func (m *mockRecordRepo) GetPersonalRecordHistory(ctx context.Context, userID, recordType string) ([]domain.PersonalRecord, error) {
+	return append([]domain.PersonalRecord(nil), m.records...), nil
+}
+
+func (m *mockRecordRepo) DeletePersonalRecordsByEvent(ctx context.Context, userID, eventKey string) ([]domain.PersonalRecord, error) {
+	var kept, removed []domain.PersonalRecord
+	for _, rec := range m.records {
+		if rec.EventKey == eventKey {
+			removed = append(removed, rec)
+		} else {
+			kept = append(kept, rec)
+		}
+	}
+	m.records = kept
+	return removed, nil
+}
+
+func (m *mockRecordRepo) GetActivityRecords(ctx context.Context, userID string, startDate, endDate time.Time) ([]domain.ActivityRecord, error) {
+	return m.activities, nil
+}
+
+func (m *mockRecordRepo) GetUserActivityDates(ctx context.Context, userID string) ([]time.Time, error) {
+	return m.dates, nil
+}
+
+func (m *mockRecordRepo) report(uc *PersonalRecordUsecase, key, text string, now time.Time) string {
+	metrics := domain.ParseActivityMetrics(text)
+	day := domain.GetToday(now)
+	m.activities = append(m.activities, domain.ActivityRecord{EventID: key, ActivityDate: day, ActivityMetrics: metrics})
+	m.dates = append(m.dates, day)
+	return uc.Record(context.Background(), PersonalRecordEvent{
+		UserID: "u1", Name: "Alice", EventKey: key, ActivityText: text,
+		Metrics: metrics, ActivityDate: day, OccurredAt: now,
+	})
+}
+
+func TestPersonalRecordUsecase_BaselineThenAnnounce(t *testing.T) {
+	repo := &mockRecordRepo{}
+	uc := NewPersonalRecordUsecase(repo)
+	var announcements []string
+	uc.SetNotifier(func(ctx context.Context, message string) { announcements = append(announcements, message) })
+
+	monday := time.Date(2026, time.September, 7, 7, 0, 0, 0, time.UTC)
+	if line := repo.report(uc, "evt-1", "lari 5km 30 menit", monday); line != "" {
+		t.Fatalf("first report should only set baselines, got %q", line)
+	}
+
+	line := repo.report(uc, "evt-2", "lari 6km 33 menit", monday.AddDate(0, 0, 1))
+	for _, want := range []string{"5K Tercepat (estimasi): 27:30 (sebelumnya 30:00)", "Lari Terjauh: 6.0 km (sebelumnya 5.0 km)"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected reply to contain %q, got %q", want, line)
+		}
+	}
+	// Weekly distance and active days grow within the period that set the
+	// baseline, so they update quietly instead of being announced.
+	if strings.Contains(line, "Jarak Mingguan") || strings.Contains(line, "Bulan Paling Aktif") {
+		t.Errorf("same-period aggregates must not be announced, got %q", line)
+	}
+	if len(announcements) != 1 || !strings.Contains(announcements[0], "Alice baru saja memecahkan rekornya") {
+		t.Fatalf("expected one group announcement, got %v", announcements)
+	}
+
+	current, _, err := uc.Current(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("Current failed: %v", err)
+	}
+	for _, rec := range current {
+		if rec.RecordType == domain.RecordBiggestWeeklyDistance && rec.Value != 11000 {
+			t.Errorf("weekly distance = %d, want 11000", rec.Value)
+		}
+	}
+
+	msg, err := uc.Execute(context.Background(), "u1", "Alice")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.Contains(msg, "REKOR PRIBADI ALICE") || !strings.Contains(msg, "⚡ 5K Tercepat (estimasi): *27:30* (08 Sep 2026)") {
+		t.Fatalf("unexpected /pr reply: %q", msg)
+	}
+}
+
+func TestPersonalRecordUsecase_RetractCancelledReport(t *testing.T) {
+	repo := &mockRecordRepo{}
+	uc := NewPersonalRecordUsecase(repo)
+	ctx := context.Background()
+
+	monday := time.Date(2026, time.September, 7, 7, 0, 0, 0, time.UTC)
+	repo.report(uc, "evt-1", "lari 5km 30 menit", monday)
+	repo.report(uc, "evt-2", "lari 6km 33 menit", monday.AddDate(0, 0, 1))
+
+	// The cancel removes the report's log before its hooks run.
+	repo.activities = repo.activities[:1]
+	repo.dates = repo.dates[:1]
+	uc.Retract(ctx, "u1", "evt-2")
+
+	current, _, err := uc.Current(ctx, "u1")
+	if err != nil {
+		t.Fatalf("Current failed: %v", err)
+	}
+	want := map[string]int{
+		domain.RecordFastest5K:             30 * 60,
+		domain.RecordLongestRun:            5000,
+		domain.RecordBiggestWeeklyDistance: 5000,
+		domain.RecordMostActiveMonth:       1,
+	}
+	if len(current) != len(want) {
+		t.Fatalf("current records = %+v, want %v", current, want)
+	}
+	for _, rec := range current {
+		if rec.Value != want[rec.RecordType] {
+			t.Errorf("%s = %d, want %d", rec.RecordType, rec.Value, want[rec.RecordType])
+		}
+	}
+}
+
+func TestPersonalRecordUsecase_ExecuteWithoutRecords(t *testing.T) {
+	msg, err := NewPersonalRecordUsecase(&mockRecordRepo{}).Execute(context.Background(), "u1", "Alice")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.Contains(msg, "Belum ada rekor pribadi") {
+		t.Fatalf("unexpected reply: %q", msg)
+	}
+}
@@ -4,20 +4,33 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
 	"github.com/fardannozami/whatsapp-gateway/internal/infra/strava"
-	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/types"
 )
 
+// stravaReportSource is the report event source for Strava auto-reports.
+const stravaReportSource = "strava"
+
+// stravaReportableTypes are the Strava activity types that are auto-reported.
+var stravaReportableTypes = map[string]bool{
+	"Run": true, "Ride": true, "Walk": true, "Hike": true, "Swim": true,
+	"Workout": true, "WeightTraining": true, "Crossfit": true, "Yoga": true,
+	"VirtualRun": true, "VirtualRide": true,
+}
+
 type ProcessStravaWebhookUsecase struct {
 	repo         domain.ReportRepository
 	stravaClient *strava.Client
 	reportUC     *ReportActivityUsecase
+	cancelUC     *CancelReportUsecase
+	sender       StravaNotificationSender
 	groupID      string
 }
 
@@ -31,10 +44,36 @@ func NewProcessStravaWebhookUsecase(
 		repo:         repo,
 		stravaClient: stravaClient,
 		reportUC:     reportUC,
+		cancelUC:     NewCancelReportUsecase(repo),
 		groupID:      groupID,
 	}
 }
 
+// SetEventPublisher reports cancellations caused by deleted or changed
+// Strava activities to the same hook as /cancel.
+func (uc *ProcessStravaWebhookUsecase) SetEventPublisher(fn BotEventPublisher) {
+	uc.cancelUC.SetEventPublisher(fn)
+}
+
+// SetCancelUsecase cancels the reports of deleted or changed Strava
+// activities through the bot's /cancel, so its hooks also take back the
+// raid damage and personal records of those reports.
+func (uc *ProcessStravaWebhookUsecase) SetCancelUsecase(cancelUC *CancelReportUsecase) {
+	uc.cancelUC = cancelUC
+}
+
+// StravaNotificationSender queues Strava notifications for WhatsApp;
+// *queue.MessageSender implements it.
+type StravaNotificationSender interface {
+	SendNormalPriority(ctx context.Context, target types.JID, msg *waE2E.Message) error
+}
+
+// SetSender routes Strava notifications through the WhatsApp send queue.
+// Without a sender, notifications are only logged.
+func (uc *ProcessStravaWebhookUsecase) SetSender(sender StravaNotificationSender) {
+	uc.sender = sender
+}
+
 type StravaWebhookEvent struct {
 	ObjectType     string            `json:"object_type"`
 	ObjectID       int64             `json:"object_id"`
@@ -45,16 +84,42 @@ type StravaWebhookEvent struct {
 	Updates        map[string]string `json:"updates"`
 }
 
-func (uc *ProcessStravaWebhookUsecase) Execute(ctx context.Context, waClient *whatsmeow.Client, event StravaWebhookEvent) error {
+func (uc *ProcessStravaWebhookUsecase) activities() (domain.StravaActivityRepository, bool) {
+	activities, ok := uc.repo.(domain.StravaActivityRepository)
+	return activities, ok
+}
+
+func (uc *ProcessStravaWebhookUsecase) Execute(ctx context.Context, event StravaWebhookEvent) error {
 	log.Printf("Processing Strava event: %+v", event)
 
-	// Only process new activities
-	if event.ObjectType != "activity" || event.AspectType != "create" {
-		log.Printf("Ignoring Strava event: not a new activity (ObjectType=%s, AspectType=%s)", event.ObjectType, event.AspectType)
+	if event.ObjectType == "athlete" {
+		if event.AspectType == "update" && event.Updates["authorized"] == "false" {
+			return uc.deauthorize(ctx, event)
+		}
+		log.Printf("Ignoring Strava athlete event (AspectType=%s)", event.AspectType)
+		return nil
+	}
+	if event.ObjectType != "activity" {
+		log.Printf("Ignoring Strava event: unknown object type %s", event.ObjectType)
 		return nil
 	}
 
-	// 1. Get Strava account
+	switch event.AspectType {
+	case "create":
+		return uc.create(ctx, event)
+	case "update":
+		return uc.update(ctx, event)
+	case "delete":
+		return uc.remove(ctx, event)
+	default:
+		log.Printf("Ignoring Strava activity event: unknown aspect type %s", event.AspectType)
+		return nil
+	}
+}
+
+// create reports a new activity. The activity ID is claimed first so a
+// retried event is dropped instead of reported twice.
+func (uc *ProcessStravaWebhookUsecase) create(ctx context.Context, event StravaWebhookEvent) error {
 	account, err := uc.repo.GetStravaAccountByAthleteID(ctx, event.AthleteID)
 	if err != nil {
 		return err
@@ -65,119 +130,444 @@ func (uc *ProcessStravaWebhookUsecase) Execute(ctx context.Context, waClient *wh
 	}
 	log.Printf("Found linked account for user %s", account.UserID)
 
-	// 2. Check if token needs refresh
-	if time.Now().After(account.ExpiresAt.Add(-5 * time.Minute)) {
-		log.Printf("Refreshing Strava token for user %s", account.UserID)
-		refreshed, err := uc.stravaClient.RefreshToken(account.RefreshToken)
+	now := time.Now()
+	uc.markWebhookSeen(ctx, account.UserID, now)
+	activities, tracked := uc.activities()
+	if tracked {
+		claimed, err := activities.ClaimStravaActivity(ctx, domain.StravaActivity{
+			ActivityID: event.ObjectID,
+			UserID:     account.UserID,
+			AthleteID:  account.AthleteID,
+			Status:     domain.StravaActivityPending,
+			CreatedAt:  now,
+		})
 		if err != nil {
 			return err
 		}
-		refreshed.UserID = account.UserID
-		refreshed.AthleteID = account.AthleteID
-		if err := uc.repo.UpsertStravaAccount(ctx, refreshed); err != nil {
-			return err
+		if !claimed {
+			log.Printf("Ignoring duplicate Strava create event for activity %d", event.ObjectID)
+			return nil
+		}
+	}
+	release := func() {
+		if tracked {
+			if err := activities.DeleteStravaActivity(ctx, event.ObjectID); err != nil {
+				log.Printf("Failed to release Strava activity %d: %v", event.ObjectID, err)
+			}
 		}
-		account = refreshed
 	}
 
-	// 3. Get activity details
-	activity, err := uc.stravaClient.GetActivity(account.AccessToken, event.ObjectID)
+	activity, err := uc.fetchActivity(ctx, account, event.ObjectID)
 	if err != nil {
-		log.Printf("Failed to get Strava activity %d: %v", event.ObjectID, err)
+		release()
 		return err
 	}
-	log.Printf("Fetched activity: %s (%s)", activity.Name, activity.Type)
 
-	// 4. Filter activity types (Run, Ride, Swim, Walk, etc.)
-	// You can customize this list
-	validTypes := map[string]bool{
-		"Run": true, "Ride": true, "Walk": true, "Hike": true, "Swim": true,
-		"Workout": true, "WeightTraining": true, "Crossfit": true, "Yoga": true,
-		"VirtualRun": true, "VirtualRide": true,
-	}
-	if !validTypes[activity.Type] {
+	if !uc.autoReports(ctx, account.UserID, activity.Type) {
 		log.Printf("Ignoring activity type: %s (User: %s)", activity.Type, account.UserID)
+		if tracked {
+			return activities.SaveStravaActivity(ctx, stravaActivityRecord(account, activity, domain.StravaActivitySkipped, now))
+		}
 		return nil
 	}
 
-	// 5. Trigger Report
-	var workout *domain.HevyWorkout
-	workoutTypes := map[string]bool{
-		"workout": true, "weighttraining": true, "yoga": true, "crossfit": true,
-		"hiit": true, "pilates": true, "rockclimbing": true,
+	if _, err := uc.report(ctx, account, activity, now); err != nil {
+		release()
+		return err
 	}
+	return nil
+}
+
+// report files the activity as a report on the day it happened and
+// announces it in the group.
+func (uc *ProcessStravaWebhookUsecase) report(ctx context.Context, account *domain.StravaAccount, activity *strava.Activity, now time.Time) (string, error) {
+	workout := activity.Workout()
+	activityDate := activity.LocalDate()
+	log.Printf("Activity Type: %s (mapped=%q, date=%s)", activity.Type, workout.ActivityType, activityDate.Format(time.DateOnly))
 
-	activityTypeLower := strings.ToLower(activity.Type)
-	isWorkout := workoutTypes[activityTypeLower]
-	log.Printf("Activity Type: %s (isWorkout=%v)", activity.Type, isWorkout)
+	name, err := uc.userName(ctx, account)
+	if err != nil {
+		return "", err
+	}
 
-	if isWorkout {
-		workout = &domain.HevyWorkout{
-			Title: activity.Name,
-			Time:  "", // Strava time is usually duration_seconds
+	response, eventKey, err := uc.reportUC.ExecuteFromSource(ctx, account.UserID, name, stravaReportSource, "", workout, activityDate)
+	if err != nil {
+		log.Printf("Failed to execute report usecase: %v", err)
+		return "", err
+	}
+	log.Printf("Report triggered successfully for %s. Response: %s", name, response)
+
+	if activities, ok := uc.activities(); ok {
+		status := domain.StravaActivityReported
+		if eventKey == "" {
+			// Over the daily limit or too old: nothing to cancel later.
+			status = domain.StravaActivityRejected
 		}
-		if activity.Description != "" {
-			lines := strings.Split(activity.Description, "\n")
-			for _, line := range lines {
-				trimmed := strings.TrimSpace(line)
-				if trimmed != "" {
-					workout.Exercises = append(workout.Exercises, trimmed)
-				}
-			}
+		record := stravaActivityRecord(account, activity, status, now)
+		record.EventKey = eventKey
+		if err := activities.SaveStravaActivity(ctx, record); err != nil {
+			log.Printf("Failed to save Strava activity %d: %v", activity.ID, err)
 		}
 	}
 
-	report, err := uc.repo.GetReport(ctx, account.UserID)
+	notification := fmt.Sprintf("🚴‍♂️ *STRAVA AUTO-REPORT* 🏃‍♂️\n\n🎯 Activity: %s\n📅 Type: %s\n%s\n%s",
+		activity.Name, activity.Type, stravaActivityExtras(activity), response)
+	uc.notify(ctx, account.UserID, notification)
+	return eventKey, nil
+}
+
+// stravaActivityRecord captures the activity's metadata for the
+// strava_activities table.
+func stravaActivityRecord(account *domain.StravaAccount, activity *strava.Activity, status string, now time.Time) domain.StravaActivity {
+	activityDate := activity.LocalDate()
+	if activityDate.IsZero() {
+		activityDate = domain.GetToday(now)
+	}
+	return domain.StravaActivity{
+		ActivityID:          activity.ID,
+		UserID:              account.UserID,
+		AthleteID:           account.AthleteID,
+		ActivityType:        activity.Type,
+		ActivityDate:        activityDate,
+		Status:              status,
+		Name:                activity.Name,
+		StartDateLocal:      activity.StartDateLocal,
+		DistanceMeters:      int(math.Round(activity.Distance)),
+		MovingSeconds:       activity.MovingTime,
+		ElapsedSeconds:      activity.ElapsedTime,
+		ElevationGainMeters: activity.TotalElevationGain,
+		AverageHeartrate:    activity.AverageHeartrate,
+		MaxHeartrate:        activity.MaxHeartrate,
+		AverageSpeed:        activity.AverageSpeed,
+		Calories:            int(math.Round(activity.Calories)),
+		DeviceName:          activity.DeviceName,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+}
+
+// stravaActivityExtras lists what the report reply does not already show:
+// elevation, heart rate and the recording device.
+func stravaActivityExtras(activity *strava.Activity) string {
+	var lines []string
+	if activity.TotalElevationGain >= 1 {
+		lines = append(lines, fmt.Sprintf("⛰️ Elevasi: %.0f m", activity.TotalElevationGain))
+	}
+	if activity.HasHeartrate && activity.AverageHeartrate > 0 {
+		hr := fmt.Sprintf("❤️ HR: %.0f bpm", activity.AverageHeartrate)
+		if activity.MaxHeartrate > 0 {
+			hr += fmt.Sprintf(" (maks %.0f)", activity.MaxHeartrate)
+		}
+		lines = append(lines, hr)
+	}
+	if activity.DeviceName != "" {
+		lines = append(lines, "⌚ "+activity.DeviceName)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// update re-evaluates an edited activity: a report whose new type no longer
+// counts is cancelled, and a skipped activity that now counts is reported.
+func (uc *ProcessStravaWebhookUsecase) update(ctx context.Context, event StravaWebhookEvent) error {
+	activities, ok := uc.activities()
+	if !ok {
+		return nil
+	}
+	tracked, err := activities.GetStravaActivity(ctx, event.ObjectID)
 	if err != nil {
 		return err
 	}
+	if tracked == nil {
+		// We never saw the create event (e.g. it failed); treat it as new.
+		return uc.create(ctx, event)
+	}
+	if tracked.Status == domain.StravaActivityPending {
+		if time.Since(tracked.UpdatedAt) > domain.StravaClaimTimeout {
+			// The create that claimed it never finished; report it now.
+			return uc.create(ctx, event)
+		}
+		log.Printf("Strava activity %d is still being processed; ignoring update", event.ObjectID)
+		return nil
+	}
 
-	name := account.Name
-	if name == "" {
-		name = "User"
+	account, err := uc.repo.GetStravaAccountByAthleteID(ctx, event.AthleteID)
+	if err != nil {
+		return err
 	}
-	if report != nil {
-		name = report.Name
+	if account == nil {
+		log.Printf("Strava athlete %d not linked to any WhatsApp user", event.AthleteID)
+		return nil
 	}
 
-	response, err := uc.reportUC.Execute(ctx, account.UserID, name, workout)
+	now := time.Now()
+	uc.markWebhookSeen(ctx, account.UserID, now)
+
+	activity, err := uc.fetchActivity(ctx, account, event.ObjectID)
 	if err != nil {
-		log.Printf("Failed to execute report usecase: %v", err)
 		return err
 	}
-	log.Printf("Report triggered successfully for %s. Response: %s", name, response)
+	reportable := uc.autoReports(ctx, account.UserID, activity.Type)
 
-	// 6. Send notification to group
-	if uc.groupID != "" && waClient != nil && waClient.IsConnected() {
-		targetJID, _ := types.ParseJID(uc.groupID)
-		log.Printf("Sending Strava auto-report to group JID: %s", targetJID.String())
+	switch {
+	case tracked.Status == domain.StravaActivityReported && !reportable:
+		log.Printf("Strava activity %d changed to %s; cancelling its report", event.ObjectID, activity.Type)
+		tracked.ActivityType = activity.Type
+		return uc.cancel(ctx, tracked, fmt.Sprintf("diubah jadi tipe %s yang tidak dihitung", activity.Type), now)
+	case (tracked.Status == domain.StravaActivitySkipped || tracked.Status == domain.StravaActivityCancelled) && reportable:
+		log.Printf("Strava activity %d changed to %s; reporting it", event.ObjectID, activity.Type)
+		_, err := uc.report(ctx, account, activity, now)
+		return err
+	default:
+		refreshed := stravaActivityRecord(account, activity, tracked.Status, now)
+		refreshed.EventKey = tracked.EventKey
+		// The report stays on the day it was filed even if the start time moved.
+		refreshed.ActivityDate = tracked.ActivityDate
+		refreshed.CreatedAt = tracked.CreatedAt
+		return activities.SaveStravaActivity(ctx, refreshed)
+	}
+}
 
-		// Handle workout-style activities without distance
-		activityLabel := "Activity"
-		distanceLine := fmt.Sprintf("📏 Distance: %.2f km\n", activity.Distance/1000)
+// remove cancels the report of a deleted activity.
+func (uc *ProcessStravaWebhookUsecase) remove(ctx context.Context, event StravaWebhookEvent) error {
+	activities, ok := uc.activities()
+	if !ok {
+		return nil
+	}
+	tracked, err := activities.GetStravaActivity(ctx, event.ObjectID)
+	if err != nil {
+		return err
+	}
+	if tracked == nil || tracked.Status != domain.StravaActivityReported {
+		log.Printf("Ignoring delete of Strava activity %d: no report to cancel", event.ObjectID)
+		return nil
+	}
+	return uc.cancel(ctx, tracked, "dihapus dari Strava", time.Now())
+}
 
-		if isWorkout || activity.Distance == 0 {
-			activityLabel = "Workout List"
-			if activity.Distance == 0 {
-				distanceLine = ""
-			}
-		}
+func (uc *ProcessStravaWebhookUsecase) cancel(ctx context.Context, tracked *domain.StravaActivity, reason string, now time.Time) error {
+	activities, _ := uc.activities()
+	name, err := uc.userName(ctx, &domain.StravaAccount{UserID: tracked.UserID})
+	if err != nil {
+		return err
+	}
 
-		notification := fmt.Sprintf("🚴‍♂️ *STRAVA AUTO-REPORT* 🏃‍♂️\n\n🎯 %s: %s\n%s📅 Type: %s\n\n%s",
-			activityLabel, activity.Name, distanceLine, activity.Type, response)
+	response, err := uc.cancelUC.CancelActivity(ctx, tracked.UserID, name, tracked.ActivityDate, tracked.EventKey)
+	if err != nil {
+		return err
+	}
+
+	tracked.Status = domain.StravaActivityCancelled
+	tracked.UpdatedAt = now
+	if err := activities.SaveStravaActivity(ctx, *tracked); err != nil {
+		return err
+	}
+
+	notification := fmt.Sprintf("🚫 *STRAVA AUTO-REPORT DIBATALKAN*\n\nAktivitas Strava milik %s %s, jadi laporannya ikut dibatalkan.\n\n%s",
+		name, reason, response)
+	uc.notify(ctx, tracked.UserID, notification)
+	return nil
+}
+
+// deauthorize unlinks an athlete who revoked the app's access on Strava.
+func (uc *ProcessStravaWebhookUsecase) deauthorize(ctx context.Context, event StravaWebhookEvent) error {
+	account, err := uc.repo.GetStravaAccountByAthleteID(ctx, event.AthleteID)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		log.Printf("Strava athlete %d deauthorized but was not linked", event.AthleteID)
+		return nil
+	}
+	activities, ok := uc.activities()
+	if !ok {
+		return nil
+	}
+	if err := activities.DeleteStravaAccount(ctx, account.UserID); err != nil {
+		return err
+	}
+	log.Printf("Unlinked Strava athlete %d from user %s after deauthorization", event.AthleteID, account.UserID)
+
+	message := "🔌 *Strava terputus*\n\nAkses Bot Lapor dicabut dari pengaturan Strava kamu, jadi akunmu sudah tidak terhubung lagi dan aktivitas baru tidak akan dilaporkan otomatis.\n\nKirim /strava kapan saja kalau mau menghubungkan lagi."
+	uc.send(ctx, types.NewJID(account.UserID, types.DefaultUserServer), message)
+	return nil
+}
+
+// refreshToken renews the account's access token when it is about to expire
+// and stores the new one.
+func (uc *ProcessStravaWebhookUsecase) refreshToken(ctx context.Context, account *domain.StravaAccount) error {
+	if time.Now().Before(account.ExpiresAt.Add(-5 * time.Minute)) {
+		return nil
+	}
+	log.Printf("Refreshing Strava token for user %s", account.UserID)
+	refreshed, err := uc.stravaClient.RefreshToken(account.RefreshToken)
+	if err != nil {
+		return err
+	}
+	refreshed.UserID = account.UserID
+	refreshed.AthleteID = account.AthleteID
+	refreshed.Name = account.Name
+	if err := uc.repo.UpsertStravaAccount(ctx, refreshed); err != nil {
+		return err
+	}
+	*account = *refreshed
+	return nil
+}
+
+// fetchActivity loads an activity, refreshing the account's token if needed.
+func (uc *ProcessStravaWebhookUsecase) fetchActivity(ctx context.Context, account *domain.StravaAccount, activityID int64) (*strava.Activity, error) {
+	if err := uc.refreshToken(ctx, account); err != nil {
+		return nil, err
+	}
+
+	activity, err := uc.stravaClient.GetActivity(account.AccessToken, activityID)
+	if err != nil {
+		log.Printf("Failed to get Strava activity %d: %v", activityID, err)
+		return nil, err
+	}
+	log.Printf("Fetched activity: %s (%s)", activity.Name, activity.Type)
+	return activity, nil
+}
+
+// preferences returns the user's auto-report settings, falling back to the
+// defaults when none are stored or the repository cannot hold them.
+func (uc *ProcessStravaWebhookUsecase) preferences(ctx context.Context, userID string) domain.StravaPreferences {
+	prefsRepo, ok := uc.repo.(domain.StravaPreferencesRepository)
+	if !ok {
+		return domain.DefaultStravaPreferences(userID)
+	}
+	prefs, err := prefsRepo.GetStravaPreferences(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load Strava preferences for user %s: %v", userID, err)
+		return domain.DefaultStravaPreferences(userID)
+	}
+	if prefs == nil {
+		return domain.DefaultStravaPreferences(userID)
+	}
+	return *prefs
+}
 
-		msg := &waE2E.Message{
-			Conversation: &notification,
+// autoReports reports whether an activity of this type should be reported
+// for the user: it must be supported and allowed by their preferences.
+func (uc *ProcessStravaWebhookUsecase) autoReports(ctx context.Context, userID, activityType string) bool {
+	return stravaReportableTypes[activityType] && uc.preferences(ctx, userID).AllowsType(activityType)
+}
+
+// notify announces an auto-report in the group, or only to the user when
+// they opted out of group posts.
+func (uc *ProcessStravaWebhookUsecase) notify(ctx context.Context, userID, text string) {
+	if uc.preferences(ctx, userID).PostToGroup {
+		uc.sendToGroup(ctx, text)
+		return
+	}
+	uc.send(ctx, types.NewJID(userID, types.DefaultUserServer), text)
+}
+
+// markWebhookSeen notes that webhooks still arrive for the user, which keeps
+// them out of the polling fallback.
+func (uc *ProcessStravaWebhookUsecase) markWebhookSeen(ctx context.Context, userID string, now time.Time) {
+	syncRepo, ok := uc.repo.(domain.StravaSyncRepository)
+	if !ok {
+		return
+	}
+	if err := syncRepo.RecordStravaWebhookSeen(ctx, userID, now); err != nil {
+		log.Printf("Failed to record Strava webhook for user %s: %v", userID, err)
+	}
+}
+
+// importActivity reports an activity found by polling or a backfill rather
+// than announced by a webhook. It claims the activity ID like create does,
+// so an activity is imported once however it arrives. Activities from
+// before yesterday are only imported when history is set, and then land on
+// their own day through ImportPastActivity. It reports whether the activity
+// was recorded.
+func (uc *ProcessStravaWebhookUsecase) importActivity(ctx context.Context, account *domain.StravaAccount, activity *strava.Activity, history bool, now time.Time) (bool, error) {
+	activities, ok := uc.activities()
+	if !ok {
+		return false, ErrStravaSyncUnavailable
+	}
+	activityDate := activity.LocalDate()
+	past := activityDate.Before(domain.GetToday(now).AddDate(0, 0, -1))
+	if past && (!history || activityDate.Before(SeasonStartDate(now))) {
+		return false, nil
+	}
+
+	status := domain.StravaActivityPending
+	if !uc.autoReports(ctx, account.UserID, activity.Type) {
+		status = domain.StravaActivitySkipped
+	}
+	claimed, err := activities.ClaimStravaActivity(ctx, stravaActivityRecord(account, activity, status, now))
+	if err != nil || !claimed || status == domain.StravaActivitySkipped {
+		return false, err
+	}
+
+	var eventKey string
+	if past {
+		name, err := uc.userName(ctx, account)
+		if err == nil {
+			eventKey, err = uc.reportUC.ImportPastActivity(ctx, account.UserID, name, stravaReportSource, strconv.FormatInt(activity.ID, 10), activity.Workout(), activityDate, activity.StartDate, now)
+		}
+		if err == nil {
+			status = domain.StravaActivityReported
+			if eventKey == "" {
+				status = domain.StravaActivityRejected
+			}
+			record := stravaActivityRecord(account, activity, status, now)
+			record.EventKey = eventKey
+			err = activities.SaveStravaActivity(ctx, record)
 		}
-		_, err = waClient.SendMessage(ctx, targetJID, msg)
 		if err != nil {
-			log.Printf("Failed to send Strava notification to group %s: %v", uc.groupID, err)
-		} else {
-			log.Printf("Sent Strava auto-report notification to group %s", uc.groupID)
+			_ = activities.DeleteStravaActivity(ctx, activity.ID)
+			return false, err
 		}
 	} else {
-		log.Printf("Skipping group notification: groupID=%s, waClient connected=%v", uc.groupID, waClient != nil && waClient.IsConnected())
+		eventKey, err = uc.report(ctx, account, activity, now)
+		if err != nil {
+			_ = activities.DeleteStravaActivity(ctx, activity.ID)
+			return false, err
+		}
 	}
+	return eventKey != "", nil
+}
 
-	return nil
+func (uc *ProcessStravaWebhookUsecase) userName(ctx context.Context, account *domain.StravaAccount) (string, error) {
+	report, err := uc.repo.GetReport(ctx, account.UserID)
+	if err != nil {
+		return "", err
+	}
+	if report != nil {
+		return report.Name, nil
+	}
+	if account.Name != "" {
+		return account.Name, nil
+	}
+	return "User", nil
+}
+
+func (uc *ProcessStravaWebhookUsecase) sendToGroup(ctx context.Context, text string) {
+	if uc.groupID == "" {
+		log.Printf("Skipping Strava group notification: no group configured")
+		return
+	}
+	targetJID, err := types.ParseJID(uc.groupID)
+	if err != nil {
+		log.Printf("Invalid group JID %s: %v", uc.groupID, err)
+		return
+	}
+	uc.send(ctx, targetJID, text)
+}
+
+func (uc *ProcessStravaWebhookUsecase) send(ctx context.Context, target types.JID, text string) {
+	if uc.sender == nil {
+		log.Printf("Skipping Strava notification to %s: no sender configured", target.String())
+		return
+	}
+	msg := &waE2E.Message{
+		Conversation: &text,
+	}
+	if err := uc.sender.SendNormalPriority(ctx, target, msg); err != nil {
+		log.Printf("Failed to queue Strava notification to %s: %v", target.String(), err)
+	}
 }
@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/strava"
+	"github.com/fardannozami/whatsapp-gateway/internal/queue"
+	"go.mau.fi/whatsmeow/types"
+)
+
+type stravaWebhookRepoStub struct {
+	*cancelReportRepoStub
+	accounts   map[int64]*domain.StravaAccount
+	activities map[int64]domain.StravaActivity
+	unlinked   []string
+	accountErr error
+	cancelled  []string
+}
+
+func newStravaWebhookRepoStub(base *cancelReportRepoStub) *stravaWebhookRepoStub {
+	return &stravaWebhookRepoStub{
+		cancelReportRepoStub: base,
+		accounts:             map[int64]*domain.StravaAccount{},
+		activities:           map[int64]domain.StravaActivity{},
+	}
+}
+
+func (r *stravaWebhookRepoStub) GetStravaAccountByAthleteID(ctx context.Context, athleteID int64) (*domain.StravaAccount, error) {
+	if r.accountErr != nil {
+		return nil, r.accountErr
+	}
+	return r.accounts[athleteID], nil
+}
+
+func (r *stravaWebhookRepoStub) ClaimStravaActivity(ctx context.Context, activity domain.StravaActivity) (bool, error) {
+	if prev, ok := r.activities[activity.ActivityID]; ok {
+		stale := prev.Status == domain.StravaActivityPending && activity.CreatedAt.Sub(prev.UpdatedAt) > domain.StravaClaimTimeout
+		if !stale {
+			return false, nil
+		}
+	}
+	r.activities[activity.ActivityID] = activity
+	return true, nil
+}
+
+func (r *stravaWebhookRepoStub) GetStravaActivity(ctx context.Context, activityID int64) (*domain.StravaActivity, error) {
+	a, ok := r.activities[activityID]
+	if !ok {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+func (r *stravaWebhookRepoStub) SaveStravaActivity(ctx context.Context, activity domain.StravaActivity) error {
+	r.activities[activity.ActivityID] = activity
+	return nil
+}
+
+func (r *stravaWebhookRepoStub) DeleteStravaActivity(ctx context.Context, activityID int64) error {
+	delete(r.activities, activityID)
+	return nil
+}
+
+func (r *stravaWebhookRepoStub) DeleteStravaAccount(ctx context.Context, userID string) error {
+	r.unlinked = append(r.unlinked, userID)
+	for id, acc := range r.accounts {
+		if acc.UserID == userID {
+			delete(r.accounts, id)
+		}
+	}
+	return nil
+}
+
+// GetReportEvent finds the events of tracked activities as regular
+// reports of the user; only whether they were cancelled is tracked.
+func (r *stravaWebhookRepoStub) GetReportEvent(ctx context.Context, userID, eventID string) (*domain.ReportActivityEvent, error) {
+	event := &domain.ReportActivityEvent{EventID: eventID, UserID: userID, Kind: domain.ActivityKindRegularReport}
+	if slices.Contains(r.cancelled, eventID) {
+		event.CancelledAt = time.Now()
+		return event, nil
+	}
+	for _, activity := range r.activities {
+		if activity.EventKey == eventID {
+			return event, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *stravaWebhookRepoStub) CancelReportEvent(ctx context.Context, userID, eventID string, at time.Time) (bool, error) {
+	if slices.Contains(r.cancelled, eventID) {
+		return false, nil
+	}
+	r.cancelled = append(r.cancelled, eventID)
+	return true, nil
+}
+
+func (r *stravaWebhookRepoStub) CancelLatestReportEvents(ctx context.Context, userID, kind string, activityDate time.Time, limit int, at time.Time) ([]string, error) {
+	return nil, nil
+}
+
+func TestProcessStravaWebhook_DeleteCancelsReport(t *testing.T) {
+	activityDate := domain.GetToday(time.Now()).AddDate(0, 0, -2)
+	earlier := activityDate.AddDate(0, 0, -7)
+	repo := newStravaWebhookRepoStub(&cancelReportRepoStub{
+		report:           &domain.Report{UserID: "628111", Name: "Budi", LastReportDate: activityDate},
+		dates:            []time.Time{earlier, activityDate},
+		dailyCountByKind: map[string]int{domain.ActivityKindRegularReport: 1},
+	})
+	repo.activities[42] = domain.StravaActivity{
+		ActivityID:   42,
+		UserID:       "628111",
+		AthleteID:    7,
+		EventKey:     "evt-1",
+		ActivityType: "Run",
+		ActivityDate: activityDate,
+		Status:       domain.StravaActivityReported,
+	}
+	uc := NewProcessStravaWebhookUsecase(repo, nil, nil, "")
+
+	err := uc.Execute(context.Background(), StravaWebhookEvent{ObjectType: "activity", AspectType: "delete", ObjectID: 42, AthleteID: 7})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !repo.deletedLog || !repo.deletedLogDate.Equal(activityDate) {
+		t.Fatalf("expected the activity's day %s to be cancelled, got deleted=%v date=%s", activityDate, repo.deletedLog, repo.deletedLogDate)
+	}
+	if got := repo.activities[42].Status; got != domain.StravaActivityCancelled {
+		t.Fatalf("expected activity status cancelled, got %q", got)
+	}
+	if len(repo.cancelled) != 1 || repo.cancelled[0] != "evt-1" {
+		t.Fatalf("expected the activity's own event evt-1 to be cancelled, got %v", repo.cancelled)
+	}
+
+	// A retried delete finds nothing left to cancel.
+	repo.deletedLog = false
+	if err := uc.Execute(context.Background(), StravaWebhookEvent{ObjectType: "activity", AspectType: "delete", ObjectID: 42, AthleteID: 7}); err != nil {
+		t.Fatalf("Execute retry: %v", err)
+	}
+	if repo.deletedLog {
+		t.Fatal("retried delete must not cancel another report")
+	}
+}
+
+func TestProcessStravaWebhook_DeleteUnknownActivityIsIgnored(t *testing.T) {
+	repo := newStravaWebhookRepoStub(&cancelReportRepoStub{
+		report: &domain.Report{UserID: "628111", Name: "Budi"},
+	})
+	uc := NewProcessStravaWebhookUsecase(repo, nil, nil, "")
+
+	if err := uc.Execute(context.Background(), StravaWebhookEvent{ObjectType: "activity", AspectType: "delete", ObjectID: 99, AthleteID: 7}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if repo.deletedLog || repo.latestDeleted {
+		t.Fatal("deleting an unreported activity must not cancel anything")
+	}
+}
+
+func TestProcessStravaWebhook_DuplicateCreateIsDropped(t *testing.T) {
+	repo := newStravaWebhookRepoStub(&cancelReportRepoStub{})
+	repo.accounts[7] = &domain.StravaAccount{UserID: "628111", AthleteID: 7}
+	repo.activities[42] = domain.StravaActivity{ActivityID: 42, UserID: "628111", Status: domain.StravaActivityReported}
+	// A nil Strava client would panic if the duplicate reached the fetch.
+	uc := NewProcessStravaWebhookUsecase(repo, nil, nil, "")
+
+	if err := uc.Execute(context.Background(), StravaWebhookEvent{ObjectType: "activity", AspectType: "create", ObjectID: 42, AthleteID: 7}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if got := repo.activities[42].Status; got != domain.StravaActivityReported {
+		t.Fatalf("duplicate create must leave the activity alone, got %q", got)
+	}
+}
+
+func TestProcessStravaWebhook_DeauthorizeUnlinks(t *testing.T) {
+	repo := newStravaWebhookRepoStub(&cancelReportRepoStub{})
+	repo.accounts[7] = &domain.StravaAccount{UserID: "628111", AthleteID: 7}
+	uc := NewProcessStravaWebhookUsecase(repo, nil, nil, "")
+	client := &fakeMessageClient{}
+	sender := queue.NewTestSender(client, context.Background())
+	sender.Start()
+	defer sender.Shutdown(100 * time.Millisecond)
+	uc.SetSender(sender)
+
+	err := uc.Execute(context.Background(), StravaWebhookEvent{
+		ObjectType: "athlete",
+		AspectType: "update",
+		ObjectID:   7,
+		AthleteID:  7,
+		Updates:    map[string]string{"authorized": "false"},
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(repo.unlinked) != 1 || repo.unlinked[0] != "628111" {
+		t.Fatalf("expected 628111 to be unlinked, got %v", repo.unlinked)
+	}
+	if _, ok := repo.accounts[7]; ok {
+		t.Fatal("account should be removed")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if want := types.NewJID("628111", types.DefaultUserServer); client.sentJID != want {
+		t.Fatalf("expected a DM to %s, got %s", want, client.sentJID)
+	}
+}
+
+func TestProcessStravaWebhook_DeleteTakesBackRaidHitAndRecord(t *testing.T) {
+	now := time.Now()
+	today := domain.GetToday(now)
+	srv := newStravaStubServer(t, []strava.Activity{stravaRun(1, today, "Run")})
+	account := domain.StravaAccount{UserID: "628111", AthleteID: 7, AccessToken: "token-1", ExpiresAt: now.Add(time.Hour)}
+	repo := &stravaSyncRepoStub{
+		stravaWebhookRepoStub: newStravaWebhookRepoStub(&cancelReportRepoStub{
+			report: &domain.Report{UserID: "628111", Name: "Budi"},
+			dates:  []time.Time{today},
+		}),
+		perDay: map[string]int{},
+		quiet:  []domain.StravaAccount{account},
+	}
+	repo.accounts[7] = &account
+
+	// The raid and the record book are keyed by the report's event, the
+	// way the raid and personal record usecases keep them.
+	hits, records := map[string]int{}, map[string]bool{}
+	reportUC := NewReportActivityUsecase(repo)
+	reportUC.SetRaidRecorder(func(ctx context.Context, userID string, points int, attr domain.AttributeType, eventKey string, now time.Time) string {
+		hits[eventKey] = points
+		return ""
+	})
+	reportUC.SetRecordTracker(func(ctx context.Context, event PersonalRecordEvent) string {
+		records[event.EventKey] = true
+		return ""
+	})
+	cancelUC := NewCancelReportUsecase(repo)
+	cancelUC.SetRaidReverter(func(ctx context.Context, userID, eventID string) { delete(hits, eventID) })
+	cancelUC.SetRecordRetractor(func(ctx context.Context, userID, eventID string) { delete(records, eventID) })
+
+	client := strava.NewClient(config.Config{StravaBaseURL: srv.URL})
+	processor := NewProcessStravaWebhookUsecase(repo, client, reportUC, "")
+	processor.SetCancelUsecase(cancelUC)
+	if _, err := NewSyncStravaUsecase(repo, processor).PollQuiet(context.Background(), now); err != nil {
+		t.Fatalf("PollQuiet: %v", err)
+	}
+	if len(hits) != 1 || len(records) != 1 {
+		t.Fatalf("the run should hit the raid and set a record, got hits=%v records=%v", hits, records)
+	}
+
+	if err := processor.Execute(context.Background(), StravaWebhookEvent{ObjectType: "activity", AspectType: "delete", ObjectID: 1, AthleteID: 7}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(hits) != 0 || len(records) != 0 {
+		t.Fatalf("deleting the run should take back its raid hit and record, got hits=%v records=%v", hits, records)
+	}
+}
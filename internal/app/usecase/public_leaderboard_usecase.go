@@ -0,0 +1,242 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const (
+	DefaultPublicLeaderboardLimit = 50
+	MaxPublicLeaderboardLimit     = 100
+	// publicLeaderboardCacheTTL bounds how stale a cached page gets from
+	// changes that raise no bot event, e.g. a renamed user or a new job.
+	publicLeaderboardCacheTTL = time.Minute
+	// publicLeaderboardCacheSize caps cached queries; searches can make
+	// many distinct ones.
+	publicLeaderboardCacheSize = 256
+)
+
+var (
+	ErrPublicLeaderboardUnavailable = errors.New("leaderboard queries are not supported by this repository")
+	ErrPublicLeaderboardInvalid     = errors.New("invalid leaderboard query")
+)
+
+// PublicLeaderboardQuery selects a page of the web leaderboard. Cursor is
+// the NextCursor of the previous page; empty starts from the top.
+type PublicLeaderboardQuery struct {
+	Sort   domain.LeaderboardSortKey // empty for season_rank
+	Job    string                    // empty or "all" for every job
+	Season int                       // zero for the running season
+	Search string
+	Cursor string
+	Limit  int
+}
+
+// PublicLeaderboardPage is a ranked page of the web leaderboard. The
+// standings are shared with the cache and must not be modified.
+type PublicLeaderboardPage struct {
+	Standings []domain.LeaderboardStanding
+	// Offset is how many users rank above the first standing.
+	Offset     int
+	Total      int
+	NextCursor string
+	Sort       domain.LeaderboardSortKey
+	Season     int
+	Today      time.Time
+	WeekStart  time.Time
+}
+
+type publicLeaderboardCacheEntry struct {
+	standings []domain.LeaderboardStanding
+	total     int
+	expires   time.Time
+}
+
+// PublicLeaderboardUsecase ranks the web leaderboard in the repository and
+// caches the pages in process. Invalidate drops the cache when a report
+// changes the standings.
+type PublicLeaderboardUsecase struct {
+	repo domain.ReportRepository
+
+	mu         sync.Mutex
+	cache      map[string]publicLeaderboardCacheEntry
+	generation uint64
+}
+
+func NewPublicLeaderboardUsecase(repo domain.ReportRepository) *PublicLeaderboardUsecase {
+	return &PublicLeaderboardUsecase{repo: repo, cache: make(map[string]publicLeaderboardCacheEntry)}
+}
+
+// List returns a page of the leaderboard ranked by query.Sort, leaving out
+// users with nothing to show on that tab.
+func (u *PublicLeaderboardUsecase) List(ctx context.Context, query PublicLeaderboardQuery, now time.Time) (PublicLeaderboardPage, error) {
+	sortKey := query.Sort
+	if sortKey == "" {
+		sortKey = domain.SortBySeasonRank
+	}
+	if !domain.IsLeaderboardSortKey(sortKey) {
+		return PublicLeaderboardPage{}, fmt.Errorf("%w: unknown sort %q", ErrPublicLeaderboardInvalid, sortKey)
+	}
+	currentSeason, _ := GetCurrentSessionInfo(now)
+	season := query.Season
+	if season == 0 {
+		season = currentSeason
+	}
+	if season < 0 || season > currentSeason {
+		return PublicLeaderboardPage{}, fmt.Errorf("%w: season %d has not started", ErrPublicLeaderboardInvalid, query.Season)
+	}
+	offset := 0
+	if query.Cursor != "" {
+		var err error
+		if offset, err = decodeLeaderboardCursor(query.Cursor); err != nil {
+			return PublicLeaderboardPage{}, err
+		}
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultPublicLeaderboardLimit
+	}
+	limit = min(limit, MaxPublicLeaderboardLimit)
+	job := strings.ToLower(strings.TrimSpace(query.Job))
+	if job == "all" {
+		job = ""
+	}
+
+	boardQuery := u.boardQuery(sortKey, now)
+	if season != currentSeason {
+		boardQuery.Season = season
+		boardQuery.CurrentSeason = 0
+	}
+	boardQuery.JobClass = job
+	boardQuery.Search = strings.TrimSpace(query.Search)
+	boardQuery.ActiveOnly = true
+	boardQuery.Offset = offset
+	boardQuery.Limit = limit
+
+	standings, total, err := u.load(ctx, boardQuery, now)
+	if err != nil {
+		return PublicLeaderboardPage{}, err
+	}
+	page := u.page(boardQuery, standings, total, season)
+	if offset+len(standings) < total {
+		page.NextCursor = encodeLeaderboardCursor(offset + len(standings))
+	}
+	return page, nil
+}
+
+// All returns every user in season order, active or not, for clients that
+// rank and filter the leaderboard themselves.
+func (u *PublicLeaderboardUsecase) All(ctx context.Context, now time.Time) (PublicLeaderboardPage, error) {
+	boardQuery := u.boardQuery(domain.SortBySeasonRank, now)
+	standings, total, err := u.load(ctx, boardQuery, now)
+	if err != nil {
+		return PublicLeaderboardPage{}, err
+	}
+	season, _ := GetCurrentSessionInfo(now)
+	return u.page(boardQuery, standings, total, season), nil
+}
+
+// Invalidate drops every cached page when event changes the standings. It
+// has the BotEventPublisher signature.
+func (u *PublicLeaderboardUsecase) Invalidate(ctx context.Context, event domain.BotEvent) {
+	switch event.Type {
+	case domain.BotEventReportAccepted, domain.BotEventReportCancelled, domain.BotEventSeasonReset,
+		domain.BotEventRaidDefeated, domain.BotEventRaidRewarded:
+	default:
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	clear(u.cache)
+	u.generation++
+}
+
+func (u *PublicLeaderboardUsecase) boardQuery(sortKey domain.LeaderboardSortKey, now time.Time) domain.LeaderboardQuery {
+	// Attribution-aware, like the activity_date keys the reports are
+	// logged under.
+	query := domain.LeaderboardQuery{
+		SortKey:   sortKey,
+		Today:     domain.GetToday(now),
+		WeekStart: domain.GetStartOfISOWeek(now),
+	}
+	// The projections only hold a season the ledger saw from its start.
+	if season, start := GetCurrentSessionInfo(now); ReportEventLedgerEnabled(start) {
+		query.CurrentSeason = season
+	}
+	return query
+}
+
+func (u *PublicLeaderboardUsecase) page(query domain.LeaderboardQuery, standings []domain.LeaderboardStanding, total, season int) PublicLeaderboardPage {
+	return PublicLeaderboardPage{
+		Standings: standings,
+		Offset:    query.Offset,
+		Total:     total,
+		Sort:      query.SortKey,
+		Season:    season,
+		Today:     query.Today,
+		WeekStart: query.WeekStart,
+	}
+}
+
+// load serves query from the cache, or ranks it and caches the result
+// unless an invalidation raced with the query.
+func (u *PublicLeaderboardUsecase) load(ctx context.Context, query domain.LeaderboardQuery, now time.Time) ([]domain.LeaderboardStanding, int, error) {
+	board, ok := u.repo.(domain.LeaderboardRepository)
+	if !ok {
+		return nil, 0, ErrPublicLeaderboardUnavailable
+	}
+	key := fmt.Sprintf("%s|%d|%d|%s|%s|%t|%s|%s|%d|%d",
+		query.SortKey, query.Season, query.CurrentSeason, query.JobClass, query.Search, query.ActiveOnly,
+		query.Today.Format(time.DateOnly), query.WeekStart.Format(time.DateOnly), query.Offset, query.Limit)
+
+	u.mu.Lock()
+	entry, hit := u.cache[key]
+	generation := u.generation
+	u.mu.Unlock()
+	if hit && now.Before(entry.expires) {
+		return entry.standings, entry.total, nil
+	}
+
+	standings, total, err := board.ListLeaderboard(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.generation == generation {
+		if len(u.cache) >= publicLeaderboardCacheSize {
+			clear(u.cache)
+		}
+		u.cache[key] = publicLeaderboardCacheEntry{standings: standings, total: total, expires: now.Add(publicLeaderboardCacheTTL)}
+	}
+	return standings, total, nil
+}
+
+func encodeLeaderboardCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o" + strconv.Itoa(offset)))
+}
+
+func decodeLeaderboardCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrPublicLeaderboardInvalid)
+	}
+	value, ok := strings.CutPrefix(string(raw), "o")
+	if !ok {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrPublicLeaderboardInvalid)
+	}
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrPublicLeaderboardInvalid)
+	}
+	return offset, nil
+}
@@ -0,0 +1,102 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// boardRepo ranks a fixed list and counts how often it is asked.
+type boardRepo struct {
+	*mockReportRepo
+	standings []domain.LeaderboardStanding
+	queries   []domain.LeaderboardQuery
+}
+
+func (r *boardRepo) ListLeaderboard(ctx context.Context, query domain.LeaderboardQuery) ([]domain.LeaderboardStanding, int, error) {
+	r.queries = append(r.queries, query)
+	if query.Offset >= len(r.standings) {
+		return nil, 0, nil
+	}
+	end := len(r.standings)
+	if query.Limit > 0 {
+		end = min(end, query.Offset+query.Limit)
+	}
+	return r.standings[query.Offset:end], len(r.standings), nil
+}
+
+func TestPublicLeaderboard_PagesCachesAndInvalidates(t *testing.T) {
+	repo := &boardRepo{mockReportRepo: &mockReportRepo{reports: make(map[string]*domain.Report)}}
+	for _, name := range []string{"Alice", "Bob", "Carol"} {
+		repo.standings = append(repo.standings, domain.LeaderboardStanding{Report: &domain.Report{UserID: name, Name: name}})
+	}
+	uc := usecase.NewPublicLeaderboardUsecase(repo)
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 19, 7, 0, 0, 0, time.UTC)
+
+	first, err := uc.List(ctx, usecase.PublicLeaderboardQuery{Sort: domain.SortByLifetimeXP, Job: "All", Limit: 2}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.Standings) != 2 || first.Total != 3 || first.NextCursor == "" || first.Sort != domain.SortByLifetimeXP {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	if q := repo.queries[0]; !q.ActiveOnly || q.JobClass != "" || q.Season != 0 || q.CurrentSeason != 2 || !q.Today.Equal(domain.GetToday(now)) {
+		t.Fatalf("unexpected repository query: %+v", q)
+	}
+
+	second, err := uc.List(ctx, usecase.PublicLeaderboardQuery{Sort: domain.SortByLifetimeXP, Job: "all", Limit: 2, Cursor: first.NextCursor}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.Standings) != 1 || second.Offset != 2 || second.Standings[0].Report.Name != "Carol" || second.NextCursor != "" {
+		t.Fatalf("unexpected last page: %+v", second)
+	}
+
+	if _, err := uc.List(ctx, usecase.PublicLeaderboardQuery{Sort: domain.SortByLifetimeXP, Limit: 2}, now.Add(time.Second)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.queries) != 2 {
+		t.Fatalf("a repeated query should be served from the cache, repository asked %d times", len(repo.queries))
+	}
+	uc.Invalidate(ctx, domain.BotEvent{Type: domain.BotEventLevelUp})
+	uc.List(ctx, usecase.PublicLeaderboardQuery{Sort: domain.SortByLifetimeXP, Limit: 2}, now)
+	if len(repo.queries) != 2 {
+		t.Fatal("events that don't move the standings should keep the cache")
+	}
+	uc.Invalidate(ctx, domain.BotEvent{Type: domain.BotEventReportAccepted})
+	uc.List(ctx, usecase.PublicLeaderboardQuery{Sort: domain.SortByLifetimeXP, Limit: 2}, now)
+	if len(repo.queries) != 3 {
+		t.Fatal("an accepted report should drop the cache")
+	}
+	uc.Invalidate(ctx, domain.BotEvent{Type: domain.BotEventRaidRewarded})
+	uc.List(ctx, usecase.PublicLeaderboardQuery{Sort: domain.SortByLifetimeXP, Limit: 2}, now)
+	if len(repo.queries) != 4 {
+		t.Fatal("a raid reward should drop the cache")
+	}
+	uc.List(ctx, usecase.PublicLeaderboardQuery{Sort: domain.SortByLifetimeXP, Limit: 2}, now.Add(2*time.Minute))
+	if len(repo.queries) != 5 {
+		t.Fatal("cached pages should expire")
+	}
+
+	for name, query := range map[string]usecase.PublicLeaderboardQuery{
+		"unknown sort":     {Sort: "fastest"},
+		"future season":    {Season: 99},
+		"malformed cursor": {Cursor: "not a cursor"},
+	} {
+		if _, err := uc.List(ctx, query, now); !errors.Is(err, usecase.ErrPublicLeaderboardInvalid) {
+			t.Fatalf("%s should be invalid, got %v", name, err)
+		}
+	}
+}
+
+func TestPublicLeaderboard_Unavailable(t *testing.T) {
+	uc := usecase.NewPublicLeaderboardUsecase(&mockReportRepo{reports: make(map[string]*domain.Report)})
+	if _, err := uc.All(context.Background(), time.Now()); !errors.Is(err, usecase.ErrPublicLeaderboardUnavailable) {
+		t.Fatalf("expected ErrPublicLeaderboardUnavailable, got %v", err)
+	}
+}
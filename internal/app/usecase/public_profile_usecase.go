@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// publicProfileBadges is how many badges a profile showcases.
+const publicProfileBadges = 4
+
+var (
+	ErrPublicProfileUnavailable = errors.New("public profiles are not supported by this repository")
+	// ErrPublicProfileNotFound covers unknown keys as well as profiles their
+	// owner has not made public, so callers can't probe who has one.
+	ErrPublicProfileNotFound      = errors.New("public profile not found")
+	ErrPublicProfileInvalidHandle = errors.New("handle must be 3-20 lowercase letters, digits or underscores")
+	ErrPublicProfileHandleTaken   = domain.ErrPublicProfileHandleTaken
+)
+
+// Handles have no dashes so they never look like a masked public ID.
+var publicProfileHandlePattern = regexp.MustCompile(`^[a-z0-9_]{3,20}$`)
+
+// PublicProfileUpdate changes a user's profile settings. Nil fields are
+// left as they are; an empty Handle releases the handle.
+type PublicProfileUpdate struct {
+	Enabled *bool   `json:"enabled"`
+	Handle  *string `json:"handle"`
+}
+
+// PublicProfileView is everything a public profile page or hunter card
+// shows about its owner.
+type PublicProfileView struct {
+	Profile       domain.PublicProfile
+	Report        *domain.Report
+	ActivityDates []time.Time
+	TopBadges     []domain.BadgeSummary
+}
+
+// PublicProfileUsecase lets users opt in to a shareable profile page and
+// serves it to anyone by public ID or handle.
+type PublicProfileUsecase struct {
+	repo domain.ReportRepository
+}
+
+func NewPublicProfileUsecase(repo domain.ReportRepository) *PublicProfileUsecase {
+	return &PublicProfileUsecase{repo: repo}
+}
+
+func (u *PublicProfileUsecase) profiles() (domain.PublicProfileRepository, error) {
+	profiles, ok := u.repo.(domain.PublicProfileRepository)
+	if !ok {
+		return nil, ErrPublicProfileUnavailable
+	}
+	return profiles, nil
+}
+
+// Settings returns the user's profile settings; users who never opted in
+// get a disabled profile.
+func (u *PublicProfileUsecase) Settings(ctx context.Context, userID string) (domain.PublicProfile, error) {
+	profiles, err := u.profiles()
+	if err != nil {
+		return domain.PublicProfile{}, err
+	}
+	profile, err := profiles.GetPublicProfile(ctx, userID)
+	if err != nil {
+		return domain.PublicProfile{}, err
+	}
+	if profile == nil {
+		return domain.PublicProfile{UserID: userID, PublicID: domain.PublicUserID(userID)}, nil
+	}
+	return *profile, nil
+}
+
+// Update applies the user's changes. Only users who have reported can
+// have a profile.
+func (u *PublicProfileUsecase) Update(ctx context.Context, userID string, update PublicProfileUpdate, now time.Time) (domain.PublicProfile, error) {
+	profiles, err := u.profiles()
+	if err != nil {
+		return domain.PublicProfile{}, err
+	}
+	report, err := u.repo.GetReport(ctx, userID)
+	if err != nil {
+		return domain.PublicProfile{}, err
+	}
+	if report == nil {
+		return domain.PublicProfile{}, ErrPublicProfileNotFound
+	}
+	profile, err := u.Settings(ctx, userID)
+	if err != nil {
+		return domain.PublicProfile{}, err
+	}
+
+	if update.Handle != nil {
+		handle := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(*update.Handle, "@")))
+		if handle != "" && handle != profile.Handle {
+			if !publicProfileHandlePattern.MatchString(handle) {
+				return domain.PublicProfile{}, ErrPublicProfileInvalidHandle
+			}
+			existing, err := profiles.FindPublicProfile(ctx, handle)
+			if err != nil {
+				return domain.PublicProfile{}, err
+			}
+			if existing != nil && existing.UserID != userID {
+				return domain.PublicProfile{}, ErrPublicProfileHandleTaken
+			}
+		}
+		profile.Handle = handle
+	}
+	if update.Enabled != nil {
+		profile.Enabled = *update.Enabled
+	}
+	profile.PublicID = domain.PublicUserID(userID)
+	profile.UpdatedAt = now
+	if err := profiles.SavePublicProfile(ctx, profile); err != nil {
+		return domain.PublicProfile{}, err
+	}
+	return profile, nil
+}
+
+// Get returns the public profile behind a public ID or handle.
+func (u *PublicProfileUsecase) Get(ctx context.Context, key string) (PublicProfileView, error) {
+	profiles, err := u.profiles()
+	if err != nil {
+		return PublicProfileView{}, err
+	}
+	key = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(key, "@")))
+	if key == "" {
+		return PublicProfileView{}, ErrPublicProfileNotFound
+	}
+	profile, err := profiles.FindPublicProfile(ctx, key)
+	if err != nil {
+		return PublicProfileView{}, err
+	}
+	if profile == nil || !profile.Enabled {
+		return PublicProfileView{}, ErrPublicProfileNotFound
+	}
+	report, err := u.repo.GetReport(ctx, profile.UserID)
+	if err != nil {
+		return PublicProfileView{}, err
+	}
+	if report == nil {
+		return PublicProfileView{}, ErrPublicProfileNotFound
+	}
+	dates, err := u.repo.GetUserActivityDates(ctx, profile.UserID)
+	if err != nil {
+		return PublicProfileView{}, err
+	}
+	return PublicProfileView{
+		Profile:       *profile,
+		Report:        report,
+		ActivityDates: dates,
+		TopBadges:     domain.TopAchievementSummaries(report.Achievements, publicProfileBadges),
+	}, nil
+}
@@ -0,0 +1,131 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// profileRepo keeps public profiles in memory.
+type profileRepo struct {
+	*mockReportRepo
+	profiles map[string]domain.PublicProfile
+}
+
+func (r *profileRepo) GetPublicProfile(ctx context.Context, userID string) (*domain.PublicProfile, error) {
+	profile, ok := r.profiles[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &profile, nil
+}
+
+func (r *profileRepo) FindPublicProfile(ctx context.Context, key string) (*domain.PublicProfile, error) {
+	for _, profile := range r.profiles {
+		if profile.PublicID == key || (profile.Handle != "" && profile.Handle == key) {
+			return &profile, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *profileRepo) SavePublicProfile(ctx context.Context, profile domain.PublicProfile) error {
+	r.profiles[profile.UserID] = profile
+	return nil
+}
+
+func TestPublicProfile_OptInHandlesAndLookup(t *testing.T) {
+	repo := &profileRepo{
+		mockReportRepo: &mockReportRepo{reports: map[string]*domain.Report{
+			"628111": {UserID: "628111", Name: "Alice", Achievements: "first_report,streak_2,streak_1"},
+			"628222": {UserID: "628222", Name: "Bob"},
+		}},
+		profiles: make(map[string]domain.PublicProfile),
+	}
+	uc := usecase.NewPublicProfileUsecase(repo)
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 19, 7, 0, 0, 0, time.UTC)
+	publicID := domain.PublicUserID("628111")
+
+	settings, err := uc.Settings(ctx, "628111")
+	if err != nil || settings.Enabled || settings.PublicID != publicID {
+		t.Fatalf("profiles should start private under the masked ID, got %+v, %v", settings, err)
+	}
+	if _, err := uc.Get(ctx, publicID); !errors.Is(err, usecase.ErrPublicProfileNotFound) {
+		t.Fatalf("a profile nobody opted in to must not be public, got %v", err)
+	}
+
+	enabled, handle := true, "@Alice_Runs"
+	profile, err := uc.Update(ctx, "628111", usecase.PublicProfileUpdate{Enabled: &enabled, Handle: &handle}, now)
+	if err != nil || !profile.Enabled || profile.Handle != "alice_runs" {
+		t.Fatalf("unexpected profile after opting in: %+v, %v", profile, err)
+	}
+	for _, key := range []string{"alice_runs", "@ALICE_RUNS", publicID} {
+		view, err := uc.Get(ctx, key)
+		if err != nil || view.Report.Name != "Alice" {
+			t.Fatalf("Get(%q) = %+v, %v", key, view, err)
+		}
+	}
+	view, _ := uc.Get(ctx, "alice_runs")
+	if len(view.TopBadges) != 3 || view.TopBadges[0].ID != "streak_2" || view.TopBadges[2].ID != "first_report" {
+		t.Fatalf("badges should be ordered by points, got %+v", view.TopBadges)
+	}
+
+	taken := "alice_runs"
+	if _, err := uc.Update(ctx, "628222", usecase.PublicProfileUpdate{Handle: &taken}, now); !errors.Is(err, usecase.ErrPublicProfileHandleTaken) {
+		t.Fatalf("expected ErrPublicProfileHandleTaken, got %v", err)
+	}
+	for _, bad := range []string{"ab", "hunter-1234", "with space"} {
+		if _, err := uc.Update(ctx, "628222", usecase.PublicProfileUpdate{Handle: &bad}, now); !errors.Is(err, usecase.ErrPublicProfileInvalidHandle) {
+			t.Fatalf("handle %q should be invalid, got %v", bad, err)
+		}
+	}
+	if _, err := uc.Update(ctx, "628999", usecase.PublicProfileUpdate{Enabled: &enabled}, now); !errors.Is(err, usecase.ErrPublicProfileNotFound) {
+		t.Fatalf("users who never reported can't have a profile, got %v", err)
+	}
+
+	disabled := false
+	if _, err := uc.Update(ctx, "628111", usecase.PublicProfileUpdate{Enabled: &disabled}, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := uc.Get(ctx, "alice_runs"); !errors.Is(err, usecase.ErrPublicProfileNotFound) {
+		t.Fatalf("a disabled profile should be hidden, got %v", err)
+	}
+}
+
+func TestPublicProfile_Unavailable(t *testing.T) {
+	uc := usecase.NewPublicProfileUsecase(&mockReportRepo{reports: make(map[string]*domain.Report)})
+	if _, err := uc.Get(context.Background(), "someone"); !errors.Is(err, usecase.ErrPublicProfileUnavailable) {
+		t.Fatalf("expected ErrPublicProfileUnavailable, got %v", err)
+	}
+}
+
+// racingProfileRepo loses the handle to another user between the lookup and
+// the save, the way the unique index reports it.
+type racingProfileRepo struct {
+	*profileRepo
+}
+
+func (r *racingProfileRepo) SavePublicProfile(ctx context.Context, profile domain.PublicProfile) error {
+	return domain.ErrPublicProfileHandleTaken
+}
+
+func TestPublicProfile_HandleTakenDuringSave(t *testing.T) {
+	repo := &racingProfileRepo{profileRepo: &profileRepo{
+		mockReportRepo: &mockReportRepo{reports: map[string]*domain.Report{
+			"628111": {UserID: "628111", Name: "Alice"},
+		}},
+		profiles: make(map[string]domain.PublicProfile),
+	}}
+	uc := usecase.NewPublicProfileUsecase(repo)
+
+	handle := "alice_runs"
+	now := time.Date(2026, time.October, 19, 7, 0, 0, 0, time.UTC)
+	if _, err := uc.Update(context.Background(), "628111", usecase.PublicProfileUpdate{Handle: &handle}, now); !errors.Is(err, usecase.ErrPublicProfileHandleTaken) {
+		t.Fatalf("expected ErrPublicProfileHandleTaken, got %v", err)
+	}
+}
@@ -0,0 +1,412 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// raidMVPLimit caps how many contributors are listed in raid announcements.
+const raidMVPLimit = 5
+
+// RaidDamageRecorder applies the points of an accepted report to the weekly
+// raid boss and returns a short line for the reporter's reply. An empty line
+// means no damage was dealt (raid disabled, boss already down, duplicate).
+type RaidDamageRecorder func(ctx context.Context, userID string, points int, attr domain.AttributeType, eventKey string, now time.Time) string
+
+// RaidDefeatNotifier receives the group announcement when a boss falls.
+type RaidDefeatNotifier func(ctx context.Context, message string)
+
+// RaidRewardGranter credits a defeated boss's reward to one contributor.
+// eventID is stable per boss and hunter, so granting it again is a no-op;
+// the returned report is nil when nothing was credited.
+type RaidRewardGranter func(ctx context.Context, userID, eventID string, points int, now time.Time) (*domain.Report, error)
+
+type RaidBossUsecase struct {
+	repo           domain.ReportRepository
+	defeatNotifier RaidDefeatNotifier
+	rewardGranter  RaidRewardGranter
+	eventPublisher BotEventPublisher
+}
+
+func NewRaidBossUsecase(repo domain.ReportRepository) *RaidBossUsecase {
+	return &RaidBossUsecase{repo: repo}
+}
+
+// SetDefeatNotifier sets a callback that fires once when a boss is defeated.
+func (u *RaidBossUsecase) SetDefeatNotifier(fn RaidDefeatNotifier) {
+	u.defeatNotifier = fn
+}
+
+// SetRewardGranter sets how contributors of a defeated boss are credited.
+// Without it a boss still falls, but nobody is rewarded.
+func (u *RaidBossUsecase) SetRewardGranter(fn RaidRewardGranter) {
+	u.rewardGranter = fn
+}
+
+// SetEventPublisher sets the hook that is told when a boss is defeated and
+// its contributors are rewarded.
+func (u *RaidBossUsecase) SetEventPublisher(fn BotEventPublisher) {
+	u.eventPublisher = fn
+}
+
+func (u *RaidBossUsecase) raids() (domain.RaidRepository, bool) {
+	raids, ok := u.repo.(domain.RaidRepository)
+	return raids, ok
+}
+
+// Spawn creates this week's boss and returns the group announcement. It
+// returns an empty string when the boss already exists.
+func (u *RaidBossUsecase) Spawn(ctx context.Context, now time.Time) (string, error) {
+	boss, created, err := u.ensureBoss(ctx, now)
+	if err != nil {
+		return "", err
+	}
+	if boss == nil || !created {
+		return "", nil
+	}
+	return formatRaidSpawn(boss), nil
+}
+
+func (u *RaidBossUsecase) ensureBoss(ctx context.Context, now time.Time) (*domain.RaidBoss, bool, error) {
+	raids, ok := u.raids()
+	if !ok {
+		return nil, false, nil
+	}
+
+	weekStart := domain.GetStartOfISOWeek(now)
+	boss, err := raids.GetRaidBoss(ctx, domain.RaidBossID(weekStart))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get raid boss: %w", err)
+	}
+	if boss != nil {
+		return boss, false, nil
+	}
+
+	// HP scales with the hunters who were active last week so the raid stays
+	// winnable for a small group and challenging for a big one.
+	active, err := u.repo.GetActivityCountsByDateRange(ctx, weekStart.AddDate(0, 0, -7), weekStart)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to count active hunters: %w", err)
+	}
+	seasonNumber, _ := GetCurrentSessionInfo(now)
+	boss = domain.NewRaidBoss(weekStart, seasonNumber, len(active), now)
+
+	created, err := raids.CreateRaidBoss(ctx, boss)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create raid boss: %w", err)
+	}
+	if !created {
+		boss, err = raids.GetRaidBoss(ctx, boss.ID)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get raid boss: %w", err)
+		}
+	}
+	return boss, created, nil
+}
+
+// RecordDamage implements RaidDamageRecorder. Raid failures never block a
+// report; they are logged and the reply simply omits the raid line.
+func (u *RaidBossUsecase) RecordDamage(ctx context.Context, userID string, points int, attr domain.AttributeType, eventKey string, now time.Time) string {
+	raids, ok := u.raids()
+	if !ok {
+		return ""
+	}
+
+	boss, _, err := u.ensureBoss(ctx, now)
+	if err != nil {
+		log.Printf("[RAID] %v", err)
+		return ""
+	}
+	if boss == nil || boss.Status != domain.RaidStatusActive {
+		return ""
+	}
+
+	damage, weakness := domain.ComputeRaidDamage(points, attr, boss.Weakness)
+	boss, applied, defeated, err := raids.ApplyRaidDamage(ctx, domain.RaidDamage{
+		BossID:    boss.ID,
+		UserID:    userID,
+		EventKey:  eventKey,
+		Attribute: attr,
+		Points:    points,
+		Damage:    damage,
+		Weakness:  weakness,
+		CreatedAt: now,
+	})
+	if err != nil {
+		log.Printf("[RAID] Failed to apply damage for %s: %v", userID, err)
+		return ""
+	}
+	if !applied || boss == nil {
+		return ""
+	}
+
+	line := fmt.Sprintf("⚔️ Raid: -%d HP ke %s %s", damage, boss.Icon, boss.Name)
+	if weakness {
+		line += fmt.Sprintf(" (CRITICAL! weakness %s)", strings.ToUpper(string(boss.Weakness)))
+	}
+	line += "\n" + domain.FormatRaidHPBar(boss)
+
+	if defeated {
+		line += "\n💥 *FINISHING BLOW!* Boss tumbang!"
+		if msg := u.defeat(ctx, boss, userID, now); msg != "" && u.defeatNotifier != nil {
+			u.defeatNotifier(ctx, msg)
+		}
+	}
+	return line
+}
+
+// RevertDamage implements CancelledEventHook: it takes back the hit of a
+// cancelled report while the boss is still up. A boss that already fell
+// keeps its damage, and its contributors their reward.
+func (u *RaidBossUsecase) RevertDamage(ctx context.Context, userID, eventID string) {
+	raids, ok := u.raids()
+	if !ok {
+		return
+	}
+	boss, reverted, err := raids.RevertRaidDamage(ctx, eventID)
+	if err != nil {
+		log.Printf("[RAID] Failed to revert damage of %s for %s: %v", eventID, userID, err)
+		return
+	}
+	if reverted {
+		log.Printf("[RAID] Reverted a cancelled hit by %s on %s: %d/%d HP", userID, boss.Name, boss.HP, boss.MaxHP)
+	}
+}
+
+// defeat settles the boss the finishing blow just brought down and returns
+// the group announcement. If settling fails, the boss is left unrewarded
+// for SettleUnrewarded to pick up.
+func (u *RaidBossUsecase) defeat(ctx context.Context, boss *domain.RaidBoss, finisherID string, now time.Time) string {
+	msg, err := u.settle(ctx, boss, finisherID, now)
+	if err != nil {
+		log.Printf("[RAID] Failed to settle %s, the weekly raid job will retry: %v", boss.ID, err)
+		return ""
+	}
+	return msg
+}
+
+// SettleUnrewarded hands out the rewards of defeated bosses whose
+// settlement failed at the finishing blow, and returns their group
+// announcements. Bosses that fail again stay for the next run.
+func (u *RaidBossUsecase) SettleUnrewarded(ctx context.Context, now time.Time) (string, error) {
+	raids, ok := u.raids()
+	if !ok {
+		return "", nil
+	}
+	bosses, err := raids.ListUnrewardedRaidBosses(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list unrewarded raid bosses: %w", err)
+	}
+
+	var messages []string
+	var errs []error
+	for _, boss := range bosses {
+		msg, err := u.settle(ctx, boss, "", now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to settle %s: %w", boss.ID, err))
+			continue
+		}
+		if msg != "" {
+			messages = append(messages, msg)
+		}
+	}
+	return strings.Join(messages, "\n\n"), errors.Join(errs...)
+}
+
+// settle rewards every contributor of a defeated boss, marks it rewarded
+// and returns the group announcement, which is empty when the boss was
+// already settled. finisherID is empty when the finisher isn't known.
+func (u *RaidBossUsecase) settle(ctx context.Context, boss *domain.RaidBoss, finisherID string, now time.Time) (string, error) {
+	raids, _ := u.raids()
+	contributions, err := raids.GetRaidContributions(ctx, boss.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get contributions: %w", err)
+	}
+
+	// Rewards are credited before the boss is marked rewarded, and a failed
+	// grant leaves it unmarked for a retry. Each grant is idempotent, so
+	// settling a boss again credits nobody twice.
+	for _, c := range contributions {
+		if err := u.reward(ctx, boss, c, now); err != nil {
+			return "", err
+		}
+	}
+	marked, err := raids.MarkRaidRewarded(ctx, boss.ID, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to mark rewarded: %w", err)
+	}
+	if !marked || len(contributions) == 0 {
+		return "", nil
+	}
+
+	finisherName := ""
+	for _, c := range contributions {
+		if c.UserID == finisherID {
+			finisherName = c.Name
+			break
+		}
+	}
+	if u.eventPublisher != nil {
+		u.eventPublisher(ctx, domain.BotEvent{
+			Type:       domain.BotEventRaidDefeated,
+			OccurredAt: now,
+			UserID:     finisherID,
+			Name:       finisherName,
+			Data: map[string]any{
+				"boss_id":      boss.ID,
+				"boss_name":    boss.Name,
+				"boss_icon":    boss.Icon,
+				"contributors": len(contributions),
+				"reward":       domain.RaidContributorReward,
+			},
+		})
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🏆 *RAID BOSS TUMBANG!* %s *%s* berhasil dikalahkan! 🏆\n\n", boss.Icon, boss.Name))
+	if finisherName != "" {
+		sb.WriteString(fmt.Sprintf("💥 Finishing blow: %s\n\n", finisherName))
+	}
+	sb.WriteString(formatRaidMVPs(contributions))
+	sb.WriteString(fmt.Sprintf("\n🎁 %d hunter kontributor mendapat +%d pts!", len(contributions), domain.RaidContributorReward))
+	return sb.String(), nil
+}
+
+func (u *RaidBossUsecase) reward(ctx context.Context, boss *domain.RaidBoss, c domain.RaidContribution, now time.Time) error {
+	if u.rewardGranter == nil {
+		return nil
+	}
+	report, err := u.rewardGranter(ctx, c.UserID, domain.RaidRewardEventID(boss.ID, c.UserID), domain.RaidContributorReward, now)
+	if err != nil {
+		return fmt.Errorf("failed to reward %s: %w", c.UserID, err)
+	}
+	if report == nil || u.eventPublisher == nil {
+		return nil
+	}
+	u.eventPublisher(ctx, domain.BotEvent{
+		Type:       domain.BotEventRaidRewarded,
+		OccurredAt: now,
+		UserID:     c.UserID,
+		Name:       report.Name,
+		Data: map[string]any{
+			"boss_id":         boss.ID,
+			"boss_name":       boss.Name,
+			"reward":          domain.RaidContributorReward,
+			"total_points":    report.TotalPoints,
+			"seasonal_points": report.SeasonalPoints,
+		},
+	})
+	return nil
+}
+
+// Expire closes bosses from previous weeks that were not defeated and
+// returns the group summary, or an empty string when nothing expired.
+func (u *RaidBossUsecase) Expire(ctx context.Context, now time.Time) (string, error) {
+	raids, ok := u.raids()
+	if !ok {
+		return "", nil
+	}
+
+	expired, err := raids.ExpireRaidBosses(ctx, domain.GetStartOfISOWeek(now))
+	if err != nil {
+		return "", fmt.Errorf("failed to expire raid bosses: %w", err)
+	}
+
+	var sb strings.Builder
+	for _, boss := range expired {
+		contributions, err := raids.GetRaidContributions(ctx, boss.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get raid contributions: %w", err)
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(fmt.Sprintf("🌫️ *RAID BERAKHIR* — %s *%s* berhasil kabur.\n", boss.Icon, boss.Name))
+		sb.WriteString(fmt.Sprintf("Sisa HP: %s\n", domain.FormatRaidHPBar(boss)))
+		if len(contributions) > 0 {
+			sb.WriteString("\n")
+			sb.WriteString(formatRaidMVPs(contributions))
+		}
+		sb.WriteString("\nMinggu ini kita balas! 💪")
+	}
+	return sb.String(), nil
+}
+
+// Status returns the /raid reply for the current week.
+func (u *RaidBossUsecase) Status(ctx context.Context, now time.Time) (string, error) {
+	raids, ok := u.raids()
+	if !ok {
+		return "Raid boss belum tersedia. 🙏", nil
+	}
+
+	boss, _, err := u.ensureBoss(ctx, now)
+	if err != nil {
+		return "", err
+	}
+	if boss == nil {
+		return "Raid boss belum tersedia. 🙏", nil
+	}
+	contributions, err := raids.GetRaidContributions(ctx, boss.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get raid contributions: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("⚔️ *RAID MINGGU INI: %s %s*\n", boss.Icon, boss.Name))
+	if archetype, ok := domain.FindRaidArchetype(boss.ArchetypeID); ok {
+		sb.WriteString(fmt.Sprintf("_%s_\n", archetype.Lore))
+	}
+	sb.WriteString(fmt.Sprintf("Weakness: %s (damage x%d.%d)\n", strings.ToUpper(string(boss.Weakness)), domain.RaidWeaknessMultiplierPct/100, domain.RaidWeaknessMultiplierPct%100/10))
+	sb.WriteString(domain.FormatRaidHPBar(boss) + "\n")
+	switch boss.Status {
+	case domain.RaidStatusDefeated:
+		sb.WriteString("✅ Boss sudah dikalahkan minggu ini!\n")
+	case domain.RaidStatusActive:
+		sb.WriteString("Setiap /lapor memberi damage sebesar poin yang didapat.\n")
+	}
+
+	if len(contributions) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(formatRaidMVPs(contributions))
+	} else {
+		sb.WriteString("\nBelum ada hunter yang menyerang. Jadilah yang pertama! 🔥")
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+func formatRaidSpawn(boss *domain.RaidBoss) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🚨 *RAID BOSS MUNCUL!* %s *%s* 🚨\n", boss.Icon, boss.Name))
+	if archetype, ok := domain.FindRaidArchetype(boss.ArchetypeID); ok {
+		sb.WriteString(fmt.Sprintf("_%s_\n", archetype.Lore))
+	}
+	sb.WriteString(fmt.Sprintf("\n❤️ %s\n", domain.FormatRaidHPBar(boss)))
+	sb.WriteString(fmt.Sprintf("🎯 Weakness: %s — latihan yang cocok memberi damage lebih besar!\n", strings.ToUpper(string(boss.Weakness))))
+	sb.WriteString(fmt.Sprintf("🎁 Kalahkan sebelum Senin depan: semua kontributor dapat +%d pts.\n\n", domain.RaidContributorReward))
+	sb.WriteString("Serang dengan /lapor, cek progres dengan /raid ⚔️")
+	return sb.String()
+}
+
+func formatRaidMVPs(contributions []domain.RaidContribution) string {
+	var sb strings.Builder
+	sb.WriteString("🎖️ *MVP Raid:*\n")
+	medals := []string{"🥇", "🥈", "🥉"}
+	for i, c := range contributions {
+		if i >= raidMVPLimit {
+			sb.WriteString(fmt.Sprintf("...dan %d hunter lainnya\n", len(contributions)-raidMVPLimit))
+			break
+		}
+		prefix := fmt.Sprintf("%d.", i+1)
+		if i < len(medals) {
+			prefix = medals[i]
+		}
+		sb.WriteString(fmt.Sprintf("%s %s — %d dmg (%dx serang)\n", prefix, c.Name, c.TotalDamage, c.Hits))
+	}
+	return sb.String()
+}
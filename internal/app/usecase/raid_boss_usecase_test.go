@@ -0,0 +1,329 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type mockRaidRepo struct {
+	domain.ReportRepository
+	activeHunters int
+	bosses        map[string]*domain.RaidBoss
+	hits          map[string][]domain.RaidDamage
+	rewarded      map[string]int
+}
+
+func newMockRaidRepo(activeHunters int) *mockRaidRepo {
+	return &mockRaidRepo{
+		activeHunters: activeHunters,
+		bosses:        map[string]*domain.RaidBoss{},
+		hits:          map[string][]domain.RaidDamage{},
+		rewarded:      map[string]int{},
+	}
+}
+
+func (m *mockRaidRepo) GetActivityCountsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]domain.ActivityLeaderboardEntry, error) {
+	entries := make([]domain.ActivityLeaderboardEntry, m.activeHunters)
+	return entries, nil
+}
+
+func (m *mockRaidRepo) CreateRaidBoss(ctx context.Context, boss *domain.RaidBoss) (bool, error) {
+	if _, ok := m.bosses[boss.ID]; ok {
+		return false, nil
+	}
+	copied := *boss
+	m.bosses[boss.ID] = &copied
+	return true, nil
+}
+
+func (m *mockRaidRepo) GetRaidBoss(ctx context.Context, bossID string) (*domain.RaidBoss, error) {
+	boss, ok := m.bosses[bossID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *boss
+	return &copied, nil
+}
+
+func (m *mockRaidRepo) ApplyRaidDamage(ctx context.Context, hit domain.RaidDamage) (*domain.RaidBoss, bool, bool, error) {
+	boss := m.bosses[hit.BossID]
+	if boss == nil || boss.Status != domain.RaidStatusActive {
+		return boss, false, false, nil
+	}
+	for _, prev := range m.hits[hit.BossID] {
+		if prev.EventKey == hit.EventKey {
+			return boss, false, false, nil
+		}
+	}
+	m.hits[hit.BossID] = append(m.hits[hit.BossID], hit)
+	boss.HP -= hit.Damage
+	defeated := false
+	if boss.HP <= 0 {
+		boss.HP = 0
+		boss.Status = domain.RaidStatusDefeated
+		defeated = true
+	}
+	copied := *boss
+	return &copied, true, defeated, nil
+}
+
+func (m *mockRaidRepo) GetRaidContributions(ctx context.Context, bossID string) ([]domain.RaidContribution, error) {
+	byUser := map[string]*domain.RaidContribution{}
+	for _, hit := range m.hits[bossID] {
+		c, ok := byUser[hit.UserID]
+		if !ok {
+			c = &domain.RaidContribution{UserID: hit.UserID, Name: hit.UserID}
+			byUser[hit.UserID] = c
+		}
+		c.TotalDamage += hit.Damage
+		c.Hits++
+	}
+	var result []domain.RaidContribution
+	for _, c := range byUser {
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].TotalDamage > result[j].TotalDamage })
+	return result, nil
+}
+
+func (m *mockRaidRepo) MarkRaidRewarded(ctx context.Context, bossID string, now time.Time) (bool, error) {
+	if _, ok := m.rewarded[bossID]; ok {
+		return false, nil
+	}
+	m.rewarded[bossID] = domain.RaidContributorReward
+	return true, nil
+}
+
+func (m *mockRaidRepo) ListUnrewardedRaidBosses(ctx context.Context) ([]*domain.RaidBoss, error) {
+	var bosses []*domain.RaidBoss
+	for id, boss := range m.bosses {
+		if _, ok := m.rewarded[id]; !ok && boss.Status == domain.RaidStatusDefeated {
+			copied := *boss
+			bosses = append(bosses, &copied)
+		}
+	}
+	return bosses, nil
+}
+
+func (m *mockRaidRepo) RevertRaidDamage(ctx context.Context, eventKey string) (*domain.RaidBoss, bool, error) {
+	for bossID, hits := range m.hits {
+		boss := m.bosses[bossID]
+		if boss == nil || boss.Status != domain.RaidStatusActive {
+			continue
+		}
+		for i, hit := range hits {
+			if hit.EventKey != eventKey {
+				continue
+			}
+			m.hits[bossID] = append(hits[:i:i], hits[i+1:]...)
+			boss.HP = min(boss.HP+hit.Damage, boss.MaxHP)
+			copied := *boss
+			return &copied, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (m *mockRaidRepo) ExpireRaidBosses(ctx context.Context, weekStart time.Time) ([]*domain.RaidBoss, error) {
+	var expired []*domain.RaidBoss
+	for _, boss := range m.bosses {
+		if boss.Status == domain.RaidStatusActive && boss.WeekStart.Before(weekStart) {
+			boss.Status = domain.RaidStatusExpired
+			copied := *boss
+			expired = append(expired, &copied)
+		}
+	}
+	return expired, nil
+}
+
+func TestRaidBossSpawn_ScalesHPAndIsIdempotent(t *testing.T) {
+	now := time.Date(2026, 6, 15, 1, 0, 0, 0, time.UTC) // Monday
+	repo := newMockRaidRepo(6)
+	uc := NewRaidBossUsecase(repo)
+
+	msg, err := uc.Spawn(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(msg, "RAID BOSS MUNCUL") {
+		t.Fatalf("expected spawn announcement, got:\n%s", msg)
+	}
+
+	boss := repo.bosses["raid-2026-06-15"]
+	if boss == nil {
+		t.Fatal("expected boss for week 2026-06-15")
+	}
+	if boss.MaxHP != 6*domain.RaidHPPerHunter {
+		t.Fatalf("MaxHP = %d, want %d", boss.MaxHP, 6*domain.RaidHPPerHunter)
+	}
+
+	again, err := uc.Spawn(context.Background(), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again != "" {
+		t.Fatalf("expected no second announcement, got:\n%s", again)
+	}
+}
+
+func TestRaidBossRecordDamage_DefeatNotifiesOnce(t *testing.T) {
+	now := time.Date(2026, 6, 16, 8, 0, 0, 0, time.UTC)
+	repo := newMockRaidRepo(0) // minimum HP
+	uc := NewRaidBossUsecase(repo)
+
+	var notifications []string
+	uc.SetDefeatNotifier(func(ctx context.Context, message string) {
+		notifications = append(notifications, message)
+	})
+
+	ctx := context.Background()
+	line := uc.RecordDamage(ctx, "alice", 60, "", "evt-1", now)
+	if !strings.Contains(line, "-60 HP") {
+		t.Fatalf("expected damage line, got %q", line)
+	}
+
+	if dup := uc.RecordDamage(ctx, "alice", 60, "", "evt-1", now); dup != "" {
+		t.Fatalf("duplicate event should not deal damage, got %q", dup)
+	}
+
+	line = uc.RecordDamage(ctx, "bob", 60, "", "evt-2", now)
+	if !strings.Contains(line, "FINISHING BLOW") {
+		t.Fatalf("expected finishing blow, got %q", line)
+	}
+	if len(notifications) != 1 {
+		t.Fatalf("expected 1 defeat notification, got %d", len(notifications))
+	}
+	for _, expected := range []string{"RAID BOSS TUMBANG", "Finishing blow: bob", "MVP Raid", "2 hunter kontributor"} {
+		if !strings.Contains(notifications[0], expected) {
+			t.Errorf("expected notification to contain %q, got:\n%s", expected, notifications[0])
+		}
+	}
+
+	if after := uc.RecordDamage(ctx, "carol", 10, "", "evt-3", now); after != "" {
+		t.Fatalf("defeated boss should not take damage, got %q", after)
+	}
+}
+
+func TestRaidBossDefeat_GrantsEachContributorOnce(t *testing.T) {
+	now := time.Date(2026, 6, 16, 8, 0, 0, 0, time.UTC)
+	repo := newMockRaidRepo(0)
+	uc := NewRaidBossUsecase(repo)
+
+	granted := map[string]string{}
+	uc.SetRewardGranter(func(ctx context.Context, userID, eventID string, points int, now time.Time) (*domain.Report, error) {
+		if _, ok := granted[userID]; ok {
+			return nil, nil
+		}
+		granted[userID] = eventID
+		return &domain.Report{UserID: userID, Name: userID, TotalPoints: points}, nil
+	})
+	var rewarded []string
+	uc.SetEventPublisher(func(ctx context.Context, event domain.BotEvent) {
+		if event.Type == domain.BotEventRaidRewarded {
+			rewarded = append(rewarded, event.UserID)
+		}
+	})
+
+	ctx := context.Background()
+	uc.RecordDamage(ctx, "alice", 60, "", "evt-1", now)
+	uc.RecordDamage(ctx, "bob", 60, "", "evt-2", now)
+
+	if len(granted) != 2 || granted["alice"] != domain.RaidRewardEventID("raid-2026-06-15", "alice") {
+		t.Fatalf("unexpected grants: %+v", granted)
+	}
+	if len(rewarded) != 2 {
+		t.Fatalf("expected 2 raid.rewarded events, got %v", rewarded)
+	}
+}
+
+func TestRaidBossSettleUnrewarded_RetriesFailedRewards(t *testing.T) {
+	now := time.Date(2026, 6, 16, 8, 0, 0, 0, time.UTC)
+	repo := newMockRaidRepo(0)
+	uc := NewRaidBossUsecase(repo)
+
+	granted := map[string]bool{}
+	failing := "bob"
+	uc.SetRewardGranter(func(ctx context.Context, userID, eventID string, points int, now time.Time) (*domain.Report, error) {
+		if userID == failing {
+			return nil, errors.New("database is locked")
+		}
+		if granted[userID] {
+			return nil, nil
+		}
+		granted[userID] = true
+		return &domain.Report{UserID: userID, Name: userID}, nil
+	})
+	var announced []string
+	uc.SetDefeatNotifier(func(ctx context.Context, message string) {
+		announced = append(announced, message)
+	})
+
+	ctx := context.Background()
+	uc.RecordDamage(ctx, "alice", 60, "", "evt-1", now)
+	uc.RecordDamage(ctx, "bob", 60, "", "evt-2", now)
+	if len(announced) != 0 || len(repo.rewarded) != 0 {
+		t.Fatalf("a failed reward must leave the boss unrewarded, got announced=%q rewarded=%v", announced, repo.rewarded)
+	}
+
+	if _, err := uc.SettleUnrewarded(ctx, now); err == nil {
+		t.Fatal("a reward that fails again should be reported")
+	}
+
+	failing = ""
+	msg, err := uc.SettleUnrewarded(ctx, now)
+	if err != nil {
+		t.Fatalf("SettleUnrewarded: %v", err)
+	}
+	if !granted["alice"] || !granted["bob"] || len(repo.rewarded) != 1 || !strings.Contains(msg, "2 hunter kontributor") {
+		t.Fatalf("the retry should reward both hunters once, got granted=%v msg=%q", granted, msg)
+	}
+	if msg, err := uc.SettleUnrewarded(ctx, now); err != nil || msg != "" {
+		t.Fatalf("a settled boss should be left alone, got %q, %v", msg, err)
+	}
+}
+
+func TestRaidBossRevertDamage_RestoresHP(t *testing.T) {
+	now := time.Date(2026, 6, 16, 8, 0, 0, 0, time.UTC)
+	repo := newMockRaidRepo(10)
+	uc := NewRaidBossUsecase(repo)
+	ctx := context.Background()
+
+	uc.RecordDamage(ctx, "alice", 20, "", "evt-1", now)
+	boss := repo.bosses["raid-2026-06-15"]
+	if boss.HP == boss.MaxHP {
+		t.Fatal("expected the hit to deal damage")
+	}
+
+	uc.RevertDamage(ctx, "alice", "evt-1")
+	if boss.HP != boss.MaxHP || len(repo.hits[boss.ID]) != 0 {
+		t.Fatalf("after revert hp = %d/%d, hits = %d; want full hp and no hits", boss.HP, boss.MaxHP, len(repo.hits[boss.ID]))
+	}
+}
+
+func TestRaidBossExpire_SummarizesUndefeatedBoss(t *testing.T) {
+	week1 := time.Date(2026, 6, 16, 8, 0, 0, 0, time.UTC)
+	repo := newMockRaidRepo(10)
+	uc := NewRaidBossUsecase(repo)
+	ctx := context.Background()
+
+	uc.RecordDamage(ctx, "alice", 20, "", "evt-1", week1)
+
+	msg, err := uc.Expire(ctx, week1.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{"RAID BERAKHIR", "alice — 20 dmg"} {
+		if !strings.Contains(msg, expected) {
+			t.Errorf("expected summary to contain %q, got:\n%s", expected, msg)
+		}
+	}
+	if repo.bosses["raid-2026-06-15"].Status != domain.RaidStatusExpired {
+		t.Fatalf("expected boss to be expired")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math"
 	"strings"
@@ -50,15 +51,23 @@ type eventActivityRepository interface {
 	UpsertReportWithActivityEvent(ctx context.Context, report *domain.Report, event domain.ReportActivityEvent) error
 }
 
+type bonusEventRepository interface {
+	UpsertReportWithBonusEvent(ctx context.Context, report *domain.Report, event domain.BonusEvent) (bool, error)
+}
+
 // GoalCompletionNotifier is called when a user's weekly goal is completed.
 // userID and name identify the user; activity and targetDays describe the completed goal;
 // totalCompleted is the cumulative goals_completed count.
 type GoalCompletionNotifier func(ctx context.Context, userID string, name string, activity string, targetDays int, totalCompleted int)
 
 type ReportActivityUsecase struct {
-	repo         domain.ReportRepository
-	goalNotifier GoalCompletionNotifier
-	locks        sync.Map
+	repo           domain.ReportRepository
+	goalNotifier   GoalCompletionNotifier
+	eventPublisher BotEventPublisher
+	raidRecorder   RaidDamageRecorder
+	liftRecorder   LiftingRecorder
+	prRecorder     PersonalRecordRecorder
+	locks          sync.Map
 }
 
 type reportActivityOptions struct {
@@ -66,6 +75,7 @@ type reportActivityOptions struct {
 	activityText    string
 	now             time.Time
 	sideQuestPoints int // total points from side quest difficulty multipliers (computed in DailyQuestUsecase)
+	source          string
 }
 
 func NewReportActivityUsecase(repo domain.ReportRepository) *ReportActivityUsecase {
@@ -77,41 +87,260 @@ func (uc *ReportActivityUsecase) SetGoalNotifier(fn GoalCompletionNotifier) {
 	uc.goalNotifier = fn
 }
 
-func (uc *ReportActivityUsecase) userLock(userID string) *sync.Mutex {
+// SetEventPublisher sets the hook that receives report, achievement, level,
+// goal and streak events.
+func (uc *ReportActivityUsecase) SetEventPublisher(fn BotEventPublisher) {
+	uc.eventPublisher = fn
+}
+
+// SetRaidRecorder sets the hook that turns accepted reports into raid boss damage.
+func (uc *ReportActivityUsecase) SetRaidRecorder(fn RaidDamageRecorder) {
+	uc.raidRecorder = fn
+}
+
+// SetLiftingRecorder sets the hook that stores Hevy sets and detects lifting PRs.
+func (uc *ReportActivityUsecase) SetLiftingRecorder(fn LiftingRecorder) {
+	uc.liftRecorder = fn
+}
+
+// SetRecordTracker sets the hook that updates personal records after each report.
+func (uc *ReportActivityUsecase) SetRecordTracker(fn PersonalRecordRecorder) {
+	uc.prRecorder = fn
+}
+
+// UserLock returns the lock userID's report changes are made under. Other
+// usecases that rewrite the report, like cancellations, share it so they
+// can't interleave with a report or a bonus.
+func (uc *ReportActivityUsecase) UserLock(userID string) *sync.Mutex {
 	lock, _ := uc.locks.LoadOrStore(userID, &sync.Mutex{})
 	return lock.(*sync.Mutex)
 }
 
-func (uc *ReportActivityUsecase) Execute(ctx context.Context, userID, name string, workout *domain.HevyWorkout) (string, error) {
-	return uc.execute(ctx, userID, name, workout, reportActivityOptions{})
+type heldUserLockKey struct{}
+
+// withUserLockHeld marks ctx as running under userID's lock, so hooks a
+// report calls, like the raid reward of its finishing blow, can write to
+// the same user without waiting on the lock the report holds.
+func withUserLockHeld(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, heldUserLockKey{}, userID)
 }
 
-func (uc *ReportActivityUsecase) ExecuteWithMessage(ctx context.Context, userID, name, message string, workout *domain.HevyWorkout) (string, error) {
-	return uc.execute(ctx, userID, name, workout, reportActivityOptions{
-		activityText: message,
+func holdsUserLock(ctx context.Context, userID string) bool {
+	held, _ := ctx.Value(heldUserLockKey{}).(string)
+	return held == userID
+}
+
+// GrantBonus credits points that come with no activity, such as a raid
+// reward, to the user's lifetime and season totals. It is recorded as a
+// bonus event under eventID, so a bonus already granted is skipped; the
+// returned report is nil then, and when the user never reported.
+func (uc *ReportActivityUsecase) GrantBonus(ctx context.Context, userID, eventID, source string, points int, now time.Time) (*domain.Report, error) {
+	if !holdsUserLock(ctx, userID) {
+		lock := uc.UserLock(userID)
+		lock.Lock()
+		defer lock.Unlock()
+	}
+
+	report, err := uc.repo.GetReport(ctx, userID)
+	if err != nil || report == nil {
+		return nil, err
+	}
+	report.TotalPoints += points
+	report.SeasonalPoints += points
+	report.Level = domain.NumericLevelFromTotalPoints(report.TotalPoints)
+
+	repo, ok := uc.repo.(bonusEventRepository)
+	if !ok || !ReportEventLedgerEnabled(now) {
+		return report, uc.repo.UpsertReport(ctx, report)
+	}
+	seasonNumber, _ := GetCurrentSessionInfo(now)
+	granted, err := repo.UpsertReportWithBonusEvent(ctx, report, domain.BonusEvent{
+		EventID:      eventID,
+		UserID:       userID,
+		SeasonNumber: seasonNumber,
+		Source:       source,
+		Points:       points,
+		OccurredAt:   now,
 	})
+	if err != nil || !granted {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GrantRaidReward implements RaidRewardGranter.
+func (uc *ReportActivityUsecase) GrantRaidReward(ctx context.Context, userID, eventID string, points int, now time.Time) (*domain.Report, error) {
+	return uc.GrantBonus(ctx, userID, eventID, "raid", points, now)
+}
+
+func (uc *ReportActivityUsecase) Execute(ctx context.Context, userID, name string, workout *domain.Workout) (string, error) {
+	return uc.ExecuteWithMessage(ctx, userID, name, "", workout)
+}
+
+func (uc *ReportActivityUsecase) ExecuteWithMessage(ctx context.Context, userID, name, message string, workout *domain.Workout) (string, error) {
+	outcome, err := uc.ReportToday(ctx, userID, name, message, workout)
+	if err != nil {
+		return "", err
+	}
+	return RenderReportOutcome(outcome), nil
+}
+
+// ReportToday files today's report like /lapor, but returns what happened
+// instead of the bot's reply.
+func (uc *ReportActivityUsecase) ReportToday(ctx context.Context, userID, name, message string, workout *domain.Workout) (ReportOutcome, error) {
+	return uc.execute(ctx, userID, name, workout, reportActivityOptions{activityText: message})
+}
+
+// ExecuteFromSource reports an activity on the user's behalf for an
+// integration such as Strava. activityDate is the day the activity happened
+// (zero means today); yesterday follows the /lapor-kemarin rules and older
+// days are not reported. eventKey is empty when the report was not
+// accepted, e.g. because the daily limit was already reached.
+func (uc *ReportActivityUsecase) ExecuteFromSource(ctx context.Context, userID, name, source, activityText string, workout *domain.Workout, activityDate time.Time) (reply, eventKey string, err error) {
+	outcome, err := uc.ReportFromSource(ctx, userID, name, source, activityText, workout, activityDate)
+	if err != nil {
+		return "", "", err
+	}
+	return RenderReportOutcome(outcome), outcome.EventKey, nil
+}
+
+// ReportFromSource is ExecuteFromSource returning what happened instead of
+// the bot's reply.
+func (uc *ReportActivityUsecase) ReportFromSource(ctx context.Context, userID, name, source, activityText string, workout *domain.Workout, activityDate time.Time) (ReportOutcome, error) {
+	opts := reportActivityOptions{
+		activityText: activityText,
+		source:       source,
+	}
+	today := domain.GetToday(time.Now())
+	switch {
+	case activityDate.IsZero() || !activityDate.Before(today):
+		return uc.execute(ctx, userID, name, workout, opts)
+	case activityDate.Equal(today.AddDate(0, 0, -1)):
+		return uc.executeYesterday(ctx, userID, name, workout, opts)
+	default:
+		return rejectedReport(ReportRejectedTooOld, userID, name, domain.ActivityKindRegularReport, false, activityDate, 0, MaxDailyRegularReports), nil
+	}
+}
+
+// ImportPastActivity records an activity from an earlier day of the current
+// season, e.g. from a Strava backfill. Only days before yesterday are
+// accepted; today and yesterday go through ExecuteFromSource so the usual
+// streak rules apply. The day's report limit still holds, and a past report
+// earns base points only: streaks, achievements and goals are not
+// recalculated for history. sourceID is the activity's ID at source, which
+// with the day keys the event, so importing it again records nothing new and
+// returns the same key. occurredAt is when the activity started. eventKey is
+// empty when nothing was recorded.
+func (uc *ReportActivityUsecase) ImportPastActivity(ctx context.Context, userID, name, source, sourceID string, workout *domain.Workout, activityDate, occurredAt, now time.Time) (eventKey string, err error) {
+	lock := uc.UserLock(userID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	yesterday := domain.GetToday(now).AddDate(0, 0, -1)
+	if !activityDate.Before(yesterday) || activityDate.Before(SeasonStartDate(now)) {
+		return "", nil
+	}
+
+	eventKey = importedActivityEventID(userID, source, sourceID, activityDate)
+	if events, ok := uc.repo.(domain.ReportEventRepository); ok {
+		imported, err := events.GetReportEvent(ctx, userID, eventKey)
+		if err != nil {
+			return "", err
+		}
+		if imported != nil {
+			if !imported.CancelledAt.IsZero() {
+				return "", nil
+			}
+			return eventKey, nil
+		}
+	}
+
+	dailyCount, err := uc.getDailyActivityCount(ctx, userID, activityDate, domain.ActivityKindRegularReport)
+	if err != nil {
+		return "", err
+	}
+	if dailyCount >= MaxDailyRegularReports {
+		return "", nil
+	}
+
+	report, err := uc.repo.GetReport(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if report == nil {
+		report = &domain.Report{
+			UserID:         userID,
+			Name:           reportName("", name),
+			LastReportDate: activityDate,
+		}
+	}
+
+	points := baseReportPoints
+	if dailyCount > 0 {
+		points = points / 2
+	}
+	report.ActivityCount++
+	report.SeasonalActivityCount++
+	report.TotalPoints += points
+	report.SeasonalPoints += points
+	report.Level = domain.NumericLevelFromTotalPoints(report.TotalPoints)
+
+	if occurredAt.IsZero() {
+		occurredAt = now
+	}
+	metrics := workout.ApplyTo(domain.ParseActivityMetrics(goalActivityTextWithFallback(workout, "")))
+	if err := uc.upsertReportWithActivity(ctx, report, reportActivityEventInput{
+		eventID:           eventKey,
+		activityDate:      activityDate,
+		kind:              domain.ActivityKindRegularReport,
+		occurredAt:        occurredAt,
+		pointsDelta:       points,
+		regularCountDelta: 1,
+		activityText:      goalActivityTextWithFallback(workout, ""),
+		metrics:           metrics,
+		source:            source,
+	}); err != nil {
+		return "", err
+	}
+	return eventKey, nil
+}
+
+// SeasonStartDate returns the first day of the season containing now, in the
+// same UTC-midnight form as domain.GetToday.
+func SeasonStartDate(now time.Time) time.Time {
+	_, start := GetCurrentSessionInfo(now)
+	return time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
 }
 
 func (uc *ReportActivityUsecase) ExecuteSideQuest(ctx context.Context, userID, name, activityText string, completedCount, sideQuestPoints int, now time.Time) (string, error) {
+	outcome, err := uc.executeSideQuest(ctx, userID, name, activityText, completedCount, sideQuestPoints, now, reportActivityOptions{})
+	if err != nil {
+		return "", err
+	}
+	return RenderReportOutcome(outcome), nil
+}
+
+// executeSideQuest files a side quest with opts' source.
+func (uc *ReportActivityUsecase) executeSideQuest(ctx context.Context, userID, name, activityText string, completedCount, sideQuestPoints int, now time.Time, opts reportActivityOptions) (ReportOutcome, error) {
 	if completedCount < 1 {
 		completedCount = 1
 	}
-	return uc.execute(ctx, userID, name, nil, reportActivityOptions{
-		sideQuestCount:  completedCount,
-		activityText:    activityText,
-		now:             now,
-		sideQuestPoints: sideQuestPoints,
-	})
+	opts.sideQuestCount = completedCount
+	opts.activityText = activityText
+	opts.now = now
+	opts.sideQuestPoints = sideQuestPoints
+	return uc.execute(ctx, userID, name, nil, opts)
 }
 
-func (uc *ReportActivityUsecase) execute(ctx context.Context, userID, name string, workout *domain.HevyWorkout, opts reportActivityOptions) (string, error) {
-	lock := uc.userLock(userID)
+func (uc *ReportActivityUsecase) execute(ctx context.Context, userID, name string, workout *domain.Workout, opts reportActivityOptions) (ReportOutcome, error) {
+	lock := uc.UserLock(userID)
 	lock.Lock()
 	defer lock.Unlock()
+	ctx = withUserLockHeld(ctx, userID)
 
 	report, err := uc.repo.GetReport(ctx, userID)
 	if err != nil {
-		return "", err
+		return ReportOutcome{}, err
 	}
 
 	now := opts.now
@@ -128,7 +357,7 @@ func (uc *ReportActivityUsecase) execute(ctx context.Context, userID, name strin
 	var dailyCount int
 	dailyCount, err = uc.getDailyActivityCount(ctx, userID, today, activityKind)
 	if err != nil {
-		return "", err
+		return ReportOutcome{}, err
 	}
 
 	dailyLimit := MaxDailyRegularReports
@@ -136,27 +365,18 @@ func (uc *ReportActivityUsecase) execute(ctx context.Context, userID, name strin
 		dailyLimit = MaxDailySideQuests
 	}
 	if dailyCount >= dailyLimit {
-		label := "laporan utama"
-		if isSideQuest {
-			label = "side quest"
-		}
 		displayName := name
 		if report != nil {
 			displayName = report.Name
 		}
-		msg := fmt.Sprintf("%s sudah mencapai batas %s %dx hari ini. Batas laporan utama dan side quest terpisah: masing-masing %d kali per hari.", displayName, label, dailyLimit, MaxDailyRegularReports)
-		if !isSideQuest {
-			msg += " Kalau tadi salah input, pakai /cancel untuk hapus laporan terakhir atau /cancel-all untuk hapus semua laporan hari ini."
-		}
-		msg += " 🙏"
-		msg += fmt.Sprintf("\n\n💬 _\"%s\"_", RandomQuote())
-		return msg, nil
+		return rejectedReport(ReportRejectedDailyLimit, userID, displayName, activityKind, false, today, dailyCount, dailyLimit), nil
 	}
 
 	isRepeatReport := !isSideQuest && dailyCount > 0
 	isFullReport := !isSideQuest && !isRepeatReport
 
 	streakFreezeUsed := false
+	brokenStreak := 0
 
 	if report != nil {
 		storedName := report.Name
@@ -172,14 +392,14 @@ func (uc *ReportActivityUsecase) execute(ctx context.Context, userID, name strin
 		if isSideQuest {
 			if shouldUpdateStoredName {
 				if err := uc.repo.UpsertReport(ctx, report); err != nil {
-					return "", err
+					return ReportOutcome{}, err
 				}
 			}
 			name = report.Name
 		} else if lastReportDate.Equal(today) {
 			if shouldUpdateStoredName {
 				if err := uc.repo.UpsertReport(ctx, report); err != nil {
-					return "", err
+					return ReportOutcome{}, err
 				}
 			}
 			report.LastReportDate = now
@@ -202,6 +422,7 @@ func (uc *ReportActivityUsecase) execute(ctx context.Context, userID, name strin
 					report.ComebackStreak++
 					streakFreezeUsed = true
 				} else {
+					brokenStreak = report.Streak
 					report.InactiveDays = daysSinceLastReport
 					report.ComebackStreak = 1
 					report.Streak = 1
@@ -300,8 +521,10 @@ func (uc *ReportActivityUsecase) execute(ctx context.Context, userID, name strin
 			activityForParse += " " + ex
 		}
 	}
+	metrics := workout.ApplyTo(domain.ParseActivityMetrics(activityForParse))
 	attributesActive := hasSelectedJob(report)
-	var statGains []string
+	var statGains []AttributeGain
+	var chosenAttr domain.AttributeType
 	if attributesActive {
 		// A report grants a single, fair attribute point. The activity directs
 		// which attribute is rewarded; the job breaks ties among multiple
@@ -309,9 +532,9 @@ func (uc *ReportActivityUsecase) execute(ctx context.Context, userID, name strin
 		// every matched attribute would make mixed sessions worth several
 		// times the attribute points of focused ones.
 		attrs, _ := domain.ResolveReportAttributes(activityForParse, report.JobClass)
-		chosen := domain.SelectReportAttribute(attrs, report.JobClass,
+		chosenAttr = domain.SelectReportAttribute(attrs, report.JobClass,
 			attributeSelectionSeed(userID, activityKind, today.Format(time.DateOnly), dailyCount+1, activityForParse))
-		statGains = applyAttributeGains(report, []domain.AttributeType{chosen}, AttributeGainPerReport)
+		statGains = applyAttributeGains(report, []domain.AttributeType{chosenAttr}, AttributeGainPerReport)
 	}
 
 	var newAchievements []domain.Achievement
@@ -349,11 +572,6 @@ func (uc *ReportActivityUsecase) execute(ctx context.Context, userID, name strin
 	}
 
 	report.Level = domain.NumericLevelFromTotalPoints(report.TotalPoints)
-	leveledUp := report.Level > oldNumericLevel
-	newLifetimeTier := domain.GetLevel(report.TotalPoints)
-	newSeasonRank := domain.GetSeasonRank(report.SeasonalPoints)
-	lifetimeTierUp := newLifetimeTier.Tier > oldLifetimeTier.Tier
-	seasonRankUp := newSeasonRank.Tier > oldSeasonRank.Tier
 	totalPointsGained := reportPoints + pointsGained
 
 	if err := uc.upsertReportWithActivity(ctx, report, reportActivityEventInput{
@@ -364,160 +582,126 @@ func (uc *ReportActivityUsecase) execute(ctx context.Context, userID, name strin
 		regularCountDelta:   boolToInt(!isSideQuest),
 		sideQuestCountDelta: opts.sideQuestCount,
 		activityText:        goalActivityTextWithFallback(workout, opts.activityText),
+		metrics:             metrics,
+		source:              opts.source,
+		attributes:          statGains,
 	}); err != nil {
-		return "", err
+		return ReportOutcome{}, err
 	}
 	goalCompleted := false
 	if !isSideQuest {
 		goalCompleted, err = NewGoalUsecase(uc.repo).RecordActivity(ctx, userID, now, goalActivityTextWithFallback(workout, opts.activityText))
 		if err != nil {
-			return "", err
-		}
-	}
-
-	isComeback := isFullReport && report.InactiveDays > 3 && report.Streak == 1
-	var response string
-
-	if isComeback {
-		response = fmt.Sprintf("🎉 WELCOME BACK, %s! 🎉\n", name)
-		response += fmt.Sprintf("Kamu kembali setelah %d hari absen. Itu butuh keberanian! 💪\n", report.InactiveDays)
-		response += "Streak kamu direset, tapi totalmu tetap tersimpan.\n"
-		if report.JobClass != "" {
-			response += fmt.Sprintf("🧭 Job: %s\n", domain.FormatJobClass(report.JobClass))
+			return ReportOutcome{}, err
 		}
-		response += fmt.Sprintf("\n📊 Level: Lv.%d • %s (Total: %d pts)\n", report.Level, domain.FormatLevel(report.TotalPoints), report.TotalPoints)
-		response += fmt.Sprintf("📅 Total hari aktif: %d\n", report.ActivityCount)
-
-		nextComebackAch := uc.getNextComebackTarget(report)
-		if nextComebackAch != nil {
-			response += fmt.Sprintf("\n🔥 Comeback Challenge dimulai! Raih %d minggu berturut-turut untuk unlock \"%s\"!", nextComebackAch.MinComebackStreak, nextComebackAch.Name)
-		} else {
-			response += "\n🔥 Comeback Challenge dimulai! Ayo bangun streak-mu kembali!"
-		}
-	} else {
-		cyclePrefix := ""
-		if report.CenturionCycles > 0 {
-			cyclePrefix = fmt.Sprintf("[C%d] ", report.CenturionCycles+1)
-		}
-
-		// Only the final point result is shown; the per-component breakdown
-		// (base / weekly / daily / seasonal / repeat ½) is intentionally hidden
-		// from the user-facing message for both /lapor and /lapor sidequest.
-		expBreakdown := fmt.Sprintf("⭐ +%d pts", reportPoints)
-		if len(statGains) > 0 {
-			expBreakdown += fmt.Sprintf("\n💪 Attributes: %s", strings.Join(statGains, ", "))
-		}
-
-		if isSideQuest {
-			response = fmt.Sprintf("Side quest diterima, %s%s menyelesaikan %d side quest. Ini bonus terpisah dari 3 slot laporan utama harian. 🔥\n%s",
-				cyclePrefix, name, opts.sideQuestCount, expBreakdown)
-		} else if isRepeatReport {
-			response = fmt.Sprintf("Laporan diterima (laporan ke-%d hari ini), %s%s sudah berkeringat %d hari. Lanjutkan 🔥 (streak %d minggu)\n%s",
-				dailyCount+1, cyclePrefix, name, report.ActivityCount, report.Streak, expBreakdown)
-		} else {
-			response = fmt.Sprintf("Laporan diterima, %s%s sudah berkeringat %d hari. Lanjutkan 🔥 (streak %d minggu)\n%s",
-				cyclePrefix, name, report.ActivityCount, report.Streak, expBreakdown)
-		}
-		if report.JobClass != "" {
-			response += fmt.Sprintf("\n🧭 Job: %s", domain.FormatJobClass(report.JobClass))
-		}
-	}
-	if !attributesActive {
-		response += "\n\n" + formatJobSetupNotice()
-	}
-
-	if isFullReport && report.ActivityCount == 1 && report.CenturionCycles > 0 && !isComeback {
-		response = "🔥 *ERA BARU DIMULAI!* 🔥\n" +
-			fmt.Sprintf("Selamat %s, Anda telah menyelesaikan 100 hari sebelumnya.\n", name) +
-			fmt.Sprintf("Sekarang Anda memulai *Siklus %d (Hari ke-1)*. Terus jaga konsistensi! 💪\n\n", report.CenturionCycles+1) +
-			response
-	} else if isFullReport && report.ActivityCount == 100 {
-		response = "🎊 *LUAR BIASA!* 🎊\n" +
-			fmt.Sprintf("Selamat %s, Anda mencapai *HARI KE-100*! 💯\n", name) +
-			"Anda sekarang resmi menyandang gelar *CENTURION 🛡️*. Nama Anda telah diabdikan dalam jajaran legenda grup!\n\n" +
-			response
-	}
-
-	if workout != nil {
-		response += "\n" + uc.formatWorkout(workout)
-	}
-
-	if streakFreezeUsed {
-		response += fmt.Sprintf("\n\n❄️ *Streak Freeze terpakai!* Streak kamu aman. (Sisa freeze: %d)", report.StreakFreezes)
 	}
-
-	if newRecord {
-		response += fmt.Sprintf("\n\n🏆 New Personal Best Streak: %d minggu!", report.MaxStreak)
-	}
-
+	eventKey := reportActivityEventID(userID, activityKind, today, now, totalPointsGained, boolToInt(!isSideQuest), opts.sideQuestCount)
+	raidLine := ""
+	if uc.raidRecorder != nil {
+		raidLine = uc.raidRecorder(ctx, userID, totalPointsGained, chosenAttr, eventKey, now)
+	}
+	liftLine := ""
+	if uc.liftRecorder != nil && !isSideQuest && workout != nil {
+		liftLine = uc.liftRecorder(ctx, userID, name, workout, eventKey, today, now)
+	}
+	recordLine := ""
+	if uc.prRecorder != nil {
+		recordLine = uc.prRecorder(ctx, PersonalRecordEvent{
+			UserID:       userID,
+			Name:         name,
+			EventKey:     eventKey,
+			ActivityText: opts.activityText,
+			Metrics:      metrics,
+			Workout:      workout,
+			ActivityDate: today,
+			OccurredAt:   now,
+		})
+	}
+
+	goalsCompleted := 0
 	if goalCompleted {
-		response += "\n\n🎯 *Goal minggu ini tercapai!* Konsistensi harianmu sudah sesuai target. Mantap, champ! 🏆"
+		goalsCompleted = 1
+		if report.GoalsCompleted > 0 {
+			goalsCompleted = report.GoalsCompleted + 1
+		}
 		if uc.goalNotifier != nil {
-			goalsCompleted := 1
-			if report.GoalsCompleted > 0 {
-				goalsCompleted = report.GoalsCompleted + 1
-			}
 			uc.goalNotifier(ctx, userID, name, "", 0, goalsCompleted)
 		}
 	}
+	outcome := ReportOutcome{
+		Accepted:     true,
+		UserID:       userID,
+		Name:         name,
+		Kind:         activityKind,
+		Source:       opts.source,
+		ActivityDate: today,
+		OccurredAt:   now,
+		EventKey:     eventKey,
+		DailyCount:   dailyCount,
+		DailyLimit:   dailyLimit,
+		SideQuests:   opts.sideQuestCount,
+		Points: ReportPoints{
+			Base:              basePoints,
+			WeeklyStreakBonus: weeklyStreakBonus,
+			DailyStreakBonus:  dailyStreakBonus,
+			SeasonFirstBonus:  seasonalFirstBonus,
+			RepeatHalved:      isRepeatReport,
+			Report:            reportPoints,
+			Badges:            pointsGained,
+			Total:             totalPointsGained,
+		},
+		AttributeGains: statGains,
+		Badges:         newAchievements,
+		ComebackBadges: comebackAchievements,
+		Report:         report,
+		OldLevel:       oldNumericLevel,
+		OldTier:        oldLifetimeTier,
+		OldRank:        oldSeasonRank,
+		BrokenStreak:   brokenStreak,
+		Comeback:       isFullReport && report.InactiveDays > 3 && report.Streak == 1,
+		NewBestStreak:  newRecord,
+		FreezeUsed:     streakFreezeUsed,
+		FreezeAwarded:  freezeAwarded,
+		GoalCompleted:  goalCompleted,
+		GoalsCompleted: goalsCompleted,
+		Workout:        workout,
+		Metrics:        metrics,
+		RaidLine:       raidLine,
+		LiftLine:       liftLine,
+		RecordLine:     recordLine,
+	}
+	uc.publishReportEvents(ctx, outcome)
+	return outcome, nil
+}
 
-	if leveledUp {
-		response += fmt.Sprintf("\n\n⚔️ *LEVEL UP!* Lv.%d → Lv.%d", oldNumericLevel, report.Level)
-	}
-	if lifetimeTierUp {
-		response += fmt.Sprintf("\n🎖️ *TIER LIFETIME UP!* %s %s → %s %s", oldLifetimeTier.Name, oldLifetimeTier.Icon, newLifetimeTier.Name, newLifetimeTier.Icon)
-	}
-	if seasonRankUp {
-		response += fmt.Sprintf("\n🏹 *RANK SEASON UP!* %s %s → %s %s", oldSeasonRank.Name, oldSeasonRank.Icon, newSeasonRank.Name, newSeasonRank.Icon)
-	}
-
-	if len(newAchievements)+len(comebackAchievements) > 0 {
-		response += "\n\n🏅 *Badge baru:*"
-		for _, ach := range newAchievements {
-			response += fmt.Sprintf("\n%s %s (+%d pts)", ach.DisplayEmoji, ach.Name, ach.Points)
-		}
-		for _, ach := range comebackAchievements {
-			response += fmt.Sprintf("\n%s %s (+%d pts)", ach.DisplayEmoji, ach.Name, ach.Points)
-		}
-
-		if freezeAwarded {
-			response += fmt.Sprintf("\n\n❄️ Bonus: +1 Streak Freeze! (Total: %d)", report.StreakFreezes)
-		}
-
-		response += "\nDetail badge & cerita unlock: https://lapor-bot.web.id/"
-	}
-
-	response += fmt.Sprintf("\n\n💬 _\"%s\"_", RandomQuote())
-
-	if totalPointsGained > 0 {
-		response += fmt.Sprintf("\n\n💰 Total: +%d points (Lifetime: %d | Season: %d)", totalPointsGained, report.TotalPoints, report.SeasonalPoints)
-	}
-
-	response += fmt.Sprintf("\n%s", domain.FormatNumericLevelProgressBar(report.TotalPoints))
-	response += fmt.Sprintf("\n%s", domain.FormatProgressBar(report.TotalPoints))
-	if attributesActive {
-		response += fmt.Sprintf("\n\n%s", formatCurrentAttributes(report))
-	}
-
-	return response, nil
+func (uc *ReportActivityUsecase) ExecuteYesterday(ctx context.Context, userID, name string, workout *domain.Workout) (string, error) {
+	return uc.ExecuteYesterdayWithMessage(ctx, userID, name, "", workout)
 }
 
-func (uc *ReportActivityUsecase) ExecuteYesterday(ctx context.Context, userID, name string, workout *domain.HevyWorkout) (string, error) {
-	return uc.executeYesterday(ctx, userID, name, "", workout)
+func (uc *ReportActivityUsecase) ExecuteYesterdayWithMessage(ctx context.Context, userID, name, message string, workout *domain.Workout) (string, error) {
+	outcome, err := uc.ReportYesterday(ctx, userID, name, message, workout)
+	if err != nil {
+		return "", err
+	}
+	return RenderReportOutcome(outcome), nil
 }
 
-func (uc *ReportActivityUsecase) ExecuteYesterdayWithMessage(ctx context.Context, userID, name, message string, workout *domain.HevyWorkout) (string, error) {
-	return uc.executeYesterday(ctx, userID, name, message, workout)
+// ReportYesterday files yesterday's report like /lapor-kemarin, but returns
+// what happened instead of the bot's reply.
+func (uc *ReportActivityUsecase) ReportYesterday(ctx context.Context, userID, name, message string, workout *domain.Workout) (ReportOutcome, error) {
+	return uc.executeYesterday(ctx, userID, name, workout, reportActivityOptions{activityText: message})
 }
 
-func (uc *ReportActivityUsecase) executeYesterday(ctx context.Context, userID, name, activityText string, workout *domain.HevyWorkout) (string, error) {
-	lock := uc.userLock(userID)
+func (uc *ReportActivityUsecase) executeYesterday(ctx context.Context, userID, name string, workout *domain.Workout, opts reportActivityOptions) (ReportOutcome, error) {
+	activityText := opts.activityText
+	lock := uc.UserLock(userID)
 	lock.Lock()
 	defer lock.Unlock()
+	ctx = withUserLockHeld(ctx, userID)
 
 	report, err := uc.repo.GetReport(ctx, userID)
 	if err != nil {
-		return "", err
+		return ReportOutcome{}, err
 	}
 
 	now := time.Now()
@@ -526,25 +710,33 @@ func (uc *ReportActivityUsecase) executeYesterday(ctx context.Context, userID, n
 
 	dailyCount, err := uc.getDailyActivityCount(ctx, userID, yesterday, domain.ActivityKindRegularReport)
 	if err != nil {
-		return "", err
+		return ReportOutcome{}, err
+	}
+	rejected := func(reason string) ReportOutcome {
+		displayName := name
+		if report != nil {
+			displayName = report.Name
+		}
+		return rejectedReport(reason, userID, displayName, domain.ActivityKindRegularReport, true, yesterday, dailyCount, MaxDailyReports)
 	}
 	if dailyCount >= MaxDailyReports {
-		return fmt.Sprintf("%s sudah mencapai batas maksimal %dx laporan untuk hari kemarin. 🙏", report.Name, MaxDailyReports), nil
+		return rejected(ReportRejectedDailyLimit), nil
 	}
 
 	if report != nil && domain.GetToday(report.LastReportDate).Equal(today) {
-		return fmt.Sprintf("%s sudah laporan hari ini. Gunakan /lapor untuk laporan tambahan hari ini. 💪", report.Name), nil
+		return rejected(ReportRejectedAlreadyToday), nil
 	}
 
 	if report != nil {
 		lastReportDate := domain.GetToday(report.LastReportDate)
 		daysSinceLastReport := int(math.Round(today.Sub(lastReportDate).Hours() / 24))
 		if daysSinceLastReport < 2 {
-			return fmt.Sprintf("%s sudah laporan dalam 2 hari terakhir. Tidak perlu lapor ulang untuk hari kemarin. 🙏", report.Name), nil
+			return rejected(ReportRejectedReportedRecently), nil
 		}
 	}
 
 	streakFreezeUsed := false
+	brokenStreak := 0
 
 	if report != nil {
 		storedName := report.Name
@@ -569,6 +761,7 @@ func (uc *ReportActivityUsecase) executeYesterday(ctx context.Context, userID, n
 			report.ComebackStreak++
 			streakFreezeUsed = true
 		} else {
+			brokenStreak = report.Streak
 			report.InactiveDays = int(math.Round(today.Sub(lastReportDate).Hours()/24)) - 1
 			report.ComebackStreak = 1
 			report.Streak = 1
@@ -611,6 +804,8 @@ func (uc *ReportActivityUsecase) executeYesterday(ctx context.Context, userID, n
 		report.MaxStreak = 1
 	}
 	oldNumericLevel := domain.NumericLevelFromTotalPoints(report.TotalPoints)
+	oldLifetimeTier := domain.GetLevel(report.TotalPoints)
+	oldSeasonRank := domain.GetSeasonRank(report.SeasonalPoints)
 
 	newRecord := false
 	if report.Streak > report.MaxStreak {
@@ -646,8 +841,10 @@ func (uc *ReportActivityUsecase) executeYesterday(ctx context.Context, userID, n
 			activityForParse += " " + ex
 		}
 	}
+	metrics := workout.ApplyTo(domain.ParseActivityMetrics(activityForParse))
 	attributesActive := hasSelectedJob(report)
-	var statGains []string
+	var statGains []AttributeGain
+	var chosenAttr domain.AttributeType
 	if attributesActive {
 		// A report grants a single, fair attribute point. The activity directs
 		// which attribute is rewarded; the job breaks ties among multiple
@@ -655,9 +852,9 @@ func (uc *ReportActivityUsecase) executeYesterday(ctx context.Context, userID, n
 		// every matched attribute would make mixed sessions worth several
 		// times the attribute points of focused ones.
 		attrs, _ := domain.ResolveReportAttributes(activityForParse, report.JobClass)
-		chosen := domain.SelectReportAttribute(attrs, report.JobClass,
+		chosenAttr = domain.SelectReportAttribute(attrs, report.JobClass,
 			attributeSelectionSeed(userID, domain.ActivityKindRegularReport, yesterday.Format(time.DateOnly), dailyCount+1, activityForParse))
-		statGains = applyAttributeGains(report, []domain.AttributeType{chosen}, AttributeGainPerReport)
+		statGains = applyAttributeGains(report, []domain.AttributeType{chosenAttr}, AttributeGainPerReport)
 	}
 
 	newAchievements := domain.CheckNewSeasonAchievements(report)
@@ -689,7 +886,6 @@ func (uc *ReportActivityUsecase) executeYesterday(ctx context.Context, userID, n
 	}
 
 	report.Level = domain.NumericLevelFromTotalPoints(report.TotalPoints)
-	leveledUp := report.Level > oldNumericLevel
 	totalPointsGained := reportPoints + pointsGained
 
 	if err := uc.upsertReportWithActivity(ctx, report, reportActivityEventInput{
@@ -700,126 +896,91 @@ func (uc *ReportActivityUsecase) executeYesterday(ctx context.Context, userID, n
 		regularCountDelta:   1,
 		sideQuestCountDelta: 0,
 		activityText:        goalActivityTextWithFallback(workout, activityText),
+		metrics:             metrics,
+		source:              opts.source,
+		attributes:          statGains,
 	}); err != nil {
-		return "", err
+		return ReportOutcome{}, err
 	}
 	goalCompleted, err := NewGoalUsecase(uc.repo).RecordActivity(ctx, userID, yesterday, goalActivityText(workout))
 	if err != nil {
-		return "", err
-	}
-
-	isComeback := report.InactiveDays > 3 && report.Streak == 1
-	var response string
-
-	if isComeback {
-		response = fmt.Sprintf("🎉 WELCOME BACK, %s! 🎉\n", name)
-		response += fmt.Sprintf("Kamu kembali setelah %d hari absen. Itu butuh keberanian! 💪\n", report.InactiveDays)
-		response += "Streak kamu direset, tapi totalmu tetap tersimpan.\n"
-		if report.JobClass != "" {
-			response += fmt.Sprintf("🧭 Job: %s\n", domain.FormatJobClass(report.JobClass))
-		}
-		response += fmt.Sprintf("\n📊 Level: Lv.%d • %s (Total: %d pts)\n", report.Level, domain.FormatLevel(report.TotalPoints), report.TotalPoints)
-		response += fmt.Sprintf("📅 Total hari aktif: %d\n", report.ActivityCount)
-
-		nextComebackAch := uc.getNextComebackTarget(report)
-		if nextComebackAch != nil {
-			response += fmt.Sprintf("\n🔥 Comeback Challenge dimulai! Raih %d minggu berturut-turut untuk unlock \"%s\"!", nextComebackAch.MinComebackStreak, nextComebackAch.Name)
-		} else {
-			response += "\n🔥 Comeback Challenge dimulai! Ayo bangun streak-mu kembali!"
-		}
-	} else {
-		cyclePrefix := ""
-		if report.CenturionCycles > 0 {
-			cyclePrefix = fmt.Sprintf("[C%d] ", report.CenturionCycles+1)
-		}
-
-		// Only the final point result is shown; the per-component breakdown is
-		// intentionally hidden from the user-facing message.
-		expBreakdown := fmt.Sprintf("⭐ +%d pts", reportPoints)
-		expBreakdown += " (lapor kemarin)"
-
-		if len(statGains) > 0 {
-			expBreakdown += fmt.Sprintf("\n💪 Attributes: %s", strings.Join(statGains, ", "))
-		}
-
-		response = fmt.Sprintf("Laporan kemarin diterima, %s%s sudah berkeringat %d hari. Lanjutkan 🔥 (streak %d minggu)\n%s",
-			cyclePrefix, name, report.ActivityCount, report.Streak, expBreakdown)
-		if report.JobClass != "" {
-			response += fmt.Sprintf("\n🧭 Job: %s", domain.FormatJobClass(report.JobClass))
-		}
-	}
-	if !attributesActive {
-		response += "\n\n" + formatJobSetupNotice()
-	}
-
-	if report.ActivityCount == 1 && report.CenturionCycles > 0 && !isComeback {
-		response = "🔥 *ERA BARU DIMULAI!* 🔥\n" +
-			fmt.Sprintf("Selamat %s, Anda telah menyelesaikan 100 hari sebelumnya.\n", name) +
-			fmt.Sprintf("Sekarang Anda memulai *Siklus %d (Hari ke-1)*. Terus jaga konsistensi! 💪\n\n", report.CenturionCycles+1) +
-			response
-	} else if report.ActivityCount == 100 {
-		response = "🎊 *LUAR BIASA!* 🎊\n" +
-			fmt.Sprintf("Selamat %s, Anda mencapai *HARI KE-100*! 💯\n", name) +
-			"Anda sekarang resmi menyandang gelar *CENTURION 🛡️*. Nama Anda telah diabdikan dalam jajaran legenda grup!\n\n" +
-			response
-	}
-
-	if workout != nil {
-		response += "\n" + uc.formatWorkout(workout)
-	}
-
-	if streakFreezeUsed {
-		response += fmt.Sprintf("\n\n❄️ *Streak Freeze terpakai!* Streak kamu aman. (Sisa freeze: %d)", report.StreakFreezes)
-	}
-
-	if newRecord {
-		response += fmt.Sprintf("\n\n🏆 New Personal Best Streak: %d minggu!", report.MaxStreak)
-	}
-
+		return ReportOutcome{}, err
+	}
+	eventKey := reportActivityEventID(userID, domain.ActivityKindRegularReport, yesterday, now, totalPointsGained, 1, 0)
+	raidLine := ""
+	if uc.raidRecorder != nil {
+		raidLine = uc.raidRecorder(ctx, userID, totalPointsGained, chosenAttr, eventKey, now)
+	}
+	liftLine := ""
+	if uc.liftRecorder != nil && workout != nil {
+		liftLine = uc.liftRecorder(ctx, userID, name, workout, eventKey, yesterday, now)
+	}
+	recordLine := ""
+	if uc.prRecorder != nil {
+		recordLine = uc.prRecorder(ctx, PersonalRecordEvent{
+			UserID:       userID,
+			Name:         name,
+			EventKey:     eventKey,
+			ActivityText: activityText,
+			Metrics:      metrics,
+			Workout:      workout,
+			ActivityDate: yesterday,
+			OccurredAt:   now,
+		})
+	}
+
+	goalsCompleted := 0
 	if goalCompleted {
-		response += "\n\n🎯 *Goal minggu ini tercapai!* Konsistensi harianmu sudah sesuai target. Mantap, champ! 🏆"
+		goalsCompleted = 1
+		if report.GoalsCompleted > 0 {
+			goalsCompleted = report.GoalsCompleted + 1
+		}
 		if uc.goalNotifier != nil {
-			goalsCompleted := 1
-			if report.GoalsCompleted > 0 {
-				goalsCompleted = report.GoalsCompleted + 1
-			}
 			uc.goalNotifier(ctx, userID, name, "", 0, goalsCompleted)
 		}
 	}
-
-	if leveledUp {
-		response += fmt.Sprintf("\n\n⚔️ *LEVEL UP!* Lv.%d → Lv.%d", oldNumericLevel, report.Level)
-	}
-
-	if len(newAchievements)+len(comebackAchievements) > 0 {
-		response += "\n\n🏅 *Badge baru:*"
-		for _, ach := range newAchievements {
-			response += fmt.Sprintf("\n%s %s (+%d pts)", ach.DisplayEmoji, ach.Name, ach.Points)
-		}
-		for _, ach := range comebackAchievements {
-			response += fmt.Sprintf("\n%s %s (+%d pts)", ach.DisplayEmoji, ach.Name, ach.Points)
-		}
-
-		if freezeAwarded {
-			response += fmt.Sprintf("\n\n❄️ Bonus: +1 Streak Freeze! (Total: %d)", report.StreakFreezes)
-		}
-
-		response += "\nDetail badge & cerita unlock: https://lapor-bot.web.id/"
-	}
-
-	response += fmt.Sprintf("\n\n💬 _\"%s\"_", RandomQuote())
-
-	if totalPointsGained > 0 {
-		response += fmt.Sprintf("\n\n💰 Total: +%d points (Lifetime: %d | Season: %d)", totalPointsGained, report.TotalPoints, report.SeasonalPoints)
-	}
-
-	response += fmt.Sprintf("\n%s", domain.FormatNumericLevelProgressBar(report.TotalPoints))
-	response += fmt.Sprintf("\n%s", domain.FormatProgressBar(report.TotalPoints))
-	if attributesActive {
-		response += fmt.Sprintf("\n\n%s", formatCurrentAttributes(report))
-	}
-
-	return response, nil
+	outcome := ReportOutcome{
+		Accepted:     true,
+		UserID:       userID,
+		Name:         name,
+		Kind:         domain.ActivityKindRegularReport,
+		Source:       opts.source,
+		Late:         true,
+		ActivityDate: yesterday,
+		OccurredAt:   now,
+		EventKey:     eventKey,
+		DailyCount:   dailyCount,
+		DailyLimit:   MaxDailyReports,
+		Points: ReportPoints{
+			Base:              basePoints,
+			WeeklyStreakBonus: weeklyStreakBonus,
+			SeasonFirstBonus:  seasonalFirstBonus,
+			Report:            reportPoints,
+			Badges:            pointsGained,
+			Total:             totalPointsGained,
+		},
+		AttributeGains: statGains,
+		Badges:         newAchievements,
+		ComebackBadges: comebackAchievements,
+		Report:         report,
+		OldLevel:       oldNumericLevel,
+		OldTier:        oldLifetimeTier,
+		OldRank:        oldSeasonRank,
+		BrokenStreak:   brokenStreak,
+		Comeback:       report.InactiveDays > 3 && report.Streak == 1,
+		NewBestStreak:  newRecord,
+		FreezeUsed:     streakFreezeUsed,
+		FreezeAwarded:  freezeAwarded,
+		GoalCompleted:  goalCompleted,
+		GoalsCompleted: goalsCompleted,
+		Workout:        workout,
+		Metrics:        metrics,
+		RaidLine:       raidLine,
+		LiftLine:       liftLine,
+		RecordLine:     recordLine,
+	}
+	uc.publishReportEvents(ctx, outcome)
+	return outcome, nil
 }
 
 func (uc *ReportActivityUsecase) getDailyActivityCount(ctx context.Context, userID string, date time.Time, kind string) (int, error) {
@@ -830,6 +991,8 @@ func (uc *ReportActivityUsecase) getDailyActivityCount(ctx context.Context, user
 }
 
 type reportActivityEventInput struct {
+	// eventID overrides the ID derived from the other fields.
+	eventID             string
 	activityDate        time.Time
 	kind                string
 	occurredAt          time.Time
@@ -837,13 +1000,29 @@ type reportActivityEventInput struct {
 	regularCountDelta   int
 	sideQuestCountDelta int
 	activityText        string
+	metrics             domain.ActivityMetrics
+	source              string
+	attributes          []AttributeGain
+}
+
+// reportEventMetadata is what a report event keeps in its metadata_json.
+type reportEventMetadata struct {
+	Attributes []AttributeGain `json:"attributes,omitempty"`
 }
 
 func (uc *ReportActivityUsecase) upsertReportWithActivity(ctx context.Context, report *domain.Report, input reportActivityEventInput) error {
 	if repo, ok := uc.repo.(eventActivityRepository); ok && ReportEventLedgerEnabled(input.occurredAt) {
 		seasonNumber, _ := GetCurrentSessionInfo(input.occurredAt)
+		source := input.source
+		if source == "" {
+			source = "whatsapp"
+		}
+		eventID := input.eventID
+		if eventID == "" {
+			eventID = reportActivityEventID(report.UserID, input.kind, input.activityDate, input.occurredAt, input.pointsDelta, input.regularCountDelta, input.sideQuestCountDelta)
+		}
 		event := domain.ReportActivityEvent{
-			EventID:             reportActivityEventID(report.UserID, input.kind, input.activityDate, input.occurredAt, input.pointsDelta, input.regularCountDelta, input.sideQuestCountDelta),
+			EventID:             eventID,
 			UserID:              report.UserID,
 			SeasonNumber:        seasonNumber,
 			Kind:                input.kind,
@@ -853,10 +1032,17 @@ func (uc *ReportActivityUsecase) upsertReportWithActivity(ctx context.Context, r
 			RegularCountDelta:   input.regularCountDelta,
 			SideQuestCountDelta: input.sideQuestCountDelta,
 			RuleVersion:         1,
-			Source:              "whatsapp",
+			Source:              source,
 			ActivityText:        input.activityText,
 			MetadataJSON:        "{}",
 		}
+		if metadata, err := json.Marshal(reportEventMetadata{Attributes: input.attributes}); err == nil {
+			event.MetadataJSON = string(metadata)
+		}
+		if !input.metrics.IsZero() {
+			metrics := input.metrics
+			event.Metrics = &metrics
+		}
 		return repo.UpsertReportWithActivityEvent(ctx, report, event)
 	}
 	if repo, ok := uc.repo.(typedActivityRepository); ok {
@@ -888,6 +1074,14 @@ func reportActivityEventID(userID, kind string, activityDate, occurredAt time.Ti
 	return hex.EncodeToString(sum[:])
 }
 
+// importedActivityEventID keys an imported activity by where it came from,
+// not by what it scored, which depends on the day's other reports.
+func importedActivityEventID(userID, source, sourceID string, activityDate time.Time) string {
+	seed := fmt.Sprintf("import|%s|%s|%s|%s", userID, source, sourceID, activityDate.Format(time.DateOnly))
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
 func boolToInt(v bool) int {
 	if v {
 		return 1
@@ -971,7 +1165,7 @@ func isUnknownName(name string) bool {
 		strings.EqualFold(clean, "user")
 }
 
-func (uc *ReportActivityUsecase) formatWorkout(workout *domain.HevyWorkout) string {
+func formatWorkout(workout *domain.Workout) string {
 	if workout == nil {
 		return ""
 	}
@@ -984,12 +1178,46 @@ func (uc *ReportActivityUsecase) formatWorkout(workout *domain.HevyWorkout) stri
 		}
 	}
 	if workout.Time != "" {
-		res += fmt.Sprintf("... ⏱️ Time: %s", workout.Time)
+		res += fmt.Sprintf("... ⏱️ Time: %s\n", workout.Time)
+	}
+
+	// Cardio apps share numbers rather than an exercise list.
+	var stats []string
+	if workout.Source != "" && workout.Source != domain.WorkoutSourceHevy {
+		stats = append(stats, fmt.Sprintf("📱 %s", domain.WorkoutSourceName(workout.Source)))
+	}
+	if workout.DistanceMeters > 0 {
+		stats = append(stats, fmt.Sprintf("📏 %.2f km", float64(workout.DistanceMeters)/1000))
+	}
+	if workout.DurationSeconds > 0 && workout.Time == "" {
+		stats = append(stats, fmt.Sprintf("⏱️ %s", domain.FormatDuration(workout.DurationSeconds)))
+	}
+	if pace := workoutPace(workout); pace != "" {
+		stats = append(stats, fmt.Sprintf("⚡ %s", pace))
+	}
+	if workout.Calories > 0 {
+		stats = append(stats, fmt.Sprintf("🔥 %d kcal", workout.Calories))
+	}
+	if len(stats) > 0 {
+		res += strings.Join(stats, "\n")
 	}
 	return res
 }
 
-func (uc *ReportActivityUsecase) getNextComebackTarget(report *domain.Report) *domain.ComebackAchievement {
+// workoutPace shows speed for rides and pace per kilometre for activities on
+// foot, where runners think in minutes per kilometre.
+func workoutPace(workout *domain.Workout) string {
+	switch workout.ActivityType {
+	case domain.ActivityTypeRide:
+		return domain.FormatSpeed(workout.DistanceMeters, workout.DurationSeconds)
+	case domain.ActivityTypeRun, domain.ActivityTypeWalk, domain.ActivityTypeHike:
+		return domain.FormatPace(workout.DistanceMeters, workout.DurationSeconds)
+	default:
+		return ""
+	}
+}
+
+func nextComebackTarget(report *domain.Report) *domain.ComebackAchievement {
 	for i := range domain.AllComebackAchievements {
 		a := &domain.AllComebackAchievements[i]
 		if !domain.HasAchievement(report.Achievements, a.ID) &&
@@ -1000,27 +1228,26 @@ func (uc *ReportActivityUsecase) getNextComebackTarget(report *domain.Report) *d
 	return nil
 }
 
-func applyAttributeGains(report *domain.Report, attrs []domain.AttributeType, statPoints int) []string {
+func applyAttributeGains(report *domain.Report, attrs []domain.AttributeType, statPoints int) []AttributeGain {
 	if report == nil || statPoints <= 0 || len(attrs) == 0 {
 		return nil
 	}
 
-	var statGains []string
+	var statGains []AttributeGain
 	for _, attr := range attrs {
 		switch attr {
 		case domain.AttrStr:
 			report.Str = domain.ClampedAttribute(report.Str) + statPoints
-			statGains = append(statGains, fmt.Sprintf("STR +%d", statPoints))
 		case domain.AttrSta:
 			report.Sta = domain.ClampedAttribute(report.Sta) + statPoints
-			statGains = append(statGains, fmt.Sprintf("STA +%d", statPoints))
 		case domain.AttrAgi:
 			report.Agi = domain.ClampedAttribute(report.Agi) + statPoints
-			statGains = append(statGains, fmt.Sprintf("AGI +%d", statPoints))
 		case domain.AttrVit:
 			report.Vit = domain.ClampedAttribute(report.Vit) + statPoints
-			statGains = append(statGains, fmt.Sprintf("VIT +%d", statPoints))
+		default:
+			continue
 		}
+		statGains = append(statGains, AttributeGain{Attribute: attr, Points: statPoints})
 	}
 	return statGains
 }
@@ -1187,3 +1187,203 @@ func indexOf(s, substr string) int {
 func containsSubstring(s, substr string) bool {
 	return indexOf(s, substr) >= 0
 }
+
+func TestReportActivity_ShowsParsedShareWorkout(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report), dailyCounts: make(map[string]int)}
+	uc := usecase.NewReportActivityUsecase(repo)
+
+	message := "/lapor\nAfternoon Run\nDistance 5.21 km\nTime 28m 51s\nCalories 345 Cal\nhttps://strava.app.link/AbC123"
+	msg, err := uc.ExecuteWithMessage(context.Background(), "user1", "Alice", message, domain.ParseWorkout(message))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"*Afternoon Run Detail:*", "📱 Strava", "📏 5.21 km", "⏱️ 28 menit", "🔥 345 kcal"} {
+		if !containsSubstring(msg, want) {
+			t.Errorf("Expected message to contain %q, got %q", want, msg)
+		}
+	}
+	if containsSubstring(msg, "📏 Tercatat:") {
+		t.Errorf("Expected the parsed workout to replace the free-text summary, got %q", msg)
+	}
+}
+
+func TestReportActivity_WorkoutTimeEndsItsLine(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report), dailyCounts: make(map[string]int)}
+	uc := usecase.NewReportActivityUsecase(repo)
+	workout := &domain.Workout{Source: domain.WorkoutSourceGarmin, Title: "Evening Ride", Time: "1h 5min", Calories: 420}
+
+	msg, err := uc.ExecuteWithMessage(context.Background(), "user1", "Alice", "/lapor sepeda", workout)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "⏱️ Time: 1h 5min\n📱 " + domain.WorkoutSourceName(domain.WorkoutSourceGarmin)
+	if !containsSubstring(msg, want) {
+		t.Errorf("Expected message to contain %q, got %q", want, msg)
+	}
+}
+
+func TestExecuteFromSource_UsesActivityDate(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report), dailyCounts: make(map[string]int)}
+	uc := usecase.NewReportActivityUsecase(repo)
+	ctx := context.Background()
+	workout := &domain.Workout{
+		Source:          domain.WorkoutSourceStrava,
+		Title:           "Morning Run",
+		ActivityType:    domain.ActivityTypeRun,
+		DistanceMeters:  5000,
+		DurationSeconds: 1650,
+	}
+
+	old := domain.GetToday(time.Now()).AddDate(0, 0, -5)
+	msg, eventKey, err := uc.ExecuteFromSource(ctx, "user1", "Alice", "strava", "", workout, old)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if eventKey != "" || repo.reports["user1"] != nil {
+		t.Fatalf("activity from 5 days ago must not be reported, got key %q and report %+v", eventKey, repo.reports["user1"])
+	}
+	if !containsSubstring(msg, "terlalu lama") {
+		t.Errorf("Expected a too-old reply, got '%s'", msg)
+	}
+
+	msg, eventKey, err = uc.ExecuteFromSource(ctx, "user1", "Alice", "strava", "", workout, domain.GetToday(time.Now()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if eventKey == "" || repo.reports["user1"] == nil {
+		t.Fatalf("today's activity should be reported, got key %q", eventKey)
+	}
+	if !containsSubstring(msg, "5:30 /km") {
+		t.Errorf("Expected the reply to show the pace, got '%s'", msg)
+	}
+}
+
+func TestReportToday_ReturnsOutcome(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report), dailyCounts: make(map[string]int)}
+	uc := usecase.NewReportActivityUsecase(repo)
+	ctx := context.Background()
+
+	first, err := uc.ReportToday(ctx, "user1", "Alice", "lari 5 km", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !first.Accepted || first.Rejection != "" || first.EventKey == "" {
+		t.Fatalf("first report should be accepted, got %+v", first)
+	}
+	points := first.Points
+	if points.Base != 10 || points.SeasonFirstBonus != 5 || points.RepeatHalved || points.Report != 15 {
+		t.Fatalf("unexpected first report breakdown: %+v", points)
+	}
+	if points.Total != points.Report+points.Badges || first.Report.TotalPoints != points.Total {
+		t.Fatalf("total should add the report and its badges, got %+v (lifetime %d)", points, first.Report.TotalPoints)
+	}
+
+	second, err := uc.ReportToday(ctx, "user1", "Alice", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !second.Points.RepeatHalved || second.Points.Report != 5 || second.DailyCount != 1 {
+		t.Fatalf("repeat report should earn half, got %+v", second)
+	}
+	if !strings.Contains(usecase.RenderReportOutcome(second), "laporan ke-2 hari ini") {
+		t.Fatalf("unexpected repeat reply: %s", usecase.RenderReportOutcome(second))
+	}
+
+	if _, err := uc.ReportToday(ctx, "user1", "Alice", "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	limited, err := uc.ReportToday(ctx, "user1", "Alice", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if limited.Accepted || limited.Rejection != usecase.ReportRejectedDailyLimit || limited.DailyLimit != usecase.MaxDailyRegularReports {
+		t.Fatalf("fourth report should hit the daily limit, got %+v", limited)
+	}
+	if !strings.Contains(usecase.RenderReportOutcome(limited), "batas laporan utama 3x") {
+		t.Fatalf("unexpected limit reply: %s", usecase.RenderReportOutcome(limited))
+	}
+
+	late, err := uc.ReportYesterday(ctx, "user1", "Alice", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if late.Accepted || late.Rejection != usecase.ReportRejectedAlreadyToday || !late.Late {
+		t.Fatalf("yesterday's report after today's should be rejected, got %+v", late)
+	}
+
+	old := domain.GetToday(time.Now()).AddDate(0, 0, -5)
+	tooOld, err := uc.ReportFromSource(ctx, "user1", "Alice", "strava", "", nil, old)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tooOld.Rejection != usecase.ReportRejectedTooOld || !tooOld.ActivityDate.Equal(old) {
+		t.Fatalf("old activity should be rejected as too old, got %+v", tooOld)
+	}
+}
+
+func TestGrantRaidReward_FromRaidRecorderDoesNotDeadlock(t *testing.T) {
+	repo := &mockRepo{reports: make(map[string]*domain.Report), dailyCounts: make(map[string]int)}
+	uc := usecase.NewReportActivityUsecase(repo)
+	ctx := context.Background()
+
+	// The finishing blow rewards the finisher while their report still
+	// holds the user lock.
+	uc.SetRaidRecorder(func(ctx context.Context, userID string, points int, attr domain.AttributeType, eventKey string, now time.Time) string {
+		if _, err := uc.GrantRaidReward(ctx, userID, "boss:reward:"+userID, 15, now); err != nil {
+			t.Errorf("GrantRaidReward failed: %v", err)
+		}
+		return ""
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := uc.ExecuteWithMessage(ctx, "user1", "Alice", "lari pagi", nil); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("report with a raid reward deadlocked on the user lock")
+	}
+
+	before := repo.reports["user1"].TotalPoints
+	if _, err := uc.GrantRaidReward(ctx, "user1", "boss:reward:user1", 15, time.Now()); err != nil {
+		t.Fatalf("GrantRaidReward failed: %v", err)
+	}
+	if got := repo.reports["user1"].TotalPoints; got != before+15 {
+		t.Fatalf("TotalPoints = %d, want %d", got, before+15)
+	}
+}
+
+func TestImportPastActivity_ReimportKeepsItsEvent(t *testing.T) {
+	repo := newUserReportRepo()
+	repo.reports["628111"] = &domain.Report{UserID: "628111", Name: "Budi"}
+	uc := usecase.NewReportActivityUsecase(repo)
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	day := time.Date(2026, time.October, 10, 0, 0, 0, 0, time.UTC)
+
+	first, err := uc.ImportPastActivity(ctx, "628111", "Budi", "strava", "101", nil, day, day.Add(6*time.Hour), now)
+	if err != nil || first == "" {
+		t.Fatalf("first import = %q, %v", first, err)
+	}
+	// Another activity that day halves the points a repeat would score.
+	if _, err := uc.ImportPastActivity(ctx, "628111", "Budi", "strava", "102", nil, day, day.Add(9*time.Hour), now); err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+	points := repo.reports["628111"].TotalPoints
+
+	again, err := uc.ImportPastActivity(ctx, "628111", "Budi", "strava", "101", nil, day, day.Add(6*time.Hour), now)
+	if err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	if again != first {
+		t.Fatalf("re-importing should return the same event key, got %q want %q", again, first)
+	}
+	if len(repo.events) != 2 || repo.reports["628111"].TotalPoints != points {
+		t.Fatalf("re-importing must record nothing new, got %d events and %d points (want 2, %d)", len(repo.events), repo.reports["628111"].TotalPoints, points)
+	}
+}
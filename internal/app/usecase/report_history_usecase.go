@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const (
+	DefaultReportHistoryLimit = 20
+	MaxReportHistoryLimit     = 100
+	// riwayatEntries is how many reports /riwayat lists.
+	riwayatEntries = 10
+)
+
+var (
+	ErrReportHistoryUnavailable = errors.New("report history is not supported by this repository")
+	ErrReportHistoryInvalid     = errors.New("invalid report history query")
+)
+
+// ReportHistoryQuery selects a page of a user's report history. Before is
+// the NextCursor of the previous page; empty starts from the newest report.
+// Season zero covers every season and an empty Kind covers both kinds.
+type ReportHistoryQuery struct {
+	Before string
+	Limit  int
+	Kind   string
+	Season int
+}
+
+// ReportHistoryEntry is one report as it was filed. Cancelled reports stay
+// in the history with CancelledAt set.
+type ReportHistoryEntry struct {
+	EventID      string          `json:"event_id"`
+	Kind         string          `json:"kind"`
+	Season       int             `json:"season"`
+	ActivityDate string          `json:"activity_date"`
+	OccurredAt   time.Time       `json:"occurred_at"`
+	Text         string          `json:"text"`
+	Source       string          `json:"source"`
+	Points       int             `json:"points"`
+	SideQuests   int             `json:"side_quests"`
+	Attributes   []AttributeGain `json:"attributes"`
+	Cancelled    bool            `json:"cancelled"`
+	CancelledAt  time.Time       `json:"cancelled_at,omitzero"`
+}
+
+// ReportHistoryPage is a page of report history, newest first. NextCursor
+// is empty on the last page.
+type ReportHistoryPage struct {
+	Entries    []ReportHistoryEntry `json:"entries"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// ReportHistoryUsecase lists what a user reported, from the report event
+// ledger. Reports filed before the ledger started are not in it.
+type ReportHistoryUsecase struct {
+	repo domain.ReportRepository
+}
+
+func NewReportHistoryUsecase(repo domain.ReportRepository) *ReportHistoryUsecase {
+	return &ReportHistoryUsecase{repo: repo}
+}
+
+// List returns a page of userID's report history.
+func (u *ReportHistoryUsecase) List(ctx context.Context, userID string, query ReportHistoryQuery) (ReportHistoryPage, error) {
+	history, ok := u.repo.(domain.ReportHistoryRepository)
+	if !ok {
+		return ReportHistoryPage{}, ErrReportHistoryUnavailable
+	}
+	switch query.Kind {
+	case "", domain.ActivityKindRegularReport, domain.ActivityKindSideQuest:
+	default:
+		return ReportHistoryPage{}, fmt.Errorf("%w: unknown kind %q", ErrReportHistoryInvalid, query.Kind)
+	}
+	if query.Season < 0 {
+		return ReportHistoryPage{}, fmt.Errorf("%w: season must not be negative", ErrReportHistoryInvalid)
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultReportHistoryLimit
+	}
+	limit = min(limit, MaxReportHistoryLimit)
+
+	eventQuery := domain.ReportEventQuery{
+		UserID:       userID,
+		Kind:         query.Kind,
+		SeasonNumber: query.Season,
+		Limit:        limit + 1, // one extra tells whether there is a next page
+	}
+	if query.Before != "" {
+		occurredAt, eventID, err := decodeHistoryCursor(query.Before)
+		if err != nil {
+			return ReportHistoryPage{}, err
+		}
+		eventQuery.BeforeOccurredAt = occurredAt
+		eventQuery.BeforeEventID = eventID
+	}
+
+	events, err := history.ListReportEvents(ctx, eventQuery)
+	if err != nil {
+		return ReportHistoryPage{}, err
+	}
+	page := ReportHistoryPage{Entries: []ReportHistoryEntry{}}
+	if len(events) > limit {
+		events = events[:limit]
+		last := events[len(events)-1]
+		page.NextCursor = encodeHistoryCursor(last.OccurredAt, last.EventID)
+	}
+	for _, event := range events {
+		page.Entries = append(page.Entries, reportHistoryEntry(event))
+	}
+	return page, nil
+}
+
+// Execute answers /riwayat with the user's latest reports.
+func (u *ReportHistoryUsecase) Execute(ctx context.Context, userID, name string) (string, error) {
+	page, err := u.List(ctx, userID, ReportHistoryQuery{Limit: riwayatEntries})
+	if errors.Is(err, ErrReportHistoryUnavailable) {
+		return "Riwayat laporan belum tersedia. 🙏", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(page.Entries) == 0 {
+		return fmt.Sprintf("Halo %s, belum ada laporan yang tercatat di riwayat. Yuk mulai dengan /lapor! 💪", name), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📜 *Riwayat Laporan - %s*\n", name))
+	sb.WriteString(fmt.Sprintf("%d laporan terakhir:\n", len(page.Entries)))
+	for i, entry := range page.Entries {
+		sb.WriteString("\n")
+		sb.WriteString(formatHistoryEntry(i+1, entry))
+	}
+	sb.WriteString("\n\n🌐 Riwayat lengkap ada di dashboard: https://lapor-bot.web.id/")
+	return sb.String(), nil
+}
+
+func reportHistoryEntry(event domain.ReportActivityEvent) ReportHistoryEntry {
+	entry := ReportHistoryEntry{
+		EventID:      event.EventID,
+		Kind:         event.Kind,
+		Season:       event.SeasonNumber,
+		ActivityDate: event.ActivityDate.Format(time.DateOnly),
+		OccurredAt:   event.OccurredAt,
+		Text:         event.ActivityText,
+		Source:       event.Source,
+		Points:       event.PointsDelta,
+		SideQuests:   event.SideQuestCountDelta,
+		Attributes:   []AttributeGain{},
+		Cancelled:    !event.CancelledAt.IsZero(),
+		CancelledAt:  event.CancelledAt,
+	}
+	var metadata reportEventMetadata
+	if err := json.Unmarshal([]byte(event.MetadataJSON), &metadata); err == nil {
+		entry.Attributes = append(entry.Attributes, metadata.Attributes...)
+	}
+	return entry
+}
+
+func formatHistoryEntry(n int, entry ReportHistoryEntry) string {
+	day, _ := time.Parse(time.DateOnly, entry.ActivityDate)
+	label := "📝 Laporan"
+	if entry.Kind == domain.ActivityKindSideQuest {
+		label = fmt.Sprintf("✨ Side quest (%d)", entry.SideQuests)
+	}
+	line := fmt.Sprintf("%d. %s • %s • +%d pts", n, day.Format("02 Jan 2006"), label, entry.Points)
+	for _, gain := range entry.Attributes {
+		line += " • " + gain.String()
+	}
+	if source := historySourceName(entry.Source); source != "" {
+		line += " • via " + source
+	}
+	if entry.Cancelled {
+		line += " • ❌ dibatalkan"
+	}
+	if text := strings.TrimSpace(entry.Text); text != "" {
+		line += "\n   " + text
+	}
+	return line
+}
+
+// historySourceName names where a report came from; WhatsApp, the usual
+// place, is left unnamed.
+func historySourceName(source string) string {
+	if id, ok := domain.IntegrationIDFromSource(source); ok {
+		return id
+	}
+	switch source {
+	case "", "whatsapp":
+		return ""
+	case stravaReportSource:
+		return "Strava"
+	case webReportSource:
+		return "web"
+	case workoutFileReportSource:
+		return "file workout"
+	default:
+		return source
+	}
+}
+
+func encodeHistoryCursor(occurredAt time.Time, eventID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(occurredAt.UTC().Format(time.RFC3339) + "|" + eventID))
+}
+
+func decodeHistoryCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("%w: malformed cursor", ErrReportHistoryInvalid)
+	}
+	occurredAt, eventID, ok := strings.Cut(string(raw), "|")
+	if !ok || eventID == "" {
+		return time.Time{}, "", fmt.Errorf("%w: malformed cursor", ErrReportHistoryInvalid)
+	}
+	at, err := time.Parse(time.RFC3339, occurredAt)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("%w: malformed cursor", ErrReportHistoryInvalid)
+	}
+	return at, eventID, nil
+}
@@ -0,0 +1,93 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// historyRepo serves report events newest first, like the sqlite ledger.
+type historyRepo struct {
+	*mockReportRepo
+	events []domain.ReportActivityEvent
+}
+
+func (r *historyRepo) ListReportEvents(ctx context.Context, query domain.ReportEventQuery) ([]domain.ReportActivityEvent, error) {
+	var out []domain.ReportActivityEvent
+	for _, event := range r.events {
+		if query.Kind != "" && event.Kind != query.Kind {
+			continue
+		}
+		if !query.BeforeOccurredAt.IsZero() && !event.OccurredAt.Before(query.BeforeOccurredAt) &&
+			!(event.OccurredAt.Equal(query.BeforeOccurredAt) && event.EventID < query.BeforeEventID) {
+			continue
+		}
+		if query.Limit > 0 && len(out) == query.Limit {
+			break
+		}
+		out = append(out, event)
+	}
+	return out, nil
+}
+
+func TestReportHistory_PagesNewestFirst(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 7, 0, 0, 0, time.UTC)
+	repo := &historyRepo{
+		mockReportRepo: &mockReportRepo{reports: make(map[string]*domain.Report)},
+		events: []domain.ReportActivityEvent{
+			{EventID: "c", Kind: domain.ActivityKindRegularReport, OccurredAt: now, ActivityDate: domain.GetToday(now), PointsDelta: 15, Source: "strava", ActivityText: "lari 5 km", MetadataJSON: `{"attributes":[{"attribute":"STA","points":1}]}`},
+			{EventID: "b", Kind: domain.ActivityKindSideQuest, OccurredAt: now.Add(-time.Hour), ActivityDate: domain.GetToday(now), PointsDelta: 3, SideQuestCountDelta: 1, Source: "whatsapp", MetadataJSON: "{}"},
+			{EventID: "a", Kind: domain.ActivityKindRegularReport, OccurredAt: now.AddDate(0, 0, -1), ActivityDate: domain.GetToday(now.AddDate(0, 0, -1)), PointsDelta: 10, Source: "whatsapp", MetadataJSON: "{}", CancelledAt: now},
+		},
+	}
+	uc := usecase.NewReportHistoryUsecase(repo)
+	ctx := context.Background()
+
+	first, err := uc.List(ctx, "user1", usecase.ReportHistoryQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.Entries) != 2 || first.Entries[0].EventID != "c" || first.NextCursor == "" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	if attrs := first.Entries[0].Attributes; len(attrs) != 1 || attrs[0].Attribute != domain.AttrSta || attrs[0].Points != 1 {
+		t.Fatalf("attributes should come from the event metadata, got %+v", attrs)
+	}
+
+	second, err := uc.List(ctx, "user1", usecase.ReportHistoryQuery{Limit: 2, Before: first.NextCursor})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.Entries) != 1 || second.Entries[0].EventID != "a" || !second.Entries[0].Cancelled || second.NextCursor != "" {
+		t.Fatalf("unexpected last page: %+v", second)
+	}
+
+	if _, err := uc.List(ctx, "user1", usecase.ReportHistoryQuery{Kind: "nap"}); !errors.Is(err, usecase.ErrReportHistoryInvalid) {
+		t.Fatalf("unknown kind should be invalid, got %v", err)
+	}
+	if _, err := uc.List(ctx, "user1", usecase.ReportHistoryQuery{Before: "not a cursor"}); !errors.Is(err, usecase.ErrReportHistoryInvalid) {
+		t.Fatalf("malformed cursor should be invalid, got %v", err)
+	}
+
+	msg, err := uc.Execute(ctx, "user1", "Alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Riwayat Laporan - Alice", "+15 pts • STA +1 • via Strava", "lari 5 km", "Side quest (1)", "❌ dibatalkan"} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("/riwayat reply missing %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestReportHistory_Unavailable(t *testing.T) {
+	uc := usecase.NewReportHistoryUsecase(&mockReportRepo{reports: make(map[string]*domain.Report)})
+	if _, err := uc.List(context.Background(), "user1", usecase.ReportHistoryQuery{}); !errors.Is(err, usecase.ErrReportHistoryUnavailable) {
+		t.Fatalf("expected ErrReportHistoryUnavailable, got %v", err)
+	}
+}
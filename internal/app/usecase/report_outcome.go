@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// Reasons a report is turned down. They are stable codes for API clients
+// and webhooks; the bot's wording for them lives in RenderReportOutcome.
+const (
+	ReportRejectedDailyLimit       = "daily_limit"
+	ReportRejectedAlreadyToday     = "already_reported_today"
+	ReportRejectedReportedRecently = "reported_recently"
+	ReportRejectedTooOld           = "too_old"
+)
+
+// ReportPoints is how a report's points add up. Report is what the report
+// itself earned, after a repeat report of the day was halved; Badges is what
+// the badges it unlocked added on top.
+type ReportPoints struct {
+	Base              int  `json:"base"`
+	WeeklyStreakBonus int  `json:"weekly_streak_bonus"`
+	DailyStreakBonus  int  `json:"daily_streak_bonus"`
+	SeasonFirstBonus  int  `json:"season_first_bonus"`
+	RepeatHalved      bool `json:"repeat_halved"`
+	Report            int  `json:"report"`
+	Badges            int  `json:"badges"`
+	Total             int  `json:"total"`
+}
+
+// AttributeGain is an attribute point awarded by a report.
+type AttributeGain struct {
+	Attribute domain.AttributeType `json:"attribute"`
+	Points    int                  `json:"points"`
+}
+
+func (g AttributeGain) String() string {
+	return fmt.Sprintf("%s +%d", g.Attribute, g.Points)
+}
+
+// ReportOutcome is what a report did. A rejected outcome only carries
+// Rejection and the fields that identify the attempt: UserID, Name, Kind,
+// Late, ActivityDate, DailyCount and DailyLimit.
+type ReportOutcome struct {
+	Accepted  bool
+	Rejection string
+
+	UserID       string
+	Name         string
+	Kind         string
+	Source       string
+	Late         bool // reported the day after, via /lapor-kemarin
+	ActivityDate time.Time
+	OccurredAt   time.Time
+	EventKey     string
+	// DailyCount is how many reports of Kind the day had before this one.
+	DailyCount int
+	DailyLimit int
+	SideQuests int
+
+	Points         ReportPoints
+	AttributeGains []AttributeGain
+	Badges         []domain.Achievement
+	ComebackBadges []domain.ComebackAchievement
+	// Report is the user's report after this one was applied.
+	Report   *domain.Report
+	OldLevel int
+	OldTier  domain.Level
+	OldRank  domain.Rank
+
+	// BrokenStreak is the weekly streak that had lapsed before this report,
+	// or zero when the streak carried on.
+	BrokenStreak   int
+	Comeback       bool
+	NewBestStreak  bool
+	FreezeUsed     bool
+	FreezeAwarded  bool
+	GoalCompleted  bool
+	GoalsCompleted int
+
+	Workout *domain.Workout
+	Metrics domain.ActivityMetrics
+	// RaidLine, LiftLine and RecordLine are what the raid, lifting and
+	// personal record hooks had to say about the report.
+	RaidLine   string
+	LiftLine   string
+	RecordLine string
+}
+
+// LeveledUp reports whether the report moved the user up a numeric level.
+func (o ReportOutcome) LeveledUp() bool {
+	return o.Report != nil && o.Report.Level > o.OldLevel
+}
+
+// NewTier returns the lifetime tier after the report.
+func (o ReportOutcome) NewTier() domain.Level {
+	if o.Report == nil {
+		return o.OldTier
+	}
+	return domain.GetLevel(o.Report.TotalPoints)
+}
+
+// NewRank returns the season rank after the report.
+func (o ReportOutcome) NewRank() domain.Rank {
+	if o.Report == nil {
+		return o.OldRank
+	}
+	return domain.GetSeasonRank(o.Report.SeasonalPoints)
+}
+
+func rejectedReport(reason, userID, name, kind string, late bool, activityDate time.Time, dailyCount, dailyLimit int) ReportOutcome {
+	return ReportOutcome{
+		Rejection:    reason,
+		UserID:       userID,
+		Name:         name,
+		Kind:         kind,
+		Late:         late,
+		ActivityDate: activityDate,
+		DailyCount:   dailyCount,
+		DailyLimit:   dailyLimit,
+	}
+}
+
+// RenderReportOutcome writes the bot's WhatsApp reply for a report.
+func RenderReportOutcome(o ReportOutcome) string {
+	if !o.Accepted {
+		return renderReportRejection(o)
+	}
+
+	report := o.Report
+	name := o.Name
+	isSideQuest := o.Kind == domain.ActivityKindSideQuest
+	isFullReport := !isSideQuest && !o.Points.RepeatHalved
+	attributesActive := hasSelectedJob(report)
+	var response string
+
+	if o.Comeback {
+		response = fmt.Sprintf("🎉 WELCOME BACK, %s! 🎉\n", name)
+		response += fmt.Sprintf("Kamu kembali setelah %d hari absen. Itu butuh keberanian! 💪\n", report.InactiveDays)
+		response += "Streak kamu direset, tapi totalmu tetap tersimpan.\n"
+		if report.JobClass != "" {
+			response += fmt.Sprintf("🧭 Job: %s\n", domain.FormatJobClass(report.JobClass))
+		}
+		response += fmt.Sprintf("\n📊 Level: Lv.%d • %s (Total: %d pts)\n", report.Level, domain.FormatLevel(report.TotalPoints), report.TotalPoints)
+		response += fmt.Sprintf("📅 Total hari aktif: %d\n", report.ActivityCount)
+
+		nextComebackAch := nextComebackTarget(report)
+		if nextComebackAch != nil {
+			response += fmt.Sprintf("\n🔥 Comeback Challenge dimulai! Raih %d minggu berturut-turut untuk unlock \"%s\"!", nextComebackAch.MinComebackStreak, nextComebackAch.Name)
+		} else {
+			response += "\n🔥 Comeback Challenge dimulai! Ayo bangun streak-mu kembali!"
+		}
+	} else {
+		cyclePrefix := ""
+		if report.CenturionCycles > 0 {
+			cyclePrefix = fmt.Sprintf("[C%d] ", report.CenturionCycles+1)
+		}
+
+		// Only the final point result is shown; the per-component breakdown
+		// (base / weekly / daily / seasonal / repeat ½) is intentionally hidden
+		// from the user-facing message for both /lapor and /lapor sidequest.
+		expBreakdown := fmt.Sprintf("⭐ +%d pts", o.Points.Report)
+		if o.Late {
+			expBreakdown += " (lapor kemarin)"
+		}
+		if len(o.AttributeGains) > 0 {
+			gains := make([]string, 0, len(o.AttributeGains))
+			for _, gain := range o.AttributeGains {
+				gains = append(gains, gain.String())
+			}
+			expBreakdown += fmt.Sprintf("\n💪 Attributes: %s", strings.Join(gains, ", "))
+		}
+
+		switch {
+		case isSideQuest:
+			response = fmt.Sprintf("Side quest diterima, %s%s menyelesaikan %d side quest. Ini bonus terpisah dari 3 slot laporan utama harian. 🔥\n%s",
+				cyclePrefix, name, o.SideQuests, expBreakdown)
+		case o.Late:
+			response = fmt.Sprintf("Laporan kemarin diterima, %s%s sudah berkeringat %d hari. Lanjutkan 🔥 (streak %d minggu)\n%s",
+				cyclePrefix, name, report.ActivityCount, report.Streak, expBreakdown)
+		case o.Points.RepeatHalved:
+			response = fmt.Sprintf("Laporan diterima (laporan ke-%d hari ini), %s%s sudah berkeringat %d hari. Lanjutkan 🔥 (streak %d minggu)\n%s",
+				o.DailyCount+1, cyclePrefix, name, report.ActivityCount, report.Streak, expBreakdown)
+		default:
+			response = fmt.Sprintf("Laporan diterima, %s%s sudah berkeringat %d hari. Lanjutkan 🔥 (streak %d minggu)\n%s",
+				cyclePrefix, name, report.ActivityCount, report.Streak, expBreakdown)
+		}
+		if report.JobClass != "" {
+			response += fmt.Sprintf("\n🧭 Job: %s", domain.FormatJobClass(report.JobClass))
+		}
+	}
+	if !attributesActive {
+		response += "\n\n" + formatJobSetupNotice()
+	}
+
+	if isFullReport && report.ActivityCount == 1 && report.CenturionCycles > 0 && !o.Comeback {
+		response = "🔥 *ERA BARU DIMULAI!* 🔥\n" +
+			fmt.Sprintf("Selamat %s, Anda telah menyelesaikan 100 hari sebelumnya.\n", name) +
+			fmt.Sprintf("Sekarang Anda memulai *Siklus %d (Hari ke-1)*. Terus jaga konsistensi! 💪\n\n", report.CenturionCycles+1) +
+			response
+	} else if isFullReport && report.ActivityCount == 100 {
+		response = "🎊 *LUAR BIASA!* 🎊\n" +
+			fmt.Sprintf("Selamat %s, Anda mencapai *HARI KE-100*! 💯\n", name) +
+			"Anda sekarang resmi menyandang gelar *CENTURION 🛡️*. Nama Anda telah diabdikan dalam jajaran legenda grup!\n\n" +
+			response
+	}
+
+	if o.Workout != nil {
+		response += "\n" + formatWorkout(o.Workout)
+	}
+	if o.LiftLine != "" {
+		response += "\n" + o.LiftLine
+	}
+	if o.RecordLine != "" {
+		response += "\n\n" + o.RecordLine
+	}
+	if summary := domain.FormatActivityMetrics(o.Metrics); summary != "" && (o.Workout == nil || o.Workout.Source == "") {
+		response += "\n📏 Tercatat: " + summary
+	}
+
+	if o.FreezeUsed {
+		response += fmt.Sprintf("\n\n❄️ *Streak Freeze terpakai!* Streak kamu aman. (Sisa freeze: %d)", report.StreakFreezes)
+	}
+
+	if o.NewBestStreak {
+		response += fmt.Sprintf("\n\n🏆 New Personal Best Streak: %d minggu!", report.MaxStreak)
+	}
+
+	if o.GoalCompleted {
+		response += "\n\n🎯 *Goal minggu ini tercapai!* Konsistensi harianmu sudah sesuai target. Mantap, champ! 🏆"
+	}
+
+	if o.RaidLine != "" {
+		response += "\n\n" + o.RaidLine
+	}
+
+	if o.LeveledUp() {
+		response += fmt.Sprintf("\n\n⚔️ *LEVEL UP!* Lv.%d → Lv.%d", o.OldLevel, report.Level)
+	}
+	// A catch-up report for yesterday doesn't announce tier or rank changes;
+	// they show up with the next /lapor.
+	if !o.Late {
+		if tier := o.NewTier(); tier.Tier > o.OldTier.Tier {
+			response += fmt.Sprintf("\n🎖️ *TIER LIFETIME UP!* %s %s → %s %s", o.OldTier.Name, o.OldTier.Icon, tier.Name, tier.Icon)
+		}
+		if rank := o.NewRank(); rank.Tier > o.OldRank.Tier {
+			response += fmt.Sprintf("\n🏹 *RANK SEASON UP!* %s %s → %s %s", o.OldRank.Name, o.OldRank.Icon, rank.Name, rank.Icon)
+		}
+	}
+
+	if len(o.Badges)+len(o.ComebackBadges) > 0 {
+		response += "\n\n🏅 *Badge baru:*"
+		for _, ach := range o.Badges {
+			response += fmt.Sprintf("\n%s %s (+%d pts)", ach.DisplayEmoji, ach.Name, ach.Points)
+		}
+		for _, ach := range o.ComebackBadges {
+			response += fmt.Sprintf("\n%s %s (+%d pts)", ach.DisplayEmoji, ach.Name, ach.Points)
+		}
+
+		if o.FreezeAwarded {
+			response += fmt.Sprintf("\n\n❄️ Bonus: +1 Streak Freeze! (Total: %d)", report.StreakFreezes)
+		}
+
+		response += "\nDetail badge & cerita unlock: https://lapor-bot.web.id/"
+	}
+
+	response += fmt.Sprintf("\n\n💬 _\"%s\"_", RandomQuote())
+
+	if o.Points.Total > 0 {
+		response += fmt.Sprintf("\n\n💰 Total: +%d points (Lifetime: %d | Season: %d)", o.Points.Total, report.TotalPoints, report.SeasonalPoints)
+	}
+
+	response += fmt.Sprintf("\n%s", domain.FormatNumericLevelProgressBar(report.TotalPoints))
+	response += fmt.Sprintf("\n%s", domain.FormatProgressBar(report.TotalPoints))
+	if attributesActive {
+		response += fmt.Sprintf("\n\n%s", formatCurrentAttributes(report))
+	}
+
+	return response
+}
+
+func renderReportRejection(o ReportOutcome) string {
+	switch o.Rejection {
+	case ReportRejectedTooOld:
+		return fmt.Sprintf("Aktivitas tanggal %s sudah terlalu lama untuk dilaporkan. Laporan hanya bisa untuk hari ini atau kemarin. 🙏", o.ActivityDate.Format("02 Jan 2006"))
+	case ReportRejectedAlreadyToday:
+		return fmt.Sprintf("%s sudah laporan hari ini. Gunakan /lapor untuk laporan tambahan hari ini. 💪", o.Name)
+	case ReportRejectedReportedRecently:
+		return fmt.Sprintf("%s sudah laporan dalam 2 hari terakhir. Tidak perlu lapor ulang untuk hari kemarin. 🙏", o.Name)
+	}
+
+	if o.Late {
+		return fmt.Sprintf("%s sudah mencapai batas maksimal %dx laporan untuk hari kemarin. 🙏", o.Name, o.DailyLimit)
+	}
+	isSideQuest := o.Kind == domain.ActivityKindSideQuest
+	label := "laporan utama"
+	if isSideQuest {
+		label = "side quest"
+	}
+	msg := fmt.Sprintf("%s sudah mencapai batas %s %dx hari ini. Batas laporan utama dan side quest terpisah: masing-masing %d kali per hari.", o.Name, label, o.DailyLimit, MaxDailyRegularReports)
+	if !isSideQuest {
+		msg += " Kalau tadi salah input, pakai /cancel untuk hapus laporan terakhir atau /cancel-all untuk hapus semua laporan hari ini."
+	}
+	msg += " 🙏"
+	msg += fmt.Sprintf("\n\n💬 _\"%s\"_", RandomQuote())
+	return msg
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/render"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/types"
@@ -17,13 +19,27 @@ import (
 var SessionResetMonths = []time.Month{time.January, time.May, time.September}
 
 type ResetSessionUsecase struct {
-	repo domain.ReportRepository
+	repo           domain.ReportRepository
+	eventPublisher BotEventPublisher
+	sender         GroupCardSender
 }
 
 func NewResetSessionUsecase(repo domain.ReportRepository) *ResetSessionUsecase {
 	return &ResetSessionUsecase{repo: repo}
 }
 
+// SetEventPublisher sets the hook that is told when a new season starts.
+func (uc *ResetSessionUsecase) SetEventPublisher(fn BotEventPublisher) {
+	uc.eventPublisher = fn
+}
+
+// SetSender routes the reset posts through the WhatsApp send queue, which
+// also lets the finished season's podium go out as an image. Without a
+// sender only the announcement is sent, directly through the client.
+func (uc *ResetSessionUsecase) SetSender(sender GroupCardSender) {
+	uc.sender = sender
+}
+
 // GetCurrentSessionInfo returns the current season number and its start date.
 // Seasons cycle every 4 months. This bot's public season counter starts from
 // the current launch season so members see Season 1 first, then Season 2 after
@@ -102,24 +118,60 @@ func GetNextResetTime(now time.Time) time.Time {
 }
 
 // Execute resets seasonal report data and sends an announcement to the group.
+// When a previous season ends, its podium is drawn before the reset and
+// posted ahead of the announcement.
 func (uc *ResetSessionUsecase) Execute(ctx context.Context, client *whatsmeow.Client, groupID string, sessionNumber int) error {
 	log.Printf("[SESSION RESET] Starting Season %d reset — clearing seasonal data...", sessionNumber)
 
+	var podium *GroupCard
+	if sessionNumber > 1 && uc.sender != nil {
+		card, err := uc.seasonPodium(ctx, sessionNumber-1)
+		if err != nil {
+			log.Printf("[SESSION RESET] Failed to build Season %d podium: %v", sessionNumber-1, err)
+		} else {
+			podium = card
+		}
+	}
+
 	// Reset all reports in the database
 	if err := uc.repo.ResetAllReports(ctx); err != nil {
 		return fmt.Errorf("failed to reset all reports: %w", err)
 	}
 
 	log.Printf("[SESSION RESET] Seasonal data has been reset for Season %d!", sessionNumber)
+	if uc.eventPublisher != nil {
+		uc.eventPublisher(ctx, domain.BotEvent{
+			Type:       domain.BotEventSeasonReset,
+			OccurredAt: time.Now(),
+			Data:       map[string]any{"season": sessionNumber},
+		})
+	}
 
-	// Send announcement to the group
-	if groupID != "" && client != nil && client.IsConnected() {
-		announcement := buildSeasonResetAnnouncement(sessionNumber)
+	if groupID == "" {
+		return nil
+	}
+	announcement := buildSeasonResetAnnouncement(sessionNumber)
+	targetJID, _ := types.ParseJID(groupID)
+	msg := &waE2E.Message{
+		Conversation: &announcement,
+	}
 
-		targetJID, _ := types.ParseJID(groupID)
-		msg := &waE2E.Message{
-			Conversation: &announcement,
+	// Send announcement to the group
+	if uc.sender != nil {
+		if podium != nil {
+			if err := uc.sender.SendImageHighPriority(ctx, targetJID, podium.Image, podium.Caption, podium.Text); err != nil {
+				log.Printf("[SESSION RESET] Failed to send Season %d podium: %v", sessionNumber-1, err)
+			}
 		}
+		if err := uc.sender.SendHighPriority(ctx, targetJID, msg); err != nil {
+			log.Printf("[SESSION RESET] Failed to send announcement: %v", err)
+			return fmt.Errorf("reset succeeded but failed to send announcement: %w", err)
+		}
+		log.Printf("[SESSION RESET] Season %d announcement sent to group!", sessionNumber)
+		return nil
+	}
+
+	if client != nil && client.IsConnected() {
 		_, err := client.SendMessage(ctx, targetJID, msg)
 		if err != nil {
 			log.Printf("[SESSION RESET] Failed to send announcement: %v", err)
@@ -131,6 +183,49 @@ func (uc *ResetSessionUsecase) Execute(ctx context.Context, client *whatsmeow.Cl
 	return nil
 }
 
+// seasonPodium draws the top three hunters of the season that is about to
+// be reset. It returns nil when nobody was active that season.
+func (uc *ResetSessionUsecase) seasonPodium(ctx context.Context, season int) (*GroupCard, error) {
+	reports, err := uc.repo.GetAllReports(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reports = domain.DedupReportsByUserID(reports, domain.SortBySeasonRank)
+	domain.SortReports(reports, domain.SortBySeasonRank)
+	active := domain.FilterReports(reports, domain.HasSeasonActivity)
+	if len(active) == 0 {
+		return nil, nil
+	}
+	top := active[:min(len(active), 3)]
+
+	medals := []string{"🥇", "🥈", "🥉"}
+	card := render.PodiumCard{
+		Title:    fmt.Sprintf("Season %d Champions", season),
+		Subtitle: fmt.Sprintf("%d hunter aktif season ini", len(active)),
+		Footer:   groupCardSite,
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🏆 *PODIUM SEASON %d* 🏆\n\n", season))
+	for i, r := range top {
+		rankName := domain.GetSeasonRank(r.SeasonalPoints).Name
+		card.Places = append(card.Places, render.PodiumPlace{
+			Name:   r.Name,
+			Detail: fmt.Sprintf("%s, %d hari", rankName, r.SeasonalActivityCount),
+			Value:  formatCardPoints(r.SeasonalPoints),
+		})
+		sb.WriteString(fmt.Sprintf("%s %s — %d pts (%s, %d hari)\n", medals[i], r.Name, r.SeasonalPoints, rankName, r.SeasonalActivityCount))
+	}
+	sb.WriteString("\nSelamat untuk para juara! 👏")
+
+	return &GroupCard{
+		Image: renderGroupCard("season podium", func() ([]byte, error) {
+			return render.RenderPodium(card, render.SeasonTheme(season))
+		}),
+		Caption: fmt.Sprintf("🏆 *Podium Season %d* — selamat untuk para juara! 👏", season),
+		Text:    sb.String(),
+	}, nil
+}
+
 func buildSeasonResetAnnouncement(sessionNumber int) string {
 	seasonTransition := "Season perdana telah resmi dimulai. Semua hunter mulai berburu dari titik yang sama! 🎉"
 	if sessionNumber > 1 {
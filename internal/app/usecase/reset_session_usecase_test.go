@@ -1,9 +1,14 @@
 package usecase
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
 )
 
 func TestGetCurrentSessionInfo_CurrentLaunchSeasonStartsAtOne(t *testing.T) {
@@ -57,3 +62,78 @@ func TestBuildSeasonResetAnnouncement_DoesNotMentionSeasonZero(t *testing.T) {
 		t.Fatalf("expected season 1 start announcement, got %s", announcement)
 	}
 }
+
+type resetRepoStub struct {
+	domain.ReportRepository
+	reports []*domain.Report
+	reset   bool
+}
+
+func (r *resetRepoStub) GetAllReports(ctx context.Context) ([]*domain.Report, error) {
+	return r.reports, nil
+}
+
+func (r *resetRepoStub) ResetAllReports(ctx context.Context) error {
+	r.reset = true
+	return nil
+}
+
+// groupSenderStub records what would have been queued, in order.
+type groupSenderStub struct {
+	sent []string
+	imgs [][]byte
+}
+
+func (s *groupSenderStub) SendHighPriority(ctx context.Context, target types.JID, msg *waE2E.Message) error {
+	s.sent = append(s.sent, msg.GetConversation())
+	return nil
+}
+
+func (s *groupSenderStub) SendImageHighPriority(ctx context.Context, target types.JID, png []byte, caption, fallback string) error {
+	s.sent = append(s.sent, caption)
+	s.imgs = append(s.imgs, png)
+	return nil
+}
+
+func TestResetSession_PostsPodiumBeforeAnnouncement(t *testing.T) {
+	repo := &resetRepoStub{reports: []*domain.Report{
+		{UserID: "628111", Name: "Alice", SeasonalPoints: 120, SeasonalActivityCount: 12},
+		{UserID: "628222", Name: "Bob", SeasonalPoints: 90, SeasonalActivityCount: 9},
+		{UserID: "628333", Name: "Carol", SeasonalPoints: 60, SeasonalActivityCount: 6},
+		{UserID: "628444", Name: "Dave", SeasonalPoints: 30, SeasonalActivityCount: 3},
+		{UserID: "628555", Name: "Eve"},
+	}}
+	sender := &groupSenderStub{}
+	uc := NewResetSessionUsecase(repo)
+	uc.SetSender(sender)
+
+	if err := uc.Execute(context.Background(), nil, "120363000000000000@g.us", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.reset {
+		t.Fatal("season data should be reset")
+	}
+	if len(sender.sent) != 2 {
+		t.Fatalf("expected the podium and the announcement, got %q", sender.sent)
+	}
+	if !strings.Contains(sender.sent[0], "Podium Season 1") || len(sender.imgs[0]) == 0 {
+		t.Errorf("the season 1 podium should go first as an image, got %q", sender.sent[0])
+	}
+	if !strings.Contains(sender.sent[1], "SEASON 2 TELAH DIMULAI") {
+		t.Errorf("the announcement should follow the podium, got %q", sender.sent[1])
+	}
+}
+
+func TestResetSession_SkipsPodiumWithoutActivity(t *testing.T) {
+	repo := &resetRepoStub{reports: []*domain.Report{{UserID: "628555", Name: "Eve"}}}
+	sender := &groupSenderStub{}
+	uc := NewResetSessionUsecase(repo)
+	uc.SetSender(sender)
+
+	if err := uc.Execute(context.Background(), nil, "120363000000000000@g.us", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 || !strings.Contains(sender.sent[0], "SEASON 2 TELAH DIMULAI") {
+		t.Fatalf("only the announcement should be sent, got %q", sender.sent)
+	}
+}
@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// refreshReuseGrace is how long the refresh token a rotation replaced keeps
+// working. Two tabs sharing one token can both refresh when their access
+// tokens expire; the slower one must not sign the device out.
+const refreshReuseGrace = 30 * time.Second
+
+var (
+	ErrSessionUnavailable = errors.New("web sessions are not supported by this repository")
+	// ErrSessionInvalid covers unknown, expired, revoked and already
+	// rotated refresh tokens.
+	ErrSessionInvalid  = errors.New("refresh token is invalid")
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// SessionToken is a freshly issued refresh token and the session it belongs
+// to. The token is only ever shown once.
+type SessionToken struct {
+	Session      domain.WebSession
+	RefreshToken string
+}
+
+// SessionUsecase keeps track of signed-in dashboard devices. Each holds a
+// refresh token that is swapped for a new one on every refresh; presenting
+// a token that was swapped longer than refreshReuseGrace ago means it leaked,
+// and the session is revoked.
+type SessionUsecase struct {
+	repo domain.ReportRepository
+	ttl  time.Duration
+}
+
+// NewSessionUsecase keeps an unused session alive for ttl; every refresh
+// extends it by ttl again.
+func NewSessionUsecase(repo domain.ReportRepository, ttl time.Duration) *SessionUsecase {
+	return &SessionUsecase{repo: repo, ttl: ttl}
+}
+
+func (uc *SessionUsecase) sessions() (domain.SessionRepository, error) {
+	sessions, ok := uc.repo.(domain.SessionRepository)
+	if !ok {
+		return nil, ErrSessionUnavailable
+	}
+	return sessions, nil
+}
+
+// Start opens a session for a user who just logged in.
+func (uc *SessionUsecase) Start(ctx context.Context, userID string, client LoginClient, now time.Time) (SessionToken, error) {
+	sessions, err := uc.sessions()
+	if err != nil {
+		return SessionToken{}, err
+	}
+	id, err := newSessionID()
+	if err != nil {
+		return SessionToken{}, err
+	}
+	secret, refreshHash, err := newRefreshSecret()
+	if err != nil {
+		return SessionToken{}, err
+	}
+
+	session := domain.WebSession{
+		ID:          id,
+		UserID:      userID,
+		RefreshHash: refreshHash,
+		IP:          client.IP,
+		UserAgent:   client.UserAgent,
+		CreatedAt:   now,
+		LastUsedAt:  now,
+		ExpiresAt:   now.Add(uc.ttl),
+	}
+	if err := sessions.CreateWebSession(ctx, session); err != nil {
+		return SessionToken{}, err
+	}
+	return SessionToken{Session: session, RefreshToken: id + "." + secret}, nil
+}
+
+// Refresh swaps a refresh token for a new one.
+func (uc *SessionUsecase) Refresh(ctx context.Context, refreshToken string, client LoginClient, now time.Time) (SessionToken, error) {
+	sessions, err := uc.sessions()
+	if err != nil {
+		return SessionToken{}, err
+	}
+	session, presentedHash, err := uc.lookup(ctx, sessions, refreshToken)
+	if err != nil {
+		return SessionToken{}, err
+	}
+	if !session.Active(now) {
+		return SessionToken{}, ErrSessionInvalid
+	}
+	if subtle.ConstantTimeCompare([]byte(presentedHash), []byte(session.RefreshHash)) != 1 {
+		if !recentlyRotated(*session, presentedHash, now) {
+			if _, err := sessions.RevokeWebSession(ctx, session.UserID, session.ID, now); err != nil {
+				return SessionToken{}, err
+			}
+			log.Printf("Revoked web session %s of %s: a rotated refresh token was reused", session.ID, session.UserID)
+			return SessionToken{}, ErrSessionInvalid
+		}
+		// Another tab refreshed with the same token a moment ago; rotate
+		// on from the token it got.
+	}
+
+	secret, refreshHash, err := newRefreshSecret()
+	if err != nil {
+		return SessionToken{}, err
+	}
+	rotatedFrom := session.RefreshHash
+	session.PreviousHash = rotatedFrom
+	session.RefreshHash = refreshHash
+	session.IP = client.IP
+	session.UserAgent = client.UserAgent
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(uc.ttl)
+	rotated, err := sessions.RotateWebSession(ctx, *session, rotatedFrom)
+	if err != nil {
+		return SessionToken{}, err
+	}
+	if !rotated {
+		return SessionToken{}, ErrSessionInvalid
+	}
+	return SessionToken{Session: *session, RefreshToken: session.ID + "." + secret}, nil
+}
+
+// recentlyRotated reports whether presentedHash is the one the session's
+// last rotation replaced, and that rotation is within refreshReuseGrace.
+func recentlyRotated(session domain.WebSession, presentedHash string, now time.Time) bool {
+	if session.PreviousHash == "" || now.Sub(session.LastUsedAt) >= refreshReuseGrace {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(presentedHash), []byte(session.PreviousHash)) == 1
+}
+
+// Logout revokes the session a refresh token belongs to. An unknown or
+// already revoked token is not an error.
+func (uc *SessionUsecase) Logout(ctx context.Context, refreshToken string, now time.Time) error {
+	sessions, err := uc.sessions()
+	if err != nil {
+		return err
+	}
+	session, presentedHash, err := uc.lookup(ctx, sessions, refreshToken)
+	if errors.Is(err, ErrSessionInvalid) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(presentedHash), []byte(session.RefreshHash)) != 1 {
+		return nil
+	}
+	_, err = sessions.RevokeWebSession(ctx, session.UserID, session.ID, now)
+	return err
+}
+
+// Active reports whether an access token's session is still usable.
+func (uc *SessionUsecase) Active(ctx context.Context, userID, sessionID string, now time.Time) (bool, error) {
+	sessions, err := uc.sessions()
+	if err != nil {
+		return false, err
+	}
+	session, err := sessions.GetWebSession(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	return session != nil && session.UserID == userID && session.Active(now), nil
+}
+
+// List returns the user's signed-in devices.
+func (uc *SessionUsecase) List(ctx context.Context, userID string, now time.Time) ([]domain.WebSession, error) {
+	sessions, err := uc.sessions()
+	if err != nil {
+		return nil, err
+	}
+	return sessions.ListWebSessions(ctx, userID, now)
+}
+
+// Revoke signs one of the user's devices out.
+func (uc *SessionUsecase) Revoke(ctx context.Context, userID, sessionID string, now time.Time) error {
+	sessions, err := uc.sessions()
+	if err != nil {
+		return err
+	}
+	revoked, err := sessions.RevokeWebSession(ctx, userID, sessionID, now)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAll signs every device of the user out and returns how many were.
+func (uc *SessionUsecase) RevokeAll(ctx context.Context, userID string, now time.Time) (int, error) {
+	sessions, err := uc.sessions()
+	if err != nil {
+		return 0, err
+	}
+	return sessions.RevokeWebSessions(ctx, userID, now)
+}
+
+// ExecuteLogoutAll answers /logout-semua.
+func (uc *SessionUsecase) ExecuteLogoutAll(ctx context.Context, userID string, now time.Time) (string, error) {
+	n, err := uc.RevokeAll(ctx, userID, now)
+	if err != nil {
+		return "", err
+	}
+	if n == 0 {
+		return "🔒 Tidak ada sesi dashboard yang aktif untuk nomor kamu.", nil
+	}
+	return fmt.Sprintf("🔒 %d sesi dashboard kamu sudah dikeluarkan. Login lagi dengan kode dari bot kalau mau membuka dashboard.", n), nil
+}
+
+// lookup finds the session named by a "<session ID>.<secret>" refresh token
+// and hashes the secret part.
+func (uc *SessionUsecase) lookup(ctx context.Context, sessions domain.SessionRepository, refreshToken string) (*domain.WebSession, string, error) {
+	id, secret, ok := strings.Cut(strings.TrimSpace(refreshToken), ".")
+	if !ok || id == "" || secret == "" {
+		return nil, "", ErrSessionInvalid
+	}
+	session, err := sessions.GetWebSession(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if session == nil {
+		return nil, "", ErrSessionInvalid
+	}
+	return session, hashRefreshSecret(secret), nil
+}
+
+func newRefreshSecret() (secret, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	secret = base64.RawURLEncoding.EncodeToString(b)
+	return secret, hashRefreshSecret(secret), nil
+}
+
+// hashRefreshSecret needs no key: the secret is 256 random bits, so its hash
+// can't be reversed by guessing.
+func hashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type sessionRepoStub struct {
+	*cancelReportRepoStub
+	sessions map[string]domain.WebSession
+}
+
+func newSessionRepoStub() *sessionRepoStub {
+	return &sessionRepoStub{cancelReportRepoStub: &cancelReportRepoStub{}, sessions: map[string]domain.WebSession{}}
+}
+
+func (r *sessionRepoStub) CreateWebSession(ctx context.Context, s domain.WebSession) error {
+	r.sessions[s.ID] = s
+	return nil
+}
+
+func (r *sessionRepoStub) GetWebSession(ctx context.Context, id string) (*domain.WebSession, error) {
+	s, ok := r.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (r *sessionRepoStub) RotateWebSession(ctx context.Context, s domain.WebSession, previousHash string) (bool, error) {
+	current, ok := r.sessions[s.ID]
+	if !ok || current.RefreshHash != previousHash || !current.Active(s.LastUsedAt) {
+		return false, nil
+	}
+	r.sessions[s.ID] = s
+	return true, nil
+}
+
+func (r *sessionRepoStub) ListWebSessions(ctx context.Context, userID string, now time.Time) ([]domain.WebSession, error) {
+	var out []domain.WebSession
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.Active(now) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (r *sessionRepoStub) RevokeWebSession(ctx context.Context, userID, id string, at time.Time) (bool, error) {
+	s, ok := r.sessions[id]
+	if !ok || s.UserID != userID || !s.RevokedAt.IsZero() {
+		return false, nil
+	}
+	s.RevokedAt = at
+	r.sessions[id] = s
+	return true, nil
+}
+
+func (r *sessionRepoStub) RevokeWebSessions(ctx context.Context, userID string, at time.Time) (int, error) {
+	n := 0
+	for id, s := range r.sessions {
+		if s.UserID == userID && s.Active(at) {
+			s.RevokedAt = at
+			r.sessions[id] = s
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestSession_RefreshRotatesTheToken(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	repo := newSessionRepoStub()
+	uc := NewSessionUsecase(repo, 24*time.Hour)
+	ctx := context.Background()
+
+	started, err := uc.Start(ctx, "628111", LoginClient{UserAgent: "phone"}, now)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if stored := repo.sessions[started.Session.ID].RefreshHash; strings.Contains(started.RefreshToken, stored) {
+		t.Fatal("only a hash of the refresh token should be stored")
+	}
+
+	refreshed, err := uc.Refresh(ctx, started.RefreshToken, LoginClient{UserAgent: "phone"}, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if refreshed.Session.ID != started.Session.ID || refreshed.RefreshToken == started.RefreshToken {
+		t.Fatalf("refresh should rotate the token of the same session, got %+v", refreshed)
+	}
+	if !refreshed.Session.ExpiresAt.Equal(now.Add(25 * time.Hour)) {
+		t.Fatalf("refresh should extend the session, got %v", refreshed.Session.ExpiresAt)
+	}
+	if active, _ := uc.Active(ctx, "628111", started.Session.ID, now.Add(time.Hour)); !active {
+		t.Fatal("session should be active")
+	}
+	if active, _ := uc.Active(ctx, "628999", started.Session.ID, now.Add(time.Hour)); active {
+		t.Fatal("a session must not be active for another user")
+	}
+}
+
+func TestSession_ReusedRefreshTokenRevokesTheSession(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	repo := newSessionRepoStub()
+	uc := NewSessionUsecase(repo, 24*time.Hour)
+	ctx := context.Background()
+
+	started, _ := uc.Start(ctx, "628111", LoginClient{}, now)
+	refreshed, err := uc.Refresh(ctx, started.RefreshToken, LoginClient{}, now)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	later := now.Add(refreshReuseGrace)
+	if _, err := uc.Refresh(ctx, started.RefreshToken, LoginClient{}, later); !errors.Is(err, ErrSessionInvalid) {
+		t.Fatalf("reusing a rotated token: got %v", err)
+	}
+	if _, err := uc.Refresh(ctx, refreshed.RefreshToken, LoginClient{}, later); !errors.Is(err, ErrSessionInvalid) {
+		t.Fatalf("the whole session should be revoked after a reuse, got %v", err)
+	}
+	if active, _ := uc.Active(ctx, "628111", started.Session.ID, later); active {
+		t.Fatal("session should no longer be active")
+	}
+}
+
+func TestSession_ParallelRefreshesKeepTheSession(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	repo := newSessionRepoStub()
+	uc := NewSessionUsecase(repo, 24*time.Hour)
+	ctx := context.Background()
+
+	started, _ := uc.Start(ctx, "628111", LoginClient{}, now)
+	first, err := uc.Refresh(ctx, started.RefreshToken, LoginClient{}, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	// A second tab read the same token before the first one stored its
+	// replacement.
+	second, err := uc.Refresh(ctx, started.RefreshToken, LoginClient{}, now.Add(time.Hour+time.Second))
+	if err != nil {
+		t.Fatalf("a parallel refresh should not sign the device out: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("the parallel refresh should get a token of its own")
+	}
+	// Whichever tab stored its token last, both keep working for a moment.
+	if _, err := uc.Refresh(ctx, first.RefreshToken, LoginClient{}, now.Add(time.Hour+2*time.Second)); err != nil {
+		t.Fatalf("the token the parallel refresh replaced: %v", err)
+	}
+	if active, _ := uc.Active(ctx, "628111", started.Session.ID, now.Add(time.Hour+2*time.Second)); !active {
+		t.Fatal("session should still be active")
+	}
+}
+
+func TestSession_ExpiredAndMalformedTokens(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	uc := NewSessionUsecase(newSessionRepoStub(), time.Hour)
+	ctx := context.Background()
+
+	started, _ := uc.Start(ctx, "628111", LoginClient{}, now)
+	if _, err := uc.Refresh(ctx, started.RefreshToken, LoginClient{}, now.Add(time.Hour)); !errors.Is(err, ErrSessionInvalid) {
+		t.Fatalf("expired session: got %v", err)
+	}
+	for _, token := range []string{"", "nodot", started.Session.ID + ".", "unknown.secret"} {
+		if _, err := uc.Refresh(ctx, token, LoginClient{}, now); !errors.Is(err, ErrSessionInvalid) {
+			t.Fatalf("token %q: got %v", token, err)
+		}
+	}
+}
+
+func TestSession_LogoutAndRevoke(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	repo := newSessionRepoStub()
+	uc := NewSessionUsecase(repo, 24*time.Hour)
+	ctx := context.Background()
+
+	laptop, _ := uc.Start(ctx, "628111", LoginClient{UserAgent: "laptop"}, now)
+	phone, _ := uc.Start(ctx, "628111", LoginClient{UserAgent: "phone"}, now)
+	tablet, _ := uc.Start(ctx, "628111", LoginClient{UserAgent: "tablet"}, now)
+
+	if err := uc.Logout(ctx, laptop.RefreshToken, now); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if err := uc.Logout(ctx, laptop.RefreshToken, now); err != nil {
+		t.Fatalf("logging out twice should not fail: %v", err)
+	}
+	if err := uc.Revoke(ctx, "628999", phone.Session.ID, now); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("revoking another user's session: got %v", err)
+	}
+	if err := uc.Revoke(ctx, "628111", phone.Session.ID, now); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	list, _ := uc.List(ctx, "628111", now)
+	if len(list) != 1 || list[0].ID != tablet.Session.ID {
+		t.Fatalf("only the tablet should be signed in, got %+v", list)
+	}
+
+	reply, err := uc.ExecuteLogoutAll(ctx, "628111", now)
+	if err != nil || !strings.Contains(reply, "1 sesi") {
+		t.Fatalf("ExecuteLogoutAll = %q, %v", reply, err)
+	}
+	if reply, _ := uc.ExecuteLogoutAll(ctx, "628111", now); !strings.Contains(reply, "Tidak ada sesi") {
+		t.Fatalf("nothing left to revoke, got %q", reply)
+	}
+}
@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+var (
+	ErrStravaPreferencesUnavailable = errors.New("strava preferences are not supported by this repository")
+	ErrUnknownStravaType            = errors.New("unknown strava activity type")
+)
+
+// StravaStatus describes a user's Strava link for /strava status and the
+// dashboard.
+type StravaStatus struct {
+	Linked         bool                     `json:"linked"`
+	AthleteID      int64                    `json:"athlete_id,omitempty"`
+	Preferences    domain.StravaPreferences `json:"preferences"`
+	SupportedTypes []string                 `json:"supported_types"`
+}
+
+// StravaUnlinkResult reports what an unlink did. Revoked is false when Strava
+// could not be reached; the local credentials are deleted regardless.
+type StravaUnlinkResult struct {
+	Unlinked bool `json:"unlinked"`
+	Revoked  bool `json:"revoked"`
+}
+
+// StravaAccountUsecase lets a linked user inspect, tune and undo their
+// Strava integration.
+type StravaAccountUsecase struct {
+	repo      domain.ReportRepository
+	processor *ProcessStravaWebhookUsecase
+}
+
+func NewStravaAccountUsecase(repo domain.ReportRepository, processor *ProcessStravaWebhookUsecase) *StravaAccountUsecase {
+	return &StravaAccountUsecase{repo: repo, processor: processor}
+}
+
+// StravaSupportedTypes lists the Strava activity types that can be
+// auto-reported, sorted for display.
+func StravaSupportedTypes() []string {
+	supported := make([]string, 0, len(stravaReportableTypes))
+	for t := range stravaReportableTypes {
+		supported = append(supported, t)
+	}
+	sort.Strings(supported)
+	return supported
+}
+
+func (u *StravaAccountUsecase) Status(ctx context.Context, userID string) (StravaStatus, error) {
+	status := StravaStatus{
+		Preferences:    u.processor.preferences(ctx, userID),
+		SupportedTypes: StravaSupportedTypes(),
+	}
+	account, err := u.repo.GetStravaAccountByUserID(ctx, userID)
+	if err != nil {
+		return status, err
+	}
+	if account != nil {
+		status.Linked = true
+		status.AthleteID = account.AthleteID
+	}
+	return status, nil
+}
+
+// Unlink revokes the app's access on Strava and deletes the stored tokens.
+// A failed revoke is logged but does not keep the credentials around: the
+// user asked for them to be gone.
+func (u *StravaAccountUsecase) Unlink(ctx context.Context, userID string) (StravaUnlinkResult, error) {
+	var result StravaUnlinkResult
+	activities, ok := u.repo.(domain.StravaActivityRepository)
+	if !ok {
+		return result, ErrStravaSyncUnavailable
+	}
+	account, err := u.repo.GetStravaAccountByUserID(ctx, userID)
+	if err != nil || account == nil {
+		return result, err
+	}
+
+	if err := u.processor.refreshToken(ctx, account); err != nil {
+		log.Printf("Failed to refresh Strava token before revoking for user %s: %v", userID, err)
+	} else if err := u.processor.stravaClient.Deauthorize(account.AccessToken); err != nil {
+		log.Printf("Failed to revoke Strava access for user %s: %v", userID, err)
+	} else {
+		result.Revoked = true
+	}
+
+	if err := activities.DeleteStravaAccount(ctx, userID); err != nil {
+		return result, err
+	}
+	result.Unlinked = true
+	return result, nil
+}
+
+// UpdatePreferences changes the user's auto-report settings. A nil argument
+// keeps the current value; an empty activityTypes list means every supported
+// type. Type names are matched case-insensitively.
+func (u *StravaAccountUsecase) UpdatePreferences(ctx context.Context, userID string, activityTypes []string, postToGroup *bool, now time.Time) (domain.StravaPreferences, error) {
+	prefsRepo, ok := u.repo.(domain.StravaPreferencesRepository)
+	if !ok {
+		return domain.StravaPreferences{}, ErrStravaPreferencesUnavailable
+	}
+	prefs := u.processor.preferences(ctx, userID)
+	if activityTypes != nil {
+		canonical, err := canonicalStravaTypes(activityTypes)
+		if err != nil {
+			return prefs, err
+		}
+		prefs.ActivityTypes = canonical
+	}
+	if postToGroup != nil {
+		prefs.PostToGroup = *postToGroup
+	}
+	prefs.UserID = userID
+	prefs.UpdatedAt = now
+	if err := prefsRepo.SaveStravaPreferences(ctx, prefs); err != nil {
+		return prefs, err
+	}
+	return prefs, nil
+}
+
+func canonicalStravaTypes(names []string) ([]string, error) {
+	byLower := make(map[string]string, len(stravaReportableTypes))
+	for t := range stravaReportableTypes {
+		byLower[strings.ToLower(t)] = t
+	}
+	seen := map[string]bool{}
+	canonical := []string{}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		t, ok := byLower[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownStravaType, name)
+		}
+		if !seen[t] {
+			seen[t] = true
+			canonical = append(canonical, t)
+		}
+	}
+	sort.Strings(canonical)
+	return canonical, nil
+}
+
+// ExecuteCommand handles "/strava <subcommand>" and returns the reply.
+func (u *StravaAccountUsecase) ExecuteCommand(ctx context.Context, userID, args string, now time.Time) (string, error) {
+	fields := strings.Fields(strings.ToLower(args))
+	sub := ""
+	if len(fields) > 0 {
+		sub = fields[0]
+	}
+	rest := strings.TrimSpace(strings.Join(fields[min(1, len(fields)):], " "))
+
+	switch sub {
+	case "status":
+		status, err := u.Status(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		return formatStravaStatus(status), nil
+	case "unlink":
+		result, err := u.Unlink(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		if !result.Unlinked {
+			return "Akun Strava kamu belum terhubung, jadi tidak ada yang perlu diputus. 🙂", nil
+		}
+		return "🔌 Akun Strava kamu sudah diputus dan token-nya dihapus dari Bot Lapor. Aktivitas baru tidak akan dilaporkan otomatis lagi.\n\nKirim /strava kapan saja kalau mau menghubungkan lagi.", nil
+	case "sync":
+		result, err := NewSyncStravaUsecase(u.repo, u.processor).Backfill(ctx, userID, SeasonStartDate(now), now)
+		if errors.Is(err, ErrStravaNotLinked) {
+			return stravaNotLinkedReply, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("🔄 *Sinkronisasi Strava selesai*\n\n%d aktivitas season ini dicek, %d baru tercatat. Aktivitas yang sudah pernah masuk tidak dihitung ulang.", result.Fetched, result.Imported), nil
+	case "tipe":
+		if rest == "" {
+			return "Format: /strava tipe run,ride atau /strava tipe semua\nTipe yang didukung: " + strings.Join(StravaSupportedTypes(), ", "), nil
+		}
+		activityTypes := []string{}
+		if rest != "semua" {
+			activityTypes = strings.FieldsFunc(rest, func(r rune) bool { return r == ',' || r == ' ' })
+		}
+		prefs, err := u.UpdatePreferences(ctx, userID, activityTypes, nil, now)
+		if errors.Is(err, ErrUnknownStravaType) {
+			return fmt.Sprintf("Tipe tidak dikenal. Tipe yang didukung: %s", strings.Join(StravaSupportedTypes(), ", ")), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return "✅ Tipe aktivitas yang dilaporkan otomatis: " + formatStravaTypes(prefs), nil
+	case "kirim":
+		if rest != "grup" && rest != "dm" {
+			return "Format: /strava kirim grup atau /strava kirim dm", nil
+		}
+		postToGroup := rest == "grup"
+		if _, err := u.UpdatePreferences(ctx, userID, nil, &postToGroup, now); err != nil {
+			return "", err
+		}
+		if postToGroup {
+			return "✅ Auto-report Strava akan diumumkan di grup.", nil
+		}
+		return "✅ Auto-report Strava hanya akan dikirim ke kamu lewat DM.", nil
+	default:
+		return stravaCommandHelp, nil
+	}
+}
+
+const stravaNotLinkedReply = "Akun Strava kamu belum terhubung. Kirim /strava untuk mendapatkan link."
+
+const stravaCommandHelp = "🚴 *Perintah Strava*\n\n" +
+	"/strava — hubungkan akun (link dikirim lewat DM)\n" +
+	"/strava status — lihat status dan pengaturan\n" +
+	"/strava sync — tarik aktivitas season ini\n" +
+	"/strava tipe run,ride — pilih tipe yang dilaporkan (/strava tipe semua untuk semua)\n" +
+	"/strava kirim grup|dm — umumkan di grup atau cukup lewat DM\n" +
+	"/strava unlink — putuskan akun dan hapus token"
+
+func formatStravaStatus(status StravaStatus) string {
+	if !status.Linked {
+		return stravaNotLinkedReply
+	}
+	destination := "diumumkan di grup"
+	if !status.Preferences.PostToGroup {
+		destination = "hanya lewat DM"
+	}
+	return fmt.Sprintf("🚴 *Status Strava*\n\n✅ Terhubung (athlete #%d)\n🏷️ Tipe dilaporkan: %s\n📣 Auto-report: %s\n\n%s",
+		status.AthleteID, formatStravaTypes(status.Preferences), destination, stravaCommandHelp)
+}
+
+func formatStravaTypes(prefs domain.StravaPreferences) string {
+	if len(prefs.ActivityTypes) == 0 {
+		return "semua tipe yang didukung"
+	}
+	return strings.Join(prefs.ActivityTypes, ", ")
+}
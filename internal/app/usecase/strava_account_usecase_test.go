@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/strava"
+)
+
+type stravaAccountRepoStub struct {
+	*stravaSyncRepoStub
+	prefs map[string]domain.StravaPreferences
+}
+
+func (r *stravaAccountRepoStub) GetStravaPreferences(ctx context.Context, userID string) (*domain.StravaPreferences, error) {
+	prefs, ok := r.prefs[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &prefs, nil
+}
+
+func (r *stravaAccountRepoStub) SaveStravaPreferences(ctx context.Context, prefs domain.StravaPreferences) error {
+	r.prefs[prefs.UserID] = prefs
+	return nil
+}
+
+func (r *stravaAccountRepoStub) DeleteStravaAccount(ctx context.Context, userID string) error {
+	r.account = nil
+	return r.stravaSyncRepoStub.DeleteStravaAccount(ctx, userID)
+}
+
+func newStravaAccountTest(t *testing.T, deauthorizeStatus int) (*StravaAccountUsecase, *stravaAccountRepoStub, *[]string) {
+	t.Helper()
+	var revoked []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/deauthorize" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = r.ParseForm()
+		revoked = append(revoked, r.PostForm.Get("access_token"))
+		w.WriteHeader(deauthorizeStatus)
+	}))
+	t.Cleanup(srv.Close)
+
+	repo := &stravaAccountRepoStub{
+		stravaSyncRepoStub: &stravaSyncRepoStub{
+			stravaWebhookRepoStub: newStravaWebhookRepoStub(&cancelReportRepoStub{}),
+			account:               &domain.StravaAccount{UserID: "628111", AthleteID: 7, AccessToken: "token-1", ExpiresAt: time.Now().Add(time.Hour)},
+			perDay:                map[string]int{},
+		},
+		prefs: map[string]domain.StravaPreferences{},
+	}
+	client := strava.NewClient(config.Config{StravaBaseURL: srv.URL})
+	processor := NewProcessStravaWebhookUsecase(repo, client, nil, "")
+	return NewStravaAccountUsecase(repo, processor), repo, &revoked
+}
+
+func TestStravaAccount_UnlinkRevokesAndDeletesCredentials(t *testing.T) {
+	uc, repo, revoked := newStravaAccountTest(t, http.StatusOK)
+
+	result, err := uc.Unlink(context.Background(), "628111")
+	if err != nil {
+		t.Fatalf("Unlink: %v", err)
+	}
+	if !result.Unlinked || !result.Revoked {
+		t.Fatalf("expected unlinked and revoked, got %+v", result)
+	}
+	if len(*revoked) != 1 || (*revoked)[0] != "token-1" {
+		t.Fatalf("expected token-1 to be revoked, got %v", *revoked)
+	}
+	if len(repo.unlinked) != 1 || repo.account != nil {
+		t.Fatalf("expected local credentials to be deleted, got %v", repo.unlinked)
+	}
+
+	again, err := uc.Unlink(context.Background(), "628111")
+	if err != nil || again.Unlinked {
+		t.Fatalf("second unlink should find nothing, got %+v, %v", again, err)
+	}
+}
+
+func TestStravaAccount_UnlinkDeletesEvenWhenRevokeFails(t *testing.T) {
+	uc, repo, _ := newStravaAccountTest(t, http.StatusUnauthorized)
+
+	result, err := uc.Unlink(context.Background(), "628111")
+	if err != nil {
+		t.Fatalf("Unlink: %v", err)
+	}
+	if !result.Unlinked || result.Revoked {
+		t.Fatalf("expected unlinked without revoke, got %+v", result)
+	}
+	if repo.account != nil {
+		t.Fatal("credentials must be deleted even if Strava rejects the revoke")
+	}
+}
+
+func TestStravaAccount_PreferenceCommands(t *testing.T) {
+	uc, repo, _ := newStravaAccountTest(t, http.StatusOK)
+	ctx := context.Background()
+	now := time.Now()
+
+	reply, err := uc.ExecuteCommand(ctx, "628111", "tipe run, RIDE", now)
+	if err != nil {
+		t.Fatalf("tipe: %v", err)
+	}
+	if !strings.Contains(reply, "Ride, Run") {
+		t.Fatalf("unexpected reply %q", reply)
+	}
+	if got := repo.prefs["628111"].ActivityTypes; len(got) != 2 || got[0] != "Ride" || got[1] != "Run" {
+		t.Fatalf("unexpected stored types %v", got)
+	}
+
+	if reply, _ := uc.ExecuteCommand(ctx, "628111", "tipe golf", now); !strings.Contains(reply, "Tipe tidak dikenal") {
+		t.Fatalf("expected unknown type reply, got %q", reply)
+	}
+
+	if _, err := uc.ExecuteCommand(ctx, "628111", "kirim dm", now); err != nil {
+		t.Fatalf("kirim: %v", err)
+	}
+	prefs := uc.processor.preferences(ctx, "628111")
+	if prefs.PostToGroup || len(prefs.ActivityTypes) != 2 {
+		t.Fatalf("kirim dm should keep the types and turn off group posts, got %+v", prefs)
+	}
+	if uc.processor.autoReports(ctx, "628111", "Walk") || !uc.processor.autoReports(ctx, "628111", "Run") {
+		t.Fatal("auto-reports should follow the chosen types")
+	}
+
+	status, err := uc.ExecuteCommand(ctx, "628111", "status", now)
+	if err != nil || !strings.Contains(status, "athlete #7") || !strings.Contains(status, "hanya lewat DM") {
+		t.Fatalf("unexpected status %q, %v", status, err)
+	}
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const (
+	// stravaWebhookMaxAttempts is how many times a delivery is tried before
+	// it is dead-lettered.
+	stravaWebhookMaxAttempts = 8
+	stravaWebhookBaseBackoff = 30 * time.Second
+	stravaWebhookMaxBackoff  = time.Hour
+	// stravaWebhookBatchSize caps how many deliveries one worker pass handles.
+	stravaWebhookBatchSize = 20
+	stravaWebhookListLimit = 100
+)
+
+var ErrStravaInboxUnavailable = errors.New("strava webhook inbox is not supported by this repository")
+
+// StravaWebhookInboxUsecase persists incoming Strava webhooks and feeds them
+// to ProcessStravaWebhookUsecase with retries, so a failed event is retried
+// instead of lost.
+type StravaWebhookInboxUsecase struct {
+	repo      domain.ReportRepository
+	processor *ProcessStravaWebhookUsecase
+}
+
+func NewStravaWebhookInboxUsecase(repo domain.ReportRepository, processor *ProcessStravaWebhookUsecase) *StravaWebhookInboxUsecase {
+	return &StravaWebhookInboxUsecase{repo: repo, processor: processor}
+}
+
+func (u *StravaWebhookInboxUsecase) inbox() (domain.StravaWebhookInboxRepository, error) {
+	inbox, ok := u.repo.(domain.StravaWebhookInboxRepository)
+	if !ok {
+		return nil, ErrStravaInboxUnavailable
+	}
+	return inbox, nil
+}
+
+// Enqueue stores an event for the worker. The webhook is only acknowledged
+// once this succeeds, so Strava retries it otherwise.
+func (u *StravaWebhookInboxUsecase) Enqueue(ctx context.Context, event StravaWebhookEvent, now time.Time) (int64, error) {
+	inbox, err := u.inbox()
+	if err != nil {
+		return 0, err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode strava webhook: %w", err)
+	}
+	return inbox.EnqueueStravaWebhook(ctx, string(payload), now)
+}
+
+// ProcessDue runs every delivery that is due and returns how many were
+// processed successfully.
+func (u *StravaWebhookInboxUsecase) ProcessDue(ctx context.Context, now time.Time) (int, error) {
+	inbox, err := u.inbox()
+	if err != nil {
+		return 0, err
+	}
+	due, err := inbox.GetDueStravaWebhooks(ctx, now, stravaWebhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, d := range due {
+		if ctx.Err() != nil {
+			return processed, ctx.Err()
+		}
+		if err := u.process(ctx, d); err != nil {
+			attempts := d.Attempts + 1
+			dead := attempts >= stravaWebhookMaxAttempts
+			next := now.Add(StravaWebhookBackoff(attempts))
+			if dead {
+				log.Printf("Strava webhook %d dead-lettered after %d attempts: %v", d.ID, attempts, err)
+			} else {
+				log.Printf("Strava webhook %d failed (attempt %d), retrying at %s: %v", d.ID, attempts, next.Format(time.RFC3339), err)
+			}
+			if err := inbox.MarkStravaWebhookFailed(ctx, d.ID, attempts, err.Error(), next, dead); err != nil {
+				return processed, err
+			}
+			continue
+		}
+		if err := inbox.MarkStravaWebhookProcessed(ctx, d.ID, now); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+func (u *StravaWebhookInboxUsecase) process(ctx context.Context, d domain.StravaWebhookDelivery) error {
+	var event StravaWebhookEvent
+	if err := json.Unmarshal([]byte(d.Payload), &event); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+	return u.processor.Execute(ctx, event)
+}
+
+// StravaWebhookBackoff returns the delay before retry number attempts:
+// 30s, 1m, 2m, ... capped at an hour.
+func StravaWebhookBackoff(attempts int) time.Duration {
+	return retryBackoff(attempts, stravaWebhookBaseBackoff, stravaWebhookMaxBackoff)
+}
+
+// retryBackoff doubles base for every attempt after the first, up to max.
+func retryBackoff(attempts int, base, max time.Duration) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
+
+// List returns the newest deliveries for the admin view. An empty status
+// returns every status.
+func (u *StravaWebhookInboxUsecase) List(ctx context.Context, status string) ([]domain.StravaWebhookDelivery, error) {
+	inbox, err := u.inbox()
+	if err != nil {
+		return nil, err
+	}
+	return inbox.ListStravaWebhooks(ctx, status, stravaWebhookListLimit)
+}
+
+// Replay queues a delivery again, typically a dead-lettered one after the
+// cause was fixed. Processing is idempotent, so replaying a processed event
+// does not report it twice.
+func (u *StravaWebhookInboxUsecase) Replay(ctx context.Context, id int64, now time.Time) (bool, error) {
+	inbox, err := u.inbox()
+	if err != nil {
+		return false, err
+	}
+	return inbox.ReplayStravaWebhook(ctx, id, now)
+}
@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type stravaInboxRepoStub struct {
+	*stravaWebhookRepoStub
+	deliveries map[int64]*domain.StravaWebhookDelivery
+	nextID     int64
+}
+
+func newStravaInboxRepoStub() *stravaInboxRepoStub {
+	return &stravaInboxRepoStub{
+		stravaWebhookRepoStub: newStravaWebhookRepoStub(&cancelReportRepoStub{}),
+		deliveries:            map[int64]*domain.StravaWebhookDelivery{},
+	}
+}
+
+func (r *stravaInboxRepoStub) EnqueueStravaWebhook(ctx context.Context, payload string, receivedAt time.Time) (int64, error) {
+	r.nextID++
+	r.deliveries[r.nextID] = &domain.StravaWebhookDelivery{ID: r.nextID, Payload: payload, Status: domain.StravaWebhookPending, ReceivedAt: receivedAt, NextAttemptAt: receivedAt}
+	return r.nextID, nil
+}
+
+func (r *stravaInboxRepoStub) GetDueStravaWebhooks(ctx context.Context, now time.Time, limit int) ([]domain.StravaWebhookDelivery, error) {
+	var out []domain.StravaWebhookDelivery
+	for id := int64(1); id <= r.nextID; id++ {
+		d := r.deliveries[id]
+		if d.Status == domain.StravaWebhookPending && !d.NextAttemptAt.After(now) {
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (r *stravaInboxRepoStub) MarkStravaWebhookProcessed(ctx context.Context, id int64, processedAt time.Time) error {
+	d := r.deliveries[id]
+	d.Status = domain.StravaWebhookProcessed
+	d.Attempts++
+	d.ProcessedAt = processedAt
+	return nil
+}
+
+func (r *stravaInboxRepoStub) MarkStravaWebhookFailed(ctx context.Context, id int64, attempts int, lastError string, nextAttemptAt time.Time, dead bool) error {
+	d := r.deliveries[id]
+	d.Attempts = attempts
+	d.LastError = lastError
+	d.NextAttemptAt = nextAttemptAt
+	if dead {
+		d.Status = domain.StravaWebhookDead
+	}
+	return nil
+}
+
+func (r *stravaInboxRepoStub) ListStravaWebhooks(ctx context.Context, status string, limit int) ([]domain.StravaWebhookDelivery, error) {
+	return nil, nil
+}
+
+func (r *stravaInboxRepoStub) ReplayStravaWebhook(ctx context.Context, id int64, now time.Time) (bool, error) {
+	d, ok := r.deliveries[id]
+	if !ok {
+		return false, nil
+	}
+	d.Status = domain.StravaWebhookPending
+	d.Attempts = 0
+	d.NextAttemptAt = now
+	return true, nil
+}
+
+func TestStravaWebhookBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		7:  32 * time.Minute,
+		8:  time.Hour,
+		20: time.Hour,
+	}
+	for attempts, want := range cases {
+		if got := StravaWebhookBackoff(attempts); got != want {
+			t.Errorf("StravaWebhookBackoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestStravaWebhookInbox_RetriesThenDeadLetters(t *testing.T) {
+	repo := newStravaInboxRepoStub()
+	repo.accountErr = errors.New("database is locked")
+	inbox := NewStravaWebhookInboxUsecase(repo, NewProcessStravaWebhookUsecase(repo, nil, nil, ""))
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	id, err := inbox.Enqueue(ctx, StravaWebhookEvent{ObjectType: "activity", AspectType: "create", ObjectID: 42, AthleteID: 7}, now)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	if n, err := inbox.ProcessDue(ctx, now); err != nil || n != 0 {
+		t.Fatalf("first pass: processed=%d err=%v", n, err)
+	}
+	d := repo.deliveries[id]
+	if d.Status != domain.StravaWebhookPending || d.Attempts != 1 || d.LastError == "" {
+		t.Fatalf("expected a scheduled retry, got %+v", d)
+	}
+	if want := now.Add(30 * time.Second); !d.NextAttemptAt.Equal(want) {
+		t.Fatalf("next attempt = %s, want %s", d.NextAttemptAt, want)
+	}
+
+	// Not due yet: nothing runs.
+	if _, err := inbox.ProcessDue(ctx, now.Add(10*time.Second)); err != nil || d.Attempts != 1 {
+		t.Fatalf("delivery ran before it was due: attempts=%d err=%v", d.Attempts, err)
+	}
+
+	for d.Status == domain.StravaWebhookPending {
+		if _, err := inbox.ProcessDue(ctx, d.NextAttemptAt); err != nil {
+			t.Fatalf("ProcessDue: %v", err)
+		}
+	}
+	if d.Status != domain.StravaWebhookDead || d.Attempts != stravaWebhookMaxAttempts {
+		t.Fatalf("expected dead letter after %d attempts, got %+v", stravaWebhookMaxAttempts, d)
+	}
+
+	// Once the cause is fixed, an admin replay processes it.
+	repo.accountErr = nil
+	if ok, err := inbox.Replay(ctx, id, now.Add(24*time.Hour)); err != nil || !ok {
+		t.Fatalf("Replay: %v, %v", ok, err)
+	}
+	if n, err := inbox.ProcessDue(ctx, now.Add(24*time.Hour)); err != nil || n != 1 {
+		t.Fatalf("replayed pass: processed=%d err=%v", n, err)
+	}
+	if d.Status != domain.StravaWebhookProcessed {
+		t.Fatalf("expected processed after replay, got %+v", d)
+	}
+}
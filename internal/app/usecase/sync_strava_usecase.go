@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/strava"
+)
+
+const (
+	stravaSyncPageSize = 100
+	// stravaSyncMaxPages bounds one sync run; a season rarely has more than
+	// a few hundred activities per athlete.
+	stravaSyncMaxPages = 10
+	// stravaQuietAfter is how long an athlete can go without a webhook
+	// before the poller starts checking on them.
+	stravaQuietAfter = 6 * time.Hour
+	// stravaPollEvery is the minimum time between polls of one athlete.
+	stravaPollEvery = 2 * time.Hour
+	// stravaPollWindow covers today and yesterday in any timezone.
+	stravaPollWindow = 48 * time.Hour
+)
+
+var (
+	ErrStravaSyncUnavailable = errors.New("strava sync is not supported by this repository")
+	ErrStravaNotLinked       = errors.New("strava account is not linked")
+)
+
+// StravaSyncResult summarizes one backfill or poll of an athlete.
+type StravaSyncResult struct {
+	Since    time.Time `json:"since"`
+	Fetched  int       `json:"fetched"`
+	Imported int       `json:"imported"`
+}
+
+// SyncStravaUsecase pulls activities from the Strava API instead of waiting
+// for webhooks: a user-requested backfill of the current season, and a
+// polling fallback for athletes whose webhooks have gone quiet. Both feed
+// the same idempotent import as the webhook, so an activity is reported once
+// no matter how many paths see it.
+type SyncStravaUsecase struct {
+	repo      domain.ReportRepository
+	processor *ProcessStravaWebhookUsecase
+}
+
+func NewSyncStravaUsecase(repo domain.ReportRepository, processor *ProcessStravaWebhookUsecase) *SyncStravaUsecase {
+	return &SyncStravaUsecase{repo: repo, processor: processor}
+}
+
+// Backfill imports the user's activities since the given day. since is
+// clamped to the start of the current season; days before yesterday are
+// recorded on their own date without streak effects, and today and
+// yesterday follow the normal report rules.
+func (u *SyncStravaUsecase) Backfill(ctx context.Context, userID string, since, now time.Time) (StravaSyncResult, error) {
+	account, err := u.repo.GetStravaAccountByUserID(ctx, userID)
+	if err != nil {
+		return StravaSyncResult{}, err
+	}
+	if account == nil {
+		return StravaSyncResult{}, ErrStravaNotLinked
+	}
+	if seasonStart := SeasonStartDate(now); since.Before(seasonStart) {
+		since = seasonStart
+	}
+	return u.sync(ctx, account, since, true, now)
+}
+
+// PollQuiet checks athletes that have had no webhook for a while and imports
+// any of today's or yesterday's activities the webhooks missed. It returns
+// how many athletes were polled.
+func (u *SyncStravaUsecase) PollQuiet(ctx context.Context, now time.Time) (int, error) {
+	syncRepo, ok := u.repo.(domain.StravaSyncRepository)
+	if !ok {
+		return 0, ErrStravaSyncUnavailable
+	}
+	accounts, err := syncRepo.ListQuietStravaAccounts(ctx, now.Add(-stravaQuietAfter), now.Add(-stravaPollEvery))
+	if err != nil {
+		return 0, err
+	}
+
+	polled := 0
+	for i := range accounts {
+		if ctx.Err() != nil {
+			return polled, ctx.Err()
+		}
+		account := &accounts[i]
+		result, err := u.sync(ctx, account, now.Add(-stravaPollWindow), false, now)
+		if err != nil {
+			// One athlete's revoked token must not stop the others.
+			log.Printf("Strava poll failed for user %s: %v", account.UserID, err)
+			continue
+		}
+		if result.Imported > 0 {
+			log.Printf("Strava poll imported %d activities for user %s", result.Imported, account.UserID)
+		}
+		if err := syncRepo.RecordStravaPolled(ctx, account.UserID, now); err != nil {
+			return polled, err
+		}
+		polled++
+	}
+	return polled, nil
+}
+
+func (u *SyncStravaUsecase) sync(ctx context.Context, account *domain.StravaAccount, since time.Time, history bool, now time.Time) (StravaSyncResult, error) {
+	result := StravaSyncResult{Since: since}
+	if !since.Before(now) {
+		return result, nil
+	}
+	if err := u.processor.refreshToken(ctx, account); err != nil {
+		return result, err
+	}
+
+	var activities []strava.Activity
+	for page := 1; page <= stravaSyncMaxPages; page++ {
+		batch, err := u.processor.stravaClient.ListActivities(account.AccessToken, since, now, page, stravaSyncPageSize)
+		if err != nil {
+			return result, err
+		}
+		activities = append(activities, batch...)
+		if len(batch) < stravaSyncPageSize {
+			break
+		}
+	}
+	// Oldest first, so a day's earlier activities take its report slots.
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].StartDate.Before(activities[j].StartDate)
+	})
+
+	for i := range activities {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		result.Fetched++
+		imported, err := u.processor.importActivity(ctx, account, &activities[i], history, now)
+		if err != nil {
+			return result, err
+		}
+		if imported {
+			result.Imported++
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/strava"
+)
+
+// stravaSyncRepoStub counts reports per day so the daily limit applies.
+type stravaSyncRepoStub struct {
+	*stravaWebhookRepoStub
+	account   *domain.StravaAccount
+	perDay    map[string]int
+	quiet     []domain.StravaAccount
+	polledIDs []string
+}
+
+func (r *stravaSyncRepoStub) GetStravaAccountByUserID(ctx context.Context, userID string) (*domain.StravaAccount, error) {
+	if r.account != nil && r.account.UserID == userID {
+		return r.account, nil
+	}
+	return nil, nil
+}
+
+func (r *stravaSyncRepoStub) GetDailyActivityCountByKind(ctx context.Context, userID string, date time.Time, kind string) (int, error) {
+	return r.perDay[date.Format(time.DateOnly)], nil
+}
+
+func (r *stravaSyncRepoStub) UpsertReportWithActivityKind(ctx context.Context, report *domain.Report, activityDate time.Time, kind string) error {
+	r.report = report
+	r.perDay[activityDate.Format(time.DateOnly)]++
+	return nil
+}
+
+func (r *stravaSyncRepoStub) RecordStravaWebhookSeen(ctx context.Context, userID string, at time.Time) error {
+	return nil
+}
+
+func (r *stravaSyncRepoStub) RecordStravaPolled(ctx context.Context, userID string, at time.Time) error {
+	r.polledIDs = append(r.polledIDs, userID)
+	return nil
+}
+
+func (r *stravaSyncRepoStub) ListQuietStravaAccounts(ctx context.Context, quietSince, polledBefore time.Time) ([]domain.StravaAccount, error) {
+	return r.quiet, nil
+}
+
+func newStravaStubServer(t *testing.T, activities []strava.Activity) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/athlete/activities" || r.Header.Get("Authorization") != "Bearer token-1" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		page := activities
+		if r.URL.Query().Get("page") != "1" {
+			page = nil
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func stravaRun(id int64, day time.Time, activityType string) strava.Activity {
+	start := day.Add(6 * time.Hour)
+	return strava.Activity{ID: id, Name: "Activity", Type: activityType, Distance: 5000, MovingTime: 1800, StartDate: start, StartDateLocal: start}
+}
+
+func TestSyncStrava_BackfillIsBoundedLimitedAndIdempotent(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*3600)
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, wib)
+	oct10 := time.Date(2026, time.October, 10, 0, 0, 0, 0, time.UTC)
+	srv := newStravaStubServer(t, []strava.Activity{
+		stravaRun(1, time.Date(2026, time.August, 20, 0, 0, 0, 0, time.UTC), "Run"), // last season
+		stravaRun(2, oct10, "Run"),
+		stravaRun(3, oct10, "Ride"),
+		stravaRun(4, oct10, "Walk"),
+		stravaRun(5, oct10, "Run"), // fourth report of the day
+		stravaRun(6, oct10.AddDate(0, 0, 2), "Golf"),
+		stravaRun(7, oct10.AddDate(0, 0, 3), "Swim"),
+	})
+
+	repo := &stravaSyncRepoStub{
+		stravaWebhookRepoStub: newStravaWebhookRepoStub(&cancelReportRepoStub{
+			report: &domain.Report{UserID: "628111", Name: "Budi"},
+		}),
+		account: &domain.StravaAccount{UserID: "628111", AthleteID: 7, AccessToken: "token-1", ExpiresAt: time.Now().Add(time.Hour)},
+		perDay:  map[string]int{},
+	}
+	client := strava.NewClient(config.Config{StravaBaseURL: srv.URL})
+	processor := NewProcessStravaWebhookUsecase(repo, client, NewReportActivityUsecase(repo), "")
+	uc := NewSyncStravaUsecase(repo, processor)
+
+	result, err := uc.Backfill(context.Background(), "628111", time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), now)
+	if err != nil {
+		t.Fatalf("Backfill: %v", err)
+	}
+	if want := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC); !result.Since.Equal(want) {
+		t.Fatalf("since should be clamped to the season start %s, got %s", want, result.Since)
+	}
+	if result.Fetched != 7 || result.Imported != 4 {
+		t.Fatalf("expected 7 fetched and 4 imported, got %+v", result)
+	}
+	if got := repo.perDay["2026-10-10"]; got != MaxDailyRegularReports {
+		t.Fatalf("expected the daily limit to cap Oct 10 at %d, got %d", MaxDailyRegularReports, got)
+	}
+	if repo.report.ActivityCount != 4 || repo.report.TotalPoints != 10+5+5+10 {
+		t.Fatalf("unexpected report totals: count=%d points=%d", repo.report.ActivityCount, repo.report.TotalPoints)
+	}
+	if _, ok := repo.activities[1]; ok {
+		t.Fatal("activity from last season must not be tracked")
+	}
+	if got := repo.activities[5].Status; got != domain.StravaActivityRejected {
+		t.Fatalf("activity over the daily limit should be rejected, got %q", got)
+	}
+	if got := repo.activities[6].Status; got != domain.StravaActivitySkipped {
+		t.Fatalf("unsupported type should be skipped, got %q", got)
+	}
+
+	again, err := uc.Backfill(context.Background(), "628111", time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC), now)
+	if err != nil {
+		t.Fatalf("second Backfill: %v", err)
+	}
+	if again.Imported != 0 || repo.report.ActivityCount != 4 {
+		t.Fatalf("second backfill must not import again, got %+v (count %d)", again, repo.report.ActivityCount)
+	}
+}
+
+func TestSyncStrava_RetryReportsActivityOfCrashedClaim(t *testing.T) {
+	now := time.Now()
+	today := domain.GetToday(now)
+	srv := newStravaStubServer(t, []strava.Activity{stravaRun(1, today, "Run")})
+	account := domain.StravaAccount{UserID: "628111", AthleteID: 7, AccessToken: "token-1", ExpiresAt: now.Add(time.Hour)}
+	repo := &stravaSyncRepoStub{
+		stravaWebhookRepoStub: newStravaWebhookRepoStub(&cancelReportRepoStub{
+			report: &domain.Report{UserID: "628111", Name: "Budi"},
+		}),
+		perDay: map[string]int{},
+		quiet:  []domain.StravaAccount{account},
+	}
+	client := strava.NewClient(config.Config{StravaBaseURL: srv.URL})
+	uc := NewSyncStravaUsecase(repo, NewProcessStravaWebhookUsecase(repo, client, NewReportActivityUsecase(repo), ""))
+
+	// A run claimed the activity and crashed before reporting it.
+	claimedAt := now.Add(-domain.StravaClaimTimeout - time.Minute)
+	repo.activities[1] = domain.StravaActivity{ActivityID: 1, UserID: "628111", AthleteID: 7, Status: domain.StravaActivityPending, CreatedAt: claimedAt, UpdatedAt: claimedAt}
+
+	if _, err := uc.PollQuiet(context.Background(), now); err != nil {
+		t.Fatalf("PollQuiet: %v", err)
+	}
+	got := repo.activities[1]
+	if got.Status != domain.StravaActivityReported || got.EventKey == "" {
+		t.Fatalf("expected the stale claim to be reported on retry, got %+v", got)
+	}
+	if repo.report.ActivityCount != 1 {
+		t.Fatalf("expected one report, got %d", repo.report.ActivityCount)
+	}
+}
+
+func TestSyncStrava_BackfillRequiresLinkedAccount(t *testing.T) {
+	repo := &stravaSyncRepoStub{
+		stravaWebhookRepoStub: newStravaWebhookRepoStub(&cancelReportRepoStub{}),
+		perDay:                map[string]int{},
+	}
+	uc := NewSyncStravaUsecase(repo, NewProcessStravaWebhookUsecase(repo, nil, nil, ""))
+	if _, err := uc.Backfill(context.Background(), "628111", time.Now(), time.Now()); err != ErrStravaNotLinked {
+		t.Fatalf("expected ErrStravaNotLinked, got %v", err)
+	}
+}
+
+func TestSyncStrava_PollSkipsHistory(t *testing.T) {
+	now := time.Now()
+	srv := newStravaStubServer(t, []strava.Activity{
+		stravaRun(1, domain.GetToday(now).AddDate(0, 0, -5), "Run"),
+	})
+	account := domain.StravaAccount{UserID: "628111", AthleteID: 7, AccessToken: "token-1", ExpiresAt: now.Add(time.Hour)}
+	repo := &stravaSyncRepoStub{
+		stravaWebhookRepoStub: newStravaWebhookRepoStub(&cancelReportRepoStub{
+			report: &domain.Report{UserID: "628111", Name: "Budi"},
+		}),
+		perDay: map[string]int{},
+		quiet:  []domain.StravaAccount{account},
+	}
+	client := strava.NewClient(config.Config{StravaBaseURL: srv.URL})
+	uc := NewSyncStravaUsecase(repo, NewProcessStravaWebhookUsecase(repo, client, NewReportActivityUsecase(repo), ""))
+
+	polled, err := uc.PollQuiet(context.Background(), now)
+	if err != nil {
+		t.Fatalf("PollQuiet: %v", err)
+	}
+	if polled != 1 || len(repo.polledIDs) != 1 {
+		t.Fatalf("expected one athlete polled, got %d (%v)", polled, repo.polledIDs)
+	}
+	if len(repo.activities) != 0 || repo.report.ActivityCount != 0 {
+		t.Fatal("polling must leave older activities to a backfill")
+	}
+}
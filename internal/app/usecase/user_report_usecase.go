@@ -0,0 +1,291 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// webReportSource is the source of reports filed from the web dashboard
+// or the mobile app.
+const webReportSource = "web"
+
+var (
+	ErrUserReportUnavailable = errors.New("report events are not supported by this repository")
+	ErrUserReportNotFound    = errors.New("report not found")
+	ErrUserReportCancelled   = errors.New("report was already cancelled")
+	ErrUserReportInvalid     = errors.New("invalid report")
+	// ErrUserReportNotToday means the report is from an earlier day. Like
+	// /cancel, the web only undoes today's reports: an older one has fed
+	// streaks and weekly goals since.
+	ErrUserReportNotToday = errors.New("only today's reports can be cancelled")
+)
+
+// UserReportNotifier posts a web or app report to the group chat.
+type UserReportNotifier func(ctx context.Context, message string)
+
+// UserReportBadge is a badge unlocked by a report.
+type UserReportBadge struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Emoji    string `json:"emoji"`
+	Points   int    `json:"points"`
+	Comeback bool   `json:"comeback"`
+}
+
+// UserReportResult is the outcome of a report filed through the API.
+// Accepted is false when the report rules turned it down, e.g. the day's
+// limit was reached; Rejection is then the reason code and Reply explains
+// it in the bot's words.
+type UserReportResult struct {
+	Accepted       bool              `json:"accepted"`
+	Rejection      string            `json:"rejection,omitempty"`
+	Reply          string            `json:"reply,omitempty"`
+	EventID        string            `json:"event_id,omitempty"`
+	Kind           string            `json:"kind"`
+	ActivityDate   string            `json:"activity_date,omitempty"`
+	Points         int               `json:"points"`
+	Breakdown      ReportPoints      `json:"breakdown"`
+	Attributes     []AttributeGain   `json:"attributes"`
+	TotalPoints    int               `json:"total_points"`
+	SeasonalPoints int               `json:"seasonal_points"`
+	Level          int               `json:"level"`
+	PreviousLevel  int               `json:"previous_level"`
+	Tier           string            `json:"tier,omitempty"`
+	PreviousTier   string            `json:"previous_tier,omitempty"`
+	Rank           string            `json:"rank,omitempty"`
+	PreviousRank   string            `json:"previous_rank,omitempty"`
+	Streak         int               `json:"streak"`
+	FreezeUsed     bool              `json:"freeze_used"`
+	GoalCompleted  bool              `json:"goal_completed"`
+	Badges         []UserReportBadge `json:"badges"`
+}
+
+// UserSideQuestEntry is one side quest the user says they finished, e.g.
+// {"task": "pushup", "amount": 30}.
+type UserSideQuestEntry struct {
+	Task   string  `json:"task"`
+	Amount float64 `json:"amount"`
+}
+
+// UserCancelResult is what is left after a report was cancelled.
+type UserCancelResult struct {
+	EventID        string `json:"event_id"`
+	Kind           string `json:"kind"`
+	ActivityDate   string `json:"activity_date"`
+	Remaining      int    `json:"remaining"` // reports of Kind still on ActivityDate
+	TotalPoints    int    `json:"total_points"`
+	SeasonalPoints int    `json:"seasonal_points"`
+}
+
+// UserReportUsecase files and cancels reports for the web dashboard and
+// the mobile app. It goes through the same usecases as the WhatsApp
+// commands, so scoring, limits and hooks are identical.
+type UserReportUsecase struct {
+	repo     domain.ReportRepository
+	reportUC *ReportActivityUsecase
+	questUC  *DailyQuestUsecase
+	cancelUC *CancelReportUsecase
+	notifier UserReportNotifier
+}
+
+func NewUserReportUsecase(repo domain.ReportRepository, reportUC *ReportActivityUsecase, questUC *DailyQuestUsecase, cancelUC *CancelReportUsecase) *UserReportUsecase {
+	return &UserReportUsecase{repo: repo, reportUC: reportUC, questUC: questUC, cancelUC: cancelUC}
+}
+
+// SetNotifier sets the hook that keeps the group chat posted about reports
+// made outside WhatsApp. Without one, they only show up on the dashboard.
+func (u *UserReportUsecase) SetNotifier(fn UserReportNotifier) {
+	u.notifier = fn
+}
+
+// Report files a regular report for today, like /lapor with activityText.
+func (u *UserReportUsecase) Report(ctx context.Context, userID, activityText string, now time.Time) (UserReportResult, error) {
+	name, err := u.userName(ctx, userID)
+	if err != nil {
+		return UserReportResult{}, err
+	}
+	activityText = strings.TrimSpace(activityText)
+
+	outcome, err := u.reportUC.execute(ctx, userID, name, nil, reportActivityOptions{
+		activityText: activityText,
+		now:          now,
+		source:       webReportSource,
+	})
+	if err != nil {
+		return UserReportResult{}, err
+	}
+	result := userReportResult(outcome, RenderReportOutcome(outcome))
+	if result.Accepted {
+		message := fmt.Sprintf("📲 %s lapor lewat web: +%d pts (Lv.%d)", outcome.Name, result.Points, result.Level)
+		if activityText != "" {
+			message += fmt.Sprintf("\n📝 %s", activityText)
+		}
+		u.notify(ctx, message)
+	}
+	return result, nil
+}
+
+// SideQuest reports finished side quests for today, like one
+// /lapor sidequest line per entry.
+func (u *UserReportUsecase) SideQuest(ctx context.Context, userID string, entries []UserSideQuestEntry, now time.Time) (UserReportResult, error) {
+	var lines []string
+	for _, entry := range entries {
+		task := strings.TrimSpace(entry.Task)
+		if task == "" || entry.Amount <= 0 {
+			return UserReportResult{}, fmt.Errorf("%w: each side quest needs a task and a positive amount", ErrUserReportInvalid)
+		}
+		lines = append(lines, task+" "+strconv.FormatFloat(entry.Amount, 'f', -1, 64))
+	}
+	if len(lines) == 0 {
+		return UserReportResult{}, fmt.Errorf("%w: at least one side quest is required", ErrUserReportInvalid)
+	}
+	name, err := u.userName(ctx, userID)
+	if err != nil {
+		return UserReportResult{}, err
+	}
+
+	outcome, err := u.questUC.updateProgress(ctx, userID, name, lines, u.reportUC, now, reportActivityOptions{
+		source: webReportSource,
+	})
+	if err != nil {
+		return UserReportResult{}, err
+	}
+	var result UserReportResult
+	if outcome.Accepted {
+		result = userReportResult(outcome.Report, RenderSideQuestOutcome(outcome))
+	} else {
+		result = UserReportResult{
+			Rejection:  outcome.Rejection,
+			Reply:      RenderSideQuestOutcome(outcome),
+			Kind:       domain.ActivityKindSideQuest,
+			Attributes: []AttributeGain{},
+			Badges:     []UserReportBadge{},
+		}
+	}
+	if result.Accepted {
+		u.notify(ctx, fmt.Sprintf("🧩 %s menyelesaikan side quest lewat web: +%d pts (Lv.%d)", outcome.Report.Name, result.Points, result.Level))
+	}
+	return result, nil
+}
+
+// Cancel undoes the report behind one of today's report events of the user,
+// like /cancel. The event is marked cancelled and can't be cancelled twice.
+func (u *UserReportUsecase) Cancel(ctx context.Context, userID, eventID string) (UserCancelResult, error) {
+	events, ok := u.repo.(domain.ReportEventRepository)
+	if !ok {
+		return UserCancelResult{}, ErrUserReportUnavailable
+	}
+	// The checks below must still hold when the report is undone.
+	ctx, unlock := u.cancelUC.lockUser(ctx, userID)
+	defer unlock()
+
+	event, err := events.GetReportEvent(ctx, userID, eventID)
+	if err != nil {
+		return UserCancelResult{}, err
+	}
+	if event == nil {
+		return UserCancelResult{}, ErrUserReportNotFound
+	}
+	if !event.CancelledAt.IsZero() {
+		return UserCancelResult{}, ErrUserReportCancelled
+	}
+	if event.ActivityDate.Format(time.DateOnly) != domain.GetToday(time.Now()).Format(time.DateOnly) {
+		return UserCancelResult{}, ErrUserReportNotToday
+	}
+	before, err := u.repo.GetDailyActivityCountByKind(ctx, userID, event.ActivityDate, event.Kind)
+	if err != nil {
+		return UserCancelResult{}, err
+	}
+	if before == 0 {
+		// The day's reports were already undone with /cancel-all before
+		// cancellations were tracked per event.
+		return UserCancelResult{}, ErrUserReportCancelled
+	}
+
+	name, err := u.userName(ctx, userID)
+	if err != nil {
+		return UserCancelResult{}, err
+	}
+	// A side quest event can cover several quests at once; when it covers
+	// all of the day's, clear the day rather than only the latest quest.
+	all := event.Kind == domain.ActivityKindSideQuest && event.SideQuestCountDelta >= before
+	outcome, err := u.cancelUC.cancelOn(ctx, userID, name, event.Kind, event.ActivityDate, all, event.EventID)
+	if err != nil {
+		return UserCancelResult{}, err
+	}
+	if outcome.Cancelled == 0 || outcome.Report == nil {
+		return UserCancelResult{}, ErrUserReportCancelled
+	}
+	u.notify(ctx, fmt.Sprintf("↩️ %s membatalkan %s tanggal %s lewat web.", outcome.Name, cancelItemLabel(event.Kind), event.ActivityDate.Format("02 Jan 2006")))
+	return UserCancelResult{
+		EventID:        event.EventID,
+		Kind:           event.Kind,
+		ActivityDate:   event.ActivityDate.Format(time.DateOnly),
+		Remaining:      outcome.Remaining,
+		TotalPoints:    outcome.Report.TotalPoints,
+		SeasonalPoints: outcome.Report.SeasonalPoints,
+	}, nil
+}
+
+func (u *UserReportUsecase) userName(ctx context.Context, userID string) (string, error) {
+	report, err := u.repo.GetReport(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if report == nil {
+		return userID, nil
+	}
+	return report.Name, nil
+}
+
+func (u *UserReportUsecase) notify(ctx context.Context, message string) {
+	if u.notifier != nil {
+		u.notifier(ctx, message)
+	}
+}
+
+// userReportResult turns a report outcome into the API's result, with
+// reply as the bot would have put it.
+func userReportResult(o ReportOutcome, reply string) UserReportResult {
+	result := UserReportResult{
+		Accepted:   o.Accepted,
+		Rejection:  o.Rejection,
+		Kind:       o.Kind,
+		Attributes: []AttributeGain{},
+		Badges:     []UserReportBadge{},
+	}
+	if !o.Accepted || o.Report == nil {
+		result.Reply = reply
+		return result
+	}
+	report := o.Report
+	result.EventID = o.EventKey
+	result.ActivityDate = o.ActivityDate.Format(time.DateOnly)
+	result.Points = o.Points.Total
+	result.Breakdown = o.Points
+	result.Attributes = append(result.Attributes, o.AttributeGains...)
+	result.TotalPoints = report.TotalPoints
+	result.SeasonalPoints = report.SeasonalPoints
+	result.Level = report.Level
+	result.PreviousLevel = o.OldLevel
+	result.Tier = o.NewTier().Name
+	result.PreviousTier = o.OldTier.Name
+	result.Rank = o.NewRank().Name
+	result.PreviousRank = o.OldRank.Name
+	result.Streak = report.Streak
+	result.FreezeUsed = o.FreezeUsed
+	result.GoalCompleted = o.GoalCompleted
+	for _, ach := range o.Badges {
+		result.Badges = append(result.Badges, UserReportBadge{ID: ach.ID, Name: ach.Name, Emoji: ach.DisplayEmoji, Points: ach.Points})
+	}
+	for _, ach := range o.ComebackBadges {
+		result.Badges = append(result.Badges, UserReportBadge{ID: ach.ID, Name: ach.Name, Emoji: ach.DisplayEmoji, Points: ach.Points, Comeback: true})
+	}
+	return result
+}
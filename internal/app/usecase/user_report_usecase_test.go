@@ -0,0 +1,278 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+type userReportRepo struct {
+	*mockReportRepo
+	events map[string]domain.ReportActivityEvent
+	// eventReadDelay holds event lookups up, so racing requests all read
+	// the event before any of them acts on it.
+	eventReadDelay time.Duration
+}
+
+func newUserReportRepo() *userReportRepo {
+	return &userReportRepo{
+		mockReportRepo: &mockReportRepo{reports: make(map[string]*domain.Report), dailyCountByKind: map[string]int{}},
+		events:         map[string]domain.ReportActivityEvent{},
+	}
+}
+
+func (r *userReportRepo) UpsertReportWithActivityEvent(ctx context.Context, report *domain.Report, event domain.ReportActivityEvent) error {
+	r.reports[report.UserID] = report
+	if _, ok := r.events[event.EventID]; ok {
+		return nil
+	}
+	r.events[event.EventID] = event
+	r.dailyCountByKind[event.Kind] += event.RegularCountDelta + event.SideQuestCountDelta
+	return nil
+}
+
+func (r *userReportRepo) UpsertReportWithActivityKind(ctx context.Context, report *domain.Report, activityDate time.Time, kind string) error {
+	r.reports[report.UserID] = report
+	r.dailyCountByKind[kind]++
+	return nil
+}
+
+func (r *userReportRepo) GetUserActivityDatesByKind(ctx context.Context, userID string, kind string) ([]time.Time, error) {
+	var dates []time.Time
+	for _, event := range r.events {
+		if event.UserID == userID && event.Kind == kind {
+			dates = append(dates, event.ActivityDate)
+		}
+	}
+	return dates, nil
+}
+
+func (r *userReportRepo) GetReportEvent(ctx context.Context, userID, eventID string) (*domain.ReportActivityEvent, error) {
+	event, ok := r.events[eventID]
+	time.Sleep(r.eventReadDelay)
+	if !ok || event.UserID != userID {
+		return nil, nil
+	}
+	return &event, nil
+}
+
+func (r *userReportRepo) CancelReportEvent(ctx context.Context, userID, eventID string, at time.Time) (bool, error) {
+	event, ok := r.events[eventID]
+	if !ok || event.UserID != userID || !event.CancelledAt.IsZero() {
+		return false, nil
+	}
+	event.CancelledAt = at
+	r.events[eventID] = event
+	return true, nil
+}
+
+func (r *userReportRepo) CancelLatestReportEvents(ctx context.Context, userID, kind string, activityDate time.Time, limit int, at time.Time) ([]string, error) {
+	return nil, nil
+}
+
+func newTestUserReportUsecase(repo *userReportRepo) (*usecase.UserReportUsecase, *[]string) {
+	reportUC := usecase.NewReportActivityUsecase(repo)
+	cancelUC := usecase.NewCancelReportUsecase(repo)
+	cancelUC.SetUserLock(reportUC.UserLock)
+	uc := usecase.NewUserReportUsecase(repo, reportUC, usecase.NewDailyQuestUsecase(repo), cancelUC)
+	var posted []string
+	uc.SetNotifier(func(ctx context.Context, message string) {
+		posted = append(posted, message)
+	})
+	return uc, &posted
+}
+
+func TestUserReport_ReportReturnsStructuredResult(t *testing.T) {
+	repo := newUserReportRepo()
+	repo.reports["628111"] = &domain.Report{UserID: "628111", Name: "Budi", StreakFreezes: 1}
+	uc, posted := newTestUserReportUsecase(repo)
+
+	result, err := uc.Report(context.Background(), "628111", "lari 5 km", time.Now())
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if !result.Accepted || result.EventID == "" || result.Reply != "" {
+		t.Fatalf("report should be accepted without a reply, got %+v", result)
+	}
+	if result.Kind != domain.ActivityKindRegularReport || result.Points <= 0 || result.TotalPoints != repo.reports["628111"].TotalPoints {
+		t.Fatalf("unexpected points in %+v", result)
+	}
+	if result.Level < result.PreviousLevel || result.Rank == "" || result.Tier == "" {
+		t.Fatalf("level and rank should be filled in, got %+v", result)
+	}
+	if len(*posted) != 1 || !strings.Contains((*posted)[0], "Budi") || !strings.Contains((*posted)[0], "lari 5 km") {
+		t.Fatalf("group should hear about the web report, got %q", *posted)
+	}
+}
+
+func TestUserReport_RejectedReportKeepsReply(t *testing.T) {
+	repo := newUserReportRepo()
+	repo.reports["628111"] = &domain.Report{UserID: "628111", Name: "Budi"}
+	repo.dailyCountByKind[domain.ActivityKindRegularReport] = usecase.MaxDailyRegularReports
+	uc, posted := newTestUserReportUsecase(repo)
+
+	result, err := uc.Report(context.Background(), "628111", "", time.Now())
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if result.Accepted || result.EventID != "" || !strings.Contains(result.Reply, "batas") {
+		t.Fatalf("a full day should be rejected with the bot's reason, got %+v", result)
+	}
+	if len(*posted) != 0 {
+		t.Fatalf("rejected reports must not be announced, got %q", *posted)
+	}
+}
+
+func TestUserReport_SideQuestValidatesEntries(t *testing.T) {
+	uc, _ := newTestUserReportUsecase(newUserReportRepo())
+	for _, entries := range [][]usecase.UserSideQuestEntry{nil, {{Task: "pushup"}}, {{Task: " ", Amount: 10}}} {
+		if _, err := uc.SideQuest(context.Background(), "628111", entries, time.Now()); !errors.Is(err, usecase.ErrUserReportInvalid) {
+			t.Fatalf("entries %+v: got %v", entries, err)
+		}
+	}
+}
+
+func TestUserReport_CancelByEventID(t *testing.T) {
+	repo := newUserReportRepo()
+	repo.reports["628111"] = &domain.Report{UserID: "628111", Name: "Budi", StreakFreezes: 1}
+	uc, posted := newTestUserReportUsecase(repo)
+	ctx := context.Background()
+
+	first, err := uc.Report(ctx, "628111", "lari", time.Now())
+	if err != nil || !first.Accepted {
+		t.Fatalf("first report = %+v, %v", first, err)
+	}
+	second, err := uc.Report(ctx, "628111", "renang", time.Now().Add(time.Second))
+	if err != nil || !second.Accepted {
+		t.Fatalf("second report = %+v, %v", second, err)
+	}
+	*posted = nil
+
+	if _, err := uc.Cancel(ctx, "628222", first.EventID); !errors.Is(err, usecase.ErrUserReportNotFound) {
+		t.Fatalf("another user's event: got %v", err)
+	}
+
+	result, err := uc.Cancel(ctx, "628111", first.EventID)
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if result.Remaining != 1 || result.ActivityDate != first.ActivityDate || result.TotalPoints != second.TotalPoints-first.Points {
+		t.Fatalf("cancelling the first report should take back its own points, got %+v (first %+v, second %+v)", result, first, second)
+	}
+	if repo.events[first.EventID].CancelledAt.IsZero() || !repo.events[second.EventID].CancelledAt.IsZero() {
+		t.Fatal("only the requested event should be marked cancelled")
+	}
+	if len(*posted) != 1 || !strings.Contains((*posted)[0], "membatalkan") {
+		t.Fatalf("group should hear about the cancellation, got %q", *posted)
+	}
+
+	if _, err := uc.Cancel(ctx, "628111", first.EventID); !errors.Is(err, usecase.ErrUserReportCancelled) {
+		t.Fatalf("second cancel: got %v", err)
+	}
+}
+
+func TestUserReport_ParallelCancelsUndoOnce(t *testing.T) {
+	repo := newUserReportRepo()
+	repo.reports["628111"] = &domain.Report{UserID: "628111", Name: "Budi", StreakFreezes: 1}
+	uc, _ := newTestUserReportUsecase(repo)
+	ctx := context.Background()
+
+	first, err := uc.Report(ctx, "628111", "lari", time.Now())
+	if err != nil || !first.Accepted {
+		t.Fatalf("first report = %+v, %v", first, err)
+	}
+	second, err := uc.Report(ctx, "628111", "renang", time.Now().Add(time.Second))
+	if err != nil || !second.Accepted {
+		t.Fatalf("second report = %+v, %v", second, err)
+	}
+
+	// Two tabs cancel the same report at once.
+	repo.eventReadDelay = 20 * time.Millisecond
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	start := make(chan struct{})
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, errs[i] = uc.Cancel(ctx, "628111", first.EventID)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	cancelled := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			cancelled++
+		case !errors.Is(err, usecase.ErrUserReportCancelled):
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if cancelled != 1 {
+		t.Fatalf("exactly one cancel should go through, got %d (%v)", cancelled, errs)
+	}
+	if got := repo.dailyCountByKind[domain.ActivityKindRegularReport]; got != 1 {
+		t.Fatalf("only one activity log should be deleted, %d left", got)
+	}
+	if got, want := repo.reports["628111"].TotalPoints, second.TotalPoints-first.Points; got != want {
+		t.Fatalf("the report's points should be taken back once: got %d, want %d", got, want)
+	}
+}
+
+func TestUserReport_CancelOnlyToday(t *testing.T) {
+	repo := newUserReportRepo()
+	repo.reports["628111"] = &domain.Report{UserID: "628111", Name: "Budi", TotalPoints: 30}
+	repo.dailyCountByKind[domain.ActivityKindRegularReport] = 1
+	yesterday := domain.GetToday(time.Now()).AddDate(0, 0, -1)
+	repo.events["evt-yesterday"] = domain.ReportActivityEvent{
+		EventID:           "evt-yesterday",
+		UserID:            "628111",
+		Kind:              domain.ActivityKindRegularReport,
+		ActivityDate:      yesterday,
+		PointsDelta:       30,
+		RegularCountDelta: 1,
+	}
+	uc, posted := newTestUserReportUsecase(repo)
+
+	if _, err := uc.Cancel(context.Background(), "628111", "evt-yesterday"); !errors.Is(err, usecase.ErrUserReportNotToday) {
+		t.Fatalf("cancelling an earlier day's report: got %v", err)
+	}
+	if repo.reports["628111"].TotalPoints != 30 || !repo.events["evt-yesterday"].CancelledAt.IsZero() || len(*posted) != 0 {
+		t.Fatal("an earlier day's report must be left alone")
+	}
+}
+
+func TestUserReport_CancelSideQuestEventTakesBackItsQuests(t *testing.T) {
+	repo := newUserReportRepo()
+	repo.reports["628111"] = &domain.Report{UserID: "628111", Name: "Budi", TotalPoints: 50, SeasonalPoints: 50, TotalSideQuests: 3, SeasonalSideQuests: 3}
+	repo.dailyCountByKind[domain.ActivityKindSideQuest] = 3
+	season, _ := usecase.GetCurrentSessionInfo(time.Now())
+	repo.events["evt-quests"] = domain.ReportActivityEvent{
+		EventID:             "evt-quests",
+		UserID:              "628111",
+		SeasonNumber:        season,
+		Kind:                domain.ActivityKindSideQuest,
+		ActivityDate:        domain.GetToday(time.Now()),
+		PointsDelta:         10,
+		SideQuestCountDelta: 2,
+	}
+	uc, _ := newTestUserReportUsecase(repo)
+
+	result, err := uc.Cancel(context.Background(), "628111", "evt-quests")
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	report := repo.reports["628111"]
+	if result.Remaining != 1 || report.TotalSideQuests != 1 || result.TotalPoints != 40 || result.SeasonalPoints != 40 {
+		t.Fatalf("both of the event's quests and its points should go, got %+v (report %+v)", result, report)
+	}
+}
@@ -0,0 +1,365 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/webhook"
+)
+
+const (
+	// webhookMaxAttempts is how many times a delivery is tried before it is
+	// dead-lettered. With the backoff below that spans about a day.
+	webhookMaxAttempts = 10
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = 6 * time.Hour
+	webhookBatchSize   = 20
+	webhookListLimit   = 100
+)
+
+var (
+	ErrWebhookUnavailable     = errors.New("webhooks are not supported by this repository")
+	ErrWebhookNotFound        = errors.New("webhook not found")
+	ErrWebhookExists          = errors.New("webhook already exists")
+	ErrWebhookInvalidID       = errors.New("webhook ID must be 2-40 lowercase letters, digits or dashes")
+	ErrWebhookInvalidURL      = errors.New("webhook URL must be an absolute http or https URL")
+	ErrWebhookUnknownEvent    = errors.New("unknown webhook event type")
+	errWebhookSubscriptionOff = errors.New("subscription is disabled or deleted")
+)
+
+// WebhookUpdate changes a subscription. A nil field keeps the current value;
+// an empty Events list subscribes to every event type.
+type WebhookUpdate struct {
+	URL     *string  `json:"url"`
+	Events  []string `json:"events"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// WebhookUsecase manages outbound webhook subscriptions and delivers bot
+// events to them. Publish only queues deliveries; DeliverDue sends them
+// with retries, so a slow or failing endpoint never holds up a report.
+type WebhookUsecase struct {
+	repo   domain.ReportRepository
+	client *webhook.Client
+}
+
+func NewWebhookUsecase(repo domain.ReportRepository, client *webhook.Client) *WebhookUsecase {
+	return &WebhookUsecase{repo: repo, client: client}
+}
+
+func (u *WebhookUsecase) webhooks() (domain.WebhookRepository, error) {
+	webhooks, ok := u.repo.(domain.WebhookRepository)
+	if !ok {
+		return nil, ErrWebhookUnavailable
+	}
+	return webhooks, nil
+}
+
+func (u *WebhookUsecase) List(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	webhooks, err := u.webhooks()
+	if err != nil {
+		return nil, err
+	}
+	return webhooks.ListWebhookSubscriptions(ctx)
+}
+
+// Create registers a subscription and returns its signing secret. The
+// secret is only shown here and on rotation.
+func (u *WebhookUsecase) Create(ctx context.Context, id, rawURL string, events []string, now time.Time) (domain.WebhookSubscription, string, error) {
+	webhooks, err := u.webhooks()
+	if err != nil {
+		return domain.WebhookSubscription{}, "", err
+	}
+	id = strings.ToLower(strings.TrimSpace(id))
+	if !integrationIDPattern.MatchString(id) {
+		return domain.WebhookSubscription{}, "", ErrWebhookInvalidID
+	}
+	endpoint, err := normalizeWebhookURL(rawURL)
+	if err != nil {
+		return domain.WebhookSubscription{}, "", err
+	}
+	events, err = normalizeWebhookEvents(events)
+	if err != nil {
+		return domain.WebhookSubscription{}, "", err
+	}
+	existing, err := webhooks.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		return domain.WebhookSubscription{}, "", err
+	}
+	if existing != nil {
+		return domain.WebhookSubscription{}, "", ErrWebhookExists
+	}
+
+	secret, err := newIntegrationSecret()
+	if err != nil {
+		return domain.WebhookSubscription{}, "", err
+	}
+	sub := domain.WebhookSubscription{
+		ID:        id,
+		URL:       endpoint,
+		Secret:    secret,
+		Events:    events,
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := webhooks.CreateWebhookSubscription(ctx, sub); err != nil {
+		return domain.WebhookSubscription{}, "", err
+	}
+	return sub, secret, nil
+}
+
+func (u *WebhookUsecase) Update(ctx context.Context, id string, update WebhookUpdate, now time.Time) (domain.WebhookSubscription, error) {
+	webhooks, sub, err := u.get(ctx, id)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	if update.URL != nil {
+		if sub.URL, err = normalizeWebhookURL(*update.URL); err != nil {
+			return domain.WebhookSubscription{}, err
+		}
+	}
+	if update.Events != nil {
+		if sub.Events, err = normalizeWebhookEvents(update.Events); err != nil {
+			return domain.WebhookSubscription{}, err
+		}
+	}
+	if update.Enabled != nil {
+		sub.Enabled = *update.Enabled
+	}
+	sub.UpdatedAt = now
+	if err := webhooks.UpdateWebhookSubscription(ctx, *sub); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	return *sub, nil
+}
+
+// Rotate issues a new signing secret. Deliveries still in the queue are
+// signed with it from their next attempt on.
+func (u *WebhookUsecase) Rotate(ctx context.Context, id string, now time.Time) (domain.WebhookSubscription, string, error) {
+	webhooks, sub, err := u.get(ctx, id)
+	if err != nil {
+		return domain.WebhookSubscription{}, "", err
+	}
+	secret, err := newIntegrationSecret()
+	if err != nil {
+		return domain.WebhookSubscription{}, "", err
+	}
+	sub.Secret = secret
+	sub.UpdatedAt = now
+	if err := webhooks.UpdateWebhookSubscription(ctx, *sub); err != nil {
+		return domain.WebhookSubscription{}, "", err
+	}
+	return *sub, secret, nil
+}
+
+func (u *WebhookUsecase) Delete(ctx context.Context, id string) error {
+	webhooks, _, err := u.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return webhooks.DeleteWebhookSubscription(ctx, id)
+}
+
+func (u *WebhookUsecase) get(ctx context.Context, id string) (domain.WebhookRepository, *domain.WebhookSubscription, error) {
+	webhooks, err := u.webhooks()
+	if err != nil {
+		return nil, nil, err
+	}
+	sub, err := webhooks.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sub == nil {
+		return nil, nil, ErrWebhookNotFound
+	}
+	return webhooks, sub, nil
+}
+
+func normalizeWebhookURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", ErrWebhookInvalidURL
+	}
+	return raw, nil
+}
+
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, event := range events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if event == "" || seen[event] {
+			continue
+		}
+		if !domain.IsBotEventType(event) {
+			return nil, fmt.Errorf("%w: %s", ErrWebhookUnknownEvent, event)
+		}
+		seen[event] = true
+		normalized = append(normalized, event)
+	}
+	return normalized, nil
+}
+
+// Publish queues the event for every enabled subscription that wants it.
+// It has the BotEventPublisher signature; failures are logged because the
+// report that raised the event has already been saved.
+func (u *WebhookUsecase) Publish(ctx context.Context, event domain.BotEvent) {
+	if _, err := u.Enqueue(ctx, event); err != nil && !errors.Is(err, ErrWebhookUnavailable) {
+		log.Printf("Failed to queue %s webhooks: %v", event.Type, err)
+	}
+}
+
+// Enqueue queues the event and returns how many deliveries were created.
+func (u *WebhookUsecase) Enqueue(ctx context.Context, event domain.BotEvent) (int, error) {
+	webhooks, err := u.webhooks()
+	if err != nil {
+		return 0, err
+	}
+	subs, err := webhooks.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var wanted []domain.WebhookSubscription
+	for _, sub := range subs {
+		if sub.Wants(event.Type) {
+			wanted = append(wanted, sub)
+		}
+	}
+	if len(wanted) == 0 {
+		return 0, nil
+	}
+
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	event.OccurredAt = event.OccurredAt.UTC()
+	if event.ID == "" {
+		if event.ID, err = newBotEventID(); err != nil {
+			return 0, err
+		}
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+	}
+
+	deliveries := make([]domain.WebhookDelivery, 0, len(wanted))
+	for _, sub := range wanted {
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        string(payload),
+			CreatedAt:      event.OccurredAt,
+		})
+	}
+	if err := webhooks.EnqueueWebhookDeliveries(ctx, deliveries); err != nil {
+		return 0, err
+	}
+	return len(deliveries), nil
+}
+
+func newBotEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "evt_" + hex.EncodeToString(b), nil
+}
+
+// DeliverDue sends every delivery that is due and returns how many
+// succeeded. A failed delivery is retried with backoff and dead-lettered
+// after webhookMaxAttempts; a delivery for a disabled subscription is
+// dead-lettered right away so it can be replayed once it is enabled again.
+func (u *WebhookUsecase) DeliverDue(ctx context.Context, now time.Time) (int, error) {
+	webhooks, err := u.webhooks()
+	if err != nil {
+		return 0, err
+	}
+	due, err := webhooks.GetDueWebhookDeliveries(ctx, now, webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	subs := map[string]*domain.WebhookSubscription{}
+	delivered := 0
+	for _, d := range due {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		sub, cached := subs[d.SubscriptionID]
+		if !cached {
+			if sub, err = webhooks.GetWebhookSubscription(ctx, d.SubscriptionID); err != nil {
+				return delivered, err
+			}
+			subs[d.SubscriptionID] = sub
+		}
+
+		statusCode, err := 0, errWebhookSubscriptionOff
+		if sub != nil && sub.Enabled {
+			statusCode, err = u.client.Send(ctx, webhook.Request{
+				URL:       sub.URL,
+				Secret:    sub.Secret,
+				EventID:   d.EventID,
+				EventType: d.EventType,
+				Payload:   []byte(d.Payload),
+			}, now)
+		}
+		if err != nil {
+			attempts := d.Attempts + 1
+			dead := attempts >= webhookMaxAttempts || errors.Is(err, errWebhookSubscriptionOff)
+			next := now.Add(WebhookBackoff(attempts))
+			if dead {
+				log.Printf("Webhook delivery %d to %s dead-lettered after %d attempts: %v", d.ID, d.SubscriptionID, attempts, err)
+			} else {
+				log.Printf("Webhook delivery %d to %s failed (attempt %d), retrying at %s: %v", d.ID, d.SubscriptionID, attempts, next.Format(time.RFC3339), err)
+			}
+			if err := webhooks.MarkWebhookFailed(ctx, d.ID, attempts, statusCode, err.Error(), next, dead); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+		if err := webhooks.MarkWebhookDelivered(ctx, d.ID, statusCode, now); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// WebhookBackoff returns the delay before retry number attempts:
+// 30s, 1m, 2m, ... capped at six hours.
+func WebhookBackoff(attempts int) time.Duration {
+	return retryBackoff(attempts, webhookBaseBackoff, webhookMaxBackoff)
+}
+
+// Deliveries returns the newest deliveries for the admin log. Empty
+// filters match everything.
+func (u *WebhookUsecase) Deliveries(ctx context.Context, subscriptionID, status string) ([]domain.WebhookDelivery, error) {
+	webhooks, err := u.webhooks()
+	if err != nil {
+		return nil, err
+	}
+	return webhooks.ListWebhookDeliveries(ctx, subscriptionID, status, webhookListLimit)
+}
+
+// Replay queues a delivery again with a fresh retry budget. The event ID is
+// unchanged, so receivers that deduplicate will not process it twice.
+func (u *WebhookUsecase) Replay(ctx context.Context, id int64, now time.Time) (bool, error) {
+	webhooks, err := u.webhooks()
+	if err != nil {
+		return false, err
+	}
+	return webhooks.ReplayWebhookDelivery(ctx, id, now)
+}
@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/webhook"
+)
+
+type webhookRepoStub struct {
+	*cancelReportRepoStub
+	subs       map[string]domain.WebhookSubscription
+	deliveries map[int64]*domain.WebhookDelivery
+	nextID     int64
+}
+
+func newWebhookRepoStub(subs ...domain.WebhookSubscription) *webhookRepoStub {
+	r := &webhookRepoStub{
+		cancelReportRepoStub: &cancelReportRepoStub{},
+		subs:                 map[string]domain.WebhookSubscription{},
+		deliveries:           map[int64]*domain.WebhookDelivery{},
+	}
+	for _, sub := range subs {
+		r.subs[sub.ID] = sub
+	}
+	return r
+}
+
+func (r *webhookRepoStub) CreateWebhookSubscription(ctx context.Context, sub domain.WebhookSubscription) error {
+	r.subs[sub.ID] = sub
+	return nil
+}
+
+func (r *webhookRepoStub) GetWebhookSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	sub, ok := r.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &sub, nil
+}
+
+func (r *webhookRepoStub) ListWebhookSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	var out []domain.WebhookSubscription
+	for _, sub := range r.subs {
+		out = append(out, sub)
+	}
+	return out, nil
+}
+
+func (r *webhookRepoStub) UpdateWebhookSubscription(ctx context.Context, sub domain.WebhookSubscription) error {
+	r.subs[sub.ID] = sub
+	return nil
+}
+
+func (r *webhookRepoStub) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	delete(r.subs, id)
+	return nil
+}
+
+func (r *webhookRepoStub) EnqueueWebhookDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	for _, d := range deliveries {
+		r.nextID++
+		d.ID = r.nextID
+		d.Status = domain.WebhookDeliveryPending
+		d.NextAttemptAt = d.CreatedAt
+		r.deliveries[d.ID] = &d
+	}
+	return nil
+}
+
+func (r *webhookRepoStub) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	var out []domain.WebhookDelivery
+	for id := int64(1); id <= r.nextID; id++ {
+		d := r.deliveries[id]
+		if d.Status == domain.WebhookDeliveryPending && !d.NextAttemptAt.After(now) {
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (r *webhookRepoStub) MarkWebhookDelivered(ctx context.Context, id int64, statusCode int, deliveredAt time.Time) error {
+	d := r.deliveries[id]
+	d.Status = domain.WebhookDeliveryDelivered
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.DeliveredAt = deliveredAt
+	return nil
+}
+
+func (r *webhookRepoStub) MarkWebhookFailed(ctx context.Context, id int64, attempts, statusCode int, lastError string, nextAttemptAt time.Time, dead bool) error {
+	d := r.deliveries[id]
+	d.Attempts = attempts
+	d.LastStatusCode = statusCode
+	d.LastError = lastError
+	d.NextAttemptAt = nextAttemptAt
+	if dead {
+		d.Status = domain.WebhookDeliveryDead
+	}
+	return nil
+}
+
+func (r *webhookRepoStub) ListWebhookDeliveries(ctx context.Context, subscriptionID, status string, limit int) ([]domain.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (r *webhookRepoStub) ReplayWebhookDelivery(ctx context.Context, id int64, now time.Time) (bool, error) {
+	d, ok := r.deliveries[id]
+	if !ok {
+		return false, nil
+	}
+	d.Status = domain.WebhookDeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = now
+	return true, nil
+}
+
+func TestWebhook_EnqueueHonorsFiltersAndEnabled(t *testing.T) {
+	repo := newWebhookRepoStub(
+		domain.WebhookSubscription{ID: "all", URL: "http://a", Enabled: true},
+		domain.WebhookSubscription{ID: "goals", URL: "http://b", Events: []string{domain.BotEventGoalCompleted}, Enabled: true},
+		domain.WebhookSubscription{ID: "off", URL: "http://c", Enabled: false},
+	)
+	uc := NewWebhookUsecase(repo, nil)
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	n, err := uc.Enqueue(context.Background(), domain.BotEvent{Type: domain.BotEventReportAccepted, OccurredAt: now, UserID: "628111"})
+	if err != nil || n != 1 {
+		t.Fatalf("report.accepted should go to one subscription, got %d, %v", n, err)
+	}
+	n, err = uc.Enqueue(context.Background(), domain.BotEvent{Type: domain.BotEventGoalCompleted, OccurredAt: now, UserID: "628111"})
+	if err != nil || n != 2 {
+		t.Fatalf("goal.completed should go to two subscriptions, got %d, %v", n, err)
+	}
+
+	first, second := repo.deliveries[2], repo.deliveries[3]
+	if first.EventID == "" || first.EventID != second.EventID || first.Payload != second.Payload {
+		t.Fatalf("one event must share its ID and payload across subscriptions: %+v / %+v", first, second)
+	}
+	var event domain.BotEvent
+	if err := json.Unmarshal([]byte(first.Payload), &event); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if event.ID != first.EventID || event.Type != domain.BotEventGoalCompleted || event.UserID != "628111" {
+		t.Fatalf("unexpected payload: %+v", event)
+	}
+}
+
+func TestWebhook_CreateValidatesInput(t *testing.T) {
+	uc := NewWebhookUsecase(newWebhookRepoStub(), nil)
+	ctx := context.Background()
+	now := time.Now()
+
+	if _, _, err := uc.Create(ctx, "Slack Mirror", "https://example.com/hook", nil, now); !errors.Is(err, ErrWebhookInvalidID) {
+		t.Fatalf("expected ErrWebhookInvalidID, got %v", err)
+	}
+	if _, _, err := uc.Create(ctx, "slack", "ftp://example.com/hook", nil, now); !errors.Is(err, ErrWebhookInvalidURL) {
+		t.Fatalf("expected ErrWebhookInvalidURL, got %v", err)
+	}
+	if _, _, err := uc.Create(ctx, "slack", "https://example.com/hook", []string{"report.deleted"}, now); !errors.Is(err, ErrWebhookUnknownEvent) {
+		t.Fatalf("expected ErrWebhookUnknownEvent, got %v", err)
+	}
+
+	sub, secret, err := uc.Create(ctx, "slack", "https://example.com/hook", []string{" Level.Up ", "level.up", "rank.up"}, now)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(secret) != 64 || sub.Secret != secret || !sub.Enabled {
+		t.Fatalf("unexpected subscription: %+v", sub)
+	}
+	if len(sub.Events) != 2 || sub.Events[0] != domain.BotEventLevelUp || sub.Events[1] != domain.BotEventRankUp {
+		t.Fatalf("events should be normalized and deduplicated, got %v", sub.Events)
+	}
+	if _, _, err := uc.Create(ctx, "slack", "https://example.com/other", nil, now); !errors.Is(err, ErrWebhookExists) {
+		t.Fatalf("expected ErrWebhookExists, got %v", err)
+	}
+}
+
+func TestWebhook_DeliverDueSignsAndRetries(t *testing.T) {
+	var mu sync.Mutex
+	status := http.StatusInternalServerError
+	var got []*http.Request
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	repo := newWebhookRepoStub(domain.WebhookSubscription{ID: "sheet", URL: srv.URL, Secret: "s3cret", Enabled: true})
+	uc := NewWebhookUsecase(repo, webhook.NewClient())
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	if _, err := uc.Enqueue(ctx, domain.BotEvent{Type: domain.BotEventLevelUp, OccurredAt: now, UserID: "628111"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	delivered, err := uc.DeliverDue(ctx, now)
+	if err != nil || delivered != 0 {
+		t.Fatalf("a 500 must not count as delivered, got %d, %v", delivered, err)
+	}
+	d := repo.deliveries[1]
+	if d.Status != domain.WebhookDeliveryPending || d.Attempts != 1 || d.LastStatusCode != http.StatusInternalServerError || d.LastError == "" {
+		t.Fatalf("failed delivery should be rescheduled, got %+v", d)
+	}
+	if !d.NextAttemptAt.Equal(now.Add(WebhookBackoff(1))) {
+		t.Fatalf("next attempt = %s, want %s", d.NextAttemptAt, now.Add(WebhookBackoff(1)))
+	}
+	if delivered, _ := uc.DeliverDue(ctx, now.Add(time.Second)); delivered != 0 || len(got) != 1 {
+		t.Fatal("a delivery must wait for its backoff")
+	}
+
+	status = http.StatusNoContent
+	retryAt := d.NextAttemptAt
+	if delivered, err := uc.DeliverDue(ctx, retryAt); err != nil || delivered != 1 {
+		t.Fatalf("retry should succeed, got %d, %v", delivered, err)
+	}
+	if d.Status != domain.WebhookDeliveryDelivered || d.Attempts != 2 {
+		t.Fatalf("unexpected delivery after retry: %+v", d)
+	}
+
+	req, body := got[1], bodies[1]
+	if string(body) != string(bodies[0]) {
+		t.Fatal("retries must send the same payload")
+	}
+	if req.Header.Get(webhook.HeaderEvent) != domain.BotEventLevelUp || req.Header.Get(webhook.HeaderDelivery) != d.EventID {
+		t.Fatalf("unexpected headers: %v", req.Header)
+	}
+	timestamp := req.Header.Get(webhook.HeaderTimestamp)
+	if req.Header.Get(webhook.HeaderSignature) != SignIntegrationRequest("s3cret", timestamp, body) {
+		t.Fatal("signature should verify with the subscription secret")
+	}
+}
+
+func TestWebhook_DeliverDueDeadLetters(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	repo := newWebhookRepoStub(
+		domain.WebhookSubscription{ID: "flaky", URL: srv.URL, Secret: "x", Enabled: true},
+		domain.WebhookSubscription{ID: "paused", URL: srv.URL, Secret: "y", Enabled: true},
+	)
+	uc := NewWebhookUsecase(repo, webhook.NewClient())
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	if _, err := uc.Enqueue(ctx, domain.BotEvent{Type: domain.BotEventSeasonReset, OccurredAt: now}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	paused := repo.subs["paused"]
+	paused.Enabled = false
+	repo.subs["paused"] = paused
+
+	var flaky, pausedDelivery *domain.WebhookDelivery
+	for _, d := range repo.deliveries {
+		if d.SubscriptionID == "flaky" {
+			flaky = d
+		} else {
+			pausedDelivery = d
+		}
+	}
+	flaky.Attempts = webhookMaxAttempts - 1
+
+	if _, err := uc.DeliverDue(ctx, now); err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+	if flaky.Status != domain.WebhookDeliveryDead || flaky.Attempts != webhookMaxAttempts {
+		t.Fatalf("delivery out of retries should be dead-lettered, got %+v", flaky)
+	}
+	if pausedDelivery.Status != domain.WebhookDeliveryDead || pausedDelivery.LastStatusCode != 0 {
+		t.Fatalf("delivery for a disabled subscription should be dead-lettered without sending, got %+v", pausedDelivery)
+	}
+
+	if ok, err := uc.Replay(ctx, flaky.ID, now); err != nil || !ok || flaky.Status != domain.WebhookDeliveryPending || flaky.Attempts != 0 {
+		t.Fatalf("Replay = %v, %v (%+v)", ok, err, flaky)
+	}
+}
+
+func TestReportActivity_PublishesStreakBrokenAndAccepted(t *testing.T) {
+	now := time.Now()
+	repo := &cancelReportRepoStub{
+		report: &domain.Report{
+			UserID:         "628111",
+			Name:           "Budi",
+			Streak:         3,
+			MaxStreak:      3,
+			ActivityCount:  10,
+			LastReportDate: now.AddDate(0, 0, -30),
+		},
+	}
+	var events []domain.BotEvent
+	uc := NewReportActivityUsecase(repo)
+	uc.SetEventPublisher(func(ctx context.Context, event domain.BotEvent) {
+		events = append(events, event)
+	})
+
+	if _, err := uc.Execute(context.Background(), "628111", "Budi", nil); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(events) < 2 {
+		t.Fatalf("expected streak.broken and report.accepted, got %+v", events)
+	}
+	broken, accepted := events[0], events[1]
+	if broken.Type != domain.BotEventStreakBroken || broken.Data["previous_streak"] != 3 || broken.UserID != "628111" {
+		t.Fatalf("unexpected first event: %+v", broken)
+	}
+	if points, _ := accepted.Data["points"].(int); accepted.Type != domain.BotEventReportAccepted || points < baseReportPoints || accepted.Data["source"] != "whatsapp" {
+		t.Fatalf("unexpected report event: %+v", accepted)
+	}
+	if accepted.Data["event_key"] == "" {
+		t.Fatal("report event should carry the event key")
+	}
+}
+
+func TestCancelReport_PublishesCancelled(t *testing.T) {
+	repo := &cancelReportRepoStub{
+		report: &domain.Report{UserID: "user1", Name: "Budi", LastReportDate: time.Now(), TotalSideQuests: 2},
+		dailyCountByKind: map[string]int{
+			domain.ActivityKindSideQuest: 2,
+		},
+	}
+	var events []domain.BotEvent
+	uc := NewCancelReportUsecase(repo)
+	uc.SetEventPublisher(func(ctx context.Context, event domain.BotEvent) {
+		events = append(events, event)
+	})
+
+	if _, err := uc.ExecuteSideQuest(context.Background(), "user1", "Budi"); err != nil {
+		t.Fatalf("ExecuteSideQuest: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %+v", events)
+	}
+	e := events[0]
+	if e.Type != domain.BotEventReportCancelled || e.Data["kind"] != domain.ActivityKindSideQuest || e.Data["cancelled"] != 1 || e.Data["remaining"] != 1 {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/render"
 )
 
 type WeeklyHunterRanksAnnouncementUsecase struct {
@@ -64,3 +65,50 @@ func (u *WeeklyHunterRanksAnnouncementUsecase) Execute(ctx context.Context, now
 
 	return sb.String(), nil
 }
+
+// ExecuteCard draws the weekly announcement as a board card tinted with
+// each hunter's season rank. The card's Text is what Execute returns.
+func (u *WeeklyHunterRanksAnnouncementUsecase) ExecuteCard(ctx context.Context, now time.Time) (GroupCard, error) {
+	text, err := u.Execute(ctx, now)
+	if err != nil {
+		return GroupCard{}, err
+	}
+	reports, err := u.repo.GetAllReports(ctx)
+	if err != nil {
+		return GroupCard{}, fmt.Errorf("failed to get reports: %w", err)
+	}
+
+	seasonNumber, _ := GetCurrentSessionInfo(now)
+	nextReset := GetNextResetTime(now).Format("02-01-2006")
+
+	domain.SortReports(reports, domain.SortBySeasonRank)
+	active := domain.FilterReports(reports, domain.HasSeasonActivity)
+
+	rows, more := boardRows(active, func(rank int, r *domain.Report) render.BoardRow {
+		seasonRank := domain.GetSeasonRank(r.SeasonalPoints)
+		return render.BoardRow{
+			Rank:   rank,
+			Name:   r.Name,
+			Detail: fmt.Sprintf("%s, %s, %d hari", seasonRank.Name, domain.FormatJobClass(r.JobClass), r.SeasonalActivityCount),
+			Value:  formatCardPoints(r.SeasonalPoints),
+			Tier:   seasonRank.Tier,
+		}
+	})
+	card := render.BoardCard{
+		Title:    "Rank & Job Hunter",
+		Subtitle: fmt.Sprintf("Season %d, reset badge/rank %s", seasonNumber, nextReset),
+		Rows:     rows,
+		More:     more,
+		Empty:    "Belum ada hunter aktif season ini",
+		Footer:   groupCardSite,
+	}
+
+	return GroupCard{
+		Image: renderGroupCard("weekly ranks", func() ([]byte, error) {
+			return render.RenderBoard(card, render.SeasonTheme(seasonNumber))
+		}),
+		Caption: fmt.Sprintf("📢 *Rank & Job Hunter mingguan* — Season %d\nReset badge/rank: %s. Level & EXP lifetime tetap aman.%s",
+			seasonNumber, nextReset, groupCardLink),
+		Text: text,
+	}, nil
+}
@@ -1,7 +1,9 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
+	"image/png"
 	"strings"
 	"testing"
 	"time"
@@ -77,3 +79,27 @@ func TestWeeklyHunterRanksAnnouncement(t *testing.T) {
 		t.Errorf("expected output not to contain 'Inactive User', but it did. Got:\n%s", msg)
 	}
 }
+
+func TestWeeklyHunterRanksAnnouncementCard(t *testing.T) {
+	now := time.Date(2026, 6, 15, 7, 0, 0, 0, time.UTC)
+	repo := &mockWeeklyRanksRepo{reports: []*domain.Report{
+		{UserID: "user1", Name: "Alice", JobClass: "ranger", SeasonalPoints: 300, SeasonalActivityCount: 15},
+		{UserID: "user2", Name: "Bob", JobClass: "tank", SeasonalPoints: 120, SeasonalActivityCount: 6},
+	}}
+	uc := NewWeeklyHunterRanksAnnouncementUsecase(repo)
+
+	card, err := uc.ExecuteCard(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text, _ := uc.Execute(context.Background(), now)
+	if card.Text != text {
+		t.Errorf("card fallback should be the full announcement, got:\n%s", card.Text)
+	}
+	if _, err := png.DecodeConfig(bytes.NewReader(card.Image)); err != nil {
+		t.Fatalf("expected a PNG card: %v", err)
+	}
+	if strings.Contains(card.Caption, "Alice") || !strings.Contains(card.Caption, "Season 1") {
+		t.Errorf("caption should be a short season header, got %q", card.Caption)
+	}
+}
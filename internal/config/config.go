@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -22,9 +23,17 @@ type Config struct {
 	StravaClientID        string
 	StravaClientSecret    string
 	StravaVerifyToken     string
+	StravaBaseURL         string // Strava API host; empty means production
 	AppBaseURL            string
 	JWTSecret             string
-	JWTExpiryHours        int
+	AccessTokenMinutes    int      // Lifetime of a dashboard access token
+	RefreshTokenDays      int      // How long an unused dashboard session stays signed in
+	AnnounceRecords       bool     // Post new personal records to the group
+	AnnounceWebReports    bool     // Post reports made on the dashboard or app to the group
+	AdminPhones           []string // Phone numbers allowed to use /api/admin
+	TrustedProxies        []string // IPs or CIDRs whose X-Forwarded-For is believed
+	SecretKeys            []string // "id:base64key" keys for secrets at rest; first is active
+	AllowPlaintextSecrets bool     // Start without SECRET_KEYS, storing secrets unencrypted
 }
 
 func Load() Config {
@@ -44,7 +53,6 @@ func Load() Config {
 	stravaVerifyToken := getenv("STRAVA_VERIFY_TOKEN", "")
 	appBaseURL := getenv("APP_BASE_URL", "http://localhost:8080")
 	jwtSecret := getenv("JWT_SECRET", "")
-	jwtExpiryHours := getenvInt("JWT_EXPIRY_HOURS", 24)
 
 	if jwtSecret == "" {
 		log.Println("WARNING: JWT_SECRET is not set. Generate one with: openssl rand -hex 32")
@@ -64,9 +72,17 @@ func Load() Config {
 		StravaClientID:        stravaClientID,
 		StravaClientSecret:    stravaClientSecret,
 		StravaVerifyToken:     stravaVerifyToken,
+		StravaBaseURL:         getenv("STRAVA_BASE_URL", ""),
 		AppBaseURL:            appBaseURL,
 		JWTSecret:             jwtSecret,
-		JWTExpiryHours:        jwtExpiryHours,
+		AccessTokenMinutes:    getenvInt("ACCESS_TOKEN_MINUTES", 15),
+		RefreshTokenDays:      getenvInt("REFRESH_TOKEN_DAYS", 30),
+		AnnounceRecords:       getenvBool("ANNOUNCE_PERSONAL_RECORDS", true),
+		AnnounceWebReports:    getenvBool("ANNOUNCE_WEB_REPORTS", true),
+		AdminPhones:           getenvList("ADMIN_PHONES"),
+		TrustedProxies:        getenvList("TRUSTED_PROXIES"),
+		SecretKeys:            getenvList("SECRET_KEYS"),
+		AllowPlaintextSecrets: getenvBool("ALLOW_PLAINTEXT_SECRETS", false),
 	}
 }
 
@@ -95,3 +111,13 @@ func getenvBool(key string, fallback bool) bool {
 	}
 	return fallback
 }
+
+func getenvList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 // Achievement represents a gamification achievement that users can unlock.
 type Achievement struct {
@@ -443,6 +446,35 @@ func RecentAchievementSummaries(achievements string, limit int) []BadgeSummary {
 	return summaries
 }
 
+// TopAchievementSummaries returns the achieved badges worth the most
+// points, newer ones first among equals, for profile showcases.
+func TopAchievementSummaries(achievements string, limit int) []BadgeSummary {
+	all := RecentAchievementSummaries(achievements, strings.Count(achievements, ",")+1)
+	sort.SliceStable(all, func(i, j int) bool {
+		return achievementPoints(all[i].ID) > achievementPoints(all[j].ID)
+	})
+	if len(all) > limit {
+		all = all[:max(limit, 0)]
+	}
+	return all
+}
+
+func achievementPoints(id string) int {
+	for _, list := range [][]Achievement{AllSeasonAchievements, AllAchievements} {
+		for _, a := range list {
+			if a.ID == id {
+				return a.Points
+			}
+		}
+	}
+	for _, a := range AllComebackAchievements {
+		if a.ID == id {
+			return a.Points
+		}
+	}
+	return 0
+}
+
 // CheckNewAchievements evaluates all achievements against the report and returns newly unlocked ones.
 func CheckNewAchievements(report *Report) []Achievement {
 	var newlyUnlocked []Achievement
@@ -0,0 +1,313 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ActivityTypeRun      = "run"
+	ActivityTypeWalk     = "walk"
+	ActivityTypeRide     = "ride"
+	ActivityTypeSwim     = "swim"
+	ActivityTypeHike     = "hike"
+	ActivityTypeStrength = "strength"
+	ActivityTypeHIIT     = "hiit"
+	ActivityTypeSport    = "sport"
+	ActivityTypeYoga     = "yoga"
+
+	IntensityLow      = "low"
+	IntensityModerate = "moderate"
+	IntensityHigh     = "high"
+)
+
+// ActivityMetrics are the structured facts pulled out of a free-text report
+// ("lari 5km 30 menit", "push up 3x15"). Zero values mean "not mentioned".
+type ActivityMetrics struct {
+	ActivityType    string `json:"activity_type,omitempty"`
+	DistanceMeters  int    `json:"distance_m,omitempty"`
+	DurationSeconds int    `json:"duration_s,omitempty"`
+	Sets            int    `json:"sets,omitempty"`
+	Reps            int    `json:"reps,omitempty"`
+	Steps           int    `json:"steps,omitempty"`
+	Intensity       string `json:"intensity,omitempty"`
+}
+
+// IsZero reports whether nothing could be extracted.
+func (m ActivityMetrics) IsZero() bool {
+	return m == ActivityMetrics{}
+}
+
+// HasQuantities reports whether any measurable quantity was extracted, as
+// opposed to only a type or intensity label.
+func (m ActivityMetrics) HasQuantities() bool {
+	return m.DistanceMeters > 0 || m.DurationSeconds > 0 || m.Reps > 0 || m.Steps > 0
+}
+
+// ActivityRecord is a stored ActivityMetrics row tied to its report event.
+type ActivityRecord struct {
+	EventID      string
+	UserID       string
+	Kind         string
+	ActivityDate time.Time
+	OccurredAt   time.Time
+	ActivityMetrics
+}
+
+type ActivityMetricsRepository interface {
+	// GetActivityRecords returns the structured records of a user with
+	// activity dates in [startDate, endDate), oldest first.
+	GetActivityRecords(ctx context.Context, userID string, startDate, endDate time.Time) ([]ActivityRecord, error)
+}
+
+// activityTypeKeywordSets is ordered from most to least specific so a
+// "jalan kaki ke gym" report resolves to strength, not walk.
+var activityTypeKeywordSets = []struct {
+	activityType string
+	keywords     []string
+}{
+	{ActivityTypeHIIT, []string{"hiit", "tabata", "crossfit", "circuit", "burpee", "burpees"}},
+	{ActivityTypeSwim, []string{"renang", "berenang", "swim", "swimming"}},
+	{ActivityTypeRide, []string{"sepeda", "bersepeda", "gowes", "cycle", "cycling", "bike", "ride", "spinning"}},
+	{ActivityTypeRun, []string{"lari", "berlari", "run", "running", "jogging", "jog", "marathon", "trail run", "sprint"}},
+	{ActivityTypeHike, []string{"hiking", "hike", "trekking", "mendaki", "naik gunung"}},
+	{ActivityTypeStrength, []string{
+		"gym", "beban", "angkat", "weight", "weightlifting", "strength", "powerlifting",
+		"push up", "pushup", "pull up", "pullup", "squat", "deadlift", "bench press",
+		"plank", "lunges", "lunge", "sit up", "situp", "calisthenics", "dip", "dips",
+	}},
+	{ActivityTypeYoga, []string{"yoga", "pilates", "stretching", "stretch", "peregangan", "mobility"}},
+	{ActivityTypeSport, []string{
+		"futsal", "sepak bola", "bola", "soccer", "football", "basket", "basketball",
+		"badminton", "bulutangkis", "bulu tangkis", "tenis", "tennis", "padel",
+		"pickleball", "voli", "volleyball", "boxing", "tinju", "muay thai",
+	}},
+	{ActivityTypeWalk, []string{"jalan", "jalan kaki", "jalan santai", "walk", "walking", "langkah", "steps"}},
+}
+
+var intensityKeywordSets = []struct {
+	intensity string
+	keywords  []string
+}{
+	{IntensityHigh, []string{"berat", "intens", "intense", "keras", "hard", "all out", "max effort", "capek banget", "ngos ngosan", "tempo", "interval", "hiit", "tabata"}},
+	{IntensityLow, []string{"santai", "ringan", "pelan", "easy", "light", "recovery", "chill", "zona 2", "zone 2"}},
+	{IntensityModerate, []string{"sedang", "moderate", "medium", "lumayan"}},
+}
+
+var (
+	// Numbers accept both "5.2" and the Indonesian "5,2".
+	metricNumber = `(\d+(?:[.,]\d+)?)`
+
+	hmsPattern       = regexp.MustCompile(`\b(\d{1,2}):(\d{2}):(\d{2})\b`)
+	stepsPattern     = regexp.MustCompile(metricNumber + `\s*(k|rb|ribu)?\s*(langkah|steps?)\b`)
+	setsRepsPattern  = regexp.MustCompile(`\b(\d{1,3})\s*[x×]\s*(\d{1,4})\b`)
+	setsOfPattern    = regexp.MustCompile(`\b(\d{1,3})\s*sets?\s*(?:of|x)?\s*(\d{1,4})\s*(?:reps?|repetisi)?\b`)
+	repsPattern      = regexp.MustCompile(`\b(\d{1,4})\s*(?:reps?|repetisi)\b`)
+	distancePattern  = regexp.MustCompile(metricNumber + `\s*(km|kilometers?|kilometer|k|meters?|meter|m|mi|miles?)\b`)
+	halfHourPattern  = regexp.MustCompile(`\bsetengah\s+jam\b`)
+	hoursPattern     = regexp.MustCompile(metricNumber + `\s*(jam|hours?|hrs?|hr|h)\b`)
+	minutesPattern   = regexp.MustCompile(metricNumber + `\s*(menit|mnt|minutes?|mins?)\b`)
+	secondsPattern   = regexp.MustCompile(`\b(\d{1,4})\s*(detik|dtk|seconds?|secs?)\b`)
+	rpePattern       = regexp.MustCompile(`\brpe\s*(\d{1,2})\b`)
+	thousandsPattern = regexp.MustCompile(`^\d{1,3}([.,]\d{3})+$`)
+)
+
+// ParseActivityMetrics extracts structured facts from Indonesian/English
+// free-text reports. It is best effort: anything it cannot read is left zero
+// so the report itself is never rejected because of the wording.
+func ParseActivityMetrics(text string) ActivityMetrics {
+	lower := strings.ToLower(text)
+	var m ActivityMetrics
+
+	// Each extractor blanks out what it consumed so later patterns cannot
+	// read the same digits twice ("8k langkah" is steps, not 8 km).
+	if match := hmsPattern.FindStringSubmatch(lower); match != nil {
+		h, _ := strconv.Atoi(match[1])
+		min, _ := strconv.Atoi(match[2])
+		sec, _ := strconv.Atoi(match[3])
+		m.DurationSeconds = h*3600 + min*60 + sec
+		lower = blankMatches(lower, hmsPattern)
+	}
+
+	for _, match := range stepsPattern.FindAllStringSubmatch(lower, -1) {
+		value := parseMetricNumber(match[1], true)
+		if match[2] != "" {
+			value *= 1000
+		}
+		m.Steps += int(math.Round(value))
+	}
+	lower = blankMatches(lower, stepsPattern)
+
+	for _, match := range setsRepsPattern.FindAllStringSubmatch(lower, -1) {
+		sets, _ := strconv.Atoi(match[1])
+		reps, _ := strconv.Atoi(match[2])
+		m.Sets += sets
+		m.Reps += sets * reps
+	}
+	lower = blankMatches(lower, setsRepsPattern)
+	for _, match := range setsOfPattern.FindAllStringSubmatch(lower, -1) {
+		sets, _ := strconv.Atoi(match[1])
+		reps, _ := strconv.Atoi(match[2])
+		m.Sets += sets
+		m.Reps += sets * reps
+	}
+	lower = blankMatches(lower, setsOfPattern)
+	for _, match := range repsPattern.FindAllStringSubmatch(lower, -1) {
+		reps, _ := strconv.Atoi(match[1])
+		m.Reps += reps
+	}
+	lower = blankMatches(lower, repsPattern)
+
+	if m.DurationSeconds == 0 {
+		duration := 0.0
+		if halfHourPattern.MatchString(lower) {
+			duration += 1800
+			lower = blankMatches(lower, halfHourPattern)
+		}
+		for _, match := range hoursPattern.FindAllStringSubmatch(lower, -1) {
+			duration += parseMetricNumber(match[1], false) * 3600
+		}
+		lower = blankMatches(lower, hoursPattern)
+		for _, match := range minutesPattern.FindAllStringSubmatch(lower, -1) {
+			duration += parseMetricNumber(match[1], false) * 60
+		}
+		lower = blankMatches(lower, minutesPattern)
+		for _, match := range secondsPattern.FindAllStringSubmatch(lower, -1) {
+			sec, _ := strconv.Atoi(match[1])
+			duration += float64(sec)
+		}
+		lower = blankMatches(lower, secondsPattern)
+		m.DurationSeconds = int(math.Round(duration))
+	}
+
+	distance := 0.0
+	for _, match := range distancePattern.FindAllStringSubmatch(lower, -1) {
+		switch match[2] {
+		case "m", "meter", "meters":
+			distance += parseMetricNumber(match[1], true)
+		case "mi", "mile", "miles":
+			distance += parseMetricNumber(match[1], false) * 1609.344
+		default:
+			distance += parseMetricNumber(match[1], false) * 1000
+		}
+	}
+	m.DistanceMeters = int(math.Round(distance))
+
+	normalized := normalizeActivityText(text)
+	for _, set := range activityTypeKeywordSets {
+		if containsAnyActivityKeyword(normalized, set.keywords) {
+			m.ActivityType = set.activityType
+			break
+		}
+	}
+	if m.ActivityType == "" && m.Steps > 0 {
+		m.ActivityType = ActivityTypeWalk
+	}
+
+	if match := rpePattern.FindStringSubmatch(lower); match != nil {
+		rpe, _ := strconv.Atoi(match[1])
+		switch {
+		case rpe >= 8:
+			m.Intensity = IntensityHigh
+		case rpe <= 4:
+			m.Intensity = IntensityLow
+		default:
+			m.Intensity = IntensityModerate
+		}
+	} else {
+		for _, set := range intensityKeywordSets {
+			if containsAnyActivityKeyword(normalized, set.keywords) {
+				m.Intensity = set.intensity
+				break
+			}
+		}
+	}
+
+	return m
+}
+
+// FormatActivityMetrics returns a short summary such as
+// "5.0 km • 30 menit • 45 reps", or "" when no quantity was found.
+func FormatActivityMetrics(m ActivityMetrics) string {
+	var parts []string
+	if m.DistanceMeters > 0 {
+		if m.DistanceMeters >= 1000 {
+			parts = append(parts, fmt.Sprintf("%.1f km", float64(m.DistanceMeters)/1000))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d m", m.DistanceMeters))
+		}
+	}
+	if m.DurationSeconds > 0 {
+		parts = append(parts, FormatDuration(m.DurationSeconds))
+	}
+	if m.Steps > 0 {
+		parts = append(parts, fmt.Sprintf("%d langkah", m.Steps))
+	}
+	if m.Reps > 0 {
+		if m.Sets > 0 {
+			parts = append(parts, fmt.Sprintf("%d set / %d reps", m.Sets, m.Reps))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d reps", m.Reps))
+		}
+	}
+	return strings.Join(parts, " • ")
+}
+
+// FormatDuration renders seconds as "1 jam 5 menit", "30 menit" or "45 detik".
+func FormatDuration(seconds int) string {
+	h := seconds / 3600
+	min := (seconds % 3600) / 60
+	switch {
+	case h > 0 && min > 0:
+		return fmt.Sprintf("%d jam %d menit", h, min)
+	case h > 0:
+		return fmt.Sprintf("%d jam", h)
+	case min > 0:
+		return fmt.Sprintf("%d menit", min)
+	default:
+		return fmt.Sprintf("%d detik", seconds)
+	}
+}
+
+// FormatPace renders an average pace per kilometre, e.g. "5:32 /km". It
+// returns "" when either value is missing.
+func FormatPace(distanceMeters, durationSeconds int) string {
+	if distanceMeters <= 0 || durationSeconds <= 0 {
+		return ""
+	}
+	secondsPerKm := int(math.Round(float64(durationSeconds) * 1000 / float64(distanceMeters)))
+	return fmt.Sprintf("%d:%02d /km", secondsPerKm/60, secondsPerKm%60)
+}
+
+// FormatSpeed renders an average speed, e.g. "24.3 km/h", for rides where
+// pace per kilometre reads oddly.
+func FormatSpeed(distanceMeters, durationSeconds int) string {
+	if distanceMeters <= 0 || durationSeconds <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f km/h", float64(distanceMeters)/float64(durationSeconds)*3.6)
+}
+
+// parseMetricNumber reads "5", "5.2" or "5,2". When thousands is true,
+// "8.000" and "1,500" are read as grouped integers instead of decimals.
+func parseMetricNumber(raw string, thousands bool) float64 {
+	if thousands && thousandsPattern.MatchString(raw) {
+		raw = strings.NewReplacer(".", "", ",", "").Replace(raw)
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", "."), 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+func blankMatches(text string, pattern *regexp.Regexp) string {
+	return pattern.ReplaceAllStringFunc(text, func(match string) string {
+		return strings.Repeat(" ", len(match))
+	})
+}
@@ -0,0 +1,88 @@
+package domain
+
+import "testing"
+
+func TestParseActivityMetrics(t *testing.T) {
+	tests := []struct {
+		text string
+		want ActivityMetrics
+	}{
+		{
+			text: "/lapor lari 5km 30 menit",
+			want: ActivityMetrics{ActivityType: ActivityTypeRun, DistanceMeters: 5000, DurationSeconds: 1800},
+		},
+		{
+			text: "/lapor gym 1 jam",
+			want: ActivityMetrics{ActivityType: ActivityTypeStrength, DurationSeconds: 3600},
+		},
+		{
+			text: "/lapor push up 3x15",
+			want: ActivityMetrics{ActivityType: ActivityTypeStrength, Sets: 3, Reps: 45},
+		},
+		{
+			text: "/lapor jalan 8000 langkah",
+			want: ActivityMetrics{ActivityType: ActivityTypeWalk, Steps: 8000},
+		},
+		{
+			text: "/lapor jalan kaki 8.000 langkah santai",
+			want: ActivityMetrics{ActivityType: ActivityTypeWalk, Steps: 8000, Intensity: IntensityLow},
+		},
+		{
+			text: "/lapor 8k steps",
+			want: ActivityMetrics{ActivityType: ActivityTypeWalk, Steps: 8000},
+		},
+		{
+			text: "/lapor gowes 21,5 km 1 jam 15 menit",
+			want: ActivityMetrics{ActivityType: ActivityTypeRide, DistanceMeters: 21500, DurationSeconds: 4500},
+		},
+		{
+			text: "/lapor renang 1.500 m setengah jam",
+			want: ActivityMetrics{ActivityType: ActivityTypeSwim, DistanceMeters: 1500, DurationSeconds: 1800},
+		},
+		{
+			text: "/lapor squat 4 sets of 12 reps, plank 60 detik",
+			want: ActivityMetrics{ActivityType: ActivityTypeStrength, Sets: 4, Reps: 48, DurationSeconds: 60},
+		},
+		{
+			text: "/lapor easy run 10k 01:02:30 rpe 3",
+			want: ActivityMetrics{ActivityType: ActivityTypeRun, DistanceMeters: 10000, DurationSeconds: 3750, Intensity: IntensityLow},
+		},
+		{
+			text: "/lapor hiit tabata 20 min berat",
+			want: ActivityMetrics{ActivityType: ActivityTypeHIIT, DurationSeconds: 1200, Intensity: IntensityHigh},
+		},
+		{
+			text: "/lapor",
+			want: ActivityMetrics{},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := ParseActivityMetrics(tt.text); got != tt.want {
+			t.Errorf("ParseActivityMetrics(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFormatActivityMetrics(t *testing.T) {
+	got := FormatActivityMetrics(ActivityMetrics{DistanceMeters: 5000, DurationSeconds: 1800, Sets: 3, Reps: 45})
+	want := "5.0 km • 30 menit • 3 set / 45 reps"
+	if got != want {
+		t.Fatalf("FormatActivityMetrics = %q, want %q", got, want)
+	}
+	if got := FormatActivityMetrics(ActivityMetrics{ActivityType: ActivityTypeRun}); got != "" {
+		t.Fatalf("expected empty summary without quantities, got %q", got)
+	}
+}
+
+func TestFormatPaceAndSpeed(t *testing.T) {
+	if got := FormatPace(5000, 1650); got != "5:30 /km" {
+		t.Fatalf("FormatPace = %q, want 5:30 /km", got)
+	}
+	if got := FormatSpeed(30000, 3600); got != "30.0 km/h" {
+		t.Fatalf("FormatSpeed = %q, want 30.0 km/h", got)
+	}
+	if got := FormatPace(0, 1650); got != "" {
+		t.Fatalf("FormatPace without distance = %q, want empty", got)
+	}
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Integration is a third-party system, such as a gym check-in kiosk or a
+// member's script, allowed to submit activities. Requests are signed with
+// Secret. After a rotation the previous secret keeps working until
+// PreviousSecretExpiresAt so the client can switch over.
+type Integration struct {
+	ID                      string    `json:"id"`
+	Name                    string    `json:"name"`
+	Secret                  string    `json:"-"`
+	PreviousSecret          string    `json:"-"`
+	PreviousSecretExpiresAt time.Time `json:"previous_secret_expires_at,omitzero"`
+	Enabled                 bool      `json:"enabled"`
+	CreatedAt               time.Time `json:"created_at"`
+	UpdatedAt               time.Time `json:"updated_at"`
+	LastUsedAt              time.Time `json:"last_used_at,omitzero"`
+}
+
+// IntegrationReportSource is the ReportActivityEvent.Source of activities
+// submitted by an integration.
+func IntegrationReportSource(integrationID string) string {
+	return integrationSourcePrefix + integrationID
+}
+
+const integrationSourcePrefix = "integration:"
+
+// IntegrationIDFromSource returns the integration behind a report source.
+func IntegrationIDFromSource(source string) (string, bool) {
+	return strings.CutPrefix(source, integrationSourcePrefix)
+}
+
+// IntegrationClaimTimeout is how long a pending claim holds an external ID.
+// It is far longer than reporting one activity takes.
+const IntegrationClaimTimeout = 5 * time.Minute
+
+const (
+	IntegrationActivityPending  = "pending"
+	IntegrationActivityReported = "reported"
+	// IntegrationActivityRejected was turned down by the report rules, e.g.
+	// the daily limit. Resubmitting the external ID returns the same result.
+	IntegrationActivityRejected = "rejected"
+)
+
+// IntegrationActivity ties an integration's external ID to the report it
+// produced, which makes submissions idempotent.
+type IntegrationActivity struct {
+	IntegrationID string    `json:"integration_id"`
+	ExternalID    string    `json:"external_id"`
+	UserID        string    `json:"user_id"`
+	EventKey      string    `json:"event_key,omitempty"`
+	Status        string    `json:"status"`
+	Reply         string    `json:"reply,omitempty"`
+	ActivityDate  time.Time `json:"activity_date"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type IntegrationRepository interface {
+	CreateIntegration(ctx context.Context, integration Integration) error
+	// GetIntegration returns nil when the ID is unknown.
+	GetIntegration(ctx context.Context, id string) (*Integration, error)
+	ListIntegrations(ctx context.Context) ([]Integration, error)
+	UpdateIntegration(ctx context.Context, integration Integration) error
+	TouchIntegration(ctx context.Context, id string, usedAt time.Time) error
+
+	// ClaimIntegrationActivity inserts the activity unless its external ID
+	// is already known for the integration, and reports whether it did. A
+	// pending claim older than IntegrationClaimTimeout is taken over, so a
+	// submission that died between claiming and saving can be retried.
+	ClaimIntegrationActivity(ctx context.Context, activity IntegrationActivity) (bool, error)
+	GetIntegrationActivity(ctx context.Context, integrationID, externalID string) (*IntegrationActivity, error)
+	SaveIntegrationActivity(ctx context.Context, activity IntegrationActivity) error
+	// ReleaseIntegrationActivity drops a claim whose processing failed, so
+	// the client's retry is processed again.
+	ReleaseIntegrationActivity(ctx context.Context, integrationID, externalID string) error
+}
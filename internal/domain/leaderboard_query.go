@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// LeaderboardSortKeys lists every key the public leaderboard can be ranked by.
+var LeaderboardSortKeys = []LeaderboardSortKey{
+	SortBySeasonRank,
+	SortByLifetimeXP,
+	SortByWeeklyStreak,
+	SortByDailyStreak,
+	SortByWeeklyActivity,
+	SortByAttributeOverall,
+	SortByAttributeSTR,
+	SortByAttributeSTA,
+	SortByAttributeAGI,
+	SortByAttributeVIT,
+}
+
+// IsLeaderboardSortKey reports whether key is a known sort key.
+func IsLeaderboardSortKey(key LeaderboardSortKey) bool {
+	return slices.Contains(LeaderboardSortKeys, key)
+}
+
+// LeaderboardQuery selects a page of the public leaderboard, ranked the way
+// the web tabs rank it: weekly_activity counts days active this week and
+// the streak keys use daily streaks, unlike CompareReports.
+type LeaderboardQuery struct {
+	SortKey LeaderboardSortKey
+	// Season is an earlier season whose seasonal numbers come from its
+	// archived stats. Zero uses the running season on the report.
+	Season int
+	// CurrentSeason, when Season is zero, is the running season whose
+	// points, active days and side quests come from the ledger projections.
+	// Zero reads them off the report, for a season the ledger started in.
+	CurrentSeason int
+	// JobClass keeps one job class, compared case-insensitively.
+	JobClass string
+	// Search keeps users whose name contains it, case-insensitively.
+	Search string
+	// ActiveOnly drops users with nothing to show on the SortKey tab, e.g.
+	// no seasonal activity on season_rank.
+	ActiveOnly bool
+	// Today anchors the current daily streak; WeekStart starts the week
+	// counted by WeekDates.
+	Today     time.Time
+	WeekStart time.Time
+	Offset    int
+	Limit     int // zero or less for every row
+}
+
+// LeaderboardStanding is one user's row on the public leaderboard.
+// WeekDates are the days active since the query's WeekStart.
+type LeaderboardStanding struct {
+	Report             *Report
+	WeekDates          []time.Time
+	CurrentDailyStreak int
+	LongestDailyStreak int
+}
+
+// LeaderboardRepository ranks the public leaderboard in storage. total is
+// how many users match the query before Offset and Limit apply; it is zero
+// when the page is past the end.
+type LeaderboardRepository interface {
+	ListLeaderboard(ctx context.Context, query LeaderboardQuery) (standings []LeaderboardStanding, total int, err error)
+}
@@ -0,0 +1,274 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SetKindWeighted   = "weighted"
+	SetKindBodyweight = "bodyweight"
+	SetKindDuration   = "duration"
+
+	// maxRepsForOneRepMax limits e1RM to sets where the Epley estimate is
+	// still meaningful; a 30-rep set says little about a single.
+	maxRepsForOneRepMax = 12
+	poundsToKg          = 0.45359237
+)
+
+// ExerciseSet is one "Set N:" line of a Hevy share.
+type ExerciseSet struct {
+	Kind            string
+	WeightKg        float64
+	Reps            int
+	DurationSeconds int
+	Warmup          bool
+}
+
+// ExerciseLog groups the sets logged for one exercise in a workout.
+type ExerciseLog struct {
+	Name string
+	Sets []ExerciseSet
+}
+
+// VolumeKg is the sum of weight × reps over the working sets.
+func (e ExerciseLog) VolumeKg() float64 {
+	total := 0.0
+	for _, set := range e.Sets {
+		if set.Kind == SetKindWeighted && !set.Warmup {
+			total += set.WeightKg * float64(set.Reps)
+		}
+	}
+	return total
+}
+
+func (e ExerciseLog) TotalReps() int {
+	total := 0
+	for _, set := range e.Sets {
+		if !set.Warmup {
+			total += set.Reps
+		}
+	}
+	return total
+}
+
+// WorkingSets counts the sets that are not marked as warm-up.
+func (e ExerciseLog) WorkingSets() int {
+	n := 0
+	for _, set := range e.Sets {
+		if !set.Warmup {
+			n++
+		}
+	}
+	return n
+}
+
+func (e ExerciseLog) TopWeightKg() float64 {
+	top := 0.0
+	for _, set := range e.Sets {
+		if set.Kind == SetKindWeighted && set.WeightKg > top {
+			top = set.WeightKg
+		}
+	}
+	return top
+}
+
+// BestSet returns the working set with the highest estimated 1RM.
+func (e ExerciseLog) BestSet() (ExerciseSet, float64) {
+	var best ExerciseSet
+	bestE1RM := 0.0
+	for _, set := range e.Sets {
+		if set.Kind != SetKindWeighted || set.Warmup {
+			continue
+		}
+		if e1rm := EstimateOneRepMax(set.WeightKg, set.Reps); e1rm > bestE1RM {
+			best, bestE1RM = set, e1rm
+		}
+	}
+	return best, bestE1RM
+}
+
+// EstimateOneRepMax uses the Epley formula. A single is its own 1RM, and
+// sets above maxRepsForOneRepMax reps are not estimated.
+func EstimateOneRepMax(weightKg float64, reps int) float64 {
+	if weightKg <= 0 || reps <= 0 || reps > maxRepsForOneRepMax {
+		return 0
+	}
+	if reps == 1 {
+		return weightKg
+	}
+	return roundKg(weightKg * (1 + float64(reps)/30))
+}
+
+// TotalVolumeKg sums the volume of every exercise in the workout.
+func (w *Workout) TotalVolumeKg() float64 {
+	if w == nil {
+		return 0
+	}
+	total := 0.0
+	for _, log := range w.ExerciseLogs {
+		total += log.VolumeKg()
+	}
+	return total
+}
+
+var (
+	setPrefixPattern   = regexp.MustCompile(`(?i)^set\s*\d+\s*:\s*`)
+	weightedSetPattern = regexp.MustCompile(`([+-]?\d+(?:[.,]\d+)?)\s*(kg|lbs?)\s*[x×]\s*(\d+)`)
+	repsSetPattern     = regexp.MustCompile(`^(\d+)\s*reps?\b`)
+)
+
+// ParseExerciseSet reads the body of a Hevy set line, e.g.
+// "Set 2: 60 kg x 8", "Set 1: 135 lbs x 10 [Warm-up]", "Set 3: 12 reps" or
+// "Set 1: 1min 30s".
+func ParseExerciseSet(line string) (ExerciseSet, bool) {
+	body := strings.ToLower(strings.TrimSpace(setPrefixPattern.ReplaceAllString(strings.TrimSpace(line), "")))
+	set := ExerciseSet{Warmup: strings.Contains(body, "warm")}
+
+	if m := weightedSetPattern.FindStringSubmatch(body); m != nil {
+		weight, _ := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", "."), 64)
+		if strings.HasPrefix(m[2], "lb") {
+			weight *= poundsToKg
+		}
+		set.Reps, _ = strconv.Atoi(m[3])
+		// Assisted sets ("-20 kg") are logged as bodyweight reps.
+		if weight <= 0 {
+			set.Kind = SetKindBodyweight
+			return set, true
+		}
+		set.Kind = SetKindWeighted
+		set.WeightKg = roundKg(weight)
+		return set, true
+	}
+	if m := repsSetPattern.FindStringSubmatch(body); m != nil {
+		set.Kind = SetKindBodyweight
+		set.Reps, _ = strconv.Atoi(m[1])
+		return set, true
+	}
+	if seconds := parseShareDuration(body); seconds > 0 {
+		set.Kind = SetKindDuration
+		set.DurationSeconds = seconds
+		return set, true
+	}
+	return ExerciseSet{}, false
+}
+
+// ExerciseKey normalizes an exercise name for history lookups so
+// "Bench Press (Barbell)" and "bench press (barbell)" share one history.
+func ExerciseKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+func roundKg(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// FormatKg renders weights without a trailing ".0".
+func FormatKg(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f kg", v)
+	}
+	return fmt.Sprintf("%.1f kg", v)
+}
+
+// LiftingLog is the stored per-exercise summary of one workout.
+type LiftingLog struct {
+	EventKey             string    `json:"-"`
+	UserID               string    `json:"-"`
+	ExerciseKey          string    `json:"exercise_key"`
+	Exercise             string    `json:"exercise"`
+	ActivityDate         time.Time `json:"activity_date"`
+	OccurredAt           time.Time `json:"occurred_at"`
+	Sets                 int       `json:"sets"`
+	Reps                 int       `json:"reps"`
+	VolumeKg             float64   `json:"volume_kg"`
+	TopWeightKg          float64   `json:"top_weight_kg"`
+	EstimatedOneRepMaxKg float64   `json:"e1rm_kg"`
+}
+
+// LiftingExerciseSummary is one row of a user's lift list.
+type LiftingExerciseSummary struct {
+	ExerciseKey              string    `json:"exercise_key"`
+	Exercise                 string    `json:"exercise"`
+	Sessions                 int       `json:"sessions"`
+	BestEstimatedOneRepMaxKg float64   `json:"best_e1rm_kg"`
+	BestVolumeKg             float64   `json:"best_volume_kg"`
+	LastActivityDate         time.Time `json:"last_activity_date"`
+}
+
+// LiftingPR is a new best estimated 1RM for an exercise.
+type LiftingPR struct {
+	Exercise   string
+	PreviousKg float64
+	NewKg      float64
+	Set        ExerciseSet
+}
+
+// NewLiftingLogs summarizes the exercises of a workout that carry sets.
+func NewLiftingLogs(userID, eventKey string, workout *Workout, activityDate, occurredAt time.Time) []LiftingLog {
+	if workout == nil {
+		return nil
+	}
+	var logs []LiftingLog
+	for _, ex := range workout.ExerciseLogs {
+		if len(ex.Sets) == 0 {
+			continue
+		}
+		_, e1rm := ex.BestSet()
+		logs = append(logs, LiftingLog{
+			EventKey:             eventKey,
+			UserID:               userID,
+			ExerciseKey:          ExerciseKey(ex.Name),
+			Exercise:             ex.Name,
+			ActivityDate:         activityDate,
+			OccurredAt:           occurredAt,
+			Sets:                 ex.WorkingSets(),
+			Reps:                 ex.TotalReps(),
+			VolumeKg:             roundKg(ex.VolumeKg()),
+			TopWeightKg:          ex.TopWeightKg(),
+			EstimatedOneRepMaxKg: e1rm,
+		})
+	}
+	return logs
+}
+
+// DetectLiftingPRs compares a workout against the previous best e1RM per
+// exercise key. The first time an exercise is logged sets the baseline and
+// is not celebrated.
+func DetectLiftingPRs(workout *Workout, previousBest map[string]float64) []LiftingPR {
+	if workout == nil {
+		return nil
+	}
+	var prs []LiftingPR
+	seen := make(map[string]bool)
+	for _, ex := range workout.ExerciseLogs {
+		key := ExerciseKey(ex.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		set, e1rm := ex.BestSet()
+		prev := previousBest[key]
+		if e1rm <= 0 || prev <= 0 || e1rm <= prev {
+			continue
+		}
+		prs = append(prs, LiftingPR{Exercise: ex.Name, PreviousKg: prev, NewKg: e1rm, Set: set})
+	}
+	return prs
+}
+
+type LiftingRepository interface {
+	// RecordLiftingLogs stores the logs of one workout. It reports false when
+	// the event was already recorded, so retries don't re-announce PRs.
+	RecordLiftingLogs(ctx context.Context, logs []LiftingLog) (bool, error)
+	// GetBestEstimatedOneRepMax returns the best stored e1RM per exercise key.
+	GetBestEstimatedOneRepMax(ctx context.Context, userID string, exerciseKeys []string) (map[string]float64, error)
+	// GetLiftingHistory returns a user's logs for one exercise, oldest first.
+	GetLiftingHistory(ctx context.Context, userID, exerciseKey string, limit int) ([]LiftingLog, error)
+	GetLiftingExercises(ctx context.Context, userID string) ([]LiftingExerciseSummary, error)
+}
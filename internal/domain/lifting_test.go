@@ -0,0 +1,93 @@
+package domain
+
+import "testing"
+
+func TestParseExerciseSet(t *testing.T) {
+	tests := []struct {
+		line string
+		want ExerciseSet
+		ok   bool
+	}{
+		{"Set 1: 60 kg x 8", ExerciseSet{Kind: SetKindWeighted, WeightKg: 60, Reps: 8}, true},
+		{"Set 2: 62,5kg x 5", ExerciseSet{Kind: SetKindWeighted, WeightKg: 62.5, Reps: 5}, true},
+		{"Set 1: 135 lbs x 10 [Warm-up]", ExerciseSet{Kind: SetKindWeighted, WeightKg: 61.2, Reps: 10, Warmup: true}, true},
+		{"Set 3: +10 kg x 6", ExerciseSet{Kind: SetKindWeighted, WeightKg: 10, Reps: 6}, true},
+		{"Set 1: -20 kg x 8", ExerciseSet{Kind: SetKindBodyweight, Reps: 8}, true},
+		{"Set 1: 12 reps", ExerciseSet{Kind: SetKindBodyweight, Reps: 12}, true},
+		{"Set 1: 1min 30s", ExerciseSet{Kind: SetKindDuration, DurationSeconds: 90}, true},
+		{"Set 1: -", ExerciseSet{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseExerciseSet(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseExerciseSet(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestEstimateOneRepMax(t *testing.T) {
+	if got := EstimateOneRepMax(100, 1); got != 100 {
+		t.Errorf("single: got %v, want 100", got)
+	}
+	if got := EstimateOneRepMax(75, 6); got != 90 {
+		t.Errorf("75 kg x 6: got %v, want 90", got)
+	}
+	if got := EstimateOneRepMax(40, 20); got != 0 {
+		t.Errorf("high-rep set should not be estimated, got %v", got)
+	}
+}
+
+func TestParseHevy_SetsVolumeAndPRs(t *testing.T) {
+	message := `Today at 6:10 PM · Hevy
+Push Day
+Logged with Hevy
+
+Bench Press (Barbell)
+Set 1: 40 kg x 10 [Warm-up]
+Set 2: 60 kg x 8
+Set 3: 70 kg x 5
+
+Push Up
+Set 1: 15 reps
+Set 2: 12 reps
+
+Plank
+Set 1: 1min
+
+Time
+52m 3s`
+
+	w := ParseHevy(message)
+	if w == nil || len(w.ExerciseLogs) != 3 {
+		t.Fatalf("expected 3 exercise logs, got %+v", w)
+	}
+
+	bench := w.ExerciseLogs[0]
+	if bench.Name != "Bench Press (Barbell)" || len(bench.Sets) != 3 || bench.WorkingSets() != 2 {
+		t.Fatalf("unexpected bench log: %+v", bench)
+	}
+	if got := bench.VolumeKg(); got != 60*8+70*5 {
+		t.Errorf("bench volume = %v, want 830", got)
+	}
+	if _, e1rm := bench.BestSet(); e1rm != 81.7 {
+		t.Errorf("bench e1RM = %v, want 81.7", e1rm)
+	}
+	if got := w.ExerciseLogs[1].TotalReps(); got != 27 {
+		t.Errorf("push up reps = %d, want 27", got)
+	}
+	if got := w.ExerciseLogs[2].Sets[0].DurationSeconds; got != 60 {
+		t.Errorf("plank duration = %d, want 60", got)
+	}
+	if got := w.TotalVolumeKg(); got != 830 {
+		t.Errorf("total volume = %v, want 830", got)
+	}
+
+	prs := DetectLiftingPRs(w, map[string]float64{"bench press (barbell)": 80})
+	if len(prs) != 1 || prs[0].NewKg != 81.7 || prs[0].Set.WeightKg != 70 {
+		t.Fatalf("expected one bench PR, got %+v", prs)
+	}
+	if prs := DetectLiftingPRs(w, map[string]float64{}); len(prs) != 0 {
+		t.Fatalf("first log should only set the baseline, got %+v", prs)
+	}
+}
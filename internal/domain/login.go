@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// LoginChallenge is the one-time code the bot DMs to a user who asked to
+// log in to the dashboard. Only a keyed hash of the code is stored.
+// SendCount counts the codes sent since WindowStartedAt and drives the
+// resend throttle.
+type LoginChallenge struct {
+	UserID          string
+	CodeHash        string
+	Attempts        int
+	SendCount       int
+	WindowStartedAt time.Time
+	LastSentAt      time.Time
+	ExpiresAt       time.Time
+}
+
+const (
+	LoginEventCodeSent      = "code_sent"
+	LoginEventCodeThrottled = "code_throttled"
+	LoginEventVerified      = "verified"
+	LoginEventFailed        = "failed"
+	// LoginEventLocked is recorded when a code runs out of attempts. It
+	// can't be used any more, even with the right digits.
+	LoginEventLocked  = "locked"
+	LoginEventExpired = "expired"
+	// LoginEventLinkSent and LoginEventLinkUsed track /dashboard links.
+	LoginEventLinkSent = "link_sent"
+	LoginEventLinkUsed = "link_used"
+)
+
+// LoginLink is a single-use dashboard login link the bot DMs for
+// /dashboard. The URL also carries a signature over Nonce and UserID.
+type LoginLink struct {
+	Nonce     string
+	UserID    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    time.Time
+}
+
+// LoginAuditEntry records one step of a dashboard login.
+type LoginAuditEntry struct {
+	ID        int64     `json:"id"`
+	UserID    string    `json:"user_id"`
+	Event     string    `json:"event"`
+	IP        string    `json:"ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LoginRepository interface {
+	// GetLoginChallenge returns nil when the user has no pending code.
+	GetLoginChallenge(ctx context.Context, userID string) (*LoginChallenge, error)
+	// SaveLoginChallenge stores the challenge only if the stored one was
+	// last sent at lastSentAt, and reports whether it did. Of two parallel
+	// requests that read the same challenge, only one gets to send a code.
+	SaveLoginChallenge(ctx context.Context, challenge LoginChallenge, lastSentAt time.Time) (bool, error)
+	// IncrementLoginAttempts counts a verification attempt against the
+	// pending code and returns the new total, or zero when there is none.
+	IncrementLoginAttempts(ctx context.Context, userID string) (int, error)
+	// ConsumeLoginChallenge clears the code's hash so it can't be used
+	// again, keeping the throttle counters. It reports whether the hash
+	// still matched, so of two concurrent verifications only one wins.
+	ConsumeLoginChallenge(ctx context.Context, userID, codeHash string) (bool, error)
+
+	CreateLoginLink(ctx context.Context, link LoginLink) error
+	// ConsumeLoginLink marks the link used and returns it, or nil when it
+	// is unknown, expired or was already used.
+	ConsumeLoginLink(ctx context.Context, nonce string, now time.Time) (*LoginLink, error)
+
+	AddLoginAudit(ctx context.Context, entry LoginAuditEntry) error
+	// ListLoginAudit returns the newest entries first; an empty userID
+	// lists every user.
+	ListLoginAudit(ctx context.Context, userID string, limit int) ([]LoginAuditEntry, error)
+}
@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	RecordFastest5K             = "fastest_5k"
+	RecordLongestRun            = "longest_run"
+	RecordLongestRide           = "longest_ride"
+	RecordLongestPlank          = "longest_plank"
+	RecordBiggestWeeklyDistance = "biggest_weekly_distance"
+	RecordMostActiveMonth       = "most_active_month"
+
+	RecordUnitSeconds = "seconds"
+	RecordUnitMeters  = "meters"
+	RecordUnitDays    = "days"
+
+	fiveKMeters = 5000
+)
+
+type PersonalRecordType struct {
+	ID            string
+	Name          string
+	Icon          string
+	Unit          string
+	LowerIsBetter bool
+	// Aggregate records are totals over a period (week, month) that grow as
+	// more reports come in, rather than a single effort.
+	Aggregate bool
+}
+
+var AllPersonalRecordTypes = []PersonalRecordType{
+	{ID: RecordFastest5K, Name: "5K Tercepat (estimasi)", Icon: "⚡", Unit: RecordUnitSeconds, LowerIsBetter: true},
+	{ID: RecordLongestRun, Name: "Lari Terjauh", Icon: "🏃", Unit: RecordUnitMeters},
+	{ID: RecordLongestRide, Name: "Gowes Terjauh", Icon: "🚴", Unit: RecordUnitMeters},
+	{ID: RecordLongestPlank, Name: "Plank Terlama", Icon: "🧱", Unit: RecordUnitSeconds},
+	{ID: RecordBiggestWeeklyDistance, Name: "Jarak Mingguan Terbesar", Icon: "🗺️", Unit: RecordUnitMeters, Aggregate: true},
+	{ID: RecordMostActiveMonth, Name: "Bulan Paling Aktif", Icon: "📅", Unit: RecordUnitDays, Aggregate: true},
+}
+
+func FindPersonalRecordType(id string) *PersonalRecordType {
+	for i := range AllPersonalRecordTypes {
+		if AllPersonalRecordTypes[i].ID == id {
+			return &AllPersonalRecordTypes[i]
+		}
+	}
+	return nil
+}
+
+// PersonalRecord is one entry of a user's record history. Ref identifies what
+// set it: the report event for single efforts, or the period label
+// ("2026-W36", "2026-09") for aggregate records. EventKey is the report
+// event that last wrote the entry, so cancelling that report can take it
+// back.
+type PersonalRecord struct {
+	UserID       string    `json:"-"`
+	RecordType   string    `json:"record_type"`
+	Ref          string    `json:"ref"`
+	EventKey     string    `json:"-"`
+	Value        int       `json:"value"`
+	ActivityDate time.Time `json:"activity_date"`
+	AchievedAt   time.Time `json:"achieved_at"`
+}
+
+// PersonalRecordCandidate is a value a report could set a record with.
+type PersonalRecordCandidate struct {
+	RecordType string
+	Ref        string
+	Value      int
+}
+
+// Beats reports whether value is strictly better than other for this type.
+func (t PersonalRecordType) Beats(value, other int) bool {
+	if t.LowerIsBetter {
+		return value < other
+	}
+	return value > other
+}
+
+// FormatValue renders a record value in its unit, e.g. "24:35", "12.4 km"
+// or "22 hari aktif".
+func (t PersonalRecordType) FormatValue(value int) string {
+	switch t.Unit {
+	case RecordUnitSeconds:
+		if t.ID == RecordFastest5K {
+			return FormatClock(value)
+		}
+		return FormatDuration(value)
+	case RecordUnitMeters:
+		return fmt.Sprintf("%.1f km", float64(value)/1000)
+	case RecordUnitDays:
+		return fmt.Sprintf("%d hari aktif", value)
+	default:
+		return fmt.Sprintf("%d", value)
+	}
+}
+
+// FormatClock renders seconds as "m:ss" or "h:mm:ss".
+func FormatClock(seconds int) string {
+	h, m, s := seconds/3600, (seconds%3600)/60, seconds%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// WeekRecordRef labels the ISO week of date, e.g. "2026-W36".
+func WeekRecordRef(date time.Time) string {
+	year, week := date.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// MonthRecordRef labels the calendar month of date, e.g. "2026-09".
+func MonthRecordRef(date time.Time) string {
+	return date.Format("2006-01")
+}
+
+// EffortRecordCandidates derives the single-effort records a report can set.
+// Reports carry no splits, so fastest 5K is an estimate: the average pace of
+// a run of at least 5 km held over 5 km. Its name says so wherever it shows.
+func EffortRecordCandidates(eventKey, activityText string, metrics ActivityMetrics, workout *Workout) []PersonalRecordCandidate {
+	var out []PersonalRecordCandidate
+	add := func(recordType string, value int) {
+		if value > 0 {
+			out = append(out, PersonalRecordCandidate{RecordType: recordType, Ref: eventKey, Value: value})
+		}
+	}
+
+	switch metrics.ActivityType {
+	case ActivityTypeRun:
+		add(RecordLongestRun, metrics.DistanceMeters)
+		if metrics.DistanceMeters >= fiveKMeters && metrics.DurationSeconds > 0 {
+			add(RecordFastest5K, metrics.DurationSeconds*fiveKMeters/metrics.DistanceMeters)
+		}
+	case ActivityTypeRide:
+		add(RecordLongestRide, metrics.DistanceMeters)
+	}
+
+	plank := 0
+	if strings.Contains(strings.ToLower(activityText), "plank") && metrics.DistanceMeters == 0 {
+		plank = metrics.DurationSeconds
+	}
+	if workout != nil {
+		for _, ex := range workout.ExerciseLogs {
+			if !strings.Contains(strings.ToLower(ex.Name), "plank") {
+				continue
+			}
+			for _, set := range ex.Sets {
+				if set.Kind == SetKindDuration && set.DurationSeconds > plank {
+					plank = set.DurationSeconds
+				}
+			}
+		}
+	}
+	add(RecordLongestPlank, plank)
+	return out
+}
+
+// CurrentPersonalRecords reduces a record history to the best entry per
+// type, in AllPersonalRecordTypes order.
+func CurrentPersonalRecords(history []PersonalRecord) []PersonalRecord {
+	best := make(map[string]PersonalRecord)
+	for _, rec := range history {
+		t := FindPersonalRecordType(rec.RecordType)
+		if t == nil {
+			continue
+		}
+		if cur, ok := best[rec.RecordType]; !ok || t.Beats(rec.Value, cur.Value) {
+			best[rec.RecordType] = rec
+		}
+	}
+	var out []PersonalRecord
+	for _, t := range AllPersonalRecordTypes {
+		if rec, ok := best[t.ID]; ok {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+type PersonalRecordRepository interface {
+	// SavePersonalRecord inserts the entry, or replaces the value of the
+	// existing entry with the same ref.
+	SavePersonalRecord(ctx context.Context, rec PersonalRecord) error
+	// GetPersonalRecordHistory returns a user's record entries oldest first.
+	// An empty recordType returns every type.
+	GetPersonalRecordHistory(ctx context.Context, userID, recordType string) ([]PersonalRecord, error)
+	// DeletePersonalRecordsByEvent removes the entries last written by the
+	// report event and returns them.
+	DeletePersonalRecordsByEvent(ctx context.Context, userID, eventKey string) ([]PersonalRecord, error)
+}
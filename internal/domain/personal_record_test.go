@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEffortRecordCandidates(t *testing.T) {
+	run := ParseActivityMetrics("/lapor lari 10km 55 menit")
+	got := EffortRecordCandidates("evt-1", "/lapor lari 10km 55 menit", run, nil)
+	want := []PersonalRecordCandidate{
+		{RecordType: RecordLongestRun, Ref: "evt-1", Value: 10000},
+		{RecordType: RecordFastest5K, Ref: "evt-1", Value: 1650},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("run candidates = %+v, want %+v", got, want)
+	}
+
+	short := ParseActivityMetrics("/lapor lari 3km 15 menit")
+	for _, c := range EffortRecordCandidates("evt-2", "lari 3km 15 menit", short, nil) {
+		if c.RecordType == RecordFastest5K {
+			t.Fatalf("runs under 5 km must not set a 5K time: %+v", c)
+		}
+	}
+
+	plank := ParseActivityMetrics("/lapor plank 2 menit")
+	got = EffortRecordCandidates("evt-3", "/lapor plank 2 menit", plank, nil)
+	if len(got) != 1 || got[0].RecordType != RecordLongestPlank || got[0].Value != 120 {
+		t.Fatalf("plank candidates = %+v", got)
+	}
+
+	hevy := &Workout{ExerciseLogs: []ExerciseLog{{Name: "Plank", Sets: []ExerciseSet{
+		{Kind: SetKindDuration, DurationSeconds: 75},
+		{Kind: SetKindDuration, DurationSeconds: 95},
+	}}}}
+	got = EffortRecordCandidates("evt-4", "", ActivityMetrics{}, hevy)
+	if len(got) != 1 || got[0].Value != 95 {
+		t.Fatalf("hevy plank candidates = %+v", got)
+	}
+}
+
+func TestCurrentPersonalRecords(t *testing.T) {
+	day := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	history := []PersonalRecord{
+		{RecordType: RecordMostActiveMonth, Ref: "2026-08", Value: 18, ActivityDate: day},
+		{RecordType: RecordFastest5K, Ref: "a", Value: 1800, ActivityDate: day},
+		{RecordType: RecordFastest5K, Ref: "b", Value: 1700, ActivityDate: day.AddDate(0, 0, 7)},
+		{RecordType: RecordFastest5K, Ref: "c", Value: 1750, ActivityDate: day.AddDate(0, 0, 14)},
+	}
+
+	got := CurrentPersonalRecords(history)
+	if len(got) != 2 || got[0].RecordType != RecordFastest5K || got[0].Ref != "b" || got[1].RecordType != RecordMostActiveMonth {
+		t.Fatalf("CurrentPersonalRecords = %+v", got)
+	}
+}
+
+func TestPersonalRecordType_FormatValue(t *testing.T) {
+	tests := []struct {
+		id    string
+		value int
+		want  string
+	}{
+		{RecordFastest5K, 1475, "24:35"},
+		{RecordLongestRun, 21100, "21.1 km"},
+		{RecordLongestPlank, 150, "2 menit"},
+		{RecordMostActiveMonth, 22, "22 hari aktif"},
+	}
+	for _, tt := range tests {
+		if got := FindPersonalRecordType(tt.id).FormatValue(tt.value); got != tt.want {
+			t.Errorf("FormatValue(%s, %d) = %q, want %q", tt.id, tt.value, got, tt.want)
+		}
+	}
+}
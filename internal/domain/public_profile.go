@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ErrPublicProfileHandleTaken is returned when saving a handle another
+// profile already holds.
+var ErrPublicProfileHandleTaken = errors.New("handle is already taken")
+
+// PublicUserID is the masked ID that stands in for a phone number on the
+// public leaderboard and event stream. It is stable, so a leaderboard row
+// links to the same profile across days.
+func PublicUserID(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	digest := hex.EncodeToString(sum[:])[:10]
+	if len(userID) <= 2 {
+		return "hunter-" + digest
+	}
+	return "hunter-" + digest + "-" + userID[len(userID)-2:]
+}
+
+// PublicProfile is a user's opt-in to a public profile page and hunter
+// card. The page is reachable by PublicID or, when set, by Handle.
+type PublicProfile struct {
+	UserID    string    `json:"-"`
+	PublicID  string    `json:"public_id"`
+	Handle    string    `json:"handle,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PublicProfileRepository interface {
+	// GetPublicProfile returns nil when the user never set up a profile.
+	GetPublicProfile(ctx context.Context, userID string) (*PublicProfile, error)
+	// FindPublicProfile looks a profile up by its public ID or handle, and
+	// returns nil when neither matches. Disabled profiles are returned too.
+	FindPublicProfile(ctx context.Context, key string) (*PublicProfile, error)
+	// SavePublicProfile returns ErrPublicProfileHandleTaken when another
+	// profile holds the handle, even if it took it a moment ago.
+	SavePublicProfile(ctx context.Context, profile PublicProfile) error
+}
@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	RaidStatusActive   = "active"
+	RaidStatusDefeated = "defeated"
+	RaidStatusExpired  = "expired"
+
+	// RaidHPPerHunter is the HP each active hunter adds to the weekly boss.
+	// A hunter who reports 3-4 times a week deals roughly this much damage,
+	// so the boss falls when most of the group shows up.
+	RaidHPPerHunter = 40
+	// RaidMinHunters keeps the boss from being trivial in a quiet week.
+	RaidMinHunters = 3
+	// RaidWeaknessMultiplierPct is the damage multiplier (in percent) applied
+	// when the attacking attribute matches the boss weakness.
+	RaidWeaknessMultiplierPct = 150
+	// RaidContributorReward is the bonus granted to every hunter who dealt
+	// damage to a defeated boss.
+	RaidContributorReward = 15
+)
+
+// RaidArchetype describes a boss template. Each archetype is weak to one
+// attribute so different sports matter in different weeks.
+type RaidArchetype struct {
+	ID       string
+	Name     string
+	Icon     string
+	Weakness AttributeType
+	Lore     string
+}
+
+// AllRaidArchetypes is the rotation of weekly bosses.
+var AllRaidArchetypes = []RaidArchetype{
+	{ID: "golem", Name: "Stone Golem", Icon: "🗿", Weakness: AttrStr, Lore: "Raksasa batu yang hanya retak oleh kekuatan mentah."},
+	{ID: "wyvern", Name: "Sky Wyvern", Icon: "🐉", Weakness: AttrSta, Lore: "Naga terbang yang kalah oleh hunter yang kuat mengejar jauh."},
+	{ID: "shadow", Name: "Shadow Stalker", Icon: "👤", Weakness: AttrAgi, Lore: "Bayangan licin yang hanya bisa ditangkap oleh gerakan gesit."},
+	{ID: "lich", Name: "Plague Lich", Icon: "💀", Weakness: AttrVit, Lore: "Penyihir wabah yang luluh oleh tubuh yang pulih dan sehat."},
+}
+
+// RaidBoss is the weekly collective challenge. HP is shared by the whole
+// group and drained by accepted reports.
+type RaidBoss struct {
+	ID           string
+	SeasonNumber int
+	WeekStart    time.Time
+	ArchetypeID  string
+	Name         string
+	Icon         string
+	Weakness     AttributeType
+	MaxHP        int
+	HP           int
+	Status       string
+	SpawnedAt    time.Time
+	DefeatedAt   *time.Time
+	RewardedAt   *time.Time
+}
+
+// RaidDamage is a single hit logged against a boss.
+type RaidDamage struct {
+	BossID    string
+	UserID    string
+	EventKey  string
+	Attribute AttributeType
+	Points    int
+	Damage    int
+	Weakness  bool
+	CreatedAt time.Time
+}
+
+// RaidContribution aggregates a hunter's damage against one boss.
+type RaidContribution struct {
+	UserID      string
+	Name        string
+	TotalDamage int
+	Hits        int
+}
+
+// RaidArchetypeForWeek picks a boss deterministically from the ISO week so
+// every restart spawns the same boss for the same week.
+func RaidArchetypeForWeek(weekStart time.Time) RaidArchetype {
+	_, week := weekStart.ISOWeek()
+	return AllRaidArchetypes[week%len(AllRaidArchetypes)]
+}
+
+// FindRaidArchetype looks up an archetype by ID.
+func FindRaidArchetype(id string) (RaidArchetype, bool) {
+	for _, a := range AllRaidArchetypes {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return RaidArchetype{}, false
+}
+
+// RaidRewardEventID is the bonus event ID of one hunter's reward for a
+// boss, so the reward is credited once however often it is granted.
+func RaidRewardEventID(bossID, userID string) string {
+	return bossID + ":reward:" + userID
+}
+
+// RaidBossID returns the stable ID of the boss for a given week.
+func RaidBossID(weekStart time.Time) string {
+	return "raid-" + weekStart.Format(time.DateOnly)
+}
+
+// ScaleRaidHP returns the boss HP for the given number of active hunters.
+func ScaleRaidHP(activeHunters int) int {
+	if activeHunters < RaidMinHunters {
+		activeHunters = RaidMinHunters
+	}
+	return activeHunters * RaidHPPerHunter
+}
+
+// ComputeRaidDamage converts awarded points into boss damage. Hitting the
+// boss weakness multiplies damage; any accepted report deals at least 1.
+func ComputeRaidDamage(points int, attr AttributeType, weakness AttributeType) (int, bool) {
+	if points < 1 {
+		points = 1
+	}
+	if attr != "" && attr == weakness {
+		return (points*RaidWeaknessMultiplierPct + 50) / 100, true
+	}
+	return points, false
+}
+
+// NewRaidBoss builds the boss for the week starting at weekStart (an ISO
+// week Monday as returned by GetStartOfISOWeek).
+func NewRaidBoss(weekStart time.Time, seasonNumber, activeHunters int, now time.Time) *RaidBoss {
+	archetype := RaidArchetypeForWeek(weekStart)
+	hp := ScaleRaidHP(activeHunters)
+	return &RaidBoss{
+		ID:           RaidBossID(weekStart),
+		SeasonNumber: seasonNumber,
+		WeekStart:    weekStart,
+		ArchetypeID:  archetype.ID,
+		Name:         archetype.Name,
+		Icon:         archetype.Icon,
+		Weakness:     archetype.Weakness,
+		MaxHP:        hp,
+		HP:           hp,
+		Status:       RaidStatusActive,
+		SpawnedAt:    now,
+	}
+}
+
+// FormatRaidHPBar returns a compact HP bar like "[██████░░░░] 180/300 HP".
+func FormatRaidHPBar(boss *RaidBoss) string {
+	if boss == nil {
+		return ""
+	}
+	barLen := 10
+	filled := 0
+	if boss.MaxHP > 0 {
+		filled = (boss.HP*barLen + boss.MaxHP - 1) / boss.MaxHP
+	}
+	if filled > barLen {
+		filled = barLen
+	}
+	if filled < 0 {
+		filled = 0
+	}
+
+	var bar strings.Builder
+	for i := 0; i < barLen; i++ {
+		if i < filled {
+			bar.WriteString("█")
+		} else {
+			bar.WriteString("░")
+		}
+	}
+	return fmt.Sprintf("[%s] %d/%d HP", bar.String(), boss.HP, boss.MaxHP)
+}
+
+type RaidRepository interface {
+	// CreateRaidBoss inserts the boss unless one already exists for the same
+	// week. Returns false when the boss was already spawned.
+	CreateRaidBoss(ctx context.Context, boss *RaidBoss) (bool, error)
+	GetRaidBoss(ctx context.Context, bossID string) (*RaidBoss, error)
+	// ApplyRaidDamage logs the hit and lowers HP atomically. The returned
+	// boss reflects the post-hit state; defeated is true only for the hit
+	// that brought HP to zero. Duplicate event keys are ignored (applied=false).
+	ApplyRaidDamage(ctx context.Context, hit RaidDamage) (boss *RaidBoss, applied bool, defeated bool, err error)
+	GetRaidContributions(ctx context.Context, bossID string) ([]RaidContribution, error)
+	// MarkRaidRewarded records that a defeated boss's rewards were handed
+	// out. It returns false when they already were.
+	MarkRaidRewarded(ctx context.Context, bossID string, now time.Time) (bool, error)
+	// ListUnrewardedRaidBosses returns defeated bosses whose rewards were
+	// not marked handed out, oldest first.
+	ListUnrewardedRaidBosses(ctx context.Context) ([]*RaidBoss, error)
+	// RevertRaidDamage takes back the hit logged for eventKey while its boss
+	// is still active, restoring the HP. It returns reverted=false when
+	// there was no such hit or the boss already fell or escaped.
+	RevertRaidDamage(ctx context.Context, eventKey string) (boss *RaidBoss, reverted bool, err error)
+	// ExpireRaidBosses marks active bosses from weeks before weekStart as
+	// expired and returns them.
+	ExpireRaidBosses(ctx context.Context, weekStart time.Time) ([]*RaidBoss, error)
+}
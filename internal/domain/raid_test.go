@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScaleRaidHP_UsesMinimumHunters(t *testing.T) {
+	tests := []struct {
+		hunters int
+		want    int
+	}{
+		{hunters: 0, want: RaidMinHunters * RaidHPPerHunter},
+		{hunters: 2, want: RaidMinHunters * RaidHPPerHunter},
+		{hunters: 10, want: 10 * RaidHPPerHunter},
+	}
+
+	for _, tt := range tests {
+		if got := ScaleRaidHP(tt.hunters); got != tt.want {
+			t.Fatalf("ScaleRaidHP(%d) = %d, want %d", tt.hunters, got, tt.want)
+		}
+	}
+}
+
+func TestComputeRaidDamage_WeaknessMultiplier(t *testing.T) {
+	if got, weak := ComputeRaidDamage(10, AttrStr, AttrStr); got != 15 || !weak {
+		t.Fatalf("weakness hit = %d, %v; want 15, true", got, weak)
+	}
+	if got, weak := ComputeRaidDamage(10, AttrVit, AttrStr); got != 10 || weak {
+		t.Fatalf("normal hit = %d, %v; want 10, false", got, weak)
+	}
+	if got, weak := ComputeRaidDamage(10, "", ""); got != 10 || weak {
+		t.Fatalf("hit without attribute = %d, %v; want 10, false", got, weak)
+	}
+	if got, _ := ComputeRaidDamage(0, AttrSta, AttrStr); got != 1 {
+		t.Fatalf("zero-point hit = %d, want 1", got)
+	}
+}
+
+func TestRaidArchetypeForWeek_IsDeterministic(t *testing.T) {
+	weekStart := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	first := RaidArchetypeForWeek(weekStart)
+	if again := RaidArchetypeForWeek(weekStart); again.ID != first.ID {
+		t.Fatalf("archetype changed between calls: %s vs %s", first.ID, again.ID)
+	}
+	if next := RaidArchetypeForWeek(weekStart.AddDate(0, 0, 7)); next.ID == first.ID {
+		t.Fatalf("expected a different boss next week, got %s twice", first.ID)
+	}
+}
+
+func TestNewRaidBoss_StartsAtFullHP(t *testing.T) {
+	weekStart := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	boss := NewRaidBoss(weekStart, 1, 5, weekStart)
+
+	if boss.ID != "raid-2026-06-15" {
+		t.Fatalf("boss ID = %q, want raid-2026-06-15", boss.ID)
+	}
+	if boss.HP != boss.MaxHP || boss.MaxHP != 5*RaidHPPerHunter {
+		t.Fatalf("boss HP = %d/%d, want %d/%d", boss.HP, boss.MaxHP, 5*RaidHPPerHunter, 5*RaidHPPerHunter)
+	}
+	if boss.Status != RaidStatusActive {
+		t.Fatalf("boss status = %q, want active", boss.Status)
+	}
+}
+
+func TestFormatRaidHPBar(t *testing.T) {
+	tests := []struct {
+		hp   int
+		want string
+	}{
+		{hp: 200, want: "[██████████] 200/200 HP"},
+		{hp: 100, want: "[█████░░░░░] 100/200 HP"},
+		{hp: 1, want: "[█░░░░░░░░░] 1/200 HP"},
+		{hp: 0, want: "[░░░░░░░░░░] 0/200 HP"},
+	}
+
+	for _, tt := range tests {
+		got := FormatRaidHPBar(&RaidBoss{HP: tt.hp, MaxHP: 200})
+		if got != tt.want {
+			t.Fatalf("FormatRaidHPBar(hp=%d) = %q, want %q", tt.hp, got, tt.want)
+		}
+	}
+}
@@ -283,6 +283,56 @@ type ReportActivityEvent struct {
 	Source              string
 	ActivityText        string
 	MetadataJSON        string
+	// Metrics holds the structured facts parsed from ActivityText, if any.
+	Metrics *ActivityMetrics
+	// CancelledAt is set once the report the event recorded was cancelled.
+	// The event itself is kept so the ledger stays append-only.
+	CancelledAt time.Time
+}
+
+// ReportEventRepository looks up single report events so they can be
+// cancelled by ID, and marks events cancelled when their report is undone.
+type ReportEventRepository interface {
+	// GetReportEvent returns nil when the user has no event with that ID.
+	GetReportEvent(ctx context.Context, userID, eventID string) (*ReportActivityEvent, error)
+	// CancelReportEvent marks one event cancelled. It reports false when
+	// the event was already cancelled or isn't the user's.
+	CancelReportEvent(ctx context.Context, userID, eventID string, at time.Time) (bool, error)
+	// CancelLatestReportEvents marks up to limit of the user's newest
+	// active events of kind on activityDate cancelled; limit <= 0 marks
+	// all of them. It returns the IDs of the events it marked.
+	CancelLatestReportEvents(ctx context.Context, userID, kind string, activityDate time.Time, limit int, at time.Time) ([]string, error)
+}
+
+// BonusEvent records points granted outside a report, such as a raid
+// reward. The points count towards the season like a report's, but add no
+// report and no active day.
+type BonusEvent struct {
+	// EventID is stable for the grant, so recording it again is a no-op.
+	EventID      string
+	UserID       string
+	SeasonNumber int
+	Source       string
+	Points       int
+	OccurredAt   time.Time
+}
+
+// ReportEventQuery selects a page of one user's report events, newest
+// first. When BeforeOccurredAt is set, only events older than the
+// (BeforeOccurredAt, BeforeEventID) cursor are returned.
+type ReportEventQuery struct {
+	UserID           string
+	Kind             string // empty for both kinds
+	SeasonNumber     int    // zero for every season
+	BeforeOccurredAt time.Time
+	BeforeEventID    string
+	Limit            int
+}
+
+// ReportHistoryRepository lists a user's report events, cancelled ones
+// included, for their report history.
+type ReportHistoryRepository interface {
+	ListReportEvents(ctx context.Context, query ReportEventQuery) ([]ReportActivityEvent, error)
 }
 
 // GetToday returns the normalized "today" (midnight) based on the cutoff offset.
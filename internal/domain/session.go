@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// WebSession is one signed-in dashboard device. Access tokens carry its ID
+// and stop working as soon as it is revoked. The refresh token is rotated on
+// every use and only its hash is stored.
+type WebSession struct {
+	ID          string `json:"id"`
+	UserID      string `json:"-"`
+	RefreshHash string `json:"-"`
+	// PreviousHash is the hash RefreshHash replaced. It is still honoured
+	// for a moment after the rotation, for a tab that refreshed in parallel.
+	PreviousHash string    `json:"-"`
+	IP           string    `json:"ip,omitempty"`
+	UserAgent    string    `json:"user_agent,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastUsedAt   time.Time `json:"last_used_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RevokedAt    time.Time `json:"revoked_at,omitzero"`
+}
+
+// Active reports whether the session can still be used at now.
+func (s WebSession) Active(now time.Time) bool {
+	return s.RevokedAt.IsZero() && now.Before(s.ExpiresAt)
+}
+
+type SessionRepository interface {
+	CreateWebSession(ctx context.Context, session WebSession) error
+	// GetWebSession returns nil when the ID is unknown. Revoked and
+	// expired sessions are returned too.
+	GetWebSession(ctx context.Context, id string) (*WebSession, error)
+	// RotateWebSession stores the session's new refresh hash, client and
+	// expiry, and keeps previousHash as its previous hash, but only while
+	// it is active and still holds previousHash.
+	// It reports whether it did, so a refresh token is only rotated once.
+	RotateWebSession(ctx context.Context, session WebSession, previousHash string) (bool, error)
+	// ListWebSessions returns the user's active sessions, most recently
+	// used first.
+	ListWebSessions(ctx context.Context, userID string, now time.Time) ([]WebSession, error)
+	// RevokeWebSession reports whether an active session of the user was
+	// revoked.
+	RevokeWebSession(ctx context.Context, userID, id string, at time.Time) (bool, error)
+	// RevokeWebSessions revokes every active session of the user and
+	// returns how many there were.
+	RevokeWebSessions(ctx context.Context, userID string, at time.Time) (int, error)
+}
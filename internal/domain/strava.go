@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -19,3 +20,170 @@ type StravaRepository interface {
 	GetStravaAccountByAthleteID(ctx context.Context, athleteID int64) (*StravaAccount, error)
 	GetStravaAccountByUserID(ctx context.Context, userID string) (*StravaAccount, error)
 }
+
+const (
+	// StravaStateLink is a one-time link the bot DMs to start linking.
+	StravaStateLink = "link"
+	// StravaStateOAuth is the state sent to Strava and checked on callback.
+	StravaStateOAuth = "oauth"
+)
+
+// ErrInvalidStravaState is returned when an OAuth state or link token is
+// tampered with, expired or already used.
+var ErrInvalidStravaState = errors.New("invalid or expired strava state")
+
+// StravaOAuthState is a single-use nonce that ties a Strava authorization to
+// the user who started it.
+type StravaOAuthState struct {
+	Nonce     string
+	Purpose   string
+	UserID    string
+	Name      string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type StravaOAuthStateRepository interface {
+	CreateStravaOAuthState(ctx context.Context, state StravaOAuthState) error
+	// ConsumeStravaOAuthState marks an unused, unexpired state with the given
+	// purpose as used and returns it. It returns nil when there is no such
+	// state, so a nonce can only ever be consumed once.
+	ConsumeStravaOAuthState(ctx context.Context, nonce, purpose string, now time.Time) (*StravaOAuthState, error)
+}
+
+// StravaClaimTimeout is how long a pending claim holds an activity. It is
+// far longer than fetching and reporting one activity takes.
+const StravaClaimTimeout = 10 * time.Minute
+
+const (
+	// StravaActivityPending marks an activity whose create event is being
+	// processed; it keeps a retried event from reporting it twice.
+	StravaActivityPending  = "pending"
+	StravaActivityReported = "reported"
+	// StravaActivitySkipped is an activity type that is not auto-reported.
+	StravaActivitySkipped = "skipped"
+	// StravaActivityRejected was over the daily report limit.
+	StravaActivityRejected  = "rejected"
+	StravaActivityCancelled = "cancelled"
+)
+
+// StravaActivity ties a Strava activity to the report event it produced.
+type StravaActivity struct {
+	ActivityID   int64
+	UserID       string
+	AthleteID    int64
+	EventKey     string
+	ActivityType string
+	ActivityDate time.Time
+	Status       string
+	// Metadata from the Strava API at the time it was last processed.
+	Name                string
+	StartDateLocal      time.Time
+	DistanceMeters      int
+	MovingSeconds       int
+	ElapsedSeconds      int
+	ElevationGainMeters float64
+	AverageHeartrate    float64
+	MaxHeartrate        float64
+	AverageSpeed        float64 // meters per second
+	Calories            int
+	DeviceName          string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+type StravaActivityRepository interface {
+	// ClaimStravaActivity inserts the activity unless its ID is already
+	// known, and reports whether it did. A pending claim older than
+	// StravaClaimTimeout is taken over, so a run that died between claiming
+	// and recording doesn't lose the activity.
+	ClaimStravaActivity(ctx context.Context, activity StravaActivity) (bool, error)
+	GetStravaActivity(ctx context.Context, activityID int64) (*StravaActivity, error)
+	SaveStravaActivity(ctx context.Context, activity StravaActivity) error
+	DeleteStravaActivity(ctx context.Context, activityID int64) error
+	// DeleteStravaAccount unlinks the user's athlete. Activity mappings are
+	// kept so past reports stay traceable.
+	DeleteStravaAccount(ctx context.Context, userID string) error
+}
+
+const (
+	StravaWebhookPending   = "pending"
+	StravaWebhookProcessed = "processed"
+	// StravaWebhookDead is a delivery that ran out of retries. It stays in
+	// the inbox until an admin replays it.
+	StravaWebhookDead = "dead"
+)
+
+// StravaWebhookDelivery is a webhook event persisted before it is processed,
+// so a crash or Strava API failure can't lose it.
+type StravaWebhookDelivery struct {
+	ID            int64     `json:"id"`
+	Payload       string    `json:"payload"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error,omitempty"`
+	ReceivedAt    time.Time `json:"received_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	ProcessedAt   time.Time `json:"processed_at,omitempty"`
+}
+
+type StravaWebhookInboxRepository interface {
+	EnqueueStravaWebhook(ctx context.Context, payload string, receivedAt time.Time) (int64, error)
+	// GetDueStravaWebhooks returns pending deliveries whose next attempt is
+	// due, oldest first.
+	GetDueStravaWebhooks(ctx context.Context, now time.Time, limit int) ([]StravaWebhookDelivery, error)
+	MarkStravaWebhookProcessed(ctx context.Context, id int64, processedAt time.Time) error
+	MarkStravaWebhookFailed(ctx context.Context, id int64, attempts int, lastError string, nextAttemptAt time.Time, dead bool) error
+	// ListStravaWebhooks returns the newest deliveries, optionally filtered
+	// by status.
+	ListStravaWebhooks(ctx context.Context, status string, limit int) ([]StravaWebhookDelivery, error)
+	// ReplayStravaWebhook puts a delivery back in the queue with a fresh
+	// retry budget. It reports false when the ID is unknown.
+	ReplayStravaWebhook(ctx context.Context, id int64, now time.Time) (bool, error)
+}
+
+// StravaSyncRepository tracks when each linked athlete was last heard from,
+// so athletes whose webhooks stopped arriving can be polled instead.
+type StravaSyncRepository interface {
+	RecordStravaWebhookSeen(ctx context.Context, userID string, at time.Time) error
+	RecordStravaPolled(ctx context.Context, userID string, at time.Time) error
+	// ListQuietStravaAccounts returns linked accounts that have had no
+	// webhook since quietSince and were not polled since polledBefore.
+	ListQuietStravaAccounts(ctx context.Context, quietSince, polledBefore time.Time) ([]StravaAccount, error)
+}
+
+// StravaPreferences controls what a user's Strava auto-reports do.
+type StravaPreferences struct {
+	UserID string `json:"-"`
+	// ActivityTypes limits auto-reports to these Strava activity types;
+	// empty means every supported type.
+	ActivityTypes []string `json:"activity_types"`
+	// PostToGroup announces auto-reports in the group. When false the
+	// summary only goes to the user by DM.
+	PostToGroup bool      `json:"post_to_group"`
+	UpdatedAt   time.Time `json:"updated_at,omitzero"`
+}
+
+// DefaultStravaPreferences reports every supported type to the group.
+func DefaultStravaPreferences(userID string) StravaPreferences {
+	return StravaPreferences{UserID: userID, ActivityTypes: []string{}, PostToGroup: true}
+}
+
+// AllowsType reports whether the user wants activityType auto-reported.
+func (p StravaPreferences) AllowsType(activityType string) bool {
+	if len(p.ActivityTypes) == 0 {
+		return true
+	}
+	for _, t := range p.ActivityTypes {
+		if t == activityType {
+			return true
+		}
+	}
+	return false
+}
+
+type StravaPreferencesRepository interface {
+	// GetStravaPreferences returns nil when the user never changed them.
+	GetStravaPreferences(ctx context.Context, userID string) (*StravaPreferences, error)
+	SaveStravaPreferences(ctx context.Context, prefs StravaPreferences) error
+}
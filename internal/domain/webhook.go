@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Bot event types sent to outbound webhooks.
+const (
+	BotEventReportAccepted      = "report.accepted"
+	BotEventReportCancelled     = "report.cancelled"
+	BotEventAchievementUnlocked = "achievement.unlocked"
+	BotEventLevelUp             = "level.up"
+	BotEventRankUp              = "rank.up"
+	BotEventGoalCompleted       = "goal.completed"
+	BotEventSeasonReset         = "season.reset"
+	// BotEventRaidDefeated is raised by the report that lands the weekly
+	// raid boss's finishing blow.
+	BotEventRaidDefeated = "raid.defeated"
+	// BotEventRaidRewarded is raised for each contributor credited with a
+	// defeated boss's reward.
+	BotEventRaidRewarded = "raid.rewarded"
+	// BotEventStreakBroken is noticed on the user's next report, when the
+	// weekly streak is found to have lapsed.
+	BotEventStreakBroken = "streak.broken"
+)
+
+// BotEventTypes lists every event a webhook can subscribe to.
+var BotEventTypes = []string{
+	BotEventReportAccepted,
+	BotEventReportCancelled,
+	BotEventAchievementUnlocked,
+	BotEventLevelUp,
+	BotEventRankUp,
+	BotEventGoalCompleted,
+	BotEventSeasonReset,
+	BotEventStreakBroken,
+	BotEventRaidDefeated,
+	BotEventRaidRewarded,
+}
+
+// IsBotEventType reports whether eventType is a known event.
+func IsBotEventType(eventType string) bool {
+	return slices.Contains(BotEventTypes, eventType)
+}
+
+// BotEvent is something that happened in the bot. UserID and Name are empty
+// for group-wide events such as a season reset. ID is assigned when the
+// event is published and stays the same across delivery retries, so
+// receivers can deduplicate on it.
+type BotEvent struct {
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	UserID     string         `json:"user_id,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
+// WebhookSubscription is an outbound webhook endpoint. Payloads are signed
+// with Secret. An empty Events list receives every event type.
+type WebhookSubscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Wants reports whether the subscription receives eventType.
+func (s WebhookSubscription) Wants(eventType string) bool {
+	return s.Enabled && (len(s.Events) == 0 || slices.Contains(s.Events, eventType))
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	// WebhookDeliveryDead ran out of retries. It stays in the log until an
+	// admin replays it.
+	WebhookDeliveryDead = "dead"
+)
+
+// WebhookDelivery is one event queued for one subscription. Payload is the
+// exact body sent on every attempt.
+type WebhookDelivery struct {
+	ID             int64     `json:"id"`
+	SubscriptionID string    `json:"subscription_id"`
+	EventID        string    `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	Payload        string    `json:"payload"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	LastStatusCode int       `json:"last_status_code,omitempty"`
+	LastError      string    `json:"last_error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	NextAttemptAt  time.Time `json:"next_attempt_at"`
+	DeliveredAt    time.Time `json:"delivered_at,omitzero"`
+}
+
+type WebhookRepository interface {
+	CreateWebhookSubscription(ctx context.Context, sub WebhookSubscription) error
+	// GetWebhookSubscription returns nil when the ID is unknown.
+	GetWebhookSubscription(ctx context.Context, id string) (*WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	UpdateWebhookSubscription(ctx context.Context, sub WebhookSubscription) error
+	// DeleteWebhookSubscription removes the subscription and its deliveries.
+	DeleteWebhookSubscription(ctx context.Context, id string) error
+
+	EnqueueWebhookDeliveries(ctx context.Context, deliveries []WebhookDelivery) error
+	// GetDueWebhookDeliveries returns pending deliveries whose next attempt
+	// is due, oldest first.
+	GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]WebhookDelivery, error)
+	MarkWebhookDelivered(ctx context.Context, id int64, statusCode int, deliveredAt time.Time) error
+	MarkWebhookFailed(ctx context.Context, id int64, attempts, statusCode int, lastError string, nextAttemptAt time.Time, dead bool) error
+	// ListWebhookDeliveries returns the newest deliveries, optionally
+	// filtered by subscription and status.
+	ListWebhookDeliveries(ctx context.Context, subscriptionID, status string, limit int) ([]WebhookDelivery, error)
+	// ReplayWebhookDelivery puts a delivery back in the queue with a fresh
+	// retry budget. It reports false when the ID is unknown.
+	ReplayWebhookDelivery(ctx context.Context, id int64, now time.Time) (bool, error)
+}
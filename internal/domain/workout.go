@@ -0,0 +1,269 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	WorkoutSourceHevy          = "hevy"
+	WorkoutSourceStrava        = "strava"
+	WorkoutSourceNRC           = "nrc"
+	WorkoutSourceGarmin        = "garmin"
+	WorkoutSourceGoogleFit     = "google_fit"
+	WorkoutSourceSamsungHealth = "samsung_health"
+	WorkoutSourceAppleFitness  = "apple_fitness"
+	// WorkoutSourceFile is a GPX, TCX or FIT file uploaded by the user.
+	WorkoutSourceFile = "file"
+)
+
+// Workout is the normalized result of parsing a fitness app share text.
+// Title/Exercises/Time keep what the app printed; the numeric fields are
+// zero when the share text does not carry them.
+type Workout struct {
+	Source          string
+	Title           string
+	ActivityType    string
+	DistanceMeters  int
+	DurationSeconds int
+	Calories        int
+	Exercises       []string
+	ExerciseLogs    []ExerciseLog
+	Time            string
+}
+
+// WorkoutParser recognizes one app's share format.
+type WorkoutParser interface {
+	Source() string
+	// Detect reports whether message looks like this app's share text.
+	Detect(message string) bool
+	Parse(message string) *Workout
+}
+
+// workoutParsers is checked in order; Hevy goes first because its share text
+// is the most structured and the most common in the group.
+var workoutParsers = []WorkoutParser{
+	hevyParser{},
+	stravaParser{},
+	nrcParser{},
+	garminParser{},
+	googleFitParser{},
+	samsungHealthParser{},
+	appleFitnessParser{},
+}
+
+// workoutParsersMu guards workoutParsers, which RegisterWorkoutParser may
+// grow while messages are being parsed.
+var workoutParsersMu sync.RWMutex
+
+// RegisterWorkoutParser adds a parser after the built-in ones. It is safe to
+// call at any time; parsers registered later only see later messages.
+func RegisterWorkoutParser(p WorkoutParser) {
+	workoutParsersMu.Lock()
+	defer workoutParsersMu.Unlock()
+	workoutParsers = append(workoutParsers, p)
+}
+
+// ParseWorkout returns the workout from the first parser that recognizes the
+// message, or nil when it is a plain text report.
+func ParseWorkout(message string) *Workout {
+	workoutParsersMu.RLock()
+	parsers := workoutParsers
+	workoutParsersMu.RUnlock()
+
+	for _, p := range parsers {
+		if !p.Detect(message) {
+			continue
+		}
+		if w := p.Parse(message); w != nil {
+			if w.Source == "" {
+				w.Source = p.Source()
+			}
+			return w
+		}
+	}
+	return nil
+}
+
+// WorkoutSourceName returns the display name of a workout source.
+func WorkoutSourceName(source string) string {
+	switch source {
+	case WorkoutSourceHevy:
+		return "Hevy"
+	case WorkoutSourceStrava:
+		return "Strava"
+	case WorkoutSourceNRC:
+		return "Nike Run Club"
+	case WorkoutSourceGarmin:
+		return "Garmin Connect"
+	case WorkoutSourceGoogleFit:
+		return "Google Fit"
+	case WorkoutSourceSamsungHealth:
+		return "Samsung Health"
+	case WorkoutSourceAppleFitness:
+		return "Apple Fitness"
+	case WorkoutSourceFile:
+		return "File aktivitas"
+	default:
+		if id, ok := IntegrationIDFromSource(source); ok {
+			return id
+		}
+		return source
+	}
+}
+
+// ApplyTo overlays what the app measured on metrics parsed from free text.
+// Distance and duration from the app win even when zero, since the free-text
+// scan tends to misread share text (e.g. "37m 11s" as 37 meters).
+func (w *Workout) ApplyTo(m ActivityMetrics) ActivityMetrics {
+	if w == nil || w.Source == "" {
+		return m
+	}
+	if w.ActivityType != "" {
+		m.ActivityType = w.ActivityType
+	}
+	m.DistanceMeters = w.DistanceMeters
+	m.DurationSeconds = w.DurationSeconds
+	return m
+}
+
+var (
+	clockDurationPattern   = regexp.MustCompile(`\b(\d{1,2}):(\d{2})(?::(\d{2}))?\b`)
+	compactDurationPattern = regexp.MustCompile(`\b(\d+)\s*(h|hr|j|jam|m|min|mnt|menit|s|sec|dtk|detik)\b`)
+	caloriesPattern        = regexp.MustCompile(`(\d[\d.,]*)\s*(kcal|kkal|kilocalories|calories|cal|kal|kalori)\b`)
+)
+
+// shareLines returns the trimmed, non-empty lines of a share text.
+func shareLines(message string) []string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			lines = append(lines, trimmed)
+		}
+	}
+	return lines
+}
+
+// labeledValue finds the first line starting with one of labels and returns
+// the rest of it, or the next line when the app prints the value below the
+// label. Labels are matched case-insensitively on word boundaries. The lines
+// it read are marked in used.
+func labeledValue(lines []string, used map[int]bool, labels ...string) (string, bool) {
+	for i, line := range lines {
+		lower := strings.ToLower(line)
+		for _, label := range labels {
+			if !strings.HasPrefix(lower, label) {
+				continue
+			}
+			rest := line[len(label):]
+			if rest != "" && isLetter(rest[0]) {
+				continue
+			}
+			rest = strings.TrimSpace(strings.TrimLeft(rest, ":"))
+			used[i] = true
+			if rest == "" && i+1 < len(lines) {
+				rest = lines[i+1]
+				used[i+1] = true
+			}
+			return rest, rest != ""
+		}
+	}
+	return "", false
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// parseShareDuration reads "0:30:12", "28:41" (minutes:seconds), "28m 51s"
+// or "1 jam 5 menit" and returns seconds.
+func parseShareDuration(value string) int {
+	lower := strings.ToLower(value)
+	if m := clockDurationPattern.FindStringSubmatch(lower); m != nil {
+		a, _ := strconv.Atoi(m[1])
+		b, _ := strconv.Atoi(m[2])
+		if m[3] == "" {
+			return a*60 + b
+		}
+		c, _ := strconv.Atoi(m[3])
+		return a*3600 + b*60 + c
+	}
+	total := 0
+	for _, m := range compactDurationPattern.FindAllStringSubmatch(lower, -1) {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "h", "hr", "j", "jam":
+			total += n * 3600
+		case "m", "min", "mnt", "menit":
+			total += n * 60
+		default:
+			total += n
+		}
+	}
+	return total
+}
+
+// parseShareDistance reads "5.21 km", "5,02KM" or "3.1 mi" as meters.
+func parseShareDistance(value string) int {
+	return ParseActivityMetrics(value).DistanceMeters
+}
+
+// parseShareCalories returns the first calorie figure in text.
+func parseShareCalories(text string) int {
+	m := caloriesPattern.FindStringSubmatch(strings.ToLower(text))
+	if m == nil {
+		return 0
+	}
+	return int(parseMetricNumber(strings.TrimRight(m[1], ".,"), true))
+}
+
+// detectWorkoutType resolves the activity type from the title first, then
+// from the whole share text, and falls back to fallback.
+func detectWorkoutType(title, message, fallback string) string {
+	if t := ParseActivityMetrics(title).ActivityType; t != "" {
+		return t
+	}
+	if t := ParseActivityMetrics(message).ActivityType; t != "" {
+		return t
+	}
+	return fallback
+}
+
+// fillShareStats reads the usual labeled stat lines shared by most apps.
+// Values the app printed without a label are picked up from the remaining
+// free text, skipping labeled and pace lines that would otherwise read as a
+// distance.
+func fillShareStats(w *Workout, lines []string) {
+	used := make(map[int]bool)
+	if v, ok := labeledValue(lines, used, "distance", "total distance", "jarak"); ok {
+		w.DistanceMeters = parseShareDistance(v)
+	}
+	if v, ok := labeledValue(lines, used,
+		"moving time", "elapsed time", "workout time", "total time", "time",
+		"duration", "durasi", "waktu", "total waktu",
+	); ok {
+		w.DurationSeconds = parseShareDuration(v)
+	}
+	w.Calories = parseShareCalories(strings.Join(lines, "\n"))
+
+	if w.DistanceMeters > 0 && w.DurationSeconds > 0 {
+		return
+	}
+	var free []string
+	for i, line := range lines {
+		lower := strings.ToLower(line)
+		if used[i] || strings.Contains(lower, "pace") || strings.Contains(lower, "/km") || strings.Contains(lower, "http") {
+			continue
+		}
+		free = append(free, line)
+	}
+	m := ParseActivityMetrics(strings.Join(free, "\n"))
+	if w.DistanceMeters == 0 {
+		w.DistanceMeters = m.DistanceMeters
+	}
+	if w.DurationSeconds == 0 {
+		w.DurationSeconds = m.DurationSeconds
+	}
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Share-text parsers for the cardio apps members use besides Hevy. Each one
+// only decides how to spot its format and where the title lives; the stats
+// are read by fillShareStats since the apps print them in similar shapes.
+
+func containsFold(text string, markers ...string) bool {
+	lower := strings.ToLower(text)
+	for _, marker := range markers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstTitleLine returns the first line that is not a command, link,
+// labeled stat or one of the app's own marker lines.
+func firstTitleLine(lines []string, skip ...string) string {
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		if strings.HasPrefix(lower, "/") || strings.Contains(lower, "http") || containsFold(lower, skip...) {
+			continue
+		}
+		if clockDurationPattern.MatchString(lower) || (strings.ContainsAny(lower, "0123456789") && len(strings.Fields(lower)) <= 4) {
+			continue
+		}
+		return line
+	}
+	return ""
+}
+
+func newShareWorkout(source, title, message, fallbackType string, lines []string) *Workout {
+	w := &Workout{Source: source, Title: title}
+	if w.Title == "" {
+		w.Title = WorkoutSourceName(source)
+	}
+	fillShareStats(w, lines)
+	w.ActivityType = detectWorkoutType(title, message, fallbackType)
+	return w
+}
+
+type stravaParser struct{}
+
+func (stravaParser) Source() string { return WorkoutSourceStrava }
+
+func (stravaParser) Detect(message string) bool {
+	return containsFold(message, "strava.app.link", "strava.com/activities", "on strava", "di strava")
+}
+
+func (stravaParser) Parse(message string) *Workout {
+	lines := shareLines(message)
+	title := firstTitleLine(lines, "strava", "distance", "pace", "time", "elev", "calories", "jarak", "waktu")
+	return newShareWorkout(WorkoutSourceStrava, title, message, "", lines)
+}
+
+var nrcFinishTimePattern = regexp.MustCompile(`(?i)\b(?:in|dalam)\s+(\d{1,2}:\d{2}(?::\d{2})?)`)
+
+type nrcParser struct{}
+
+func (nrcParser) Source() string { return WorkoutSourceNRC }
+
+func (nrcParser) Detect(message string) bool {
+	return containsFold(message, "nike run club", "#nrc", "nike.com/nrc")
+}
+
+func (nrcParser) Parse(message string) *Workout {
+	lines := shareLines(message)
+	w := newShareWorkout(WorkoutSourceNRC, "", message, ActivityTypeRun, lines)
+	// NRC puts the finish time inline: "I just ran 5.01 km in 28:41".
+	if m := nrcFinishTimePattern.FindStringSubmatch(message); m != nil {
+		w.DurationSeconds = parseShareDuration(m[1])
+	}
+	return w
+}
+
+var garminTitlePattern = regexp.MustCompile(`(?i)garmin connect\s*:\s*(.+)`)
+
+type garminParser struct{}
+
+func (garminParser) Source() string { return WorkoutSourceGarmin }
+
+func (garminParser) Detect(message string) bool {
+	return containsFold(message, "garmin connect", "connect.garmin.com", "gar.mn/")
+}
+
+func (garminParser) Parse(message string) *Workout {
+	lines := shareLines(message)
+	var title string
+	if m := garminTitlePattern.FindStringSubmatch(message); m != nil && !strings.Contains(m[1], "http") {
+		title = strings.TrimSpace(m[1])
+	}
+	if title == "" {
+		title = firstTitleLine(lines, "garmin", "distance", "time", "calories", "jarak", "waktu")
+	}
+	return newShareWorkout(WorkoutSourceGarmin, title, message, "", lines)
+}
+
+type googleFitParser struct{}
+
+func (googleFitParser) Source() string { return WorkoutSourceGoogleFit }
+
+func (googleFitParser) Detect(message string) bool {
+	return containsFold(message, "google fit")
+}
+
+func (googleFitParser) Parse(message string) *Workout {
+	lines := shareLines(message)
+	title := firstTitleLine(lines, "google fit", "langkah", "steps")
+	return newShareWorkout(WorkoutSourceGoogleFit, title, message, "", lines)
+}
+
+type samsungHealthParser struct{}
+
+func (samsungHealthParser) Source() string { return WorkoutSourceSamsungHealth }
+
+func (samsungHealthParser) Detect(message string) bool {
+	return containsFold(message, "samsung health")
+}
+
+func (samsungHealthParser) Parse(message string) *Workout {
+	lines := shareLines(message)
+	title := firstTitleLine(lines, "samsung health", "durasi", "duration", "jarak", "distance", "kalori", "calories")
+	return newShareWorkout(WorkoutSourceSamsungHealth, title, message, "", lines)
+}
+
+type appleFitnessParser struct{}
+
+func (appleFitnessParser) Source() string { return WorkoutSourceAppleFitness }
+
+// Detect looks for the Fitness app's share footer, or an Apple mention next
+// to its "Workout Time" stat; members name their Apple Watch or "fitness
+// app" in plain reports too, so neither phrase is enough on its own.
+func (appleFitnessParser) Detect(message string) bool {
+	if containsFold(message, "shared from apple fitness", "shared via apple fitness", "sent from apple fitness") {
+		return true
+	}
+	return containsFold(message, "apple fitness", "apple watch") && containsFold(message, "workout time")
+}
+
+func (appleFitnessParser) Parse(message string) *Workout {
+	lines := shareLines(message)
+	title := firstTitleLine(lines, "apple", "fitness app", "workout time", "distance", "kilocalories", "calories", "heart rate", "pace")
+	return newShareWorkout(WorkoutSourceAppleFitness, title, message, "", lines)
+}
@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestParseWorkout_ShareFixtures(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Workout
+	}{
+		{
+			name: "strava",
+			message: `/lapor
+Afternoon Run
+Distance 5.21 km
+Pace 5:32 /km
+Time 28m 51s
+Calories 345 Cal
+Check out my activity on Strava: https://strava.app.link/AbC123`,
+			want: Workout{
+				Source: WorkoutSourceStrava, Title: "Afternoon Run", ActivityType: ActivityTypeRun,
+				DistanceMeters: 5210, DurationSeconds: 28*60 + 51, Calories: 345,
+			},
+		},
+		{
+			name:    "nike run club",
+			message: "/lapor I just ran 5,01 km in 28:41 with Nike Run Club. #NRC",
+			want: Workout{
+				Source: WorkoutSourceNRC, Title: "Nike Run Club", ActivityType: ActivityTypeRun,
+				DistanceMeters: 5010, DurationSeconds: 28*60 + 41,
+			},
+		},
+		{
+			name: "garmin connect",
+			message: `/lapor Check out my activity on Garmin Connect: Jakarta Running
+10.02 km
+Time 58:12
+612 kcal
+https://connect.garmin.com/modern/activity/123456`,
+			want: Workout{
+				Source: WorkoutSourceGarmin, Title: "Jakarta Running", ActivityType: ActivityTypeRun,
+				DistanceMeters: 10020, DurationSeconds: 58*60 + 12, Calories: 612,
+			},
+		},
+		{
+			name: "google fit",
+			message: `/lapor
+Jalan kaki pagi
+45 mnt · 3,2 km · 180 Kal
+4.512 langkah
+Dilacak dengan Google Fit`,
+			want: Workout{
+				Source: WorkoutSourceGoogleFit, Title: "Jalan kaki pagi", ActivityType: ActivityTypeWalk,
+				DistanceMeters: 3200, DurationSeconds: 45 * 60, Calories: 180,
+			},
+		},
+		{
+			name: "samsung health",
+			message: `/lapor
+Samsung Health
+Bersepeda sore
+Durasi 01:05:30
+Jarak 21,4 km
+Kalori 1.024 kkal`,
+			want: Workout{
+				Source: WorkoutSourceSamsungHealth, Title: "Bersepeda sore", ActivityType: ActivityTypeRide,
+				DistanceMeters: 21400, DurationSeconds: 3600 + 5*60 + 30, Calories: 1024,
+			},
+		},
+		{
+			name: "apple fitness",
+			message: `/lapor
+Outdoor Walk
+Workout Time
+0:42:08
+Distance 3.85KM
+Active Kilocalories 210KCAL
+Shared from Apple Fitness`,
+			want: Workout{
+				Source: WorkoutSourceAppleFitness, Title: "Outdoor Walk", ActivityType: ActivityTypeWalk,
+				DistanceMeters: 3850, DurationSeconds: 42*60 + 8, Calories: 210,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseWorkout(tt.message)
+			if got == nil {
+				t.Fatal("expected a workout, got nil")
+			}
+			if got.Source != tt.want.Source || got.Title != tt.want.Title || got.ActivityType != tt.want.ActivityType ||
+				got.DistanceMeters != tt.want.DistanceMeters || got.DurationSeconds != tt.want.DurationSeconds ||
+				got.Calories != tt.want.Calories {
+				t.Errorf("ParseWorkout() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWorkout_PlainReport(t *testing.T) {
+	if got := ParseWorkout("/lapor lari 5km 30 menit"); got != nil {
+		t.Fatalf("expected nil for a plain report, got %+v", *got)
+	}
+}
+
+func TestParseWorkout_AppleMentionIsNotAShare(t *testing.T) {
+	for _, message := range []string{
+		"/lapor jalan sore 30 menit pakai apple watch",
+		"/lapor lari 5km, dicatat di fitness app",
+		"/lapor yoga, habis ikut kelas apple fitness+",
+	} {
+		if got := ParseWorkout(message); got != nil {
+			t.Errorf("ParseWorkout(%q) = %+v, want nil", message, *got)
+		}
+	}
+}
+
+func TestParseWorkout_HevyTakesPrecedence(t *testing.T) {
+	got := ParseWorkout("/lapor\nToday at 9:46 AM · Hevy\nPush Day\nLogged with Hevy\n\nBench Press\nSet 1: 60kg x 8")
+	if got == nil || got.Source != WorkoutSourceHevy || got.ActivityType != ActivityTypeStrength {
+		t.Fatalf("expected a Hevy strength workout, got %+v", got)
+	}
+}
+
+type stubWorkoutParser struct{}
+
+func (stubWorkoutParser) Source() string                { return "stub" }
+func (stubWorkoutParser) Detect(message string) bool    { return message == "stub share" }
+func (stubWorkoutParser) Parse(message string) *Workout { return &Workout{Title: "Stub"} }
+
+func TestRegisterWorkoutParser(t *testing.T) {
+	saved := workoutParsers
+	defer func() { workoutParsers = saved }()
+
+	RegisterWorkoutParser(stubWorkoutParser{})
+	got := ParseWorkout("stub share")
+	if got == nil || got.Source != "stub" || got.Title != "Stub" {
+		t.Fatalf("expected the registered parser to handle the message, got %+v", got)
+	}
+}
+
+func TestRegisterWorkoutParser_WhileParsing(t *testing.T) {
+	saved := workoutParsers
+	defer func() { workoutParsers = saved }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterWorkoutParser(stubWorkoutParser{})
+		}()
+		go func() {
+			defer wg.Done()
+			ParseWorkout("/lapor lari 5km 30 menit")
+		}()
+	}
+	wg.Wait()
+
+	if got := ParseWorkout("stub share"); got == nil || got.Source != "stub" {
+		t.Fatalf("expected the registered parser to handle the message, got %+v", got)
+	}
+}
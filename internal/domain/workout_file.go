@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// WorkoutFileImport is an uploaded GPX, TCX or FIT file, keyed by the
+// WorkoutFileEvidenceHash of the recording it holds so the same activity
+// can't be reported twice.
+type WorkoutFileImport struct {
+	EvidenceHash        string    `json:"evidence_hash"`
+	UserID              string    `json:"-"`
+	FileName            string    `json:"file_name"`
+	Format              string    `json:"format"`
+	ActivityType        string    `json:"activity_type,omitempty"`
+	ActivityDate        time.Time `json:"activity_date"`
+	StartTime           time.Time `json:"start_time"`
+	DistanceMeters      int       `json:"distance_meters"`
+	DurationSeconds     int       `json:"duration_seconds"`
+	ElevationGainMeters float64   `json:"elevation_gain_meters"`
+	EventKey            string    `json:"event_key,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// WorkoutFileEvidenceHash identifies a recording by who made it and the
+// minute it started, not by the file's bytes: the same activity exported
+// again, or as another format, gives a different file. Sport, distance and
+// duration are left out because exports of one recording disagree on them,
+// and nobody starts two recordings in the same minute.
+func WorkoutFileEvidenceHash(userID string, startTime time.Time) string {
+	sum := sha256.Sum256([]byte(userID + "|" + startTime.UTC().Truncate(time.Minute).Format(time.RFC3339)))
+	return hex.EncodeToString(sum[:])
+}
+
+type WorkoutFileRepository interface {
+	// ClaimWorkoutFile records the import unless its evidence hash is
+	// already known, and reports whether it did.
+	ClaimWorkoutFile(ctx context.Context, file WorkoutFileImport) (bool, error)
+	// CompleteWorkoutFile stores the report event the file produced.
+	CompleteWorkoutFile(ctx context.Context, evidenceHash, eventKey string) error
+	// ReleaseWorkoutFile drops a claim whose report was not recorded, so
+	// the file can be sent again.
+	ReleaseWorkoutFile(ctx context.Context, evidenceHash string) error
+}
@@ -5,19 +5,21 @@ import (
 	"strings"
 )
 
-type HevyWorkout struct {
-	Title     string
-	Exercises []string
-	Time      string
-}
+type hevyParser struct{}
+
+func (hevyParser) Source() string { return WorkoutSourceHevy }
+
+func (hevyParser) Detect(message string) bool { return strings.Contains(message, "Hevy") }
 
-func ParseHevy(message string) *HevyWorkout {
+func (hevyParser) Parse(message string) *Workout { return ParseHevy(message) }
+
+func ParseHevy(message string) *Workout {
 	if !strings.Contains(message, "Hevy") {
 		return nil
 	}
 
 	lines := strings.Split(message, "\n")
-	workout := &HevyWorkout{}
+	workout := &Workout{Source: WorkoutSourceHevy}
 
 	// Regex to match "Today at 9:46 AM · Hevy" or similar
 	hevyHeader := regexp.MustCompile(`.*(· Hevy|Logged with Hevy|Hevy)`)
@@ -44,6 +46,8 @@ func ParseHevy(message string) *HevyWorkout {
 
 	// Extract exercises and time
 	exerciseMap := make(map[string]bool)
+	logIndex := make(map[string]int)
+	current := -1
 	for i, line := range lines {
 		trimmed := strings.TrimSpace(line)
 		if trimmed == "" {
@@ -64,8 +68,28 @@ func ParseHevy(message string) *HevyWorkout {
 				workout.Exercises = append(workout.Exercises, exercise)
 				exerciseMap[exercise] = true
 			}
+			current = -1
+			if exercise != "" && !strings.Contains(exercise, "Logged with Hevy") {
+				idx, ok := logIndex[exercise]
+				if !ok {
+					idx = len(workout.ExerciseLogs)
+					logIndex[exercise] = idx
+					workout.ExerciseLogs = append(workout.ExerciseLogs, ExerciseLog{Name: exercise})
+				}
+				current = idx
+			}
+		}
+
+		// Every "Set N:" line belongs to the exercise opened by the last "Set 1:".
+		if current >= 0 && setPrefixPattern.MatchString(trimmed) {
+			if set, ok := ParseExerciseSet(trimmed); ok {
+				workout.ExerciseLogs[current].Sets = append(workout.ExerciseLogs[current].Sets, set)
+			}
 		}
 	}
 
+	workout.DurationSeconds = parseShareDuration(workout.Time)
+	workout.ActivityType = detectWorkoutType(workout.Title, strings.Join(workout.Exercises, "\n"), ActivityTypeStrength)
+
 	return workout
 }
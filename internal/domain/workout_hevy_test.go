@@ -49,7 +49,7 @@ Set 2: 50s
 Time
 37m 11s`
 
-	expected := &HevyWorkout{
+	expected := &Workout{
 		Title: "Flexibility",
 		Exercises: []string{
 			"Warm Up",
@@ -67,7 +67,7 @@ Time
 	got := ParseHevy(message)
 
 	if got == nil {
-		t.Fatal("Expected HevyWorkout, got nil")
+		t.Fatal("Expected Workout, got nil")
 	}
 
 	if got.Title != expected.Title {
@@ -78,6 +78,10 @@ Time
 		t.Errorf("Time: expected %s, got %s", expected.Time, got.Time)
 	}
 
+	if got.Source != WorkoutSourceHevy || got.DurationSeconds != 37*60+11 || got.ActivityType != ActivityTypeYoga {
+		t.Errorf("normalized fields: got source %q duration %d type %q", got.Source, got.DurationSeconds, got.ActivityType)
+	}
+
 	if !reflect.DeepEqual(got.Exercises, expected.Exercises) {
 		t.Errorf("Exercises: expected %v, got %v", expected.Exercises, got.Exercises)
 	}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
+)
+
+func adminPhoneSet(phones []string) map[string]bool {
+	set := make(map[string]bool, len(phones))
+	for _, p := range phones {
+		if normalized, err := phone.Normalize(p); err == nil {
+			set[normalized] = true
+		}
+	}
+	return set
+}
+
+// AdminMiddleware only lets through authenticated users listed in
+// ADMIN_PHONES.
+func (s *Server) AdminMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return s.AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := UserIDFromContext(r.Context())
+		if !ok || !s.adminPhones[userID] {
+			s.writeJSON(w, http.StatusForbidden, map[string]string{"error": "Admin access required"})
+			return
+		}
+		next(w, r)
+	})
+}
+
+// HandleListStravaWebhooks lists inbox deliveries, newest first. Use
+// ?status=dead to see the ones that need a replay.
+func (s *Server) HandleListStravaWebhooks(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", domain.StravaWebhookPending, domain.StravaWebhookProcessed, domain.StravaWebhookDead:
+	default:
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid status"})
+		return
+	}
+
+	deliveries, err := usecase.NewStravaWebhookInboxUsecase(s.repo, s.processUC).List(r.Context(), status)
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if deliveries == nil {
+		deliveries = []domain.StravaWebhookDelivery{}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"webhooks": deliveries})
+}
+
+// HandleReplayStravaWebhook queues a delivery for another round of retries.
+func (s *Server) HandleReplayStravaWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid webhook ID"})
+		return
+	}
+
+	found, err := usecase.NewStravaWebhookInboxUsecase(s.repo, s.processUC).Replay(r.Context(), id, time.Now())
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if !found {
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "Webhook not found"})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]string{"status": domain.StravaWebhookPending})
+}
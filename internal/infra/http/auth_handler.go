@@ -2,31 +2,41 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
+	"math"
+	"net"
 	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
 	"github.com/fardannozami/whatsapp-gateway/internal/domain/phone"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// Claims extends JWT registered claims with the user's phone number.
+// Claims extends JWT registered claims with the user's phone number and
+// the session the token was issued for.
 type Claims struct {
 	jwt.RegisteredClaims
-	Phone string `json:"sub"`
+	Phone     string `json:"sub"`
+	SessionID string `json:"sid"`
 }
 
-// generateToken creates a signed HS256 JWT for the given phone number.
-func (s *Server) generateToken(phone string) (string, time.Time, error) {
-	expiry := time.Duration(s.jwtExpiryHours) * time.Hour
-	expiresAt := time.Now().Add(expiry)
+// generateToken creates a short-lived HS256 access token for a session.
+func (s *Server) generateToken(phone, sessionID string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(s.accessTokenTTL)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
-		Phone: phone,
+		Phone:     phone,
+		SessionID: sessionID,
 	})
 
 	tokenString, err := token.SignedString([]byte(s.jwtSecret))
@@ -37,7 +47,8 @@ func (s *Server) generateToken(phone string) (string, time.Time, error) {
 	return tokenString, expiresAt, nil
 }
 
-// HandleLogin authenticates a user by phone number and returns a JWT token.
+// HandleLogin starts a login: the bot DMs a one-time code to the number,
+// which the user then exchanges at /api/auth/verify.
 func (s *Server) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodOptions {
 		s.writeJSON(w, http.StatusNoContent, nil)
@@ -62,30 +73,302 @@ func (s *Server) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	report, err := s.repo.GetReport(r.Context(), normalized)
+	sent, err := s.loginUC.RequestCode(r.Context(), normalized, s.loginClient(r), time.Now())
+	switch {
+	case errors.Is(err, usecase.ErrLoginUnknownUser):
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "User tidak ditemukan"})
+	case errors.Is(err, usecase.ErrLoginThrottled):
+		retryAfter := int(math.Ceil(time.Until(sent.ResendAt).Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+		s.writeJSON(w, http.StatusTooManyRequests, map[string]string{
+			"error":     "Kode baru saja dikirim. Tunggu sebentar sebelum minta lagi.",
+			"resend_at": sent.ResendAt.Format(time.RFC3339),
+		})
+	case errors.Is(err, usecase.ErrLoginUnavailable):
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Login sedang tidak tersedia"})
+	case err != nil:
+		log.Printf("login code error: %v", err)
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Gagal mengirim kode login"})
+	default:
+		s.writeJSON(w, http.StatusAccepted, map[string]string{
+			"status":     "code_sent",
+			"expires_at": sent.ExpiresAt.Format(time.RFC3339),
+			"resend_at":  sent.ResendAt.Format(time.RFC3339),
+		})
+	}
+}
+
+// HandleVerifyLogin exchanges the code DM'd by HandleLogin for a JWT.
+func (s *Server) HandleVerifyLogin(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Phone string `json:"phone"`
+		Code  string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Body tidak valid"})
+		return
+	}
+
+	normalized, err := phone.Normalize(body.Phone)
 	if err != nil {
-		log.Printf("login DB error: %v", err)
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Nomor telepon tidak valid"})
+		return
+	}
+
+	report, err := s.loginUC.Verify(r.Context(), normalized, body.Code, s.loginClient(r), time.Now())
+	switch {
+	case errors.Is(err, usecase.ErrLoginInvalidCode):
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Kode login salah"})
+		return
+	case errors.Is(err, usecase.ErrLoginCodeExpired):
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Kode login sudah kedaluwarsa. Minta kode baru."})
+		return
+	case errors.Is(err, usecase.ErrLoginLocked):
+		s.writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "Terlalu banyak kode salah. Minta kode baru."})
+		return
+	case errors.Is(err, usecase.ErrLoginUnknownUser):
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "User tidak ditemukan"})
+		return
+	case errors.Is(err, usecase.ErrLoginUnavailable):
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Login sedang tidak tersedia"})
+		return
+	case err != nil:
+		log.Printf("login verify error: %v", err)
 		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Terjadi kesalahan"})
 		return
 	}
-	if report == nil {
+
+	s.startSession(w, r, report)
+}
+
+// HandleLoginLink exchanges {"token"} from a /dashboard link for a session.
+// The link itself opens the dashboard, which posts the token here, so a
+// link preview fetching the URL doesn't use it up.
+func (s *Server) HandleLoginLink(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Body tidak valid"})
+		return
+	}
+
+	report, err := s.loginUC.RedeemLink(r.Context(), body.Token, s.loginClient(r), time.Now())
+	switch {
+	case errors.Is(err, usecase.ErrLoginLinkInvalid):
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Link login sudah kedaluwarsa atau sudah dipakai. Kirim /dashboard lagi untuk link baru."})
+		return
+	case errors.Is(err, usecase.ErrLoginUnknownUser):
 		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "User tidak ditemukan"})
 		return
+	case err != nil:
+		log.Printf("login link error: %v", err)
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Terjadi kesalahan"})
+		return
 	}
+	s.startSession(w, r, report)
+}
+
+// startSession signs in a user whose login was just verified.
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request, report *domain.Report) {
+	session, err := s.sessionUC.Start(r.Context(), report.UserID, s.loginClient(r), time.Now())
+	if err != nil {
+		log.Printf("login session error: %v", err)
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Gagal membuat sesi"})
+		return
+	}
+	s.writeSession(w, session, map[string]string{"phone": report.UserID, "name": report.Name})
+}
 
-	tokenString, expiresAt, err := s.generateToken(normalized)
+// writeSession answers a login or refresh with a new access token and the
+// session's rotated refresh token.
+func (s *Server) writeSession(w http.ResponseWriter, session usecase.SessionToken, user map[string]string) {
+	tokenString, expiresAt, err := s.generateToken(session.Session.UserID, session.Session.ID)
 	if err != nil {
 		log.Printf("login token error: %v", err)
 		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Gagal membuat token"})
 		return
 	}
 
-	s.writeJSON(w, http.StatusOK, map[string]any{
-		"token":      tokenString,
-		"expires_at": expiresAt.Format(time.RFC3339),
-		"user": map[string]string{
-			"phone": normalized,
-			"name":  report.Name,
-		},
-	})
+	body := map[string]any{
+		"token":              tokenString,
+		"expires_at":         expiresAt.Format(time.RFC3339),
+		"refresh_token":      session.RefreshToken,
+		"refresh_expires_at": session.Session.ExpiresAt.Format(time.RFC3339),
+	}
+	if user != nil {
+		body["user"] = user
+	}
+	s.writeJSON(w, http.StatusOK, body)
+}
+
+// HandleRefreshToken swaps {"refresh_token"} for a new access token and
+// refresh token. Each refresh token works once.
+func (s *Server) HandleRefreshToken(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Body tidak valid"})
+		return
+	}
+
+	session, err := s.sessionUC.Refresh(r.Context(), body.RefreshToken, s.loginClient(r), time.Now())
+	if errors.Is(err, usecase.ErrSessionInvalid) {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Sesi sudah berakhir. Silakan login lagi."})
+		return
+	}
+	if err != nil {
+		log.Printf("token refresh error: %v", err)
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Terjadi kesalahan"})
+		return
+	}
+	s.writeSession(w, session, nil)
+}
+
+// HandleLogout ends the session of {"refresh_token"}. It needs no access
+// token, so a client whose access token already expired can still log out.
+func (s *Server) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Body tidak valid"})
+		return
+	}
+
+	if err := s.sessionUC.Logout(r.Context(), body.RefreshToken, time.Now()); err != nil {
+		log.Printf("logout error: %v", err)
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Terjadi kesalahan"})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]string{"status": "logged_out"})
+}
+
+// HandleListSessions lists the caller's signed-in devices. The one making
+// the request is marked current.
+func (s *Server) HandleListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	sessions, err := s.sessionUC.List(r.Context(), userID, time.Now())
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	currentID, _ := SessionIDFromContext(r.Context())
+	type sessionView struct {
+		domain.WebSession
+		Current bool `json:"current"`
+	}
+	views := make([]sessionView, 0, len(sessions))
+	for _, session := range sessions {
+		views = append(views, sessionView{WebSession: session, Current: session.ID == currentID})
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"sessions": views})
+}
+
+// HandleRevokeSession signs one of the caller's devices out.
+func (s *Server) HandleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	err := s.sessionUC.Revoke(r.Context(), userID, r.PathValue("id"), time.Now())
+	switch {
+	case errors.Is(err, usecase.ErrSessionNotFound):
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "Sesi tidak ditemukan"})
+	case err != nil:
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		s.writeJSON(w, http.StatusOK, map[string]string{"status": "revoked"})
+	}
+}
+
+// HandleListLogins shows the login audit log, newest first. ?user=<phone>
+// narrows it down to one user.
+func (s *Server) HandleListLogins(w http.ResponseWriter, r *http.Request) {
+	userID := ""
+	if raw := r.URL.Query().Get("user"); raw != "" {
+		normalized, err := phone.Normalize(raw)
+		if err != nil {
+			s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid phone number"})
+			return
+		}
+		userID = normalized
+	}
+
+	entries, err := s.loginUC.Audit(r.Context(), userID)
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if entries == nil {
+		entries = []domain.LoginAuditEntry{}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"logins": entries})
+}
+
+// loginClient identifies the caller for the login audit. X-Forwarded-For is
+// only believed when the connection comes from a trusted proxy, and then the
+// right-most hop that isn't a trusted proxy is the client; anything left of
+// it could have been written by the client itself.
+func (s *Server) loginClient(r *http.Request) usecase.LoginClient {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if s.trustedProxy(ip) {
+		hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				continue
+			}
+			ip = hop
+			if !s.trustedProxy(hop) {
+				break
+			}
+		}
+	}
+	return usecase.LoginClient{IP: ip, UserAgent: r.UserAgent()}
+}
+
+func (s *Server) trustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range s.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// trustedProxyPrefixes parses TRUSTED_PROXIES, where a bare IP stands for
+// itself. Invalid entries are skipped with a warning.
+func trustedProxyPrefixes(entries []string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range entries {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			log.Printf("ignoring invalid TRUSTED_PROXIES entry %q", entry)
+			continue
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes
 }
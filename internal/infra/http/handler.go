@@ -5,13 +5,14 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
 	"net/http"
+	"net/netip"
 	"os"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
 	"github.com/fardannozami/whatsapp-gateway/internal/config"
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/queue"
 	"go.mau.fi/whatsmeow"
 )
 
@@ -26,10 +28,19 @@ type Server struct {
 	repo           domain.ReportRepository
 	linkUC         *usecase.LinkStravaUsecase
 	processUC      *usecase.ProcessStravaWebhookUsecase
+	reportUC       *usecase.ReportActivityUsecase
+	userReportUC   *usecase.UserReportUsecase
+	loginUC        *usecase.LoginUsecase
+	sessionUC      *usecase.SessionUsecase
 	waClient       *whatsmeow.Client
 	verifyToken    string
 	jwtSecret      string
-	jwtExpiryHours int
+	accessTokenTTL time.Duration
+	adminPhones    map[string]bool
+	trustedProxies []netip.Prefix
+	appBaseURL     string
+	leaderboardUC  *usecase.PublicLeaderboardUsecase
+	streamUC       *usecase.EventStreamUsecase
 }
 
 func NewServer(repo domain.ReportRepository, linkUC *usecase.LinkStravaUsecase, processUC *usecase.ProcessStravaWebhookUsecase, waClient *whatsmeow.Client, cfg config.Config) *Server {
@@ -37,13 +48,43 @@ func NewServer(repo domain.ReportRepository, linkUC *usecase.LinkStravaUsecase,
 		repo:           repo,
 		linkUC:         linkUC,
 		processUC:      processUC,
+		loginUC:        usecase.NewLoginUsecase(repo, cfg),
+		sessionUC:      usecase.NewSessionUsecase(repo, time.Duration(cfg.RefreshTokenDays)*24*time.Hour),
 		waClient:       waClient,
 		verifyToken:    cfg.StravaVerifyToken,
 		jwtSecret:      cfg.JWTSecret,
-		jwtExpiryHours: cfg.JWTExpiryHours,
+		accessTokenTTL: time.Duration(cfg.AccessTokenMinutes) * time.Minute,
+		adminPhones:    adminPhoneSet(cfg.AdminPhones),
+		trustedProxies: trustedProxyPrefixes(cfg.TrustedProxies),
+		appBaseURL:     strings.TrimSuffix(cfg.AppBaseURL, "/"),
+		leaderboardUC:  usecase.NewPublicLeaderboardUsecase(repo),
 	}
 }
 
+// SetPublicLeaderboardUsecase shares a leaderboard usecase whose cache is
+// invalidated by bot events. The default one only expires its cache.
+func (s *Server) SetPublicLeaderboardUsecase(leaderboardUC *usecase.PublicLeaderboardUsecase) {
+	s.leaderboardUC = leaderboardUC
+}
+
+// SetReportUsecase enables the endpoints that file reports. They share the
+// bot's report usecase so goal, raid and record hooks fire as for /lapor.
+func (s *Server) SetReportUsecase(reportUC *usecase.ReportActivityUsecase) {
+	s.reportUC = reportUC
+}
+
+// SetUserReportUsecase enables reporting, side quests and cancelling from
+// the dashboard and the mobile app.
+func (s *Server) SetUserReportUsecase(userReportUC *usecase.UserReportUsecase) {
+	s.userReportUC = userReportUC
+}
+
+// SetMessageSender lets the login endpoint DM one-time codes. Without it,
+// nobody can log in to the dashboard.
+func (s *Server) SetMessageSender(sender *queue.MessageSender) {
+	s.loginUC.SetSender(sender)
+}
+
 func (s *Server) RegisterHandlers(mux *http.ServeMux) {
 	mux.HandleFunc("/health", s.HandleHealth)
 	mux.HandleFunc("/strava/link", s.HandleStravaLink)
@@ -53,15 +94,58 @@ func (s *Server) RegisterHandlers(mux *http.ServeMux) {
 	mux.HandleFunc("/api/summary", s.HandleSummary)
 	mux.HandleFunc("/api/motivation", s.HandleMotivation)
 	mux.HandleFunc("GET /api/jobs", s.HandleListJobs)
+	mux.HandleFunc("GET /api/stream", s.HandleStream)
+	mux.HandleFunc("GET /api/profiles/{key}", s.HandleGetPublicProfile)
+	mux.HandleFunc("GET /api/profiles/{key}/card.png", s.HandlePublicProfileCard)
+	mux.HandleFunc("GET /p/{key}", s.HandleProfilePage)
 	mux.HandleFunc("/", s.HandleStatic)
 
 	mux.HandleFunc("POST /api/auth/login", s.HandleLogin)
+	mux.HandleFunc("POST /api/auth/verify", s.HandleVerifyLogin)
+	mux.HandleFunc("POST /api/auth/link", s.HandleLoginLink)
+	mux.HandleFunc("POST /api/auth/refresh", s.HandleRefreshToken)
+	mux.HandleFunc("POST /api/auth/logout", s.HandleLogout)
 
 	mux.HandleFunc("GET /api/user", s.AuthMiddleware(s.HandleGetUser))
 	mux.HandleFunc("POST /api/user", s.AuthMiddleware(s.HandleGetUserByPhone))
+	mux.HandleFunc("GET /api/user/stream", s.AuthMiddleware(s.HandleUserStream))
+	mux.HandleFunc("GET /api/user/sessions", s.AuthMiddleware(s.HandleListSessions))
+	mux.HandleFunc("DELETE /api/user/sessions/{id}", s.AuthMiddleware(s.HandleRevokeSession))
+	mux.HandleFunc("GET /api/user/reports", s.AuthMiddleware(s.HandleListReports))
+	mux.HandleFunc("POST /api/user/reports", s.AuthMiddleware(s.HandleCreateReport))
+	mux.HandleFunc("DELETE /api/user/reports/{id}", s.AuthMiddleware(s.HandleCancelReport))
+	mux.HandleFunc("POST /api/user/sidequests", s.AuthMiddleware(s.HandleCreateSideQuest))
 	mux.HandleFunc("PATCH /api/user/name", s.AuthMiddleware(s.HandleUpdateName))
+	mux.HandleFunc("GET /api/user/profile", s.AuthMiddleware(s.HandleGetProfileSettings))
+	mux.HandleFunc("PATCH /api/user/profile", s.AuthMiddleware(s.HandleUpdateProfileSettings))
 	mux.HandleFunc("PATCH /api/user/job", s.AuthMiddleware(s.HandleSelectJob))
 	mux.HandleFunc("PATCH /api/user/goal", s.AuthMiddleware(s.HandleSetGoal))
+	mux.HandleFunc("GET /api/user/lifts", s.AuthMiddleware(s.HandleListLifts))
+	mux.HandleFunc("GET /api/user/lifts/{exercise}", s.AuthMiddleware(s.HandleLiftHistory))
+	mux.HandleFunc("GET /api/user/records", s.AuthMiddleware(s.HandleGetRecords))
+	mux.HandleFunc("POST /api/user/workouts/import", s.AuthMiddleware(s.HandleImportWorkoutFile))
+	mux.HandleFunc("GET /api/user/strava/link", s.AuthMiddleware(s.HandleStartStravaLink))
+	mux.HandleFunc("POST /api/user/strava/backfill", s.AuthMiddleware(s.HandleStravaBackfill))
+	mux.HandleFunc("GET /api/user/strava", s.AuthMiddleware(s.HandleGetStrava))
+	mux.HandleFunc("DELETE /api/user/strava", s.AuthMiddleware(s.HandleUnlinkStrava))
+	mux.HandleFunc("PATCH /api/user/strava/preferences", s.AuthMiddleware(s.HandleUpdateStravaPreferences))
+
+	mux.HandleFunc("GET /api/admin/logins", s.AdminMiddleware(s.HandleListLogins))
+	mux.HandleFunc("GET /api/admin/strava/webhooks", s.AdminMiddleware(s.HandleListStravaWebhooks))
+	mux.HandleFunc("POST /api/admin/strava/webhooks/{id}/replay", s.AdminMiddleware(s.HandleReplayStravaWebhook))
+	mux.HandleFunc("GET /api/admin/integrations", s.AdminMiddleware(s.HandleListIntegrations))
+	mux.HandleFunc("POST /api/admin/integrations", s.AdminMiddleware(s.HandleCreateIntegration))
+	mux.HandleFunc("POST /api/admin/integrations/{id}/rotate", s.AdminMiddleware(s.HandleRotateIntegration))
+	mux.HandleFunc("PATCH /api/admin/integrations/{id}", s.AdminMiddleware(s.HandleUpdateIntegration))
+	mux.HandleFunc("GET /api/admin/webhooks", s.AdminMiddleware(s.HandleListWebhooks))
+	mux.HandleFunc("POST /api/admin/webhooks", s.AdminMiddleware(s.HandleCreateWebhook))
+	mux.HandleFunc("PATCH /api/admin/webhooks/{id}", s.AdminMiddleware(s.HandleUpdateWebhook))
+	mux.HandleFunc("DELETE /api/admin/webhooks/{id}", s.AdminMiddleware(s.HandleDeleteWebhook))
+	mux.HandleFunc("POST /api/admin/webhooks/{id}/rotate", s.AdminMiddleware(s.HandleRotateWebhook))
+	mux.HandleFunc("GET /api/admin/webhooks/deliveries", s.AdminMiddleware(s.HandleListWebhookDeliveries))
+	mux.HandleFunc("POST /api/admin/webhooks/deliveries/{id}/replay", s.AdminMiddleware(s.HandleReplayWebhookDelivery))
+
+	mux.HandleFunc("POST /api/integrations/{id}/activities", s.HandleIntegrationActivity)
 }
 
 func (s *Server) HandleHealth(w http.ResponseWriter, r *http.Request) {
@@ -74,31 +158,171 @@ func (s *Server) HandleHealth(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, "OK")
 }
 
+// HandleStravaLink redeems the one-time link the bot DMs for /strava and
+// sends the user on to Strava's consent page.
 func (s *Server) HandleStravaLink(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		http.Error(w, "User ID is required", http.StatusBadRequest)
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Error(w, "Link token is required", http.StatusBadRequest)
 		return
 	}
 
-	name := r.URL.Query().Get("name")
-	if name == "" {
-		name = "User"
+	authURL, err := s.linkUC.RedeemLinkToken(r.Context(), token, time.Now())
+	if errors.Is(err, domain.ErrInvalidStravaState) {
+		http.Error(w, "Link sudah kedaluwarsa atau sudah dipakai. Kirim /strava lagi untuk link baru.", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Strava link error: %v", err)
+		http.Error(w, "Failed to start Strava linking", http.StatusInternalServerError)
+		return
 	}
-	authURL := s.linkUC.GetAuthURL(userID, name)
 	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
 }
 
+// HandleStartStravaLink returns the Strava authorization URL for the
+// logged-in dashboard user.
+func (s *Server) HandleStartStravaLink(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	name := ""
+	if report, err := s.repo.GetReport(r.Context(), userID); err == nil && report != nil {
+		name = report.Name
+	}
+	authURL, err := s.linkUC.StartAuth(r.Context(), userID, name, time.Now())
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]string{"auth_url": authURL})
+}
+
+// HandleStravaBackfill imports the user's Strava activities since the given
+// date ("YYYY-MM-DD"), bounded to the current season. Activities that were
+// already imported are skipped, so it is safe to call again.
+func (s *Server) HandleStravaBackfill(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	var body struct {
+		Since string `json:"since"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Body tidak valid"})
+		return
+	}
+	since, err := time.Parse(time.DateOnly, body.Since)
+	if err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "since harus berformat YYYY-MM-DD"})
+		return
+	}
+
+	result, err := usecase.NewSyncStravaUsecase(s.repo, s.processUC).Backfill(r.Context(), userID, since, time.Now())
+	if errors.Is(err, usecase.ErrStravaNotLinked) {
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "Akun Strava belum terhubung"})
+		return
+	}
+	if err != nil {
+		log.Printf("Strava backfill failed for %s: %v", userID, err)
+		s.writeJSON(w, http.StatusBadGateway, map[string]string{"error": "Gagal mengambil aktivitas dari Strava"})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// HandleGetStrava returns whether the user is linked and their auto-report
+// preferences.
+func (s *Server) HandleGetStrava(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	status, err := usecase.NewStravaAccountUsecase(s.repo, s.processUC).Status(r.Context(), userID)
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, status)
+}
+
+// HandleUnlinkStrava revokes the app on Strava and deletes the user's tokens.
+func (s *Server) HandleUnlinkStrava(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := usecase.NewStravaAccountUsecase(s.repo, s.processUC).Unlink(r.Context(), userID)
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if !result.Unlinked {
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "Akun Strava belum terhubung"})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// HandleUpdateStravaPreferences changes which activity types auto-report and
+// whether auto-reports post to the group. Omitted fields are left as is.
+func (s *Server) HandleUpdateStravaPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	var body struct {
+		ActivityTypes *[]string `json:"activity_types"`
+		PostToGroup   *bool     `json:"post_to_group"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Body tidak valid"})
+		return
+	}
+	var activityTypes []string
+	if body.ActivityTypes != nil {
+		activityTypes = append([]string{}, *body.ActivityTypes...)
+	}
+
+	prefs, err := usecase.NewStravaAccountUsecase(s.repo, s.processUC).UpdatePreferences(r.Context(), userID, activityTypes, body.PostToGroup, time.Now())
+	if errors.Is(err, usecase.ErrUnknownStravaType) {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, prefs)
+}
+
 func (s *Server) HandleStravaCallback(w http.ResponseWriter, r *http.Request) {
 	code := r.URL.Query().Get("code")
-	userID := r.URL.Query().Get("state") // we passed userID in 'state'
+	state := r.URL.Query().Get("state")
 
-	if code == "" || userID == "" {
+	if code == "" || state == "" {
 		http.Error(w, "Invalid callback data", http.StatusBadRequest)
 		return
 	}
 
-	if err := s.linkUC.HandleCallback(r.Context(), code, userID); err != nil {
+	err := s.linkUC.HandleCallback(r.Context(), code, state, time.Now())
+	if errors.Is(err, domain.ErrInvalidStravaState) {
+		http.Error(w, "Invalid or expired state", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
 		log.Printf("Strava callback error: %v", err)
 		http.Error(w, fmt.Sprintf("Failed to link Strava: %v", err), http.StatusInternalServerError)
 		return
@@ -135,11 +359,13 @@ func (s *Server) HandleStravaWebhook(w http.ResponseWriter, r *http.Request) {
 
 		log.Printf("Received Strava webhook: %+v", event)
 
-		go func() {
-			if err := s.processUC.Execute(context.Background(), s.waClient, event); err != nil {
-				log.Printf("Strava webhook execution error: %v", err)
-			}
-		}()
+		// Persist first and let the inbox worker process it; a non-2xx
+		// response makes Strava redeliver.
+		if _, err := usecase.NewStravaWebhookInboxUsecase(s.repo, s.processUC).Enqueue(r.Context(), event, time.Now()); err != nil {
+			log.Printf("Failed to store Strava webhook: %v", err)
+			http.Error(w, "Failed to store event", http.StatusInternalServerError)
+			return
+		}
 
 		w.WriteHeader(http.StatusOK)
 		return
@@ -198,6 +424,8 @@ type EnrichedReport struct {
 	ActiveDaysInWindow    int                         `json:"active_days_in_window,omitempty"`
 	ActiveGoal            *PersonalGoal               `json:"active_goal,omitempty"`
 	TodaySideQuests       []domain.QuestTask          `json:"today_side_quests,omitempty"`
+	// Rank is the position on a ranked leaderboard page.
+	Rank int `json:"rank,omitempty"`
 }
 
 // TierProgress is precomputed for the web UI so templates/components only render it.
@@ -253,12 +481,7 @@ type GlobalSummary struct {
 // React keys and leaderboard tie-breakers unique without exposing the real
 // phone number; the two-digit suffix is only a familiar visual hint.
 func maskPhone(phone string) string {
-	sum := sha256.Sum256([]byte(phone))
-	digest := hex.EncodeToString(sum[:])[:10]
-	if len(phone) <= 2 {
-		return "hunter-" + digest
-	}
-	return "hunter-" + digest + "-" + phone[len(phone)-2:]
+	return domain.PublicUserID(phone)
 }
 
 func buildTierProgress(value, currentMin int, nextMin int, nextName, nextIcon string, isMax bool) TierProgress {
@@ -459,28 +682,6 @@ func buildPersonalGoal(goal *domain.WeeklyGoal, activities []domain.GoalActivity
 	}
 }
 
-func sortEnrichedReportsBySeason(reports []EnrichedReport) {
-	sort.SliceStable(reports, func(i, j int) bool {
-		a, b := reports[i], reports[j]
-		if a.SeasonalPoints != b.SeasonalPoints {
-			return a.SeasonalPoints > b.SeasonalPoints
-		}
-		if a.SeasonalActivityCount != b.SeasonalActivityCount {
-			return a.SeasonalActivityCount > b.SeasonalActivityCount
-		}
-		if a.Streak != b.Streak {
-			return a.Streak > b.Streak
-		}
-		if a.TotalActiveDays != b.TotalActiveDays {
-			return a.TotalActiveDays > b.TotalActiveDays
-		}
-		if a.Name != b.Name {
-			return a.Name < b.Name
-		}
-		return a.UserID < b.UserID
-	})
-}
-
 func enrichReport(r *domain.Report, today time.Time, weekActivity []bool, weekActiveDays int) EnrichedReport {
 	return enrichReportWithMasking(r, today, weekActivity, weekActiveDays, true)
 }
@@ -580,9 +781,7 @@ func enrichReportWithMasking(r *domain.Report, today time.Time, weekActivity []b
 }
 
 func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	setCORSHeaders(w)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(v); err != nil {
@@ -590,62 +789,151 @@ func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
 	}
 }
 
-// AssemblePublicLeaderboard builds the EnrichedReport list for the public web leaderboard (klasemen publik).
-// All tab-specific sorting, filtering and pagination is intentionally client-side (React/TS) for low-latency metric switching.
-// Backend only guarantees canonical enrichment (week activity dots computed from activity_logs, RPG fields, masking).
-//
-// Clean Go: this assembler isolates data transformation and N queries from the HTTP handler.
-// Note (ponytail): the per-user GetUserActivityDates loop is N+1. Acceptable ceiling for current scale (<100 users);
-// upgrade path: pre-load activity by week range or pre-join + group.
-func (s *Server) AssemblePublicLeaderboard(ctx context.Context) ([]EnrichedReport, error) {
-	reports, err := s.repo.GetAllReports(ctx)
+// writeCachedJSON writes v with an ETag of its encoding, answering 304
+// when the request's If-None-Match already has it. Clients revalidate on
+// every request, so a stale copy is never served.
+func (s *Server) writeCachedJSON(w http.ResponseWriter, r *http.Request, v any) {
+	body, err := json.Marshal(v)
 	if err != nil {
-		return nil, err
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
 	}
-	reports = domain.DedupReportsByUserID(reports, domain.SortBySeasonRank)
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
 
-	now := time.Now()
-	today := domain.GetToday(now)
-	// Attribution-aware ISO week (derives from GetToday, matching the
-	// activity_date keys produced by logActivity/UpsertReportWithActivity).
-	// All entry points (main /lapor, /lapor sidequest via ExecuteSideQuest,
-	// and Strava auto-reports) use GetToday for the stored date.
-	// Using matching week start keeps week_active_days and week_activity
-	// dots consistent with what is actually logged (no cutoff <-> strict drift).
-	// Manual user counts and the week tab will now align.
-	weekStart := domain.GetStartOfISOWeek(now)
-
-	enriched := make([]EnrichedReport, 0, len(reports))
-	for _, rep := range reports {
-		dates, err := s.repo.GetUserActivityDates(ctx, rep.UserID)
-		if err != nil {
-			return nil, err
+	setCORSHeaders(w)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		log.Printf("Error writing JSON: %v", err)
+	}
+}
+
+func setCORSHeaders(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	w.Header().Set("Access-Control-Expose-Headers", "ETag")
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. The
+// comparison is weak, as RFC 9110 asks for If-None-Match.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
 		}
-		weekAct, weekDays := buildWeekActivity(dates, weekStart)
-		row := enrichReport(rep, today, weekAct, weekDays)
-		row.CurrentDailyStreak, row.LongestDailyStreak = buildDailyStreaks(dates, today)
-		enriched = append(enriched, row)
 	}
+	return false
+}
 
-	// Season sort is our canonical order (mirrors WA leaderboard ordering).
-	// Client re-sorts for its activeTab.
-	sortEnrichedReportsBySeason(enriched)
+// AssemblePublicLeaderboard builds the whole public leaderboard (klasemen
+// publik) in season order, inactive users included, for clients that still
+// rank and filter it themselves.
+func (s *Server) AssemblePublicLeaderboard(ctx context.Context) ([]EnrichedReport, error) {
+	page, err := s.leaderboardUC.All(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	enriched := make([]EnrichedReport, 0, len(page.Standings))
+	for _, standing := range page.Standings {
+		enriched = append(enriched, enrichStanding(standing, page.Today, page.WeekStart))
+	}
 	return enriched, nil
 }
 
+// enrichStanding enriches a leaderboard row. Week dots count the
+// attribution-aware ISO week, matching the activity_date keys reports are
+// logged under.
+func enrichStanding(standing domain.LeaderboardStanding, today, weekStart time.Time) EnrichedReport {
+	weekAct, weekDays := buildWeekActivity(standing.WeekDates, weekStart)
+	row := enrichReport(standing.Report, today, weekAct, weekDays)
+	row.CurrentDailyStreak = standing.CurrentDailyStreak
+	row.LongestDailyStreak = standing.LongestDailyStreak
+	return row
+}
+
+// LeaderboardPage is a ranked page of the public leaderboard.
+type LeaderboardPage struct {
+	Entries    []EnrichedReport          `json:"entries"`
+	Total      int                       `json:"total"`
+	NextCursor string                    `json:"next_cursor,omitempty"`
+	Sort       domain.LeaderboardSortKey `json:"sort"`
+	Season     int                       `json:"season"`
+}
+
+// HandleLeaderboard serves the public leaderboard. Without query
+// parameters it returns the whole list as before; with any of sort, job,
+// season, search, cursor or limit it returns one ranked LeaderboardPage.
 func (s *Server) HandleLeaderboard(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodOptions {
 		s.writeJSON(w, http.StatusNoContent, nil)
 		return
 	}
 
-	enriched, err := s.AssemblePublicLeaderboard(r.Context())
-	if err != nil {
+	if r.URL.RawQuery == "" {
+		enriched, err := s.AssemblePublicLeaderboard(r.Context())
+		if err != nil {
+			s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		s.writeCachedJSON(w, r, enriched)
+		return
+	}
+
+	params := r.URL.Query()
+	query := usecase.PublicLeaderboardQuery{
+		Sort:   domain.LeaderboardSortKey(params.Get("sort")),
+		Job:    params.Get("job"),
+		Search: params.Get("search"),
+		Cursor: params.Get("cursor"),
+	}
+	for name, dst := range map[string]*int{"limit": &query.Limit, "season": &query.Season} {
+		raw := params.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Parameter " + name + " harus berupa angka"})
+			return
+		}
+		*dst = n
+	}
+
+	page, err := s.leaderboardUC.List(r.Context(), query, time.Now())
+	switch {
+	case errors.Is(err, usecase.ErrPublicLeaderboardInvalid):
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Parameter klasemen tidak valid"})
+		return
+	case errors.Is(err, usecase.ErrPublicLeaderboardUnavailable):
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Klasemen belum tersedia"})
+		return
+	case err != nil:
 		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
 
-	s.writeJSON(w, http.StatusOK, enriched)
+	response := LeaderboardPage{
+		Entries:    make([]EnrichedReport, 0, len(page.Standings)),
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+		Sort:       page.Sort,
+		Season:     page.Season,
+	}
+	for i, standing := range page.Standings {
+		row := enrichStanding(standing, page.Today, page.WeekStart)
+		row.Rank = page.Offset + i + 1
+		response.Entries = append(response.Entries, row)
+	}
+	s.writeCachedJSON(w, r, response)
 }
 
 func (s *Server) HandleSummary(w http.ResponseWriter, r *http.Request) {
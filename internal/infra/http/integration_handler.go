@@ -0,0 +1,145 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const (
+	integrationTimestampHeader = "X-Lapor-Timestamp"
+	integrationSignatureHeader = "X-Lapor-Signature"
+	maxIntegrationBodyBytes    = 64 << 10
+)
+
+// HandleIntegrationActivity accepts one activity from a third-party
+// integration. The request is signed as described by
+// usecase.SignIntegrationRequest, with the timestamp and signature in the
+// X-Lapor-Timestamp and X-Lapor-Signature headers. A new activity answers
+// 201; resubmitting an external ID answers 200 with the original result.
+func (s *Server) HandleIntegrationActivity(w http.ResponseWriter, r *http.Request) {
+	if s.reportUC == nil {
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Integrations are not enabled"})
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIntegrationBodyBytes))
+	if err != nil {
+		s.writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "Body too large"})
+		return
+	}
+
+	uc := usecase.NewIntegrationUsecase(s.repo, s.reportUC)
+	now := time.Now()
+	integration, err := uc.Authenticate(r.Context(), r.PathValue("id"),
+		r.Header.Get(integrationTimestampHeader), r.Header.Get(integrationSignatureHeader), body, now)
+	if errors.Is(err, usecase.ErrIntegrationUnauthorized) {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Invalid signature"})
+		return
+	}
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	var input usecase.IntegrationActivityInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON body"})
+		return
+	}
+	result, err := uc.Submit(r.Context(), integration, input, now)
+	switch {
+	case errors.Is(err, usecase.ErrIntegrationInvalidActivity):
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, usecase.ErrIntegrationUnknownUser):
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, usecase.ErrIntegrationInProgress):
+		s.writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		log.Printf("Integration %s activity %q failed: %v", integration.ID, input.ExternalID, err)
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	case result.Duplicate:
+		s.writeJSON(w, http.StatusOK, result)
+	default:
+		s.writeJSON(w, http.StatusCreated, result)
+	}
+}
+
+// HandleListIntegrations lists integrations without their secrets.
+func (s *Server) HandleListIntegrations(w http.ResponseWriter, r *http.Request) {
+	integrations, err := usecase.NewIntegrationUsecase(s.repo, s.reportUC).List(r.Context())
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if integrations == nil {
+		integrations = []domain.Integration{}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"integrations": integrations})
+}
+
+// HandleCreateIntegration registers an integration from {"id", "name"} and
+// returns its secret. The secret is not shown again; rotate to get a new one.
+func (s *Server) HandleCreateIntegration(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON body"})
+		return
+	}
+
+	integration, secret, err := usecase.NewIntegrationUsecase(s.repo, s.reportUC).Create(r.Context(), body.ID, body.Name, time.Now())
+	switch {
+	case errors.Is(err, usecase.ErrIntegrationInvalidID):
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, usecase.ErrIntegrationExists):
+		s.writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		s.writeJSON(w, http.StatusCreated, map[string]any{"integration": integration, "secret": secret})
+	}
+}
+
+// HandleRotateIntegration issues a new secret. The previous one stays valid
+// until previous_secret_expires_at.
+func (s *Server) HandleRotateIntegration(w http.ResponseWriter, r *http.Request) {
+	integration, secret, err := usecase.NewIntegrationUsecase(s.repo, s.reportUC).Rotate(r.Context(), r.PathValue("id"), time.Now())
+	switch {
+	case errors.Is(err, usecase.ErrIntegrationNotFound):
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case err != nil:
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		s.writeJSON(w, http.StatusOK, map[string]any{"integration": integration, "secret": secret})
+	}
+}
+
+// HandleUpdateIntegration enables or disables an integration with
+// {"enabled": bool}.
+func (s *Server) HandleUpdateIntegration(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Enabled == nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Body must set enabled"})
+		return
+	}
+
+	integration, err := usecase.NewIntegrationUsecase(s.repo, s.reportUC).SetEnabled(r.Context(), r.PathValue("id"), *body.Enabled, time.Now())
+	switch {
+	case errors.Is(err, usecase.ErrIntegrationNotFound):
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case err != nil:
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		s.writeJSON(w, http.StatusOK, integration)
+	}
+}
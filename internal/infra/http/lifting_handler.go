@@ -0,0 +1,60 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// HandleListLifts returns every exercise the user has logged Hevy sets for,
+// with their best e1RM and volume.
+func (s *Server) HandleListLifts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	exercises, err := usecase.NewLiftingUsecase(s.repo).Exercises(r.Context(), userID)
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if exercises == nil {
+		exercises = []domain.LiftingExerciseSummary{}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"exercises": exercises})
+}
+
+// HandleLiftHistory returns the sessions of one exercise, oldest first, for
+// progress charts. The path segment is the exercise name or its key.
+func (s *Server) HandleLiftHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	exercise := strings.TrimSpace(r.PathValue("exercise"))
+	if exercise == "" {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Nama latihan wajib diisi"})
+		return
+	}
+
+	history, err := usecase.NewLiftingUsecase(s.repo).History(r.Context(), userID, exercise)
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if len(history) == 0 {
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "Riwayat latihan tidak ditemukan"})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"exercise_key": domain.ExerciseKey(exercise),
+		"exercise":     history[len(history)-1].Exercise,
+		"history":      history,
+	})
+}
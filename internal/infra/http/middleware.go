@@ -2,18 +2,24 @@ package http
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-type contextKey struct{}
+type contextKey struct{ name string }
 
-var userIDContextKey = contextKey{}
+var (
+	userIDContextKey    = contextKey{"user"}
+	sessionIDContextKey = contextKey{"session"}
+)
 
-// AuthMiddleware validates the Authorization Bearer token and injects the
-// authenticated user's phone number into the request context.
+// AuthMiddleware validates the Authorization Bearer token, checks its
+// session hasn't been revoked and injects the authenticated user's phone
+// number into the request context.
 func (s *Server) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -40,12 +46,24 @@ func (s *Server) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		if claims.Phone == "" {
+		if claims.Phone == "" || claims.SessionID == "" {
 			s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Invalid token claims"})
 			return
 		}
 
+		active, err := s.sessionUC.Active(r.Context(), claims.Phone, claims.SessionID, time.Now())
+		if err != nil {
+			log.Printf("session check error: %v", err)
+			s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Terjadi kesalahan"})
+			return
+		}
+		if !active {
+			s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Session has been revoked"})
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), userIDContextKey, claims.Phone)
+		ctx = context.WithValue(ctx, sessionIDContextKey, claims.SessionID)
 		next(w, r.WithContext(ctx))
 	}
 }
@@ -55,3 +73,9 @@ func UserIDFromContext(ctx context.Context) (string, bool) {
 	id, ok := ctx.Value(userIDContextKey).(string)
 	return id, ok
 }
+
+// SessionIDFromContext extracts the session of the authenticated request.
+func SessionIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(sessionIDContextKey).(string)
+	return id, ok
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// PersonalRecordView is one current record on the dashboard, with the
+// entries that led up to it.
+type PersonalRecordView struct {
+	RecordType   string                  `json:"record_type"`
+	Name         string                  `json:"name"`
+	Icon         string                  `json:"icon"`
+	Unit         string                  `json:"unit"`
+	Value        int                     `json:"value"`
+	DisplayValue string                  `json:"display_value"`
+	ActivityDate string                  `json:"activity_date"`
+	AchievedAt   string                  `json:"achieved_at"`
+	History      []domain.PersonalRecord `json:"history"`
+}
+
+// HandleGetRecords lists the user's current personal records with dates.
+func (s *Server) HandleGetRecords(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	current, history, err := usecase.NewPersonalRecordUsecase(s.repo).Current(r.Context(), userID)
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	views := make([]PersonalRecordView, 0, len(current))
+	for _, rec := range current {
+		t := domain.FindPersonalRecordType(rec.RecordType)
+		view := PersonalRecordView{
+			RecordType:   rec.RecordType,
+			Name:         t.Name,
+			Icon:         t.Icon,
+			Unit:         t.Unit,
+			Value:        rec.Value,
+			DisplayValue: t.FormatValue(rec.Value),
+			ActivityDate: rec.ActivityDate.Format(time.DateOnly),
+			AchievedAt:   rec.AchievedAt.Format(time.RFC3339),
+			History:      []domain.PersonalRecord{},
+		}
+		for _, h := range history {
+			if h.RecordType == rec.RecordType {
+				view.History = append(view.History, h)
+			}
+		}
+		views = append(views, view)
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"records": views})
+}
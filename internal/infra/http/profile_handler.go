@@ -0,0 +1,304 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/render"
+)
+
+const (
+	// profileCalendarDays is the streak calendar on a public profile:
+	// twelve full weeks.
+	profileCalendarDays = 84
+	// profileCardMaxAge lets link-preview crawlers reuse a hunter card for
+	// a while; the ETag still changes as soon as the hunter progresses.
+	profileCardMaxAge = 5 * time.Minute
+	// hunterCardVersion goes into the card's ETag; bump it when the
+	// card's drawing changes so cached cards are redrawn.
+	hunterCardVersion = 1
+)
+
+// ProfileBadge is a badge showcased on a public profile.
+type ProfileBadge struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Emoji string `json:"emoji"`
+}
+
+// PublicProfileResponse is a hunter's public profile page.
+type PublicProfileResponse struct {
+	PublicID  string         `json:"public_id"`
+	Handle    string         `json:"handle,omitempty"`
+	URL       string         `json:"url"`
+	CardURL   string         `json:"card_url"`
+	Hunter    EnrichedReport `json:"hunter"`
+	TopBadges []ProfileBadge `json:"top_badges"`
+}
+
+// profileKey is the key a profile is shared under: its handle if it has
+// one, its public ID otherwise.
+func profileKey(profile domain.PublicProfile) string {
+	if profile.Handle != "" {
+		return profile.Handle
+	}
+	return profile.PublicID
+}
+
+func (s *Server) profileURL(profile domain.PublicProfile) string {
+	return s.appBaseURL + "/p/" + url.PathEscape(profileKey(profile))
+}
+
+func (s *Server) profileCardURL(profile domain.PublicProfile) string {
+	return s.appBaseURL + "/api/profiles/" + url.PathEscape(profileKey(profile)) + "/card.png"
+}
+
+// loadPublicProfile answers the error itself and returns false when the
+// profile can't be shown.
+func (s *Server) loadPublicProfile(w http.ResponseWriter, r *http.Request) (usecase.PublicProfileView, bool) {
+	view, err := usecase.NewPublicProfileUsecase(s.repo).Get(r.Context(), r.PathValue("key"))
+	switch {
+	case errors.Is(err, usecase.ErrPublicProfileNotFound):
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "Profil tidak ditemukan"})
+		return view, false
+	case errors.Is(err, usecase.ErrPublicProfileUnavailable):
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Profil publik belum tersedia"})
+		return view, false
+	case err != nil:
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return view, false
+	}
+	return view, true
+}
+
+// HandleGetPublicProfile serves an opted-in hunter's profile by public ID
+// or handle. The phone number stays masked, as on the leaderboard.
+func (s *Server) HandleGetPublicProfile(w http.ResponseWriter, r *http.Request) {
+	view, ok := s.loadPublicProfile(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	today := domain.GetToday(now)
+	weekActivity, weekActiveDays := buildWeekActivity(view.ActivityDates, domain.GetStartOfISOWeekStrict(now))
+	hunter := enrichReport(view.Report, today, weekActivity, weekActiveDays)
+	hunter.DailyActivity, hunter.ActiveDaysInWindow, hunter.CurrentDailyStreak, hunter.LongestDailyStreak =
+		buildDailyActivity(view.ActivityDates, today, profileCalendarDays)
+
+	response := PublicProfileResponse{
+		PublicID:  view.Profile.PublicID,
+		Handle:    view.Profile.Handle,
+		URL:       s.profileURL(view.Profile),
+		CardURL:   s.profileCardURL(view.Profile),
+		Hunter:    hunter,
+		TopBadges: make([]ProfileBadge, 0, len(view.TopBadges)),
+	}
+	for _, badge := range view.TopBadges {
+		response.TopBadges = append(response.TopBadges, ProfileBadge{ID: badge.ID, Name: badge.Name, Emoji: badge.DisplayEmoji})
+	}
+	s.writeCachedJSON(w, r, response)
+}
+
+// HandlePublicProfileCard serves an opted-in hunter's card as a PNG for
+// link previews and for sharing to WhatsApp.
+func (s *Server) HandlePublicProfileCard(w http.ResponseWriter, r *http.Request) {
+	view, ok := s.loadPublicProfile(w, r)
+	if !ok {
+		return
+	}
+	card := s.hunterCard(view, time.Now())
+	// The card is a pure function of its data, so a revalidation that
+	// matches is answered without drawing it.
+	data, err := json.Marshal(struct {
+		Version int
+		Theme   string
+		Card    render.HunterCard
+	}{hunterCardVersion, render.DefaultTheme.Name, card})
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	setCORSHeaders(w)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(profileCardMaxAge.Seconds())))
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	image, err := render.RenderHunterCard(card, render.DefaultTheme)
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(image); err != nil {
+		log.Printf("Error writing hunter card: %v", err)
+	}
+}
+
+func (s *Server) hunterCard(view usecase.PublicProfileView, now time.Time) render.HunterCard {
+	report := view.Report
+	progress := domain.GetNumericLevelProgress(report.TotalPoints)
+	rank := domain.GetSeasonRank(report.SeasonalPoints)
+	jobName := "No Job"
+	if job, ok := domain.GetJobClass(report.JobClass); ok {
+		jobName = job.Name
+	}
+	current, longest := buildDailyStreaks(view.ActivityDates, domain.GetToday(now))
+
+	card := render.HunterCard{
+		Name:          report.Name,
+		Handle:        view.Profile.PublicID,
+		Level:         progress.Level,
+		LevelName:     domain.GetLevel(report.TotalPoints).Name,
+		XPCurrent:     progress.CurrentXP,
+		XPRequired:    progress.RequiredXP,
+		RankName:      rank.Name,
+		RankTier:      rank.Tier,
+		JobName:       jobName,
+		Str:           domain.ClampedAttribute(report.Str),
+		Sta:           domain.ClampedAttribute(report.Sta),
+		Agi:           domain.ClampedAttribute(report.Agi),
+		Vit:           domain.ClampedAttribute(report.Vit),
+		CurrentStreak: current,
+		LongestStreak: longest,
+		Footer:        strings.TrimPrefix(strings.TrimPrefix(s.profileURL(view.Profile), "https://"), "http://"),
+	}
+	if view.Profile.Handle != "" {
+		card.Handle = "@" + view.Profile.Handle
+	}
+	for _, badge := range view.TopBadges {
+		card.Badges = append(card.Badges, badge.Name)
+	}
+	return card
+}
+
+// HandleProfilePage serves the web app for /p/{key} with OpenGraph tags,
+// so a shared profile link previews as the hunter's card. Unknown and
+// private profiles get the plain web app.
+func (s *Server) HandleProfilePage(w http.ResponseWriter, r *http.Request) {
+	view, err := usecase.NewPublicProfileUsecase(s.repo).Get(r.Context(), r.PathValue("key"))
+	if err != nil {
+		if !errors.Is(err, usecase.ErrPublicProfileNotFound) && !errors.Is(err, usecase.ErrPublicProfileUnavailable) {
+			log.Printf("Profile page %q: %v", r.PathValue("key"), err)
+		}
+		s.HandleStatic(w, r)
+		return
+	}
+
+	report := view.Report
+	title := fmt.Sprintf("%s — Lv.%d %s", report.Name, domain.NumericLevelFromTotalPoints(report.TotalPoints), domain.GetSeasonRank(report.SeasonalPoints).Name)
+	description := fmt.Sprintf("%s · %d poin season ini · %d aktivitas. Lihat kartu hunter-nya di SWEG Healthy Club.",
+		domain.FormatJobClass(report.JobClass), report.SeasonalPoints, report.ActivityCount)
+	meta := []struct{ attr, key, content string }{
+		{"property", "og:type", "profile"},
+		{"property", "og:title", title},
+		{"property", "og:description", description},
+		{"property", "og:url", s.profileURL(view.Profile)},
+		{"property", "og:image", s.profileCardURL(view.Profile)},
+		{"property", "og:image:width", fmt.Sprint(render.HunterCardWidth)},
+		{"property", "og:image:height", fmt.Sprint(render.HunterCardHeight)},
+		{"name", "twitter:card", "summary_large_image"},
+		{"name", "twitter:title", title},
+		{"name", "twitter:description", description},
+		{"name", "twitter:image", s.profileCardURL(view.Profile)},
+	}
+	var tags strings.Builder
+	for _, m := range meta {
+		fmt.Fprintf(&tags, "    <meta %s=\"%s\" content=\"%s\" />\n", m.attr, m.key, html.EscapeString(m.content))
+	}
+
+	page := "<!doctype html>\n<html>\n  <head>\n    <meta charset=\"UTF-8\" />\n    <title></title>\n  </head>\n  <body></body>\n</html>\n"
+	if index, err := os.ReadFile(filepath.Join("./frontend/dist", "index.html")); err == nil {
+		page = string(index)
+	}
+	page = strings.Replace(page, "</head>", tags.String()+"  </head>", 1)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(page)); err != nil {
+		log.Printf("Error writing profile page: %v", err)
+	}
+}
+
+// HandleGetProfileSettings returns the logged-in user's public profile
+// settings.
+func (s *Server) HandleGetProfileSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	profile, err := usecase.NewPublicProfileUsecase(s.repo).Settings(r.Context(), userID)
+	if errors.Is(err, usecase.ErrPublicProfileUnavailable) {
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Profil publik belum tersedia"})
+		return
+	}
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, s.profileSettings(profile))
+}
+
+// HandleUpdateProfileSettings turns the public profile on or off and sets
+// its handle.
+func (s *Server) HandleUpdateProfileSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	var body usecase.PublicProfileUpdate
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Body tidak valid"})
+		return
+	}
+
+	profile, err := usecase.NewPublicProfileUsecase(s.repo).Update(r.Context(), userID, body, time.Now())
+	switch {
+	case errors.Is(err, usecase.ErrPublicProfileInvalidHandle):
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Handle harus 3-20 huruf kecil, angka atau garis bawah"})
+	case errors.Is(err, usecase.ErrPublicProfileHandleTaken):
+		s.writeJSON(w, http.StatusConflict, map[string]string{"error": "Handle sudah dipakai hunter lain"})
+	case errors.Is(err, usecase.ErrPublicProfileNotFound):
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "User tidak ditemukan"})
+	case errors.Is(err, usecase.ErrPublicProfileUnavailable):
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Profil publik belum tersedia"})
+	case err != nil:
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		s.writeJSON(w, http.StatusOK, s.profileSettings(profile))
+	}
+}
+
+// ProfileSettings is the owner's view of their public profile, with the
+// links to share.
+type ProfileSettings struct {
+	domain.PublicProfile
+	URL     string `json:"url"`
+	CardURL string `json:"card_url"`
+}
+
+func (s *Server) profileSettings(profile domain.PublicProfile) ProfileSettings {
+	return ProfileSettings{PublicProfile: profile, URL: s.profileURL(profile), CardURL: s.profileCardURL(profile)}
+}
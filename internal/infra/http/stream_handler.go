@@ -0,0 +1,152 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+const (
+	// streamHeartbeat keeps idle streams open through proxies that close
+	// quiet connections.
+	streamHeartbeat = 25 * time.Second
+	// streamRetryMillis is how long EventSource waits before reconnecting.
+	streamRetryMillis = 3000
+)
+
+// streamMessage is the data of one server-sent event. UserID is masked
+// like on the leaderboard; Personal marks the subscriber's own events,
+// which carry the full event data.
+type streamMessage struct {
+	Type       string         `json:"type"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	UserID     string         `json:"user_id,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	Personal   bool           `json:"personal,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
+// SetEventStreamUsecase enables the live event streams.
+func (s *Server) SetEventStreamUsecase(streamUC *usecase.EventStreamUsecase) {
+	s.streamUC = streamUC
+}
+
+// HandleStream streams public bot events to the web leaderboard.
+func (s *Server) HandleStream(w http.ResponseWriter, r *http.Request) {
+	s.serveStream(w, r, "")
+}
+
+// HandleUserStream streams public bot events plus the logged-in user's
+// own events, in full.
+func (s *Server) HandleUserStream(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	s.serveStream(w, r, userID)
+}
+
+// serveStream writes server-sent events until the client leaves. A client
+// that reconnects with Last-Event-ID gets the events it missed, or a
+// "resync" event when they are no longer buffered.
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request, userID string) {
+	if s.streamUC == nil {
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Stream belum tersedia"})
+		return
+	}
+	controller := http.NewResponseController(w)
+	// Streams outlive any server write timeout.
+	_ = controller.SetWriteDeadline(time.Time{})
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		// EventSource polyfills that can't set headers send it here.
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	var lastEventID uint64
+	if lastID != "" {
+		n, err := strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Last-Event-ID tidak valid"})
+			return
+		}
+		lastEventID = n
+	}
+
+	sub, backlog, resumed := s.streamUC.Subscribe(userID, lastEventID)
+	defer s.streamUC.Unsubscribe(sub)
+
+	setCORSHeaders(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis)
+	if !resumed {
+		fmt.Fprint(w, "event: resync\ndata: {}\n\n")
+	}
+	for _, event := range backlog {
+		if err := writeStreamEvent(w, event, userID); err != nil {
+			return
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		log.Printf("Event stream cannot flush: %v", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Dropped for falling behind, or shutting down; the client
+				// reconnects and resumes from the buffer.
+				return
+			}
+			if err := writeStreamEvent(w, event, userID); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, event usecase.StreamEvent, userID string) error {
+	message := streamMessage{
+		Type:       event.Event.Type,
+		OccurredAt: event.Event.OccurredAt,
+		Name:       event.Event.Name,
+		Data:       event.Public,
+	}
+	if event.Event.UserID != "" {
+		message.UserID = maskPhone(event.Event.UserID)
+	}
+	if userID != "" && event.Event.UserID == userID {
+		message.Personal = true
+		message.Data = event.Event.Data
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, message.Type, data)
+	return err
+}
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+// HandleCreateReport files today's report for the logged-in user, like
+// /lapor. The body is {"activity": "lari 5 km"}; the activity is optional.
+func (s *Server) HandleCreateReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	if s.userReportUC == nil {
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Laporan lewat web belum tersedia"})
+		return
+	}
+
+	var body struct {
+		Activity string `json:"activity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Body tidak valid"})
+		return
+	}
+
+	result, err := s.userReportUC.Report(r.Context(), userID, body.Activity, time.Now())
+	if err != nil {
+		log.Printf("Web report failed for %s: %v", userID, err)
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// HandleCreateSideQuest reports finished side quests for today, like
+// /lapor sidequest. The body is {"quests": [{"task": "pushup", "amount": 30}]}.
+func (s *Server) HandleCreateSideQuest(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	if s.userReportUC == nil {
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Laporan lewat web belum tersedia"})
+		return
+	}
+
+	var body struct {
+		Quests []usecase.UserSideQuestEntry `json:"quests"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Body tidak valid"})
+		return
+	}
+
+	result, err := s.userReportUC.SideQuest(r.Context(), userID, body.Quests, time.Now())
+	if errors.Is(err, usecase.ErrUserReportInvalid) {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Setiap side quest butuh task dan amount lebih dari 0"})
+		return
+	}
+	if err != nil {
+		log.Printf("Web side quest failed for %s: %v", userID, err)
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// HandleCancelReport cancels one of the user's reports by its event ID.
+func (s *Server) HandleCancelReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	if s.userReportUC == nil {
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Laporan lewat web belum tersedia"})
+		return
+	}
+
+	result, err := s.userReportUC.Cancel(r.Context(), userID, r.PathValue("id"))
+	switch {
+	case errors.Is(err, usecase.ErrUserReportNotFound):
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "Laporan tidak ditemukan"})
+	case errors.Is(err, usecase.ErrUserReportCancelled):
+		s.writeJSON(w, http.StatusConflict, map[string]string{"error": "Laporan sudah dibatalkan"})
+	case errors.Is(err, usecase.ErrUserReportNotToday):
+		s.writeJSON(w, http.StatusConflict, map[string]string{"error": "Hanya laporan hari ini yang bisa dibatalkan"})
+	case errors.Is(err, usecase.ErrUserReportUnavailable):
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Pembatalan laporan belum tersedia"})
+	case err != nil:
+		log.Printf("Web cancel failed for %s: %v", userID, err)
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		s.writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// HandleListReports pages through the user's report history, newest first.
+// Query parameters: before (the previous page's next_cursor), limit, kind
+// (regular_report or sidequest) and season.
+func (s *Server) HandleListReports(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	params := r.URL.Query()
+	query := usecase.ReportHistoryQuery{
+		Before: params.Get("before"),
+		Kind:   params.Get("kind"),
+	}
+	for name, dst := range map[string]*int{"limit": &query.Limit, "season": &query.Season} {
+		raw := params.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Parameter " + name + " harus berupa angka"})
+			return
+		}
+		*dst = n
+	}
+
+	page, err := usecase.NewReportHistoryUsecase(s.repo).List(r.Context(), userID, query)
+	switch {
+	case errors.Is(err, usecase.ErrReportHistoryInvalid):
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Parameter riwayat tidak valid"})
+	case errors.Is(err, usecase.ErrReportHistoryUnavailable):
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Riwayat laporan belum tersedia"})
+	case err != nil:
+		log.Printf("Report history failed for %s: %v", userID, err)
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		s.writeJSON(w, http.StatusOK, page)
+	}
+}
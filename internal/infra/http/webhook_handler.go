@@ -0,0 +1,154 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// The admin endpoints only manage subscriptions and the delivery log, so
+// they need no HTTP client; the bot's scheduler sends the deliveries.
+func (s *Server) webhookUsecase() *usecase.WebhookUsecase {
+	return usecase.NewWebhookUsecase(s.repo, nil)
+}
+
+// HandleListWebhooks lists outbound webhook subscriptions and the event
+// types they can filter on.
+func (s *Server) HandleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs, err := s.webhookUsecase().List(r.Context())
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if subs == nil {
+		subs = []domain.WebhookSubscription{}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"webhooks": subs, "event_types": domain.BotEventTypes})
+}
+
+// HandleCreateWebhook registers a subscription from {"id", "url", "events"}
+// and returns its signing secret. An empty events list receives everything.
+func (s *Server) HandleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ID     string   `json:"id"`
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON body"})
+		return
+	}
+
+	sub, secret, err := s.webhookUsecase().Create(r.Context(), body.ID, body.URL, body.Events, time.Now())
+	switch {
+	case isWebhookInputError(err):
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, usecase.ErrWebhookExists):
+		s.writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		s.writeJSON(w, http.StatusCreated, map[string]any{"webhook": sub, "secret": secret})
+	}
+}
+
+// HandleUpdateWebhook changes any of {"url", "events", "enabled"}.
+func (s *Server) HandleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	var body usecase.WebhookUpdate
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid JSON body"})
+		return
+	}
+
+	sub, err := s.webhookUsecase().Update(r.Context(), r.PathValue("id"), body, time.Now())
+	switch {
+	case isWebhookInputError(err):
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, usecase.ErrWebhookNotFound):
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case err != nil:
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		s.writeJSON(w, http.StatusOK, sub)
+	}
+}
+
+// HandleRotateWebhook issues a new signing secret, used from the next
+// delivery attempt on.
+func (s *Server) HandleRotateWebhook(w http.ResponseWriter, r *http.Request) {
+	sub, secret, err := s.webhookUsecase().Rotate(r.Context(), r.PathValue("id"), time.Now())
+	switch {
+	case errors.Is(err, usecase.ErrWebhookNotFound):
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case err != nil:
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		s.writeJSON(w, http.StatusOK, map[string]any{"webhook": sub, "secret": secret})
+	}
+}
+
+// HandleDeleteWebhook removes a subscription together with its delivery log.
+func (s *Server) HandleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	err := s.webhookUsecase().Delete(r.Context(), r.PathValue("id"))
+	switch {
+	case errors.Is(err, usecase.ErrWebhookNotFound):
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case err != nil:
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		s.writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+	}
+}
+
+// HandleListWebhookDeliveries shows the delivery log, newest first.
+// ?webhook=<id> and ?status=dead narrow it down.
+func (s *Server) HandleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", domain.WebhookDeliveryPending, domain.WebhookDeliveryDelivered, domain.WebhookDeliveryDead:
+	default:
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid status"})
+		return
+	}
+
+	deliveries, err := s.webhookUsecase().Deliveries(r.Context(), r.URL.Query().Get("webhook"), status)
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if deliveries == nil {
+		deliveries = []domain.WebhookDelivery{}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"deliveries": deliveries})
+}
+
+// HandleReplayWebhookDelivery queues a delivery for another round of retries.
+func (s *Server) HandleReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid delivery ID"})
+		return
+	}
+
+	found, err := s.webhookUsecase().Replay(r.Context(), id, time.Now())
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if !found {
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "Delivery not found"})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]string{"status": domain.WebhookDeliveryPending})
+}
+
+func isWebhookInputError(err error) bool {
+	return errors.Is(err, usecase.ErrWebhookInvalidID) ||
+		errors.Is(err, usecase.ErrWebhookInvalidURL) ||
+		errors.Is(err, usecase.ErrWebhookUnknownEvent)
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/app/usecase"
+)
+
+// HandleImportWorkoutFile reports an activity from an uploaded GPX, TCX or
+// FIT file. The multipart form carries the file in "file" and an optional
+// caption in "note". A file is only accepted once.
+func (s *Server) HandleImportWorkoutFile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		s.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	if s.reportUC == nil {
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Import file belum tersedia"})
+		return
+	}
+
+	// Leave room for the multipart headers and the note around the file.
+	r.Body = http.MaxBytesReader(w, r.Body, usecase.MaxWorkoutFileBytes+64<<10)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			reply, _ := usecase.WorkoutFileErrorReply(usecase.ErrWorkoutFileTooLarge)
+			s.writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": reply})
+			return
+		}
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Field file wajib diisi"})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Gagal membaca file"})
+		return
+	}
+
+	name := ""
+	if report, err := s.repo.GetReport(r.Context(), userID); err == nil && report != nil {
+		name = report.Name
+	}
+	result, err := usecase.NewImportWorkoutFileUsecase(s.repo, s.reportUC).Import(r.Context(), userID, name, header.Filename, data, r.FormValue("note"), time.Now())
+	if reply, ok := usecase.WorkoutFileErrorReply(err); ok {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, usecase.ErrWorkoutFileDuplicate):
+			status = http.StatusConflict
+		case errors.Is(err, usecase.ErrWorkoutFileTooLarge):
+			status = http.StatusRequestEntityTooLarge
+		case errors.Is(err, usecase.ErrWorkoutFileInvalid):
+			status = http.StatusUnprocessableEntity
+		}
+		s.writeJSON(w, status, map[string]string{"error": reply})
+		return
+	}
+	if err != nil {
+		log.Printf("Workout file import failed for %s: %v", userID, err)
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
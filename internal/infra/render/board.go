@@ -0,0 +1,130 @@
+package render
+
+import (
+	"fmt"
+	"image"
+)
+
+const (
+	// BoardWidth suits a phone screen: chat apps show it full width
+	// without cropping, however many rows it has.
+	BoardWidth = 1080
+
+	boardPadding   = 60
+	boardRowHeight = 72
+	boardRowGap    = 10
+)
+
+// BoardStat is a headline number above the rows, e.g. how many hunters
+// kept their streak.
+type BoardStat struct {
+	Label string
+	Value string
+}
+
+// BoardRow is one ranked hunter. Tier, when set, colors the row's edge
+// with the season rank; Dimmed greys out hunters who lost their streak.
+type BoardRow struct {
+	Rank   int
+	Name   string
+	Detail string
+	Value  string
+	Tier   int
+	Dimmed bool
+}
+
+// BoardCard is a ranked list such as the daily leaderboard or the weekly
+// hunter ranks. More is how many hunters did not fit in Rows.
+type BoardCard struct {
+	Title    string
+	Subtitle string
+	Stats    []BoardStat
+	Rows     []BoardRow
+	More     int
+	Empty    string
+	Footer   string
+}
+
+// RenderBoard draws card as a PNG as tall as its rows need.
+func RenderBoard(card BoardCard, theme Theme) ([]byte, error) {
+	y := boardPadding + TextHeight(6) + 20 + TextHeight(3) + 40
+	statsTop := y
+	if len(card.Stats) > 0 {
+		y += 90 + 30
+	}
+	rowsTop := y
+	rowCount := max(len(card.Rows), 1)
+	if card.More > 0 {
+		rowCount++
+	}
+	y += rowCount*(boardRowHeight+boardRowGap) + 20
+	if card.Footer != "" {
+		y += TextHeight(2) + 20
+	}
+	height := y + boardPadding - 20
+
+	c := NewCanvas(BoardWidth, height, theme.Background)
+	c.Rect(image.Rect(0, 0, BoardWidth, 10), theme.Accent)
+	inner := BoardWidth - 2*boardPadding
+	c.Text(boardPadding, boardPadding, Truncate(card.Title, 6, inner), 6, theme.Text)
+	c.Text(boardPadding, boardPadding+TextHeight(6)+20, Truncate(card.Subtitle, 3, inner), 3, theme.Muted)
+
+	if len(card.Stats) > 0 {
+		gap := 20
+		width := (inner - gap*(len(card.Stats)-1)) / len(card.Stats)
+		for i, stat := range card.Stats {
+			x := boardPadding + i*(width+gap)
+			c.RoundRect(image.Rect(x, statsTop, x+width, statsTop+90), 18, theme.Panel)
+			c.Text(x+24, statsTop+18, Truncate(stat.Value, 5, width-48), 5, theme.Accent)
+			c.Text(x+24, statsTop+18+TextHeight(5)+10, Truncate(stat.Label, 2, width-48), 2, theme.Muted)
+		}
+	}
+
+	y = rowsTop
+	if len(card.Rows) == 0 {
+		c.RoundRect(image.Rect(boardPadding, y, BoardWidth-boardPadding, y+boardRowHeight), 16, theme.Panel)
+		c.TextCenter(BoardWidth/2, y+(boardRowHeight-TextHeight(3))/2, Truncate(card.Empty, 3, inner-40), 3, theme.Muted)
+		y += boardRowHeight + boardRowGap
+	}
+	for _, row := range card.Rows {
+		drawBoardRow(c, y, row, theme)
+		y += boardRowHeight + boardRowGap
+	}
+	if card.More > 0 {
+		c.TextCenter(BoardWidth/2, y+(boardRowHeight-TextHeight(3))/2, fmt.Sprintf("+%d HUNTER LAINNYA", card.More), 3, theme.Muted)
+		y += boardRowHeight + boardRowGap
+	}
+	if card.Footer != "" {
+		c.Text(boardPadding, y+20, Truncate(card.Footer, 2, inner), 2, theme.Muted)
+	}
+	return c.PNG()
+}
+
+func drawBoardRow(c *Canvas, y int, row BoardRow, theme Theme) {
+	left, right := boardPadding, BoardWidth-boardPadding
+	c.RoundRect(image.Rect(left, y, right, y+boardRowHeight), 16, theme.Panel)
+	if row.Tier > 0 {
+		c.RoundRect(image.Rect(left, y, left+10, y+boardRowHeight), 5, theme.TierColor(row.Tier))
+	}
+
+	text, muted := theme.Text, theme.Muted
+	if row.Dimmed {
+		text = muted
+	}
+	rankColor := muted
+	if row.Rank >= 1 && row.Rank <= len(theme.Medals) {
+		rankColor = theme.Medals[row.Rank-1]
+		c.Circle(float64(left+60), float64(y+boardRowHeight/2), 24, withAlpha(rankColor, 0x40))
+	}
+	c.TextCenter(left+60, y+(boardRowHeight-TextHeight(3))/2, fmt.Sprint(row.Rank), 3, rankColor)
+
+	valueWidth := TextWidth(row.Value, 3)
+	c.TextRight(right-24, y+(boardRowHeight-TextHeight(3))/2, row.Value, 3, theme.Accent)
+	nameWidth := right - 24 - valueWidth - 24 - (left + 110)
+	if row.Detail == "" {
+		c.Text(left+110, y+(boardRowHeight-TextHeight(3))/2, Truncate(row.Name, 3, nameWidth), 3, text)
+		return
+	}
+	c.Text(left+110, y+14, Truncate(row.Name, 3, nameWidth), 3, text)
+	c.Text(left+110, y+14+TextHeight(3)+8, Truncate(row.Detail, 2, nameWidth), 2, muted)
+}
@@ -0,0 +1,68 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func decodeSize(t *testing.T, data []byte) image.Point {
+	t.Helper()
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected a valid PNG: %v", err)
+	}
+	return image.Pt(config.Width, config.Height)
+}
+
+func TestRenderBoard_GrowsWithRows(t *testing.T) {
+	empty, err := RenderBoard(BoardCard{Title: "Season 2", Empty: "Belum ada hunter aktif"}, DefaultTheme)
+	if err != nil {
+		t.Fatalf("RenderBoard: %v", err)
+	}
+	card := BoardCard{
+		Title:    "Season 2 - Day 49",
+		Subtitle: "Klasemen season sementara",
+		Stats:    []BoardStat{{Label: "keep the streak", Value: "12"}, {Label: "lose the streak", Value: "3"}},
+		More:     4,
+		Footer:   "lapor-bot.web.id",
+	}
+	for rank := 1; rank <= 10; rank++ {
+		card.Rows = append(card.Rows, BoardRow{Rank: rank, Name: "Hunter", Detail: "5 hari season", Value: "100 PTS", Tier: rank, Dimmed: rank > 8})
+	}
+	full, err := RenderBoard(card, SeasonTheme(2))
+	if err != nil {
+		t.Fatalf("RenderBoard: %v", err)
+	}
+
+	emptySize, fullSize := decodeSize(t, empty), decodeSize(t, full)
+	if emptySize.X != BoardWidth || fullSize.X != BoardWidth {
+		t.Fatalf("boards should be %dpx wide, got %v and %v", BoardWidth, emptySize, fullSize)
+	}
+	if fullSize.Y <= emptySize.Y+10*boardRowHeight {
+		t.Fatalf("ten rows and stats should make the board taller, got %v vs %v", fullSize, emptySize)
+	}
+}
+
+func TestRenderPodium_DrawsShortPodiums(t *testing.T) {
+	data, err := RenderPodium(PodiumCard{Title: "Season 1 Champions", Places: []PodiumPlace{{Name: "Alice", Value: "900 PTS"}}}, DefaultTheme)
+	if err != nil {
+		t.Fatalf("RenderPodium: %v", err)
+	}
+	if size := decodeSize(t, data); size != image.Pt(PodiumWidth, PodiumHeight) {
+		t.Fatalf("unexpected podium size %v", size)
+	}
+}
+
+func TestSeasonTheme_Rotates(t *testing.T) {
+	if SeasonTheme(1).Name != DefaultTheme.Name || SeasonTheme(0).Name != DefaultTheme.Name {
+		t.Fatal("the first season and unknown seasons should use the default theme")
+	}
+	if SeasonTheme(2).Name == SeasonTheme(1).Name {
+		t.Fatal("consecutive seasons should look different")
+	}
+	if SeasonTheme(1+len(SeasonThemes)).Name != SeasonTheme(1).Name {
+		t.Fatal("themes should repeat once every theme was used")
+	}
+}
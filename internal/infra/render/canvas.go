@@ -0,0 +1,186 @@
+// Package render draws shareable PNG cards with the standard library
+// image packages only, using a built-in bitmap font, so the bot needs no
+// font files or image services at runtime.
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"sort"
+)
+
+// Point is a position on a canvas, in pixels.
+type Point struct {
+	X, Y float64
+}
+
+// Canvas is an RGBA image with the few drawing primitives cards need.
+// Shapes are not anti-aliased; cards are drawn large enough that it
+// doesn't show once a chat app scales them down.
+type Canvas struct {
+	img *image.RGBA
+}
+
+func NewCanvas(width, height int, background color.Color) *Canvas {
+	c := &Canvas{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+	draw.Draw(c.img, c.img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	return c
+}
+
+func (c *Canvas) Width() int  { return c.img.Bounds().Dx() }
+func (c *Canvas) Height() int { return c.img.Bounds().Dy() }
+
+// Image returns the drawing so far.
+func (c *Canvas) Image() image.Image {
+	return c.img
+}
+
+// PNG encodes the canvas.
+func (c *Canvas) PNG() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+	if err := encoder.Encode(&buf, c.img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Rect fills r, blending translucent colors over what is there.
+func (c *Canvas) Rect(r image.Rectangle, col color.Color) {
+	draw.Draw(c.img, r, image.NewUniform(col), image.Point{}, draw.Over)
+}
+
+// RoundRect fills r with corners of the given radius.
+func (c *Canvas) RoundRect(r image.Rectangle, radius int, col color.Color) {
+	radius = min(radius, r.Dx()/2, r.Dy()/2)
+	src := image.NewUniform(col)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		inset := 0
+		var dy int
+		switch {
+		case y < r.Min.Y+radius:
+			dy = r.Min.Y + radius - y
+		case y >= r.Max.Y-radius:
+			dy = y - (r.Max.Y - radius - 1)
+		}
+		if dy > 0 {
+			fy := float64(dy) - 0.5
+			inset = radius - int(math.Sqrt(math.Max(0, float64(radius*radius)-fy*fy)))
+		}
+		draw.Draw(c.img, image.Rect(r.Min.X+inset, y, r.Max.X-inset, y+1), src, image.Point{}, draw.Over)
+	}
+}
+
+// Circle fills a disc centred on (cx, cy).
+func (c *Canvas) Circle(cx, cy, radius float64, col color.Color) {
+	src := image.NewUniform(col)
+	for y := int(math.Floor(cy - radius)); y <= int(math.Ceil(cy+radius)); y++ {
+		dy := float64(y) + 0.5 - cy
+		if dy*dy > radius*radius {
+			continue
+		}
+		dx := math.Sqrt(radius*radius - dy*dy)
+		x0, x1 := int(math.Round(cx-dx)), int(math.Round(cx+dx))
+		draw.Draw(c.img, image.Rect(x0, y, x1, y+1), src, image.Point{}, draw.Over)
+	}
+}
+
+// Polygon fills the polygon through points with the even-odd rule.
+func (c *Canvas) Polygon(points []Point, col color.Color) {
+	if len(points) < 3 {
+		return
+	}
+	minY, maxY := points[0].Y, points[0].Y
+	for _, p := range points[1:] {
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+	src := image.NewUniform(col)
+	var crossings []float64
+	for y := int(math.Floor(minY)); y <= int(math.Ceil(maxY)); y++ {
+		scan := float64(y) + 0.5
+		crossings = crossings[:0]
+		for i, a := range points {
+			b := points[(i+1)%len(points)]
+			if (a.Y <= scan) == (b.Y <= scan) {
+				continue
+			}
+			crossings = append(crossings, a.X+(scan-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+		}
+		sort.Float64s(crossings)
+		for i := 0; i+1 < len(crossings); i += 2 {
+			x0, x1 := int(math.Round(crossings[i])), int(math.Round(crossings[i+1]))
+			draw.Draw(c.img, image.Rect(x0, y, x1, y+1), src, image.Point{}, draw.Over)
+		}
+	}
+}
+
+// Line draws a segment of the given thickness with square ends.
+func (c *Canvas) Line(from, to Point, thickness float64, col color.Color) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	nx, ny := -dy/length*thickness/2, dx/length*thickness/2
+	c.Polygon([]Point{
+		{from.X + nx, from.Y + ny},
+		{to.X + nx, to.Y + ny},
+		{to.X - nx, to.Y - ny},
+		{from.X - nx, from.Y - ny},
+	}, col)
+}
+
+// Outline draws the closed polygon through points.
+func (c *Canvas) Outline(points []Point, thickness float64, col color.Color) {
+	for i, p := range points {
+		c.Line(p, points[(i+1)%len(points)], thickness, col)
+	}
+}
+
+// Text draws s with its top-left corner at (x, y), each font pixel
+// scale pixels square, and returns the width drawn.
+func (c *Canvas) Text(x, y int, s string, scale int, col color.Color) int {
+	src := image.NewUniform(col)
+	pen := x
+	runes := drawable(s)
+	for _, r := range runes {
+		glyph := glyphs[r]
+		for row, bits := range glyph {
+			for column := range glyphWidth {
+				if bits&(1<<(glyphWidth-1-column)) == 0 {
+					continue
+				}
+				px, py := pen+column*scale, y+row*scale
+				draw.Draw(c.img, image.Rect(px, py, px+scale, py+scale), src, image.Point{}, draw.Over)
+			}
+		}
+		pen += glyphAdvance * scale
+	}
+	if len(runes) == 0 {
+		return 0
+	}
+	return pen - x - scale
+}
+
+// TextRight draws s so that it ends at x.
+func (c *Canvas) TextRight(x, y int, s string, scale int, col color.Color) int {
+	return c.Text(x-TextWidth(s, scale), y, s, scale, col)
+}
+
+// TextCenter draws s centred on x.
+func (c *Canvas) TextCenter(x, y int, s string, scale int, col color.Color) int {
+	return c.Text(x-TextWidth(s, scale)/2, y, s, scale, col)
+}
+
+// Bar draws a progress bar filled to fraction, clamped to [0, 1].
+func (c *Canvas) Bar(r image.Rectangle, fraction float64, track, fill color.Color) {
+	c.RoundRect(r, r.Dy()/2, track)
+	fraction = math.Max(0, math.Min(1, fraction))
+	if filled := int(float64(r.Dx()) * fraction); filled > 0 {
+		c.RoundRect(image.Rect(r.Min.X, r.Min.Y, r.Min.X+max(filled, r.Dy()), r.Max.Y), r.Dy()/2, fill)
+	}
+}
@@ -0,0 +1,155 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+const (
+	// HunterCardWidth and HunterCardHeight are the OpenGraph image size,
+	// which WhatsApp, Twitter and Facebook previews all crop well.
+	HunterCardWidth  = 1200
+	HunterCardHeight = 630
+	// radarFloor keeps a new hunter's radar from collapsing to a dot.
+	radarFloor = 10
+)
+
+// HunterCard is what a hunter card shows. Handle is the line under the
+// name, e.g. "@alice" or the masked public ID.
+type HunterCard struct {
+	Name          string
+	Handle        string
+	Level         int
+	LevelName     string
+	XPCurrent     int
+	XPRequired    int
+	RankName      string
+	RankTier      int
+	JobName       string
+	Str           int
+	Sta           int
+	Agi           int
+	Vit           int
+	CurrentStreak int
+	LongestStreak int
+	Badges        []string
+	Footer        string
+}
+
+// RenderHunterCard draws card as a PNG: identity, level and season rank
+// on the left, the attribute radar on the right and badges underneath.
+func RenderHunterCard(card HunterCard, theme Theme) ([]byte, error) {
+	c := NewCanvas(HunterCardWidth, HunterCardHeight, theme.Background)
+	tier := theme.TierColor(card.RankTier)
+	c.Rect(image.Rect(0, 0, HunterCardWidth, 10), tier)
+
+	const left = 60
+	c.Text(left, 60, Truncate(card.Name, 8, 640), 8, theme.Text)
+	c.Text(left, 132, Truncate(card.Handle, 3, 640), 3, theme.Muted)
+
+	levelWidth := c.Text(left, 190, fmt.Sprintf("LV %d", card.Level), 10, theme.Accent)
+	infoX := left + levelWidth + 30
+	c.Text(infoX, 194, Truncate(card.LevelName, 3, 700-infoX), 3, theme.Text)
+	xpFraction := 0.0
+	if card.XPRequired > 0 {
+		xpFraction = float64(card.XPCurrent) / float64(card.XPRequired)
+	}
+	c.Bar(image.Rect(infoX, 228, 700, 244), xpFraction, theme.Panel, theme.Accent)
+	c.Text(infoX, 254, fmt.Sprintf("%d/%d XP", card.XPCurrent, card.XPRequired), 2, theme.Muted)
+
+	drawRankEmblem(c, Point{X: left + 40, Y: 345}, 42, card.RankTier, theme)
+	c.Text(left+100, 314, "SEASON RANK", 2, theme.Muted)
+	c.Text(left+100, 338, Truncate(card.RankName, 5, 700-left-100), 5, tier)
+
+	c.Text(left, 410, "JOB", 2, theme.Muted)
+	c.Text(left, 432, Truncate(card.JobName, 4, 290), 4, theme.Text)
+	c.Text(380, 410, "STREAK", 2, theme.Muted)
+	c.Text(380, 432, fmt.Sprintf("%d HARI", card.CurrentStreak), 4, theme.Text)
+	c.Text(380, 470, fmt.Sprintf("TERBAIK %d HARI", card.LongestStreak), 2, theme.Muted)
+
+	x := left
+	for _, badge := range card.Badges {
+		label := Truncate(badge, 2, 200)
+		width := TextWidth(label, 2) + 32
+		if x+width > 700 {
+			break
+		}
+		c.RoundRect(image.Rect(x, 510, x+width, 548), 19, theme.Panel)
+		c.Text(x+16, 522, label, 2, theme.Text)
+		x += width + 12
+	}
+
+	c.RoundRect(image.Rect(740, 40, 1160, 590), 24, theme.Panel)
+	drawRadar(c, Point{X: 950, Y: 315}, 130, card, theme)
+
+	if card.Footer != "" {
+		c.Text(left, 590, Truncate(card.Footer, 2, 640), 2, theme.Muted)
+	}
+	return c.PNG()
+}
+
+// drawRankEmblem draws a diamond in the tier's color with the tier number.
+func drawRankEmblem(c *Canvas, center Point, size float64, tier int, theme Theme) {
+	diamond := func(r float64) []Point {
+		return []Point{{center.X, center.Y - r}, {center.X + r, center.Y}, {center.X, center.Y + r}, {center.X - r, center.Y}}
+	}
+	c.Polygon(diamond(size), theme.TierColor(tier))
+	c.Polygon(diamond(size-8), theme.Background)
+	label := fmt.Sprint(max(tier, 1))
+	c.TextCenter(int(center.X), int(center.Y)-TextHeight(4)/2, label, 4, theme.TierColor(tier))
+}
+
+// drawRadar draws STR, STA, AGI and VIT on four axes, scaled to the
+// hunter's strongest attribute.
+func drawRadar(c *Canvas, center Point, radius float64, card HunterCard, theme Theme) {
+	values := []int{card.Str, card.Sta, card.Agi, card.Vit}
+	names := []string{"STR", "STA", "AGI", "VIT"}
+	peak := radarFloor
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	// Axes clockwise from the top.
+	directions := []Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+	at := func(axis int, r float64) Point {
+		return Point{center.X + directions[axis].X*r, center.Y + directions[axis].Y*r}
+	}
+
+	grid := withAlpha(theme.Muted, 0x60)
+	for ring := 1; ring <= 4; ring++ {
+		r := radius * float64(ring) / 4
+		c.Outline([]Point{at(0, r), at(1, r), at(2, r), at(3, r)}, 2, grid)
+	}
+	for axis := range directions {
+		c.Line(center, at(axis, radius), 2, grid)
+	}
+
+	shape := make([]Point, len(values))
+	for axis, v := range values {
+		r := radius * math.Max(float64(max(v, 0))/float64(peak), 0.08)
+		shape[axis] = at(axis, r)
+	}
+	c.Polygon(shape, withAlpha(theme.Accent, 0x80))
+	c.Outline(shape, 4, theme.Accent)
+	for _, p := range shape {
+		c.Circle(p.X, p.Y, 6, theme.Accent)
+	}
+
+	label := func(axis int) (string, string) { return names[axis], fmt.Sprint(values[axis]) }
+	name, value := label(0)
+	top := at(0, radius)
+	c.TextCenter(int(top.X), int(top.Y)-50, name, 3, theme.Text)
+	c.TextCenter(int(top.X), int(top.Y)-26, value, 2, theme.Muted)
+	name, value = label(2)
+	bottom := at(2, radius)
+	c.TextCenter(int(bottom.X), int(bottom.Y)+16, name, 3, theme.Text)
+	c.TextCenter(int(bottom.X), int(bottom.Y)+44, value, 2, theme.Muted)
+	name, value = label(1)
+	right := at(1, radius)
+	c.Text(int(right.X)+14, int(right.Y)-22, name, 3, theme.Text)
+	c.Text(int(right.X)+14, int(right.Y)+6, value, 2, theme.Muted)
+	name, value = label(3)
+	leftEnd := at(3, radius)
+	c.TextRight(int(leftEnd.X)-14, int(leftEnd.Y)-22, name, 3, theme.Text)
+	c.TextRight(int(leftEnd.X)-14, int(leftEnd.Y)+6, value, 2, theme.Muted)
+}
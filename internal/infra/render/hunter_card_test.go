@@ -0,0 +1,59 @@
+package render
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestRenderHunterCard_DrawsPNG(t *testing.T) {
+	card := HunterCard{
+		Name:          "Alice Wonder 🏃",
+		Handle:        "@alice_runs",
+		Level:         42,
+		LevelName:     "S-Tier Hunter",
+		XPCurrent:     1200,
+		XPRequired:    3000,
+		RankName:      "Mythical Glory",
+		RankTier:      9,
+		JobName:       "Ranger",
+		Str:           12,
+		Sta:           48,
+		Agi:           20,
+		Vit:           7,
+		CurrentStreak: 4,
+		LongestStreak: 12,
+		Badges:        []string{"On Fire", "Pemula", "Comeback Kid", "Phoenix"},
+		Footer:        "lapor.example/p/alice_runs",
+	}
+	data, err := RenderHunterCard(card, DefaultTheme)
+	if err != nil {
+		t.Fatalf("RenderHunterCard: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("the card should be a valid PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != HunterCardWidth || b.Dy() != HunterCardHeight {
+		t.Fatalf("unexpected card size %v", b)
+	}
+	// The rank stripe along the top is drawn in the tier's color.
+	if r, g, b, _ := img.At(600, 4).RGBA(); uint8(r>>8) != DefaultTheme.TierColor(9).R || uint8(g>>8) != DefaultTheme.TierColor(9).G || uint8(b>>8) != DefaultTheme.TierColor(9).B {
+		t.Fatalf("expected the tier color at the top, got %v", img.At(600, 4))
+	}
+}
+
+func TestText_MeasuresAndTruncates(t *testing.T) {
+	if got := TextWidth("ab", 2); got != 22 {
+		t.Fatalf("two glyphs at scale 2 should be 22px wide, got %d", got)
+	}
+	if got := TextWidth("🔥", 3); got != 0 {
+		t.Fatalf("runes the font lacks should be skipped, got width %d", got)
+	}
+	if got := Truncate("Mythical Immortal", 1, 40); got != "MYTH.." {
+		t.Fatalf("unexpected truncation %q", got)
+	}
+	if got := Truncate("Elite", 1, 40); got != "ELITE" {
+		t.Fatalf("text that fits should be kept, got %q", got)
+	}
+}
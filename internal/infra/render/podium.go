@@ -0,0 +1,76 @@
+package render
+
+import (
+	"fmt"
+	"image"
+)
+
+const (
+	PodiumWidth  = 1200
+	PodiumHeight = 900
+)
+
+// PodiumPlace is one of the top three hunters of a season.
+type PodiumPlace struct {
+	Name   string
+	Detail string
+	Value  string
+}
+
+// PodiumCard celebrates the top three of a finished season. Places are in
+// finishing order and may hold fewer than three.
+type PodiumCard struct {
+	Title    string
+	Subtitle string
+	Places   []PodiumPlace
+	Footer   string
+}
+
+// RenderPodium draws card as a PNG with the winner on the tallest step in
+// the middle, second on the left and third on the right.
+func RenderPodium(card PodiumCard, theme Theme) ([]byte, error) {
+	c := NewCanvas(PodiumWidth, PodiumHeight, theme.Background)
+	c.Rect(image.Rect(0, 0, PodiumWidth, 10), theme.Medals[0])
+	c.TextCenter(PodiumWidth/2, 70, Truncate(card.Title, 7, PodiumWidth-120), 7, theme.Text)
+	c.TextCenter(PodiumWidth/2, 70+TextHeight(7)+24, Truncate(card.Subtitle, 3, PodiumWidth-120), 3, theme.Muted)
+
+	const (
+		base      = 800
+		stepWidth = 320
+		gap       = 24
+	)
+	steps := []struct {
+		place  int
+		x      int
+		height int
+	}{
+		{place: 1, x: (PodiumWidth - stepWidth) / 2, height: 330},
+		{place: 2, x: (PodiumWidth-stepWidth)/2 - stepWidth - gap, height: 250},
+		{place: 3, x: (PodiumWidth+stepWidth)/2 + gap, height: 190},
+	}
+	for _, step := range steps {
+		if step.place > len(card.Places) {
+			continue
+		}
+		place := card.Places[step.place-1]
+		medal := theme.Medals[step.place-1]
+		top := base - step.height
+		center := step.x + stepWidth/2
+
+		c.RoundRect(image.Rect(step.x, top, step.x+stepWidth, base+20), 20, theme.Panel)
+		c.Rect(image.Rect(step.x, top, step.x+stepWidth, top+10), medal)
+		c.TextCenter(center, top+40, fmt.Sprint(step.place), 12, medal)
+		c.TextCenter(center, top+40+TextHeight(12)+24, Truncate(place.Value, 3, stepWidth-40), 3, theme.Accent)
+
+		c.Circle(float64(center), float64(top-130), 44, withAlpha(medal, 0x50))
+		c.Circle(float64(center), float64(top-130), 30, medal)
+		c.TextCenter(center, top-66, Truncate(place.Name, 4, stepWidth), 4, theme.Text)
+		c.TextCenter(center, top-66+TextHeight(4)+12, Truncate(place.Detail, 2, stepWidth), 2, theme.Muted)
+	}
+	c.Rect(image.Rect(0, base, PodiumWidth, PodiumHeight), theme.Background)
+
+	if card.Footer != "" {
+		c.TextCenter(PodiumWidth/2, base+40, Truncate(card.Footer, 2, PodiumWidth-120), 2, theme.Muted)
+	}
+	return c.PNG()
+}
@@ -0,0 +1,94 @@
+package render
+
+import "image/color"
+
+// Theme is the palette a card is drawn with.
+type Theme struct {
+	Name       string
+	Background color.RGBA
+	Panel      color.RGBA
+	Text       color.RGBA
+	Muted      color.RGBA
+	Accent     color.RGBA
+	// Tiers colors season rank tiers from Warrior upwards; ranks past the
+	// end reuse the last color.
+	Tiers []color.RGBA
+	// Medals colors first, second and third place.
+	Medals [3]color.RGBA
+}
+
+// DefaultTheme is the dashboard's dark hunter palette.
+var DefaultTheme = Theme{
+	Name:       "hunter",
+	Background: color.RGBA{R: 0x0b, G: 0x10, B: 0x20, A: 0xff},
+	Panel:      color.RGBA{R: 0x16, G: 0x1e, B: 0x36, A: 0xff},
+	Text:       color.RGBA{R: 0xf1, G: 0xf5, B: 0xf9, A: 0xff},
+	Muted:      color.RGBA{R: 0x8b, G: 0x95, B: 0xb0, A: 0xff},
+	Accent:     color.RGBA{R: 0x38, G: 0xbd, B: 0xf8, A: 0xff},
+	Tiers: []color.RGBA{
+		{R: 0x9c, G: 0xa3, B: 0xaf, A: 0xff}, // Warrior
+		{R: 0x84, G: 0xcc, B: 0x16, A: 0xff}, // Elite
+		{R: 0x22, G: 0xc5, B: 0x5e, A: 0xff}, // Master
+		{R: 0xf9, G: 0x73, B: 0x16, A: 0xff}, // Grandmaster
+		{R: 0x3b, G: 0x82, B: 0xf6, A: 0xff}, // Epic
+		{R: 0xea, G: 0xb3, B: 0x08, A: 0xff}, // Legend
+		{R: 0xa8, G: 0x55, B: 0xf7, A: 0xff}, // Mythic
+		{R: 0xec, G: 0x48, B: 0x99, A: 0xff}, // Mythical Honor
+		{R: 0xf4, G: 0x3f, B: 0x5e, A: 0xff}, // Mythical Glory
+		{R: 0xfd, G: 0xe0, B: 0x47, A: 0xff}, // Mythical Immortal
+	},
+	Medals: [3]color.RGBA{
+		{R: 0xfa, G: 0xcc, B: 0x15, A: 0xff},
+		{R: 0xcb, G: 0xd5, B: 0xe1, A: 0xff},
+		{R: 0xd9, G: 0x77, B: 0x06, A: 0xff},
+	},
+}
+
+// SeasonThemes rotate with the seasons, so each season's cards look
+// different in the group while the rank and medal colors stay the same.
+var SeasonThemes = []Theme{
+	DefaultTheme,
+	DefaultTheme.recolor("ember",
+		color.RGBA{R: 0x1c, G: 0x0c, B: 0x08, A: 0xff},
+		color.RGBA{R: 0x33, G: 0x18, B: 0x10, A: 0xff},
+		color.RGBA{R: 0xfb, G: 0x92, B: 0x3c, A: 0xff}),
+	DefaultTheme.recolor("verdant",
+		color.RGBA{R: 0x06, G: 0x17, B: 0x12, A: 0xff},
+		color.RGBA{R: 0x0f, G: 0x2e, B: 0x24, A: 0xff},
+		color.RGBA{R: 0x34, G: 0xd3, B: 0x99, A: 0xff}),
+	DefaultTheme.recolor("aurora",
+		color.RGBA{R: 0x14, G: 0x0b, B: 0x24, A: 0xff},
+		color.RGBA{R: 0x26, G: 0x17, B: 0x45, A: 0xff},
+		color.RGBA{R: 0xc0, G: 0x84, B: 0xfc, A: 0xff}),
+}
+
+// SeasonTheme is the theme for a season number, starting at 1.
+func SeasonTheme(season int) Theme {
+	if season < 1 {
+		return DefaultTheme
+	}
+	return SeasonThemes[(season-1)%len(SeasonThemes)]
+}
+
+func (t Theme) recolor(name string, background, panel, accent color.RGBA) Theme {
+	t.Name = name
+	t.Background = background
+	t.Panel = panel
+	t.Accent = accent
+	return t
+}
+
+// TierColor is the color of a season rank tier, starting at 1.
+func (t Theme) TierColor(tier int) color.RGBA {
+	if len(t.Tiers) == 0 {
+		return t.Accent
+	}
+	return t.Tiers[min(max(tier, 1), len(t.Tiers))-1]
+}
+
+// withAlpha returns col at the given opacity, premultiplied as
+// color.RGBA expects.
+func withAlpha(col color.RGBA, alpha uint8) color.RGBA {
+	scale := func(v uint8) uint8 { return uint8(uint16(v) * uint16(alpha) / 0xff) }
+	return color.RGBA{R: scale(col.R), G: scale(col.G), B: scale(col.B), A: alpha}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/fardannozami/whatsapp-gateway/internal/config"
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/secrets"
 	"github.com/fardannozami/whatsapp-gateway/internal/infra/sqlite"
 	_ "modernc.org/sqlite"
 )
@@ -22,10 +23,27 @@ func NewReportRepository(cfg config.Config) domain.ReportRepository {
 	}
 
 	repo := sqlite.NewReportRepository(db)
+	keyring, err := secrets.NewKeyring(cfg.SecretKeys)
+	if err != nil {
+		log.Fatalf("Invalid SECRET_KEYS: %v", err)
+	}
+	if keyring == nil {
+		if !cfg.AllowPlaintextSecrets {
+			log.Fatal("SECRET_KEYS is not set. Generate a key with: openssl rand -base64 32, or set ALLOW_PLAINTEXT_SECRETS=1 to store secrets unencrypted")
+		}
+		log.Println("WARNING: SECRET_KEYS not set, integration secrets are stored unencrypted (ALLOW_PLAINTEXT_SECRETS=1)")
+	}
+	repo.SetSecretKeyring(keyring)
+
 	// Initialize table if needed
 	if err := repo.InitTable(context.Background()); err != nil {
 		log.Printf("Failed to init table: %v", err)
 	}
+	if n, err := repo.MigrateSecrets(context.Background()); err != nil {
+		log.Printf("Failed to encrypt stored secrets: %v", err)
+	} else if n > 0 {
+		log.Printf("Encrypted or rewrapped secrets in %d rows", n)
+	}
 
 	return repo
 }
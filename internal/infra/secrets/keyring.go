@@ -0,0 +1,241 @@
+// Package secrets encrypts integration secrets, such as OAuth tokens, before
+// they are written to the database.
+//
+// Each value gets its own random data key. The value is sealed with that key
+// and the data key is sealed ("wrapped") with a key-encryption key from
+// configuration. Rotating the key-encryption key only rewraps the small data
+// keys; the sealed values themselves never change.
+//
+// Values are also bound to where they are stored (additional authenticated
+// data such as table, column and row key), so a ciphertext copied into
+// another row or column fails to decrypt.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// prefix marks an encrypted value, so rows written before encryption was
+	// enabled can still be read and migrated.
+	prefix = "enc:v2:"
+	// prefixV1 values were sealed without additional data. They still
+	// decrypt, and NeedsRewrap reports them so the migration upgrades them.
+	prefixV1 = "enc:v1:"
+	keySize  = 32
+)
+
+var (
+	ErrUnknownKey = errors.New("secret was encrypted with an unknown key")
+	ErrMalformed  = errors.New("malformed encrypted secret")
+)
+
+// Keyring holds the key-encryption keys. The active key encrypts new values;
+// the others are kept so values written before a rotation can still be read.
+type Keyring struct {
+	activeID string
+	keys     map[string]cipher.AEAD
+}
+
+// NewKeyring parses "id:base64key" specs; the first one is the active key.
+// Keys are 32 random bytes, e.g. from `openssl rand -base64 32`. It returns
+// nil without error when no keys are configured.
+func NewKeyring(specs []string) (*Keyring, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	k := &Keyring{keys: make(map[string]cipher.AEAD, len(specs))}
+	for i, spec := range specs {
+		id, encoded, ok := strings.Cut(spec, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("key %d: expected id:base64key", i+1)
+		}
+		if _, dup := k.keys[id]; dup {
+			return nil, fmt.Errorf("key %q is listed twice", id)
+		}
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		if len(raw) != keySize {
+			return nil, fmt.Errorf("key %q: must be %d bytes, got %d", id, keySize, len(raw))
+		}
+		aead, err := newAEAD(raw)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+		if i == 0 {
+			k.activeID = id
+		}
+	}
+	return k, nil
+}
+
+// ActiveKeyID is the ID of the key new values are encrypted with.
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+// IsEncrypted reports whether value was produced by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix) || strings.HasPrefix(value, prefixV1)
+}
+
+// Encrypt seals plaintext under a fresh data key wrapped with the active key,
+// bound to aad: Decrypt needs the same aad. The empty string stays empty so
+// optional columns remain distinguishable.
+func (k *Keyring) Encrypt(plaintext, aad string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(dataAEAD, []byte(plaintext), []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.activeID], dataKey, []byte(k.activeID))
+	if err != nil {
+		return "", err
+	}
+	return format(k.activeID, wrapped, sealed), nil
+}
+
+// Decrypt opens a value Encrypt sealed with the same aad. Values without
+// the encryption prefix are legacy plaintext and returned unchanged.
+func (k *Keyring) Decrypt(value, aad string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	_, dataKey, sealed, err := k.unwrap(value)
+	if err != nil {
+		return "", err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	var additionalData []byte
+	if strings.HasPrefix(value, prefix) {
+		additionalData = []byte(aad)
+	}
+	plaintext, err := open(dataAEAD, sealed, additionalData)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRewrap reports whether value is plaintext, unbound to its location
+// or wrapped with a key other than the active one.
+func (k *Keyring) NeedsRewrap(value string) bool {
+	if value == "" {
+		return false
+	}
+	if !strings.HasPrefix(value, prefix) {
+		return true
+	}
+	id, _, _, ok := parse(value)
+	return !ok || id != k.activeID
+}
+
+// Rewrap brings value under the active key: plaintext and values sealed
+// without aad are encrypted afresh, and an older value has its data key
+// rewrapped without touching the sealed data.
+func (k *Keyring) Rewrap(value, aad string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		plaintext, err := k.Decrypt(value, aad)
+		if err != nil {
+			return "", err
+		}
+		return k.Encrypt(plaintext, aad)
+	}
+	_, dataKey, sealed, err := k.unwrap(value)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.activeID], dataKey, []byte(k.activeID))
+	if err != nil {
+		return "", err
+	}
+	return format(k.activeID, wrapped, sealed), nil
+}
+
+func (k *Keyring) unwrap(value string) (id string, dataKey, sealed []byte, err error) {
+	id, wrapped, sealed, ok := parse(value)
+	if !ok {
+		return "", nil, nil, ErrMalformed
+	}
+	kek, ok := k.keys[id]
+	if !ok {
+		return "", nil, nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	dataKey, err = open(kek, wrapped, []byte(id))
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return id, dataKey, sealed, nil
+}
+
+func format(id string, wrapped, sealed []byte) string {
+	return prefix + id + ":" + base64.RawURLEncoding.EncodeToString(wrapped) + ":" + base64.RawURLEncoding.EncodeToString(sealed)
+}
+
+func parse(value string) (id string, wrapped, sealed []byte, ok bool) {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, prefix), prefixV1)
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return "", nil, nil, false
+	}
+	wrapped, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, false
+	}
+	sealed, err = base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, false
+	}
+	return parts[0], wrapped, sealed, true
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal prepends the random nonce to the ciphertext.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	return plaintext, nil
+}
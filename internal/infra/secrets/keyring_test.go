@@ -0,0 +1,160 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(t *testing.T, id string) string {
+	t.Helper()
+	raw := make([]byte, keySize)
+	if _, err := rand.Read(raw); err != nil {
+		t.Fatal(err)
+	}
+	return id + ":" + base64.StdEncoding.EncodeToString(raw)
+}
+
+func TestKeyring_RoundTrip(t *testing.T) {
+	k, err := NewKeyring([]string{testKey(t, "k1")})
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	enc, err := k.Encrypt("access-token", "tokens:1")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !IsEncrypted(enc) || strings.Contains(enc, "access-token") {
+		t.Fatalf("expected an opaque encrypted value, got %q", enc)
+	}
+	again, _ := k.Encrypt("access-token", "tokens:1")
+	if again == enc {
+		t.Fatal("encrypting the same value twice must not produce the same output")
+	}
+	got, err := k.Decrypt(enc, "tokens:1")
+	if err != nil || got != "access-token" {
+		t.Fatalf("Decrypt = %q, %v", got, err)
+	}
+	if empty, _ := k.Encrypt("", "tokens:1"); empty != "" {
+		t.Fatalf("empty value should stay empty, got %q", empty)
+	}
+}
+
+func TestKeyring_LegacyPlaintextPassesThrough(t *testing.T) {
+	k, _ := NewKeyring([]string{testKey(t, "k1")})
+	got, err := k.Decrypt("plain-token", "tokens:1")
+	if err != nil || got != "plain-token" {
+		t.Fatalf("Decrypt legacy = %q, %v", got, err)
+	}
+	if !k.NeedsRewrap("plain-token") {
+		t.Fatal("plaintext should need a rewrap")
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	oldKey, newKey := testKey(t, "old"), testKey(t, "new")
+	before, _ := NewKeyring([]string{oldKey})
+	enc, _ := before.Encrypt("refresh-token", "tokens:1")
+
+	after, _ := NewKeyring([]string{newKey, oldKey})
+	if !after.NeedsRewrap(enc) {
+		t.Fatal("value under a retired key should need a rewrap")
+	}
+	if got, err := after.Decrypt(enc, "tokens:1"); err != nil || got != "refresh-token" {
+		t.Fatalf("old value should still decrypt, got %q, %v", got, err)
+	}
+	rewrapped, err := after.Rewrap(enc, "tokens:1")
+	if err != nil {
+		t.Fatalf("Rewrap: %v", err)
+	}
+	if after.NeedsRewrap(rewrapped) {
+		t.Fatal("rewrapped value should be under the active key")
+	}
+
+	newOnly, _ := NewKeyring([]string{newKey})
+	if got, err := newOnly.Decrypt(rewrapped, "tokens:1"); err != nil || got != "refresh-token" {
+		t.Fatalf("rewrapped value should decrypt without the old key, got %q, %v", got, err)
+	}
+	if _, err := newOnly.Decrypt(enc, "tokens:1"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+}
+
+func TestKeyring_BindsValueToAAD(t *testing.T) {
+	k, _ := NewKeyring([]string{testKey(t, "k1")})
+	enc, _ := k.Encrypt("access-token", "strava_accounts.access_token:628111")
+	if _, err := k.Decrypt(enc, "strava_accounts.access_token:628222"); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("a value moved to another row must not decrypt, got %v", err)
+	}
+	if _, err := k.Decrypt(enc, "strava_accounts.refresh_token:628111"); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("a value moved to another column must not decrypt, got %v", err)
+	}
+}
+
+func TestKeyring_UpgradesValuesSealedWithoutAAD(t *testing.T) {
+	k, _ := NewKeyring([]string{testKey(t, "k1")})
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		t.Fatal(err)
+	}
+	dataAEAD, _ := newAEAD(dataKey)
+	sealed, _ := seal(dataAEAD, []byte("access-token"), nil)
+	wrapped, _ := seal(k.keys["k1"], dataKey, []byte("k1"))
+	legacy := prefixV1 + strings.TrimPrefix(format("k1", wrapped, sealed), prefix)
+
+	if got, err := k.Decrypt(legacy, "tokens:1"); err != nil || got != "access-token" {
+		t.Fatalf("v1 value should still decrypt, got %q, %v", got, err)
+	}
+	if !k.NeedsRewrap(legacy) {
+		t.Fatal("v1 value should need a rewrap")
+	}
+	upgraded, err := k.Rewrap(legacy, "tokens:1")
+	if err != nil || !strings.HasPrefix(upgraded, prefix) || k.NeedsRewrap(upgraded) {
+		t.Fatalf("Rewrap = %q, %v; want a current value", upgraded, err)
+	}
+	if _, err := k.Decrypt(upgraded, "tokens:2"); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("the upgraded value should be bound to its aad, got %v", err)
+	}
+}
+
+func TestKeyring_RejectsTampering(t *testing.T) {
+	k, _ := NewKeyring([]string{testKey(t, "k1")})
+	enc, _ := k.Encrypt("access-token", "tokens:1")
+	// Flip a character inside the sealed data; the last one may only carry
+	// padding bits.
+	i := len(enc) - 10
+	flipped := byte('A')
+	if enc[i] == 'A' {
+		flipped = 'B'
+	}
+	if _, err := k.Decrypt(enc[:i]+string(flipped)+enc[i+1:], "tokens:1"); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("expected ErrMalformed for a tampered value, got %v", err)
+	}
+	if _, err := k.Decrypt(prefix+"k1:garbage", "tokens:1"); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("expected ErrMalformed for a truncated value, got %v", err)
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	if k, err := NewKeyring(nil); k != nil || err != nil {
+		t.Fatalf("no keys should give a nil keyring, got %v, %v", k, err)
+	}
+	valid := testKey(t, "k1")
+	for _, specs := range [][]string{
+		{"missing-separator"},
+		{":" + strings.SplitN(valid, ":", 2)[1]},
+		{"k1:not base64"},
+		{"k1:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+		{valid, valid},
+	} {
+		if _, err := NewKeyring(specs); err == nil {
+			t.Errorf("expected an error for %q", specs)
+		}
+	}
+	k, err := NewKeyring([]string{valid, testKey(t, "k0")})
+	if err != nil || k.ActiveKeyID() != "k1" {
+		t.Fatalf("first key should be active, got %v, %v", k, err)
+	}
+}
@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func (r *ReportRepository) initActivityMetricsTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS report_event_metrics (
+			event_id         TEXT PRIMARY KEY,
+			user_id          TEXT NOT NULL,
+			kind             TEXT NOT NULL,
+			activity_date    TEXT NOT NULL,
+			occurred_at_utc  TEXT NOT NULL,
+			activity_type    TEXT NOT NULL DEFAULT '',
+			distance_meters  INTEGER NOT NULL DEFAULT 0,
+			duration_seconds INTEGER NOT NULL DEFAULT 0,
+			sets             INTEGER NOT NULL DEFAULT 0,
+			reps             INTEGER NOT NULL DEFAULT 0,
+			steps            INTEGER NOT NULL DEFAULT 0,
+			intensity        TEXT NOT NULL DEFAULT '',
+			FOREIGN KEY (event_id) REFERENCES report_events(event_id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_report_event_metrics_user_date ON report_event_metrics (user_id, activity_date);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+func insertReportEventMetrics(ctx context.Context, execer execContexter, event domain.ReportActivityEvent) error {
+	if event.Metrics == nil || event.Metrics.IsZero() {
+		return nil
+	}
+	m := event.Metrics
+	_, err := execer.ExecContext(ctx, `
+		INSERT OR IGNORE INTO report_event_metrics (
+			event_id, user_id, kind, activity_date, occurred_at_utc,
+			activity_type, distance_meters, duration_seconds, sets, reps, steps, intensity
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		event.EventID,
+		event.UserID,
+		event.Kind,
+		event.ActivityDate.Format(time.DateOnly),
+		event.OccurredAt.UTC().Format(time.RFC3339),
+		m.ActivityType,
+		m.DistanceMeters,
+		m.DurationSeconds,
+		m.Sets,
+		m.Reps,
+		m.Steps,
+		m.Intensity,
+	)
+	return err
+}
+
+func (r *ReportRepository) GetActivityRecords(ctx context.Context, userID string, startDate, endDate time.Time) ([]domain.ActivityRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT event_id, user_id, kind, activity_date, occurred_at_utc,
+			activity_type, distance_meters, duration_seconds, sets, reps, steps, intensity
+		FROM report_event_metrics
+		WHERE user_id = ? AND activity_date >= ? AND activity_date < ?
+		ORDER BY occurred_at_utc ASC
+	`, userID, startDate.Format(time.DateOnly), endDate.Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []domain.ActivityRecord
+	for rows.Next() {
+		var rec domain.ActivityRecord
+		var activityDate, occurredAt string
+		if err := rows.Scan(
+			&rec.EventID, &rec.UserID, &rec.Kind, &activityDate, &occurredAt,
+			&rec.ActivityType, &rec.DistanceMeters, &rec.DurationSeconds, &rec.Sets, &rec.Reps, &rec.Steps, &rec.Intensity,
+		); err != nil {
+			return nil, err
+		}
+		rec.ActivityDate, err = time.Parse(time.DateOnly, activityDate)
+		if err != nil {
+			return nil, err
+		}
+		rec.OccurredAt, err = time.Parse(time.RFC3339, occurredAt)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,54 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestReportRepository_ActivityMetricsStoredWithEvent(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2026, time.September, 2, 10, 0, 0, 0, time.UTC)
+	report := &domain.Report{UserID: "user123", Name: "Alice", LastReportDate: now}
+	metrics := domain.ParseActivityMetrics("lari 5km 30 menit")
+	event := domain.ReportActivityEvent{
+		EventID:           "event-1",
+		UserID:            "user123",
+		SeasonNumber:      2,
+		Kind:              domain.ActivityKindRegularReport,
+		ActivityDate:      domain.GetToday(now),
+		OccurredAt:        now,
+		PointsDelta:       10,
+		RegularCountDelta: 1,
+		ActivityText:      "lari 5km 30 menit",
+		Metrics:           &metrics,
+	}
+
+	if err := repo.UpsertReportWithActivityEvent(ctx, report, event); err != nil {
+		t.Fatalf("UpsertReportWithActivityEvent() error = %v", err)
+	}
+	// Re-delivering the same event must not duplicate the record.
+	if err := repo.UpsertReportWithActivityEvent(ctx, report, event); err != nil {
+		t.Fatalf("UpsertReportWithActivityEvent() retry error = %v", err)
+	}
+
+	records, err := repo.GetActivityRecords(ctx, "user123", event.ActivityDate, event.ActivityDate.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("GetActivityRecords() error = %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	got := records[0]
+	if got.EventID != "event-1" || got.ActivityType != domain.ActivityTypeRun || got.DistanceMeters != 5000 || got.DurationSeconds != 1800 {
+		t.Fatalf("unexpected record: %+v", got)
+	}
+	if !got.ActivityDate.Equal(event.ActivityDate) || !got.OccurredAt.Equal(now) {
+		t.Fatalf("unexpected record dates: %v %v", got.ActivityDate, got.OccurredAt)
+	}
+}
@@ -0,0 +1,279 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func (r *ReportRepository) initIntegrationTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS integrations (
+			id                             TEXT PRIMARY KEY,
+			name                           TEXT NOT NULL,
+			secret                         TEXT NOT NULL,
+			previous_secret                TEXT NOT NULL DEFAULT '',
+			previous_secret_expires_at_utc TEXT NOT NULL DEFAULT '',
+			enabled                        INTEGER NOT NULL DEFAULT 1,
+			created_at_utc                 TEXT NOT NULL,
+			updated_at_utc                 TEXT NOT NULL,
+			last_used_at_utc               TEXT NOT NULL DEFAULT ''
+		);
+
+		CREATE TABLE IF NOT EXISTS integration_activities (
+			integration_id TEXT NOT NULL,
+			external_id    TEXT NOT NULL,
+			user_id        TEXT NOT NULL,
+			event_key      TEXT NOT NULL DEFAULT '',
+			status         TEXT NOT NULL,
+			reply          TEXT NOT NULL DEFAULT '',
+			activity_date  TEXT NOT NULL DEFAULT '',
+			created_at_utc TEXT NOT NULL,
+			updated_at_utc TEXT NOT NULL,
+			PRIMARY KEY (integration_id, external_id)
+		);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+func (r *ReportRepository) CreateIntegration(ctx context.Context, integration domain.Integration) error {
+	secret, previous, err := r.sealIntegrationSecrets(integration)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO integrations (
+			id, name, secret, previous_secret, previous_secret_expires_at_utc,
+			enabled, created_at_utc, updated_at_utc, last_used_at_utc
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		integration.ID,
+		integration.Name,
+		secret,
+		previous,
+		formatOptionalTime(integration.PreviousSecretExpiresAt),
+		integration.Enabled,
+		integration.CreatedAt.UTC().Format(time.RFC3339),
+		integration.UpdatedAt.UTC().Format(time.RFC3339),
+		formatOptionalTime(integration.LastUsedAt),
+	)
+	return err
+}
+
+func (r *ReportRepository) UpdateIntegration(ctx context.Context, integration domain.Integration) error {
+	secret, previous, err := r.sealIntegrationSecrets(integration)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE integrations SET
+			name = ?, secret = ?, previous_secret = ?, previous_secret_expires_at_utc = ?,
+			enabled = ?, updated_at_utc = ?
+		WHERE id = ?
+	`,
+		integration.Name,
+		secret,
+		previous,
+		formatOptionalTime(integration.PreviousSecretExpiresAt),
+		integration.Enabled,
+		integration.UpdatedAt.UTC().Format(time.RFC3339),
+		integration.ID,
+	)
+	return err
+}
+
+func (r *ReportRepository) TouchIntegration(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE integrations SET last_used_at_utc = ? WHERE id = ?`,
+		usedAt.UTC().Format(time.RFC3339), id)
+	return err
+}
+
+func (r *ReportRepository) GetIntegration(ctx context.Context, id string) (*domain.Integration, error) {
+	row := r.db.QueryRowContext(ctx, integrationSelect+` WHERE id = ?`, id)
+	integration, err := r.scanIntegration(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return integration, err
+}
+
+func (r *ReportRepository) ListIntegrations(ctx context.Context) ([]domain.Integration, error) {
+	rows, err := r.db.QueryContext(ctx, integrationSelect+` ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var integrations []domain.Integration
+	for rows.Next() {
+		integration, err := r.scanIntegration(rows)
+		if err != nil {
+			return nil, err
+		}
+		integrations = append(integrations, *integration)
+	}
+	return integrations, rows.Err()
+}
+
+const integrationSelect = `
+	SELECT id, name, secret, previous_secret, previous_secret_expires_at_utc,
+		enabled, created_at_utc, updated_at_utc, last_used_at_utc
+	FROM integrations`
+
+func (r *ReportRepository) scanIntegration(row interface{ Scan(...any) error }) (*domain.Integration, error) {
+	var integration domain.Integration
+	var previousExpiresAt, createdAt, updatedAt, lastUsedAt string
+	if err := row.Scan(
+		&integration.ID,
+		&integration.Name,
+		&integration.Secret,
+		&integration.PreviousSecret,
+		&previousExpiresAt,
+		&integration.Enabled,
+		&createdAt,
+		&updatedAt,
+		&lastUsedAt,
+	); err != nil {
+		return nil, err
+	}
+	var err error
+	if integration.Secret, err = r.openSecret(integration.Secret, secretAAD("integrations", "secret", integration.ID)); err != nil {
+		return nil, err
+	}
+	if integration.PreviousSecret, err = r.openSecret(integration.PreviousSecret, secretAAD("integrations", "previous_secret", integration.ID)); err != nil {
+		return nil, err
+	}
+	integration.PreviousSecretExpiresAt, _ = time.Parse(time.RFC3339, previousExpiresAt)
+	integration.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	integration.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	integration.LastUsedAt, _ = time.Parse(time.RFC3339, lastUsedAt)
+	return &integration, nil
+}
+
+func (r *ReportRepository) sealIntegrationSecrets(integration domain.Integration) (string, string, error) {
+	secret, err := r.sealSecret(integration.Secret, secretAAD("integrations", "secret", integration.ID))
+	if err != nil {
+		return "", "", err
+	}
+	previous, err := r.sealSecret(integration.PreviousSecret, secretAAD("integrations", "previous_secret", integration.ID))
+	if err != nil {
+		return "", "", err
+	}
+	return secret, previous, nil
+}
+
+func (r *ReportRepository) ClaimIntegrationActivity(ctx context.Context, activity domain.IntegrationActivity) (bool, error) {
+	claimedAt := activity.UpdatedAt
+	if claimedAt.IsZero() {
+		claimedAt = activity.CreatedAt
+	}
+	staleBefore := claimedAt.Add(-domain.IntegrationClaimTimeout).UTC().Format(time.RFC3339)
+	// A pending row that outlived the timeout belongs to a submission that
+	// died mid-way, so it is taken over as if it were new.
+	args := append(integrationActivityArgs(activity), domain.IntegrationActivityPending, staleBefore)
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO integration_activities (
+			integration_id, external_id, user_id, event_key, status, reply,
+			activity_date, created_at_utc, updated_at_utc
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(integration_id, external_id) DO UPDATE SET
+			user_id = excluded.user_id,
+			event_key = excluded.event_key,
+			status = excluded.status,
+			reply = excluded.reply,
+			activity_date = excluded.activity_date,
+			created_at_utc = excluded.created_at_utc,
+			updated_at_utc = excluded.updated_at_utc
+		WHERE integration_activities.status = ? AND integration_activities.updated_at_utc < ?
+	`, args...)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *ReportRepository) SaveIntegrationActivity(ctx context.Context, activity domain.IntegrationActivity) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO integration_activities (
+			integration_id, external_id, user_id, event_key, status, reply,
+			activity_date, created_at_utc, updated_at_utc
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(integration_id, external_id) DO UPDATE SET
+			event_key = excluded.event_key,
+			status = excluded.status,
+			reply = excluded.reply,
+			activity_date = excluded.activity_date,
+			updated_at_utc = excluded.updated_at_utc
+	`, integrationActivityArgs(activity)...)
+	return err
+}
+
+func integrationActivityArgs(activity domain.IntegrationActivity) []any {
+	activityDate := ""
+	if !activity.ActivityDate.IsZero() {
+		activityDate = activity.ActivityDate.Format(time.DateOnly)
+	}
+	return []any{
+		activity.IntegrationID,
+		activity.ExternalID,
+		activity.UserID,
+		activity.EventKey,
+		activity.Status,
+		activity.Reply,
+		activityDate,
+		activity.CreatedAt.UTC().Format(time.RFC3339),
+		activity.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (r *ReportRepository) GetIntegrationActivity(ctx context.Context, integrationID, externalID string) (*domain.IntegrationActivity, error) {
+	var activity domain.IntegrationActivity
+	var activityDate, createdAt, updatedAt string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT integration_id, external_id, user_id, event_key, status, reply,
+			activity_date, created_at_utc, updated_at_utc
+		FROM integration_activities
+		WHERE integration_id = ? AND external_id = ?
+	`, integrationID, externalID).Scan(
+		&activity.IntegrationID,
+		&activity.ExternalID,
+		&activity.UserID,
+		&activity.EventKey,
+		&activity.Status,
+		&activity.Reply,
+		&activityDate,
+		&createdAt,
+		&updatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	activity.ActivityDate, _ = time.Parse(time.DateOnly, activityDate)
+	activity.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	activity.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return &activity, nil
+}
+
+func (r *ReportRepository) ReleaseIntegrationActivity(ctx context.Context, integrationID, externalID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM integration_activities WHERE integration_id = ? AND external_id = ?`,
+		integrationID, externalID)
+	return err
+}
+
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,115 @@
+package sqlite_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestIntegration_RoundTripWithEncryptedSecrets(t *testing.T) {
+	db, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	// Created before encryption was configured.
+	integration := domain.Integration{ID: "gym-kiosk", Name: "Gym Kiosk", Secret: "secret-1", Enabled: true, CreatedAt: now, UpdatedAt: now}
+	if err := repo.CreateIntegration(ctx, integration); err != nil {
+		t.Fatalf("CreateIntegration: %v", err)
+	}
+
+	keyring, _ := newTestKeyring(t, "k1")
+	repo.SetSecretKeyring(keyring)
+	if n, err := repo.MigrateSecrets(ctx); err != nil || n != 1 {
+		t.Fatalf("expected the integration secret to be migrated, got %d, %v", n, err)
+	}
+
+	integration.PreviousSecret = "secret-1"
+	integration.Secret = "secret-2"
+	integration.PreviousSecretExpiresAt = now.Add(24 * time.Hour)
+	integration.Enabled = false
+	if err := repo.UpdateIntegration(ctx, integration); err != nil {
+		t.Fatalf("UpdateIntegration: %v", err)
+	}
+	if err := repo.TouchIntegration(ctx, "gym-kiosk", now.Add(time.Minute)); err != nil {
+		t.Fatalf("TouchIntegration: %v", err)
+	}
+
+	var stored string
+	if err := db.QueryRow(`SELECT secret FROM integrations WHERE id = ?`, "gym-kiosk").Scan(&stored); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if !strings.HasPrefix(stored, "enc:v2:k1:") {
+		t.Fatalf("secret should be stored encrypted, got %q", stored)
+	}
+
+	got, err := repo.GetIntegration(ctx, "gym-kiosk")
+	if err != nil || got == nil {
+		t.Fatalf("GetIntegration = %v, %v", got, err)
+	}
+	if got.Secret != "secret-2" || got.PreviousSecret != "secret-1" || got.Enabled || !got.PreviousSecretExpiresAt.Equal(now.Add(24*time.Hour)) || !got.LastUsedAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected integration: %+v", got)
+	}
+	if missing, err := repo.GetIntegration(ctx, "nope"); err != nil || missing != nil {
+		t.Fatalf("unknown integration should be nil, got %v, %v", missing, err)
+	}
+	list, err := repo.ListIntegrations(ctx)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListIntegrations = %v, %v", list, err)
+	}
+}
+
+func TestIntegrationActivity_ClaimSaveRelease(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	activity := domain.IntegrationActivity{
+		IntegrationID: "gym-kiosk",
+		ExternalID:    "checkin-1",
+		UserID:        "628111",
+		Status:        domain.IntegrationActivityPending,
+		ActivityDate:  time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if claimed, err := repo.ClaimIntegrationActivity(ctx, activity); err != nil || !claimed {
+		t.Fatalf("first claim = %v, %v", claimed, err)
+	}
+	if claimed, err := repo.ClaimIntegrationActivity(ctx, activity); err != nil || claimed {
+		t.Fatalf("second claim = %v, %v", claimed, err)
+	}
+
+	// The claim of a submission that died mid-way is taken over once stale.
+	retry := activity
+	retry.CreatedAt = now.Add(domain.IntegrationClaimTimeout + time.Minute)
+	retry.UpdatedAt = retry.CreatedAt
+	if claimed, err := repo.ClaimIntegrationActivity(ctx, retry); err != nil || !claimed {
+		t.Fatalf("stale claim = %v, %v", claimed, err)
+	}
+	if claimed, err := repo.ClaimIntegrationActivity(ctx, retry); err != nil || claimed {
+		t.Fatalf("claim of the fresh retry = %v, %v", claimed, err)
+	}
+
+	activity.Status = domain.IntegrationActivityReported
+	activity.EventKey = "event-1"
+	activity.Reply = "ok"
+	if err := repo.SaveIntegrationActivity(ctx, activity); err != nil {
+		t.Fatalf("SaveIntegrationActivity: %v", err)
+	}
+	got, err := repo.GetIntegrationActivity(ctx, "gym-kiosk", "checkin-1")
+	if err != nil || got == nil || got.Status != domain.IntegrationActivityReported || got.EventKey != "event-1" || !got.ActivityDate.Equal(activity.ActivityDate) {
+		t.Fatalf("GetIntegrationActivity = %+v, %v", got, err)
+	}
+
+	if err := repo.ReleaseIntegrationActivity(ctx, "gym-kiosk", "checkin-1"); err != nil {
+		t.Fatalf("ReleaseIntegrationActivity: %v", err)
+	}
+	if got, err := repo.GetIntegrationActivity(ctx, "gym-kiosk", "checkin-1"); err != nil || got != nil {
+		t.Fatalf("released activity should be gone, got %+v, %v", got, err)
+	}
+}
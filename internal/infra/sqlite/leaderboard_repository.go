@@ -0,0 +1,248 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+// leaderboardReportColumns is user_reports with NULLs zeroed, so the tab
+// filters and orderings can compare plainly. %s is the seasonal columns.
+const leaderboardReportColumns = `ur.user_id, ur.name, COALESCE(ur.job_class, '') AS job_class,
+	COALESCE(ur.streak, 0) AS streak, COALESCE(ur.activity_count, 0) AS activity_count, ur.last_report_date,
+	COALESCE(ur.max_streak, 0) AS max_streak, COALESCE(ur.total_points, 0) AS total_points,
+	ur.level, ur.achievements, ur.comeback_streak, ur.inactive_days,
+	COALESCE(ur.centurion_cycles, 0) AS centurion_cycles,
+	%s,
+	ur.streak_freezes, ur.goals_completed, ur.total_side_quests,
+	COALESCE(ur.str, 0) AS str, COALESCE(ur.sta, 0) AS sta, COALESCE(ur.agi, 0) AS agi, COALESCE(ur.vit, 0) AS vit`
+
+const currentSeasonColumns = `COALESCE(ur.seasonal_points, 0) AS seasonal_points,
+	COALESCE(ur.seasonal_activity_count, 0) AS seasonal_activity_count,
+	COALESCE(ur.seasonal_max_streak, 0) AS seasonal_max_streak,
+	ur.seasonal_achievements, ur.seasonal_side_quests`
+
+// projectedSeasonColumns reads the running season from its
+// user_season_stats row, which cancellations keep exact. The best streak and
+// badges aren't projected and still come from the report.
+const projectedSeasonColumns = `COALESCE(uss.total_points, 0) AS seasonal_points,
+	COALESCE(uss.active_days, 0) AS seasonal_activity_count,
+	COALESCE(ur.seasonal_max_streak, 0) AS seasonal_max_streak,
+	ur.seasonal_achievements,
+	COALESCE(uss.sidequest_reports, 0) AS seasonal_side_quests`
+
+// archivedSeasonColumns reads a past season from its user_season_stats
+// row. The season's best streak and badges weren't archived.
+const archivedSeasonColumns = `COALESCE(uss.total_points, 0) AS seasonal_points,
+	COALESCE(uss.active_days, 0) AS seasonal_activity_count,
+	0 AS seasonal_max_streak, '' AS seasonal_achievements,
+	COALESCE(uss.sidequest_reports, 0) AS seasonal_side_quests`
+
+// leaderboardFilters mirror the web tabs: who has anything to show there.
+var leaderboardFilters = map[domain.LeaderboardSortKey]string{
+	domain.SortBySeasonRank:       "(seasonal_points > 0 OR seasonal_activity_count > 0)",
+	domain.SortByLifetimeXP:       "(total_points > 0 OR activity_count > 0 OR centurion_cycles > 0)",
+	domain.SortByWeeklyStreak:     leaderboardStreakFilter,
+	domain.SortByDailyStreak:      leaderboardStreakFilter,
+	domain.SortByWeeklyActivity:   "week_active_days > 0",
+	domain.SortByAttributeOverall: leaderboardAttributeFilter,
+	domain.SortByAttributeSTR:     leaderboardAttributeFilter,
+	domain.SortByAttributeSTA:     leaderboardAttributeFilter,
+	domain.SortByAttributeAGI:     leaderboardAttributeFilter,
+	domain.SortByAttributeVIT:     leaderboardAttributeFilter,
+}
+
+const (
+	leaderboardStreakFilter = `(streak > 0 OR max_streak > 0 OR seasonal_max_streak > 0
+		OR current_daily_streak > 0 OR longest_daily_streak > 0 OR activity_count > 0)`
+	leaderboardAttributeFilter = "(str > 0 OR sta > 0 OR agi > 0 OR vit > 0)"
+)
+
+// leaderboardOrders mirror the web tab comparators; every one ends with
+// name then user_id so pages never overlap.
+var leaderboardOrders = map[domain.LeaderboardSortKey]string{
+	domain.SortBySeasonRank:       "seasonal_points DESC, seasonal_activity_count DESC, streak DESC, total_active_days DESC",
+	domain.SortByLifetimeXP:       "total_points DESC, total_active_days DESC, max_streak DESC",
+	domain.SortByWeeklyStreak:     "streak DESC, current_daily_streak DESC, seasonal_points DESC, max_streak DESC",
+	domain.SortByDailyStreak:      "current_daily_streak DESC, longest_daily_streak DESC, total_points DESC",
+	domain.SortByWeeklyActivity:   "week_active_days DESC, streak DESC, seasonal_points DESC",
+	domain.SortByAttributeOverall: "(MAX(str, 1) + MAX(sta, 1) + MAX(agi, 1) + MAX(vit, 1)) / 4 DESC, total_points DESC",
+	domain.SortByAttributeSTR:     "MAX(str, 1) DESC, total_points DESC",
+	domain.SortByAttributeSTA:     "MAX(sta, 1) DESC, total_points DESC",
+	domain.SortByAttributeAGI:     "MAX(agi, 1) DESC, total_points DESC",
+	domain.SortByAttributeVIT:     "MAX(vit, 1) DESC, total_points DESC",
+}
+
+// weekActivityFromLogs and weekActivityFromProjection count the days active
+// in the week. The projection keeps a row per season, so a week straddling
+// a season reset reads the previous season's rows too; bonus-only days have
+// no reports and don't count.
+const (
+	weekActivityFromLogs = `
+			SELECT user_id, COUNT(*) AS days, GROUP_CONCAT(activity_date) AS dates
+			FROM activity_logs
+			WHERE activity_date >= ? AND activity_date < ?
+			GROUP BY user_id`
+	weekActivityFromProjection = `
+			SELECT user_id, COUNT(DISTINCT activity_date) AS days, GROUP_CONCAT(DISTINCT activity_date) AS dates
+			FROM user_daily_activity
+			WHERE season_number IN (?, ?) AND activity_date >= ? AND activity_date < ?
+			  AND regular_count + sidequest_count > 0
+			GROUP BY user_id`
+)
+
+// ListLeaderboard ranks every user in one query. Seasonal numbers come from
+// the user_season_stats projection unless the query's season predates the
+// ledger. Daily streaks are lifetime runs of consecutive activity_logs days,
+// which reach back before the ledger, found by grouping each day on its
+// date minus its row number.
+func (r *ReportRepository) ListLeaderboard(ctx context.Context, query domain.LeaderboardQuery) ([]domain.LeaderboardStanding, int, error) {
+	order, ok := leaderboardOrders[query.SortKey]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown leaderboard sort key %q", query.SortKey)
+	}
+
+	var args []any
+	seasonColumns, seasonJoin := currentSeasonColumns, ""
+	switch {
+	case query.Season > 0:
+		seasonColumns = archivedSeasonColumns
+		seasonJoin = "LEFT JOIN user_season_stats uss ON uss.user_id = ur.user_id AND uss.season_number = ?"
+		args = append(args, query.Season)
+	case query.CurrentSeason > 0:
+		seasonColumns = projectedSeasonColumns
+		seasonJoin = "LEFT JOIN user_season_stats uss ON uss.user_id = ur.user_id AND uss.season_number = ?"
+		args = append(args, query.CurrentSeason)
+	}
+	args = append(args, query.Today.Format(time.DateOnly))
+	weekActivity := weekActivityFromLogs
+	if query.Season == 0 && query.CurrentSeason > 0 {
+		weekActivity = weekActivityFromProjection
+		args = append(args, query.CurrentSeason-1, query.CurrentSeason)
+	}
+	args = append(args,
+		query.WeekStart.Format(time.DateOnly),
+		query.WeekStart.AddDate(0, 0, 7).Format(time.DateOnly),
+	)
+
+	var where []string
+	if query.ActiveOnly {
+		where = append(where, leaderboardFilters[query.SortKey])
+	}
+	if query.JobClass != "" {
+		where = append(where, "LOWER(job_class) = LOWER(?)")
+		args = append(args, query.JobClass)
+	}
+	if query.Search != "" {
+		where = append(where, "INSTR(LOWER(name), LOWER(?)) > 0")
+		args = append(args, query.Search)
+	}
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = "WHERE " + strings.Join(where, " AND ")
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	args = append(args, limit, max(query.Offset, 0))
+
+	rows, err := r.db.QueryContext(ctx, `
+		WITH season_reports AS (
+			SELECT `+fmt.Sprintf(leaderboardReportColumns, seasonColumns)+`
+			FROM user_reports ur
+			`+seasonJoin+`
+		),
+		active_days AS (
+			SELECT user_id, activity_date,
+			       JULIANDAY(activity_date) - ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY activity_date) AS run
+			FROM activity_logs
+		),
+		runs AS (
+			SELECT user_id, COUNT(*) AS length, MAX(activity_date) AS last_date
+			FROM active_days
+			GROUP BY user_id, run
+		),
+		daily_streaks AS (
+			SELECT user_id,
+			       MAX(CASE WHEN last_date = ? THEN length ELSE 0 END) AS current_streak,
+			       MAX(length) AS longest_streak
+			FROM runs
+			GROUP BY user_id
+		),
+		week_activity AS (`+weekActivity+`
+		),
+		board AS (
+			SELECT sr.*,
+			       sr.centurion_cycles * 100 + sr.activity_count AS total_active_days,
+			       COALESCE(ds.current_streak, 0) AS current_daily_streak,
+			       COALESCE(ds.longest_streak, 0) AS longest_daily_streak,
+			       COALESCE(wa.days, 0) AS week_active_days,
+			       COALESCE(wa.dates, '') AS week_dates
+			FROM season_reports sr
+			LEFT JOIN daily_streaks ds ON ds.user_id = sr.user_id
+			LEFT JOIN week_activity wa ON wa.user_id = sr.user_id
+		)
+		SELECT `+selectColumns+`,
+		       current_daily_streak, longest_daily_streak, week_dates, COUNT(*) OVER ()
+		FROM board
+		`+whereClause+`
+		ORDER BY `+order+`, name ASC, user_id ASC
+		LIMIT ? OFFSET ?
+	`, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var standings []domain.LeaderboardStanding
+	total := 0
+	for rows.Next() {
+		var standing domain.LeaderboardStanding
+		var weekDates string
+		report, err := scanReport(leaderboardScanner{rows: rows, extra: []any{
+			&standing.CurrentDailyStreak, &standing.LongestDailyStreak, &weekDates, &total,
+		}})
+		if err != nil {
+			return nil, 0, err
+		}
+		standing.Report = report
+		if standing.WeekDates, err = parseActivityDates(weekDates); err != nil {
+			return nil, 0, err
+		}
+		standings = append(standings, standing)
+	}
+	return standings, total, rows.Err()
+}
+
+// leaderboardScanner lets scanReport read the report columns of a row
+// that carries more columns after them.
+type leaderboardScanner struct {
+	rows  *sql.Rows
+	extra []any
+}
+
+func (s leaderboardScanner) Scan(dest ...any) error {
+	return s.rows.Scan(append(dest, s.extra...)...)
+}
+
+func parseActivityDates(joined string) ([]time.Time, error) {
+	if joined == "" {
+		return nil, nil
+	}
+	var dates []time.Time
+	for _, value := range strings.Split(joined, ",") {
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates, nil
+}
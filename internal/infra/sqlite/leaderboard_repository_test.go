@@ -0,0 +1,153 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestLeaderboard_RanksFiltersAndPages(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	today := time.Date(2026, time.October, 21, 0, 0, 0, 0, time.UTC) // a Wednesday
+	weekStart := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	reports := []*domain.Report{
+		{UserID: "628111", Name: "Alice", JobClass: "warrior", Streak: 2, SeasonalPoints: 50, SeasonalActivityCount: 4, TotalPoints: 300, Str: 4, LastReportDate: today},
+		{UserID: "628222", Name: "Bob", JobClass: "mage", Streak: 3, SeasonalPoints: 80, SeasonalActivityCount: 2, TotalPoints: 100, Vit: 6, LastReportDate: today},
+		{UserID: "628333", Name: "Carol", LastReportDate: today.AddDate(0, -6, 0)},
+	}
+	for _, report := range reports {
+		if err := repo.UpsertReport(ctx, report); err != nil {
+			t.Fatalf("UpsertReport: %v", err)
+		}
+	}
+	// Alice: a 4-day run ending today after a 5-day run in September.
+	for _, day := range []int{0, -1, -2, -3, -30, -31, -32, -33, -34} {
+		if err := repo.LogActivity(ctx, "628111", today.AddDate(0, 0, day)); err != nil {
+			t.Fatalf("LogActivity: %v", err)
+		}
+	}
+	if err := repo.LogActivity(ctx, "628222", today.AddDate(0, 0, -5)); err != nil {
+		t.Fatalf("LogActivity: %v", err)
+	}
+	// Carol only played in season 1.
+	archived := domain.ReportActivityEvent{
+		EventID:           "s1-carol",
+		UserID:            "628333",
+		SeasonNumber:      1,
+		Kind:              domain.ActivityKindRegularReport,
+		ActivityDate:      today.AddDate(0, -6, 0),
+		OccurredAt:        today.AddDate(0, -6, 0),
+		PointsDelta:       120,
+		RegularCountDelta: 1,
+	}
+	if err := repo.UpsertReportWithActivityEvent(ctx, reports[2], archived); err != nil {
+		t.Fatalf("UpsertReportWithActivityEvent: %v", err)
+	}
+
+	query := domain.LeaderboardQuery{SortKey: domain.SortBySeasonRank, ActiveOnly: true, Today: today, WeekStart: weekStart}
+	standings, total, err := repo.ListLeaderboard(ctx, query)
+	if err != nil {
+		t.Fatalf("ListLeaderboard: %v", err)
+	}
+	if total != 2 || len(standings) != 2 || standings[0].Report.Name != "Bob" || standings[1].Report.Name != "Alice" {
+		t.Fatalf("season ranking should be Bob, Alice without Carol, got %d %+v", total, standings)
+	}
+	alice := standings[1]
+	if alice.CurrentDailyStreak != 4 || alice.LongestDailyStreak != 5 || len(alice.WeekDates) != 3 {
+		t.Fatalf("unexpected streaks for Alice: current %d longest %d week %v", alice.CurrentDailyStreak, alice.LongestDailyStreak, alice.WeekDates)
+	}
+
+	query.SortKey = domain.SortByDailyStreak
+	query.Limit = 1
+	standings, total, _ = repo.ListLeaderboard(ctx, query)
+	if total != 3 || len(standings) != 1 || standings[0].Report.Name != "Alice" {
+		t.Fatalf("daily streak page 1 should be Alice of 3, got %d %+v", total, standings)
+	}
+	query.Offset = 1
+	standings, _, _ = repo.ListLeaderboard(ctx, query)
+	if len(standings) != 1 || standings[0].Report.Name != "Bob" || standings[0].LongestDailyStreak != 1 {
+		t.Fatalf("daily streak page 2 should be Bob, ahead of Carol on points, got %+v", standings)
+	}
+
+	query = domain.LeaderboardQuery{SortKey: domain.SortByAttributeOverall, ActiveOnly: true, JobClass: "MAGE", Today: today, WeekStart: weekStart}
+	if standings, _, _ = repo.ListLeaderboard(ctx, query); len(standings) != 1 || standings[0].Report.Name != "Bob" {
+		t.Fatalf("job filter should keep Bob only, got %+v", standings)
+	}
+	query = domain.LeaderboardQuery{SortKey: domain.SortByLifetimeXP, Search: "ali", Today: today, WeekStart: weekStart}
+	if standings, _, _ = repo.ListLeaderboard(ctx, query); len(standings) != 1 || standings[0].Report.Name != "Alice" {
+		t.Fatalf("search should keep Alice only, got %+v", standings)
+	}
+
+	query = domain.LeaderboardQuery{SortKey: domain.SortBySeasonRank, Season: 1, ActiveOnly: true, Today: today, WeekStart: weekStart}
+	standings, _, err = repo.ListLeaderboard(ctx, query)
+	if err != nil {
+		t.Fatalf("ListLeaderboard season 1: %v", err)
+	}
+	if len(standings) != 1 || standings[0].Report.Name != "Carol" || standings[0].Report.SeasonalPoints != 120 || standings[0].Report.SeasonalActivityCount != 1 {
+		t.Fatalf("season 1 should come from its archived stats, got %+v", standings)
+	}
+}
+
+func TestLeaderboard_CurrentSeasonFromProjections(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	today := time.Date(2026, time.October, 21, 0, 0, 0, 0, time.UTC) // a Wednesday
+	weekStart := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	// The report's seasonal numbers are stale; the projections are not.
+	report := &domain.Report{UserID: "628111", Name: "Alice", SeasonalPoints: 999, SeasonalActivityCount: 9, LastReportDate: today}
+	if err := repo.UpsertReport(ctx, report); err != nil {
+		t.Fatalf("UpsertReport: %v", err)
+	}
+	for i, event := range []struct {
+		id     string
+		day    time.Time
+		points int
+	}{
+		{"evt-1", today.AddDate(0, 0, -1), 30},
+		{"evt-2", today.AddDate(0, 0, -1), 5},
+		{"evt-3", today, 30},
+	} {
+		err := repo.UpsertReportWithActivityEvent(ctx, report, domain.ReportActivityEvent{
+			EventID:           event.id,
+			UserID:            "628111",
+			SeasonNumber:      2,
+			Kind:              domain.ActivityKindRegularReport,
+			ActivityDate:      event.day,
+			OccurredAt:        event.day.Add(time.Duration(i) * time.Minute),
+			PointsDelta:       event.points,
+			RegularCountDelta: 1,
+		})
+		if err != nil {
+			t.Fatalf("UpsertReportWithActivityEvent: %v", err)
+		}
+	}
+	if ok, err := repo.CancelReportEvent(ctx, "628111", "evt-3", today); err != nil || !ok {
+		t.Fatalf("CancelReportEvent = %v, %v", ok, err)
+	}
+	if ids, err := repo.CancelLatestReportEvents(ctx, "628111", domain.ActivityKindRegularReport, today.AddDate(0, 0, -1), 1, today); err != nil || len(ids) != 1 || ids[0] != "evt-2" {
+		t.Fatalf("CancelLatestReportEvents = %v, %v", ids, err)
+	}
+
+	query := domain.LeaderboardQuery{SortKey: domain.SortBySeasonRank, CurrentSeason: 2, ActiveOnly: true, Today: today, WeekStart: weekStart}
+	standings, _, err := repo.ListLeaderboard(ctx, query)
+	if err != nil {
+		t.Fatalf("ListLeaderboard: %v", err)
+	}
+	if len(standings) != 1 {
+		t.Fatalf("expected Alice only, got %+v", standings)
+	}
+	alice := standings[0]
+	if alice.Report.SeasonalPoints != 30 || alice.Report.SeasonalActivityCount != 1 {
+		t.Fatalf("cancelled events should leave the projections, got %d pts over %d days", alice.Report.SeasonalPoints, alice.Report.SeasonalActivityCount)
+	}
+	if len(alice.WeekDates) != 1 || !alice.WeekDates[0].Equal(today.AddDate(0, 0, -1)) {
+		t.Fatalf("only the day with a report left should count this week, got %v", alice.WeekDates)
+	}
+}
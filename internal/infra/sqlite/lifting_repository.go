@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func (r *ReportRepository) initLiftingTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS lifting_logs (
+			event_key       TEXT NOT NULL,
+			user_id         TEXT NOT NULL,
+			exercise_key    TEXT NOT NULL,
+			exercise        TEXT NOT NULL,
+			activity_date   TEXT NOT NULL,
+			occurred_at_utc TEXT NOT NULL,
+			sets            INTEGER NOT NULL DEFAULT 0,
+			reps            INTEGER NOT NULL DEFAULT 0,
+			volume_kg       REAL NOT NULL DEFAULT 0,
+			top_weight_kg   REAL NOT NULL DEFAULT 0,
+			e1rm_kg         REAL NOT NULL DEFAULT 0,
+			PRIMARY KEY (event_key, exercise_key)
+		);
+		CREATE INDEX IF NOT EXISTS idx_lifting_logs_user_exercise ON lifting_logs (user_id, exercise_key, occurred_at_utc);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+func (r *ReportRepository) RecordLiftingLogs(ctx context.Context, logs []domain.LiftingLog) (bool, error) {
+	if len(logs) == 0 {
+		return false, nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+
+	inserted := false
+	for _, l := range logs {
+		res, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO lifting_logs (
+				event_key, user_id, exercise_key, exercise, activity_date, occurred_at_utc,
+				sets, reps, volume_kg, top_weight_kg, e1rm_kg
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+			l.EventKey,
+			l.UserID,
+			l.ExerciseKey,
+			l.Exercise,
+			l.ActivityDate.Format(time.DateOnly),
+			l.OccurredAt.UTC().Format(time.RFC3339),
+			l.Sets,
+			l.Reps,
+			l.VolumeKg,
+			l.TopWeightKg,
+			l.EstimatedOneRepMaxKg,
+		)
+		if err != nil {
+			_ = tx.Rollback()
+			return false, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			_ = tx.Rollback()
+			return false, err
+		}
+		inserted = inserted || affected > 0
+	}
+	return inserted, tx.Commit()
+}
+
+func (r *ReportRepository) GetBestEstimatedOneRepMax(ctx context.Context, userID string, exerciseKeys []string) (map[string]float64, error) {
+	best := make(map[string]float64)
+	if len(exerciseKeys) == 0 {
+		return best, nil
+	}
+
+	args := []any{userID}
+	for _, key := range exerciseKeys {
+		args = append(args, key)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(exerciseKeys)), ",")
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT exercise_key, MAX(e1rm_kg)
+		FROM lifting_logs
+		WHERE user_id = ? AND exercise_key IN (`+placeholders+`)
+		GROUP BY exercise_key
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		var e1rm float64
+		if err := rows.Scan(&key, &e1rm); err != nil {
+			return nil, err
+		}
+		best[key] = e1rm
+	}
+	return best, rows.Err()
+}
+
+// GetLiftingHistory keeps the latest limit sessions but returns them oldest
+// first, which is the order progress charts plot them in.
+func (r *ReportRepository) GetLiftingHistory(ctx context.Context, userID, exerciseKey string, limit int) ([]domain.LiftingLog, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT event_key, user_id, exercise_key, exercise, activity_date, occurred_at_utc,
+			sets, reps, volume_kg, top_weight_kg, e1rm_kg
+		FROM (
+			SELECT * FROM lifting_logs
+			WHERE user_id = ? AND exercise_key = ?
+			ORDER BY occurred_at_utc DESC
+			LIMIT ?
+		)
+		ORDER BY occurred_at_utc ASC
+	`, userID, exerciseKey, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []domain.LiftingLog
+	for rows.Next() {
+		var l domain.LiftingLog
+		var activityDate, occurredAt string
+		if err := rows.Scan(
+			&l.EventKey, &l.UserID, &l.ExerciseKey, &l.Exercise, &activityDate, &occurredAt,
+			&l.Sets, &l.Reps, &l.VolumeKg, &l.TopWeightKg, &l.EstimatedOneRepMaxKg,
+		); err != nil {
+			return nil, err
+		}
+		if l.ActivityDate, err = time.Parse(time.DateOnly, activityDate); err != nil {
+			return nil, err
+		}
+		if l.OccurredAt, err = time.Parse(time.RFC3339, occurredAt); err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, rows.Err()
+}
+
+func (r *ReportRepository) GetLiftingExercises(ctx context.Context, userID string) ([]domain.LiftingExerciseSummary, error) {
+	// The display name is taken from the latest session so renames in Hevy
+	// show up without splitting the history.
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT l.exercise_key,
+			(SELECT exercise FROM lifting_logs x
+				WHERE x.user_id = l.user_id AND x.exercise_key = l.exercise_key
+				ORDER BY x.occurred_at_utc DESC LIMIT 1),
+			COUNT(*), MAX(l.e1rm_kg), MAX(l.volume_kg), MAX(l.activity_date)
+		FROM lifting_logs l
+		WHERE l.user_id = ?
+		GROUP BY l.exercise_key
+		ORDER BY MAX(l.activity_date) DESC, l.exercise_key ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []domain.LiftingExerciseSummary
+	for rows.Next() {
+		var s domain.LiftingExerciseSummary
+		var lastDate string
+		if err := rows.Scan(&s.ExerciseKey, &s.Exercise, &s.Sessions, &s.BestEstimatedOneRepMaxKg, &s.BestVolumeKg, &lastDate); err != nil {
+			return nil, err
+		}
+		if s.LastActivityDate, err = time.Parse(time.DateOnly, lastDate); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
+}
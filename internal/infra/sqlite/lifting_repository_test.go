@@ -0,0 +1,65 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestReportRepository_LiftingLogsHistoryAndBest(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	day1 := time.Date(2026, time.September, 1, 11, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 3)
+
+	bench := func(weight float64, reps int) *domain.Workout {
+		return &domain.Workout{ExerciseLogs: []domain.ExerciseLog{{
+			Name: "Bench Press (Barbell)",
+			Sets: []domain.ExerciseSet{{Kind: domain.SetKindWeighted, WeightKg: weight, Reps: reps}},
+		}}}
+	}
+
+	first := domain.NewLiftingLogs("user123", "evt-1", bench(60, 8), domain.GetToday(day1), day1)
+	inserted, err := repo.RecordLiftingLogs(ctx, first)
+	if err != nil || !inserted {
+		t.Fatalf("RecordLiftingLogs = %v, %v; want true, nil", inserted, err)
+	}
+	inserted, err = repo.RecordLiftingLogs(ctx, first)
+	if err != nil || inserted {
+		t.Fatalf("duplicate RecordLiftingLogs = %v, %v; want false, nil", inserted, err)
+	}
+	if _, err := repo.RecordLiftingLogs(ctx, domain.NewLiftingLogs("user123", "evt-2", bench(70, 5), domain.GetToday(day2), day2)); err != nil {
+		t.Fatalf("RecordLiftingLogs failed: %v", err)
+	}
+
+	best, err := repo.GetBestEstimatedOneRepMax(ctx, "user123", []string{"bench press (barbell)", "squat"})
+	if err != nil {
+		t.Fatalf("GetBestEstimatedOneRepMax failed: %v", err)
+	}
+	if best["bench press (barbell)"] != 81.7 || best["squat"] != 0 {
+		t.Fatalf("unexpected best lifts: %v", best)
+	}
+
+	history, err := repo.GetLiftingHistory(ctx, "user123", "bench press (barbell)", 10)
+	if err != nil {
+		t.Fatalf("GetLiftingHistory failed: %v", err)
+	}
+	if len(history) != 2 || history[0].VolumeKg != 480 || history[1].TopWeightKg != 70 || !history[1].OccurredAt.Equal(day2) {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	if latest, _ := repo.GetLiftingHistory(ctx, "user123", "bench press (barbell)", 1); len(latest) != 1 || latest[0].TopWeightKg != 70 {
+		t.Fatalf("limit should keep the latest session, got %+v", latest)
+	}
+
+	exercises, err := repo.GetLiftingExercises(ctx, "user123")
+	if err != nil {
+		t.Fatalf("GetLiftingExercises failed: %v", err)
+	}
+	if len(exercises) != 1 || exercises[0].Sessions != 2 || exercises[0].BestEstimatedOneRepMaxKg != 81.7 || exercises[0].Exercise != "Bench Press (Barbell)" {
+		t.Fatalf("unexpected exercises: %+v", exercises)
+	}
+}
@@ -0,0 +1,234 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func (r *ReportRepository) initLoginTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS login_challenges (
+			user_id               TEXT PRIMARY KEY,
+			code_hash             TEXT NOT NULL DEFAULT '',
+			attempts              INTEGER NOT NULL DEFAULT 0,
+			send_count            INTEGER NOT NULL DEFAULT 0,
+			window_started_at_utc TEXT NOT NULL,
+			last_sent_at_utc      TEXT NOT NULL,
+			expires_at_utc        TEXT NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS login_links (
+			nonce          TEXT PRIMARY KEY,
+			user_id        TEXT NOT NULL,
+			created_at_utc TEXT NOT NULL,
+			expires_at_utc TEXT NOT NULL,
+			used_at_utc    TEXT NOT NULL DEFAULT ''
+		);
+
+		CREATE TABLE IF NOT EXISTS login_audit (
+			id             INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id        TEXT NOT NULL,
+			event          TEXT NOT NULL,
+			ip             TEXT NOT NULL DEFAULT '',
+			user_agent     TEXT NOT NULL DEFAULT '',
+			created_at_utc TEXT NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_login_audit_user
+			ON login_audit (user_id, id);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+func (r *ReportRepository) GetLoginChallenge(ctx context.Context, userID string) (*domain.LoginChallenge, error) {
+	var challenge domain.LoginChallenge
+	var windowStartedAt, lastSentAt, expiresAt string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, code_hash, attempts, send_count,
+			window_started_at_utc, last_sent_at_utc, expires_at_utc
+		FROM login_challenges WHERE user_id = ?
+	`, userID).Scan(
+		&challenge.UserID,
+		&challenge.CodeHash,
+		&challenge.Attempts,
+		&challenge.SendCount,
+		&windowStartedAt,
+		&lastSentAt,
+		&expiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	challenge.WindowStartedAt, _ = time.Parse(time.RFC3339, windowStartedAt)
+	challenge.LastSentAt, _ = time.Parse(time.RFC3339, lastSentAt)
+	challenge.ExpiresAt, _ = time.Parse(time.RFC3339, expiresAt)
+	return &challenge, nil
+}
+
+func (r *ReportRepository) SaveLoginChallenge(ctx context.Context, challenge domain.LoginChallenge, lastSentAt time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO login_challenges (
+			user_id, code_hash, attempts, send_count,
+			window_started_at_utc, last_sent_at_utc, expires_at_utc
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET
+			code_hash = excluded.code_hash,
+			attempts = excluded.attempts,
+			send_count = excluded.send_count,
+			window_started_at_utc = excluded.window_started_at_utc,
+			last_sent_at_utc = excluded.last_sent_at_utc,
+			expires_at_utc = excluded.expires_at_utc
+		WHERE login_challenges.last_sent_at_utc = ?
+	`,
+		challenge.UserID,
+		challenge.CodeHash,
+		challenge.Attempts,
+		challenge.SendCount,
+		challenge.WindowStartedAt.UTC().Format(time.RFC3339),
+		challenge.LastSentAt.UTC().Format(time.RFC3339),
+		challenge.ExpiresAt.UTC().Format(time.RFC3339),
+		lastSentAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *ReportRepository) IncrementLoginAttempts(ctx context.Context, userID string) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE login_challenges SET attempts = attempts + 1
+		WHERE user_id = ? AND code_hash != ''
+	`, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	var attempts int
+	if err := tx.QueryRowContext(ctx, `SELECT attempts FROM login_challenges WHERE user_id = ?`, userID).Scan(&attempts); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	return attempts, tx.Commit()
+}
+
+func (r *ReportRepository) ConsumeLoginChallenge(ctx context.Context, userID, codeHash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE login_challenges SET code_hash = ''
+		WHERE user_id = ? AND code_hash = ? AND code_hash != ''
+	`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *ReportRepository) CreateLoginLink(ctx context.Context, link domain.LoginLink) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO login_links (nonce, user_id, created_at_utc, expires_at_utc, used_at_utc)
+		VALUES (?, ?, ?, ?, ?)
+	`,
+		link.Nonce,
+		link.UserID,
+		link.CreatedAt.UTC().Format(time.RFC3339),
+		link.ExpiresAt.UTC().Format(time.RFC3339),
+		formatOptionalTime(link.UsedAt),
+	)
+	return err
+}
+
+func (r *ReportRepository) ConsumeLoginLink(ctx context.Context, nonce string, now time.Time) (*domain.LoginLink, error) {
+	nowStr := now.UTC().Format(time.RFC3339)
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE login_links SET used_at_utc = ?
+		WHERE nonce = ? AND used_at_utc = '' AND expires_at_utc > ?
+	`, nowStr, nonce, nowStr)
+	if err != nil {
+		return nil, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, nil
+	}
+
+	var link domain.LoginLink
+	var createdAt, expiresAt, usedAt string
+	err = r.db.QueryRowContext(ctx, `
+		SELECT nonce, user_id, created_at_utc, expires_at_utc, used_at_utc
+		FROM login_links WHERE nonce = ?
+	`, nonce).Scan(&link.Nonce, &link.UserID, &createdAt, &expiresAt, &usedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	link.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	link.ExpiresAt, _ = time.Parse(time.RFC3339, expiresAt)
+	link.UsedAt, _ = time.Parse(time.RFC3339, usedAt)
+	return &link, nil
+}
+
+func (r *ReportRepository) AddLoginAudit(ctx context.Context, entry domain.LoginAuditEntry) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO login_audit (user_id, event, ip, user_agent, created_at_utc)
+		VALUES (?, ?, ?, ?, ?)
+	`, entry.UserID, entry.Event, entry.IP, entry.UserAgent, entry.CreatedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (r *ReportRepository) ListLoginAudit(ctx context.Context, userID string, limit int) ([]domain.LoginAuditEntry, error) {
+	query := `SELECT id, user_id, event, ip, user_agent, created_at_utc FROM login_audit`
+	var args []any
+	if userID != "" {
+		query += ` WHERE user_id = ?`
+		args = append(args, userID)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []domain.LoginAuditEntry
+	for rows.Next() {
+		var entry domain.LoginAuditEntry
+		var createdAt string
+		if err := rows.Scan(&entry.ID, &entry.UserID, &entry.Event, &entry.IP, &entry.UserAgent, &createdAt); err != nil {
+			return nil, err
+		}
+		entry.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
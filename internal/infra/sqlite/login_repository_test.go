@@ -0,0 +1,123 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestLoginChallenge_AttemptsAndSingleUse(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	if n, err := repo.IncrementLoginAttempts(ctx, "628111"); err != nil || n != 0 {
+		t.Fatalf("no pending code should count as zero attempts, got %d, %v", n, err)
+	}
+
+	challenge := domain.LoginChallenge{
+		UserID:          "628111",
+		CodeHash:        "hash-1",
+		SendCount:       1,
+		WindowStartedAt: now,
+		LastSentAt:      now,
+		ExpiresAt:       now.Add(5 * time.Minute),
+	}
+	if ok, err := repo.SaveLoginChallenge(ctx, challenge, time.Time{}); err != nil || !ok {
+		t.Fatalf("SaveLoginChallenge = %v, %v", ok, err)
+	}
+	// A parallel request that read no challenge either must not overwrite it.
+	stale := challenge
+	stale.CodeHash = "hash-2"
+	if ok, err := repo.SaveLoginChallenge(ctx, stale, time.Time{}); err != nil || ok {
+		t.Fatalf("save over a challenge sent since the read = %v, %v", ok, err)
+	}
+	for want := 1; want <= 2; want++ {
+		if n, err := repo.IncrementLoginAttempts(ctx, "628111"); err != nil || n != want {
+			t.Fatalf("IncrementLoginAttempts = %d, %v; want %d", n, err, want)
+		}
+	}
+
+	if ok, err := repo.ConsumeLoginChallenge(ctx, "628111", "wrong"); err != nil || ok {
+		t.Fatalf("consuming with the wrong hash should fail, got %v, %v", ok, err)
+	}
+	if ok, err := repo.ConsumeLoginChallenge(ctx, "628111", "hash-1"); err != nil || !ok {
+		t.Fatalf("ConsumeLoginChallenge = %v, %v", ok, err)
+	}
+	if ok, _ := repo.ConsumeLoginChallenge(ctx, "628111", "hash-1"); ok {
+		t.Fatal("a code must only be consumed once")
+	}
+
+	got, err := repo.GetLoginChallenge(ctx, "628111")
+	if err != nil || got == nil {
+		t.Fatalf("GetLoginChallenge = %v, %v", got, err)
+	}
+	if got.CodeHash != "" || got.Attempts != 2 || got.SendCount != 1 || !got.ExpiresAt.Equal(now.Add(5*time.Minute)) {
+		t.Fatalf("consuming should only clear the hash, got %+v", got)
+	}
+	if missing, err := repo.GetLoginChallenge(ctx, "628999"); err != nil || missing != nil {
+		t.Fatalf("unknown user should have no challenge, got %v, %v", missing, err)
+	}
+}
+
+func TestLoginAudit_ListNewestFirst(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	entries := []domain.LoginAuditEntry{
+		{UserID: "628111", Event: domain.LoginEventCodeSent, IP: "10.0.0.1", UserAgent: "curl", CreatedAt: now},
+		{UserID: "628222", Event: domain.LoginEventCodeSent, CreatedAt: now},
+		{UserID: "628111", Event: domain.LoginEventVerified, IP: "10.0.0.1", CreatedAt: now.Add(time.Minute)},
+	}
+	for _, entry := range entries {
+		if err := repo.AddLoginAudit(ctx, entry); err != nil {
+			t.Fatalf("AddLoginAudit: %v", err)
+		}
+	}
+
+	got, err := repo.ListLoginAudit(ctx, "628111", 10)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("ListLoginAudit = %v, %v", got, err)
+	}
+	if got[0].Event != domain.LoginEventVerified || got[1].UserAgent != "curl" || !got[0].CreatedAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected audit entries: %+v", got)
+	}
+	if all, _ := repo.ListLoginAudit(ctx, "", 2); len(all) != 2 || all[0].UserID != "628111" || all[1].UserID != "628222" {
+		t.Fatalf("limit should keep the newest entries, got %+v", all)
+	}
+}
+
+func TestLoginLink_ConsumeOnce(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	for _, nonce := range []string{"n1", "n2"} {
+		if err := repo.CreateLoginLink(ctx, domain.LoginLink{Nonce: nonce, UserID: "628111", CreatedAt: now, ExpiresAt: now.Add(5 * time.Minute)}); err != nil {
+			t.Fatalf("CreateLoginLink: %v", err)
+		}
+	}
+
+	link, err := repo.ConsumeLoginLink(ctx, "n1", now.Add(time.Minute))
+	if err != nil || link == nil {
+		t.Fatalf("ConsumeLoginLink = %v, %v", link, err)
+	}
+	if link.UserID != "628111" || !link.UsedAt.Equal(now.Add(time.Minute)) || !link.ExpiresAt.Equal(now.Add(5*time.Minute)) {
+		t.Fatalf("unexpected link: %+v", link)
+	}
+	if again, _ := repo.ConsumeLoginLink(ctx, "n1", now.Add(time.Minute)); again != nil {
+		t.Fatal("a link must only be consumed once")
+	}
+	if expired, _ := repo.ConsumeLoginLink(ctx, "n2", now.Add(5*time.Minute)); expired != nil {
+		t.Fatal("an expired link must not be consumed")
+	}
+	if missing, err := repo.ConsumeLoginLink(ctx, "nope", now); err != nil || missing != nil {
+		t.Fatalf("unknown link should be nil, got %v, %v", missing, err)
+	}
+}
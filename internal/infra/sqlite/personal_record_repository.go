@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func (r *ReportRepository) initPersonalRecordTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS personal_records (
+			user_id         TEXT NOT NULL,
+			record_type     TEXT NOT NULL,
+			ref             TEXT NOT NULL,
+			value           INTEGER NOT NULL,
+			activity_date   TEXT NOT NULL,
+			achieved_at_utc TEXT NOT NULL,
+			PRIMARY KEY (user_id, record_type, ref)
+		);
+		CREATE INDEX IF NOT EXISTS idx_personal_records_user_achieved ON personal_records (user_id, achieved_at_utc);
+	`
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE personal_records ADD COLUMN event_key TEXT NOT NULL DEFAULT ''")
+	// Single-effort entries are already keyed by their event in ref.
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE personal_records SET event_key = ref
+		WHERE event_key = '' AND record_type NOT IN (?, ?)
+	`, domain.RecordBiggestWeeklyDistance, domain.RecordMostActiveMonth); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_personal_records_user_event ON personal_records (user_id, event_key)`)
+	return err
+}
+
+func (r *ReportRepository) SavePersonalRecord(ctx context.Context, rec domain.PersonalRecord) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO personal_records (user_id, record_type, ref, event_key, value, activity_date, achieved_at_utc)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, record_type, ref) DO UPDATE SET
+			event_key = excluded.event_key,
+			value = excluded.value,
+			activity_date = excluded.activity_date,
+			achieved_at_utc = excluded.achieved_at_utc
+	`,
+		rec.UserID,
+		rec.RecordType,
+		rec.Ref,
+		rec.EventKey,
+		rec.Value,
+		rec.ActivityDate.Format(time.DateOnly),
+		rec.AchievedAt.UTC().Format(time.RFC3339),
+	)
+	return err
+}
+
+func (r *ReportRepository) GetPersonalRecordHistory(ctx context.Context, userID, recordType string) ([]domain.PersonalRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT user_id, record_type, ref, event_key, value, activity_date, achieved_at_utc
+		FROM personal_records
+		WHERE user_id = ? AND (? = '' OR record_type = ?)
+		ORDER BY achieved_at_utc ASC, record_type ASC
+	`, userID, recordType, recordType)
+	if err != nil {
+		return nil, err
+	}
+	return scanPersonalRecords(rows)
+}
+
+func (r *ReportRepository) DeletePersonalRecordsByEvent(ctx context.Context, userID, eventKey string) ([]domain.PersonalRecord, error) {
+	if eventKey == "" {
+		return nil, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		DELETE FROM personal_records
+		WHERE user_id = ? AND event_key = ?
+		RETURNING user_id, record_type, ref, event_key, value, activity_date, achieved_at_utc
+	`, userID, eventKey)
+	if err != nil {
+		return nil, err
+	}
+	return scanPersonalRecords(rows)
+}
+
+func scanPersonalRecords(rows *sql.Rows) ([]domain.PersonalRecord, error) {
+	defer rows.Close()
+
+	var records []domain.PersonalRecord
+	for rows.Next() {
+		var rec domain.PersonalRecord
+		var activityDate, achievedAt string
+		err := rows.Scan(&rec.UserID, &rec.RecordType, &rec.Ref, &rec.EventKey, &rec.Value, &activityDate, &achievedAt)
+		if err != nil {
+			return nil, err
+		}
+		if rec.ActivityDate, err = time.Parse(time.DateOnly, activityDate); err != nil {
+			return nil, err
+		}
+		if rec.AchievedAt, err = time.Parse(time.RFC3339, achievedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,89 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestReportRepository_PersonalRecordHistory(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2026, time.September, 7, 7, 0, 0, 0, time.UTC)
+	week := domain.PersonalRecord{
+		UserID:       "user123",
+		RecordType:   domain.RecordBiggestWeeklyDistance,
+		Ref:          "2026-W36",
+		Value:        12000,
+		ActivityDate: domain.GetToday(now),
+		AchievedAt:   now,
+	}
+	if err := repo.SavePersonalRecord(ctx, week); err != nil {
+		t.Fatalf("SavePersonalRecord failed: %v", err)
+	}
+	week.Value = 18000
+	week.AchievedAt = now.Add(2 * time.Hour)
+	if err := repo.SavePersonalRecord(ctx, week); err != nil {
+		t.Fatalf("SavePersonalRecord update failed: %v", err)
+	}
+	if err := repo.SavePersonalRecord(ctx, domain.PersonalRecord{
+		UserID: "user123", RecordType: domain.RecordFastest5K, Ref: "evt-1", Value: 1650,
+		ActivityDate: domain.GetToday(now), AchievedAt: now.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("SavePersonalRecord failed: %v", err)
+	}
+
+	all, err := repo.GetPersonalRecordHistory(ctx, "user123", "")
+	if err != nil {
+		t.Fatalf("GetPersonalRecordHistory failed: %v", err)
+	}
+	if len(all) != 2 || all[0].RecordType != domain.RecordFastest5K || all[1].Value != 18000 {
+		t.Fatalf("unexpected history: %+v", all)
+	}
+
+	weekly, err := repo.GetPersonalRecordHistory(ctx, "user123", domain.RecordBiggestWeeklyDistance)
+	if err != nil {
+		t.Fatalf("GetPersonalRecordHistory failed: %v", err)
+	}
+	if len(weekly) != 1 || !weekly[0].AchievedAt.Equal(now.Add(2*time.Hour)) {
+		t.Fatalf("unexpected weekly history: %+v", weekly)
+	}
+}
+
+func TestReportRepository_DeletePersonalRecordsByEvent(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2026, time.September, 7, 7, 0, 0, 0, time.UTC)
+	for _, rec := range []domain.PersonalRecord{
+		{UserID: "user123", RecordType: domain.RecordFastest5K, Ref: "evt-1", EventKey: "evt-1", Value: 1650},
+		{UserID: "user123", RecordType: domain.RecordBiggestWeeklyDistance, Ref: "2026-W37", EventKey: "evt-1", Value: 8000},
+		{UserID: "user123", RecordType: domain.RecordLongestRun, Ref: "evt-2", EventKey: "evt-2", Value: 6000},
+	} {
+		rec.ActivityDate, rec.AchievedAt = domain.GetToday(now), now
+		if err := repo.SavePersonalRecord(ctx, rec); err != nil {
+			t.Fatalf("SavePersonalRecord failed: %v", err)
+		}
+	}
+
+	removed, err := repo.DeletePersonalRecordsByEvent(ctx, "user123", "evt-1")
+	if err != nil {
+		t.Fatalf("DeletePersonalRecordsByEvent failed: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Fatalf("removed %d records, want 2: %+v", len(removed), removed)
+	}
+
+	left, err := repo.GetPersonalRecordHistory(ctx, "user123", "")
+	if err != nil {
+		t.Fatalf("GetPersonalRecordHistory failed: %v", err)
+	}
+	if len(left) != 1 || left[0].EventKey != "evt-2" {
+		t.Fatalf("unexpected records left: %+v", left)
+	}
+}
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func (r *ReportRepository) initProfileTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS public_profiles (
+			user_id        TEXT PRIMARY KEY,
+			public_id      TEXT NOT NULL,
+			handle         TEXT NOT NULL DEFAULT '',
+			enabled        INTEGER NOT NULL DEFAULT 0,
+			updated_at_utc TEXT NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_public_profiles_public_id ON public_profiles(public_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_public_profiles_handle ON public_profiles(handle) WHERE handle <> '';
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+const publicProfileSelect = `
+	SELECT user_id, public_id, handle, enabled, updated_at_utc
+	FROM public_profiles`
+
+func (r *ReportRepository) GetPublicProfile(ctx context.Context, userID string) (*domain.PublicProfile, error) {
+	return scanPublicProfile(r.db.QueryRowContext(ctx, publicProfileSelect+` WHERE user_id = ?`, userID))
+}
+
+func (r *ReportRepository) FindPublicProfile(ctx context.Context, key string) (*domain.PublicProfile, error) {
+	// A handle can't contain the dashes of a public ID, so at most one of
+	// the two matches.
+	return scanPublicProfile(r.db.QueryRowContext(ctx, publicProfileSelect+`
+		WHERE public_id = ? OR (handle <> '' AND handle = ?)
+		LIMIT 1
+	`, key, key))
+}
+
+func (r *ReportRepository) SavePublicProfile(ctx context.Context, profile domain.PublicProfile) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO public_profiles (user_id, public_id, handle, enabled, updated_at_utc)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET
+			public_id = excluded.public_id,
+			handle = excluded.handle,
+			enabled = excluded.enabled,
+			updated_at_utc = excluded.updated_at_utc
+	`,
+		profile.UserID,
+		profile.PublicID,
+		profile.Handle,
+		profile.Enabled,
+		profile.UpdatedAt.UTC().Format(time.RFC3339),
+	)
+	// The handle check in the usecase can race another user taking the
+	// same handle; the unique index settles it.
+	if isUniqueViolation(err) {
+		return domain.ErrPublicProfileHandleTaken
+	}
+	return err
+}
+
+// isUniqueViolation reports whether err is SQLite refusing a row for a
+// UNIQUE constraint. The production and test drivers have different error
+// types but both pass on SQLite's message.
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+func scanPublicProfile(row *sql.Row) (*domain.PublicProfile, error) {
+	var profile domain.PublicProfile
+	var updatedAt string
+	err := row.Scan(&profile.UserID, &profile.PublicID, &profile.Handle, &profile.Enabled, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	profile.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return &profile, nil
+}
@@ -0,0 +1,61 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestPublicProfile_SaveFindAndUniqueHandle(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	if profile, err := repo.GetPublicProfile(ctx, "628111"); err != nil || profile != nil {
+		t.Fatalf("expected no profile yet, got %+v, %v", profile, err)
+	}
+
+	alice := domain.PublicProfile{UserID: "628111", PublicID: domain.PublicUserID("628111"), Handle: "alice", Enabled: true, UpdatedAt: now}
+	if err := repo.SavePublicProfile(ctx, alice); err != nil {
+		t.Fatalf("SavePublicProfile: %v", err)
+	}
+	for _, key := range []string{"alice", alice.PublicID} {
+		found, err := repo.FindPublicProfile(ctx, key)
+		if err != nil || found == nil || found.UserID != "628111" || !found.Enabled || !found.UpdatedAt.Equal(now) {
+			t.Fatalf("FindPublicProfile(%q) = %+v, %v", key, found, err)
+		}
+	}
+
+	// Two profiles without a handle don't collide, a taken handle does.
+	bob := domain.PublicProfile{UserID: "628222", PublicID: domain.PublicUserID("628222"), UpdatedAt: now}
+	carol := domain.PublicProfile{UserID: "628333", PublicID: domain.PublicUserID("628333"), UpdatedAt: now}
+	if err := repo.SavePublicProfile(ctx, bob); err != nil {
+		t.Fatalf("SavePublicProfile bob: %v", err)
+	}
+	if err := repo.SavePublicProfile(ctx, carol); err != nil {
+		t.Fatalf("SavePublicProfile carol: %v", err)
+	}
+	if found, _ := repo.FindPublicProfile(ctx, ""); found != nil {
+		t.Fatalf("an empty key must not match a profile without a handle, got %+v", found)
+	}
+	carol.Handle = "alice"
+	if err := repo.SavePublicProfile(ctx, carol); !errors.Is(err, domain.ErrPublicProfileHandleTaken) {
+		t.Fatalf("expected a taken handle to be rejected, got %v", err)
+	}
+
+	alice.Handle = ""
+	alice.Enabled = false
+	if err := repo.SavePublicProfile(ctx, alice); err != nil {
+		t.Fatalf("SavePublicProfile update: %v", err)
+	}
+	if found, _ := repo.FindPublicProfile(ctx, "alice"); found != nil {
+		t.Fatalf("a released handle should no longer resolve, got %+v", found)
+	}
+	if found, _ := repo.GetPublicProfile(ctx, "628111"); found == nil || found.Enabled {
+		t.Fatalf("expected the profile to be disabled, got %+v", found)
+	}
+}
@@ -0,0 +1,338 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const raidBossColumns = `id, season_number, week_start, archetype_id, name, icon, weakness,
+	max_hp, hp, status, spawned_at, COALESCE(defeated_at, ''), COALESCE(rewarded_at, '')`
+
+func (r *ReportRepository) initRaidTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS raid_bosses (
+			id            TEXT PRIMARY KEY,
+			season_number INTEGER NOT NULL,
+			week_start    TEXT NOT NULL,
+			archetype_id  TEXT NOT NULL,
+			name          TEXT NOT NULL,
+			icon          TEXT NOT NULL DEFAULT '',
+			weakness      TEXT NOT NULL DEFAULT '',
+			max_hp        INTEGER NOT NULL,
+			hp            INTEGER NOT NULL,
+			status        TEXT NOT NULL,
+			spawned_at    TEXT NOT NULL,
+			defeated_at   TEXT DEFAULT '',
+			rewarded_at   TEXT DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_raid_bosses_status_week ON raid_bosses (status, week_start);
+
+		CREATE TABLE IF NOT EXISTS raid_damage_logs (
+			boss_id    TEXT NOT NULL,
+			event_key  TEXT NOT NULL,
+			user_id    TEXT NOT NULL,
+			attribute  TEXT NOT NULL DEFAULT '',
+			points     INTEGER NOT NULL DEFAULT 0,
+			damage     INTEGER NOT NULL,
+			weakness   INTEGER NOT NULL DEFAULT 0,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (boss_id, event_key)
+		);
+		CREATE INDEX IF NOT EXISTS idx_raid_damage_logs_boss_user ON raid_damage_logs (boss_id, user_id);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+func (r *ReportRepository) CreateRaidBoss(ctx context.Context, boss *domain.RaidBoss) (bool, error) {
+	query := `
+		INSERT OR IGNORE INTO raid_bosses
+			(id, season_number, week_start, archetype_id, name, icon, weakness, max_hp, hp, status, spawned_at, defeated_at, rewarded_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, '', '')
+	`
+	res, err := r.db.ExecContext(ctx, query,
+		boss.ID,
+		boss.SeasonNumber,
+		boss.WeekStart.Format(time.DateOnly),
+		boss.ArchetypeID,
+		boss.Name,
+		boss.Icon,
+		string(boss.Weakness),
+		boss.MaxHP,
+		boss.HP,
+		boss.Status,
+		boss.SpawnedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *ReportRepository) GetRaidBoss(ctx context.Context, bossID string) (*domain.RaidBoss, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+raidBossColumns+" FROM raid_bosses WHERE id = ?", bossID)
+	return scanRaidBoss(row)
+}
+
+func (r *ReportRepository) ApplyRaidDamage(ctx context.Context, hit domain.RaidDamage) (*domain.RaidBoss, bool, bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	createdAt := hit.CreatedAt.UTC().Format(time.RFC3339)
+	res, err := tx.ExecContext(ctx, `
+		INSERT OR IGNORE INTO raid_damage_logs
+			(boss_id, event_key, user_id, attribute, points, damage, weakness, created_at)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?
+		WHERE EXISTS (SELECT 1 FROM raid_bosses WHERE id = ? AND status = ?)
+	`, hit.BossID, hit.EventKey, hit.UserID, string(hit.Attribute), hit.Points, hit.Damage, boolToInt(hit.Weakness), createdAt,
+		hit.BossID, domain.RaidStatusActive)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, false, false, err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, false, false, err
+	}
+	if inserted == 0 {
+		// Boss is gone/defeated or this event already hit it.
+		boss, err := scanRaidBoss(tx.QueryRowContext(ctx, "SELECT "+raidBossColumns+" FROM raid_bosses WHERE id = ?", hit.BossID))
+		_ = tx.Rollback()
+		return boss, false, false, err
+	}
+
+	// SQLite evaluates every SET expression against the pre-update row, so
+	// status/defeated_at see the old hp value.
+	_, err = tx.ExecContext(ctx, `
+		UPDATE raid_bosses SET
+			hp = MAX(hp - ?, 0),
+			status = CASE WHEN hp - ? <= 0 THEN ? ELSE status END,
+			defeated_at = CASE WHEN hp - ? <= 0 THEN ? ELSE defeated_at END
+		WHERE id = ? AND status = ?
+	`, hit.Damage, hit.Damage, domain.RaidStatusDefeated, hit.Damage, createdAt, hit.BossID, domain.RaidStatusActive)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, false, false, err
+	}
+
+	boss, err := scanRaidBoss(tx.QueryRowContext(ctx, "SELECT "+raidBossColumns+" FROM raid_bosses WHERE id = ?", hit.BossID))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, false, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, false, err
+	}
+
+	defeated := boss != nil && boss.Status == domain.RaidStatusDefeated
+	return boss, true, defeated, nil
+}
+
+func (r *ReportRepository) GetRaidContributions(ctx context.Context, bossID string) ([]domain.RaidContribution, error) {
+	return queryRaidContributions(ctx, r.db, bossID)
+}
+
+func (r *ReportRepository) MarkRaidRewarded(ctx context.Context, bossID string, now time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE raid_bosses SET rewarded_at = ?
+		WHERE id = ? AND status = ? AND COALESCE(rewarded_at, '') = ''
+	`, now.UTC().Format(time.RFC3339), bossID, domain.RaidStatusDefeated)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *ReportRepository) ListUnrewardedRaidBosses(ctx context.Context) ([]*domain.RaidBoss, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+raidBossColumns+" FROM raid_bosses WHERE status = ? AND COALESCE(rewarded_at, '') = '' ORDER BY week_start",
+		domain.RaidStatusDefeated)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bosses []*domain.RaidBoss
+	for rows.Next() {
+		boss, err := scanRaidBoss(rows)
+		if err != nil {
+			return nil, err
+		}
+		bosses = append(bosses, boss)
+	}
+	return bosses, rows.Err()
+}
+
+func (r *ReportRepository) RevertRaidDamage(ctx context.Context, eventKey string) (*domain.RaidBoss, bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var bossID string
+	var damage int
+	err = tx.QueryRowContext(ctx, `
+		SELECT l.boss_id, l.damage
+		FROM raid_damage_logs l
+		JOIN raid_bosses b ON b.id = l.boss_id
+		WHERE l.event_key = ? AND b.status = ?
+	`, eventKey, domain.RaidStatusActive).Scan(&bossID, &damage)
+	if err == sql.ErrNoRows {
+		// No hit, or the boss already fell or escaped and keeps its log.
+		_ = tx.Rollback()
+		return nil, false, nil
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM raid_damage_logs WHERE boss_id = ? AND event_key = ?`, bossID, eventKey); err != nil {
+		_ = tx.Rollback()
+		return nil, false, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE raid_bosses SET hp = MIN(hp + ?, max_hp) WHERE id = ?`, damage, bossID); err != nil {
+		_ = tx.Rollback()
+		return nil, false, err
+	}
+	boss, err := scanRaidBoss(tx.QueryRowContext(ctx, "SELECT "+raidBossColumns+" FROM raid_bosses WHERE id = ?", bossID))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return boss, true, nil
+}
+
+func (r *ReportRepository) ExpireRaidBosses(ctx context.Context, weekStart time.Time) ([]*domain.RaidBoss, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT "+raidBossColumns+" FROM raid_bosses WHERE status = ? AND week_start < ? ORDER BY week_start",
+		domain.RaidStatusActive, weekStart.Format(time.DateOnly))
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	var bosses []*domain.RaidBoss
+	for rows.Next() {
+		boss, err := scanRaidBoss(rows)
+		if err != nil {
+			rows.Close()
+			_ = tx.Rollback()
+			return nil, err
+		}
+		bosses = append(bosses, boss)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		_ = tx.Rollback()
+		return nil, err
+	}
+	rows.Close()
+
+	for _, boss := range bosses {
+		if _, err := tx.ExecContext(ctx, "UPDATE raid_bosses SET status = ? WHERE id = ?", domain.RaidStatusExpired, boss.ID); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		boss.Status = domain.RaidStatusExpired
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return bosses, nil
+}
+
+type queryContexter interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func queryRaidContributions(ctx context.Context, q queryContexter, bossID string) ([]domain.RaidContribution, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT l.user_id, COALESCE(u.name, l.user_id), SUM(l.damage), COUNT(*)
+		FROM raid_damage_logs l
+		LEFT JOIN user_reports u ON u.user_id = l.user_id
+		WHERE l.boss_id = ?
+		GROUP BY l.user_id
+		ORDER BY SUM(l.damage) DESC, MIN(l.created_at) ASC
+	`, bossID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contributions []domain.RaidContribution
+	for rows.Next() {
+		var c domain.RaidContribution
+		if err := rows.Scan(&c.UserID, &c.Name, &c.TotalDamage, &c.Hits); err != nil {
+			return nil, err
+		}
+		contributions = append(contributions, c)
+	}
+	return contributions, rows.Err()
+}
+
+func scanRaidBoss(scanner interface{ Scan(dest ...any) error }) (*domain.RaidBoss, error) {
+	var boss domain.RaidBoss
+	var weekStartStr, weaknessStr, spawnedAtStr, defeatedAtStr, rewardedAtStr string
+	err := scanner.Scan(
+		&boss.ID, &boss.SeasonNumber, &weekStartStr, &boss.ArchetypeID, &boss.Name, &boss.Icon, &weaknessStr,
+		&boss.MaxHP, &boss.HP, &boss.Status, &spawnedAtStr, &defeatedAtStr, &rewardedAtStr,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	boss.Weakness = domain.AttributeType(weaknessStr)
+	boss.WeekStart, err = time.Parse(time.DateOnly, weekStartStr)
+	if err != nil {
+		return nil, err
+	}
+	boss.SpawnedAt, err = time.Parse(time.RFC3339, spawnedAtStr)
+	if err != nil {
+		return nil, err
+	}
+	if defeatedAtStr != "" {
+		defeatedAt, err := time.Parse(time.RFC3339, defeatedAtStr)
+		if err != nil {
+			return nil, err
+		}
+		boss.DefeatedAt = &defeatedAt
+	}
+	if rewardedAtStr != "" {
+		rewardedAt, err := time.Parse(time.RFC3339, rewardedAtStr)
+		if err != nil {
+			return nil, err
+		}
+		boss.RewardedAt = &rewardedAt
+	}
+	return &boss, nil
+}
+
+func boolToInt(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,194 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestRaidRepository_DamageDefeatAndRewards(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2026, 6, 16, 8, 0, 0, 0, time.UTC)
+	weekStart := domain.GetStartOfISOWeek(now)
+
+	for _, r := range []*domain.Report{
+		{UserID: "alice", Name: "Alice", LastReportDate: now, TotalPoints: 100, SeasonalPoints: 50},
+		{UserID: "bob", Name: "Bob", LastReportDate: now, TotalPoints: 10, SeasonalPoints: 10},
+	} {
+		if err := repo.UpsertReport(ctx, r); err != nil {
+			t.Fatalf("Failed to upsert report: %v", err)
+		}
+	}
+
+	boss := domain.NewRaidBoss(weekStart, 1, 0, now)
+	boss.MaxHP, boss.HP = 30, 30
+	created, err := repo.CreateRaidBoss(ctx, boss)
+	if err != nil || !created {
+		t.Fatalf("CreateRaidBoss = %v, %v; want true, nil", created, err)
+	}
+	created, err = repo.CreateRaidBoss(ctx, boss)
+	if err != nil || created {
+		t.Fatalf("second CreateRaidBoss = %v, %v; want false, nil", created, err)
+	}
+
+	hit := domain.RaidDamage{BossID: boss.ID, UserID: "alice", EventKey: "evt-1", Points: 20, Damage: 20, CreatedAt: now}
+	got, applied, defeated, err := repo.ApplyRaidDamage(ctx, hit)
+	if err != nil {
+		t.Fatalf("ApplyRaidDamage failed: %v", err)
+	}
+	if !applied || defeated || got.HP != 10 {
+		t.Fatalf("first hit = applied %v defeated %v hp %d; want true false 10", applied, defeated, got.HP)
+	}
+
+	_, applied, _, err = repo.ApplyRaidDamage(ctx, hit)
+	if err != nil || applied {
+		t.Fatalf("duplicate hit = applied %v err %v; want false, nil", applied, err)
+	}
+
+	got, applied, defeated, err = repo.ApplyRaidDamage(ctx, domain.RaidDamage{BossID: boss.ID, UserID: "bob", EventKey: "evt-2", Damage: 15, CreatedAt: now})
+	if err != nil {
+		t.Fatalf("ApplyRaidDamage failed: %v", err)
+	}
+	if !applied || !defeated || got.HP != 0 || got.Status != domain.RaidStatusDefeated || got.DefeatedAt == nil {
+		t.Fatalf("finishing hit = applied %v defeated %v boss %+v", applied, defeated, got)
+	}
+
+	contributions, err := repo.GetRaidContributions(ctx, boss.ID)
+	if err != nil {
+		t.Fatalf("GetRaidContributions failed: %v", err)
+	}
+	if len(contributions) != 2 || contributions[0].Name != "Alice" || contributions[0].TotalDamage != 20 {
+		t.Fatalf("unexpected contributions: %+v", contributions)
+	}
+
+	unrewarded, err := repo.ListUnrewardedRaidBosses(ctx)
+	if err != nil || len(unrewarded) != 1 || unrewarded[0].ID != boss.ID {
+		t.Fatalf("ListUnrewardedRaidBosses = %+v, %v; want the defeated boss", unrewarded, err)
+	}
+
+	marked, err := repo.MarkRaidRewarded(ctx, boss.ID, now)
+	if err != nil || !marked {
+		t.Fatalf("MarkRaidRewarded = %v, %v; want true, nil", marked, err)
+	}
+	unrewarded, err = repo.ListUnrewardedRaidBosses(ctx)
+	if err != nil || len(unrewarded) != 0 {
+		t.Fatalf("ListUnrewardedRaidBosses after marking = %+v, %v; want none", unrewarded, err)
+	}
+	marked, err = repo.MarkRaidRewarded(ctx, boss.ID, now)
+	if err != nil || marked {
+		t.Fatalf("second MarkRaidRewarded = %v, %v; want false, nil", marked, err)
+	}
+
+	_, reverted, err := repo.RevertRaidDamage(ctx, "evt-1")
+	if err != nil || reverted {
+		t.Fatalf("RevertRaidDamage on a defeated boss = %v, %v; want false, nil", reverted, err)
+	}
+}
+
+func TestRaidRepository_RevertRaidDamage(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2026, 6, 16, 8, 0, 0, 0, time.UTC)
+
+	boss := domain.NewRaidBoss(domain.GetStartOfISOWeek(now), 1, 0, now)
+	boss.MaxHP, boss.HP = 30, 30
+	if _, err := repo.CreateRaidBoss(ctx, boss); err != nil {
+		t.Fatalf("CreateRaidBoss failed: %v", err)
+	}
+	hit := domain.RaidDamage{BossID: boss.ID, UserID: "alice", EventKey: "evt-1", Damage: 12, CreatedAt: now}
+	if _, _, _, err := repo.ApplyRaidDamage(ctx, hit); err != nil {
+		t.Fatalf("ApplyRaidDamage failed: %v", err)
+	}
+
+	got, reverted, err := repo.RevertRaidDamage(ctx, "evt-1")
+	if err != nil || !reverted || got.HP != 30 {
+		t.Fatalf("RevertRaidDamage = %+v, %v, %v; want hp 30, true, nil", got, reverted, err)
+	}
+	_, reverted, err = repo.RevertRaidDamage(ctx, "evt-1")
+	if err != nil || reverted {
+		t.Fatalf("second RevertRaidDamage = %v, %v; want false, nil", reverted, err)
+	}
+
+	// The hit is gone, so the same event may land again later.
+	_, applied, _, err := repo.ApplyRaidDamage(ctx, hit)
+	if err != nil || !applied {
+		t.Fatalf("ApplyRaidDamage after revert = %v, %v; want true, nil", applied, err)
+	}
+}
+
+func TestReportRepository_UpsertReportWithBonusEvent(t *testing.T) {
+	db, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2026, 6, 16, 8, 0, 0, 0, time.UTC)
+	report := &domain.Report{UserID: "alice", Name: "Alice", LastReportDate: now, TotalPoints: 115, SeasonalPoints: 65}
+	event := domain.BonusEvent{EventID: "boss-1:reward:alice", UserID: "alice", SeasonNumber: 3, Source: "raid", Points: 15, OccurredAt: now}
+
+	granted, err := repo.UpsertReportWithBonusEvent(ctx, report, event)
+	if err != nil || !granted {
+		t.Fatalf("UpsertReportWithBonusEvent = %v, %v; want true, nil", granted, err)
+	}
+
+	again := *report
+	again.TotalPoints, again.SeasonalPoints = 130, 80
+	granted, err = repo.UpsertReportWithBonusEvent(ctx, &again, event)
+	if err != nil || granted {
+		t.Fatalf("second UpsertReportWithBonusEvent = %v, %v; want false, nil", granted, err)
+	}
+
+	alice, err := repo.GetReport(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetReport failed: %v", err)
+	}
+	if alice.TotalPoints != 115 || alice.SeasonalPoints != 65 {
+		t.Fatalf("alice points = %d/%d, want 115/65", alice.TotalPoints, alice.SeasonalPoints)
+	}
+
+	var seasonPoints, activeDays int
+	if err := db.QueryRow(`SELECT total_points, active_days FROM user_season_stats WHERE user_id = ? AND season_number = ?`, "alice", 3).Scan(&seasonPoints, &activeDays); err != nil {
+		t.Fatalf("read user_season_stats: %v", err)
+	}
+	if seasonPoints != 15 || activeDays != 0 {
+		t.Fatalf("season stats = %d points, %d active days; want 15, 0", seasonPoints, activeDays)
+	}
+}
+
+func TestRaidRepository_ExpireRaidBosses(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2026, 6, 16, 8, 0, 0, 0, time.UTC)
+	weekStart := domain.GetStartOfISOWeek(now)
+
+	boss := domain.NewRaidBoss(weekStart, 1, 3, now)
+	if _, err := repo.CreateRaidBoss(ctx, boss); err != nil {
+		t.Fatalf("CreateRaidBoss failed: %v", err)
+	}
+
+	expired, err := repo.ExpireRaidBosses(ctx, weekStart)
+	if err != nil || len(expired) != 0 {
+		t.Fatalf("current week expired = %d, %v; want 0, nil", len(expired), err)
+	}
+
+	expired, err = repo.ExpireRaidBosses(ctx, weekStart.AddDate(0, 0, 7))
+	if err != nil || len(expired) != 1 {
+		t.Fatalf("next week expired = %d, %v; want 1, nil", len(expired), err)
+	}
+
+	got, err := repo.GetRaidBoss(ctx, boss.ID)
+	if err != nil {
+		t.Fatalf("GetRaidBoss failed: %v", err)
+	}
+	if got.Status != domain.RaidStatusExpired {
+		t.Fatalf("status = %q, want expired", got.Status)
+	}
+}
@@ -0,0 +1,286 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const reportEventColumns = `
+	event_id, user_id, season_number, kind, activity_date, occurred_at_utc,
+	points_delta, regular_count_delta, sidequest_count_delta, rule_version,
+	source, activity_text, metadata_json, cancelled_at_utc`
+
+func scanReportEvent(scanner interface{ Scan(...any) error }) (domain.ReportActivityEvent, error) {
+	var event domain.ReportActivityEvent
+	var activityDate, occurredAt, cancelledAt string
+	err := scanner.Scan(
+		&event.EventID,
+		&event.UserID,
+		&event.SeasonNumber,
+		&event.Kind,
+		&activityDate,
+		&occurredAt,
+		&event.PointsDelta,
+		&event.RegularCountDelta,
+		&event.SideQuestCountDelta,
+		&event.RuleVersion,
+		&event.Source,
+		&event.ActivityText,
+		&event.MetadataJSON,
+		&cancelledAt,
+	)
+	if err != nil {
+		return event, err
+	}
+	event.ActivityDate, _ = time.Parse(time.DateOnly, activityDate)
+	event.OccurredAt, _ = time.Parse(time.RFC3339, occurredAt)
+	event.CancelledAt, _ = time.Parse(time.RFC3339, cancelledAt)
+	return event, nil
+}
+
+func (r *ReportRepository) GetReportEvent(ctx context.Context, userID, eventID string) (*domain.ReportActivityEvent, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+reportEventColumns+`
+		FROM report_events WHERE user_id = ? AND event_id = ?
+	`, userID, eventID)
+	event, err := scanReportEvent(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *ReportRepository) ListReportEvents(ctx context.Context, query domain.ReportEventQuery) ([]domain.ReportActivityEvent, error) {
+	where := []string{"user_id = ?"}
+	args := []any{query.UserID}
+	if query.Kind != "" {
+		where = append(where, "kind = ?")
+		args = append(args, query.Kind)
+	}
+	if query.SeasonNumber > 0 {
+		where = append(where, "season_number = ?")
+		args = append(args, query.SeasonNumber)
+	}
+	if !query.BeforeOccurredAt.IsZero() {
+		before := query.BeforeOccurredAt.UTC().Format(time.RFC3339)
+		where = append(where, "(occurred_at_utc < ? OR (occurred_at_utc = ? AND event_id < ?))")
+		args = append(args, before, before, query.BeforeEventID)
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+reportEventColumns+`
+		FROM report_events
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY occurred_at_utc DESC, event_id DESC
+		LIMIT ?
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.ReportActivityEvent
+	for rows.Next() {
+		event, err := scanReportEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (r *ReportRepository) CancelReportEvent(ctx context.Context, userID, eventID string, at time.Time) (bool, error) {
+	eventIDs, err := r.cancelReportEvents(ctx, userID, at, `event_id = ?`, eventID)
+	return len(eventIDs) > 0, err
+}
+
+func (r *ReportRepository) CancelLatestReportEvents(ctx context.Context, userID, kind string, activityDate time.Time, limit int, at time.Time) ([]string, error) {
+	if limit <= 0 {
+		limit = -1 // SQLite reads a negative LIMIT as no limit.
+	}
+	return r.cancelReportEvents(ctx, userID, at, `event_id IN (
+			SELECT event_id FROM report_events
+			WHERE user_id = ? AND kind = ? AND activity_date = ? AND cancelled_at_utc = ''
+			ORDER BY occurred_at_utc DESC, recorded_at_utc DESC
+			LIMIT ?
+		)`, userID, kind, activityDate.Format(time.DateOnly), limit)
+}
+
+// cancelledReportEvent is what a cancelled event takes back out of the
+// projections.
+type cancelledReportEvent struct {
+	eventID      string
+	seasonNumber int
+	activityDate string
+	points       int
+	regular      int
+	sideQuests   int
+}
+
+// cancelReportEvents marks the user's active events matching filter
+// cancelled and takes them out of user_daily_activity and
+// user_season_stats in the same transaction.
+func (r *ReportRepository) cancelReportEvents(ctx context.Context, userID string, at time.Time, filter string, filterArgs ...any) ([]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	args := append([]any{at.UTC().Format(time.RFC3339), userID}, filterArgs...)
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE report_events SET cancelled_at_utc = ?
+		WHERE user_id = ? AND cancelled_at_utc = '' AND `+filter+`
+		RETURNING event_id, season_number, activity_date,
+			points_delta, regular_count_delta, sidequest_count_delta
+	`, args...)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	var cancelled []cancelledReportEvent
+	for rows.Next() {
+		var event cancelledReportEvent
+		if err := rows.Scan(&event.eventID, &event.seasonNumber, &event.activityDate, &event.points, &event.regular, &event.sideQuests); err != nil {
+			rows.Close()
+			_ = tx.Rollback()
+			return nil, err
+		}
+		cancelled = append(cancelled, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	var eventIDs []string
+	for _, event := range cancelled {
+		if err := retractReportEventProjections(ctx, tx, userID, event); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		eventIDs = append(eventIDs, event.eventID)
+	}
+	return eventIDs, tx.Commit()
+}
+
+// retractReportEventProjections undoes what upsertDailyActivityProjection
+// and upsertSeasonStatsProjection added for the event. The day stops being
+// active once it has no reports left.
+func retractReportEventProjections(ctx context.Context, tx *sql.Tx, userID string, event cancelledReportEvent) error {
+	var remaining int
+	err := tx.QueryRowContext(ctx, `
+		UPDATE user_daily_activity SET
+			regular_count = MAX(regular_count - ?, 0),
+			sidequest_count = MAX(sidequest_count - ?, 0),
+			total_points = total_points - ?
+		WHERE user_id = ? AND season_number = ? AND activity_date = ?
+		RETURNING regular_count + sidequest_count
+	`, event.regular, event.sideQuests, event.points, userID, event.seasonNumber, event.activityDate).Scan(&remaining)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	dayGone := 0
+	if remaining == 0 && event.regular+event.sideQuests > 0 {
+		dayGone = 1
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE user_season_stats SET
+			total_points = total_points - ?,
+			regular_reports = MAX(regular_reports - ?, 0),
+			sidequest_reports = MAX(sidequest_reports - ?, 0),
+			active_days = MAX(active_days - ?, 0)
+		WHERE user_id = ? AND season_number = ?
+	`, event.points, event.regular, event.sideQuests, dayGone, userID, event.seasonNumber)
+	return err
+}
+
+func (r *ReportRepository) initBonusEventTables(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS bonus_events (
+			event_id        TEXT PRIMARY KEY,
+			user_id         TEXT NOT NULL,
+			season_number   INTEGER NOT NULL,
+			source          TEXT NOT NULL,
+			points          INTEGER NOT NULL,
+			occurred_at_utc TEXT NOT NULL,
+			recorded_at_utc TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_bonus_events_user_season ON bonus_events (user_id, season_number);
+	`)
+	return err
+}
+
+// UpsertReportWithBonusEvent saves report together with the bonus that
+// changed it. It reports false, and leaves report unsaved, when the bonus
+// was already recorded.
+func (r *ReportRepository) UpsertReportWithBonusEvent(ctx context.Context, report *domain.Report, event domain.BonusEvent) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+
+	occurredAt := event.OccurredAt.UTC().Format(time.RFC3339)
+	res, err := tx.ExecContext(ctx, `
+		INSERT OR IGNORE INTO bonus_events (event_id, user_id, season_number, source, points, occurred_at_utc, recorded_at_utc)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, event.EventID, event.UserID, event.SeasonNumber, event.Source, event.Points, occurredAt, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+	if inserted == 0 {
+		_ = tx.Rollback()
+		return false, nil
+	}
+
+	if err := upsertReport(ctx, tx, report); err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+	// A bonus adds points to the day and the season without counting as a
+	// report, so active days and report counts stay as they are.
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO user_daily_activity (
+			user_id, season_number, activity_date, regular_count, sidequest_count, total_points
+		) VALUES (?, ?, ?, 0, 0, ?)
+		ON CONFLICT(user_id, season_number, activity_date) DO UPDATE SET
+			total_points = total_points + excluded.total_points
+	`, event.UserID, event.SeasonNumber, domain.GetToday(event.OccurredAt).Format(time.DateOnly), event.Points); err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO user_season_stats (user_id, season_number, total_points)
+		VALUES (?, ?, ?)
+		ON CONFLICT(user_id, season_number) DO UPDATE SET
+			total_points = total_points + excluded.total_points
+	`, event.UserID, event.SeasonNumber, event.Points); err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,119 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestReportEvent_CancelByIDAndLatest(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 19, 7, 0, 0, 0, time.UTC)
+	report := &domain.Report{UserID: "628111", Name: "Budi", LastReportDate: now}
+	for i, id := range []string{"event-1", "event-2", "event-3"} {
+		event := domain.ReportActivityEvent{
+			EventID:           id,
+			UserID:            "628111",
+			SeasonNumber:      2,
+			Kind:              domain.ActivityKindRegularReport,
+			ActivityDate:      domain.GetToday(now),
+			OccurredAt:        now.Add(time.Duration(i) * time.Hour),
+			PointsDelta:       10,
+			RegularCountDelta: 1,
+			Source:            "web",
+		}
+		if err := repo.UpsertReportWithActivityEvent(ctx, report, event); err != nil {
+			t.Fatalf("UpsertReportWithActivityEvent: %v", err)
+		}
+	}
+
+	event, err := repo.GetReportEvent(ctx, "628111", "event-1")
+	if err != nil || event == nil {
+		t.Fatalf("GetReportEvent = %v, %v", event, err)
+	}
+	if event.Source != "web" || !event.ActivityDate.Equal(domain.GetToday(now)) || !event.CancelledAt.IsZero() {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	if other, err := repo.GetReportEvent(ctx, "628999", "event-1"); err != nil || other != nil {
+		t.Fatalf("another user's event should not be found, got %v, %v", other, err)
+	}
+
+	if ok, err := repo.CancelReportEvent(ctx, "628111", "event-1", now); err != nil || !ok {
+		t.Fatalf("CancelReportEvent = %v, %v", ok, err)
+	}
+	if ok, _ := repo.CancelReportEvent(ctx, "628111", "event-1", now); ok {
+		t.Fatal("an event must only be cancelled once")
+	}
+
+	// The newest active event goes first; event-1 is already cancelled.
+	if ids, err := repo.CancelLatestReportEvents(ctx, "628111", domain.ActivityKindRegularReport, domain.GetToday(now), 1, now); err != nil || len(ids) != 1 || ids[0] != "event-3" {
+		t.Fatalf("CancelLatestReportEvents = %v, %v", ids, err)
+	}
+	if latest, _ := repo.GetReportEvent(ctx, "628111", "event-3"); latest.CancelledAt.IsZero() {
+		t.Fatal("the newest event should be cancelled")
+	}
+	if ids, err := repo.CancelLatestReportEvents(ctx, "628111", domain.ActivityKindRegularReport, domain.GetToday(now), 0, now); err != nil || len(ids) != 1 {
+		t.Fatalf("cancelling the rest = %v, %v", ids, err)
+	}
+	if last, _ := repo.GetReportEvent(ctx, "628111", "event-2"); !last.CancelledAt.Equal(now) {
+		t.Fatalf("event-2 should be cancelled at %v, got %v", now, last.CancelledAt)
+	}
+}
+
+func TestReportEvent_ListNewestFirstWithCursor(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 19, 7, 0, 0, 0, time.UTC)
+	report := &domain.Report{UserID: "628111", Name: "Budi", LastReportDate: now}
+	events := []domain.ReportActivityEvent{
+		{EventID: "a", Kind: domain.ActivityKindRegularReport, SeasonNumber: 1, OccurredAt: now.AddDate(0, 0, -60)},
+		{EventID: "b", Kind: domain.ActivityKindRegularReport, SeasonNumber: 2, OccurredAt: now},
+		{EventID: "c", Kind: domain.ActivityKindSideQuest, SeasonNumber: 2, OccurredAt: now, SideQuestCountDelta: 1},
+		{EventID: "d", Kind: domain.ActivityKindRegularReport, SeasonNumber: 2, OccurredAt: now.Add(time.Hour), ActivityText: "lari 5 km"},
+	}
+	for _, event := range events {
+		event.UserID = "628111"
+		event.ActivityDate = domain.GetToday(event.OccurredAt)
+		event.PointsDelta = 10
+		event.RegularCountDelta = 1 - event.SideQuestCountDelta
+		event.MetadataJSON = "{}"
+		if err := repo.UpsertReportWithActivityEvent(ctx, report, event); err != nil {
+			t.Fatalf("UpsertReportWithActivityEvent: %v", err)
+		}
+	}
+
+	ids := func(events []domain.ReportActivityEvent) string {
+		var out string
+		for _, event := range events {
+			out += event.EventID
+		}
+		return out
+	}
+
+	page, err := repo.ListReportEvents(ctx, domain.ReportEventQuery{UserID: "628111", Limit: 2})
+	if err != nil || ids(page) != "dc" {
+		t.Fatalf("first page = %q, %v; want dc", ids(page), err)
+	}
+	if page[0].ActivityText != "lari 5 km" || !page[0].OccurredAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("unexpected event: %+v", page[0])
+	}
+	last := page[len(page)-1]
+	page, err = repo.ListReportEvents(ctx, domain.ReportEventQuery{UserID: "628111", Limit: 2, BeforeOccurredAt: last.OccurredAt, BeforeEventID: last.EventID})
+	if err != nil || ids(page) != "ba" {
+		t.Fatalf("second page = %q, %v; want ba", ids(page), err)
+	}
+
+	if got, _ := repo.ListReportEvents(ctx, domain.ReportEventQuery{UserID: "628111", Kind: domain.ActivityKindRegularReport, SeasonNumber: 2}); ids(got) != "db" {
+		t.Fatalf("kind and season filter = %q; want db", ids(got))
+	}
+	if got, _ := repo.ListReportEvents(ctx, domain.ReportEventQuery{UserID: "628999"}); len(got) != 0 {
+		t.Fatalf("another user's events should not be listed, got %q", ids(got))
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/secrets"
 )
 
 type ReportRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	secrets *secrets.Keyring
 }
 
 type execContexter interface {
@@ -247,6 +249,10 @@ func (r *ReportRepository) UpsertReportWithActivityEvent(ctx context.Context, re
 			_ = tx.Rollback()
 			return err
 		}
+		if err := insertReportEventMetrics(ctx, tx, event); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
 	}
 
 	return tx.Commit()
@@ -339,8 +345,8 @@ func upsertSeasonStatsProjection(ctx context.Context, execer execContexter, even
 					  AND (uda.regular_count + uda.sidequest_count) > (excluded.regular_reports + excluded.sidequest_reports)
 				) THEN 0 ELSE 1
 			END,
-			first_activity_date = MIN(first_activity_date, excluded.first_activity_date),
-			last_activity_date = MAX(last_activity_date, excluded.last_activity_date),
+			first_activity_date = MIN(COALESCE(first_activity_date, excluded.first_activity_date), excluded.first_activity_date),
+			last_activity_date = MAX(COALESCE(last_activity_date, excluded.last_activity_date), excluded.last_activity_date),
 			last_reported_at_utc = excluded.last_reported_at_utc
 	`
 	_, err := execer.ExecContext(ctx, query,
@@ -553,6 +559,10 @@ func (r *ReportRepository) InitTable(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	_, err = r.db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_report_events_user_occurred ON report_events (user_id, occurred_at_utc)`)
+	if err != nil {
+		return err
+	}
 	_, err = r.db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_user_daily_activity_season_date ON user_daily_activity (season_number, activity_date)`)
 	if err != nil {
 		return err
@@ -607,6 +617,7 @@ func (r *ReportRepository) InitTable(ctx context.Context) error {
 	_, _ = r.db.ExecContext(ctx, "ALTER TABLE activity_logs ADD COLUMN sidequest_count INTEGER NOT NULL DEFAULT 0")
 	_, _ = r.db.ExecContext(ctx, "ALTER TABLE activity_logs ADD COLUMN activity_text TEXT DEFAULT ''")
 	_, _ = r.db.ExecContext(ctx, "ALTER TABLE strava_accounts ADD COLUMN name TEXT")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE report_events ADD COLUMN cancelled_at_utc TEXT NOT NULL DEFAULT ''")
 
 	goalQuery := `
 		CREATE TABLE IF NOT EXISTS weekly_goals (
@@ -707,6 +718,43 @@ func (r *ReportRepository) InitTable(ctx context.Context) error {
 		return err
 	}
 
+	if err := r.initActivityMetricsTables(ctx); err != nil {
+		return err
+	}
+	if err := r.initBonusEventTables(ctx); err != nil {
+		return err
+	}
+	if err := r.initRaidTables(ctx); err != nil {
+		return err
+	}
+	if err := r.initLiftingTables(ctx); err != nil {
+		return err
+	}
+	if err := r.initPersonalRecordTables(ctx); err != nil {
+		return err
+	}
+	if err := r.initStravaTables(ctx); err != nil {
+		return err
+	}
+	if err := r.initWorkoutFileTables(ctx); err != nil {
+		return err
+	}
+	if err := r.initIntegrationTables(ctx); err != nil {
+		return err
+	}
+	if err := r.initWebhookTables(ctx); err != nil {
+		return err
+	}
+	if err := r.initLoginTables(ctx); err != nil {
+		return err
+	}
+	if err := r.initSessionTables(ctx); err != nil {
+		return err
+	}
+	if err := r.initProfileTables(ctx); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -856,9 +904,17 @@ func (r *ReportRepository) UpsertStravaAccount(ctx context.Context, account *dom
 			expires_at = excluded.expires_at,
 			name = excluded.name
 	`
-	_, err := r.db.ExecContext(ctx, query,
-		account.UserID, account.AthleteID, account.AccessToken,
-		account.RefreshToken, account.ExpiresAt.Format(time.RFC3339), account.Name,
+	accessToken, err := r.sealSecret(account.AccessToken, secretAAD("strava_accounts", "access_token", account.UserID))
+	if err != nil {
+		return err
+	}
+	refreshToken, err := r.sealSecret(account.RefreshToken, secretAAD("strava_accounts", "refresh_token", account.UserID))
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, query,
+		account.UserID, account.AthleteID, accessToken,
+		refreshToken, account.ExpiresAt.Format(time.RFC3339), account.Name,
 	)
 	return err
 }
@@ -881,6 +937,9 @@ func (r *ReportRepository) GetStravaAccountByAthleteID(ctx context.Context, athl
 	if err != nil {
 		return nil, err
 	}
+	if err := r.openStravaTokens(&account); err != nil {
+		return nil, err
+	}
 
 	return &account, nil
 }
@@ -1188,6 +1247,18 @@ func reconcileGoalAfterActivityDelete(ctx context.Context, tx *sql.Tx, userID st
 }
 
 func (r *ReportRepository) DeleteReport(ctx context.Context, userID string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM report_event_metrics WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM lifting_logs WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM personal_records WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM bonus_events WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
 	if _, err := r.db.ExecContext(ctx, `DELETE FROM report_events WHERE user_id = ?`, userID); err != nil {
 		return err
 	}
@@ -1538,6 +1609,9 @@ func (r *ReportRepository) GetStravaAccountByUserID(ctx context.Context, userID
 	if err != nil {
 		return nil, err
 	}
+	if err := r.openStravaTokens(&account); err != nil {
+		return nil, err
+	}
 
 	return &account, nil
 }
@@ -0,0 +1,161 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/secrets"
+)
+
+var errSecretKeyMissing = errors.New("stored secret is encrypted but no SECRET_KEYS are configured")
+
+// SetSecretKeyring makes the repository encrypt integration secrets, such as
+// Strava tokens, at rest. Without a keyring they are stored as given.
+func (r *ReportRepository) SetSecretKeyring(keyring *secrets.Keyring) {
+	r.secrets = keyring
+}
+
+// secretAAD names where a secret is stored. It is sealed into the
+// ciphertext, so a value copied to another row or column won't decrypt.
+func secretAAD(table, column, rowKey string) string {
+	return table + "." + column + ":" + rowKey
+}
+
+func (r *ReportRepository) sealSecret(value, aad string) (string, error) {
+	if r.secrets == nil {
+		return value, nil
+	}
+	return r.secrets.Encrypt(value, aad)
+}
+
+func (r *ReportRepository) openSecret(value, aad string) (string, error) {
+	if r.secrets == nil {
+		if secrets.IsEncrypted(value) {
+			return "", errSecretKeyMissing
+		}
+		return value, nil
+	}
+	return r.secrets.Decrypt(value, aad)
+}
+
+func (r *ReportRepository) openStravaTokens(account *domain.StravaAccount) error {
+	var err error
+	if account.AccessToken, err = r.openSecret(account.AccessToken, secretAAD("strava_accounts", "access_token", account.UserID)); err != nil {
+		return err
+	}
+	account.RefreshToken, err = r.openSecret(account.RefreshToken, secretAAD("strava_accounts", "refresh_token", account.UserID))
+	return err
+}
+
+// secretColumns lists the columns that hold secrets, per table.
+var secretColumns = []struct {
+	table   string
+	key     string
+	columns []string
+}{
+	{table: "strava_accounts", key: "user_id", columns: []string{"access_token", "refresh_token"}},
+	{table: "integrations", key: "id", columns: []string{"secret", "previous_secret"}},
+	{table: "webhook_subscriptions", key: "id", columns: []string{"secret"}},
+}
+
+// MigrateSecrets encrypts secrets stored before encryption was enabled and
+// rewraps those still under a retired key, so the old key can be dropped
+// from SECRET_KEYS afterwards. It returns how many rows were rewritten.
+func (r *ReportRepository) MigrateSecrets(ctx context.Context) (int, error) {
+	if r.secrets == nil {
+		return 0, nil
+	}
+	migrated := 0
+	for _, t := range secretColumns {
+		n, err := r.migrateSecretColumns(ctx, t.table, t.key, t.columns)
+		if err != nil {
+			return migrated, fmt.Errorf("%s: %w", t.table, err)
+		}
+		migrated += n
+	}
+	return migrated, nil
+}
+
+func (r *ReportRepository) migrateSecretColumns(ctx context.Context, table, key string, columns []string) (int, error) {
+	selected := make([]string, len(columns))
+	assignments := make([]string, len(columns))
+	conditions := make([]string, len(columns))
+	for i, c := range columns {
+		selected[i] = "COALESCE(" + c + ", '')"
+		assignments[i] = c + " = ?"
+		conditions[i] = "COALESCE(" + c + ", '') = ?"
+	}
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf("SELECT %s, %s FROM %s", key, strings.Join(selected, ", "), table))
+	if err != nil {
+		return 0, err
+	}
+	type row struct {
+		key    string
+		values []string
+	}
+	var pending []row
+	for rows.Next() {
+		current := row{values: make([]string, len(columns))}
+		dest := []any{&current.key}
+		for i := range current.values {
+			dest = append(dest, &current.values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		for _, v := range current.values {
+			if r.secrets.NeedsRewrap(v) {
+				pending = append(pending, current)
+				break
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, err
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+
+	// Rows a concurrent write already changed are skipped by matching the
+	// old values.
+	update := fmt.Sprintf("UPDATE %s SET %s WHERE %s = ? AND %s",
+		table, strings.Join(assignments, ", "), key, strings.Join(conditions, " AND "))
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, p := range pending {
+		args := make([]any, 0, 2*len(columns)+1)
+		for i, v := range p.values {
+			rewrapped, err := r.secrets.Rewrap(v, secretAAD(table, columns[i], p.key))
+			if err != nil {
+				_ = tx.Rollback()
+				return 0, err
+			}
+			args = append(args, rewrapped)
+		}
+		args = append(args, p.key)
+		for _, v := range p.values {
+			args = append(args, v)
+		}
+		res, err := tx.ExecContext(ctx, update, args...)
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			migrated++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return migrated, nil
+}
@@ -0,0 +1,150 @@
+package sqlite_test
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+	"github.com/fardannozami/whatsapp-gateway/internal/infra/secrets"
+)
+
+func newTestKeyring(t *testing.T, ids ...string) (*secrets.Keyring, []string) {
+	t.Helper()
+	specs := make([]string, len(ids))
+	for i, id := range ids {
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			t.Fatal(err)
+		}
+		specs[i] = id + ":" + base64.StdEncoding.EncodeToString(raw)
+	}
+	k, err := secrets.NewKeyring(specs)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	return k, specs
+}
+
+func storedTokens(t *testing.T, db *sql.DB, userID string) (string, string) {
+	t.Helper()
+	var access, refresh string
+	if err := db.QueryRow(`SELECT access_token, refresh_token FROM strava_accounts WHERE user_id = ?`, userID).Scan(&access, &refresh); err != nil {
+		t.Fatalf("select tokens: %v", err)
+	}
+	return access, refresh
+}
+
+func TestSecrets_StravaTokensEncryptedAtRest(t *testing.T) {
+	db, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	keyring, _ := newTestKeyring(t, "k1")
+	repo.SetSecretKeyring(keyring)
+
+	account := &domain.StravaAccount{UserID: "628111", AthleteID: 7, AccessToken: "access-1", RefreshToken: "refresh-1", ExpiresAt: time.Now().UTC().Truncate(time.Second)}
+	if err := repo.UpsertStravaAccount(ctx, account); err != nil {
+		t.Fatalf("UpsertStravaAccount: %v", err)
+	}
+
+	access, refresh := storedTokens(t, db, "628111")
+	if !strings.HasPrefix(access, "enc:v2:k1:") || !strings.HasPrefix(refresh, "enc:v2:k1:") {
+		t.Fatalf("tokens should be stored encrypted, got %q / %q", access, refresh)
+	}
+
+	byUser, err := repo.GetStravaAccountByUserID(ctx, "628111")
+	if err != nil || byUser.AccessToken != "access-1" || byUser.RefreshToken != "refresh-1" {
+		t.Fatalf("GetStravaAccountByUserID = %+v, %v", byUser, err)
+	}
+	byAthlete, err := repo.GetStravaAccountByAthleteID(ctx, 7)
+	if err != nil || byAthlete.AccessToken != "access-1" {
+		t.Fatalf("GetStravaAccountByAthleteID = %+v, %v", byAthlete, err)
+	}
+	quiet, err := repo.ListQuietStravaAccounts(ctx, time.Now(), time.Now())
+	if err != nil || len(quiet) != 1 || quiet[0].RefreshToken != "refresh-1" {
+		t.Fatalf("ListQuietStravaAccounts = %+v, %v", quiet, err)
+	}
+
+	repo.SetSecretKeyring(nil)
+	if _, err := repo.GetStravaAccountByUserID(ctx, "628111"); err == nil {
+		t.Fatal("reading encrypted tokens without a key should fail instead of returning ciphertext")
+	}
+}
+
+func TestSecrets_CopiedCiphertextDoesNotDecrypt(t *testing.T) {
+	db, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	keyring, _ := newTestKeyring(t, "k1")
+	repo.SetSecretKeyring(keyring)
+
+	for i, userID := range []string{"628111", "628222"} {
+		account := &domain.StravaAccount{UserID: userID, AthleteID: int64(i + 1), AccessToken: "access-" + userID, RefreshToken: "refresh-" + userID, ExpiresAt: time.Now()}
+		if err := repo.UpsertStravaAccount(ctx, account); err != nil {
+			t.Fatalf("UpsertStravaAccount: %v", err)
+		}
+	}
+	access, _ := storedTokens(t, db, "628111")
+	if _, err := db.Exec(`UPDATE strava_accounts SET access_token = ? WHERE user_id = ?`, access, "628222"); err != nil {
+		t.Fatalf("copy token: %v", err)
+	}
+	if _, err := repo.GetStravaAccountByUserID(ctx, "628222"); err == nil {
+		t.Fatal("a token copied from another account must not decrypt")
+	}
+}
+
+func TestSecrets_MigrateEncryptsAndRewraps(t *testing.T) {
+	db, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Rows written before encryption was configured.
+	for i, userID := range []string{"628111", "628222"} {
+		account := &domain.StravaAccount{UserID: userID, AthleteID: int64(i + 1), AccessToken: "access-" + userID, RefreshToken: "refresh-" + userID, ExpiresAt: time.Now()}
+		if err := repo.UpsertStravaAccount(ctx, account); err != nil {
+			t.Fatalf("UpsertStravaAccount: %v", err)
+		}
+	}
+	if n, err := repo.MigrateSecrets(ctx); err != nil || n != 0 {
+		t.Fatalf("migration without a keyring should be a no-op, got %d, %v", n, err)
+	}
+
+	oldKeyring, oldSpecs := newTestKeyring(t, "old")
+	repo.SetSecretKeyring(oldKeyring)
+	if n, err := repo.MigrateSecrets(ctx); err != nil || n != 2 {
+		t.Fatalf("expected 2 accounts encrypted, got %d, %v", n, err)
+	}
+	if access, _ := storedTokens(t, db, "628111"); !strings.HasPrefix(access, "enc:v2:old:") {
+		t.Fatalf("legacy token should now be encrypted, got %q", access)
+	}
+	if n, _ := repo.MigrateSecrets(ctx); n != 0 {
+		t.Fatalf("second migration should find nothing to do, got %d", n)
+	}
+
+	// Rotate: new key first, old key kept for reading until the rewrap.
+	rotated, err := secrets.NewKeyring(append([]string{newKeySpec(t)}, oldSpecs...))
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	repo.SetSecretKeyring(rotated)
+	if n, err := repo.MigrateSecrets(ctx); err != nil || n != 2 {
+		t.Fatalf("expected 2 accounts rewrapped, got %d, %v", n, err)
+	}
+	if _, refresh := storedTokens(t, db, "628222"); !strings.HasPrefix(refresh, "enc:v2:new:") {
+		t.Fatalf("token should be rewrapped under the new key, got %q", refresh)
+	}
+	account, err := repo.GetStravaAccountByUserID(ctx, "628222")
+	if err != nil || account.AccessToken != "access-628222" || account.RefreshToken != "refresh-628222" {
+		t.Fatalf("tokens should survive the rotation, got %+v, %v", account, err)
+	}
+}
+
+func newKeySpec(t *testing.T) string {
+	t.Helper()
+	_, specs := newTestKeyring(t, "new")
+	return specs[0]
+}
@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func (r *ReportRepository) initSessionTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS web_sessions (
+			id               TEXT PRIMARY KEY,
+			user_id          TEXT NOT NULL,
+			refresh_hash     TEXT NOT NULL,
+			ip               TEXT NOT NULL DEFAULT '',
+			user_agent       TEXT NOT NULL DEFAULT '',
+			created_at_utc   TEXT NOT NULL,
+			last_used_at_utc TEXT NOT NULL,
+			expires_at_utc   TEXT NOT NULL,
+			revoked_at_utc   TEXT NOT NULL DEFAULT ''
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_web_sessions_user
+			ON web_sessions (user_id, revoked_at_utc);
+	`
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE web_sessions ADD COLUMN previous_refresh_hash TEXT NOT NULL DEFAULT ''")
+	return nil
+}
+
+func (r *ReportRepository) CreateWebSession(ctx context.Context, session domain.WebSession) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO web_sessions (
+			id, user_id, refresh_hash, ip, user_agent,
+			created_at_utc, last_used_at_utc, expires_at_utc, revoked_at_utc
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		session.ID,
+		session.UserID,
+		session.RefreshHash,
+		session.IP,
+		session.UserAgent,
+		session.CreatedAt.UTC().Format(time.RFC3339),
+		session.LastUsedAt.UTC().Format(time.RFC3339),
+		session.ExpiresAt.UTC().Format(time.RFC3339),
+		formatOptionalTime(session.RevokedAt),
+	)
+	return err
+}
+
+func (r *ReportRepository) GetWebSession(ctx context.Context, id string) (*domain.WebSession, error) {
+	session, err := scanWebSession(r.db.QueryRowContext(ctx, webSessionSelect+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return session, err
+}
+
+func (r *ReportRepository) RotateWebSession(ctx context.Context, session domain.WebSession, previousHash string) (bool, error) {
+	lastUsedAt := session.LastUsedAt.UTC().Format(time.RFC3339)
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE web_sessions SET
+			refresh_hash = ?, previous_refresh_hash = ?, ip = ?, user_agent = ?,
+			last_used_at_utc = ?, expires_at_utc = ?
+		WHERE id = ? AND refresh_hash = ? AND revoked_at_utc = '' AND expires_at_utc > ?
+	`,
+		session.RefreshHash,
+		previousHash,
+		session.IP,
+		session.UserAgent,
+		lastUsedAt,
+		session.ExpiresAt.UTC().Format(time.RFC3339),
+		session.ID,
+		previousHash,
+		lastUsedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *ReportRepository) ListWebSessions(ctx context.Context, userID string, now time.Time) ([]domain.WebSession, error) {
+	rows, err := r.db.QueryContext(ctx, webSessionSelect+`
+		WHERE user_id = ? AND revoked_at_utc = '' AND expires_at_utc > ?
+		ORDER BY last_used_at_utc DESC, id
+	`, userID, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []domain.WebSession
+	for rows.Next() {
+		session, err := scanWebSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *ReportRepository) RevokeWebSession(ctx context.Context, userID, id string, at time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE web_sessions SET revoked_at_utc = ?
+		WHERE id = ? AND user_id = ? AND revoked_at_utc = ''
+	`, at.UTC().Format(time.RFC3339), id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *ReportRepository) RevokeWebSessions(ctx context.Context, userID string, at time.Time) (int, error) {
+	atStr := at.UTC().Format(time.RFC3339)
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE web_sessions SET revoked_at_utc = ?
+		WHERE user_id = ? AND revoked_at_utc = '' AND expires_at_utc > ?
+	`, atStr, userID, atStr)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+const webSessionSelect = `
+	SELECT id, user_id, refresh_hash, previous_refresh_hash, ip, user_agent,
+		created_at_utc, last_used_at_utc, expires_at_utc, revoked_at_utc
+	FROM web_sessions`
+
+func scanWebSession(row interface{ Scan(...any) error }) (*domain.WebSession, error) {
+	var session domain.WebSession
+	var createdAt, lastUsedAt, expiresAt, revokedAt string
+	if err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.RefreshHash,
+		&session.PreviousHash,
+		&session.IP,
+		&session.UserAgent,
+		&createdAt,
+		&lastUsedAt,
+		&expiresAt,
+		&revokedAt,
+	); err != nil {
+		return nil, err
+	}
+	session.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	session.LastUsedAt, _ = time.Parse(time.RFC3339, lastUsedAt)
+	session.ExpiresAt, _ = time.Parse(time.RFC3339, expiresAt)
+	session.RevokedAt, _ = time.Parse(time.RFC3339, revokedAt)
+	return &session, nil
+}
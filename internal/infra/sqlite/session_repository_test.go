@@ -0,0 +1,78 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestWebSession_RotateAndRevoke(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	for i, id := range []string{"s1", "s2"} {
+		err := repo.CreateWebSession(ctx, domain.WebSession{
+			ID:          id,
+			UserID:      "628111",
+			RefreshHash: "h-" + id,
+			UserAgent:   "browser " + id,
+			CreatedAt:   now,
+			LastUsedAt:  now.Add(time.Duration(i) * time.Minute),
+			ExpiresAt:   now.Add(24 * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("CreateWebSession: %v", err)
+		}
+	}
+
+	session, err := repo.GetWebSession(ctx, "s1")
+	if err != nil || session == nil {
+		t.Fatalf("GetWebSession = %v, %v", session, err)
+	}
+	rotated := *session
+	rotated.RefreshHash = "h-s1-2"
+	rotated.IP = "10.0.0.1"
+	rotated.LastUsedAt = now.Add(time.Hour)
+	rotated.ExpiresAt = now.Add(25 * time.Hour)
+	if ok, err := repo.RotateWebSession(ctx, rotated, "h-s1"); err != nil || !ok {
+		t.Fatalf("RotateWebSession = %v, %v", ok, err)
+	}
+	if ok, _ := repo.RotateWebSession(ctx, rotated, "h-s1"); ok {
+		t.Fatal("a refresh hash must only rotate once")
+	}
+
+	list, err := repo.ListWebSessions(ctx, "628111", now.Add(time.Hour))
+	if err != nil || len(list) != 2 {
+		t.Fatalf("ListWebSessions = %v, %v", list, err)
+	}
+	if list[0].ID != "s1" || list[0].RefreshHash != "h-s1-2" || list[0].PreviousHash != "h-s1" || list[0].IP != "10.0.0.1" || !list[0].ExpiresAt.Equal(now.Add(25*time.Hour)) {
+		t.Fatalf("most recently used session should come first, got %+v", list[0])
+	}
+
+	if ok, err := repo.RevokeWebSession(ctx, "628999", "s1", now); err != nil || ok {
+		t.Fatalf("another user must not revoke the session, got %v, %v", ok, err)
+	}
+	if ok, err := repo.RevokeWebSession(ctx, "628111", "s1", now.Add(2*time.Hour)); err != nil || !ok {
+		t.Fatalf("RevokeWebSession = %v, %v", ok, err)
+	}
+	if got, _ := repo.GetWebSession(ctx, "s1"); got == nil || !got.RevokedAt.Equal(now.Add(2*time.Hour)) {
+		t.Fatalf("revoked session should keep its revocation time, got %+v", got)
+	}
+	if ok, _ := repo.RotateWebSession(ctx, domain.WebSession{ID: "s1", RefreshHash: "h3", LastUsedAt: now, ExpiresAt: now.Add(time.Hour)}, "h-s1-2"); ok {
+		t.Fatal("a revoked session must not rotate")
+	}
+
+	if n, err := repo.RevokeWebSessions(ctx, "628111", now.Add(2*time.Hour)); err != nil || n != 1 {
+		t.Fatalf("RevokeWebSessions = %d, %v; want the one remaining session", n, err)
+	}
+	if list, _ := repo.ListWebSessions(ctx, "628111", now.Add(2*time.Hour)); len(list) != 0 {
+		t.Fatalf("no session should be left, got %+v", list)
+	}
+	if missing, err := repo.GetWebSession(ctx, "nope"); err != nil || missing != nil {
+		t.Fatalf("unknown session should be nil, got %v, %v", missing, err)
+	}
+}
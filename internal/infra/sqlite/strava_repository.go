@@ -0,0 +1,466 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func (r *ReportRepository) initStravaTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS strava_oauth_states (
+			nonce          TEXT PRIMARY KEY,
+			purpose        TEXT NOT NULL,
+			user_id        TEXT NOT NULL,
+			name           TEXT NOT NULL DEFAULT '',
+			created_at_utc TEXT NOT NULL,
+			expires_at_utc TEXT NOT NULL,
+			used_at_utc    TEXT NOT NULL DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_strava_oauth_states_expires ON strava_oauth_states (expires_at_utc);
+
+		CREATE TABLE IF NOT EXISTS strava_activities (
+			activity_id    INTEGER PRIMARY KEY,
+			user_id        TEXT NOT NULL,
+			athlete_id     INTEGER NOT NULL,
+			event_key      TEXT NOT NULL DEFAULT '',
+			activity_type  TEXT NOT NULL DEFAULT '',
+			activity_date  TEXT NOT NULL DEFAULT '',
+			status         TEXT NOT NULL,
+			created_at_utc TEXT NOT NULL,
+			updated_at_utc TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_strava_activities_user ON strava_activities (user_id, activity_date);
+
+		CREATE TABLE IF NOT EXISTS strava_webhook_inbox (
+			id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+			payload             TEXT NOT NULL,
+			status              TEXT NOT NULL,
+			attempts            INTEGER NOT NULL DEFAULT 0,
+			last_error          TEXT NOT NULL DEFAULT '',
+			received_at_utc     TEXT NOT NULL,
+			next_attempt_at_utc TEXT NOT NULL,
+			processed_at_utc    TEXT NOT NULL DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_strava_webhook_inbox_due ON strava_webhook_inbox (status, next_attempt_at_utc);
+
+		CREATE TABLE IF NOT EXISTS strava_preferences (
+			user_id        TEXT PRIMARY KEY,
+			activity_types TEXT NOT NULL DEFAULT '',
+			post_to_group  INTEGER NOT NULL DEFAULT 1,
+			updated_at_utc TEXT NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS strava_sync_state (
+			user_id             TEXT PRIMARY KEY,
+			last_webhook_at_utc TEXT NOT NULL DEFAULT '',
+			last_polled_at_utc  TEXT NOT NULL DEFAULT ''
+		);
+	`
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE strava_activities ADD COLUMN name TEXT NOT NULL DEFAULT ''")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE strava_activities ADD COLUMN start_date_local TEXT NOT NULL DEFAULT ''")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE strava_activities ADD COLUMN distance_meters INTEGER NOT NULL DEFAULT 0")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE strava_activities ADD COLUMN moving_seconds INTEGER NOT NULL DEFAULT 0")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE strava_activities ADD COLUMN elapsed_seconds INTEGER NOT NULL DEFAULT 0")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE strava_activities ADD COLUMN elevation_gain_meters REAL NOT NULL DEFAULT 0")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE strava_activities ADD COLUMN average_heartrate REAL NOT NULL DEFAULT 0")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE strava_activities ADD COLUMN max_heartrate REAL NOT NULL DEFAULT 0")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE strava_activities ADD COLUMN average_speed REAL NOT NULL DEFAULT 0")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE strava_activities ADD COLUMN calories INTEGER NOT NULL DEFAULT 0")
+	_, _ = r.db.ExecContext(ctx, "ALTER TABLE strava_activities ADD COLUMN device_name TEXT NOT NULL DEFAULT ''")
+	return nil
+}
+
+func (r *ReportRepository) CreateStravaOAuthState(ctx context.Context, state domain.StravaOAuthState) error {
+	// Expired states are useless, so creating a new one sweeps them out.
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM strava_oauth_states WHERE expires_at_utc < ?`,
+		state.CreatedAt.UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO strava_oauth_states (nonce, purpose, user_id, name, created_at_utc, expires_at_utc)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		state.Nonce,
+		state.Purpose,
+		state.UserID,
+		state.Name,
+		state.CreatedAt.UTC().Format(time.RFC3339),
+		state.ExpiresAt.UTC().Format(time.RFC3339),
+	)
+	return err
+}
+
+func (r *ReportRepository) ConsumeStravaOAuthState(ctx context.Context, nonce, purpose string, now time.Time) (*domain.StravaOAuthState, error) {
+	nowStr := now.UTC().Format(time.RFC3339)
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE strava_oauth_states SET used_at_utc = ?
+		WHERE nonce = ? AND purpose = ? AND used_at_utc = '' AND expires_at_utc > ?
+	`, nowStr, nonce, purpose, nowStr)
+	if err != nil {
+		return nil, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, nil
+	}
+
+	var state domain.StravaOAuthState
+	var createdAt, expiresAt string
+	err = r.db.QueryRowContext(ctx, `
+		SELECT nonce, purpose, user_id, name, created_at_utc, expires_at_utc
+		FROM strava_oauth_states WHERE nonce = ?
+	`, nonce).Scan(&state.Nonce, &state.Purpose, &state.UserID, &state.Name, &createdAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	state.ExpiresAt, _ = time.Parse(time.RFC3339, expiresAt)
+	return &state, nil
+}
+
+func (r *ReportRepository) ClaimStravaActivity(ctx context.Context, activity domain.StravaActivity) (bool, error) {
+	claimedAt := activity.UpdatedAt
+	if claimedAt.IsZero() {
+		claimedAt = activity.CreatedAt
+	}
+	staleBefore := claimedAt.Add(-domain.StravaClaimTimeout).UTC().Format(time.RFC3339)
+	// A pending row that outlived the timeout belongs to a run that died
+	// mid-way, so it is taken over as if it were new.
+	args := append(stravaActivityArgs(activity), domain.StravaActivityPending, staleBefore)
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO strava_activities (
+			activity_id, user_id, athlete_id, event_key, activity_type, activity_date,
+			status, created_at_utc, updated_at_utc, name, start_date_local,
+			distance_meters, moving_seconds, elapsed_seconds, elevation_gain_meters,
+			average_heartrate, max_heartrate, average_speed, calories, device_name
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(activity_id) DO UPDATE SET
+			user_id = excluded.user_id,
+			athlete_id = excluded.athlete_id,
+			event_key = excluded.event_key,
+			activity_type = excluded.activity_type,
+			activity_date = excluded.activity_date,
+			status = excluded.status,
+			created_at_utc = excluded.created_at_utc,
+			updated_at_utc = excluded.updated_at_utc,
+			name = excluded.name,
+			start_date_local = excluded.start_date_local,
+			distance_meters = excluded.distance_meters,
+			moving_seconds = excluded.moving_seconds,
+			elapsed_seconds = excluded.elapsed_seconds,
+			elevation_gain_meters = excluded.elevation_gain_meters,
+			average_heartrate = excluded.average_heartrate,
+			max_heartrate = excluded.max_heartrate,
+			average_speed = excluded.average_speed,
+			calories = excluded.calories,
+			device_name = excluded.device_name
+		WHERE strava_activities.status = ? AND strava_activities.updated_at_utc < ?
+	`, args...)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *ReportRepository) SaveStravaActivity(ctx context.Context, activity domain.StravaActivity) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO strava_activities (
+			activity_id, user_id, athlete_id, event_key, activity_type, activity_date,
+			status, created_at_utc, updated_at_utc, name, start_date_local,
+			distance_meters, moving_seconds, elapsed_seconds, elevation_gain_meters,
+			average_heartrate, max_heartrate, average_speed, calories, device_name
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(activity_id) DO UPDATE SET
+			event_key = excluded.event_key,
+			activity_type = excluded.activity_type,
+			activity_date = excluded.activity_date,
+			status = excluded.status,
+			updated_at_utc = excluded.updated_at_utc,
+			name = excluded.name,
+			start_date_local = excluded.start_date_local,
+			distance_meters = excluded.distance_meters,
+			moving_seconds = excluded.moving_seconds,
+			elapsed_seconds = excluded.elapsed_seconds,
+			elevation_gain_meters = excluded.elevation_gain_meters,
+			average_heartrate = excluded.average_heartrate,
+			max_heartrate = excluded.max_heartrate,
+			average_speed = excluded.average_speed,
+			calories = excluded.calories,
+			device_name = excluded.device_name
+	`, stravaActivityArgs(activity)...)
+	return err
+}
+
+func stravaActivityArgs(a domain.StravaActivity) []any {
+	activityDate := ""
+	if !a.ActivityDate.IsZero() {
+		activityDate = a.ActivityDate.Format(time.DateOnly)
+	}
+	updatedAt := a.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = a.CreatedAt
+	}
+	startDateLocal := ""
+	if !a.StartDateLocal.IsZero() {
+		startDateLocal = a.StartDateLocal.Format(time.RFC3339)
+	}
+	return []any{
+		a.ActivityID,
+		a.UserID,
+		a.AthleteID,
+		a.EventKey,
+		a.ActivityType,
+		activityDate,
+		a.Status,
+		a.CreatedAt.UTC().Format(time.RFC3339),
+		updatedAt.UTC().Format(time.RFC3339),
+		a.Name,
+		startDateLocal,
+		a.DistanceMeters,
+		a.MovingSeconds,
+		a.ElapsedSeconds,
+		a.ElevationGainMeters,
+		a.AverageHeartrate,
+		a.MaxHeartrate,
+		a.AverageSpeed,
+		a.Calories,
+		a.DeviceName,
+	}
+}
+
+func (r *ReportRepository) GetStravaActivity(ctx context.Context, activityID int64) (*domain.StravaActivity, error) {
+	var a domain.StravaActivity
+	var activityDate, createdAt, updatedAt, startDateLocal string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT activity_id, user_id, athlete_id, event_key, activity_type, activity_date,
+			status, created_at_utc, updated_at_utc, name, start_date_local,
+			distance_meters, moving_seconds, elapsed_seconds, elevation_gain_meters,
+			average_heartrate, max_heartrate, average_speed, calories, device_name
+		FROM strava_activities WHERE activity_id = ?
+	`, activityID).Scan(&a.ActivityID, &a.UserID, &a.AthleteID, &a.EventKey, &a.ActivityType, &activityDate,
+		&a.Status, &createdAt, &updatedAt, &a.Name, &startDateLocal,
+		&a.DistanceMeters, &a.MovingSeconds, &a.ElapsedSeconds, &a.ElevationGainMeters,
+		&a.AverageHeartrate, &a.MaxHeartrate, &a.AverageSpeed, &a.Calories, &a.DeviceName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if activityDate != "" {
+		a.ActivityDate, _ = time.Parse(time.DateOnly, activityDate)
+	}
+	if startDateLocal != "" {
+		a.StartDateLocal, _ = time.Parse(time.RFC3339, startDateLocal)
+	}
+	a.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	a.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return &a, nil
+}
+
+func (r *ReportRepository) DeleteStravaActivity(ctx context.Context, activityID int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM strava_activities WHERE activity_id = ?`, activityID)
+	return err
+}
+
+func (r *ReportRepository) DeleteStravaAccount(ctx context.Context, userID string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM strava_sync_state WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `DELETE FROM strava_accounts WHERE user_id = ?`, userID)
+	return err
+}
+
+func (r *ReportRepository) RecordStravaWebhookSeen(ctx context.Context, userID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO strava_sync_state (user_id, last_webhook_at_utc) VALUES (?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET last_webhook_at_utc = excluded.last_webhook_at_utc
+	`, userID, at.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (r *ReportRepository) RecordStravaPolled(ctx context.Context, userID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO strava_sync_state (user_id, last_polled_at_utc) VALUES (?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET last_polled_at_utc = excluded.last_polled_at_utc
+	`, userID, at.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (r *ReportRepository) ListQuietStravaAccounts(ctx context.Context, quietSince, polledBefore time.Time) ([]domain.StravaAccount, error) {
+	// RFC3339 UTC strings sort chronologically, and '' sorts before any time.
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT a.user_id, a.athlete_id, a.access_token, a.refresh_token, a.expires_at, COALESCE(a.name, '')
+		FROM strava_accounts a
+		LEFT JOIN strava_sync_state s ON s.user_id = a.user_id
+		WHERE COALESCE(s.last_webhook_at_utc, '') < ? AND COALESCE(s.last_polled_at_utc, '') < ?
+		ORDER BY a.user_id
+	`, quietSince.UTC().Format(time.RFC3339), polledBefore.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []domain.StravaAccount
+	for rows.Next() {
+		var account domain.StravaAccount
+		var expiresAt string
+		if err := rows.Scan(&account.UserID, &account.AthleteID, &account.AccessToken, &account.RefreshToken, &expiresAt, &account.Name); err != nil {
+			return nil, err
+		}
+		account.ExpiresAt, _ = time.Parse(time.RFC3339, expiresAt)
+		if err := r.openStravaTokens(&account); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *ReportRepository) GetStravaPreferences(ctx context.Context, userID string) (*domain.StravaPreferences, error) {
+	prefs := domain.StravaPreferences{UserID: userID, ActivityTypes: []string{}}
+	var activityTypes, updatedAt string
+	var postToGroup int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT activity_types, post_to_group, updated_at_utc FROM strava_preferences WHERE user_id = ?
+	`, userID).Scan(&activityTypes, &postToGroup, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if activityTypes != "" {
+		prefs.ActivityTypes = strings.Split(activityTypes, ",")
+	}
+	prefs.PostToGroup = postToGroup != 0
+	prefs.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return &prefs, nil
+}
+
+func (r *ReportRepository) SaveStravaPreferences(ctx context.Context, prefs domain.StravaPreferences) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO strava_preferences (user_id, activity_types, post_to_group, updated_at_utc)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET
+			activity_types = excluded.activity_types,
+			post_to_group = excluded.post_to_group,
+			updated_at_utc = excluded.updated_at_utc
+	`, prefs.UserID, strings.Join(prefs.ActivityTypes, ","), prefs.PostToGroup, prefs.UpdatedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (r *ReportRepository) EnqueueStravaWebhook(ctx context.Context, payload string, receivedAt time.Time) (int64, error) {
+	at := receivedAt.UTC().Format(time.RFC3339)
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO strava_webhook_inbox (payload, status, received_at_utc, next_attempt_at_utc)
+		VALUES (?, ?, ?, ?)
+	`, payload, domain.StravaWebhookPending, at, at)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+const stravaWebhookColumns = `id, payload, status, attempts, last_error, received_at_utc, next_attempt_at_utc, processed_at_utc`
+
+func (r *ReportRepository) GetDueStravaWebhooks(ctx context.Context, now time.Time, limit int) ([]domain.StravaWebhookDelivery, error) {
+	return r.queryStravaWebhooks(ctx, `
+		SELECT `+stravaWebhookColumns+`
+		FROM strava_webhook_inbox
+		WHERE status = ? AND next_attempt_at_utc <= ?
+		ORDER BY id ASC
+		LIMIT ?
+	`, domain.StravaWebhookPending, now.UTC().Format(time.RFC3339), limit)
+}
+
+func (r *ReportRepository) ListStravaWebhooks(ctx context.Context, status string, limit int) ([]domain.StravaWebhookDelivery, error) {
+	return r.queryStravaWebhooks(ctx, `
+		SELECT `+stravaWebhookColumns+`
+		FROM strava_webhook_inbox
+		WHERE ? = '' OR status = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, status, status, limit)
+}
+
+func (r *ReportRepository) queryStravaWebhooks(ctx context.Context, query string, args ...any) ([]domain.StravaWebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.StravaWebhookDelivery
+	for rows.Next() {
+		var d domain.StravaWebhookDelivery
+		var receivedAt, nextAttemptAt, processedAt string
+		if err := rows.Scan(&d.ID, &d.Payload, &d.Status, &d.Attempts, &d.LastError, &receivedAt, &nextAttemptAt, &processedAt); err != nil {
+			return nil, err
+		}
+		d.ReceivedAt, _ = time.Parse(time.RFC3339, receivedAt)
+		d.NextAttemptAt, _ = time.Parse(time.RFC3339, nextAttemptAt)
+		if processedAt != "" {
+			d.ProcessedAt, _ = time.Parse(time.RFC3339, processedAt)
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *ReportRepository) MarkStravaWebhookProcessed(ctx context.Context, id int64, processedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE strava_webhook_inbox
+		SET status = ?, attempts = attempts + 1, last_error = '', processed_at_utc = ?
+		WHERE id = ?
+	`, domain.StravaWebhookProcessed, processedAt.UTC().Format(time.RFC3339), id)
+	return err
+}
+
+func (r *ReportRepository) MarkStravaWebhookFailed(ctx context.Context, id int64, attempts int, lastError string, nextAttemptAt time.Time, dead bool) error {
+	status := domain.StravaWebhookPending
+	if dead {
+		status = domain.StravaWebhookDead
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE strava_webhook_inbox
+		SET status = ?, attempts = ?, last_error = ?, next_attempt_at_utc = ?
+		WHERE id = ?
+	`, status, attempts, lastError, nextAttemptAt.UTC().Format(time.RFC3339), id)
+	return err
+}
+
+func (r *ReportRepository) ReplayStravaWebhook(ctx context.Context, id int64, now time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE strava_webhook_inbox
+		SET status = ?, attempts = 0, next_attempt_at_utc = ?, processed_at_utc = ''
+		WHERE id = ?
+	`, domain.StravaWebhookPending, now.UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,277 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestStravaOAuthState_ConsumeOnce(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	err := repo.CreateStravaOAuthState(ctx, domain.StravaOAuthState{
+		Nonce:     "abc",
+		Purpose:   domain.StravaStateOAuth,
+		UserID:    "628111",
+		Name:      "Budi",
+		CreatedAt: now,
+		ExpiresAt: now.Add(10 * time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("CreateStravaOAuthState: %v", err)
+	}
+
+	if state, err := repo.ConsumeStravaOAuthState(ctx, "abc", domain.StravaStateLink, now); err != nil || state != nil {
+		t.Fatalf("wrong purpose must not consume, got %+v, %v", state, err)
+	}
+
+	state, err := repo.ConsumeStravaOAuthState(ctx, "abc", domain.StravaStateOAuth, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("ConsumeStravaOAuthState: %v", err)
+	}
+	if state == nil || state.UserID != "628111" || state.Name != "Budi" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	if again, err := repo.ConsumeStravaOAuthState(ctx, "abc", domain.StravaStateOAuth, now.Add(time.Minute)); err != nil || again != nil {
+		t.Fatalf("state must be single use, got %+v, %v", again, err)
+	}
+}
+
+func TestStravaOAuthState_Expired(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	err := repo.CreateStravaOAuthState(ctx, domain.StravaOAuthState{
+		Nonce:     "old",
+		Purpose:   domain.StravaStateLink,
+		UserID:    "628111",
+		CreatedAt: now,
+		ExpiresAt: now.Add(15 * time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("CreateStravaOAuthState: %v", err)
+	}
+
+	state, err := repo.ConsumeStravaOAuthState(ctx, "old", domain.StravaStateLink, now.Add(15*time.Minute))
+	if err != nil || state != nil {
+		t.Fatalf("expired state must not be consumed, got %+v, %v", state, err)
+	}
+}
+
+func TestStravaActivity_ClaimSaveAndUnlink(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	pending := domain.StravaActivity{ActivityID: 42, UserID: "628111", AthleteID: 7, Status: domain.StravaActivityPending, CreatedAt: now}
+	claimed, err := repo.ClaimStravaActivity(ctx, pending)
+	if err != nil || !claimed {
+		t.Fatalf("first claim should succeed, got %v, %v", claimed, err)
+	}
+	if claimed, err := repo.ClaimStravaActivity(ctx, pending); err != nil || claimed {
+		t.Fatalf("second claim must be rejected, got %v, %v", claimed, err)
+	}
+
+	reported := pending
+	reported.EventKey = "evt-1"
+	reported.ActivityType = "Run"
+	reported.ActivityDate = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	reported.Status = domain.StravaActivityReported
+	reported.UpdatedAt = now.Add(time.Minute)
+	reported.Name = "Morning Run"
+	reported.StartDateLocal = time.Date(2026, 10, 19, 6, 15, 0, 0, time.UTC)
+	reported.DistanceMeters = 5012
+	reported.MovingSeconds = 1650
+	reported.ElevationGainMeters = 42.5
+	reported.AverageHeartrate = 151.2
+	reported.DeviceName = "Garmin Forerunner 255"
+	if err := repo.SaveStravaActivity(ctx, reported); err != nil {
+		t.Fatalf("SaveStravaActivity: %v", err)
+	}
+
+	got, err := repo.GetStravaActivity(ctx, 42)
+	if err != nil || got == nil {
+		t.Fatalf("GetStravaActivity: %+v, %v", got, err)
+	}
+	if got.EventKey != "evt-1" || got.Status != domain.StravaActivityReported || !got.ActivityDate.Equal(reported.ActivityDate) || !got.CreatedAt.Equal(now) {
+		t.Fatalf("unexpected activity %+v", got)
+	}
+	if got.Name != "Morning Run" || got.DistanceMeters != 5012 || got.MovingSeconds != 1650 ||
+		got.ElevationGainMeters != 42.5 || got.AverageHeartrate != 151.2 || got.DeviceName != "Garmin Forerunner 255" ||
+		!got.StartDateLocal.Equal(reported.StartDateLocal) {
+		t.Fatalf("metadata not round-tripped: %+v", got)
+	}
+
+	if missing, err := repo.GetStravaActivity(ctx, 43); err != nil || missing != nil {
+		t.Fatalf("unknown activity should be nil, got %+v, %v", missing, err)
+	}
+
+	if err := repo.UpsertStravaAccount(ctx, &domain.StravaAccount{UserID: "628111", AthleteID: 7, ExpiresAt: now}); err != nil {
+		t.Fatalf("UpsertStravaAccount: %v", err)
+	}
+	if err := repo.DeleteStravaAccount(ctx, "628111"); err != nil {
+		t.Fatalf("DeleteStravaAccount: %v", err)
+	}
+	if acc, err := repo.GetStravaAccountByAthleteID(ctx, 7); err != nil || acc != nil {
+		t.Fatalf("account should be unlinked, got %+v, %v", acc, err)
+	}
+	if kept, _ := repo.GetStravaActivity(ctx, 42); kept == nil {
+		t.Fatal("activity mapping should survive unlinking")
+	}
+}
+
+func TestStravaWebhookInbox_Lifecycle(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	first, err := repo.EnqueueStravaWebhook(ctx, `{"object_id":1}`, now)
+	if err != nil {
+		t.Fatalf("EnqueueStravaWebhook: %v", err)
+	}
+	second, err := repo.EnqueueStravaWebhook(ctx, `{"object_id":2}`, now.Add(time.Second))
+	if err != nil {
+		t.Fatalf("EnqueueStravaWebhook: %v", err)
+	}
+
+	due, err := repo.GetDueStravaWebhooks(ctx, now.Add(time.Minute), 10)
+	if err != nil || len(due) != 2 || due[0].ID != first {
+		t.Fatalf("expected both deliveries oldest first, got %+v, %v", due, err)
+	}
+
+	if err := repo.MarkStravaWebhookProcessed(ctx, first, now.Add(time.Minute)); err != nil {
+		t.Fatalf("MarkStravaWebhookProcessed: %v", err)
+	}
+	if err := repo.MarkStravaWebhookFailed(ctx, second, 8, "boom", now.Add(time.Hour), true); err != nil {
+		t.Fatalf("MarkStravaWebhookFailed: %v", err)
+	}
+	if due, _ := repo.GetDueStravaWebhooks(ctx, now.Add(2*time.Hour), 10); len(due) != 0 {
+		t.Fatalf("processed and dead deliveries must not be due, got %+v", due)
+	}
+
+	dead, err := repo.ListStravaWebhooks(ctx, domain.StravaWebhookDead, 10)
+	if err != nil || len(dead) != 1 || dead[0].LastError != "boom" || dead[0].Attempts != 8 {
+		t.Fatalf("unexpected dead letters %+v, %v", dead, err)
+	}
+	if all, _ := repo.ListStravaWebhooks(ctx, "", 10); len(all) != 2 || all[0].ID != second {
+		t.Fatalf("expected all deliveries newest first, got %+v", all)
+	}
+
+	replayAt := now.Add(3 * time.Hour)
+	if ok, err := repo.ReplayStravaWebhook(ctx, second, replayAt); err != nil || !ok {
+		t.Fatalf("ReplayStravaWebhook: %v, %v", ok, err)
+	}
+	due, _ = repo.GetDueStravaWebhooks(ctx, replayAt, 10)
+	if len(due) != 1 || due[0].ID != second || due[0].Attempts != 0 {
+		t.Fatalf("replayed delivery should be due with a fresh budget, got %+v", due)
+	}
+	if ok, _ := repo.ReplayStravaWebhook(ctx, 999, replayAt); ok {
+		t.Fatal("unknown delivery should not be replayed")
+	}
+}
+
+func TestStravaSync_ListQuietAccounts(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	for i, userID := range []string{"628111", "628222", "628333"} {
+		if err := repo.UpsertStravaAccount(ctx, &domain.StravaAccount{UserID: userID, AthleteID: int64(i + 1), ExpiresAt: now}); err != nil {
+			t.Fatalf("UpsertStravaAccount: %v", err)
+		}
+	}
+	// 628111 has a recent webhook; 628222 was polled recently; 628333 is quiet.
+	if err := repo.RecordStravaWebhookSeen(ctx, "628111", now.Add(-time.Hour)); err != nil {
+		t.Fatalf("RecordStravaWebhookSeen: %v", err)
+	}
+	if err := repo.RecordStravaPolled(ctx, "628222", now.Add(-30*time.Minute)); err != nil {
+		t.Fatalf("RecordStravaPolled: %v", err)
+	}
+
+	quiet, err := repo.ListQuietStravaAccounts(ctx, now.Add(-6*time.Hour), now.Add(-2*time.Hour))
+	if err != nil {
+		t.Fatalf("ListQuietStravaAccounts: %v", err)
+	}
+	if len(quiet) != 1 || quiet[0].UserID != "628333" || !quiet[0].ExpiresAt.Equal(now) {
+		t.Fatalf("expected only 628333 to be quiet, got %+v", quiet)
+	}
+}
+
+func TestStravaPreferences_RoundTrip(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	if prefs, err := repo.GetStravaPreferences(ctx, "628111"); err != nil || prefs != nil {
+		t.Fatalf("unset preferences should be nil, got %+v, %v", prefs, err)
+	}
+
+	want := domain.StravaPreferences{UserID: "628111", ActivityTypes: []string{"Ride", "Run"}, PostToGroup: false, UpdatedAt: now}
+	if err := repo.SaveStravaPreferences(ctx, want); err != nil {
+		t.Fatalf("SaveStravaPreferences: %v", err)
+	}
+	got, err := repo.GetStravaPreferences(ctx, "628111")
+	if err != nil || got == nil {
+		t.Fatalf("GetStravaPreferences: %+v, %v", got, err)
+	}
+	if got.PostToGroup || len(got.ActivityTypes) != 2 || got.ActivityTypes[1] != "Run" || !got.UpdatedAt.Equal(now) {
+		t.Fatalf("unexpected preferences %+v", got)
+	}
+
+	want.ActivityTypes = []string{}
+	want.PostToGroup = true
+	if err := repo.SaveStravaPreferences(ctx, want); err != nil {
+		t.Fatalf("SaveStravaPreferences: %v", err)
+	}
+	got, _ = repo.GetStravaPreferences(ctx, "628111")
+	if !got.PostToGroup || len(got.ActivityTypes) != 0 {
+		t.Fatalf("expected all types posted to group, got %+v", got)
+	}
+}
+
+func TestStravaActivity_StalePendingClaimIsTakenOver(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	// The first run claims the activity and dies before recording it.
+	pending := domain.StravaActivity{ActivityID: 42, UserID: "628111", AthleteID: 7, Status: domain.StravaActivityPending, CreatedAt: now}
+	if claimed, err := repo.ClaimStravaActivity(ctx, pending); err != nil || !claimed {
+		t.Fatalf("first claim should succeed, got %v, %v", claimed, err)
+	}
+
+	retry := pending
+	retry.CreatedAt = now.Add(time.Minute)
+	if claimed, err := repo.ClaimStravaActivity(ctx, retry); err != nil || claimed {
+		t.Fatalf("a fresh claim must hold, got %v, %v", claimed, err)
+	}
+
+	retry.CreatedAt = now.Add(domain.StravaClaimTimeout + time.Minute)
+	if claimed, err := repo.ClaimStravaActivity(ctx, retry); err != nil || !claimed {
+		t.Fatalf("a stale claim should be taken over, got %v, %v", claimed, err)
+	}
+
+	reported := retry
+	reported.Status = domain.StravaActivityReported
+	reported.EventKey = "evt-1"
+	if err := repo.SaveStravaActivity(ctx, reported); err != nil {
+		t.Fatalf("SaveStravaActivity: %v", err)
+	}
+	retry.CreatedAt = now.Add(24 * time.Hour)
+	if claimed, err := repo.ClaimStravaActivity(ctx, retry); err != nil || claimed {
+		t.Fatalf("a reported activity must never be reclaimed, got %v, %v", claimed, err)
+	}
+}
@@ -0,0 +1,257 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func (r *ReportRepository) initWebhookTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+			id             TEXT PRIMARY KEY,
+			url            TEXT NOT NULL,
+			secret         TEXT NOT NULL,
+			events         TEXT NOT NULL DEFAULT '',
+			enabled        INTEGER NOT NULL DEFAULT 1,
+			created_at_utc TEXT NOT NULL,
+			updated_at_utc TEXT NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+			subscription_id     TEXT NOT NULL,
+			event_id            TEXT NOT NULL,
+			event_type          TEXT NOT NULL,
+			payload             TEXT NOT NULL,
+			status              TEXT NOT NULL,
+			attempts            INTEGER NOT NULL DEFAULT 0,
+			last_status_code    INTEGER NOT NULL DEFAULT 0,
+			last_error          TEXT NOT NULL DEFAULT '',
+			created_at_utc      TEXT NOT NULL,
+			next_attempt_at_utc TEXT NOT NULL,
+			delivered_at_utc    TEXT NOT NULL DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at_utc);
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries (subscription_id, id);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+func (r *ReportRepository) CreateWebhookSubscription(ctx context.Context, sub domain.WebhookSubscription) error {
+	secret, err := r.sealSecret(sub.Secret, secretAAD("webhook_subscriptions", "secret", sub.ID))
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO webhook_subscriptions (id, url, secret, events, enabled, created_at_utc, updated_at_utc)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`,
+		sub.ID,
+		sub.URL,
+		secret,
+		strings.Join(sub.Events, ","),
+		sub.Enabled,
+		sub.CreatedAt.UTC().Format(time.RFC3339),
+		sub.UpdatedAt.UTC().Format(time.RFC3339),
+	)
+	return err
+}
+
+func (r *ReportRepository) UpdateWebhookSubscription(ctx context.Context, sub domain.WebhookSubscription) error {
+	secret, err := r.sealSecret(sub.Secret, secretAAD("webhook_subscriptions", "secret", sub.ID))
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE webhook_subscriptions SET
+			url = ?, secret = ?, events = ?, enabled = ?, updated_at_utc = ?
+		WHERE id = ?
+	`,
+		sub.URL,
+		secret,
+		strings.Join(sub.Events, ","),
+		sub.Enabled,
+		sub.UpdatedAt.UTC().Format(time.RFC3339),
+		sub.ID,
+	)
+	return err
+}
+
+func (r *ReportRepository) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE subscription_id = ?`, id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = ?`, id); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *ReportRepository) GetWebhookSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	row := r.db.QueryRowContext(ctx, webhookSubscriptionSelect+` WHERE id = ?`, id)
+	sub, err := r.scanWebhookSubscription(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return sub, err
+}
+
+func (r *ReportRepository) ListWebhookSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, webhookSubscriptionSelect+` ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []domain.WebhookSubscription
+	for rows.Next() {
+		sub, err := r.scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+	return subs, rows.Err()
+}
+
+const webhookSubscriptionSelect = `
+	SELECT id, url, secret, events, enabled, created_at_utc, updated_at_utc
+	FROM webhook_subscriptions`
+
+func (r *ReportRepository) scanWebhookSubscription(row interface{ Scan(...any) error }) (*domain.WebhookSubscription, error) {
+	var sub domain.WebhookSubscription
+	var events, createdAt, updatedAt string
+	if err := row.Scan(&sub.ID, &sub.URL, &sub.Secret, &events, &sub.Enabled, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if sub.Secret, err = r.openSecret(sub.Secret, secretAAD("webhook_subscriptions", "secret", sub.ID)); err != nil {
+		return nil, err
+	}
+	sub.Events = []string{}
+	if events != "" {
+		sub.Events = strings.Split(events, ",")
+	}
+	sub.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	sub.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return &sub, nil
+}
+
+func (r *ReportRepository) EnqueueWebhookDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, d := range deliveries {
+		at := d.CreatedAt.UTC().Format(time.RFC3339)
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO webhook_deliveries (
+				subscription_id, event_id, event_type, payload, status, created_at_utc, next_attempt_at_utc
+			) VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, d.SubscriptionID, d.EventID, d.EventType, d.Payload, domain.WebhookDeliveryPending, at, at); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts,
+	last_status_code, last_error, created_at_utc, next_attempt_at_utc, delivered_at_utc`
+
+func (r *ReportRepository) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	return r.queryWebhookDeliveries(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at_utc <= ?
+		ORDER BY id ASC
+		LIMIT ?
+	`, domain.WebhookDeliveryPending, now.UTC().Format(time.RFC3339), limit)
+}
+
+func (r *ReportRepository) ListWebhookDeliveries(ctx context.Context, subscriptionID, status string, limit int) ([]domain.WebhookDelivery, error) {
+	return r.queryWebhookDeliveries(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE (? = '' OR subscription_id = ?) AND (? = '' OR status = ?)
+		ORDER BY id DESC
+		LIMIT ?
+	`, subscriptionID, subscriptionID, status, status, limit)
+}
+
+func (r *ReportRepository) queryWebhookDeliveries(ctx context.Context, query string, args ...any) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.WebhookDelivery
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		var createdAt, nextAttemptAt, deliveredAt string
+		if err := rows.Scan(
+			&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.LastStatusCode, &d.LastError, &createdAt, &nextAttemptAt, &deliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		d.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		d.NextAttemptAt, _ = time.Parse(time.RFC3339, nextAttemptAt)
+		if deliveredAt != "" {
+			d.DeliveredAt, _ = time.Parse(time.RFC3339, deliveredAt)
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *ReportRepository) MarkWebhookDelivered(ctx context.Context, id int64, statusCode int, deliveredAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = attempts + 1, last_status_code = ?, last_error = '', delivered_at_utc = ?
+		WHERE id = ?
+	`, domain.WebhookDeliveryDelivered, statusCode, deliveredAt.UTC().Format(time.RFC3339), id)
+	return err
+}
+
+func (r *ReportRepository) MarkWebhookFailed(ctx context.Context, id int64, attempts, statusCode int, lastError string, nextAttemptAt time.Time, dead bool) error {
+	status := domain.WebhookDeliveryPending
+	if dead {
+		status = domain.WebhookDeliveryDead
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, last_status_code = ?, last_error = ?, next_attempt_at_utc = ?
+		WHERE id = ?
+	`, status, attempts, statusCode, lastError, nextAttemptAt.UTC().Format(time.RFC3339), id)
+	return err
+}
+
+func (r *ReportRepository) ReplayWebhookDelivery(ctx context.Context, id int64, now time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = 0, next_attempt_at_utc = ?, delivered_at_utc = ''
+		WHERE id = ?
+	`, domain.WebhookDeliveryPending, now.UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,130 @@
+package sqlite_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestWebhookSubscription_RoundTrip(t *testing.T) {
+	db, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	keyring, _ := newTestKeyring(t, "k1")
+	repo.SetSecretKeyring(keyring)
+
+	sub := domain.WebhookSubscription{
+		ID:        "slack-mirror",
+		URL:       "https://example.com/hook",
+		Secret:    "whsec",
+		Events:    []string{domain.BotEventLevelUp, domain.BotEventRankUp},
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.CreateWebhookSubscription(ctx, sub); err != nil {
+		t.Fatalf("CreateWebhookSubscription: %v", err)
+	}
+	var stored string
+	if err := db.QueryRow(`SELECT secret FROM webhook_subscriptions WHERE id = ?`, sub.ID).Scan(&stored); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if !strings.HasPrefix(stored, "enc:v2:k1:") {
+		t.Fatalf("secret should be stored encrypted, got %q", stored)
+	}
+
+	sub.Events = nil
+	sub.Enabled = false
+	sub.UpdatedAt = now.Add(time.Minute)
+	if err := repo.UpdateWebhookSubscription(ctx, sub); err != nil {
+		t.Fatalf("UpdateWebhookSubscription: %v", err)
+	}
+	got, err := repo.GetWebhookSubscription(ctx, sub.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetWebhookSubscription = %v, %v", got, err)
+	}
+	if got.Secret != "whsec" || got.Enabled || len(got.Events) != 0 || !got.UpdatedAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected subscription: %+v", got)
+	}
+	if missing, err := repo.GetWebhookSubscription(ctx, "nope"); err != nil || missing != nil {
+		t.Fatalf("unknown subscription should be nil, got %v, %v", missing, err)
+	}
+	if list, err := repo.ListWebhookSubscriptions(ctx); err != nil || len(list) != 1 {
+		t.Fatalf("ListWebhookSubscriptions = %v, %v", list, err)
+	}
+}
+
+func TestWebhookDeliveries_QueueLifecycle(t *testing.T) {
+	_, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	for _, id := range []string{"a", "b"} {
+		if err := repo.CreateWebhookSubscription(ctx, domain.WebhookSubscription{ID: id, URL: "https://example.com/" + id, Secret: "s", Enabled: true, CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("CreateWebhookSubscription: %v", err)
+		}
+	}
+	err := repo.EnqueueWebhookDeliveries(ctx, []domain.WebhookDelivery{
+		{SubscriptionID: "a", EventID: "evt_1", EventType: domain.BotEventGoalCompleted, Payload: `{"id":"evt_1"}`, CreatedAt: now},
+		{SubscriptionID: "b", EventID: "evt_1", EventType: domain.BotEventGoalCompleted, Payload: `{"id":"evt_1"}`, CreatedAt: now},
+	})
+	if err != nil {
+		t.Fatalf("EnqueueWebhookDeliveries: %v", err)
+	}
+
+	due, err := repo.GetDueWebhookDeliveries(ctx, now, 10)
+	if err != nil || len(due) != 2 {
+		t.Fatalf("GetDueWebhookDeliveries = %v, %v", due, err)
+	}
+	if due[0].SubscriptionID != "a" || due[0].Status != domain.WebhookDeliveryPending || !due[0].NextAttemptAt.Equal(now) {
+		t.Fatalf("unexpected due delivery: %+v", due[0])
+	}
+
+	if err := repo.MarkWebhookDelivered(ctx, due[0].ID, 204, now); err != nil {
+		t.Fatalf("MarkWebhookDelivered: %v", err)
+	}
+	if err := repo.MarkWebhookFailed(ctx, due[1].ID, 1, 500, "boom", now.Add(time.Minute), false); err != nil {
+		t.Fatalf("MarkWebhookFailed: %v", err)
+	}
+	if due, _ := repo.GetDueWebhookDeliveries(ctx, now, 10); len(due) != 0 {
+		t.Fatalf("nothing should be due before the backoff, got %+v", due)
+	}
+	if due, _ := repo.GetDueWebhookDeliveries(ctx, now.Add(time.Minute), 10); len(due) != 1 || due[0].Attempts != 1 || due[0].LastStatusCode != 500 || due[0].LastError != "boom" {
+		t.Fatalf("failed delivery should come back after its backoff, got %+v", due)
+	}
+
+	if err := repo.MarkWebhookFailed(ctx, due[1].ID, 2, 0, "timeout", now.Add(time.Hour), true); err != nil {
+		t.Fatalf("MarkWebhookFailed dead: %v", err)
+	}
+	dead, err := repo.ListWebhookDeliveries(ctx, "", domain.WebhookDeliveryDead, 10)
+	if err != nil || len(dead) != 1 || dead[0].SubscriptionID != "b" {
+		t.Fatalf("ListWebhookDeliveries dead = %v, %v", dead, err)
+	}
+	delivered, err := repo.ListWebhookDeliveries(ctx, "a", "", 10)
+	if err != nil || len(delivered) != 1 || delivered[0].Status != domain.WebhookDeliveryDelivered || delivered[0].Attempts != 1 || !delivered[0].DeliveredAt.Equal(now) {
+		t.Fatalf("ListWebhookDeliveries a = %v, %v", delivered, err)
+	}
+
+	if ok, err := repo.ReplayWebhookDelivery(ctx, dead[0].ID, now.Add(2*time.Hour)); err != nil || !ok {
+		t.Fatalf("ReplayWebhookDelivery = %v, %v", ok, err)
+	}
+	if ok, _ := repo.ReplayWebhookDelivery(ctx, 999, now); ok {
+		t.Fatal("replaying an unknown delivery should report false")
+	}
+	if due, _ := repo.GetDueWebhookDeliveries(ctx, now.Add(2*time.Hour), 10); len(due) != 1 || due[0].Attempts != 0 {
+		t.Fatalf("replayed delivery should be due with a fresh budget, got %+v", due)
+	}
+
+	if err := repo.DeleteWebhookSubscription(ctx, "b"); err != nil {
+		t.Fatalf("DeleteWebhookSubscription: %v", err)
+	}
+	if all, _ := repo.ListWebhookDeliveries(ctx, "", "", 10); len(all) != 1 || all[0].SubscriptionID != "a" {
+		t.Fatalf("deleting a subscription should drop its deliveries, got %+v", all)
+	}
+}
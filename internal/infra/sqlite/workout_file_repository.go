@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func (r *ReportRepository) initWorkoutFileTables(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS workout_file_imports (
+			evidence_hash         TEXT PRIMARY KEY,
+			user_id               TEXT NOT NULL,
+			file_name             TEXT NOT NULL DEFAULT '',
+			format                TEXT NOT NULL,
+			activity_type         TEXT NOT NULL DEFAULT '',
+			activity_date         TEXT NOT NULL,
+			start_time_utc        TEXT NOT NULL,
+			distance_meters       INTEGER NOT NULL DEFAULT 0,
+			duration_seconds      INTEGER NOT NULL DEFAULT 0,
+			elevation_gain_meters REAL NOT NULL DEFAULT 0,
+			event_key             TEXT NOT NULL DEFAULT '',
+			created_at_utc        TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_workout_file_imports_user ON workout_file_imports (user_id, activity_date);
+	`
+	_, err := r.db.ExecContext(ctx, query)
+	return err
+}
+
+func (r *ReportRepository) ClaimWorkoutFile(ctx context.Context, file domain.WorkoutFileImport) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO workout_file_imports (
+			evidence_hash, user_id, file_name, format, activity_type, activity_date,
+			start_time_utc, distance_meters, duration_seconds, elevation_gain_meters,
+			event_key, created_at_utc
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		file.EvidenceHash,
+		file.UserID,
+		file.FileName,
+		file.Format,
+		file.ActivityType,
+		file.ActivityDate.Format(time.DateOnly),
+		file.StartTime.UTC().Format(time.RFC3339),
+		file.DistanceMeters,
+		file.DurationSeconds,
+		file.ElevationGainMeters,
+		file.EventKey,
+		file.CreatedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *ReportRepository) CompleteWorkoutFile(ctx context.Context, evidenceHash, eventKey string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE workout_file_imports SET event_key = ? WHERE evidence_hash = ?`, eventKey, evidenceHash)
+	return err
+}
+
+func (r *ReportRepository) ReleaseWorkoutFile(ctx context.Context, evidenceHash string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM workout_file_imports WHERE evidence_hash = ?`, evidenceHash)
+	return err
+}
@@ -0,0 +1,54 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestWorkoutFile_ClaimCompleteRelease(t *testing.T) {
+	db, repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	file := domain.WorkoutFileImport{
+		EvidenceHash:    "abc123",
+		UserID:          "628111",
+		FileName:        "run.fit",
+		Format:          "fit",
+		ActivityType:    domain.ActivityTypeRun,
+		ActivityDate:    time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		StartTime:       time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC),
+		DistanceMeters:  5000,
+		DurationSeconds: 1800,
+		CreatedAt:       time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC),
+	}
+	claimed, err := repo.ClaimWorkoutFile(ctx, file)
+	if err != nil || !claimed {
+		t.Fatalf("first claim = %v, %v", claimed, err)
+	}
+	file.UserID = "628222"
+	if claimed, err := repo.ClaimWorkoutFile(ctx, file); err != nil || claimed {
+		t.Fatalf("the same evidence must not be claimed twice, got %v, %v", claimed, err)
+	}
+
+	if err := repo.CompleteWorkoutFile(ctx, "abc123", "event-1"); err != nil {
+		t.Fatalf("CompleteWorkoutFile: %v", err)
+	}
+	var userID, eventKey string
+	if err := db.QueryRow(`SELECT user_id, event_key FROM workout_file_imports WHERE evidence_hash = ?`, "abc123").Scan(&userID, &eventKey); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if userID != "628111" || eventKey != "event-1" {
+		t.Fatalf("unexpected row: user=%s event=%s", userID, eventKey)
+	}
+
+	if err := repo.ReleaseWorkoutFile(ctx, "abc123"); err != nil {
+		t.Fatalf("ReleaseWorkoutFile: %v", err)
+	}
+	if claimed, err := repo.ClaimWorkoutFile(ctx, file); err != nil || !claimed {
+		t.Fatalf("a released file can be claimed again, got %v, %v", claimed, err)
+	}
+}
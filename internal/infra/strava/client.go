@@ -4,24 +4,37 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/fardannozami/whatsapp-gateway/internal/config"
 	"github.com/fardannozami/whatsapp-gateway/internal/domain"
 )
 
+// DefaultBaseURL is Strava's production host. Tests point the client at a
+// local stub server instead via config.StravaBaseURL.
+const DefaultBaseURL = "https://www.strava.com"
+
 type Client struct {
 	clientID     string
 	clientSecret string
+	baseURL      string
 	httpClient   *http.Client
 }
 
 func NewClient(cfg config.Config) *Client {
+	baseURL := strings.TrimRight(cfg.StravaBaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
 	return &Client{
 		clientID:     cfg.StravaClientID,
 		clientSecret: cfg.StravaClientSecret,
+		baseURL:      baseURL,
 		httpClient:   &http.Client{Timeout: 10 * time.Second},
 	}
 }
@@ -42,7 +55,7 @@ func (c *Client) ExchangeToken(code string) (*domain.StravaAccount, error) {
 	data.Set("code", code)
 	data.Set("grant_type", "authorization_code")
 
-	resp, err := c.httpClient.PostForm("https://www.strava.com/oauth/token", data)
+	resp, err := c.httpClient.PostForm(c.baseURL+"/oauth/token", data)
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +86,7 @@ func (c *Client) RefreshToken(refreshToken string) (*domain.StravaAccount, error
 	data.Set("refresh_token", refreshToken)
 	data.Set("grant_type", "refresh_token")
 
-	resp, err := c.httpClient.PostForm("https://www.strava.com/oauth/token", data)
+	resp, err := c.httpClient.PostForm(c.baseURL+"/oauth/token", data)
 	if err != nil {
 		return nil, err
 	}
@@ -96,17 +109,120 @@ func (c *Client) RefreshToken(refreshToken string) (*domain.StravaAccount, error
 	}, nil
 }
 
+// Deauthorize revokes the app's access to the athlete's account. Strava
+// invalidates every token issued for it.
+func (c *Client) Deauthorize(accessToken string) error {
+	data := url.Values{}
+	data.Set("access_token", accessToken)
+
+	resp, err := c.httpClient.PostForm(c.baseURL+"/oauth/deauthorize", data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("strava deauthorize error: %s", string(body))
+	}
+	return nil
+}
+
 type Activity struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Distance    float64   `json:"distance"` // meters
-	Type        string    `json:"type"`
-	StartDate   time.Time `json:"start_date"`
+	ID                 int64     `json:"id"`
+	Name               string    `json:"name"`
+	Description        string    `json:"description"`
+	Distance           float64   `json:"distance"` // meters
+	Type               string    `json:"type"`
+	SportType          string    `json:"sport_type"`
+	StartDate          time.Time `json:"start_date"`
+	StartDateLocal     time.Time `json:"start_date_local"` // local wall time, encoded as UTC
+	Timezone           string    `json:"timezone"`
+	MovingTime         int       `json:"moving_time"`  // seconds
+	ElapsedTime        int       `json:"elapsed_time"` // seconds
+	TotalElevationGain float64   `json:"total_elevation_gain"`
+	HasHeartrate       bool      `json:"has_heartrate"`
+	AverageHeartrate   float64   `json:"average_heartrate"`
+	MaxHeartrate       float64   `json:"max_heartrate"`
+	AverageSpeed       float64   `json:"average_speed"` // meters per second
+	MaxSpeed           float64   `json:"max_speed"`
+	Calories           float64   `json:"calories"`
+	DeviceName         string    `json:"device_name"`
+}
+
+// stravaActivityTypes maps Strava activity types to the bot's activity types.
+var stravaActivityTypes = map[string]string{
+	"Run":                           domain.ActivityTypeRun,
+	"VirtualRun":                    domain.ActivityTypeRun,
+	"TrailRun":                      domain.ActivityTypeRun,
+	"Ride":                          domain.ActivityTypeRide,
+	"VirtualRide":                   domain.ActivityTypeRide,
+	"MountainBikeRide":              domain.ActivityTypeRide,
+	"GravelRide":                    domain.ActivityTypeRide,
+	"EBikeRide":                     domain.ActivityTypeRide,
+	"Walk":                          domain.ActivityTypeWalk,
+	"Hike":                          domain.ActivityTypeHike,
+	"Swim":                          domain.ActivityTypeSwim,
+	"WeightTraining":                domain.ActivityTypeStrength,
+	"Crossfit":                      domain.ActivityTypeHIIT,
+	"HighIntensityIntervalTraining": domain.ActivityTypeHIIT,
+	"Yoga":                          domain.ActivityTypeYoga,
+	"Pilates":                       domain.ActivityTypeYoga,
+}
+
+// LocalDate returns the calendar day the activity started in the athlete's
+// own timezone, as a UTC midnight like domain.GetToday.
+func (a *Activity) LocalDate() time.Time {
+	start := a.StartDateLocal
+	if start.IsZero() {
+		start = a.StartDate
+	}
+	if start.IsZero() {
+		return time.Time{}
+	}
+	y, m, d := start.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Duration returns the moving time in seconds, or the elapsed time for
+// activities without one (e.g. manual entries).
+func (a *Activity) Duration() int {
+	if a.MovingTime > 0 {
+		return a.MovingTime
+	}
+	return a.ElapsedTime
+}
+
+// Workout converts the activity into the workout the report flow stores, so
+// its distance and duration feed metrics, goals and personal records the
+// same way a pasted share text does.
+func (a *Activity) Workout() *domain.Workout {
+	activityType := stravaActivityTypes[a.SportType]
+	if activityType == "" {
+		activityType = stravaActivityTypes[a.Type]
+	}
+	w := &domain.Workout{
+		Source:          domain.WorkoutSourceStrava,
+		Title:           a.Name,
+		ActivityType:    activityType,
+		DistanceMeters:  int(math.Round(a.Distance)),
+		DurationSeconds: a.Duration(),
+		Calories:        int(math.Round(a.Calories)),
+	}
+	if a.Distance > 0 {
+		// Descriptions of distance activities are chatter, not a workout list.
+		return w
+	}
+	for _, line := range strings.Split(a.Description, "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			w.Exercises = append(w.Exercises, trimmed)
+		}
+	}
+	return w
 }
 
 func (c *Client) GetActivity(accessToken string, activityID int64) (*Activity, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://www.strava.com/api/v3/activities/%d", activityID), nil)
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v3/activities/%d", c.baseURL, activityID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -131,3 +247,39 @@ func (c *Client) GetActivity(accessToken string, activityID int64) (*Activity, e
 
 	return &activity, nil
 }
+
+// ListActivities returns one page of the athlete's activities that started
+// between after and before. Summary activities carry no
+// description or calories.
+func (c *Client) ListActivities(accessToken string, after, before time.Time, page, perPage int) ([]Activity, error) {
+	query := url.Values{}
+	query.Set("after", strconv.FormatInt(after.Unix(), 10))
+	if !before.IsZero() {
+		query.Set("before", strconv.FormatInt(before.Unix(), 10))
+	}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	req, err := http.NewRequest("GET", c.baseURL+"/api/v3/athlete/activities?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("strava list activities error: %s", string(body))
+	}
+
+	var activities []Activity
+	if err := json.NewDecoder(resp.Body).Decode(&activities); err != nil {
+		return nil, err
+	}
+	return activities, nil
+}
@@ -0,0 +1,87 @@
+package strava
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/config"
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+func TestActivityWorkoutAndLocalDate(t *testing.T) {
+	// A run started 23:30 on the 18th in Jakarta is already the 18th at
+	// 16:30 UTC; the report must use the athlete's local day.
+	payload := `{
+		"id": 99, "name": "Night Run", "type": "Run", "sport_type": "TrailRun",
+		"distance": 5012.4, "moving_time": 1650, "elapsed_time": 1800,
+		"start_date": "2026-10-18T16:30:00Z", "start_date_local": "2026-10-18T23:30:00Z",
+		"timezone": "(GMT+07:00) Asia/Jakarta", "total_elevation_gain": 42.5,
+		"has_heartrate": true, "average_heartrate": 151.2, "max_heartrate": 178,
+		"average_speed": 3.04, "calories": 402.6, "device_name": "Garmin Forerunner 255",
+		"description": "felt good"
+	}`
+	var a Activity
+	if err := json.Unmarshal([]byte(payload), &a); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if want := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC); !a.LocalDate().Equal(want) {
+		t.Fatalf("LocalDate = %s, want %s", a.LocalDate(), want)
+	}
+
+	w := a.Workout()
+	if w.Source != domain.WorkoutSourceStrava || w.ActivityType != domain.ActivityTypeRun {
+		t.Fatalf("unexpected source/type: %+v", w)
+	}
+	if w.DistanceMeters != 5012 || w.DurationSeconds != 1650 || w.Calories != 403 {
+		t.Fatalf("unexpected metrics: %+v", w)
+	}
+	if len(w.Exercises) != 0 {
+		t.Fatalf("distance activity descriptions are not exercises, got %v", w.Exercises)
+	}
+}
+
+func TestActivityWorkoutListsExercisesForStrength(t *testing.T) {
+	a := Activity{Name: "Leg Day", Type: "WeightTraining", ElapsedTime: 3600, Description: "Squat 5x5\n\nDeadlift 3x5"}
+	w := a.Workout()
+	if w.ActivityType != domain.ActivityTypeStrength || w.DurationSeconds != 3600 {
+		t.Fatalf("unexpected workout: %+v", w)
+	}
+	if len(w.Exercises) != 2 || w.Exercises[1] != "Deadlift 3x5" {
+		t.Fatalf("unexpected exercises: %v", w.Exercises)
+	}
+}
+
+func TestListActivitiesAgainstStubServer(t *testing.T) {
+	after := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	before := after.AddDate(0, 0, 7)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v3/athlete/activities" || r.Header.Get("Authorization") != "Bearer abc" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if q.Get("after") != "1788220800" || q.Get("before") != "1788825600" || q.Get("page") != "2" || q.Get("per_page") != "50" {
+			http.Error(w, "bad query "+r.URL.RawQuery, http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id": 1, "type": "Run", "distance": 5000}]`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(config.Config{StravaBaseURL: srv.URL + "/"})
+	activities, err := client.ListActivities("abc", after, before, 2, 50)
+	if err != nil {
+		t.Fatalf("ListActivities: %v", err)
+	}
+	if len(activities) != 1 || activities[0].ID != 1 {
+		t.Fatalf("unexpected activities %+v", activities)
+	}
+
+	if _, err := client.ListActivities("wrong", after, before, 2, 50); err == nil {
+		t.Fatal("expected an error for a non-200 response")
+	}
+}
@@ -0,0 +1,85 @@
+// Package webhook sends signed bot events to subscriber endpoints.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every delivery. The signature uses the same scheme as
+// inbound integration requests, so a receiver can share verification code.
+const (
+	HeaderEvent     = "X-Lapor-Event"
+	HeaderDelivery  = "X-Lapor-Delivery"
+	HeaderTimestamp = "X-Lapor-Timestamp"
+	HeaderSignature = "X-Lapor-Signature"
+)
+
+// maxErrorBody is how much of a failed response is kept for the delivery log.
+const maxErrorBody = 256
+
+// Request is one delivery attempt. EventID is stable across retries so the
+// receiver can drop duplicates.
+type Request struct {
+	URL       string
+	Secret    string
+	EventID   string
+	EventType string
+	Payload   []byte
+}
+
+type Client struct {
+	httpClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Sign returns "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>"
+// keyed with secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts the payload and returns the response status. Any status
+// outside 2xx is an error; the status code is still returned for the log.
+func (c *Client) Send(ctx context.Context, req Request, now time.Time) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "LaporBot-Webhook/1")
+	httpReq.Header.Set(HeaderEvent, req.EventType)
+	httpReq.Header.Set(HeaderDelivery, req.EventID)
+	httpReq.Header.Set(HeaderTimestamp, timestamp)
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Payload))
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, fmt.Errorf("webhook endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,257 @@
+package workoutfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+// FIT is Garmin's binary format. Only the fields the summary needs are read;
+// everything else is skipped using the sizes from the definition messages.
+// See the FIT protocol description in the Garmin FIT SDK.
+
+const (
+	fitMesgSession = 18
+	fitMesgRecord  = 20
+
+	fitFieldTimestamp = 253
+
+	fitSessionStartTime   = 2
+	fitSessionSport       = 5
+	fitSessionElapsedTime = 7
+	fitSessionTimerTime   = 8
+	fitSessionDistance    = 9
+	fitSessionCalories    = 11
+	fitSessionAscent      = 22
+
+	fitRecordAltitude         = 2
+	fitRecordDistance         = 5
+	fitRecordEnhancedAltitude = 78
+)
+
+// fitEpoch is the FIT timestamp origin, 1989-12-31T00:00:00Z.
+const fitEpoch = 631065600
+
+var errFITMalformed = errors.New("fit: malformed file")
+
+var fitSports = map[uint64]string{
+	1:  "Running",
+	2:  "Cycling",
+	4:  "Fitness Equipment",
+	5:  "Swimming",
+	10: "Training",
+	11: "Walking",
+	17: "Hiking",
+}
+
+type fitField struct {
+	num  byte
+	size int
+}
+
+type fitDefinition struct {
+	global   uint16
+	order    binary.ByteOrder
+	fields   []fitField
+	devBytes int
+}
+
+type fitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *fitReader) next(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, errFITMalformed
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func parseFIT(data []byte) (*Activity, error) {
+	if len(data) < 12 || string(data[8:12]) != ".FIT" {
+		return nil, errFITMalformed
+	}
+	headerSize := int(data[0])
+	dataSize := int(binary.LittleEndian.Uint32(data[4:8]))
+	if headerSize < 12 || headerSize+dataSize > len(data) {
+		return nil, errFITMalformed
+	}
+	r := &fitReader{data: data[headerSize : headerSize+dataSize]}
+
+	var (
+		definitions   = map[byte]*fitDefinition{}
+		session       map[byte]uint64
+		lastTimestamp uint64
+		firstRecord   uint64
+		lastRecord    uint64
+		maxDistance   uint64
+		altitudes     []float64
+	)
+	for r.pos < len(r.data) {
+		header, err := r.next(1)
+		if err != nil {
+			return nil, err
+		}
+		h := header[0]
+
+		compressed := h&0x80 != 0
+		if !compressed && h&0x40 != 0 {
+			def, err := readFITDefinition(r, h&0x20 != 0)
+			if err != nil {
+				return nil, err
+			}
+			definitions[h&0x0F] = def
+			continue
+		}
+
+		local := h & 0x0F
+		if compressed {
+			local = (h >> 5) & 0x03
+			offset := uint64(h & 0x1F)
+			timestamp := lastTimestamp&^0x1F + offset
+			if offset < lastTimestamp&0x1F {
+				timestamp += 0x20
+			}
+			lastTimestamp = timestamp
+		}
+		def, ok := definitions[local]
+		if !ok {
+			return nil, errFITMalformed
+		}
+		values, err := readFITData(r, def)
+		if err != nil {
+			return nil, err
+		}
+		if ts, ok := values[fitFieldTimestamp]; ok {
+			lastTimestamp = ts
+		}
+
+		switch def.global {
+		case fitMesgSession:
+			if session == nil {
+				session = values
+			}
+		case fitMesgRecord:
+			if lastTimestamp != 0 {
+				if firstRecord == 0 {
+					firstRecord = lastTimestamp
+				}
+				lastRecord = lastTimestamp
+			}
+			if d, ok := values[fitRecordDistance]; ok && d > maxDistance {
+				maxDistance = d
+			}
+			if alt, ok := values[fitRecordEnhancedAltitude]; ok {
+				altitudes = append(altitudes, float64(alt)/5-500)
+			} else if alt, ok := values[fitRecordAltitude]; ok {
+				altitudes = append(altitudes, float64(alt)/5-500)
+			}
+		}
+	}
+
+	a := &Activity{}
+	if session != nil {
+		if start, ok := session[fitSessionStartTime]; ok {
+			a.StartTime = fitTime(start)
+		}
+		duration, ok := session[fitSessionTimerTime]
+		if !ok {
+			duration = session[fitSessionElapsedTime]
+		}
+		a.DurationSeconds = int(math.Round(float64(duration) / 1000))
+		a.DistanceMeters = float64(session[fitSessionDistance]) / 100
+		a.Calories = int(session[fitSessionCalories])
+		if ascent, ok := session[fitSessionAscent]; ok {
+			a.ElevationGainMeters = float64(ascent)
+		} else {
+			a.ElevationGainMeters = elevationGain(altitudes)
+		}
+		a.Sport = fitSports[session[fitSessionSport]]
+	}
+	// Some devices write no session message; fall back to the records.
+	if a.StartTime.IsZero() && firstRecord != 0 {
+		a.StartTime = fitTime(firstRecord)
+	}
+	if a.DurationSeconds == 0 && lastRecord > firstRecord {
+		a.DurationSeconds = int(lastRecord - firstRecord)
+	}
+	if a.DistanceMeters == 0 {
+		a.DistanceMeters = float64(maxDistance) / 100
+	}
+	if a.ElevationGainMeters == 0 {
+		a.ElevationGainMeters = elevationGain(altitudes)
+	}
+	a.ActivityType = activityType(a.Sport)
+	return a, nil
+}
+
+func readFITDefinition(r *fitReader, hasDevFields bool) (*fitDefinition, error) {
+	fixed, err := r.next(5)
+	if err != nil {
+		return nil, err
+	}
+	def := &fitDefinition{order: binary.LittleEndian}
+	if fixed[1] == 1 {
+		def.order = binary.BigEndian
+	}
+	def.global = def.order.Uint16(fixed[2:4])
+	raw, err := r.next(int(fixed[4]) * 3)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(raw); i += 3 {
+		def.fields = append(def.fields, fitField{num: raw[i], size: int(raw[i+1])})
+	}
+	if hasDevFields {
+		count, err := r.next(1)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := r.next(int(count[0]) * 3)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < len(raw); i += 3 {
+			def.devBytes += int(raw[i+1])
+		}
+	}
+	return def, nil
+}
+
+// readFITData returns the valid unsigned integer fields of a data message.
+// Fields of other sizes, and the FIT "invalid" sentinels, are left out.
+func readFITData(r *fitReader, def *fitDefinition) (map[byte]uint64, error) {
+	values := make(map[byte]uint64, len(def.fields))
+	for _, field := range def.fields {
+		raw, err := r.next(field.size)
+		if err != nil {
+			return nil, err
+		}
+		var v, invalid uint64
+		switch field.size {
+		case 1:
+			v, invalid = uint64(raw[0]), math.MaxUint8
+		case 2:
+			v, invalid = uint64(def.order.Uint16(raw)), math.MaxUint16
+		case 4:
+			v, invalid = uint64(def.order.Uint32(raw)), math.MaxUint32
+		default:
+			continue
+		}
+		if v != invalid {
+			values[field.num] = v
+		}
+	}
+	if _, err := r.next(def.devBytes); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func fitTime(ts uint64) time.Time {
+	return time.Unix(int64(ts)+fitEpoch, 0).UTC()
+}
@@ -0,0 +1,79 @@
+package workoutfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+type gpxFile struct {
+	Metadata struct {
+		Time string `xml:"time"`
+	} `xml:"metadata"`
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat  float64  `xml:"lat,attr"`
+	Lon  float64  `xml:"lon,attr"`
+	Ele  *float64 `xml:"ele"`
+	Time string   `xml:"time"`
+}
+
+// parseGPX derives the summary from the track points, since GPX has no
+// summary fields. Distance is only summed within a segment: a gap between
+// segments is a pause, not ground covered.
+func parseGPX(data []byte) (*Activity, error) {
+	var file gpxFile
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&file); err != nil {
+		return nil, fmt.Errorf("gpx: %w", err)
+	}
+
+	a := &Activity{}
+	var first, last time.Time
+	var altitudes []float64
+	for _, track := range file.Tracks {
+		if a.Sport == "" {
+			a.Sport = track.Type
+		}
+		for _, segment := range track.Segments {
+			for i, point := range segment.Points {
+				if i > 0 {
+					prev := segment.Points[i-1]
+					a.DistanceMeters += haversine(prev.Lat, prev.Lon, point.Lat, point.Lon)
+				}
+				if point.Ele != nil {
+					altitudes = append(altitudes, *point.Ele)
+				}
+				t, err := time.Parse(time.RFC3339, point.Time)
+				if err != nil {
+					continue
+				}
+				if first.IsZero() || t.Before(first) {
+					first = t
+				}
+				if t.After(last) {
+					last = t
+				}
+			}
+		}
+	}
+	if first.IsZero() {
+		first, _ = time.Parse(time.RFC3339, file.Metadata.Time)
+	}
+
+	a.StartTime = first.UTC()
+	if !last.IsZero() {
+		a.DurationSeconds = int(last.Sub(first).Seconds())
+	}
+	a.ElevationGainMeters = elevationGain(altitudes)
+	a.ActivityType = activityType(a.Sport)
+	return a, nil
+}
@@ -0,0 +1,63 @@
+package workoutfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"time"
+)
+
+type tcxFile struct {
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		Laps  []struct {
+			StartTime        string  `xml:"StartTime,attr"`
+			TotalTimeSeconds float64 `xml:"TotalTimeSeconds"`
+			DistanceMeters   float64 `xml:"DistanceMeters"`
+			Calories         int     `xml:"Calories"`
+			Tracks           []struct {
+				Points []struct {
+					Altitude *float64 `xml:"AltitudeMeters"`
+				} `xml:"Trackpoint"`
+			} `xml:"Track"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+// parseTCX sums the laps of the first activity in the file. Laps carry
+// their own totals, so only the elevation comes from the track points.
+func parseTCX(data []byte) (*Activity, error) {
+	var file tcxFile
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&file); err != nil {
+		return nil, fmt.Errorf("tcx: %w", err)
+	}
+	if len(file.Activities) == 0 {
+		return nil, ErrNoActivity
+	}
+	activity := file.Activities[0]
+
+	a := &Activity{Sport: activity.Sport, ActivityType: activityType(activity.Sport)}
+	var seconds float64
+	var altitudes []float64
+	for _, lap := range activity.Laps {
+		if start, err := time.Parse(time.RFC3339, lap.StartTime); err == nil {
+			if a.StartTime.IsZero() || start.Before(a.StartTime) {
+				a.StartTime = start.UTC()
+			}
+		}
+		seconds += lap.TotalTimeSeconds
+		a.DistanceMeters += lap.DistanceMeters
+		a.Calories += lap.Calories
+		for _, track := range lap.Tracks {
+			for _, point := range track.Points {
+				if point.Altitude != nil {
+					altitudes = append(altitudes, *point.Altitude)
+				}
+			}
+		}
+	}
+	a.DurationSeconds = int(math.Round(seconds))
+	a.ElevationGainMeters = elevationGain(altitudes)
+	return a, nil
+}
@@ -0,0 +1,160 @@
+// Package workoutfile reads the activity files GPS watches and bike
+// computers export: GPX, TCX and FIT.
+package workoutfile
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const (
+	FormatGPX = "gpx"
+	FormatTCX = "tcx"
+	FormatFIT = "fit"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported workout file format")
+	// ErrNoActivity means the file parsed but holds no timed activity.
+	ErrNoActivity = errors.New("workout file has no activity data")
+)
+
+// Activity is the summary of one recorded activity. Fields the file does not
+// carry are zero.
+type Activity struct {
+	Format              string
+	Sport               string // the file's own sport name, e.g. "Running"
+	ActivityType        string // domain.ActivityType*, empty when unknown
+	StartTime           time.Time
+	DurationSeconds     int
+	DistanceMeters      float64
+	ElevationGainMeters float64
+	Calories            int
+}
+
+// FormatFromName returns the format implied by a file name's extension.
+func FormatFromName(name string) (string, bool) {
+	switch strings.ToLower(strings.TrimPrefix(filepath.Ext(name), ".")) {
+	case FormatGPX:
+		return FormatGPX, true
+	case FormatTCX:
+		return FormatTCX, true
+	case FormatFIT:
+		return FormatFIT, true
+	default:
+		return "", false
+	}
+}
+
+// Parse reads a file in the given format.
+func Parse(format string, data []byte) (*Activity, error) {
+	var (
+		a   *Activity
+		err error
+	)
+	switch format {
+	case FormatGPX:
+		a, err = parseGPX(data)
+	case FormatTCX:
+		a, err = parseTCX(data)
+	case FormatFIT:
+		a, err = parseFIT(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if a.StartTime.IsZero() || a.DurationSeconds <= 0 {
+		return nil, ErrNoActivity
+	}
+	a.Format = format
+	return a, nil
+}
+
+// LocalDate returns the day the activity started in the server's timezone,
+// as a UTC midnight like domain.GetToday. Files record UTC timestamps, and
+// the group reports on local days.
+func (a *Activity) LocalDate() time.Time {
+	return domain.GetToday(a.StartTime.Local())
+}
+
+// Workout converts the activity into the workout the report flow displays.
+func (a *Activity) Workout() *domain.Workout {
+	title := "Aktivitas"
+	if a.Sport != "" {
+		title = a.Sport
+	}
+	return &domain.Workout{
+		Source:          domain.WorkoutSourceFile,
+		Title:           title,
+		ActivityType:    a.ActivityType,
+		DistanceMeters:  int(math.Round(a.DistanceMeters)),
+		DurationSeconds: a.DurationSeconds,
+		Calories:        a.Calories,
+	}
+}
+
+// sportTypes maps sport names used by GPX, TCX and FIT to activity types.
+var sportTypes = map[string]string{
+	"running":           domain.ActivityTypeRun,
+	"run":               domain.ActivityTypeRun,
+	"trail_running":     domain.ActivityTypeRun,
+	"treadmill_running": domain.ActivityTypeRun,
+	"biking":            domain.ActivityTypeRide,
+	"cycling":           domain.ActivityTypeRide,
+	"ride":              domain.ActivityTypeRide,
+	"road_biking":       domain.ActivityTypeRide,
+	"mountain_biking":   domain.ActivityTypeRide,
+	"walking":           domain.ActivityTypeWalk,
+	"walk":              domain.ActivityTypeWalk,
+	"hiking":            domain.ActivityTypeHike,
+	"hike":              domain.ActivityTypeHike,
+	"swimming":          domain.ActivityTypeSwim,
+	"swim":              domain.ActivityTypeSwim,
+	"open_water":        domain.ActivityTypeSwim,
+}
+
+func activityType(sport string) string {
+	key := strings.ToLower(strings.TrimSpace(sport))
+	key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+	return sportTypes[key]
+}
+
+// elevationGain sums the climbs in a series of altitudes. Changes smaller
+// than the threshold are GPS noise and would inflate the total on flat
+// routes.
+func elevationGain(altitudes []float64) float64 {
+	const threshold = 2.0
+	if len(altitudes) == 0 {
+		return 0
+	}
+	gain := 0.0
+	base := altitudes[0]
+	for _, alt := range altitudes[1:] {
+		switch {
+		case alt-base >= threshold:
+			gain += alt - base
+			base = alt
+		case alt < base:
+			base = alt
+		}
+	}
+	return gain
+}
+
+// haversine returns the distance in meters between two coordinates.
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371000.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
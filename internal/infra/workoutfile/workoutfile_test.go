@@ -0,0 +1,219 @@
+package workoutfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fardannozami/whatsapp-gateway/internal/domain"
+)
+
+const sampleGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="Watch" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk>
+    <name>Morning Run</name>
+    <type>running</type>
+    <trkseg>
+      <trkpt lat="-6.200000" lon="106.816666"><ele>10</ele><time>2026-10-18T23:00:00Z</time></trkpt>
+      <trkpt lat="-6.209000" lon="106.816666"><ele>15</ele><time>2026-10-18T23:05:00Z</time></trkpt>
+      <trkpt lat="-6.218000" lon="106.816666"><ele>12</ele><time>2026-10-18T23:10:00Z</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+const sampleTCX = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Activities>
+    <Activity Sport="Biking">
+      <Id>2026-10-18T22:00:00Z</Id>
+      <Lap StartTime="2026-10-18T22:00:00Z">
+        <TotalTimeSeconds>1800.4</TotalTimeSeconds>
+        <DistanceMeters>15000</DistanceMeters>
+        <Calories>300</Calories>
+        <Track>
+          <Trackpoint><AltitudeMeters>100</AltitudeMeters></Trackpoint>
+          <Trackpoint><AltitudeMeters>130</AltitudeMeters></Trackpoint>
+        </Track>
+      </Lap>
+      <Lap StartTime="2026-10-18T22:30:00Z">
+        <TotalTimeSeconds>1200</TotalTimeSeconds>
+        <DistanceMeters>10000</DistanceMeters>
+        <Calories>200</Calories>
+      </Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestFormatFromName(t *testing.T) {
+	for name, want := range map[string]string{"run.GPX": FormatGPX, "ride.tcx": FormatTCX, "a.b.fit": FormatFIT} {
+		if got, ok := FormatFromName(name); !ok || got != want {
+			t.Errorf("FormatFromName(%q) = %q, %v", name, got, ok)
+		}
+	}
+	if _, ok := FormatFromName("photo.jpg"); ok {
+		t.Error("jpg should not be a workout file")
+	}
+}
+
+func TestParseGPX(t *testing.T) {
+	a, err := Parse(FormatGPX, []byte(sampleGPX))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !a.StartTime.Equal(time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)) || a.DurationSeconds != 600 {
+		t.Fatalf("unexpected timing: start=%s duration=%d", a.StartTime, a.DurationSeconds)
+	}
+	// Two legs of 0.009° latitude are about 1 km each.
+	if math.Abs(a.DistanceMeters-2001) > 5 {
+		t.Fatalf("expected about 2 km, got %.1f m", a.DistanceMeters)
+	}
+	if a.ElevationGainMeters != 5 || a.ActivityType != domain.ActivityTypeRun {
+		t.Fatalf("unexpected elevation %.1f or type %q", a.ElevationGainMeters, a.ActivityType)
+	}
+}
+
+func TestParseTCX(t *testing.T) {
+	a, err := Parse(FormatTCX, []byte(sampleTCX))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !a.StartTime.Equal(time.Date(2026, 10, 18, 22, 0, 0, 0, time.UTC)) || a.DurationSeconds != 3000 {
+		t.Fatalf("unexpected timing: start=%s duration=%d", a.StartTime, a.DurationSeconds)
+	}
+	if a.DistanceMeters != 25000 || a.Calories != 500 || a.ElevationGainMeters != 30 {
+		t.Fatalf("unexpected totals: %+v", a)
+	}
+	w := a.Workout()
+	if w.ActivityType != domain.ActivityTypeRide || w.Source != domain.WorkoutSourceFile || w.DistanceMeters != 25000 {
+		t.Fatalf("unexpected workout: %+v", w)
+	}
+}
+
+// fitBuilder writes just enough of the FIT format for the tests.
+type fitBuilder struct {
+	buf bytes.Buffer
+}
+
+type fitTestField struct {
+	num   byte
+	size  byte
+	value uint64
+}
+
+func (b *fitBuilder) message(local byte, global uint16, fields []fitTestField) {
+	b.define(local, global, fields)
+	b.buf.WriteByte(local)
+	b.data(fields)
+}
+
+func (b *fitBuilder) define(local byte, global uint16, fields []fitTestField) {
+	b.buf.WriteByte(0x40 | local)
+	b.buf.Write([]byte{0, 0})
+	_ = binary.Write(&b.buf, binary.LittleEndian, global)
+	b.buf.WriteByte(byte(len(fields)))
+	for _, f := range fields {
+		b.buf.Write([]byte{f.num, f.size, 0x86})
+	}
+}
+
+func (b *fitBuilder) data(fields []fitTestField) {
+	for _, f := range fields {
+		switch f.size {
+		case 1:
+			b.buf.WriteByte(byte(f.value))
+		case 2:
+			_ = binary.Write(&b.buf, binary.LittleEndian, uint16(f.value))
+		case 4:
+			_ = binary.Write(&b.buf, binary.LittleEndian, uint32(f.value))
+		}
+	}
+}
+
+func (b *fitBuilder) bytes() []byte {
+	header := make([]byte, 12)
+	header[0] = 12
+	header[1] = 0x20
+	binary.LittleEndian.PutUint32(header[4:8], uint32(b.buf.Len()))
+	copy(header[8:], ".FIT")
+	return append(append(header, b.buf.Bytes()...), 0, 0)
+}
+
+func fitTimestamp(t time.Time) uint64 {
+	return uint64(t.Unix() - fitEpoch)
+}
+
+func TestParseFIT_Session(t *testing.T) {
+	start := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	var b fitBuilder
+	b.message(0, fitMesgRecord, []fitTestField{
+		{fitFieldTimestamp, 4, fitTimestamp(start)},
+		{fitRecordDistance, 4, 0},
+	})
+	b.message(1, fitMesgSession, []fitTestField{
+		{fitFieldTimestamp, 4, fitTimestamp(start.Add(time.Hour))},
+		{fitSessionStartTime, 4, fitTimestamp(start)},
+		{fitSessionSport, 1, 1},
+		{fitSessionElapsedTime, 4, 3_000_000},
+		{fitSessionTimerTime, 4, 2_700_000},
+		{fitSessionDistance, 4, 1_000_000},
+		{fitSessionCalories, 2, 650},
+		{fitSessionAscent, 2, 0xFFFF}, // invalid: use the records instead
+	})
+
+	a, err := Parse(FormatFIT, b.bytes())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !a.StartTime.Equal(start) || a.DurationSeconds != 2700 || a.DistanceMeters != 10000 || a.Calories != 650 {
+		t.Fatalf("unexpected summary: %+v", a)
+	}
+	if a.Sport != "Running" || a.ActivityType != domain.ActivityTypeRun {
+		t.Fatalf("unexpected sport %q / %q", a.Sport, a.ActivityType)
+	}
+}
+
+func TestParseFIT_RecordsOnly(t *testing.T) {
+	start := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	var b fitBuilder
+	b.message(0, fitMesgRecord, []fitTestField{
+		{fitFieldTimestamp, 4, fitTimestamp(start)},
+		{fitRecordDistance, 4, 0},
+		{fitRecordAltitude, 2, (100 + 500) * 5},
+	})
+	// Compressed-timestamp records 10 and 40 seconds in, which carry no
+	// timestamp field of their own.
+	moving := []fitTestField{{fitRecordDistance, 4, 0}, {fitRecordAltitude, 2, 0}}
+	b.define(1, fitMesgRecord, moving)
+	for _, step := range []struct {
+		offset   int
+		distance uint64
+		altitude uint64
+	}{{10, 5000, (110 + 500) * 5}, {40, 12000, (105 + 500) * 5}} {
+		ts := fitTimestamp(start.Add(time.Duration(step.offset) * time.Second))
+		b.buf.WriteByte(0x80 | 1<<5 | byte(ts&0x1F))
+		b.data([]fitTestField{{fitRecordDistance, 4, step.distance}, {fitRecordAltitude, 2, step.altitude}})
+	}
+
+	a, err := Parse(FormatFIT, b.bytes())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !a.StartTime.Equal(start) || a.DurationSeconds != 40 || a.DistanceMeters != 120 || a.ElevationGainMeters != 10 {
+		t.Fatalf("unexpected summary: %+v", a)
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	if _, err := Parse(FormatFIT, []byte("not a fit file")); err == nil {
+		t.Error("expected an error for a non-FIT file")
+	}
+	if _, err := Parse(FormatGPX, []byte("<gpx></gpx>")); !errors.Is(err, ErrNoActivity) {
+		t.Errorf("expected ErrNoActivity for an empty track, got %v", err)
+	}
+	if _, err := Parse("kml", nil); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
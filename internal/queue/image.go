@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/png"
+	"log"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// MediaUploader is implemented by clients that can upload attachments,
+// such as *whatsmeow.Client.
+type MediaUploader interface {
+	Upload(ctx context.Context, plaintext []byte, appInfo whatsmeow.MediaType) (whatsmeow.UploadResponse, error)
+}
+
+// SendImageHighPriority uploads a PNG and enqueues it as an image message
+// with a caption. When there is no image, the client can't upload or the
+// upload fails, it enqueues fallback as a text message instead, so the
+// group still gets the post. The upload runs on the caller's goroutine to
+// keep the queue free for other messages.
+func (s *MessageSender) SendImageHighPriority(ctx context.Context, target types.JID, png []byte, caption, fallback string) error {
+	msg, err := s.imageMessage(ctx, png, caption)
+	if err != nil {
+		log.Printf("[QUEUE] image upload failed, sending text instead: %v", err)
+		msg = &waE2E.Message{Conversation: &fallback}
+	}
+	return s.SendHighPriority(ctx, target, msg)
+}
+
+func (s *MessageSender) imageMessage(ctx context.Context, png []byte, caption string) (*waE2E.Message, error) {
+	if len(png) == 0 {
+		return nil, fmt.Errorf("no image")
+	}
+	uploader, ok := s.client.(MediaUploader)
+	if !ok {
+		return nil, fmt.Errorf("client cannot upload media")
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(png))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	uploaded, err := uploader.Upload(ctx, png, whatsmeow.MediaImage)
+	if err != nil {
+		return nil, err
+	}
+
+	mimetype := "image/png"
+	width, height := uint32(config.Width), uint32(config.Height)
+	return &waE2E.Message{
+		ImageMessage: &waE2E.ImageMessage{
+			Caption:       &caption,
+			Mimetype:      &mimetype,
+			URL:           &uploaded.URL,
+			DirectPath:    &uploaded.DirectPath,
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    &uploaded.FileLength,
+			Width:         &width,
+			Height:        &height,
+		},
+	}, nil
+}
@@ -1,7 +1,11 @@
 package queue
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"image"
+	"image/png"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -163,3 +167,57 @@ func TestShutdownDrainsPending(t *testing.T) {
 		t.Errorf("expected 2 messages drained, got %d", fc.sentCount())
 	}
 }
+
+// mediaClient records sent messages and can upload media.
+type mediaClient struct {
+	sent      []*waE2E.Message
+	uploadErr error
+}
+
+func (m *mediaClient) SendMessage(ctx context.Context, target types.JID, msg *waE2E.Message, extra ...whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+	m.sent = append(m.sent, msg)
+	return whatsmeow.SendResponse{}, nil
+}
+
+func (m *mediaClient) Upload(ctx context.Context, plaintext []byte, appInfo whatsmeow.MediaType) (whatsmeow.UploadResponse, error) {
+	if m.uploadErr != nil {
+		return whatsmeow.UploadResponse{}, m.uploadErr
+	}
+	return whatsmeow.UploadResponse{URL: "https://mmg.example/img", DirectPath: "/img", FileLength: uint64(len(plaintext))}, nil
+}
+
+func TestSendImageHighPriority_FallsBackToText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 3))); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	client := &mediaClient{}
+	sender := NewTestSender(client, context.Background())
+	sender.Start()
+	if err := sender.SendImageHighPriority(context.Background(), types.JID{}, buf.Bytes(), "Klasemen", "full text"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.uploadErr = errors.New("media server down")
+	if err := sender.SendImageHighPriority(context.Background(), types.JID{}, buf.Bytes(), "Klasemen", "full text"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sender.cancel()
+	sender.wg.Wait()
+
+	if len(client.sent) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(client.sent))
+	}
+	img := client.sent[0].GetImageMessage()
+	if img.GetCaption() != "Klasemen" || img.GetURL() != "https://mmg.example/img" || img.GetWidth() != 4 || img.GetHeight() != 3 {
+		t.Fatalf("unexpected image message: %+v", img)
+	}
+	if client.sent[1].GetConversation() != "full text" {
+		t.Fatalf("a failed upload should send the text fallback, got %+v", client.sent[1])
+	}
+
+	textOnly := NewTestSender(&fakeClient{}, context.Background())
+	if _, err := textOnly.imageMessage(context.Background(), buf.Bytes(), "x"); err == nil {
+		t.Fatal("a client without uploads should not build an image message")
+	}
+}
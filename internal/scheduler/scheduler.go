@@ -60,6 +60,16 @@ func (w WeeklySchedule) Next(now time.Time) time.Time {
 	return target
 }
 
+// IntervalSchedule fires every Every, counted from the end of the previous
+// run. It suits polling work like draining an inbox.
+type IntervalSchedule struct {
+	Every time.Duration
+}
+
+func (i IntervalSchedule) Next(now time.Time) time.Time {
+	return now.Add(i.Every)
+}
+
 // Job is a single scheduled task.
 type Job struct {
 	Name    string
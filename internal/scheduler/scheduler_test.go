@@ -104,6 +104,14 @@ func TestWeeklyScheduleNext(t *testing.T) {
 	}
 }
 
+func TestIntervalScheduleNext(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 8, 0, 5, 0, time.UTC)
+	got := IntervalSchedule{Every: 10 * time.Second}.Next(now)
+	if want := now.Add(10 * time.Second); !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+}
+
 func TestParseDaily(t *testing.T) {
 	loc := time.UTC
 